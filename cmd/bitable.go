@@ -21,9 +21,10 @@ var bitableCmd = &cobra.Command{
   bitable <create|get|copy|update>      基础：创建/获取/复制/更新（重命名·高级权限）多维表格
   bitable table <list|get|create|...>   数据表 CRUD
   bitable field <list|get|create|...>   字段 CRUD + search-options
-  bitable record <list|get|search|...>  记录 CRUD + upsert + batch + history + *-attachment
+  bitable record <list|get|search|...>  记录 CRUD + upsert + batch + import/export + history + *-attachment
   bitable view <list|get|create|...>    视图 CRUD + rename
   bitable view-<filter|sort|group|visible-fields|timebar|card> <get|set>  视图配置
+  bitable schema <export|apply>         表结构即代码：导出 YAML/JSON、按文件声明式同步
   bitable role <list|get|create|...>    角色 CRUD
   bitable role member <list|create|delete|batch-create|batch-delete>  角色协作者管理
   bitable advperm <enable|disable>      高级权限开关
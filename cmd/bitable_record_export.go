@@ -0,0 +1,786 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// 记录列表单页上限
+const maxExportPageSize = 500
+
+// exportColumn 是导出的一列（一个字段）
+type exportColumn struct {
+	name      string
+	typ       string
+	linkTable string
+}
+
+// recordExporter 逐页读取记录并按字段类型展平；call 可在测试中替换
+type recordExporter struct {
+	call      baseV3Caller
+	baseToken string
+	tableID   string
+	params    map[string]any // view_id / filter 等随每页下发的 query 参数
+	pageSize  int
+	loc       *time.Location
+	ids       bool // 人员 / 关联 / 附件输出 ID 而非显示值
+	userEmail bool // 人员输出邮箱
+	retry     client.RetryConfig
+
+	columns []*exportColumn
+	links   map[string]map[string]string // 关联表 ID → record_id → 主字段文本
+}
+
+// exportCursor 是断点续传的游标文件内容：每页写完并刷盘后更新
+type exportCursor struct {
+	BaseToken string   `json:"base_token"`
+	TableID   string   `json:"table_id"`
+	ViewID    string   `json:"view_id,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	Format    string   `json:"format"`
+	Output    string   `json:"output"`
+	Columns   []string `json:"columns"`
+	Offset    int      `json:"offset"`   // 下一页的 offset
+	Exported  int      `json:"exported"` // 已写出的记录数
+	Bytes     int64    `json:"bytes"`    // 输出文件中已确认写完的字节数，续传时截断到此处
+	LastIDs   []string `json:"last_ids,omitempty"`
+	Total     int      `json:"total,omitempty"` // 读第一页时表中的记录总数，用于发现导出期间的删除
+}
+
+// sameTask 判断游标是否属于同一导出任务
+func (c *exportCursor) sameTask(o *exportCursor) bool {
+	return c.BaseToken == o.BaseToken && c.TableID == o.TableID && c.ViewID == o.ViewID &&
+		c.Filter == o.Filter && c.Format == o.Format && c.Output == o.Output
+}
+
+func loadExportCursor(path string) (*exportCursor, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取游标文件失败: %w", err)
+	}
+	var c exportCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("解析游标文件失败: %w", err)
+	}
+	return &c, nil
+}
+
+// saveExportCursor 先写临时文件再改名，避免中断时留下半截游标
+func saveExportCursor(path string, c *exportCursor) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入游标文件失败: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// prepare 确定导出列：names 为空时导出全部字段（按数据表字段顺序），否则按 names 顺序（字段名或字段 ID）
+func (ex *recordExporter) prepare(names []string) error {
+	raw, err := listBaseV3All(ex.call, bitableFieldPath(ex.baseToken, ex.tableID), nil)
+	if err != nil {
+		return fmt.Errorf("读取字段列表失败: %w", err)
+	}
+	byKey := map[string]*exportColumn{}
+	var all []*exportColumn
+	for _, f := range raw {
+		col := &exportColumn{
+			name:      pickString(f, "name", "field_name"),
+			typ:       pickString(f, "type"),
+			linkTable: pickString(f, "link_table"),
+		}
+		all = append(all, col)
+		byKey[col.name] = col
+		if id := pickString(f, "id", "field_id"); id != "" {
+			byKey[id] = col
+		}
+	}
+	ex.columns = all
+	if len(names) > 0 {
+		ex.columns = nil
+		for _, name := range names {
+			col := byKey[name]
+			if col == nil {
+				return fmt.Errorf("数据表中没有字段 %q", name)
+			}
+			ex.columns = append(ex.columns, col)
+		}
+	}
+
+	if ex.ids {
+		return nil
+	}
+	for _, col := range ex.columns {
+		if col.typ != "link" || col.linkTable == "" || ex.links[col.linkTable] != nil {
+			continue
+		}
+		index, err := ex.primaryValues(col.linkTable)
+		if err != nil {
+			return err
+		}
+		if ex.links == nil {
+			ex.links = map[string]map[string]string{}
+		}
+		ex.links[col.linkTable] = index
+	}
+	return nil
+}
+
+// primaryValues 读取关联表全部记录的主字段文本
+func (ex *recordExporter) primaryValues(tableID string) (map[string]string, error) {
+	primary, err := primaryFieldName(ex.call, ex.baseToken, tableID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := listBaseV3All(ex.call, bitableRecordPath(ex.baseToken, tableID), map[string]any{"field_id": []string{primary}})
+	if err != nil {
+		return nil, fmt.Errorf("读取关联表 %s 的记录失败: %w", tableID, err)
+	}
+	index := make(map[string]string, len(records))
+	for _, rec := range records {
+		fields, _ := rec["fields"].(map[string]any)
+		index[pickString(rec, "record_id", "id")] = importCellText(fields[primary])
+	}
+	return index, nil
+}
+
+func (ex *recordExporter) columnNames() []string {
+	names := make([]string, len(ex.columns))
+	for i, col := range ex.columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// exportRecord 是一条记录的 ID 与字段值
+type exportRecord struct {
+	id     string
+	fields map[string]any
+}
+
+// fetchPage 读取一页记录（带重试），同时返回响应中的 total（缺失时为 -1）。
+// 兼容行式 items 与列式 fields + data + record_id_list 两种响应
+func (ex *recordExporter) fetchPage(offset int) ([]exportRecord, bool, int, error) {
+	params := map[string]any{"offset": offset, "limit": ex.pageSize}
+	for k, v := range ex.params {
+		params[k] = v
+	}
+	res := client.DoWithRetry(func() (map[string]any, http.Header, error) {
+		data, err := ex.call("GET", bitableRecordPath(ex.baseToken, ex.tableID), params, nil)
+		return data, nil, err
+	}, ex.retry)
+	if res.Err != nil {
+		return nil, false, -1, fmt.Errorf("读取记录失败（offset=%d）: %w", offset, res.Err)
+	}
+	data := normalizeSchemaValue(res.Value).(map[string]any)
+	hasMore, _ := data["has_more"].(bool)
+	total := -1
+	switch n := data["total"].(type) {
+	case int64:
+		total = int(n)
+	case float64:
+		total = int(n)
+	}
+
+	var records []exportRecord
+	if items, ok := data["items"].([]any); ok {
+		for _, raw := range items {
+			m, _ := raw.(map[string]any)
+			fields, _ := m["fields"].(map[string]any)
+			records = append(records, exportRecord{id: pickString(m, "record_id", "id"), fields: fields})
+		}
+		return records, hasMore, total, nil
+	}
+	names := asAnySlice(data["fields"])
+	ids := asAnySlice(data["record_id_list"])
+	for i, raw := range asAnySlice(data["data"]) {
+		fields := map[string]any{}
+		for j, v := range asAnySlice(raw) {
+			if j < len(names) {
+				fields[fmt.Sprint(names[j])] = v
+			}
+		}
+		rec := exportRecord{fields: fields}
+		if i < len(ids) {
+			rec.id = fmt.Sprint(ids[i])
+		}
+		records = append(records, rec)
+	}
+	return records, hasMore, total, nil
+}
+
+// exportStats 是一次导出的统计
+type exportStats struct {
+	Pages      int `json:"pages"`
+	Exported   int `json:"exported"`
+	Duplicates int `json:"duplicates"` // 翻页期间数据变动导致重复出现、已跳过的记录
+	MayMiss    int `json:"may_miss"`   // 翻页期间记录被删除或插在已读位置之前，可能漏导的记录数上限
+}
+
+// run 从 cur 记录的位置开始逐页导出；每页写完后调用 checkpoint 更新游标。
+// 以 offset 翻页时若有记录插入或删除，页边界会平移：重复出现的 record_id 跳过不写；
+// 已读位置之前的删除会让后面一条未读记录被跳过，读完最后一页时按 total 的变化估算可能漏导的条数
+func (ex *recordExporter) run(w recordRowWriter, cur *exportCursor, checkpoint func(*exportCursor) error) (exportStats, error) {
+	stats := exportStats{Exported: cur.Exported}
+	seen := map[string]bool{}
+	for _, id := range cur.LastIDs {
+		seen[id] = true
+	}
+	for {
+		records, hasMore, total, err := ex.fetchPage(cur.Offset)
+		if err != nil {
+			return stats, err
+		}
+		stats.Pages++
+		if cur.Total == 0 && cur.Offset == 0 {
+			cur.Total = total
+		}
+		var pageIDs []string
+		for _, rec := range records {
+			if rec.id != "" {
+				pageIDs = append(pageIDs, rec.id)
+				if seen[rec.id] {
+					stats.Duplicates++
+					continue
+				}
+				seen[rec.id] = true
+			}
+			values := make([]any, len(ex.columns))
+			for i, col := range ex.columns {
+				values[i] = ex.flatten(col, rec.fields[col.name])
+			}
+			if err := w.row(rec.id, values); err != nil {
+				return stats, fmt.Errorf("写入输出失败: %w", err)
+			}
+			stats.Exported++
+		}
+		if err := w.flush(); err != nil {
+			return stats, fmt.Errorf("写入输出失败: %w", err)
+		}
+		cur.Offset += len(records)
+		cur.Exported = stats.Exported
+		cur.LastIDs = pageIDs
+		if checkpoint != nil {
+			if err := checkpoint(cur); err != nil {
+				return stats, err
+			}
+		}
+		if !hasMore || len(records) == 0 {
+			if total >= 0 && cur.Total >= 0 {
+				// 删除使 total 变小但已导出的条数不变；插入使 total 大于已导出条数
+				stats.MayMiss = max(cur.Total-total, total-stats.Exported, 0)
+			}
+			return stats, nil
+		}
+	}
+}
+
+// ------- 展平 -------
+
+// exportTimeLayout 与 record import 接受的日期格式一致，导出文件可直接导回
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// flatten 把单元格展平为标量或字符串列表：
+// 日期 → 本地时间文本；人员 → 姓名 / 邮箱 / open_id；关联 → 关联记录主字段值；附件 → 文件名 / file_token；
+// 公式、查找引用等其余类型按值的形态通用展开
+func (ex *recordExporter) flatten(col *exportColumn, v any) any {
+	if isEmptySchemaValue(v) {
+		return nil
+	}
+	switch col.typ {
+	case "datetime", "date", "created_at", "updated_at":
+		if ms, ok := v.(int64); ok {
+			return time.UnixMilli(ms).In(ex.loc).Format(exportTimeLayout)
+		}
+	case "user", "created_by", "updated_by":
+		return exportRefs(v, func(m map[string]any) string {
+			switch {
+			case ex.ids:
+				return pickString(m, "id", "open_id", "user_id")
+			case ex.userEmail:
+				return pickString(m, "email", "name", "id")
+			}
+			return pickString(m, "name", "en_name", "email", "id")
+		})
+	case "link":
+		return exportRefs(v, func(m map[string]any) string {
+			id := pickString(m, "id", "record_id")
+			if ex.ids {
+				return id
+			}
+			if text := ex.links[col.linkTable][id]; text != "" {
+				return text
+			}
+			return pickString(m, "text", "name", "id", "record_id")
+		})
+	case "attachment":
+		return exportRefs(v, func(m map[string]any) string {
+			if ex.ids {
+				return pickString(m, "file_token", "token", "name")
+			}
+			return pickString(m, "name", "file_name", "file_token", "token")
+		})
+	}
+	return flattenExportValue(v)
+}
+
+// exportRefs 展平对象列表：对象取 pick 的结果，字符串原样保留（如只给了 ID 的关联值）
+func exportRefs(v any, pick func(map[string]any) string) any {
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+	var out []string
+	for _, item := range list {
+		switch val := item.(type) {
+		case map[string]any:
+			if s := pick(val); s != "" {
+				out = append(out, s)
+			}
+		case string:
+			out = append(out, val)
+		}
+	}
+	return out
+}
+
+// flattenExportValue 通用展开：标量原样，富文本片段拼接，对象取 text/name/value 等，列表展开为字符串列表
+func flattenExportValue(v any) any {
+	switch val := v.(type) {
+	case []any:
+		// 富文本：片段拼接为一段文本
+		if len(val) > 0 && slices.IndexFunc(val, func(item any) bool {
+			m, ok := item.(map[string]any)
+			return !ok || m["text"] == nil
+		}) < 0 {
+			var sb strings.Builder
+			for _, item := range val {
+				sb.WriteString(fmt.Sprint(item.(map[string]any)["text"]))
+			}
+			return sb.String()
+		}
+		var out []string
+		for _, item := range val {
+			switch flat := flattenExportValue(item).(type) {
+			case nil:
+			case []string:
+				out = append(out, flat...)
+			default:
+				out = append(out, exportCellString(flat))
+			}
+		}
+		return out
+	case map[string]any:
+		if inner, ok := val["value"]; ok {
+			return flattenExportValue(inner)
+		}
+		if s := pickString(val, "text", "name", "full_address", "link", "id"); s != "" {
+			return s
+		}
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+	return v
+}
+
+// exportCellString 把展平后的值转为 CSV 单元格文本；列表用逗号连接，与 record import 的拆分规则一致
+func exportCellString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []string:
+		return strings.Join(val, ",")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// ------- 输出 -------
+
+// recordRowWriter 是一种导出格式；flush 把已写的行全部落到底层文件，之后才会更新游标
+type recordRowWriter interface {
+	header(columns []string) error
+	row(recordID string, values []any) error
+	flush() error
+	close() error
+}
+
+type csvRowWriter struct{ w *csv.Writer }
+
+func (c *csvRowWriter) header(columns []string) error {
+	return c.w.Write(append([]string{"record_id"}, columns...))
+}
+
+func (c *csvRowWriter) row(recordID string, values []any) error {
+	cells := make([]string, 0, len(values)+1)
+	cells = append(cells, recordID)
+	for _, v := range values {
+		cells = append(cells, exportCellString(v))
+	}
+	return c.w.Write(cells)
+}
+
+func (c *csvRowWriter) flush() error { c.w.Flush(); return c.w.Error() }
+func (c *csvRowWriter) close() error { return c.flush() }
+
+// jsonlRowWriter 每条记录一行对象，record_id 在前、字段按列顺序
+type jsonlRowWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func (j *jsonlRowWriter) header(columns []string) error { return nil }
+
+func (j *jsonlRowWriter) row(recordID string, values []any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encode 会追加换行，逐个值编码后去掉，拼成一行
+	add := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+	buf.WriteString(`{"record_id":`)
+	if err := add(recordID); err != nil {
+		return err
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		buf.WriteByte(',')
+		if err := add(j.columns[i]); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := add(v); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}\n")
+	_, err := j.w.Write(buf.Bytes())
+	return err
+}
+
+func (j *jsonlRowWriter) flush() error { return j.w.Flush() }
+func (j *jsonlRowWriter) close() error { return j.w.Flush() }
+
+type xlsxRowWriter struct{ x *xlsxStreamWriter }
+
+func (x *xlsxRowWriter) header(columns []string) error {
+	cells := []any{"record_id"}
+	for _, c := range columns {
+		cells = append(cells, c)
+	}
+	return x.x.WriteRow(cells)
+}
+
+func (x *xlsxRowWriter) row(recordID string, values []any) error {
+	cells := make([]any, 0, len(values)+1)
+	cells = append(cells, recordID)
+	for _, v := range values {
+		if list, ok := v.([]string); ok {
+			v = strings.Join(list, ",")
+		}
+		cells = append(cells, v)
+	}
+	return x.x.WriteRow(cells)
+}
+
+// xlsx 是一个 zip，只能在结束时整体落盘，不支持续传
+func (x *xlsxRowWriter) flush() error { return nil }
+func (x *xlsxRowWriter) close() error { return x.x.Close() }
+
+func newRecordRowWriter(format string, w io.Writer, columns []string, sheetName string) (recordRowWriter, error) {
+	switch format {
+	case "csv":
+		return &csvRowWriter{w: csv.NewWriter(w)}, nil
+	case "jsonl":
+		return &jsonlRowWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case "xlsx":
+		x, err := newXLSXStreamWriter(w, sheetName)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxRowWriter{x: x}, nil
+	}
+	return nil, fmt.Errorf("不支持的导出格式: %s（支持 csv/jsonl/xlsx）", format)
+}
+
+// exportFormatFromPath 按输出文件扩展名推断格式，无法推断时为 csv
+func exportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	case ".xlsx":
+		return "xlsx"
+	}
+	return "csv"
+}
+
+// ------- 命令 -------
+
+var bitableRecordExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "导出全部记录为 CSV/JSONL/XLSX（自动翻页、断点续传、按字段类型展平）",
+	Long: `逐页读取数据表的全部记录，边读边写入 CSV / JSONL / XLSX，内存占用与记录数无关。
+
+列为 record_id 加字段（默认全部字段，按数据表字段顺序；--field-id 指定列及顺序）。
+单元格按字段类型展平：
+  日期 / 创建时间 / 修改时间   "2006-01-02 15:04:05"（按 --timezone）
+  人员 / 创建人 / 修改人       姓名；--user-email 输出邮箱
+  关联                         关联记录的主字段值
+  附件                         文件名
+  公式 / 查找引用 / 富文本等   按值展开为文本或列表
+  --ids 时人员、关联、附件改为输出 open_id / record_id / file_token。
+多值在 CSV / XLSX 中以逗号连接，在 JSONL 中为数组；空值在 JSONL 中省略。
+导出的 CSV（配合 --user-email）可直接用 record import 导回。
+
+--view-id 与 --filter-json 原样下发给记录列表接口，导出结果与视图 / 筛选所见一致。
+
+翻页与续传:
+  - 以 offset 翻页；导出期间有增删导致页边界平移时，重复出现的记录按 record_id 跳过，
+    但插在已读位置之前的新记录、以及已读位置之前有删除时顺延过来的记录会漏掉；
+    读完后按记录总数的变化检查，可能有漏导时输出警告——需要严格快照时导出期间暂停写入
+  - --cursor-file 每页写完并刷盘后记录进度；中断后用相同参数重跑，从中断处继续
+    （输出文件截断到最后一个完整页再追加）。完成后自动删除游标文件。xlsx 不支持续传
+
+示例:
+  feishu-cli bitable record export --base-token <bt> --table-id <tid> -o records.csv
+  feishu-cli bitable record export --base-token <bt> --table-id <tid> --view-id <vid> -o view.xlsx
+  feishu-cli bitable record export --base-token <bt> --table-id <tid> --format jsonl \
+    --filter-json '{"logic":"and","conditions":[["状态","==",["Done"]]]}' > done.jsonl
+  feishu-cli bitable record export --base-token <bt> --table-id <tid> -o all.csv --cursor-file all.cursor`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		baseToken, err := resolveBaseToken(cmd)
+		if err != nil {
+			return err
+		}
+		tableID, _ := cmd.Flags().GetString("table-id")
+		viewID, _ := cmd.Flags().GetString("view-id")
+		filterJSON, _ := cmd.Flags().GetString("filter-json")
+		format, _ := cmd.Flags().GetString("format")
+		outPath, _ := cmd.Flags().GetString("output")
+		cursorPath, _ := cmd.Flags().GetString("cursor-file")
+		pageSize, _ := cmd.Flags().GetInt("page-size")
+		tz, _ := cmd.Flags().GetString("timezone")
+		ids, _ := cmd.Flags().GetBool("ids")
+		userEmail, _ := cmd.Flags().GetBool("user-email")
+		fieldNames, err := recordSelectFields(cmd, 100)
+		if err != nil {
+			return err
+		}
+
+		if format == "" {
+			format = exportFormatFromPath(outPath)
+		}
+		format = strings.ToLower(format)
+		if format == "ndjson" {
+			format = "jsonl"
+		}
+		switch {
+		case format != "csv" && format != "jsonl" && format != "xlsx":
+			return fmt.Errorf("不支持的导出格式: %s（支持 csv/jsonl/xlsx）", format)
+		case format == "xlsx" && outPath == "":
+			return fmt.Errorf("xlsx 格式需要 -o 指定输出文件")
+		case cursorPath != "" && outPath == "":
+			return fmt.Errorf("--cursor-file 需要 -o 指定输出文件")
+		case cursorPath != "" && format == "xlsx":
+			return fmt.Errorf("xlsx 不支持断点续传，请改用 csv 或 jsonl")
+		case pageSize < 1 || pageSize > maxExportPageSize:
+			return fmt.Errorf("--page-size 需在 1-%d 之间", maxExportPageSize)
+		}
+		if filterJSON != "" {
+			if err := validateCompactJSON(filterJSON, "--filter-json"); err != nil {
+				return err
+			}
+		}
+		loc := time.Local
+		if tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return fmt.Errorf("无效的 --timezone: %w", err)
+			}
+		}
+
+		token, err := resolveIdentityToken(cmd)
+		if err != nil {
+			return err
+		}
+		ex := &recordExporter{
+			call:      newBaseV3Caller(token),
+			baseToken: baseToken,
+			tableID:   tableID,
+			params:    map[string]any{},
+			pageSize:  pageSize,
+			loc:       loc,
+			ids:       ids,
+			userEmail: userEmail,
+			retry: client.RetryConfig{
+				MaxRetries:       5,
+				RetryOnRateLimit: true,
+				OnRetry: func(attempt int, err error, wait time.Duration) {
+					fmt.Fprintf(os.Stderr, "读取失败，%v 后第 %d 次重试: %v\n", wait.Round(time.Millisecond), attempt, err)
+				},
+			},
+		}
+		if viewID != "" {
+			ex.params["view_id"] = viewID
+		}
+		if filterJSON != "" {
+			ex.params["filter"] = filterJSON
+		}
+
+		cur := &exportCursor{BaseToken: baseToken, TableID: tableID, ViewID: viewID, Filter: filterJSON, Format: format, Output: outPath}
+		var saved *exportCursor
+		if cursorPath != "" {
+			if saved, err = loadExportCursor(cursorPath); err != nil {
+				return err
+			}
+			if saved != nil && !saved.sameTask(cur) {
+				return fmt.Errorf("游标文件 %s 属于另一个导出任务（%s → %s），请换一个游标文件或删除它", cursorPath, saved.TableID, saved.Output)
+			}
+		}
+		if saved != nil {
+			// 续传沿用首次导出的列，保证前后表头一致
+			fieldNames = saved.Columns
+		}
+		if err := ex.prepare(fieldNames); err != nil {
+			return err
+		}
+		cur.Columns = ex.columnNames()
+
+		var out io.Writer = os.Stdout
+		var file *os.File
+		if outPath != "" {
+			if saved != nil {
+				file, err = os.OpenFile(outPath, os.O_WRONLY, 0644)
+				if err != nil {
+					return fmt.Errorf("续传需要已有的输出文件: %w", err)
+				}
+				info, err := file.Stat()
+				if err == nil && info.Size() < saved.Bytes {
+					err = fmt.Errorf("输出文件只有 %d 字节，少于游标记录的 %d 字节", info.Size(), saved.Bytes)
+				}
+				if err == nil {
+					err = file.Truncate(saved.Bytes)
+				}
+				if err == nil {
+					_, err = file.Seek(saved.Bytes, io.SeekStart)
+				}
+				if err != nil {
+					file.Close()
+					return fmt.Errorf("续传失败: %w", err)
+				}
+				cur = saved
+				fmt.Fprintf(os.Stderr, "从游标续传: 已导出 %d 条，offset=%d\n", cur.Exported, cur.Offset)
+			} else if file, err = os.Create(outPath); err != nil {
+				return fmt.Errorf("创建输出文件失败: %w", err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		w, err := newRecordRowWriter(format, out, cur.Columns, tableID)
+		if err != nil {
+			return err
+		}
+		if saved == nil {
+			if err := w.header(cur.Columns); err != nil {
+				return err
+			}
+		}
+		var checkpoint func(*exportCursor) error
+		if file != nil {
+			checkpoint = func(c *exportCursor) error {
+				if cursorPath != "" {
+					if err := file.Sync(); err != nil {
+						return err
+					}
+					pos, err := file.Seek(0, io.SeekCurrent)
+					if err != nil {
+						return err
+					}
+					c.Bytes = pos
+					if err := saveExportCursor(cursorPath, c); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(os.Stderr, "已导出 %d 条\n", c.Exported)
+				return nil
+			}
+		}
+		stats, err := ex.run(w, cur, checkpoint)
+		if err != nil {
+			if cursorPath != "" {
+				return fmt.Errorf("%w（进度已保存到 %s，用相同参数重跑可续传）", err, cursorPath)
+			}
+			return err
+		}
+		if err := w.close(); err != nil {
+			return fmt.Errorf("写入输出失败: %w", err)
+		}
+		if cursorPath != "" {
+			_ = os.Remove(cursorPath)
+		}
+		if outPath != "" {
+			msg := fmt.Sprintf("导出完成: %d 条记录 → %s", stats.Exported, outPath)
+			if stats.Duplicates > 0 {
+				msg += fmt.Sprintf("（翻页期间数据变动，跳过重复记录 %d 条）", stats.Duplicates)
+			}
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		if stats.MayMiss > 0 {
+			fmt.Fprintf(os.Stderr, "警告: 导出期间表中记录有删除或插入（开始时 %d 条），offset 翻页可能漏掉最多 %d 条记录；需要完整快照请暂停写入后重新导出\n",
+				cur.Total, stats.MayMiss)
+		}
+		return nil
+	},
+}
+
+func init() {
+	bitableRecordCmd.AddCommand(bitableRecordExportCmd)
+	addBaseTokenFlag(bitableRecordExportCmd)
+	bitableRecordExportCmd.Flags().String("table-id", "", "table_id（必填）")
+	bitableRecordExportCmd.Flags().String("user-access-token", "", "User Access Token")
+	bitableRecordExportCmd.Flags().String("view-id", "", "只导出该视图中的记录")
+	bitableRecordExportCmd.Flags().String("filter-json", "", "结构化过滤 JSON（同 record list --filter-json）")
+	bitableRecordExportCmd.Flags().StringArray("field-id", nil, "导出的字段及顺序（字段名或字段 ID，可重复，默认全部）")
+	bitableRecordExportCmd.Flags().String("format", "", "导出格式（csv/jsonl/xlsx，默认按 -o 扩展名推断，否则 csv）")
+	bitableRecordExportCmd.Flags().StringP("output", "o", "", "输出文件（默认 stdout；xlsx 必填）")
+	bitableRecordExportCmd.Flags().String("cursor-file", "", "断点续传游标文件（需配合 -o）")
+	bitableRecordExportCmd.Flags().Int("page-size", 200, "每页记录数（1-500）")
+	bitableRecordExportCmd.Flags().String("timezone", "", "日期输出时区（例：Asia/Shanghai，默认本机时区）")
+	bitableRecordExportCmd.Flags().Bool("ids", false, "人员/关联/附件输出 open_id/record_id/file_token")
+	bitableRecordExportCmd.Flags().Bool("user-email", false, "人员输出邮箱（便于 record import 导回）")
+	mustMarkFlagRequired(bitableRecordExportCmd, "table-id")
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+)
+
+// seedExportBase 在 mock 中建「订单」表（tblO）与被关联的「客户」表（tblC，只有一条「甲公司」），
+// 返回调用器、tblO 字段名 → 字段 ID，以及甲公司的 record_id
+func seedExportBase(t *testing.T) (baseV3Caller, map[string]string, string) {
+	t.Helper()
+	_, call := newMockBaseV3(t)
+	fieldIDs := map[string]string{}
+	for _, f := range []map[string]any{
+		{"name": "单号", "type": "text"}, // 未标记主字段时第一个字段即主字段
+		{"name": "金额", "type": "number"},
+		{"name": "下单", "type": "datetime"},
+		{"name": "负责人", "type": "user"},
+		{"name": "客户", "type": "link", "link_table": "tblC"},
+		{"name": "附件", "type": "attachment"},
+		{"name": "标签", "type": "select", "multiple": true},
+		{"name": "客户等级", "type": "lookup"},
+	} {
+		data, err := call("POST", bitableFieldPath("bas1", "tblO"), nil, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fieldIDs[f["name"].(string)] = pickString(data, "id")
+	}
+	if _, err := call("POST", bitableFieldPath("bas1", "tblC"), nil, map[string]any{"name": "客户名", "type": "text"}); err != nil {
+		t.Fatal(err)
+	}
+	customer := addTestRecords(t, call, "tblC", map[string]any{"客户名": []any{map[string]any{"text": "甲公司"}}})
+	return call, fieldIDs, customer[0]
+}
+
+// addTestRecords 向 bas1 的数据表追加记录，返回新记录的 record_id
+func addTestRecords(t *testing.T, call baseV3Caller, tableID string, records ...map[string]any) []string {
+	t.Helper()
+	data, err := call("POST", bitableRecordPath("bas1", tableID, "batch_create"), nil, map[string]any{"create_records": records})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, id := range data["record_id_list"].([]any) {
+		ids = append(ids, id.(string))
+	}
+	return ids
+}
+
+func newTestExporter(call baseV3Caller, pageSize int) *recordExporter {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	return &recordExporter{
+		call:      call,
+		baseToken: "bas1",
+		tableID:   "tblO",
+		pageSize:  pageSize,
+		loc:       loc,
+		retry:     client.RetryConfig{MaxRetries: 1},
+	}
+}
+
+func TestRecordExporterFlatten(t *testing.T) {
+	call, fieldIDs, customer := seedExportBase(t)
+	rec := addTestRecords(t, call, "tblO", map[string]any{
+		"单号": []any{map[string]any{"text": "A-"}, map[string]any{"text": "001", "link": "https://x"}},
+		"金额": 12.5,
+		"下单": int64(1767283200000),
+		"负责人": []any{
+			map[string]any{"id": "ou_1", "name": "张三", "email": "zs@example.com"},
+			map[string]any{"id": "ou_2", "name": "李四"},
+		},
+		"客户":   []any{map[string]any{"id": customer}, map[string]any{"id": "recGone"}},
+		"附件":   []any{map[string]any{"file_token": "box1", "name": "合同.pdf"}},
+		"标签":   []any{"加急", "大客户"},
+		"客户等级": map[string]any{"type": "select", "value": []any{"A"}},
+	})[0]
+	cases := []struct {
+		ids, email bool
+		want       string
+	}{
+		{false, false, rec + ",A-001,12.5,2026-01-02 00:00:00,\"张三,李四\",\"甲公司,recGone\",合同.pdf,\"加急,大客户\",A\n"},
+		{false, true, rec + ",A-001,12.5,2026-01-02 00:00:00,\"zs@example.com,李四\",\"甲公司,recGone\",合同.pdf,\"加急,大客户\",A\n"},
+		{true, false, rec + ",A-001,12.5,2026-01-02 00:00:00,\"ou_1,ou_2\",\"" + customer + ",recGone\",box1,\"加急,大客户\",A\n"},
+	}
+	for _, tc := range cases {
+		ex := newTestExporter(call, 100)
+		ex.ids, ex.userEmail = tc.ids, tc.email
+		if err := ex.prepare(nil); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		w, _ := newRecordRowWriter("csv", &buf, ex.columnNames(), "")
+		_ = w.header(ex.columnNames())
+		if _, err := ex.run(w, &exportCursor{}, nil); err != nil {
+			t.Fatal(err)
+		}
+		_ = w.close()
+		if want := "record_id,单号,金额,下单,负责人,客户,附件,标签,客户等级\n" + tc.want; buf.String() != want {
+			t.Errorf("ids=%v email=%v\n got %q\nwant %q", tc.ids, tc.email, buf.String(), want)
+		}
+	}
+
+	// JSONL：record_id 在前、按列顺序、多值为数组、空值省略
+	ex := newTestExporter(call, 100)
+	if err := ex.prepare([]string{fieldIDs["金额"], "标签", "单号"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, _ := newRecordRowWriter("jsonl", &buf, ex.columnNames(), "")
+	rec2 := addTestRecords(t, call, "tblO", map[string]any{"单号": "<B>"})[0]
+	if _, err := ex.run(w, &exportCursor{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"record_id":"` + rec + `","金额":12.5,"标签":["加急","大客户"],"单号":"A-001"}` + "\n" + `{"record_id":"` + rec2 + `","单号":"<B>"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("jsonl =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	if err := newTestExporter(call, 100).prepare([]string{"不存在"}); err == nil || !strings.Contains(err.Error(), `没有字段 "不存在"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRecordExporterPagingAndResume(t *testing.T) {
+	call, _, _ := seedExportBase(t)
+	var records []map[string]any
+	for i := 1; i <= 7; i++ {
+		records = append(records, map[string]any{"单号": fmt.Sprintf("A%d", i)})
+	}
+	recs := addTestRecords(t, call, "tblO", records...)
+
+	// 读完第一页后表头插入一条新记录：之后每个 offset 读到的记录整体后移一位，第二页会再次读到第 3 条
+	var pages []string
+	shifted := func(method, path string, params map[string]any, body any) (map[string]any, error) {
+		if offset, ok := params["offset"].(int); ok && path == bitableRecordPath("bas1", "tblO") {
+			pages = append(pages, fmt.Sprint(offset))
+			if offset > 0 {
+				params["offset"] = offset - 1
+			}
+		}
+		return call(method, path, params, body)
+	}
+	ex := newTestExporter(shifted, 3)
+	if err := ex.prepare([]string{"单号"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	w, _ := newRecordRowWriter("csv", &out, ex.columnNames(), "")
+	_ = w.header(ex.columnNames())
+	var saved exportCursor
+	n := 0
+	_, err := ex.run(w, &exportCursor{}, func(c *exportCursor) error {
+		saved = *c
+		saved.Bytes = int64(out.Len())
+		if n++; n == 2 {
+			return fmt.Errorf("模拟中断")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("应在第二页后中断")
+	}
+	if saved.Offset != 6 || saved.Exported != 5 || !reflect.DeepEqual(saved.LastIDs, recs[2:5]) {
+		t.Fatalf("cursor = %+v", saved)
+	}
+
+	// 续传：从 offset 6 继续，不重写表头
+	w, _ = newRecordRowWriter("csv", &out, ex.columnNames(), "")
+	stats, err := ex.run(w, &saved, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.close()
+	if !reflect.DeepEqual(pages, []string{"0", "3", "6"}) {
+		t.Errorf("pages = %v", pages)
+	}
+	if stats.Exported != 7 || stats.Duplicates != 0 || stats.MayMiss != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range rows {
+		ids = append(ids, r[0])
+	}
+	if want := append([]string{"record_id"}, recs...); !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	// 读完第一页后删除已导出的第 1 条：后面的记录整体前移一位，第 4 条落到已读的 offset 之前被跳过，
+	// 读完时 total 比开始时少，应报告可能漏导
+	deleted := false
+	shrunk := func(method, path string, params map[string]any, body any) (map[string]any, error) {
+		if offset, ok := params["offset"].(int); ok && offset > 0 && !deleted && path == bitableRecordPath("bas1", "tblO") {
+			deleted = true
+			if _, err := call("POST", bitableRecordPath("bas1", "tblO", "batch_delete"), nil, map[string]any{"record_id_list": recs[:1]}); err != nil {
+				return nil, err
+			}
+		}
+		return call(method, path, params, body)
+	}
+	ex = newTestExporter(shrunk, 3)
+	if err := ex.prepare([]string{"单号"}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	w, _ = newRecordRowWriter("csv", &out, ex.columnNames(), "")
+	stats, err = ex.run(w, &exportCursor{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Exported != 6 || stats.MayMiss != 1 {
+		t.Errorf("删除后 stats = %+v, want exported=6 may_miss=1", stats)
+	}
+	if strings.Contains(out.String(), recs[3]) {
+		t.Fatalf("预期第 4 条因删除被跳过，用例前提不成立: %s", out.String())
+	}
+}
+
+func TestXLSXStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	x, err := newXLSXStreamWriter(&buf, "订单/2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = x.WriteRow([]any{"a<b", int64(42), 1.5, true, nil, "line1\nline2"})
+	if err := x.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("缺少部件 %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="订单_2026"`) {
+		t.Errorf("workbook = %s", parts["xl/workbook.xml"])
+	}
+	wantRow := `<row><c t="inlineStr"><is><t xml:space="preserve">a&lt;b</t></is></c><c><v>42</v></c><c><v>1.5</v></c><c t="b"><v>1</v></c><c/><c t="inlineStr"><is><t xml:space="preserve">line1&#xA;line2</t></is></c></row>`
+	if !strings.Contains(parts["xl/worksheets/sheet1.xml"], wantRow+"</sheetData></worksheet>") {
+		t.Errorf("sheet1 = %s", parts["xl/worksheets/sheet1.xml"])
+	}
+}
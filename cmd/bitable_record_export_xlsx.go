@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Excel 单个工作表的上限
+const (
+	xlsxMaxRows     = 1048576
+	xlsxMaxCellText = 32767
+)
+
+// xlsxStreamWriter 边写边压缩单工作表的 xlsx：行数据直接写入 sheet1.xml，
+// 其余部件在 Close 时补齐，内存占用与行数无关。单元格一律内联字符串 / 数字 / 布尔，不带样式
+type xlsxStreamWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+var xlsxStaticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// newXLSXStreamWriter 在 w 上开始一个 xlsx，工作表名为 sheetName
+func newXLSXStreamWriter(w io.Writer, sheetName string) (*xlsxStreamWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		if err := writeZipPart(zw, part.name, part.body); err != nil {
+			return nil, err
+		}
+	}
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(xlsxSheetName(sheetName)))
+	if err := writeZipPart(zw, "xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`+name.String()+`" sheetId="1" r:id="rId1"/></sheets></workbook>`); err != nil {
+		return nil, err
+	}
+	// sheet1.xml 必须是最后一个打开的部件：zip.Writer 同一时刻只能写一个条目
+	sw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxStreamWriter{zw: zw, sheet: bufio.NewWriterSize(sw, 64*1024)}
+	_, err = x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x, err
+}
+
+func writeZipPart(zw *zip.Writer, name, body string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, body)
+	return err
+}
+
+// xlsxSheetName 去掉工作表名不允许的字符并截断到 31 个字符
+func xlsxSheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	if r := []rune(s); len(r) > 31 {
+		s = string(r[:31])
+	}
+	if s == "" {
+		return "Sheet1"
+	}
+	return s
+}
+
+// WriteRow 写一行；string 写为文本，整数 / 浮点写为数字，bool 写为布尔，其余按 fmt 文本化
+func (x *xlsxStreamWriter) WriteRow(cells []any) error {
+	if x.rows >= xlsxMaxRows {
+		return fmt.Errorf("超过 xlsx 单表上限 %d 行，请改用 csv 或 jsonl", xlsxMaxRows)
+	}
+	x.rows++
+	b := x.sheet
+	b.WriteString("<row>")
+	for _, v := range cells {
+		switch val := v.(type) {
+		case nil:
+			b.WriteString("<c/>")
+		case int64:
+			b.WriteString(`<c><v>` + strconv.FormatInt(val, 10) + `</v></c>`)
+		case float64:
+			b.WriteString(`<c><v>` + strconv.FormatFloat(val, 'f', -1, 64) + `</v></c>`)
+		case bool:
+			flag := "0"
+			if val {
+				flag = "1"
+			}
+			b.WriteString(`<c t="b"><v>` + flag + `</v></c>`)
+		default:
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprint(v)
+			}
+			if r := []rune(s); len(r) > xlsxMaxCellText {
+				s = string(r[:xlsxMaxCellText])
+			}
+			b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			_ = xml.EscapeText(b, []byte(s))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := b.WriteString("</row>")
+	return err
+}
+
+// Close 结束工作表并写出 zip 目录；不关闭底层 io.Writer
+func (x *xlsxStreamWriter) Close() error {
+	if _, err := x.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
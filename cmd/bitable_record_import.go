@@ -0,0 +1,938 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// 批量写入端点单次上限（官方契约 200 条）
+const maxImportBatchSize = 200
+
+// contact batch_get_id 单次最多 50 个邮箱
+const importEmailBatchSize = 50
+
+// importReadOnlyTypes 是不能写入的字段类型
+var importReadOnlyTypes = map[string]bool{
+	"formula": true, "lookup": true, "auto_number": true,
+	"created_at": true, "updated_at": true, "created_by": true, "updated_by": true,
+}
+
+// importField 是导入用到的字段元数据
+type importField struct {
+	name      string
+	typ       string
+	multiple  bool
+	options   []string // select 的选项名
+	linkTable string   // link 的目标数据表 ID
+}
+
+func newImportField(raw map[string]any) *importField {
+	f := &importField{
+		name:      pickString(raw, "name", "field_name"),
+		typ:       pickString(raw, "type"),
+		linkTable: pickString(raw, "link_table"),
+	}
+	f.multiple, _ = raw["multiple"].(bool)
+	for _, opt := range asAnySlice(raw["options"]) {
+		if m, ok := opt.(map[string]any); ok {
+			if name := pickString(m, "name"); name != "" {
+				f.options = append(f.options, name)
+			}
+		}
+	}
+	return f
+}
+
+// importRow 是输入文件中的一行；CSV 的值为字符串，JSONL 保留 JSON 类型（数字为 json.Number）
+type importRow struct {
+	line   int // 数据行序号，从 1 开始，不含 CSV 表头
+	values map[string]any
+}
+
+// importResult 是每行的导入结果，也是报告的一行
+type importResult struct {
+	Row      int    `json:"row"`
+	Key      string `json:"key,omitempty"`
+	Action   string `json:"action"` // create / update / skip
+	Status   string `json:"status"` // ok / failed / unknown / skipped / planned
+	RecordID string `json:"record_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	fields map[string]any
+}
+
+// readImportRows 读取 CSV（首行为表头）或 JSONL（每行一个对象），返回列名（按首次出现顺序）与数据行
+func readImportRows(path string) ([]string, []*importRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseImportCSV(data)
+	case ".jsonl", ".ndjson":
+		return parseImportJSONL(data)
+	}
+	return nil, nil, fmt.Errorf("不支持的文件类型 %q（仅支持 .csv / .jsonl / .ndjson）", filepath.Ext(path))
+}
+
+func parseImportCSV(data []byte) ([]string, []*importRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("CSV 文件为空")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("解析 CSV 表头失败: %w", err)
+	}
+	seen := map[string]bool{}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, nil, fmt.Errorf("CSV 表头第 %d 列为空", i+1)
+		}
+		if seen[header[i]] {
+			return nil, nil, fmt.Errorf("CSV 表头列名重复: %s", header[i])
+		}
+		seen[header[i]] = true
+	}
+	var rows []*importRow
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("解析 CSV 失败: %w", err)
+		}
+		if len(rec) > len(header) {
+			return nil, nil, fmt.Errorf("CSV 第 %d 行有 %d 列，多于表头的 %d 列", len(rows)+2, len(rec), len(header))
+		}
+		row := &importRow{line: len(rows) + 1, values: map[string]any{}}
+		for i, v := range rec {
+			row.values[header[i]] = v
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+func parseImportJSONL(data []byte) ([]string, []*importRow, error) {
+	var columns []string
+	seen := map[string]bool{}
+	var rows []*importRow
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, nil, fmt.Errorf("第 %d 行不是 JSON 对象: %w", n, err)
+		}
+		for _, k := range sortedKeys(obj) {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+		rows = append(rows, &importRow{line: len(rows) + 1, values: obj})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("读取 JSONL 失败: %w", err)
+	}
+	return columns, rows, nil
+}
+
+// recordImporter 把输入行按字段类型转换后写入数据表；call / lookupUsers 可在测试中替换
+type recordImporter struct {
+	call        baseV3Caller
+	lookupUsers func(emails []string) (map[string]string, error)
+	baseToken   string
+	tableID     string
+	keyField    string
+	loc         *time.Location
+	batchSize   int
+	retry       client.RetryConfig
+
+	fields map[string]*importField
+	users  map[string]string            // 邮箱（小写）→ open_id
+	links  map[string]map[string]string // 关联表 ID → 主字段文本 → record_id；重复的主字段文本记为 ""
+}
+
+// lookupUsersByEmail 通过通讯录按邮箱批量查 open_id
+func lookupUsersByEmail(emails []string) (map[string]string, error) {
+	out := map[string]string{}
+	for start := 0; start < len(emails); start += importEmailBatchSize {
+		end := min(start+importEmailBatchSize, len(emails))
+		users, err := client.BatchGetUserID(emails[start:end], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if u.UserID != "" && u.Email != "" {
+				out[strings.ToLower(u.Email)] = u.UserID
+			}
+		}
+	}
+	return out, nil
+}
+
+// prepare 读取字段元数据并校验列，再预先解析人员邮箱与关联表的主字段
+func (im *recordImporter) prepare(columns []string, rows []*importRow) error {
+	raw, err := listBaseV3All(im.call, bitableFieldPath(im.baseToken, im.tableID), nil)
+	if err != nil {
+		return fmt.Errorf("读取字段列表失败: %w", err)
+	}
+	im.fields = map[string]*importField{}
+	for _, r := range raw {
+		f := newImportField(r)
+		im.fields[f.name] = f
+	}
+
+	var unknown, readOnly []string
+	for _, col := range columns {
+		f := im.fields[col]
+		switch {
+		case f == nil:
+			unknown = append(unknown, col)
+		case importReadOnlyTypes[f.typ]:
+			readOnly = append(readOnly, fmt.Sprintf("%s(%s)", col, f.typ))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("数据表中不存在这些列对应的字段: %s", strings.Join(unknown, ", "))
+	}
+	if len(readOnly) > 0 {
+		return fmt.Errorf("这些字段为只读类型，不能导入: %s", strings.Join(readOnly, ", "))
+	}
+	if im.keyField != "" && !slices.Contains(columns, im.keyField) {
+		return fmt.Errorf("--key-field %q 不在文件的列中", im.keyField)
+	}
+
+	var emails []string
+	seen := map[string]bool{}
+	for _, col := range columns {
+		f := im.fields[col]
+		for _, row := range rows {
+			v, ok := row.values[col]
+			if !ok {
+				continue
+			}
+			switch f.typ {
+			case "user":
+				for _, item := range splitImportList(v) {
+					if s, ok := item.(string); ok && strings.Contains(s, "@") && !seen[strings.ToLower(s)] {
+						seen[strings.ToLower(s)] = true
+						emails = append(emails, s)
+					}
+				}
+			case "link":
+				if err := im.loadLinkTable(f.linkTable); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	im.users = map[string]string{}
+	if len(emails) > 0 {
+		if im.users, err = im.lookupUsers(emails); err != nil {
+			return fmt.Errorf("按邮箱查询用户失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadLinkTable 读取关联表全部记录的主字段，建立主字段文本到 record_id 的索引
+func (im *recordImporter) loadLinkTable(tableID string) error {
+	if tableID == "" {
+		return nil
+	}
+	if _, ok := im.links[tableID]; ok {
+		return nil
+	}
+	primary, err := primaryFieldName(im.call, im.baseToken, tableID)
+	if err != nil {
+		return err
+	}
+	index, err := im.indexRecords(tableID, primary)
+	if err != nil {
+		return fmt.Errorf("读取关联表 %s 的记录失败: %w", tableID, err)
+	}
+	if im.links == nil {
+		im.links = map[string]map[string]string{}
+	}
+	im.links[tableID] = index
+	return nil
+}
+
+// primaryFieldName 返回数据表主字段的名称；接口未标记主字段时取第一个字段
+func primaryFieldName(call baseV3Caller, baseToken, tableID string) (string, error) {
+	fields, err := listBaseV3All(call, bitableFieldPath(baseToken, tableID), nil)
+	if err != nil {
+		return "", fmt.Errorf("读取关联表 %s 的字段失败: %w", tableID, err)
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("关联表 %s 没有字段", tableID)
+	}
+	primary := fields[0]
+	for _, f := range fields {
+		if f["is_primary"] == true {
+			primary = f
+			break
+		}
+	}
+	return pickString(primary, "name", "field_name"), nil
+}
+
+// indexRecords 读取数据表全部记录的某个字段，返回字段文本 → record_id；同一文本对应多条记录时值为 ""
+func (im *recordImporter) indexRecords(tableID, field string) (map[string]string, error) {
+	records, err := listBaseV3All(im.call, bitableRecordPath(im.baseToken, tableID), map[string]any{"field_id": []string{field}})
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]string{}
+	for _, rec := range records {
+		fields, _ := rec["fields"].(map[string]any)
+		text := importCellText(fields[field])
+		if text == "" {
+			continue
+		}
+		if _, dup := index[text]; dup {
+			index[text] = ""
+			continue
+		}
+		index[text] = pickString(rec, "record_id", "id")
+	}
+	return index, nil
+}
+
+// plan 转换每行的值并决定新建还是更新；转换失败的行直接记为 failed
+func (im *recordImporter) plan(rows []*importRow) ([]*importResult, error) {
+	var existing map[string]string
+	if im.keyField != "" {
+		var err error
+		if existing, err = im.indexRecords(im.tableID, im.keyField); err != nil {
+			return nil, fmt.Errorf("读取已有记录失败: %w", err)
+		}
+	}
+	firstRow := map[string]int{}
+	results := make([]*importResult, 0, len(rows))
+	for _, row := range rows {
+		res := &importResult{Row: row.line, fields: map[string]any{}}
+		results = append(results, res)
+
+		var errs []string
+		for _, col := range sortedKeys(row.values) {
+			v, err := im.coerce(im.fields[col], row.values[col])
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", col, err))
+				continue
+			}
+			if v != nil {
+				res.fields[col] = v
+			}
+		}
+		if im.keyField != "" {
+			res.Key = importCellText(res.fields[im.keyField])
+		}
+		switch {
+		case len(errs) > 0:
+			res.fail(strings.Join(errs, "; "))
+		case len(res.fields) == 0:
+			res.Action, res.Status = "skip", "skipped"
+		case im.keyField == "":
+			res.Action = "create"
+		case res.Key == "":
+			res.fail("键字段 " + im.keyField + " 为空")
+		case firstRow[res.Key] > 0:
+			res.fail(fmt.Sprintf("键值与第 %d 行重复", firstRow[res.Key]))
+		default:
+			firstRow[res.Key] = row.line
+			id, found := existing[res.Key]
+			switch {
+			case !found:
+				res.Action = "create"
+			case id == "":
+				res.fail("键值匹配到多条已有记录")
+			default:
+				res.Action, res.RecordID = "update", id
+			}
+		}
+	}
+	return results, nil
+}
+
+func (r *importResult) fail(msg string) {
+	r.Status, r.Error = "failed", msg
+}
+
+// execute 分批调用 batch_create / batch_update；单批失败只影响该批的行。
+// batch_update 按 record_id 覆盖写，重复发送无副作用，可照常重试
+func (im *recordImporter) execute(results []*importResult) {
+	var creates, updates []*importResult
+	for _, r := range results {
+		if r.Status != "" {
+			continue
+		}
+		switch r.Action {
+		case "create":
+			creates = append(creates, r)
+		case "update":
+			updates = append(updates, r)
+		}
+	}
+	for start := 0; start < len(creates); start += im.batchSize {
+		im.create(creates[start:min(start+im.batchSize, len(creates))], true)
+	}
+	for start := 0; start < len(updates); start += im.batchSize {
+		batch := updates[start:min(start+im.batchSize, len(updates))]
+		records := map[string]any{}
+		for _, r := range batch {
+			records[r.RecordID] = r.fields
+		}
+		_, err := im.write("batch_update", map[string]any{"update_records": records}, im.retry)
+		for _, r := range batch {
+			if err != nil {
+				r.fail(err.Error())
+			} else {
+				r.Status = "ok"
+			}
+		}
+	}
+}
+
+// create 新建一批记录。batch_create 不是幂等的，只在限流（请求必然未处理）时重试；
+// 5xx、超时等结果未知的错误，有键字段时按键值回查已写入的行，其余行再发一次（recheck=false 时不再回查），
+// 没有键字段时整批记为 unknown，交由用户核对，避免重复新建。
+func (im *recordImporter) create(batch []*importResult, recheck bool) {
+	records := make([]any, len(batch))
+	for i, r := range batch {
+		records[i] = r.fields
+	}
+	cfg := im.retry
+	cfg.IsPermanent = func(err error) bool { return !client.IsRateLimitError(err) }
+	data, err := im.write("batch_create", map[string]any{"create_records": records}, cfg)
+	if err != nil {
+		if !createOutcomeUnknown(err) {
+			for _, r := range batch {
+				r.fail(err.Error())
+			}
+			return
+		}
+		if !recheck || im.keyField == "" {
+			for _, r := range batch {
+				r.Status, r.Error = "unknown", "写入结果未知，重跑前请核对数据表以免重复新建: "+err.Error()
+			}
+			return
+		}
+		existing, qerr := im.indexRecords(im.tableID, im.keyField)
+		if qerr != nil {
+			for _, r := range batch {
+				r.Status, r.Error = "unknown", fmt.Sprintf("写入结果未知且回查失败: %v; 回查: %v", err, qerr)
+			}
+			return
+		}
+		var rest []*importResult
+		for _, r := range batch {
+			switch id, found := existing[r.Key]; {
+			case !found:
+				rest = append(rest, r)
+			case id == "":
+				r.fail("写入结果未知，回查时键值匹配到多条记录: " + err.Error())
+			default:
+				r.Status, r.RecordID = "ok", id
+			}
+		}
+		if len(rest) > 0 {
+			im.create(rest, false)
+		}
+		return
+	}
+	ids := asAnySlice(data["record_id_list"])
+	for i, r := range batch {
+		if i < len(ids) {
+			r.Status, r.RecordID = "ok", fmt.Sprint(ids[i])
+		} else {
+			r.fail("响应中缺少该行的 record_id")
+		}
+	}
+}
+
+// createOutcomeUnknown 判断写入错误后服务端是否可能已经写入：限流、4xx 与业务错误码表示请求被拒绝，
+// 5xx、网络错误、响应无法解析等则无法确定
+func createOutcomeUnknown(err error) bool {
+	if client.IsRateLimitError(err) {
+		return false
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "base/v3 API 失败: code=") {
+		return false
+	}
+	var status int
+	if i := strings.Index(msg, "base/v3 API HTTP "); i >= 0 {
+		if _, scanErr := fmt.Sscanf(msg[i:], "base/v3 API HTTP %d", &status); scanErr == nil && status < 500 {
+			return false
+		}
+	}
+	return true
+}
+
+// write 带重试地调用一次批量写入端点：限流不计入重试次数，其余可重试错误最多重试 MaxRetries 次
+func (im *recordImporter) write(endpoint string, body any, cfg client.RetryConfig) (map[string]any, error) {
+	path := bitableRecordPath(im.baseToken, im.tableID, endpoint)
+	res := client.DoWithRetry(func() (map[string]any, http.Header, error) {
+		data, err := im.call("POST", path, nil, body)
+		return data, nil, err
+	}, cfg)
+	return res.Value, res.Err
+}
+
+// ------- 值转换 -------
+
+// coerce 按字段类型转换单元格的值；空值返回 nil（该字段不写入）
+func (im *recordImporter) coerce(f *importField, v any) (any, error) {
+	if s, ok := v.(string); ok {
+		v = strings.TrimSpace(s)
+	}
+	if isEmptySchemaValue(v) || v == "" {
+		return nil, nil
+	}
+	switch f.typ {
+	case "number":
+		return coerceImportNumber(v)
+	case "datetime", "date":
+		return coerceImportTime(v, im.loc)
+	case "checkbox":
+		return coerceImportBool(v)
+	case "select":
+		return coerceImportSelect(f, v)
+	case "user":
+		return im.coerceRefs(v, func(s string) (string, error) {
+			switch {
+			case strings.HasPrefix(s, "ou_"):
+				return s, nil
+			case strings.Contains(s, "@"):
+				if id := im.users[strings.ToLower(s)]; id != "" {
+					return id, nil
+				}
+				return "", fmt.Errorf("通讯录中找不到邮箱 %s", s)
+			}
+			return "", fmt.Errorf("无法识别的人员 %q（需为邮箱或 open_id）", s)
+		})
+	case "link":
+		return im.coerceRefs(v, func(s string) (string, error) {
+			id, found := im.links[f.linkTable][s]
+			switch {
+			case found && id == "":
+				return "", fmt.Errorf("关联表中主字段为 %q 的记录不止一条", s)
+			case found:
+				return id, nil
+			case strings.HasPrefix(s, "rec"):
+				return s, nil
+			}
+			return "", fmt.Errorf("关联表中找不到主字段为 %q 的记录", s)
+		})
+	case "attachment":
+		return nil, fmt.Errorf("附件字段不支持导入，请用 record upload-attachment")
+	case "text":
+		if _, ok := v.(string); !ok {
+			return importCellText(v), nil
+		}
+	}
+	return v, nil
+}
+
+func coerceImportNumber(v any) (any, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64, int64:
+		return n, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(n, ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q 不是数字", n)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("无法转换为数字: %v", v)
+}
+
+// importTimeLayouts 是日期字段接受的文本格式，按 --timezone 解释；RFC 3339 自带时区
+var importTimeLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	"2006/01/02 15:04:05", "2006/01/02 15:04", "2006/01/02",
+	"2006-01-02T15:04:05", "2006-01-02T15:04",
+}
+
+// coerceImportTime 把日期转换为毫秒时间戳；纯数字视为时间戳，不超过 10 位按秒处理
+func coerceImportTime(v any, loc *time.Location) (any, error) {
+	s := importCellText(v)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(strings.TrimPrefix(s, "-")) <= 10 {
+			n *= 1000
+		}
+		return n, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UnixMilli(), nil
+	}
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return nil, fmt.Errorf("无法识别的日期 %q（支持 2006-01-02[ 15:04[:05]]、RFC 3339 或时间戳）", s)
+}
+
+func coerceImportBool(v any) (any, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	switch strings.ToLower(importCellText(v)) {
+	case "true", "1", "yes", "y", "是", "✓", "√":
+		return true, nil
+	case "false", "0", "no", "n", "否":
+		return false, nil
+	}
+	return nil, fmt.Errorf("无法转换为复选框: %v", v)
+}
+
+// coerceImportSelect 校验选项名：批量端点遇到未知选项会整批失败，因此提前逐行拦下
+func coerceImportSelect(f *importField, v any) (any, error) {
+	var names []string
+	for _, item := range splitImportList(v) {
+		name := importCellText(item)
+		if !slices.Contains(f.options, name) {
+			return nil, fmt.Errorf("选项 %q 不存在（可选: %s）", name, strings.Join(f.options, ", "))
+		}
+		names = append(names, name)
+	}
+	if f.multiple {
+		return names, nil
+	}
+	if len(names) > 1 {
+		return nil, fmt.Errorf("单选字段只能有一个值，得到 %d 个", len(names))
+	}
+	return names[0], nil
+}
+
+// coerceRefs 把人员/关联的值转换为 [{"id":...}]；已是该形态的 JSON 值原样写入
+func (im *recordImporter) coerceRefs(v any, resolve func(string) (string, error)) (any, error) {
+	var refs []any
+	for _, item := range splitImportList(v) {
+		if m, ok := item.(map[string]any); ok {
+			refs = append(refs, m)
+			continue
+		}
+		id, err := resolve(importCellText(item))
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, map[string]any{"id": id})
+	}
+	return refs, nil
+}
+
+// splitImportList 把多值拆开：JSON 数组按元素，文本按中英文逗号
+func splitImportList(v any) []any {
+	if list, ok := v.([]any); ok {
+		return list
+	}
+	s, ok := v.(string)
+	if !ok {
+		return []any{v}
+	}
+	var out []any
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// importCellText 取单元格的文本表示，用于键值匹配与关联表主字段匹配
+func importCellText(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(val)
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case bool:
+		return strconv.FormatBool(val)
+	case []string:
+		return strings.Join(val, ",")
+	case []any:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			if s := importCellText(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		return pickString(val, "text", "name", "id")
+	}
+	return fmt.Sprint(v)
+}
+
+func asAnySlice(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+// ------- 报告 -------
+
+type importSummary struct {
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	Unknown int `json:"unknown"` // 新建请求结果未知（5xx/超时且无法按键值回查）的行
+}
+
+func summarizeImport(results []*importResult) importSummary {
+	s := importSummary{Total: len(results)}
+	for _, r := range results {
+		switch {
+		case r.Status == "failed":
+			s.Failed++
+		case r.Status == "unknown":
+			s.Unknown++
+		case r.Status == "skipped":
+			s.Skipped++
+		case r.Action == "create":
+			s.Created++
+		case r.Action == "update":
+			s.Updated++
+		}
+	}
+	return s
+}
+
+// writeImportReport 写出逐行报告：.csv 为 CSV，.json 为 JSON 数组，其余为 JSONL
+func writeImportReport(path string, results []*importResult) error {
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"row", "key", "action", "status", "record_id", "error"})
+		for _, r := range results {
+			_ = w.Write([]string{strconv.Itoa(r.Row), r.Key, r.Action, r.Status, r.RecordID, r.Error})
+		}
+		w.Flush()
+	case ".json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	default:
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入报告失败: %w", err)
+	}
+	return nil
+}
+
+// ------- 命令 -------
+
+var bitableRecordImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "从 CSV/JSONL 批量导入记录（按字段类型转换，可按键字段 upsert）",
+	Long: `读取数据表字段定义，把 CSV（首行为表头）或 JSONL（每行一个对象）逐列转换为字段类型，
+分批调用 batch_create / batch_update 写入，并输出逐行结果报告。
+
+列名必须与字段名一致；空单元格不写入（不会清空已有值）。值按字段类型转换：
+  number     "1,234.5" → 1234.5
+  datetime   2026-01-02 / 2026-01-02 15:04[:05]（按 --timezone 解释）、RFC 3339、秒或毫秒时间戳 → 毫秒时间戳
+  checkbox   true/false、1/0、yes/no、是/否
+  select     选项名；多选用逗号分隔。未知选项在本地拦下，不发请求
+  user       邮箱（通讯录查 open_id）或 ou_ 开头的 open_id；多个用逗号分隔
+  link       关联表中记录的主字段值（或 rec 开头的 record_id）；多个用逗号分隔
+JSONL 中已是目标格式的值（数组、[{"id":...}] 等）原样写入。
+公式、查找引用、自动编号等只读字段与附件字段不能导入。
+
+--key-field 指定键字段：键值已存在的行更新该记录，不存在的行新建；不指定时全部新建。
+文件中键值重复、匹配到多条已有记录、或任一列转换失败的行记为 failed，其余行照常写入。
+写入遇到限流时自动退避重试；batch_update 遇到 5xx 也会重试，batch_create 不是幂等的，
+遇到 5xx、超时时不盲目重发：有 --key-field 时先按键值回查已写入的行、只补发未写入的行，
+否则该批记为 unknown（结果未知，需人工核对）。单批最终失败只影响该批的行。有失败或结果未知的行时命令以非零状态退出。
+
+--report 写出逐行报告（row/key/action/status/record_id/error）：.csv 为 CSV，.json 为 JSON 数组，其余为 JSONL。
+
+示例:
+  feishu-cli bitable record import --base-token <bt> --table-id <tid> --file staff.csv --key-field 工号
+  feishu-cli bitable record import --base-token <bt> --table-id <tid> --file data.jsonl --dry-run --report plan.csv
+  feishu-cli bitable record import --base-token <bt> --table-id <tid> --file data.csv --timezone UTC --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		baseToken, err := resolveBaseToken(cmd)
+		if err != nil {
+			return err
+		}
+		tableID, _ := cmd.Flags().GetString("table-id")
+		filePath, _ := cmd.Flags().GetString("file")
+		keyField, _ := cmd.Flags().GetString("key-field")
+		tz, _ := cmd.Flags().GetString("timezone")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		reportPath, _ := cmd.Flags().GetString("report")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		outOpts, structured, err := resolveMarkdownDiffOutput(cmd)
+		if err != nil {
+			return err
+		}
+		if batchSize < 1 || batchSize > maxImportBatchSize {
+			return fmt.Errorf("--batch-size 需在 1-%d 之间", maxImportBatchSize)
+		}
+		loc := time.Local
+		if tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return fmt.Errorf("无效的 --timezone: %w", err)
+			}
+		}
+
+		columns, rows, err := readImportRows(filePath)
+		if err != nil {
+			return err
+		}
+		token, err := resolveIdentityToken(cmd)
+		if err != nil {
+			return err
+		}
+		im := &recordImporter{
+			call:        newBaseV3Caller(token),
+			lookupUsers: lookupUsersByEmail,
+			baseToken:   baseToken,
+			tableID:     tableID,
+			keyField:    keyField,
+			loc:         loc,
+			batchSize:   batchSize,
+			retry: client.RetryConfig{
+				MaxRetries:       5,
+				RetryOnRateLimit: true,
+				OnRetry: func(attempt int, err error, wait time.Duration) {
+					fmt.Fprintf(os.Stderr, "写入失败，%v 后第 %d 次重试: %v\n", wait.Round(time.Millisecond), attempt, err)
+				},
+			},
+		}
+		if err := im.prepare(columns, rows); err != nil {
+			return err
+		}
+		results, err := im.plan(rows)
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			for _, r := range results {
+				if r.Status == "" {
+					r.Status = "planned"
+				}
+			}
+		} else {
+			im.execute(results)
+		}
+
+		if reportPath != "" {
+			if err := writeImportReport(reportPath, results); err != nil {
+				return err
+			}
+		}
+		summary := summarizeImport(results)
+		if structured {
+			if err := output.Render(outOpts, map[string]any{
+				"dry_run": dryRun,
+				"summary": summary,
+				"rows":    results,
+			}); err != nil {
+				return err
+			}
+		} else {
+			verb := "导入完成"
+			if dryRun {
+				verb = "预览（未写入）"
+			}
+			fmt.Printf("%s: 共 %d 行，新建 %d，更新 %d，跳过 %d，失败 %d\n",
+				verb, summary.Total, summary.Created, summary.Updated, summary.Skipped, summary.Failed)
+			if summary.Unknown > 0 {
+				fmt.Printf("另有 %d 行新建结果未知（服务端出错且无法回查），重跑前请先核对数据表\n", summary.Unknown)
+			}
+			shown := 0
+			for _, r := range results {
+				if (r.Status == "failed" || r.Status == "unknown") && shown < 20 {
+					fmt.Printf("  第 %d 行: %s\n", r.Row, r.Error)
+					shown++
+				}
+			}
+			if summary.Failed+summary.Unknown > shown {
+				fmt.Printf("  ……其余 %d 行见 --report\n", summary.Failed+summary.Unknown-shown)
+			}
+			if reportPath != "" {
+				fmt.Printf("逐行报告已写入 %s\n", reportPath)
+			}
+		}
+		if summary.Failed+summary.Unknown > 0 {
+			return fmt.Errorf("%d 行导入失败，%d 行结果未知", summary.Failed, summary.Unknown)
+		}
+		return nil
+	},
+}
+
+func init() {
+	bitableRecordCmd.AddCommand(bitableRecordImportCmd)
+	addBaseTokenFlag(bitableRecordImportCmd)
+	bitableRecordImportCmd.Flags().String("table-id", "", "table_id（必填）")
+	bitableRecordImportCmd.Flags().String("user-access-token", "", "User Access Token")
+	bitableRecordImportCmd.Flags().StringP("file", "f", "", "输入文件（.csv / .jsonl / .ndjson）")
+	bitableRecordImportCmd.Flags().String("key-field", "", "upsert 的键字段名（不指定则全部新建）")
+	bitableRecordImportCmd.Flags().String("timezone", "", "日期文本的时区（例：Asia/Shanghai，默认本机时区）")
+	bitableRecordImportCmd.Flags().Int("batch-size", maxImportBatchSize, "每批写入条数（1-200）")
+	bitableRecordImportCmd.Flags().String("report", "", "逐行结果报告文件（.csv / .json / .jsonl）")
+	bitableRecordImportCmd.Flags().Bool("dry-run", false, "只转换并规划，不写入")
+	output.AddFormatFlags(bitableRecordImportCmd)
+	mustMarkFlagRequired(bitableRecordImportCmd, "table-id", "file")
+}
@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/mock"
+)
+
+// seedImportBase 在 mock 中建「员工」表（tblE）与被关联的「部门」表（tblD），
+// 返回调用器及预置记录的 record_id（键为 D1..D3、E1..E2，按创建顺序）
+func seedImportBase(t *testing.T) (*mock.Server, baseV3Caller, map[string]string) {
+	t.Helper()
+	srv, call := newMockBaseV3(t)
+	mustCall := func(method, path string, body any) map[string]any {
+		t.Helper()
+		data, err := call(method, path, nil, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	for _, f := range []map[string]any{
+		{"name": "工号", "type": "text"}, // 未标记主字段时第一个字段即主字段
+		{"name": "姓名", "type": "text"},
+		{"name": "薪资", "type": "number"},
+		{"name": "入职", "type": "datetime"},
+		{"name": "在职", "type": "checkbox"},
+		{"name": "级别", "type": "select", "options": []any{map[string]any{"name": "P5"}, map[string]any{"name": "P6"}}},
+		{"name": "技能", "type": "select", "multiple": true, "options": []any{map[string]any{"name": "Go"}, map[string]any{"name": "SQL"}}},
+		{"name": "主管", "type": "user"},
+		{"name": "部门", "type": "link", "link_table": "tblD"},
+		{"name": "编号", "type": "auto_number"},
+	} {
+		mustCall("POST", bitableFieldPath("bas1", "tblE"), f)
+	}
+	mustCall("POST", bitableFieldPath("bas1", "tblD"), map[string]any{"name": "部门名", "type": "text"})
+
+	ids := map[string]string{}
+	seed := func(table, prefix string, records ...map[string]any) {
+		data := mustCall("POST", bitableRecordPath("bas1", table, "batch_create"), map[string]any{"create_records": records})
+		for i, id := range data["record_id_list"].([]any) {
+			ids[fmt.Sprintf("%s%d", prefix, i+1)] = id.(string)
+		}
+	}
+	seed("tblD", "D",
+		map[string]any{"部门名": "研发"},
+		map[string]any{"部门名": []any{map[string]any{"text": "销售"}}},
+		map[string]any{"部门名": "销售"},
+	)
+	seed("tblE", "E", map[string]any{"工号": "1001"}, map[string]any{"工号": json.Number("1002")})
+	return srv, call, ids
+}
+
+// importedRecords 读取 tblE 的全部记录，按 record_id 索引字段
+func importedRecords(t *testing.T, call baseV3Caller) map[string]map[string]any {
+	t.Helper()
+	items, err := listBaseV3All(call, bitableRecordPath("bas1", "tblE"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]map[string]any{}
+	for _, item := range items {
+		out[pickString(item, "record_id")], _ = item["fields"].(map[string]any)
+	}
+	return out
+}
+
+func newTestImporter(call baseV3Caller, keyField string, batchSize int) *recordImporter {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	return &recordImporter{
+		call: call,
+		lookupUsers: func(emails []string) (map[string]string, error) {
+			out := map[string]string{}
+			for _, e := range emails {
+				if strings.EqualFold(e, "boss@example.com") {
+					out["boss@example.com"] = "ou_boss" // 与通讯录一致：以小写邮箱为键
+				}
+			}
+			return out, nil
+		},
+		baseToken: "bas1",
+		tableID:   "tblE",
+		keyField:  keyField,
+		loc:       loc,
+		batchSize: batchSize,
+		retry:     client.RetryConfig{MaxRetries: 2, RetryOnRateLimit: true},
+	}
+}
+
+func TestRecordImporterCoerce(t *testing.T) {
+	_, call, ids := seedImportBase(t)
+	im := newTestImporter(call, "", 200)
+	columns := []string{"工号", "薪资", "入职", "在职", "级别", "技能", "主管", "部门"}
+	rows := []*importRow{{line: 1, values: map[string]any{"主管": "Boss@Example.com", "部门": "研发"}}}
+	if err := im.prepare(columns, rows); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field string
+		in    any
+		want  any
+		err   string
+	}{
+		{"薪资", "12,345.5", 12345.5, ""},
+		{"薪资", json.Number("8000"), 8000.0, ""},
+		{"薪资", "abc", nil, "不是数字"},
+		{"入职", "2026-01-02", int64(1767283200000), ""}, // 东八区零点
+		{"入职", "2026-01-02T00:00:00Z", int64(1767312000000), ""},
+		{"入职", "1767283200", int64(1767283200000), ""},
+		{"入职", "明天", nil, "无法识别的日期"},
+		{"在职", "是", true, ""},
+		{"在职", json.Number("0"), false, ""},
+		{"级别", "P6", "P6", ""},
+		{"级别", "P7", nil, `选项 "P7" 不存在（可选: P5, P6）`},
+		{"级别", "P5,P6", nil, "单选字段只能有一个值"},
+		{"技能", "Go，SQL", []string{"Go", "SQL"}, ""},
+		{"技能", []any{"Go"}, []string{"Go"}, ""},
+		{"主管", "boss@example.com, ou_x", []any{map[string]any{"id": "ou_boss"}, map[string]any{"id": "ou_x"}}, ""},
+		{"主管", "nobody@example.com", nil, "通讯录中找不到邮箱"},
+		{"部门", "研发", []any{map[string]any{"id": ids["D1"]}}, ""},
+		{"部门", "销售", nil, "不止一条"},
+		{"部门", "市场", nil, "找不到主字段"},
+		{"部门", []any{map[string]any{"id": "recD9"}}, []any{map[string]any{"id": "recD9"}}, ""},
+		{"工号", json.Number("1003"), "1003", ""},
+		{"工号", "  ", nil, ""},
+	}
+	for _, tt := range tests {
+		got, err := im.coerce(im.fields[tt.field], tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s(%v) err = %v, want %q", tt.field, tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%v) = %#v, %v; want %#v", tt.field, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRecordImporterPrepareRejects(t *testing.T) {
+	for _, tt := range []struct {
+		columns []string
+		key     string
+		want    string
+	}{
+		{[]string{"工号", "未知"}, "", "不存在这些列对应的字段: 未知"},
+		{[]string{"工号", "编号"}, "", "只读类型，不能导入: 编号(auto_number)"},
+		{[]string{"姓名"}, "工号", `--key-field "工号" 不在文件的列中`},
+	} {
+		_, call, _ := seedImportBase(t)
+		err := newTestImporter(call, tt.key, 200).prepare(tt.columns, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("prepare(%v) err = %v, want %q", tt.columns, err, tt.want)
+		}
+	}
+}
+
+func TestRecordImporterUpsert(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "staff.csv")
+	src := "\ufeff工号,姓名,级别\n1001,张三,P6\n1003,李四,P5\n1004,王五,P9\n1003,重复,P5\n,无键,P5\n1002,赵六,\n1005,孙七,P6\n"
+	if err := os.WriteFile(csvPath, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	columns, rows, err := readImportRows(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(columns, []string{"工号", "姓名", "级别"}) {
+		t.Fatalf("columns = %v", columns)
+	}
+
+	srv, call, ids := seedImportBase(t)
+	srv.InjectFault("POST", bitableRecordPath("bas1", "tblE", "batch_create"), 1, 429, 99991400, "request trigger frequency limit")
+	im := newTestImporter(call, "工号", 1)
+	if err := im.prepare(columns, rows); err != nil {
+		t.Fatal(err)
+	}
+	results, err := im.plan(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im.execute(results)
+
+	var got []string
+	for _, r := range results {
+		got = append(got, strings.TrimSpace(fmt.Sprintf("%d %s %s %s %s", r.Row, r.Key, r.Action, r.Status, r.Error)))
+	}
+	want := []string{
+		"1 1001 update ok",
+		"2 1003 create ok", // 第一次限流后重试成功
+		"3 1004  failed 级别: 选项 \"P9\" 不存在（可选: P5, P6）",
+		"4 1003  failed 键值与第 2 行重复",
+		"5   failed 键字段 工号 为空",
+		"6 1002 update ok",
+		"7 1005 create ok",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if results[0].RecordID != ids["E1"] || results[5].RecordID != ids["E2"] {
+		t.Errorf("更新行应沿用已有记录: %s %s", results[0].RecordID, results[5].RecordID)
+	}
+
+	// 限流那次没有落库，重试只写入一次
+	var records []string
+	for id, fields := range importedRecords(t, call) {
+		data, _ := json.Marshal(fields)
+		records = append(records, string(data))
+		if fields["工号"] == "1003" && id != results[1].RecordID || fields["工号"] == "1005" && id != results[6].RecordID {
+			t.Errorf("新建行的 record_id 不符: %s %s", id, data)
+		}
+	}
+	sort.Strings(records)
+	wantRecords := []string{
+		`{"姓名":"孙七","工号":"1005","级别":"P6"}`,
+		`{"姓名":"张三","工号":"1001","级别":"P6"}`,
+		`{"姓名":"李四","工号":"1003","级别":"P5"}`,
+		`{"姓名":"赵六","工号":"1002"}`,
+	}
+	if !reflect.DeepEqual(records, wantRecords) {
+		t.Errorf("records =\n%s\nwant\n%s", strings.Join(records, "\n"), strings.Join(wantRecords, "\n"))
+	}
+	if s := summarizeImport(results); s != (importSummary{Total: 7, Created: 2, Updated: 2, Failed: 3}) {
+		t.Errorf("summary = %+v", s)
+	}
+
+	reportPath := filepath.Join(dir, "report.csv")
+	if err := writeImportReport(reportPath, results[:3]); err != nil {
+		t.Fatal(err)
+	}
+	report, _ := os.ReadFile(reportPath)
+	wantReport := "row,key,action,status,record_id,error\n1,1001,update,ok," + ids["E1"] + ",\n2,1003,create,ok," + results[1].RecordID + ",\n3,1004,,failed,,\"级别: 选项 \"\"P9\"\" 不存在（可选: P5, P6）\"\n"
+	if string(report) != wantReport {
+		t.Errorf("report =\n%s\nwant\n%s", report, wantReport)
+	}
+}
+
+func TestRecordImporterBatchFailure(t *testing.T) {
+	srv, call, _ := seedImportBase(t)
+	srv.InjectFault("POST", bitableRecordPath("bas1", "tblE", "batch_create"), 1, 200, 1254045, "field not found")
+	im := newTestImporter(call, "", 2)
+	rows := []*importRow{
+		{line: 1, values: map[string]any{"姓名": "a"}},
+		{line: 2, values: map[string]any{"姓名": "b"}},
+		{line: 3, values: map[string]any{"姓名": "c"}},
+		{line: 4, values: map[string]any{"姓名": ""}},
+	}
+	if err := im.prepare([]string{"姓名"}, rows); err != nil {
+		t.Fatal(err)
+	}
+	results, err := im.plan(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im.execute(results)
+	// 非限流、非 5xx 错误不重试，只让该批失败：表里只多出第 3 行
+	var names []string
+	for _, fields := range importedRecords(t, call) {
+		if name, ok := fields["姓名"]; ok {
+			names = append(names, fmt.Sprint(name))
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"c"}) {
+		t.Errorf("imported = %v", names)
+	}
+	for i, want := range []string{"failed", "failed", "ok", "skipped"} {
+		if results[i].Status != want {
+			t.Errorf("row %d status = %s, want %s (%s)", i+1, results[i].Status, want, results[i].Error)
+		}
+	}
+}
+
+// TestRecordImporterCreateOutcomeUnknown 覆盖 batch_create 返回 5xx 的情况：
+// 服务端可能已写入，不能像 update 一样直接重发
+func TestRecordImporterCreateOutcomeUnknown(t *testing.T) {
+	createPath := bitableRecordPath("bas1", "tblE", "batch_create")
+	tests := []struct {
+		name     string
+		keyField string
+		written  bool // 502 之前请求是否已落库
+		want     []string
+		names    []string
+		creates  int
+	}{
+		{"键字段回查到已写入", "工号", true, []string{"ok", "ok"}, []string{"a", "b"}, 1},
+		{"键字段回查未写入则补发", "工号", false, []string{"ok", "ok"}, []string{"a", "b"}, 2},
+		{"无键字段记为 unknown", "", true, []string{"unknown", "unknown"}, []string{"a", "b"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, call, _ := seedImportBase(t)
+			creates := 0
+			flaky := func(method, path string, query map[string]any, body any) (map[string]any, error) {
+				if path != createPath {
+					return call(method, path, query, body)
+				}
+				creates++
+				if creates > 1 {
+					return call(method, path, query, body)
+				}
+				if tt.written {
+					if _, err := call(method, path, query, body); err != nil {
+						return nil, err
+					}
+				}
+				return nil, fmt.Errorf("base/v3 API HTTP 502: bad gateway")
+			}
+			im := newTestImporter(flaky, tt.keyField, 200)
+			rows := []*importRow{
+				{line: 1, values: map[string]any{"工号": "2001", "姓名": "a"}},
+				{line: 2, values: map[string]any{"工号": "2002", "姓名": "b"}},
+			}
+			if err := im.prepare([]string{"工号", "姓名"}, rows); err != nil {
+				t.Fatal(err)
+			}
+			results, err := im.plan(rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			im.execute(results)
+
+			if creates != tt.creates {
+				t.Errorf("batch_create 调用 %d 次, want %d", creates, tt.creates)
+			}
+			var names []string
+			for _, fields := range importedRecords(t, call) {
+				if name, ok := fields["姓名"]; ok {
+					names = append(names, fmt.Sprint(name))
+				}
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("imported = %v, want %v（不应重复新建）", names, tt.names)
+			}
+			for i, want := range tt.want {
+				if results[i].Status != want {
+					t.Errorf("row %d status = %s, want %s (%s)", i+1, results[i].Status, want, results[i].Error)
+				}
+				if want == "ok" && results[i].RecordID == "" {
+					t.Errorf("row %d 缺少 record_id", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportJSONL(t *testing.T) {
+	columns, rows, err := parseImportJSONL([]byte("{\"姓名\":\"a\",\"薪资\":100}\n\n{\"工号\":\"1\"}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(columns, []string{"姓名", "薪资", "工号"}) || len(rows) != 2 || rows[1].line != 2 {
+		t.Errorf("columns = %v, rows = %d", columns, len(rows))
+	}
+	if rows[0].values["薪资"] != json.Number("100") {
+		t.Errorf("数字应保留为 json.Number: %#v", rows[0].values["薪资"])
+	}
+	if _, _, err := parseImportJSONL([]byte("[1]\n")); err == nil || !strings.Contains(err.Error(), "第 1 行") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,581 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// schema 子命令组：把数据表/字段/视图结构导出为文件，或按文件声明式地同步到多维表格
+var bitableSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "表结构即代码（export/apply）",
+	Long: `把多维表格的数据表、字段（类型、选项、公式、关联）和视图（含 filter/sort/group 等配置）
+导出为一个 YAML/JSON 文件，或把文件声明的结构同步到多维表格。
+
+文件中引用字段和数据表一律使用名称而非 ID（视图配置里的 field_id、关联字段的 table_id、
+公式里的 $table[...]/$field[...] 都会互相转换），因此同一份文件可以应用到另一个多维表格，
+适合代码评审和重建测试环境。引用其它数据表的字段写作「表名.字段名」。
+
+示例:
+  feishu-cli bitable schema export bscnxxxx -o schema.yaml
+  feishu-cli bitable schema apply bscnxxxx --file schema.yaml --dry-run
+  feishu-cli bitable schema apply bscnxxxx --file schema.yaml`,
+}
+
+// bitableSchema 是 schema 文件的内容
+type bitableSchema struct {
+	Version int            `json:"version" yaml:"version"`
+	Tables  []*schemaTable `json:"tables" yaml:"tables"`
+}
+
+type schemaTable struct {
+	Name   string        `json:"name" yaml:"name"`
+	Fields []*schemaItem `json:"fields" yaml:"fields"`
+	Views  []*schemaItem `json:"views,omitempty" yaml:"views,omitempty"`
+}
+
+// schemaItem 是一个字段或视图：name/type 之外的属性平铺在同一层。
+// 字段的属性即 base/v3 字段 JSON 的其余键（options/formatter/expression 等），
+// 视图的属性为配置接口的路径段（filter/sort/group/visible_fields/timebar/card）。
+type schemaItem struct {
+	Name  string         `yaml:"name"`
+	Type  string         `yaml:"type"`
+	Props map[string]any `yaml:",inline"`
+}
+
+// MarshalJSON 让 name/type 排在最前，其余属性按键名排序
+func (it *schemaItem) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, value any) error {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+		return nil
+	}
+	if err := write("name", it.Name); err != nil {
+		return nil, err
+	}
+	if err := write("type", it.Type); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(it.Props))
+	for k := range it.Props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := write(k, it.Props[k]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (it *schemaItem) UnmarshalJSON(data []byte) error {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	it.Name, _ = m["name"].(string)
+	it.Type, _ = m["type"].(string)
+	delete(m, "name")
+	delete(m, "type")
+	it.Props = m
+	return nil
+}
+
+// body 返回字段/视图的完整定义（name、type 与属性合并）
+func (it *schemaItem) body() map[string]any {
+	m := make(map[string]any, len(it.Props)+2)
+	for k, v := range it.Props {
+		m[k] = v
+	}
+	m["name"] = it.Name
+	m["type"] = it.Type
+	return m
+}
+
+// loadBitableSchema 读取 schema 文件：.json 按 JSON 解析，其余按 YAML 解析
+func loadBitableSchema(path string) (*bitableSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 schema 文件失败: %w", err)
+	}
+	schema := &bitableSchema{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, schema)
+	} else {
+		err = yaml.Unmarshal(data, schema)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析 schema 文件失败: %w", err)
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// validate 检查名称与类型是否齐全、同层名称是否重复
+func (s *bitableSchema) validate() error {
+	if s.Version != 0 && s.Version != 1 {
+		return fmt.Errorf("不支持的 schema version: %d", s.Version)
+	}
+	tables := map[string]bool{}
+	for i, t := range s.Tables {
+		if t == nil || t.Name == "" {
+			return fmt.Errorf("tables[%d] 缺少 name", i)
+		}
+		if tables[t.Name] {
+			return fmt.Errorf("数据表名称重复: %s", t.Name)
+		}
+		tables[t.Name] = true
+		for _, group := range []struct {
+			label string
+			items []*schemaItem
+		}{{"字段", t.Fields}, {"视图", t.Views}} {
+			names := map[string]bool{}
+			for j, it := range group.items {
+				if it == nil || it.Name == "" || it.Type == "" {
+					return fmt.Errorf("数据表 %s 的第 %d 个%s缺少 name 或 type", t.Name, j+1, group.label)
+				}
+				if names[it.Name] {
+					return fmt.Errorf("数据表 %s 的%s名称重复: %s", t.Name, group.label, it.Name)
+				}
+				names[it.Name] = true
+			}
+		}
+	}
+	return nil
+}
+
+// encodeBitableSchema 把 schema 编码为 YAML（默认）或缩进 JSON
+func encodeBitableSchema(s *bitableSchema, asJSON bool) ([]byte, error) {
+	if asJSON {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ------- 线上结构 -------
+
+// baseV3Caller 发起一次 base/v3 调用，返回 data；便于测试替换
+type baseV3Caller func(method, path string, params map[string]any, body any) (map[string]any, error)
+
+func newBaseV3Caller(token string) baseV3Caller {
+	return func(method, path string, params map[string]any, body any) (map[string]any, error) {
+		return client.BaseV3Call(method, path, params, body, token)
+	}
+}
+
+// liveBase 是多维表格的线上结构；apply 规划时会把待创建的对象以空 ID 加入其中
+type liveBase struct {
+	token  string
+	tables []*liveTable
+}
+
+type liveTable struct {
+	id     string
+	name   string
+	fields []*liveItem
+	views  []*liveItem
+	isNew  bool // 本次 apply 新建
+}
+
+// liveItem 是线上的字段或视图，属性中的引用仍为 ID
+type liveItem struct {
+	schemaItem
+	id      string
+	primary bool
+	oldName string // 规划时被重命名前的名称
+}
+
+// schemaViewConfigKinds 是视图配置的路径段，也是 schema 文件中视图的属性名
+var schemaViewConfigKinds = []string{"filter", "sort", "group", "visible_fields", "timebar", "card"}
+
+// viewConfigApplies 判断某类视图是否有该项配置：timebar 仅甘特视图，card 仅看板/画册视图，表单视图没有视图配置
+func viewConfigApplies(viewType, kind string) bool {
+	switch {
+	case viewType == "form":
+		return false
+	case kind == "timebar":
+		return viewType == "gantt"
+	case kind == "card":
+		return viewType == "kanban" || viewType == "gallery"
+	}
+	return true
+}
+
+// fetchLiveBase 读取全部数据表、字段、视图及视图配置。单个视图配置读取失败只告警，不中断
+func fetchLiveBase(call baseV3Caller, baseToken string) (*liveBase, error) {
+	live := &liveBase{token: baseToken}
+	tables, err := listBaseV3All(call, bitableTablePath(baseToken), nil)
+	if err != nil {
+		return nil, fmt.Errorf("列出数据表失败: %w", err)
+	}
+	for _, raw := range tables {
+		t := &liveTable{id: pickString(raw, "table_id", "id"), name: pickString(raw, "name", "table_name")}
+		if err := fetchLiveTable(call, baseToken, t); err != nil {
+			return nil, err
+		}
+		live.tables = append(live.tables, t)
+	}
+	return live, nil
+}
+
+func fetchLiveTable(call baseV3Caller, baseToken string, t *liveTable) error {
+	fields, err := listBaseV3All(call, bitableFieldPath(baseToken, t.id), nil)
+	if err != nil {
+		return fmt.Errorf("列出数据表 %s 的字段失败: %w", t.name, err)
+	}
+	t.fields = nil
+	for _, raw := range fields {
+		it := &liveItem{id: pickString(raw, "field_id", "id"), primary: raw["is_primary"] == true}
+		it.Name = pickString(raw, "name", "field_name")
+		it.Type = pickString(raw, "type")
+		it.Props = map[string]any{}
+		for k, v := range raw {
+			switch k {
+			case "id", "field_id", "name", "field_name", "type", "is_primary":
+			default:
+				it.Props[k] = v
+			}
+		}
+		t.fields = append(t.fields, it)
+	}
+	// 接口未标记主字段时，第一个字段即主字段
+	if len(t.fields) > 0 && findLiveItem(t.fields, func(it *liveItem) bool { return it.primary }) == nil {
+		t.fields[0].primary = true
+	}
+
+	views, err := listBaseV3All(call, bitableViewPath(baseToken, t.id), nil)
+	if err != nil {
+		return fmt.Errorf("列出数据表 %s 的视图失败: %w", t.name, err)
+	}
+	t.views = nil
+	for _, raw := range views {
+		it := &liveItem{id: pickString(raw, "view_id", "id")}
+		it.Name = pickString(raw, "name", "view_name")
+		it.Type = pickString(raw, "type", "view_type")
+		it.Props = map[string]any{}
+		for _, kind := range schemaViewConfigKinds {
+			if !viewConfigApplies(it.Type, kind) {
+				continue
+			}
+			data, err := call("GET", bitableViewPath(baseToken, t.id, it.id, kind), nil, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "警告: 读取视图 %s.%s 的 %s 配置失败: %v\n", t.name, it.Name, kind, err)
+				continue
+			}
+			if cfg := normalizeSchemaValue(data); !isEmptySchemaValue(cfg) {
+				it.Props[kind] = cfg
+			}
+		}
+		t.views = append(t.views, it)
+	}
+	return nil
+}
+
+// listBaseV3All 按 offset/limit 翻页读取列表接口的全部条目；params 为附加的 query 参数，可为 nil
+func listBaseV3All(call baseV3Caller, path string, params map[string]any) ([]map[string]any, error) {
+	const limit = 100
+	var all []map[string]any
+	for offset := 0; ; {
+		page := map[string]any{"offset": offset, "limit": limit}
+		for k, v := range params {
+			page[k] = v
+		}
+		data, err := call("GET", path, page, nil)
+		if err != nil {
+			return nil, err
+		}
+		var items []any
+		for _, key := range []string{"items", "tables", "fields", "views"} {
+			if list, ok := data[key].([]any); ok {
+				items = list
+				break
+			}
+		}
+		for _, raw := range items {
+			if m, ok := normalizeSchemaValue(raw).(map[string]any); ok {
+				all = append(all, m)
+			}
+		}
+		hasMore, _ := data["has_more"].(bool)
+		if !hasMore || len(items) == 0 {
+			return all, nil
+		}
+		offset += len(items)
+	}
+}
+
+// pickString 返回第一个非空的字符串值，兼容不同接口对 ID/名称字段的命名
+func pickString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// normalizeSchemaValue 把 json.Number 转为 int64/float64，使 YAML 输出数字而非字符串
+func normalizeSchemaValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = normalizeSchemaValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = normalizeSchemaValue(item)
+		}
+		return out
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	}
+	return v
+}
+
+// isEmptySchemaValue 判断配置是否为空（nil、空对象、空数组，或对象的值全为空）
+func isEmptySchemaValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		for _, item := range val {
+			if !isEmptySchemaValue(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// exportBitableSchema 把线上结构转换为 schema 文件内容，引用 ID 替换为名称
+func exportBitableSchema(live *liveBase) *bitableSchema {
+	schema := &bitableSchema{Version: 1, Tables: []*schemaTable{}}
+	for _, t := range live.tables {
+		refs := &schemaRefMapper{live: live, cur: t}
+		export := func(items []*liveItem) []*schemaItem {
+			out := []*schemaItem{}
+			for _, it := range items {
+				item := &schemaItem{Name: it.Name, Type: it.Type}
+				if len(it.Props) > 0 {
+					item.Props = refs.toNames(it.Props).(map[string]any)
+				}
+				out = append(out, item)
+			}
+			return out
+		}
+		schema.Tables = append(schema.Tables, &schemaTable{Name: t.name, Fields: export(t.fields), Views: export(t.views)})
+	}
+	return schema
+}
+
+// ------- 命令 -------
+
+// schemaBaseToken 取位置参数或 --base-token
+func schemaBaseToken(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	token, _ := cmd.Flags().GetString("base-token")
+	if token == "" {
+		return "", fmt.Errorf("需要 <base_token> 参数或 --base-token")
+	}
+	return token, nil
+}
+
+var bitableSchemaExportCmd = &cobra.Command{
+	Use:   "export [base_token]",
+	Short: "导出数据表/字段/视图结构为 YAML/JSON",
+	Long: `读取多维表格的全部数据表、字段和视图（含 filter/sort/group/visible_fields/timebar/card 配置），
+输出为一个 schema 文件。引用一律转换为名称，空的视图配置省略。
+
+输出默认为 YAML；--json 或 -o 以 .json 结尾时输出 JSON。
+
+示例:
+  feishu-cli bitable schema export bscnxxxx
+  feishu-cli bitable schema export bscnxxxx -o schema.yaml
+  feishu-cli bitable schema export --base-token bscnxxxx --json > schema.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		baseToken, err := schemaBaseToken(cmd, args)
+		if err != nil {
+			return err
+		}
+		token, err := resolveIdentityToken(cmd)
+		if err != nil {
+			return err
+		}
+		outPath, _ := cmd.Flags().GetString("output")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if strings.EqualFold(filepath.Ext(outPath), ".json") {
+			asJSON = true
+		}
+
+		live, err := fetchLiveBase(newBaseV3Caller(token), baseToken)
+		if err != nil {
+			return err
+		}
+		data, err := encodeBitableSchema(exportBitableSchema(live), asJSON)
+		if err != nil {
+			return err
+		}
+		if outPath == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(outPath, data, 0644); err != nil {
+			return fmt.Errorf("写入文件失败: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "已导出 %d 张数据表到 %s\n", len(live.tables), outPath)
+		return nil
+	},
+}
+
+var bitableSchemaApplyCmd = &cobra.Command{
+	Use:   "apply [base_token]",
+	Short: "按 schema 文件同步数据表/字段/视图（支持 --dry-run 预览计划）",
+	Long: `比较 schema 文件与多维表格的线上结构，生成并执行变更计划：
+
+  + 创建缺失的数据表、字段、视图
+  ~ 更新属性不一致的字段（PUT 全量替换）、视图配置（filter/sort/group 等）
+  - 删除文件中未声明的视图、字段、数据表（仅 --prune 时执行，否则只列出）
+  ! 无法自动处理的差异（如视图类型变化），跳过
+
+匹配规则:
+  - 数据表、字段、视图都按名称匹配；改名会被视为删除旧的 + 创建新的
+  - 例外：文件中第一个字段对应主字段，名称不同时直接改名
+  - 新建数据表自带的默认视图，类型与文件中第一个视图相同时改名复用
+  - 只比较文件中写出的属性和视图配置；未写出的保持线上原样
+  - 被依赖的字段（关联、查找引用、公式）会先于依赖它的字段创建
+
+示例:
+  feishu-cli bitable schema apply bscnxxxx --file schema.yaml --dry-run
+  feishu-cli bitable schema apply bscnxxxx --file schema.yaml
+  feishu-cli bitable schema apply bscnxxxx --file schema.yaml --prune --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		baseToken, err := schemaBaseToken(cmd, args)
+		if err != nil {
+			return err
+		}
+		filePath, _ := cmd.Flags().GetString("file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		prune, _ := cmd.Flags().GetBool("prune")
+		outOpts, structured, err := resolveMarkdownDiffOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		schema, err := loadBitableSchema(filePath)
+		if err != nil {
+			return err
+		}
+		token, err := resolveIdentityToken(cmd)
+		if err != nil {
+			return err
+		}
+		call := newBaseV3Caller(token)
+		live, err := fetchLiveBase(call, baseToken)
+		if err != nil {
+			return err
+		}
+
+		ops := planBitableSchema(schema, live, prune)
+		if !structured {
+			printSchemaPlan(os.Stdout, ops, prune)
+		}
+		applied := 0
+		if !dryRun {
+			applied, err = applySchemaPlan(call, live, ops)
+			if err != nil {
+				return err
+			}
+		}
+		if structured {
+			return output.Render(outOpts, map[string]any{
+				"base_token": baseToken,
+				"dry_run":    dryRun,
+				"changes":    countSchemaChanges(ops),
+				"applied":    applied,
+				"operations": ops,
+			})
+		}
+		if !dryRun && applied > 0 {
+			fmt.Printf("已应用 %d 项变更\n", applied)
+		}
+		return nil
+	},
+}
+
+func init() {
+	bitableCmd.AddCommand(bitableSchemaCmd)
+
+	for _, c := range []*cobra.Command{bitableSchemaExportCmd, bitableSchemaApplyCmd} {
+		bitableSchemaCmd.AddCommand(c)
+		c.Flags().String("base-token", "", "多维表格 base_token（也可作为位置参数）")
+		c.Flags().String("user-access-token", "", "User Access Token")
+	}
+
+	bitableSchemaExportCmd.Flags().StringP("output", "o", "", "输出文件路径（默认输出到 stdout）")
+	bitableSchemaExportCmd.Flags().Bool("json", false, "输出 JSON（默认 YAML）")
+
+	bitableSchemaApplyCmd.Flags().StringP("file", "f", "", "schema 文件（.yaml/.yml/.json）")
+	bitableSchemaApplyCmd.Flags().Bool("prune", false, "删除文件中未声明的视图、字段和数据表")
+	bitableSchemaApplyCmd.Flags().Bool("dry-run", false, "只打印变更计划，不执行")
+	output.AddFormatFlags(bitableSchemaApplyCmd)
+	mustMarkFlagRequired(bitableSchemaApplyCmd, "file")
+}
@@ -0,0 +1,792 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ------- 引用转换：线上 ID ↔ 文件中的名称 -------
+
+// schemaTableRefKeys 值为数据表引用的键（关联/双向关联字段的目标表）
+var schemaTableRefKeys = map[string]bool{
+	"table_id": true, "link_table_id": true, "link_table": true, "target_table": true,
+}
+
+// schemaFieldListKeys 值为字段引用数组的键（卡片视图的展示字段等）
+var schemaFieldListKeys = map[string]bool{
+	"display_fields": true, "field_ids": true, "field_id_list": true,
+}
+
+// schemaFormulaRefRe 匹配公式中的 $table[...].$field[...]、$table[...] 与 $field[...]
+var schemaFormulaRefRe = regexp.MustCompile(`\$table\[([^\]]+)\]\.\$field\[([^\]]+)\]|\$table\[([^\]]+)\]|\$field\[([^\]]+)\]`)
+
+// isSchemaFieldRefKey 判断键的值是否为单个字段引用：field_id、*_field_id、*_field（如查找引用的 target_field）
+func isSchemaFieldRefKey(key string) bool {
+	return key == "field_id" || strings.HasSuffix(key, "_field_id") || strings.HasSuffix(key, "_field")
+}
+
+// schemaRefMapper 在 cur 数据表的上下文中转换引用。
+// 字段名先在当前表中查找，其它表的字段写作「表名.字段名」。
+type schemaRefMapper struct {
+	live    *liveBase
+	cur     *liveTable
+	toID    bool
+	missing []string    // toIDs 时找不到的引用
+	deps    []*liveItem // toIDs 时引用到的、尚未创建的字段
+}
+
+// toNames 把 v 中引用的 ID 替换为名称，返回副本
+func (m *schemaRefMapper) toNames(v any) any {
+	m.toID = false
+	return m.rewrite(v)
+}
+
+// toIDs 把 v 中引用的名称替换为 ID，返回副本；找不到的引用原样保留并记入 missing
+func (m *schemaRefMapper) toIDs(v any) any {
+	m.toID = true
+	return m.rewrite(v)
+}
+
+func (m *schemaRefMapper) rewrite(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = m.rewriteKey(k, item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = m.rewrite(item)
+		}
+		return out
+	case string:
+		return m.formula(val)
+	}
+	return v
+}
+
+func (m *schemaRefMapper) rewriteKey(key string, v any) any {
+	s, isString := v.(string)
+	switch {
+	case schemaTableRefKeys[key] && isString:
+		return m.table(s)
+	case isSchemaFieldRefKey(key) && isString:
+		return m.field(m.cur, s, true)
+	case schemaFieldListKeys[key]:
+		if list, ok := v.([]any); ok {
+			out := make([]any, len(list))
+			for i, item := range list {
+				if s, ok := item.(string); ok {
+					out[i] = m.field(m.cur, s, true)
+				} else {
+					out[i] = m.rewrite(item)
+				}
+			}
+			return out
+		}
+	}
+	return m.rewrite(v)
+}
+
+func (m *schemaRefMapper) table(ref string) string {
+	if !m.toID {
+		if t := m.live.tableByID(ref); t != nil {
+			return t.name
+		}
+		return ref
+	}
+	if t := m.live.tableByName(ref); t != nil {
+		if t.id == "" {
+			return ref // 待创建的数据表，执行时已有 ID
+		}
+		return t.id
+	}
+	if m.live.tableByID(ref) == nil {
+		m.missing = append(m.missing, ref)
+	}
+	return ref
+}
+
+// field 转换字段引用；qualified 为 true 时允许跨表（「表名.字段名」），公式中的 $field[...] 只在 t 内查找
+func (m *schemaRefMapper) field(t *liveTable, ref string, qualified bool) string {
+	if !m.toID {
+		if f := t.fieldByID(ref); f != nil {
+			return f.Name
+		}
+		if qualified {
+			for _, other := range m.live.tables {
+				if f := other.fieldByID(ref); f != nil {
+					return other.name + "." + f.Name
+				}
+			}
+		}
+		return ref
+	}
+
+	f := t.fieldByName(ref)
+	if f == nil && qualified {
+		for _, other := range m.live.tables {
+			if strings.HasPrefix(ref, other.name+".") {
+				if f = other.fieldByName(ref[len(other.name)+1:]); f != nil {
+					break
+				}
+			}
+		}
+	}
+	switch {
+	case f == nil:
+		if m.live.fieldByID(ref) == nil {
+			m.missing = append(m.missing, ref)
+		}
+		return ref
+	case f.id == "":
+		m.deps = append(m.deps, f)
+		return ref
+	}
+	return f.id
+}
+
+// formula 转换公式文本中的 $table[...] / $field[...] 引用
+func (m *schemaRefMapper) formula(s string) string {
+	if !strings.Contains(s, "$table[") && !strings.Contains(s, "$field[") {
+		return s
+	}
+	return schemaFormulaRefRe.ReplaceAllStringFunc(s, func(match string) string {
+		sub := schemaFormulaRefRe.FindStringSubmatch(match)
+		switch {
+		case sub[1] != "":
+			t := m.live.tableByID(sub[1])
+			if m.toID {
+				t = m.live.tableByName(sub[1])
+			}
+			if t == nil {
+				return "$table[" + m.table(sub[1]) + "].$field[" + sub[2] + "]"
+			}
+			return "$table[" + m.table(sub[1]) + "].$field[" + m.field(t, sub[2], false) + "]"
+		case sub[3] != "":
+			return "$table[" + m.table(sub[3]) + "]"
+		}
+		return "$field[" + m.field(m.cur, sub[4], false) + "]"
+	})
+}
+
+func (b *liveBase) tableByName(name string) *liveTable {
+	for _, t := range b.tables {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (b *liveBase) tableByID(id string) *liveTable {
+	for _, t := range b.tables {
+		if id != "" && t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (b *liveBase) fieldByID(id string) *liveItem {
+	for _, t := range b.tables {
+		if f := t.fieldByID(id); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func (t *liveTable) fieldByName(name string) *liveItem {
+	return findLiveItem(t.fields, func(it *liveItem) bool { return it.Name == name })
+}
+
+func (t *liveTable) fieldByID(id string) *liveItem {
+	return findLiveItem(t.fields, func(it *liveItem) bool { return id != "" && it.id == id })
+}
+
+func findLiveItem(items []*liveItem, match func(*liveItem) bool) *liveItem {
+	for _, it := range items {
+		if match(it) {
+			return it
+		}
+	}
+	return nil
+}
+
+// ------- 变更计划 -------
+
+// 计划中的动作：create/update/delete 会执行；skip 为无法处理的差异，extra 为未声明且未加 --prune 的对象
+const (
+	schemaOpCreate = "create"
+	schemaOpUpdate = "update"
+	schemaOpDelete = "delete"
+	schemaOpSkip   = "skip"
+	schemaOpExtra  = "extra"
+)
+
+// schemaOp 是变更计划中的一项
+type schemaOp struct {
+	Action  string   `json:"action"`
+	Kind    string   `json:"kind"` // table / field / view
+	Table   string   `json:"table"`
+	Name    string   `json:"name,omitempty"`
+	Type    string   `json:"type,omitempty"`
+	Changes []string `json:"changes,omitempty"` // 变化的字段属性或视图配置
+	Note    string   `json:"note,omitempty"`
+
+	run func(call baseV3Caller) error
+}
+
+// schemaTablePair 是文件中的数据表与其对应的线上数据表
+type schemaTablePair struct {
+	file   *schemaTable
+	live   *liveTable
+	fields map[*schemaItem]*liveItem
+	views  map[*schemaItem]*liveItem
+}
+
+// planBitableSchema 比较 schema 与线上结构，按执行顺序返回变更计划：
+// 建表 → 建字段（被依赖的在前）→ 改字段 → 视图 → 删除（视图、字段、数据表）。
+// 待创建的对象以空 ID 加入 live，执行时回填。
+func planBitableSchema(schema *bitableSchema, live *liveBase, prune bool) []*schemaOp {
+	var ops, tableRemovals []*schemaOp
+	var pairs []*schemaTablePair
+
+	declared := map[string]bool{}
+	for _, st := range schema.Tables {
+		declared[st.Name] = true
+		t := live.tableByName(st.Name)
+		if t == nil {
+			// 新表自带一个主字段和一个表格视图，执行建表后回填 ID
+			t = &liveTable{name: st.Name, isNew: true}
+			t.fields = []*liveItem{{schemaItem: schemaItem{Name: "<默认主字段>", Type: "text"}, primary: true}}
+			t.views = []*liveItem{{schemaItem: schemaItem{Name: "<默认视图>", Type: "grid"}}}
+			live.tables = append(live.tables, t)
+			ops = append(ops, &schemaOp{Action: schemaOpCreate, Kind: "table", Table: st.Name, run: createSchemaTable(live, t)})
+		}
+		pairs = append(pairs, &schemaTablePair{file: st, live: t})
+	}
+	for _, t := range live.tables {
+		if !declared[t.name] {
+			t := t
+			tableRemovals = append(tableRemovals, removalOp(prune, "table", t.name, "", func(call baseV3Caller) error {
+				_, err := call("DELETE", bitableTablePath(live.token, t.id), nil, nil)
+				return err
+			}))
+		}
+	}
+
+	// 先完成所有表的字段匹配与改名，后续比较和引用解析都按文件中的名称进行
+	var created []*schemaCreate
+	for _, p := range pairs {
+		created = append(created, matchSchemaFields(p)...)
+	}
+	ops = append(ops, orderSchemaFieldCreates(live, created)...)
+
+	for _, p := range pairs {
+		ops = append(ops, planSchemaFieldUpdates(live, p)...)
+	}
+	for _, p := range pairs {
+		ops = append(ops, planSchemaViews(live, p)...)
+	}
+
+	for _, p := range pairs {
+		ops = append(ops, schemaFieldViewRemovals(live, p, prune)...)
+	}
+	return append(ops, tableRemovals...)
+}
+
+// schemaCreate 是一个待创建的字段
+type schemaCreate struct {
+	pair *schemaTablePair
+	file *schemaItem
+	item *liveItem
+}
+
+// matchSchemaFields 按名称匹配字段；文件中的第一个字段未匹配时对应线上主字段（改名）。
+// 未匹配的文件字段以空 ID 加入线上表，返回这些待创建字段
+func matchSchemaFields(p *schemaTablePair) []*schemaCreate {
+	p.fields = map[*schemaItem]*liveItem{}
+	used := map[*liveItem]bool{}
+	for _, f := range p.file.Fields {
+		if it := p.live.fieldByName(f.Name); it != nil {
+			p.fields[f], used[it] = it, true
+		}
+	}
+	if len(p.file.Fields) > 0 && p.fields[p.file.Fields[0]] == nil {
+		if primary := findLiveItem(p.live.fields, func(it *liveItem) bool { return it.primary }); primary != nil && !used[primary] {
+			p.fields[p.file.Fields[0]], used[primary] = primary, true
+		}
+	}
+
+	var created []*schemaCreate
+	for _, f := range p.file.Fields {
+		it := p.fields[f]
+		if it == nil {
+			it = &liveItem{schemaItem: schemaItem{Name: f.Name, Type: f.Type}}
+			p.live.fields = append(p.live.fields, it)
+			p.fields[f] = it
+			created = append(created, &schemaCreate{pair: p, file: f, item: it})
+			continue
+		}
+		if it.Name != f.Name {
+			it.oldName, it.Name = it.Name, f.Name
+		}
+	}
+	return created
+}
+
+// orderSchemaFieldCreates 生成建字段操作：引用了其它待建字段（关联、查找引用、公式）的字段排在被引用字段之后；
+// 循环依赖时按文件顺序创建
+func orderSchemaFieldCreates(live *liveBase, pending []*schemaCreate) []*schemaOp {
+	var ops []*schemaOp
+	waiting := map[*liveItem]bool{}
+	for _, c := range pending {
+		waiting[c.item] = true
+	}
+	for len(pending) > 0 {
+		var rest []*schemaCreate
+		for _, c := range pending {
+			m := &schemaRefMapper{live: live, cur: c.pair.live}
+			m.toIDs(c.file.Props)
+			ready := true
+			for _, dep := range m.deps {
+				if dep != c.item && waiting[dep] {
+					ready = false
+				}
+			}
+			if !ready {
+				rest = append(rest, c)
+				continue
+			}
+			delete(waiting, c.item)
+			ops = append(ops, createSchemaFieldOp(live, c, m.missing))
+		}
+		if len(rest) == len(pending) {
+			c := rest[0]
+			m := &schemaRefMapper{live: live, cur: c.pair.live}
+			m.toIDs(c.file.Props)
+			delete(waiting, c.item)
+			op := createSchemaFieldOp(live, c, m.missing)
+			op.Note = joinNotes(op.Note, "与其它新字段循环引用")
+			ops = append(ops, op)
+			rest = rest[1:]
+		}
+		pending = rest
+	}
+	return ops
+}
+
+func createSchemaFieldOp(live *liveBase, c *schemaCreate, missing []string) *schemaOp {
+	t, f, it := c.pair.live, c.file, c.item
+	op := &schemaOp{Action: schemaOpCreate, Kind: "field", Table: t.name, Name: f.Name, Type: f.Type}
+	if len(missing) > 0 {
+		op.Note = "引用不存在: " + strings.Join(missing, ", ")
+	}
+	op.run = func(call baseV3Caller) error {
+		m := &schemaRefMapper{live: live, cur: t}
+		data, err := call("POST", bitableFieldPath(live.token, t.id), nil, m.toIDs(f.body()))
+		if err != nil {
+			return err
+		}
+		if it.id = pickCreatedID(data, "field", "field_id"); it.id == "" {
+			return fmt.Errorf("响应中没有 field_id")
+		}
+		return nil
+	}
+	return op
+}
+
+// planSchemaFieldUpdates 比较已匹配字段的名称、类型与文件中写出的属性，不一致时整体 PUT
+func planSchemaFieldUpdates(live *liveBase, p *schemaTablePair) []*schemaOp {
+	var ops []*schemaOp
+	for _, f := range p.file.Fields {
+		f, it := f, p.fields[f]
+		if it.id == "" && !it.primary {
+			continue // 待创建
+		}
+		op := &schemaOp{Action: schemaOpUpdate, Kind: "field", Table: p.live.name, Name: f.Name}
+		switch {
+		case p.live.isNew && it.primary:
+			op.Type = f.Type
+			op.Note = "替换建表自带的主字段"
+		default:
+			m := &schemaRefMapper{live: live, cur: p.live}
+			op.Changes = schemaItemChanges(f, it, m.toNames(it.Props).(map[string]any))
+			if len(op.Changes) == 0 {
+				continue
+			}
+			if it.oldName != "" {
+				op.Note = "原名 " + it.oldName
+			}
+		}
+		if it.primary {
+			op.Note = joinNotes("主字段", op.Note)
+		}
+		op.run = func(call baseV3Caller) error {
+			m := &schemaRefMapper{live: live, cur: p.live}
+			_, err := call("PUT", bitableFieldPath(live.token, p.live.id, it.id), nil, m.toIDs(f.body()))
+			return err
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// schemaItemChanges 列出名称、类型及文件中写出的属性里与线上不一致的项
+func schemaItemChanges(f *schemaItem, it *liveItem, liveProps map[string]any) []string {
+	var changes []string
+	if it.oldName != "" {
+		changes = append(changes, "name")
+	}
+	if f.Type != it.Type {
+		changes = append(changes, "type")
+	}
+	for _, k := range sortedKeys(f.Props) {
+		if !schemaSubset(f.Props[k], liveProps[k]) {
+			changes = append(changes, k)
+		}
+	}
+	return changes
+}
+
+// planSchemaViews 按名称匹配视图并比较文件中写出的视图配置。
+// 文件中没有写 views 的数据表不管理视图。
+func planSchemaViews(live *liveBase, p *schemaTablePair) []*schemaOp {
+	p.views = map[*schemaItem]*liveItem{}
+	if len(p.file.Views) == 0 {
+		return nil
+	}
+	used := map[*liveItem]bool{}
+	for _, v := range p.file.Views {
+		if it := findLiveItem(p.live.views, func(it *liveItem) bool { return it.Name == v.Name }); it != nil {
+			p.views[v], used[it] = it, true
+		}
+	}
+	// 新表的默认视图类型相同时复用为文件中的第一个视图
+	if first := p.file.Views[0]; p.live.isNew && p.views[first] == nil {
+		if def := p.live.views[0]; !used[def] && def.Type == first.Type {
+			p.views[first], used[def] = def, true
+			def.oldName, def.Name = def.Name, first.Name
+		}
+	}
+
+	var ops []*schemaOp
+	for _, v := range p.file.Views {
+		var unknown []string
+		for _, k := range sortedKeys(v.Props) {
+			if !isSchemaViewConfigKind(k) {
+				unknown = append(unknown, k)
+			}
+		}
+		if len(unknown) > 0 {
+			ops = append(ops, &schemaOp{Action: schemaOpSkip, Kind: "view", Table: p.live.name, Name: v.Name,
+				Note: "未知的视图配置已忽略: " + strings.Join(unknown, ", ")})
+		}
+
+		it := p.views[v]
+		if it == nil {
+			it = &liveItem{schemaItem: schemaItem{Name: v.Name, Type: v.Type}}
+			p.live.views = append(p.live.views, it)
+			p.views[v] = it
+			ops = append(ops, schemaViewOp(live, p.live, v, it, schemaOpCreate, schemaViewKinds(v)))
+			continue
+		}
+		if it.Type != v.Type {
+			ops = append(ops, &schemaOp{Action: schemaOpSkip, Kind: "view", Table: p.live.name, Name: v.Name,
+				Note: fmt.Sprintf("视图类型 %s → %s 无法修改，请删除后重建", it.Type, v.Type)})
+			continue
+		}
+		m := &schemaRefMapper{live: live, cur: p.live}
+		var changes []string
+		if it.oldName != "" {
+			changes = append(changes, "name")
+		}
+		for _, kind := range schemaViewKinds(v) {
+			if !schemaSubset(schemaViewConfigBody(kind, v.Props[kind]), m.toNames(it.Props[kind])) {
+				changes = append(changes, kind)
+			}
+		}
+		if len(changes) > 0 {
+			ops = append(ops, schemaViewOp(live, p.live, v, it, schemaOpUpdate, changes))
+		}
+	}
+	return ops
+}
+
+func isSchemaViewConfigKind(k string) bool {
+	for _, kind := range schemaViewConfigKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaViewKinds 返回文件中为视图写出的配置项，按 schemaViewConfigKinds 的顺序
+func schemaViewKinds(v *schemaItem) []string {
+	var kinds []string
+	for _, kind := range schemaViewConfigKinds {
+		if _, ok := v.Props[kind]; ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// schemaViewConfigBody 把 sort/group 的数组简写包装为接口要求的对象，同 view-sort-set / view-group-set
+func schemaViewConfigBody(kind string, v any) any {
+	if wrapKey, ok := viewConfigWrapKey[kind]; ok {
+		if _, isObject := v.(map[string]any); !isObject {
+			return map[string]any{wrapKey: v}
+		}
+	}
+	return v
+}
+
+// schemaViewOp 创建视图或更新视图：改名后逐项 PUT changes 中的配置
+func schemaViewOp(live *liveBase, t *liveTable, v *schemaItem, it *liveItem, action string, changes []string) *schemaOp {
+	op := &schemaOp{Action: action, Kind: "view", Table: t.name, Name: v.Name, Changes: changes}
+	if action == schemaOpCreate {
+		op.Type = v.Type
+	} else if it.oldName != "" {
+		op.Note = "原名 " + it.oldName
+	}
+	op.run = func(call baseV3Caller) error {
+		switch {
+		case action == schemaOpCreate:
+			data, err := call("POST", bitableViewPath(live.token, t.id), nil, map[string]any{"name": v.Name, "type": v.Type})
+			if err != nil {
+				return err
+			}
+			if it.id = pickCreatedID(data, "view", "view_id"); it.id == "" {
+				return fmt.Errorf("响应中没有 view_id")
+			}
+		case it.oldName != "":
+			if _, err := call("PATCH", bitableViewPath(live.token, t.id, it.id), nil, map[string]any{"name": v.Name}); err != nil {
+				return err
+			}
+		}
+		m := &schemaRefMapper{live: live, cur: t}
+		for _, kind := range changes {
+			if kind == "name" {
+				continue
+			}
+			body := m.toIDs(schemaViewConfigBody(kind, v.Props[kind]))
+			if _, err := call("PUT", bitableViewPath(live.token, t.id, it.id, kind), nil, body); err != nil {
+				return fmt.Errorf("设置 %s 配置失败: %w", kind, err)
+			}
+		}
+		return nil
+	}
+	return op
+}
+
+// schemaFieldViewRemovals 列出线上存在、文件未声明的视图和字段（视图在前）；主字段不能删除
+func schemaFieldViewRemovals(live *liveBase, p *schemaTablePair, prune bool) []*schemaOp {
+	var ops []*schemaOp
+	t := p.live
+	if len(p.file.Views) > 0 {
+		for _, it := range t.views {
+			if !schemaItemMatched(p.views, it) {
+				it := it
+				ops = append(ops, removalOp(prune, "view", t.name, it.Name, func(call baseV3Caller) error {
+					_, err := call("DELETE", bitableViewPath(live.token, t.id, it.id), nil, nil)
+					return err
+				}))
+			}
+		}
+	}
+	for _, it := range t.fields {
+		if schemaItemMatched(p.fields, it) {
+			continue
+		}
+		if it.primary {
+			ops = append(ops, &schemaOp{Action: schemaOpSkip, Kind: "field", Table: t.name, Name: it.Name,
+				Note: "主字段未在文件中声明，且不能删除"})
+			continue
+		}
+		it := it
+		ops = append(ops, removalOp(prune, "field", t.name, it.Name, func(call baseV3Caller) error {
+			_, err := call("DELETE", bitableFieldPath(live.token, t.id, it.id), nil, nil)
+			return err
+		}))
+	}
+	return ops
+}
+
+func schemaItemMatched(matched map[*schemaItem]*liveItem, it *liveItem) bool {
+	for _, m := range matched {
+		if m == it {
+			return true
+		}
+	}
+	return false
+}
+
+// removalOp 在 --prune 时生成删除操作，否则只记录为 extra
+func removalOp(prune bool, kind, table, name string, run func(call baseV3Caller) error) *schemaOp {
+	if !prune {
+		return &schemaOp{Action: schemaOpExtra, Kind: kind, Table: table, Name: name}
+	}
+	return &schemaOp{Action: schemaOpDelete, Kind: kind, Table: table, Name: name, run: run}
+}
+
+// createSchemaTable 建表，并回填建表自带的主字段和默认视图的 ID
+func createSchemaTable(live *liveBase, t *liveTable) func(call baseV3Caller) error {
+	return func(call baseV3Caller) error {
+		data, err := call("POST", bitableTablePath(live.token), nil, map[string]any{"name": t.name})
+		if err != nil {
+			return err
+		}
+		if t.id = pickCreatedID(data, "table", "table_id"); t.id == "" {
+			return fmt.Errorf("响应中没有 table_id")
+		}
+		fresh := &liveTable{id: t.id, name: t.name}
+		if err := fetchLiveTable(call, live.token, fresh); err != nil {
+			return err
+		}
+		if primary := findLiveItem(fresh.fields, func(it *liveItem) bool { return it.primary }); primary != nil {
+			t.fields[0].id = primary.id
+		}
+		if len(fresh.views) > 0 {
+			t.views[0].id = fresh.views[0].id
+		}
+		return nil
+	}
+}
+
+// pickCreatedID 从创建接口的响应中取 ID，兼容 {id}、{<kind>_id} 与 {<kind>:{...}} 三种形状
+func pickCreatedID(data map[string]any, kind, idKey string) string {
+	if id := pickString(data, idKey, "id"); id != "" {
+		return id
+	}
+	if nested, ok := data[kind].(map[string]any); ok {
+		return pickString(nested, idKey, "id")
+	}
+	return ""
+}
+
+// applySchemaPlan 依次执行计划中的操作，返回已执行的数量；失败即停止
+func applySchemaPlan(call baseV3Caller, live *liveBase, ops []*schemaOp) (int, error) {
+	total := countSchemaChanges(ops)
+	applied := 0
+	for _, op := range ops {
+		if op.run == nil {
+			continue
+		}
+		if err := op.run(call); err != nil {
+			return applied, fmt.Errorf("%s失败（已完成 %d/%d 项）: %w", op.describe(), applied, total, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// countSchemaChanges 统计会执行的操作数
+func countSchemaChanges(ops []*schemaOp) int {
+	n := 0
+	for _, op := range ops {
+		if op.run != nil {
+			n++
+		}
+	}
+	return n
+}
+
+var schemaOpSymbols = map[string]string{
+	schemaOpCreate: "+", schemaOpUpdate: "~", schemaOpDelete: "-", schemaOpSkip: "!", schemaOpExtra: "?",
+}
+
+var schemaKindLabels = map[string]string{"table": "数据表", "field": "字段", "view": "视图"}
+
+func (op *schemaOp) describe() string {
+	target := op.Table
+	if op.Name != "" {
+		target += "." + op.Name
+	}
+	verb := map[string]string{schemaOpCreate: "创建", schemaOpUpdate: "更新", schemaOpDelete: "删除"}[op.Action]
+	return verb + schemaKindLabels[op.Kind] + " " + target
+}
+
+// printSchemaPlan 逐行打印变更计划
+func printSchemaPlan(w io.Writer, ops []*schemaOp, prune bool) {
+	extras := 0
+	for _, op := range ops {
+		line := schemaOpSymbols[op.Action] + " " + schemaKindLabels[op.Kind] + " " + op.Table
+		if op.Name != "" {
+			line += "." + op.Name
+		}
+		if op.Type != "" {
+			line += " (" + op.Type + ")"
+		}
+		if len(op.Changes) > 0 {
+			line += ": " + strings.Join(op.Changes, ", ")
+		}
+		note := op.Note
+		if op.Action == schemaOpExtra {
+			extras++
+			note = joinNotes("未在文件中声明", note)
+		}
+		if note != "" {
+			line += "（" + note + "）"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if n := countSchemaChanges(ops); n == 0 {
+		fmt.Fprintln(w, "无变更：多维表格结构与 schema 文件一致")
+	} else {
+		fmt.Fprintf(w, "共 %d 项变更\n", n)
+	}
+	if extras > 0 && !prune {
+		fmt.Fprintf(w, "%d 项未在文件中声明，加 --prune 删除\n", extras)
+	}
+}
+
+// schemaSubset 判断 want（文件中写出的值）是否被 got（线上值）包含：
+// 对象只比较 want 中出现的键，数组逐项比较，标量按 JSON 编码比较（忽略 int/float 差异）
+func schemaSubset(want, got any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !schemaSubset(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !schemaSubset(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	a, _ := json.Marshal(want)
+	b, _ := json.Marshal(got)
+	return string(a) == string(b)
+}
+
+func joinNotes(notes ...string) string {
+	var parts []string
+	for _, n := range notes {
+		if n != "" {
+			parts = append(parts, n)
+		}
+	}
+	return strings.Join(parts, "，")
+}
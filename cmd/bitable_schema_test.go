@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/mock"
+	"gopkg.in/yaml.v3"
+)
+
+// newSchemaTestBase 构造一个有「项目」「任务」两张表的线上结构
+func newSchemaTestBase() *liveBase {
+	item := func(id, name, typ string, props map[string]any) *liveItem {
+		return &liveItem{schemaItem: schemaItem{Name: name, Type: typ, Props: props}, id: id}
+	}
+	project := &liveTable{id: "tblP", name: "项目", fields: []*liveItem{
+		item("fldP1", "项目名", "text", nil),
+		item("fldP2", "负责人", "user", nil),
+	}}
+	project.fields[0].primary = true
+	task := &liveTable{id: "tblT", name: "任务", fields: []*liveItem{
+		item("fldT1", "标题", "text", nil),
+		item("fldT2", "状态", "select", map[string]any{"options": []any{map[string]any{"name": "待办", "id": "opt1"}}}),
+		item("fldT3", "所属项目", "link", map[string]any{"link_table": "tblP"}),
+		item("fldT4", "负责人", "lookup", map[string]any{"link_field": "fldT3", "target_field": "fldP2"}),
+		item("fldT5", "旧字段", "text", nil),
+	}, views: []*liveItem{
+		item("vew1", "全部", "grid", map[string]any{"sort": map[string]any{"sort_config": []any{map[string]any{"field_id": "fldT2", "desc": true}}}}),
+		item("vew2", "甘特", "gantt", nil),
+	}}
+	task.fields[0].primary = true
+	return &liveBase{token: "bas1", tables: []*liveTable{project, task}}
+}
+
+func TestSchemaRefMapperRoundTrip(t *testing.T) {
+	live := newSchemaTestBase()
+	task := live.tables[1]
+	withIDs := map[string]any{
+		"link_table":   "tblP",
+		"target_field": "fldP2",
+		"expression":   "LEN($table[tblT].$field[fldT1]) + $field[fldT2]",
+		"card":         map[string]any{"cover_field_id": "fldT1", "display_fields": []any{"fldT2", "fldT3"}},
+		"conditions":   []any{map[string]any{"field_id": "fldT4", "value": []any{"fldT1"}}},
+	}
+	m := &schemaRefMapper{live: live, cur: task}
+	names := m.toNames(withIDs)
+	want := map[string]any{
+		"link_table":   "项目",
+		"target_field": "项目.负责人", // 其它表的字段带表名
+		"expression":   "LEN($table[任务].$field[标题]) + $field[状态]",
+		"card":         map[string]any{"cover_field_id": "标题", "display_fields": []any{"状态", "所属项目"}},
+		"conditions":   []any{map[string]any{"field_id": "负责人", "value": []any{"fldT1"}}}, // 条件值不是引用
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("toNames =\n%v\nwant\n%v", names, want)
+	}
+	if back := m.toIDs(names); !reflect.DeepEqual(back, withIDs) || len(m.missing) > 0 {
+		t.Fatalf("toIDs =\n%v\nwant\n%v (missing %v)", back, withIDs, m.missing)
+	}
+
+	m = &schemaRefMapper{live: live, cur: task}
+	m.toIDs(map[string]any{"field_id": "不存在"})
+	if !reflect.DeepEqual(m.missing, []string{"不存在"}) {
+		t.Errorf("missing = %v", m.missing)
+	}
+}
+
+func TestPlanBitableSchema(t *testing.T) {
+	src := `
+version: 1
+tables:
+  - name: 项目
+    fields:
+      - {name: 项目名称, type: text}
+      - {name: 负责人, type: user}
+  - name: 任务
+    fields:
+      - {name: 标题, type: text}
+      - name: 状态
+        type: select
+        options: [{name: 待办}, {name: 完成}]
+      - {name: 所属项目, type: link, link_table: 项目}
+      - {name: 负责人, type: lookup, link_field: 所属项目, target_field: 项目.负责人}
+      - {name: 周报, type: link, link_table: 周报}
+    views:
+      - name: 全部
+        type: grid
+        sort: [{field_id: 状态, desc: true}]
+      - {name: 甘特, type: kanban}
+      - name: 看板
+        type: kanban
+        group: [{field_id: 状态}]
+  - name: 周报
+    fields:
+      - {name: 标题, type: text}
+      - {name: 任务, type: link, link_table: 任务}
+      - {name: 任务数, type: formula, expression: "COUNTA($table[周报].$field[任务])"}
+    views:
+      - {name: 全部周报, type: grid}
+`
+	var schema bitableSchema
+	if err := yaml.Unmarshal([]byte(src), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, op := range planBitableSchema(&schema, newSchemaTestBase(), false) {
+		got = append(got, strings.TrimSpace(fmt.Sprintf("%s %s %s.%s %s %s", op.Action, op.Kind, op.Table, op.Name, strings.Join(op.Changes, ","), op.Note)))
+	}
+	want := []string{
+		"create table 周报.",
+		"create field 任务.周报",
+		"create field 周报.任务",
+		"create field 周报.任务数", // 公式引用的「任务」字段先创建
+		"update field 项目.项目名称 name 主字段，原名 项目名",
+		"update field 任务.状态 options", // 只比较文件中写出的键：线上选项多出的 id 不算差异
+		"update field 周报.标题  主字段，替换建表自带的主字段",
+		"skip view 任务.甘特  视图类型 gantt → kanban 无法修改，请删除后重建",
+		"create view 任务.看板 group",
+		"update view 周报.全部周报 name 原名 <默认视图>",
+		"extra field 任务.旧字段",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plan =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// newMockBaseV3 启动内存版 OpenAPI 服务（internal/mock），返回指向它的 base/v3 调用器。
+// 用固定的 User Token 调用：mock 只校验带了 Bearer，且不会把 mock 签发的 tenant token 留在 SDK 的全局缓存里
+func newMockBaseV3(t *testing.T) (*mock.Server, baseV3Caller) {
+	t.Helper()
+	srv := mock.New()
+	t.Cleanup(stubCmdFeishuServer(t, srv.ServeHTTP))
+	return srv, newBaseV3Caller("u-mock")
+}
+
+func TestApplySchemaPlan(t *testing.T) {
+	schema := &bitableSchema{Version: 1, Tables: []*schemaTable{{
+		Name: "里程碑",
+		Fields: []*schemaItem{
+			{Name: "名称", Type: "text"},
+			{Name: "剩余天数", Type: "formula", Props: map[string]any{"expression": "$field[截止] - TODAY()"}},
+			{Name: "截止", Type: "datetime"},
+		},
+		Views: []*schemaItem{{Name: "按截止排序", Type: "grid", Props: map[string]any{"sort": []any{map[string]any{"field_id": "截止"}}}}},
+	}}}
+	live := &liveBase{token: "bas1"}
+	ops := planBitableSchema(schema, live, false)
+
+	_, call := newMockBaseV3(t)
+	applied, err := applySchemaPlan(call, live, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != countSchemaChanges(ops) {
+		t.Errorf("applied = %d, want %d", applied, countSchemaChanges(ops))
+	}
+
+	// 按线上结果核对：建表自带的主字段被改名，公式与视图排序里的字段名换成了新字段 ID
+	got, err := fetchLiveBase(call, "bas1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.tables) != 1 || got.tables[0].name != "里程碑" {
+		t.Fatalf("tables = %+v", got.tables)
+	}
+	table := got.tables[0]
+	var fields []string
+	for _, f := range table.fields {
+		fields = append(fields, fmt.Sprintf("%s %s %v %v", f.Name, f.Type, f.primary, f.Props))
+	}
+	deadline := table.fields[1].id
+	wantFields := []string{
+		"名称 text true map[]",
+		"截止 datetime false map[]",
+		"剩余天数 formula false map[expression:$field[" + deadline + "] - TODAY()]",
+	}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("fields =\n%s\nwant\n%s", strings.Join(fields, "\n"), strings.Join(wantFields, "\n"))
+	}
+	if len(table.views) != 1 || table.views[0].Name != "按截止排序" {
+		t.Fatalf("views = %+v", table.views)
+	}
+	if want := map[string]any{"sort_config": []any{map[string]any{"field_id": deadline}}}; !reflect.DeepEqual(table.views[0].Props["sort"], want) {
+		t.Errorf("sort = %v, want %v", table.views[0].Props["sort"], want)
+	}
+}
+
+func TestSchemaItemJSON(t *testing.T) {
+	s := &bitableSchema{Version: 1, Tables: []*schemaTable{{Name: "表", Fields: []*schemaItem{
+		{Name: "状态", Type: "select", Props: map[string]any{"options": []any{map[string]any{"name": "A"}}, "multiple": false}},
+	}}}}
+	data, err := encodeBitableSchema(s, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `{
+          "name": "状态",
+          "type": "select",
+          "multiple": false,
+          "options": [`) {
+		t.Errorf("name/type 应排在最前:\n%s", data)
+	}
+	yamlData, err := encodeBitableSchema(s, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back bitableSchema
+	if err := yaml.Unmarshal(yamlData, &back); err != nil {
+		t.Fatal(err)
+	}
+	if f := back.Tables[0].Fields[0]; f.Name != "状态" || f.Props["multiple"] != false || len(f.Props) != 2 {
+		t.Errorf("YAML 往返后 = %+v\n%s", f, yamlData)
+	}
+}
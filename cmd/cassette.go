@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/riba2534/feishu-cli/internal/cassette"
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+)
+
+var (
+	recordDir string
+	replayDir string
+)
+
+// 回放时缺省的占位凭证：回放不访问网络，只需让配置校验与 SDK 的非空检查通过。
+const (
+	replayAppID     = "cli_replay"
+	replayAppSecret = "replay"
+	replayUserToken = "u-replay"
+)
+
+// setupCassette 按 --record / --replay 给 SDK client 装上录制或回放层，替换 setupHTTPClient 安装的客户端；
+// 都未指定时不做任何事。只覆盖 client.GetClient() 构建的 SDK client；少数直连 http.DefaultClient 的下载路径不在其内。
+func setupCassette() error {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return fmt.Errorf("--record 与 --replay 不能同时使用")
+	case recordDir != "":
+		network, err := networkHTTPClient()
+		if err != nil {
+			return err
+		}
+		rec, err := cassette.NewRecorder(recordDir, network)
+		if err != nil {
+			return err
+		}
+		client.SetHTTPClient(rec)
+		fmt.Fprintf(os.Stderr, "[cassette] 录制到 %s（Token 与 app_secret 已脱敏）\n", recordDir)
+	case replayDir != "":
+		player, err := cassette.Load(replayDir)
+		if err != nil {
+			return err
+		}
+		client.SetHTTPClient(player)
+		cfg := config.Get()
+		if cfg.AppID == "" || cfg.AppSecret == "" {
+			cfg.AppID, cfg.AppSecret = replayAppID, replayAppSecret
+		}
+		// 录制时走了 User Token，回放端也要让命令走同一身份分支，且不能触发 token.json 刷新
+		if player.UsedUserToken() && os.Getenv("FEISHU_USER_ACCESS_TOKEN") == "" {
+			os.Setenv("FEISHU_USER_ACCESS_TOKEN", replayUserToken)
+		}
+		fmt.Fprintf(os.Stderr, "[cassette] 从 %s 回放，不访问网络\n", replayDir)
+	}
+	return nil
+}
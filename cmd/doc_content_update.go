@@ -414,21 +414,13 @@ func doAppend(documentID, markdown string, uploadImages bool, output, userAccess
 
 // doOverwrite 完全覆盖文档内容
 func doOverwrite(documentID, markdown string, uploadImages bool, output, userAccessToken, colWidthMode string, colWidthValues []int) error {
-	// 1. 获取根块，仅需子块 ID 列表（避免获取所有子块的完整内容）
-	rootBlock, err := client.GetBlock(documentID, documentID, userAccessToken)
-	if err != nil {
-		return fmt.Errorf("获取文档内容失败: %w", err)
-	}
-
-	// 2. 删除所有现有子块
-	if rootBlock.Children != nil && len(rootBlock.Children) > 0 {
-		if _, err := client.DeleteBlocks(documentID, documentID, 0, len(rootBlock.Children), userAccessToken); err != nil {
-			return fmt.Errorf("删除现有内容失败: %w", err)
-		}
+	// 1. 删除所有现有子块
+	if err := clearDocumentContent(documentID, userAccessToken); err != nil {
+		return err
 	}
 
-	// 3. 创建新内容
-	err = addContentMarkdownWithOptions(documentID, documentID, markdown, "", uploadImages, -1, output, userAccessToken, colWidthMode, colWidthValues)
+	// 2. 创建新内容
+	err := addContentMarkdownWithOptions(documentID, documentID, markdown, "", uploadImages, -1, output, userAccessToken, colWidthMode, colWidthValues)
 	if err != nil {
 		return fmt.Errorf("写入新内容失败: %w", err)
 	}
@@ -438,6 +430,21 @@ func doOverwrite(documentID, markdown string, uploadImages bool, output, userAcc
 	return nil
 }
 
+// clearDocumentContent 删除文档根块下的全部子块
+func clearDocumentContent(documentID, userAccessToken string) error {
+	// 获取根块，仅需子块 ID 列表（避免获取所有子块的完整内容）
+	rootBlock, err := client.GetBlock(documentID, documentID, userAccessToken)
+	if err != nil {
+		return fmt.Errorf("获取文档内容失败: %w", err)
+	}
+	if len(rootBlock.Children) > 0 {
+		if _, err := client.DeleteBlocks(documentID, documentID, 0, len(rootBlock.Children), userAccessToken); err != nil {
+			return fmt.Errorf("删除现有内容失败: %w", err)
+		}
+	}
+	return nil
+}
+
 // doReplaceRange 按定位替换一段内容
 func doReplaceRange(documentID, markdown, selByTitle, selWithEllipsis string, uploadImages bool, output, userAccessToken, colWidthMode string, colWidthValues []int) error {
 	children, err := getPageChildren(documentID, userAccessToken)
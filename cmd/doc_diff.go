@@ -0,0 +1,503 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/converter"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var docDiffCmd = &cobra.Command{
+	Use:   "diff <document_id|url>",
+	Short: "按块比较两个文档、文档的两个版本，或文档与本地 Markdown",
+	Long: `以顶层块为单位比较文档内容，报告新增、删除、修改、移动的块及其所在标题路径。
+只读取内容，不修改任何文档。
+
+比较对象（三选一）:
+  --against <document_id|url>  旧 = <document_id>，新 = 另一篇文档
+  --file <file.md>             旧 = <document_id>，新 = 本地 Markdown（即按 doc import 导入后会产生的变化）
+  --revision <N>               旧 = <document_id> 的历史版本 N，新 = 当前版本
+
+块按「类型 + 文本内容 + 子块」比较，与 doc import --incremental 的对齐规则一致：
+同类型文本块的改动视为修改，其余改动视为删除 + 新增；图片、画板等资源块只按类型与位置比较。
+本地 Markdown 中的 Mermaid/PlantUML 图表对应文档中的画板，不比较图表源码。
+
+输出:
+  默认         unified diff，每处差异以 @@ 操作 · 标题路径 @@ 开头，内容为块的 Markdown 渲染
+  --view rendered  逐字标注修改：[-删除-]、{+新增+}，加粗/斜体/链接等样式变化写作 {~文字~}(旧样式 → 新样式)
+  --format / --jq  结构化输出（-o json 等价 --format json）
+
+示例:
+  feishu-cli doc diff DocA --against DocB
+  feishu-cli doc diff DocA --file docs/guide.md
+  feishu-cli doc diff DocA --revision 12 --view rendered
+  feishu-cli doc diff DocA --file docs/guide.md -o json --jq '.summary'
+  feishu-cli doc diff DocA --file docs/guide.md --exit-code   # 有差异时以非 0 状态退出，便于 CI`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		documentID, err := extractDocToken(args[0])
+		if err != nil {
+			return err
+		}
+		against, _ := cmd.Flags().GetString("against")
+		filePath, _ := cmd.Flags().GetString("file")
+		revision, _ := cmd.Flags().GetInt("revision")
+		view, _ := cmd.Flags().GetString("view")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		modes := 0
+		for _, set := range []bool{against != "", filePath != "", cmd.Flags().Changed("revision")} {
+			if set {
+				modes++
+			}
+		}
+		if modes != 1 {
+			return fmt.Errorf("请指定且只指定 --against、--file、--revision 之一")
+		}
+		if cmd.Flags().Changed("revision") && revision <= 0 {
+			return fmt.Errorf("--revision 必须为正整数（当前 %d）", revision)
+		}
+		if view != "unified" && view != "rendered" {
+			return fmt.Errorf("--view 仅支持 unified、rendered，当前值: %s", view)
+		}
+		o, structured, err := resolveMarkdownDiffOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
+
+		var oldLabel, newLabel string
+		var oldBlocks, newBlocks []docDiffBlock
+		switch {
+		case against != "":
+			otherID, err := extractDocToken(against)
+			if err != nil {
+				return err
+			}
+			oldLabel, newLabel = documentID, otherID
+			if oldBlocks, err = fetchDocDiffBlocks(documentID, 0, userAccessToken); err != nil {
+				return err
+			}
+			if newBlocks, err = fetchDocDiffBlocks(otherID, 0, userAccessToken); err != nil {
+				return err
+			}
+		case filePath != "":
+			oldLabel, newLabel = documentID, filePath
+			if oldBlocks, err = fetchDocDiffBlocks(documentID, 0, userAccessToken); err != nil {
+				return err
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return fmt.Errorf("读取文件失败: %w", err)
+			}
+			if err := validateMarkdownEncoding(content); err != nil {
+				return err
+			}
+			if newBlocks, err = markdownDiffBlocks(string(content), filepath.Dir(filePath)); err != nil {
+				return err
+			}
+		default:
+			oldLabel, newLabel = fmt.Sprintf("%s@revision=%d", documentID, revision), documentID
+			if oldBlocks, err = fetchDocDiffBlocks(documentID, revision, userAccessToken); err != nil {
+				return err
+			}
+			if newBlocks, err = fetchDocDiffBlocks(documentID, 0, userAccessToken); err != nil {
+				return err
+			}
+		}
+
+		result := buildDocDiff(oldBlocks, newBlocks)
+		result.Old, result.New = oldLabel, newLabel
+
+		switch {
+		case structured:
+			if err := output.Render(o, result); err != nil {
+				return err
+			}
+		case view == "rendered":
+			printRenderedDocDiff(os.Stdout, result)
+		default:
+			printUnifiedDocDiff(os.Stdout, result)
+		}
+		if exitCode && !result.Identical {
+			return fmt.Errorf("两侧内容存在 %d 处差异", len(result.Changes))
+		}
+		return nil
+	},
+}
+
+// docDiffBlock 是参与比较的一个顶层块
+type docDiffBlock struct {
+	entry    converter.BlockDiffEntry
+	typ      string // 块类型名
+	markdown string // 块的 Markdown 渲染
+	level    int    // 标题级别，非标题为 0
+	title    string // 标题纯文本
+	path     []string
+	elements []*larkdocx.TextElement // 文本类块的文本元素，用于逐字比较
+}
+
+// docDiffChange 是一处块级差异
+type docDiffChange struct {
+	Op          string                     `json:"op"`           // modified / inserted / deleted / moved
+	HeadingPath []string                   `json:"heading_path"` // 所在标题路径（标题块含自身），删除取旧侧，其余取新侧
+	BlockType   string                     `json:"block_type"`
+	OldIndex    int                        `json:"old_index"` // 顶层块下标（0-based），-1 表示该侧不存在
+	NewIndex    int                        `json:"new_index"`
+	OldMarkdown string                     `json:"old_markdown,omitempty"`
+	NewMarkdown string                     `json:"new_markdown,omitempty"`
+	Inline      []converter.InlineDiffSpan `json:"inline,omitempty"` // modified 文本块的逐字差异
+}
+
+// docDiffResult 是 doc diff 的完整结果
+type docDiffResult struct {
+	Old       string                     `json:"old"`
+	New       string                     `json:"new"`
+	Identical bool                       `json:"identical"`
+	Summary   converter.BlockDiffSummary `json:"summary"`
+	Changes   []docDiffChange            `json:"changes"`
+}
+
+// fetchDocDiffBlocks 拉取文档（revision > 0 时为该历史版本）并拆为顶层块
+func fetchDocDiffBlocks(documentID string, revision int, userAccessToken string) ([]docDiffBlock, error) {
+	blocks, err := client.GetAllBlocksAtRevision(documentID, revision, userAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("获取文档 %s 内容失败: %w", documentID, err)
+	}
+	return documentDiffBlocks(documentID, blocks, converter.ConvertOptions{DocumentID: documentID, UserAccessToken: userAccessToken})
+}
+
+// documentDiffBlocks 把文档块列表拆为根块下的顶层块，逐块计算签名与 Markdown 渲染
+func documentDiffBlocks(documentID string, blocks []*larkdocx.Block, options converter.ConvertOptions) ([]docDiffBlock, error) {
+	blockMap := make(map[string]*larkdocx.Block, len(blocks))
+	var page *larkdocx.Block
+	for _, b := range blocks {
+		if b == nil || b.BlockId == nil {
+			continue
+		}
+		blockMap[*b.BlockId] = b
+		if b.BlockType != nil && *b.BlockType == int(converter.BlockTypePage) && page == nil {
+			page = b
+		}
+	}
+	if p, ok := blockMap[documentID]; ok {
+		page = p
+	}
+	if page == nil {
+		return nil, fmt.Errorf("文档 %s 未返回根块", documentID)
+	}
+
+	conv := converter.NewBlockToMarkdown(blocks, options)
+	var result []docDiffBlock
+	for _, id := range page.Children {
+		block, ok := blockMap[id]
+		if !ok {
+			continue
+		}
+		md, err := conv.ConvertBlockByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("渲染块 %s 失败: %w", id, err)
+		}
+		result = append(result, newDocDiffBlock(block, converter.DocumentBlockDiffEntry(block, blockMap), md))
+	}
+	assignHeadingPaths(result)
+	return result, nil
+}
+
+// markdownDiffBlocks 按 doc import --incremental 的规则把本地 Markdown 转换为顶层块，
+// 再给块节点分配临时 block_id，用与文档侧相同的 BlockToMarkdown 渲染
+func markdownDiffBlocks(markdown, basePath string) ([]docDiffBlock, error) {
+	items, err := buildSyncItems(parseMarkdownSegments(markdown), "", true, basePath, "", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	var blocks []*larkdocx.Block
+	var topIDs []string
+	for i, item := range items {
+		if item.node == nil {
+			topIDs = append(topIDs, "")
+			continue
+		}
+		id := fmt.Sprintf("local_%d", i)
+		blocks = append(blocks, materializeBlockNode(item.node, item.tableData, id)...)
+		topIDs = append(topIDs, id)
+	}
+
+	conv := converter.NewBlockToMarkdown(blocks, converter.ConvertOptions{})
+	var result []docDiffBlock
+	for i, item := range items {
+		if item.diagram != nil {
+			md := "```" + item.diagram.kind + "\n" + strings.TrimRight(item.diagram.content, "\n") + "\n```"
+			result = append(result, docDiffBlock{entry: item.entry, typ: converter.BlockTypeName(converter.BlockTypeBoard), markdown: md})
+			continue
+		}
+		md, err := conv.ConvertBlockByID(topIDs[i])
+		if err != nil {
+			return nil, fmt.Errorf("渲染第 %d 个块失败: %w", i+1, err)
+		}
+		if isUploadImageBlockNode(item.node) && len(item.imageSources) > 0 {
+			md = "![](" + item.imageSources[0] + ")" // 尚未上传的图片没有 token，显示本地来源
+		}
+		result = append(result, newDocDiffBlock(item.node.Block, item.entry, md))
+	}
+	assignHeadingPaths(result)
+	return result, nil
+}
+
+// materializeBlockNode 把块节点树展开为扁平块列表：节点按先序分配 id、填好 Children；
+// 表格按 TableData 补出单元格与单元格内的文本块
+func materializeBlockNode(node *converter.BlockNode, tableData *converter.TableData, id string) []*larkdocx.Block {
+	block := *node.Block
+	block.BlockId = &id
+	block.Children = nil
+	blocks := []*larkdocx.Block{&block}
+
+	if tableData != nil && block.Table != nil {
+		table := *block.Table
+		prop := &larkdocx.TableProperty{}
+		if table.Property != nil {
+			p := *table.Property
+			prop = &p
+		}
+		rows := tableData.Rows + len(tableData.ExtraRowContents)
+		prop.RowSize, prop.ColumnSize = &rows, &tableData.Cols
+		table.Property = prop
+		table.Cells = nil
+		block.Table = &table
+
+		cells := make([][]*larkdocx.TextElement, 0, rows*tableData.Cols)
+		for i, content := range tableData.CellContents {
+			cells = append(cells, cellElementsOrText(tableData.CellElements, i, content))
+		}
+		for r, row := range tableData.ExtraRowContents {
+			var rowElements [][]*larkdocx.TextElement
+			if r < len(tableData.ExtraRowElements) {
+				rowElements = tableData.ExtraRowElements[r]
+			}
+			for c, content := range row {
+				cells = append(cells, cellElementsOrText(rowElements, c, content))
+			}
+		}
+		cellType, textType := int(converter.BlockTypeTableCell), int(converter.BlockTypeText)
+		for i, elements := range cells {
+			cellID, textID := fmt.Sprintf("%s.c%d", id, i), fmt.Sprintf("%s.c%d.t", id, i)
+			table.Cells = append(table.Cells, cellID)
+			block.Children = append(block.Children, cellID)
+			blocks = append(blocks,
+				&larkdocx.Block{BlockId: &cellID, BlockType: &cellType, TableCell: &larkdocx.TableCell{}, Children: []string{textID}},
+				&larkdocx.Block{BlockId: &textID, BlockType: &textType, Text: &larkdocx.Text{Elements: elements}})
+		}
+		return blocks
+	}
+
+	for i, child := range node.Children {
+		childID := fmt.Sprintf("%s.%d", id, i)
+		block.Children = append(block.Children, childID)
+		blocks = append(blocks, materializeBlockNode(child, nil, childID)...)
+	}
+	return blocks
+}
+
+func cellElementsOrText(elements [][]*larkdocx.TextElement, i int, content string) []*larkdocx.TextElement {
+	if i < len(elements) && elements[i] != nil {
+		return elements[i]
+	}
+	return []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &content}}}
+}
+
+func newDocDiffBlock(block *larkdocx.Block, entry converter.BlockDiffEntry, md string) docDiffBlock {
+	b := docDiffBlock{entry: entry, markdown: md}
+	if block == nil || block.BlockType == nil {
+		return b
+	}
+	bt := converter.BlockType(*block.BlockType)
+	b.typ = converter.BlockTypeName(bt)
+	if body := converter.BlockTextBody(block); body != nil {
+		b.elements = body.Elements
+	}
+	if bt >= converter.BlockTypeHeading1 && bt <= converter.BlockTypeHeading9 {
+		b.level = int(bt-converter.BlockTypeHeading1) + 1
+		b.title = elementsPlainText(b.elements)
+	}
+	return b
+}
+
+func elementsPlainText(elements []*larkdocx.TextElement) string {
+	var sb strings.Builder
+	for _, elem := range elements {
+		if elem != nil && elem.TextRun != nil && elem.TextRun.Content != nil {
+			sb.WriteString(*elem.TextRun.Content)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// assignHeadingPaths 为每个块记录所在的标题路径（标题块的路径包含自身）
+func assignHeadingPaths(blocks []docDiffBlock) {
+	var stack []docDiffBlock
+	for i := range blocks {
+		if level := blocks[i].level; level > 0 {
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, blocks[i])
+		}
+		path := make([]string, len(stack))
+		for j, h := range stack {
+			path[j] = h.title
+		}
+		blocks[i].path = path
+	}
+}
+
+// buildDocDiff 对齐两侧顶层块，把编辑脚本整理为差异列表；移动只在新位置报告一次
+func buildDocDiff(oldBlocks, newBlocks []docDiffBlock) *docDiffResult {
+	oldEntries := make([]converter.BlockDiffEntry, len(oldBlocks))
+	for i, b := range oldBlocks {
+		oldEntries[i] = b.entry
+	}
+	newEntries := make([]converter.BlockDiffEntry, len(newBlocks))
+	for i, b := range newBlocks {
+		newEntries[i] = b.entry
+	}
+	edits := converter.DiffBlocks(oldEntries, newEntries)
+
+	result := &docDiffResult{Summary: converter.SummarizeBlockEdits(edits), Changes: []docDiffChange{}}
+	for _, e := range edits {
+		change := docDiffChange{OldIndex: e.OldIndex, NewIndex: e.NewIndex}
+		switch {
+		case e.Op == converter.BlockDiffKeep, e.Op == converter.BlockDiffDelete && e.Moved:
+			continue
+		case e.Op == converter.BlockDiffDelete:
+			old := oldBlocks[e.OldIndex]
+			change.Op, change.HeadingPath, change.BlockType, change.OldMarkdown = "deleted", old.path, old.typ, old.markdown
+		default:
+			cur := newBlocks[e.NewIndex]
+			change.HeadingPath, change.BlockType, change.NewMarkdown = cur.path, cur.typ, cur.markdown
+			switch {
+			case e.Op == converter.BlockDiffUpdate:
+				old := oldBlocks[e.OldIndex]
+				change.Op, change.OldMarkdown = "modified", old.markdown
+				change.Inline = converter.DiffTextElements(old.elements, cur.elements)
+			case e.Moved:
+				change.Op, change.OldMarkdown = "moved", oldBlocks[e.OldIndex].markdown
+			default:
+				change.Op = "inserted"
+			}
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	result.Identical = len(result.Changes) == 0
+	return result
+}
+
+// docDiffOpLabels 是差异类型的显示名称
+var docDiffOpLabels = map[string]string{
+	"modified": "修改",
+	"inserted": "新增",
+	"deleted":  "删除",
+	"moved":    "移动",
+}
+
+// docDiffHeader 返回一处差异的标题：操作、位置与标题路径
+func docDiffHeader(c docDiffChange) string {
+	var pos string
+	switch c.Op {
+	case "deleted":
+		pos = fmt.Sprintf("旧第 %d 块", c.OldIndex+1)
+	case "moved":
+		pos = fmt.Sprintf("旧第 %d 块 → 新第 %d 块", c.OldIndex+1, c.NewIndex+1)
+	default:
+		pos = fmt.Sprintf("新第 %d 块", c.NewIndex+1)
+	}
+	path := "（文档开头）"
+	if len(c.HeadingPath) > 0 {
+		path = strings.Join(c.HeadingPath, " > ")
+	}
+	return fmt.Sprintf("%s %s（%s） · %s", docDiffOpLabels[c.Op], c.BlockType, pos, path)
+}
+
+// printUnifiedDocDiff 以 unified diff 形式输出：修改的块按行比较，其余块整块标记
+func printUnifiedDocDiff(w io.Writer, r *docDiffResult) {
+	if r.Identical {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", r.Old, r.New)
+	for _, c := range r.Changes {
+		fmt.Fprintf(w, "@@ %s @@\n", docDiffHeader(c))
+		var ops []editOp
+		switch c.Op {
+		case "modified":
+			ops = diffLines(splitDiffLines(c.OldMarkdown), splitDiffLines(c.NewMarkdown))
+		case "deleted":
+			ops = markDiffLines('-', c.OldMarkdown)
+		case "inserted":
+			ops = markDiffLines('+', c.NewMarkdown)
+		case "moved":
+			ops = markDiffLines(' ', c.NewMarkdown)
+		}
+		for _, op := range ops {
+			fmt.Fprintf(w, "%c%s\n", op.op, op.text)
+		}
+	}
+}
+
+func markDiffLines(op byte, text string) []editOp {
+	var ops []editOp
+	for _, line := range splitDiffLines(text) {
+		ops = append(ops, editOp{op, line})
+	}
+	return ops
+}
+
+// printRenderedDocDiff 逐处输出差异，文本块的修改逐字标注（含行内样式变化）
+func printRenderedDocDiff(w io.Writer, r *docDiffResult) {
+	if r.Identical {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+	fmt.Fprintf(w, "旧: %s\n新: %s\n", r.Old, r.New)
+	for _, c := range r.Changes {
+		fmt.Fprintf(w, "\n=== %s ===\n", docDiffHeader(c))
+		switch {
+		case c.Op == "modified" && converter.InlineDiffChanged(c.Inline):
+			fmt.Fprintln(w, converter.RenderInlineDiff(c.Inline))
+		case c.Op == "modified":
+			fmt.Fprintf(w, "[-%s-]\n{+%s+}\n", c.OldMarkdown, c.NewMarkdown)
+		case c.Op == "deleted":
+			fmt.Fprintf(w, "[-%s-]\n", c.OldMarkdown)
+		case c.Op == "inserted":
+			fmt.Fprintf(w, "{+%s+}\n", c.NewMarkdown)
+		default:
+			fmt.Fprintln(w, c.NewMarkdown)
+		}
+	}
+	s := r.Summary
+	fmt.Fprintf(w, "\n共 %d 处差异：修改 %d，新增 %d，删除 %d，移动 %d\n", len(r.Changes), s.Updated, s.Inserted, s.Deleted, s.Moved)
+}
+
+func init() {
+	docCmd.AddCommand(docDiffCmd)
+	docDiffCmd.Flags().String("against", "", "与另一篇文档比较（document_id 或 URL）")
+	docDiffCmd.Flags().String("file", "", "与本地 Markdown 文件比较")
+	docDiffCmd.Flags().Int("revision", 0, "与本文档的历史版本 N 比较")
+	docDiffCmd.Flags().String("view", "unified", "文本输出形式（unified/rendered）")
+	docDiffCmd.Flags().Bool("exit-code", false, "有差异时以非 0 状态退出")
+	docDiffCmd.Flags().StringP("output", "o", "", "[兼容] -o json 等价 --format json；缺省输出 unified diff 文本")
+	output.AddFormatFlags(docDiffCmd)
+	docDiffCmd.Flags().String("user-access-token", "", "User Access Token（用于访问无 App 权限的文档，自动从 auth login 读取）")
+}
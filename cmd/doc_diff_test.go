@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/converter"
+)
+
+func TestBuildDocDiff(t *testing.T) {
+	oldMD := "# 概述\n\n第一段 **加粗** 文字\n\n## 安装\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```mermaid\ngraph TD\nA-->B\n```\n\n## 附录\n\n旧段落\n"
+	newMD := "# 概述\n\n第一段 加粗 文字，新增\n\n## 安装\n\n| a | b |\n|---|---|\n| 1 | 3 |\n\n```mermaid\ngraph TD\nA-->C\n```\n\n## 附录\n"
+	oldBlocks, err := markdownDiffBlocks(oldMD, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBlocks, err := markdownDiffBlocks(newMD, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := oldBlocks[3].markdown; got != "| a | b |\n| --- | --- |\n| 1 | 2 |" {
+		t.Errorf("表格渲染 = %q", got)
+	}
+
+	r := buildDocDiff(oldBlocks, newBlocks)
+	type row struct {
+		Op   string
+		Type string
+		Path string
+	}
+	var got []row
+	for _, c := range r.Changes {
+		got = append(got, row{c.Op, c.BlockType, strings.Join(c.HeadingPath, ">")})
+	}
+	// 画板只按类型 + 位置比较，图表源码变化不算差异；表格内容变化为删除 + 新增
+	want := []row{
+		{"modified", "Text", "概述"},
+		{"deleted", "Table", "概述>安装"},
+		{"inserted", "Table", "概述>安装"},
+		{"deleted", "Text", "概述>附录"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %+v, want %+v", got, want)
+	}
+	if inline := converter.RenderInlineDiff(r.Changes[0].Inline); inline != "第一段 {~加粗~}(bold → plain) 文字{+，新增+}" {
+		t.Errorf("inline = %q", inline)
+	}
+
+	var buf bytes.Buffer
+	printUnifiedDocDiff(&buf, r)
+	for _, want := range []string{"@@ 修改 Text（新第 2 块） · 概述 @@\n-第一段 **加粗** 文字\n+第一段 加粗 文字，新增\n", "@@ 删除 Text（旧第 7 块） · 概述 > 附录 @@\n-旧段落\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("unified 输出缺少 %q:\n%s", want, buf.String())
+		}
+	}
+
+	if r := buildDocDiff(oldBlocks, oldBlocks); !r.Identical || len(r.Changes) != 0 {
+		t.Errorf("相同内容应无差异: %+v", r)
+	}
+}
+
+func TestDocumentDiffBlocksMovedBlock(t *testing.T) {
+	text := func(id, content string) *larkdocx.Block {
+		return &larkdocx.Block{BlockId: strPtr(id), BlockType: intPtr(int(converter.BlockTypeText)),
+			Text: &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: strPtr(content)}}}}}
+	}
+	page := func(children ...string) *larkdocx.Block {
+		return &larkdocx.Block{BlockId: strPtr("doc"), BlockType: intPtr(int(converter.BlockTypePage)), Children: children}
+	}
+	heading := &larkdocx.Block{BlockId: strPtr("h"), BlockType: intPtr(int(converter.BlockTypeHeading2)),
+		Heading2: &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: strPtr("小节")}}}}}
+
+	oldBlocks, err := documentDiffBlocks("doc", []*larkdocx.Block{page("a", "h", "b"), text("a", "甲"), heading, text("b", "乙")}, converter.ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBlocks, err := documentDiffBlocks("doc", []*larkdocx.Block{page("h", "b", "a"), text("a", "甲"), heading, text("b", "乙")}, converter.ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := oldBlocks[1]; got.markdown != "## 小节" || !reflect.DeepEqual(got.path, []string{"小节"}) {
+		t.Errorf("标题块 = %q %v", got.markdown, got.path)
+	}
+
+	r := buildDocDiff(oldBlocks, newBlocks)
+	if len(r.Changes) != 1 {
+		t.Fatalf("changes = %+v", r.Changes)
+	}
+	if c := r.Changes[0]; c.Op != "moved" || c.OldIndex != 0 || c.NewIndex != 2 || c.NewMarkdown != "甲" || !reflect.DeepEqual(c.HeadingPath, []string{"小节"}) {
+		t.Errorf("moved = %+v", c)
+	}
+	if r.Summary.Moved != 1 {
+		t.Errorf("summary = %+v", r.Summary)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/riba2534/feishu-cli/internal/converter"
+	"github.com/spf13/cobra"
+)
+
+// supportedMermaidTypes 是飞书画板能渲染的 Mermaid 图表类型（首行声明），其余类型导入时降级为代码块
+var supportedMermaidTypes = map[string]bool{
+	"flowchart":       true,
+	"graph":           true,
+	"sequenceDiagram": true,
+	"classDiagram":    true,
+	"stateDiagram":    true,
+	"stateDiagram-v2": true,
+	"erDiagram":       true,
+	"gantt":           true,
+	"pie":             true,
+	"mindmap":         true,
+}
+
+// maxSafeMermaidParticipants 时序图 participant 达到此数量时，叠加 alt 块与长消息容易渲染失败
+const maxSafeMermaidParticipants = 10
+
+var docLintCmd = &cobra.Command{
+	Use:   "lint <file.md>",
+	Short: "导入前检查 Markdown 中会降级或丢失的内容",
+	Long: `按 doc import 的转换规则离线检查 Markdown 文件（不访问网络、不创建文档），
+逐行报告导入后会降级或丢失的写法。
+
+检查项:
+  - 图表：飞书画板不支持的 Mermaid 类型、par 并行语法、缺少结束围栏的代码块
+  - 图片/视频：相对 Markdown 所在目录找不到的本地文件、行内图片、feishu://media/ 引用
+  - 表格：超过 9 列被拆分、数据行过多、拆分后失效的合并单元格
+  - HTML：不支持的块级标签（整段丢弃）、<img>/<a> 等需改写的标签
+  - 样式与链接：HTML 样式标签、非 http(s) 链接、7 个及以上 # 的标题
+
+级别:
+  error    导入时内容会丢失（doc import --strict 的预检遇到 error 会中止导入）
+  warning  导入后效果降级
+
+存在 error 时命令以非 0 状态退出，可直接用于 CI。
+
+示例:
+  feishu-cli doc lint doc.md
+  feishu-cli doc lint doc.md -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+		uploadImages, _ := cmd.Flags().GetBool("upload-images")
+		output, _ := cmd.Flags().GetString("output")
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("读取文件失败: %w", err)
+		}
+		if err := validateMarkdownEncoding(content); err != nil {
+			return err
+		}
+		issues, err := lintMarkdownImport(string(content), filepath.Dir(filePath), uploadImages, "", nil, "")
+		if err != nil {
+			return err
+		}
+		errCount := countLintErrors(issues)
+
+		if output == "json" {
+			if issues == nil {
+				issues = []converter.LintIssue{}
+			}
+			if err := printJSON(map[string]any{
+				"file":     filePath,
+				"errors":   errCount,
+				"warnings": len(issues) - errCount,
+				"issues":   issues,
+			}); err != nil {
+				return err
+			}
+		} else {
+			printLintIssues(os.Stdout, filePath, issues)
+		}
+		if errCount > 0 {
+			return fmt.Errorf("发现 %d 个 error 级问题", errCount)
+		}
+		return nil
+	},
+}
+
+// lintMarkdownImport 按 doc import 的片段划分与转换选项检查 Markdown，返回按行号排序的问题
+func lintMarkdownImport(markdown, basePath string, uploadImages bool, colWidthMode string, colWidthValues []int, userAccessToken string) ([]converter.LintIssue, error) {
+	var issues []converter.LintIssue
+	for _, seg := range parseMarkdownSegments(markdown) {
+		if seg.kind != "markdown" {
+			issues = append(issues, lintDiagramSegment(seg)...)
+			continue
+		}
+		if strings.TrimSpace(seg.content) == "" {
+			continue
+		}
+		result, err := convertImportSegment(seg, "", uploadImages, basePath, colWidthMode, colWidthValues, userAccessToken)
+		if err != nil {
+			return nil, fmt.Errorf("转换 Markdown 失败 (第 %d 行起): %w", seg.line, err)
+		}
+		for _, issue := range result.Issues {
+			if issue.Line > 0 {
+				issue.Line += seg.line - 1
+			} else {
+				issue.Line = seg.line
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues, nil
+}
+
+// lintDiagramSegment 检查图表代码块中飞书画板无法渲染、导入时会降级为代码块的写法
+func lintDiagramSegment(seg segment) []converter.LintIssue {
+	var issues []converter.LintIssue
+	add := func(line int, level, format string, args ...any) {
+		issues = append(issues, converter.LintIssue{Line: line, Level: level, Kind: "diagram", Message: fmt.Sprintf(format, args...)})
+	}
+	if seg.unclosed {
+		add(seg.line, converter.LintError, "```%s 代码块缺少结束围栏，其后全部内容都会被当作图表源码", seg.kind)
+	}
+
+	lines := strings.Split(seg.content, "\n")
+	switch seg.kind {
+	case "plantuml":
+		if !strings.Contains(seg.content, "@start") {
+			add(seg.line, converter.LintWarning, "PlantUML 源码缺少 @startuml/@enduml，服务端解析失败时将降级为代码块")
+		}
+	case "mermaid":
+		headerLine, diagramType := mermaidDiagramType(lines)
+		if diagramType == "" {
+			return issues
+		}
+		if !supportedMermaidTypes[diagramType] {
+			add(seg.line+1+headerLine, converter.LintWarning,
+				"飞书画板不支持 Mermaid 图表类型 %s，导入时将降级为代码块（支持 flowchart/graph、sequenceDiagram、classDiagram、stateDiagram、erDiagram、gantt、pie、mindmap）", diagramType)
+			return issues
+		}
+		if diagramType != "sequenceDiagram" {
+			return issues
+		}
+		participants := make(map[string]bool)
+		for i, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "par":
+				add(seg.line+1+i, converter.LintWarning, "飞书画板不支持 par 并行语法，导入时将降级为代码块（可改用 Note over 说明并行）")
+			case "participant", "actor":
+				if len(fields) > 1 {
+					participants[fields[1]] = true
+				}
+			}
+		}
+		if len(participants) >= maxSafeMermaidParticipants {
+			add(seg.line+1+headerLine, converter.LintWarning,
+				"时序图声明了 %d 个 participant，叠加 alt 块或长消息时服务端容易渲染失败并降级为代码块（建议少于 %d 个）",
+				len(participants), maxSafeMermaidParticipants)
+		}
+	}
+	return issues
+}
+
+// mermaidDiagramType 返回 Mermaid 源码中图表类型声明所在的行下标与类型，跳过空行、%% 注释/指令与 --- 配置头
+func mermaidDiagramType(lines []string) (int, string) {
+	inFrontMatter := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "---":
+			inFrontMatter = !inFrontMatter
+			continue
+		case inFrontMatter, trimmed == "", strings.HasPrefix(trimmed, "%%"):
+			continue
+		}
+		return i, strings.TrimSuffix(strings.Fields(trimmed)[0], ":")
+	}
+	return 0, ""
+}
+
+func countLintErrors(issues []converter.LintIssue) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Level == converter.LintError {
+			n++
+		}
+	}
+	return n
+}
+
+// printLintIssues 以 文件:行号 的形式逐条打印问题并给出汇总
+func printLintIssues(w io.Writer, filePath string, issues []converter.LintIssue) {
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s:%d: %s [%s] %s\n", filePath, issue.Line, issue.Level, issue.Kind, issue.Message)
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "%s: 未发现会降级或丢失的内容\n", filePath)
+		return
+	}
+	errCount := countLintErrors(issues)
+	fmt.Fprintf(w, "共 %d 个问题（error %d，warning %d）\n", len(issues), errCount, len(issues)-errCount)
+}
+
+func init() {
+	docCmd.AddCommand(docLintCmd)
+	docLintCmd.Flags().Bool("upload-images", true, "按开启图片上传检查（与 doc import 的同名参数一致）")
+	docLintCmd.Flags().StringP("output", "o", "", "输出格式 (json)")
+}
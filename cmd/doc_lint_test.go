@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/converter"
+)
+
+func TestLintMarkdownImport(t *testing.T) {
+	md := strings.Join([]string{
+		"# 标题",
+		"",
+		"```mermaid",
+		"%% 注释",
+		"journey",
+		"  title 用户旅程",
+		"```",
+		"",
+		"```mermaid",
+		"sequenceDiagram",
+		"  participant A",
+		"  par 并行",
+		"  A->>A: x",
+		"  end",
+		"```",
+		"",
+		"![缺失](missing.png)",
+		"",
+		"```mermaid",
+		"graph TD",
+		"  A-->B",
+	}, "\n")
+
+	issues, err := lintMarkdownImport(md, t.TempDir(), true, "", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type row struct {
+		Line  int
+		Level string
+		Kind  string
+	}
+	var got []row
+	for _, issue := range issues {
+		got = append(got, row{issue.Line, issue.Level, issue.Kind})
+	}
+	want := []row{
+		{5, converter.LintWarning, "diagram"},  // journey 不受支持
+		{12, converter.LintWarning, "diagram"}, // par
+		{17, converter.LintError, "image"},     // 第二个图表之后的 Markdown 片段，行号需加上片段起始行
+		{19, converter.LintError, "diagram"},   // 缺少结束围栏
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues =\n%+v\nwant\n%+v\nfull: %+v", got, want, issues)
+	}
+	if n := countLintErrors(issues); n != 2 {
+		t.Errorf("countLintErrors = %d, want 2", n)
+	}
+}
+
+func TestMermaidDiagramType(t *testing.T) {
+	lines := []string{"---", "title: x", "---", "", "%%{init: {}}%%", "stateDiagram-v2", "  [*] --> A"}
+	if i, typ := mermaidDiagramType(lines); i != 5 || typ != "stateDiagram-v2" {
+		t.Errorf("mermaidDiagramType = %d %q", i, typ)
+	}
+	if _, typ := mermaidDiagramType([]string{"gitGraph:", "  commit"}); typ != "gitGraph" {
+		t.Errorf("应去掉类型声明末尾的冒号: %q", typ)
+	}
+}
@@ -63,6 +63,7 @@ var doctorCmd = &cobra.Command{
   endpoint_larksuite   open.larksuite.com HTTPS 可达
   proxy                HTTP(S)_PROXY 与 NO_PROXY 配置合理
   dependencies         Go 版本 / SDK 版本
+  rate_limit           跨进程限流的各 API 族剩余配额与近 1 分钟用量（启用时）
 
 输出：
   默认：pretty 表格
@@ -131,6 +132,11 @@ var doctorCmd = &cobra.Command{
 			results = append(results, checkDependencies())
 		}
 
+		// 7. rate_limit
+		if shouldRun("rate_limit", only) {
+			results = append(results, checkRateLimit())
+		}
+
 		// 输出
 		if doctorJSON {
 			return outputJSON(results)
@@ -157,6 +163,7 @@ var validOnlyNames = map[string]bool{
 	"endpoint_larksuite": true,
 	"proxy":              true,
 	"dependencies":       true,
+	"rate_limit":         true,
 }
 
 // parseOnly 解析 --only 参数；空字符串表示全部；包含未知 name 返回 error
@@ -421,6 +428,42 @@ func checkDependencies() checkResult {
 	return checkPass("dependencies", fmt.Sprintf("go=%s larksuite-sdk=%s", goVer, sdkVer))
 }
 
+// checkRateLimit 报告跨进程限流的配额使用情况：状态文件由所有共享 app_id 的进程共同维护，
+// 这里看到的是整个应用（而非本进程）的剩余令牌与近 1 分钟请求数。
+func checkRateLimit() checkResult {
+	limiter, err := newRateLimiter()
+	if err != nil {
+		return checkFail("rate_limit", err.Error(), "检查 "+envRateLimitClasses+" 或 config.yaml 的 rate_limit.classes")
+	}
+	if limiter == nil {
+		return checkSkip("rate_limit", "未启用跨进程限流（config.yaml rate_limit.enabled 或 FEISHU_RATE_LIMIT_ENABLED=true）")
+	}
+	usage, err := limiter.Usage()
+	if err != nil {
+		return checkWarn("rate_limit", "读取限流状态失败: "+err.Error(), "检查 ~/.feishu-cli/ratelimit/ 的权限")
+	}
+	head := fmt.Sprintf("已启用 default=%g/s", limiter.DefaultQPS())
+	if len(usage) == 0 {
+		return checkPass("rate_limit", head+"，暂无调用记录")
+	}
+	parts := make([]string, 0, len(usage))
+	var blocked []string
+	for _, u := range usage {
+		item := fmt.Sprintf("%s %.1f/%g 可用（近 1 分钟 %d 次）", u.Class, u.Available, u.Burst, u.LastMinute)
+		if !u.BlockedUntil.IsZero() {
+			item += "，429 冻结至 " + u.BlockedUntil.Format("15:04:05")
+			blocked = append(blocked, u.Class)
+		}
+		parts = append(parts, item)
+	}
+	msg := head + "；" + strings.Join(parts, "；")
+	if len(blocked) > 0 {
+		return checkWarn("rate_limit", msg,
+			fmt.Sprintf("%s 刚触发服务端限流，所有进程会等到冻结结束；持续出现请调低 rate_limit.classes 中对应速率", strings.Join(blocked, ", ")))
+	}
+	return checkPass("rate_limit", msg)
+}
+
 // ── 输出 ──
 
 func outputJSON(results []checkResult) error {
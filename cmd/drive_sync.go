@@ -0,0 +1,707 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// driveSyncManifestName 是 drive sync 在 --local-dir 根目录下维护的状态文件，不参与同步
+const driveSyncManifestName = ".feishu-sync.json"
+
+const (
+	driveSyncOnConflictReport     = "report"
+	driveSyncOnConflictKeepLocal  = "keep-local"
+	driveSyncOnConflictKeepRemote = "keep-remote"
+	driveSyncOnConflictKeepBoth   = "keep-both"
+)
+
+// 单侧相对上次同步的变化
+const (
+	driveSyncAdded    = "added"
+	driveSyncModified = "modified"
+	driveSyncDeleted  = "deleted"
+)
+
+// 计划动作
+const (
+	driveSyncActionUnchanged    = "unchanged"
+	driveSyncActionPush         = "push"
+	driveSyncActionPull         = "pull"
+	driveSyncActionDeleteRemote = "delete_remote"
+	driveSyncActionDeleteLocal  = "delete_local"
+	driveSyncActionForget       = "forget"
+	driveSyncActionKeepBoth     = "keep_both"
+	driveSyncActionConflict     = "conflict"
+)
+
+// driveSyncRecord 是 manifest 中一个文件在上次同步完成时的状态
+type driveSyncRecord struct {
+	FileToken    string `json:"file_token"`
+	ModifiedTime string `json:"modified_time,omitempty"` // 远端修改时间；上传后重新列举回填，仍未知时下次同步先比对远端哈希
+	Hash         string `json:"sha256"`
+	Size         int64  `json:"size"`
+	MTime        int64  `json:"mtime"` // 本地 mtime（UnixNano），与 Size 都未变时复用 Hash 免重算
+}
+
+// driveSyncManifest 是 .feishu-sync.json 的内容
+type driveSyncManifest struct {
+	Version     int                        `json:"version"`
+	FolderToken string                     `json:"folder_token"`
+	SyncedAt    time.Time                  `json:"synced_at"`
+	Files       map[string]driveSyncRecord `json:"files"`
+}
+
+// driveSyncLocal 是本地一个 regular file 的当前状态
+type driveSyncLocal struct {
+	Abs   string
+	Hash  string
+	Size  int64
+	MTime int64
+}
+
+// driveSyncOp 是计划中一个路径的同步动作
+type driveSyncOp struct {
+	RelPath      string `json:"rel_path"`
+	Action       string `json:"action"`
+	LocalChange  string `json:"local_change,omitempty"`
+	RemoteChange string `json:"remote_change,omitempty"`
+	ConflictPath string `json:"conflict_path,omitempty"` // keep_both 时本地版本改名后的路径
+	Error        string `json:"error,omitempty"`
+}
+
+var driveSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "本地目录 ↔ 云盘文件夹双向同步（基于 manifest 检测两侧变更与冲突）",
+	Long: `在 --local-dir 与 --folder-token 之间做双向 file-level 同步。
+
+首次同步后会在本地根目录写入 ` + driveSyncManifestName + `，记录每个文件上次同步时的
+file_token、远端修改时间与 SHA-256。之后每次同步按 manifest 判断两侧各自的变化：
+
+  - 本地：新增 / 删除 / 哈希变化（size 与 mtime 都未变时复用 manifest 中的哈希，不重算）
+  - 远端：新增 / 删除 / file_token 或 modified_time 变化
+
+只有一侧变化时直接应用到另一侧（上传、下载或删除）；两侧都变化即为冲突。
+两侧都存在且内容相同的冲突（如首次同步时两边已有同一文件）会自动视为已同步。
+
+冲突策略 --on-conflict:
+  report        只报告，不处理（默认；有冲突时命令返回非零）
+  keep-local    以本地为准（本地已删除则删除远端）
+  keep-remote   以远端为准（远端已删除则删除本地）
+  keep-both     本地版本改名为 <文件名><后缀><扩展名> 后上传，原路径取远端版本；
+                一侧已删除时恢复另一侧版本，不删除任何内容
+
+仅 type=file 参与同步；docx/sheet 等在线文档与空目录不同步。远端覆盖采用「删除 + 重新上传」，
+文件 token 会变化。失败的条目不更新 manifest，修复后重跑即可重试。
+
+必填:
+  --folder-token   云盘根文件夹 token
+  --local-dir      本地根目录（必须在当前工作目录子树内）
+
+可选:
+  --on-conflict      冲突策略（report / keep-local / keep-remote / keep-both）
+  --conflict-suffix  keep-both 时本地版本的文件名后缀（默认 .conflict-local）
+  --dry-run          只输出同步计划，不修改任何文件和 manifest
+  --workers          并发 hash worker 数
+  --output / -o      输出格式（json）
+  --user-access-token  覆盖登录态
+
+权限:
+  - User Access Token 或 Tenant Token
+  - drive:drive（上传/删除）、drive:file:download
+
+示例:
+  feishu-cli drive sync --folder-token fldxxx --local-dir ./shared --dry-run
+  feishu-cli drive sync --folder-token fldxxx --local-dir ./shared
+  feishu-cli drive sync --folder-token fldxxx --local-dir ./shared --on-conflict keep-both
+  feishu-cli drive sync --folder-token fldxxx --local-dir ./shared --on-conflict keep-remote -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		folderToken, _ := cmd.Flags().GetString("folder-token")
+		localDir, _ := cmd.Flags().GetString("local-dir")
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		suffix, _ := cmd.Flags().GetString("conflict-suffix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		output, _ := cmd.Flags().GetString("output")
+		workers, _ := cmd.Flags().GetInt("workers")
+		if workers < 1 {
+			workers = 1
+		}
+
+		if folderToken == "" {
+			return fmt.Errorf("--folder-token 必填")
+		}
+		if localDir == "" {
+			return fmt.Errorf("--local-dir 必填")
+		}
+		switch onConflict {
+		case driveSyncOnConflictReport, driveSyncOnConflictKeepLocal, driveSyncOnConflictKeepRemote, driveSyncOnConflictKeepBoth:
+		default:
+			return fmt.Errorf("--on-conflict 只能是 report / keep-local / keep-remote / keep-both")
+		}
+		if onConflict == driveSyncOnConflictKeepBoth && (suffix == "" || strings.ContainsAny(suffix, `/\`)) {
+			return fmt.Errorf("--conflict-suffix 不能为空且不能包含路径分隔符")
+		}
+
+		safeRoot, _, err := resolveSafeLocalDir(localDir)
+		if err != nil {
+			return err
+		}
+		manifestPath := filepath.Join(safeRoot, driveSyncManifestName)
+		manifest, err := loadDriveSyncManifest(manifestPath, folderToken)
+		if err != nil {
+			return err
+		}
+
+		userToken := resolveOptionalUserTokenWithFallback(cmd)
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "扫描本地: %s\n", safeRoot)
+		locals, err := scanDriveSyncLocal(safeRoot, manifest, workers)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "列举云盘文件夹: %s\n", folderToken)
+		entries, err := client.ListFolderRecursive(folderToken, userToken)
+		if err != nil {
+			return err
+		}
+		remotes := make(map[string]client.DriveRemoteEntry, len(entries))
+		folderCache := map[string]string{"": folderToken}
+		for rel, e := range entries {
+			switch {
+			case e.Type == "folder":
+				folderCache[rel] = e.FileToken
+			case e.Type == "file" && rel != driveSyncManifestName:
+				remotes[rel] = e
+			}
+		}
+
+		// 修改时间未知的记录（上次上传后未能回填）先比对远端内容：与记录的哈希一致才回填，
+		// 否则保持未知，按远端已修改处理，避免漏掉原地编辑
+		if pending := driveSyncPendingMTime(manifest, remotes); len(pending) > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "比对 %d 个修改时间未知文件的远端内容\n", len(pending))
+			remoteTokens := make(map[string]string, len(pending))
+			for _, rel := range pending {
+				remoteTokens[rel] = remotes[rel].FileToken
+			}
+			remoteHashes, hashErr := concurrentHashRemote(pending, remoteTokens, userToken, workers)
+			if hashErr != nil {
+				return hashErr
+			}
+			verified := map[string]client.DriveRemoteEntry{}
+			for rel, h := range remoteHashes {
+				if h == manifest.Files[rel].Hash {
+					verified[rel] = remotes[rel]
+				}
+			}
+			backfillDriveSyncModifiedTime(manifest, verified)
+		}
+
+		ops := planDriveSync(manifest, locals, remotes)
+
+		// 两侧都存在的冲突先比对远端内容，相同则视为已同步
+		var candidates []string
+		for _, op := range ops {
+			if op.Action == driveSyncActionConflict && op.LocalChange != driveSyncDeleted && op.RemoteChange != driveSyncDeleted {
+				candidates = append(candidates, op.RelPath)
+			}
+		}
+		if len(candidates) > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "比对 %d 个冲突文件的远端内容\n", len(candidates))
+			remoteTokens := make(map[string]string, len(candidates))
+			for _, rel := range candidates {
+				remoteTokens[rel] = remotes[rel].FileToken
+			}
+			remoteHashes, hashErr := concurrentHashRemote(candidates, remoteTokens, userToken, workers)
+			if hashErr != nil {
+				return hashErr
+			}
+			for i := range ops {
+				if h, ok := remoteHashes[ops[i].RelPath]; ok && h == locals[ops[i].RelPath].Hash {
+					ops[i].Action = driveSyncActionUnchanged
+				}
+			}
+		}
+
+		for i := range ops {
+			resolveDriveSyncConflict(&ops[i], onConflict)
+			if ops[i].Action == driveSyncActionKeepBoth {
+				ops[i].ConflictPath = driveSyncConflictPath(ops[i].RelPath, suffix, locals, remotes)
+				// 占位，避免同一次运行中多个冲突分配到同名文件
+				locals[ops[i].ConflictPath] = driveSyncLocal{}
+			}
+		}
+
+		if !dryRun {
+			s := &driveSyncSession{
+				root:        safeRoot,
+				folderToken: folderToken,
+				userToken:   userToken,
+				manifest:    manifest,
+				locals:      locals,
+				remotes:     remotes,
+				folderCache: folderCache,
+			}
+			for i := range ops {
+				if err := s.apply(&ops[i]); err != nil {
+					ops[i].Error = err.Error()
+				}
+			}
+			// 上传接口不返回修改时间，重新列举一次回填；失败时留待下次同步比对哈希
+			if len(driveSyncPendingMTime(manifest, remotes)) > 0 {
+				if after, listErr := client.ListFolderRecursive(folderToken, userToken); listErr != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "回填远端修改时间失败（下次同步时比对内容）: %v\n", listErr)
+				} else {
+					backfillDriveSyncModifiedTime(manifest, after)
+				}
+			}
+			manifest.SyncedAt = time.Now()
+			if err := saveDriveSyncManifest(manifestPath, manifest); err != nil {
+				return err
+			}
+		}
+
+		summary := summarizeDriveSync(ops)
+		if output == "json" {
+			if err := printJSON(map[string]any{
+				"dry_run": dryRun,
+				"summary": summary,
+				"items":   driveSyncVisibleOps(ops),
+			}); err != nil {
+				return err
+			}
+		} else {
+			prefix := ""
+			if dryRun {
+				prefix = "同步计划 (未执行) "
+			}
+			fmt.Printf("%s上传: %d  下载: %d  删除远端: %d  删除本地: %d  保留双方: %d  冲突: %d  失败: %d  未变: %d\n",
+				prefix, summary["push"], summary["pull"], summary["delete_remote"], summary["delete_local"],
+				summary["keep_both"], summary["conflict"], summary["failed"], summary["unchanged"])
+			for _, op := range driveSyncVisibleOps(ops) {
+				switch {
+				case op.Error != "":
+					fmt.Printf("  ⚠ %-13s %s -- %s\n", op.Action, op.RelPath, op.Error)
+				case op.Action == driveSyncActionConflict:
+					fmt.Printf("  ✖ %-13s %s (本地 %s, 远端 %s)\n", op.Action, op.RelPath, op.LocalChange, op.RemoteChange)
+				case op.Action == driveSyncActionKeepBoth:
+					fmt.Printf("    %-13s %s (本地版本 → %s)\n", op.Action, op.RelPath, op.ConflictPath)
+				default:
+					fmt.Printf("    %-13s %s\n", op.Action, op.RelPath)
+				}
+			}
+		}
+
+		if summary["failed"] > 0 {
+			return fmt.Errorf("有 %d 项失败，未更新其 manifest 记录；修复后重跑", summary["failed"])
+		}
+		if summary["conflict"] > 0 {
+			return fmt.Errorf("有 %d 个冲突未处理；检查后用 --on-conflict keep-local / keep-remote / keep-both 重跑", summary["conflict"])
+		}
+		return nil
+	},
+}
+
+// loadDriveSyncManifest 读取 manifest；不存在时返回空 manifest（首次同步）。
+// manifest 绑定的 folder_token 与本次不同时拒绝执行，避免把两个云盘文件夹的状态混在一起。
+func loadDriveSyncManifest(manifestPath, folderToken string) (*driveSyncManifest, error) {
+	m := &driveSyncManifest{Version: 1, FolderToken: folderToken, Files: map[string]driveSyncRecord{}}
+	data, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", driveSyncManifestName, err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", driveSyncManifestName, err)
+	}
+	if m.FolderToken != folderToken {
+		return nil, fmt.Errorf("%s 记录的是文件夹 %s 的同步状态，与 --folder-token %s 不一致；如需改绑请先删除该文件",
+			driveSyncManifestName, m.FolderToken, folderToken)
+	}
+	if m.Files == nil {
+		m.Files = map[string]driveSyncRecord{}
+	}
+	return m, nil
+}
+
+// saveDriveSyncManifest 原子写 manifest（tmp + os.Rename）
+func saveDriveSyncManifest(manifestPath string, m *driveSyncManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化 %s 失败: %w", driveSyncManifestName, err)
+	}
+	tmp := manifestPath + ".tmp." + strconv.Itoa(os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入 %s 临时文件失败: %w", driveSyncManifestName, err)
+	}
+	if err := os.Rename(tmp, manifestPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("替换 %s 失败: %w", driveSyncManifestName, err)
+	}
+	return nil
+}
+
+// scanDriveSyncLocal 遍历本地文件并取得哈希；size 与 mtime 与 manifest 一致的文件复用记录中的哈希
+func scanDriveSyncLocal(root string, manifest *driveSyncManifest, workers int) (map[string]driveSyncLocal, error) {
+	files, err := walkLocalRegularFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	locals := make(map[string]driveSyncLocal, len(files))
+	toHash := map[string]string{}
+	for rel, abs := range files {
+		if rel == driveSyncManifestName || strings.HasPrefix(rel, driveSyncManifestName+".tmp.") {
+			continue
+		}
+		info, statErr := os.Stat(abs)
+		if statErr != nil {
+			return nil, fmt.Errorf("读取本地文件信息失败 (%s): %w", rel, statErr)
+		}
+		l := driveSyncLocal{Abs: abs, Size: info.Size(), MTime: info.ModTime().UnixNano()}
+		if rec, ok := manifest.Files[rel]; ok && rec.Hash != "" && rec.Size == l.Size && rec.MTime == l.MTime {
+			l.Hash = rec.Hash
+		} else {
+			toHash[rel] = abs
+		}
+		locals[rel] = l
+	}
+	hashes, err := concurrentHashLocal(toHash, workers)
+	if err != nil {
+		return nil, err
+	}
+	for rel, h := range hashes {
+		l := locals[rel]
+		l.Hash = h
+		locals[rel] = l
+	}
+	return locals, nil
+}
+
+// planDriveSync 对 manifest、本地与远端三方做比对，为每个路径生成一个动作（按路径排序）。
+// 只有一侧变化时向另一侧传播；两侧都变化时标记为 conflict，由 resolveDriveSyncConflict 按策略处理。
+func planDriveSync(manifest *driveSyncManifest, locals map[string]driveSyncLocal, remotes map[string]client.DriveRemoteEntry) []driveSyncOp {
+	paths := map[string]struct{}{}
+	for rel := range manifest.Files {
+		paths[rel] = struct{}{}
+	}
+	for rel := range locals {
+		paths[rel] = struct{}{}
+	}
+	for rel := range remotes {
+		paths[rel] = struct{}{}
+	}
+	sorted := make([]string, 0, len(paths))
+	for rel := range paths {
+		sorted = append(sorted, rel)
+	}
+	sort.Strings(sorted)
+
+	ops := make([]driveSyncOp, 0, len(sorted))
+	for _, rel := range sorted {
+		rec, synced := manifest.Files[rel]
+		local, hasLocal := locals[rel]
+		remote, hasRemote := remotes[rel]
+
+		op := driveSyncOp{
+			RelPath:      rel,
+			LocalChange:  driveSyncSideChange(synced, hasLocal, synced && hasLocal && local.Hash != rec.Hash),
+			RemoteChange: driveSyncSideChange(synced, hasRemote, synced && hasRemote && driveSyncRemoteChanged(rec, remote)),
+		}
+		switch {
+		case op.LocalChange == "" && op.RemoteChange == "":
+			op.Action = driveSyncActionUnchanged
+		case op.RemoteChange == "":
+			op.Action = driveSyncActionPush
+			if op.LocalChange == driveSyncDeleted {
+				op.Action = driveSyncActionDeleteRemote
+			}
+		case op.LocalChange == "":
+			op.Action = driveSyncActionPull
+			if op.RemoteChange == driveSyncDeleted {
+				op.Action = driveSyncActionDeleteLocal
+			}
+		case op.LocalChange == driveSyncDeleted && op.RemoteChange == driveSyncDeleted:
+			op.Action = driveSyncActionForget
+		default:
+			op.Action = driveSyncActionConflict
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// driveSyncSideChange 根据是否有上次同步记录、当前是否存在、内容是否变化，得出单侧的变化类型
+func driveSyncSideChange(synced, exists, changed bool) string {
+	switch {
+	case !synced && exists:
+		return driveSyncAdded
+	case synced && !exists:
+		return driveSyncDeleted
+	case changed:
+		return driveSyncModified
+	}
+	return ""
+}
+
+// driveSyncRemoteChanged 判断远端文件相对上次同步是否变化：token 变化（被删除重传）或修改时间变化（新版本）。
+// manifest 中修改时间未知时视为已变化；RunE 在规划前已对内容未变的记录回填修改时间。
+func driveSyncRemoteChanged(rec driveSyncRecord, remote client.DriveRemoteEntry) bool {
+	return rec.FileToken != remote.FileToken || rec.ModifiedTime != remote.ModifiedTime
+}
+
+// driveSyncPendingMTime 返回 manifest 中修改时间未知、且远端仍是同一 token 的路径（按路径排序）
+func driveSyncPendingMTime(manifest *driveSyncManifest, remotes map[string]client.DriveRemoteEntry) []string {
+	var pending []string
+	for rel, rec := range manifest.Files {
+		if remote, ok := remotes[rel]; ok && rec.ModifiedTime == "" && remote.FileToken == rec.FileToken {
+			pending = append(pending, rel)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// backfillDriveSyncModifiedTime 用远端条目回填 manifest 中修改时间未知的记录；token 不一致的记录不处理
+func backfillDriveSyncModifiedTime(manifest *driveSyncManifest, remotes map[string]client.DriveRemoteEntry) {
+	for rel, rec := range manifest.Files {
+		remote, ok := remotes[rel]
+		if !ok || rec.ModifiedTime != "" || remote.Type != "file" || remote.FileToken != rec.FileToken {
+			continue
+		}
+		rec.ModifiedTime = remote.ModifiedTime
+		manifest.Files[rel] = rec
+	}
+}
+
+// resolveDriveSyncConflict 按 --on-conflict 策略把 conflict 改写为具体动作；report 保持 conflict
+func resolveDriveSyncConflict(op *driveSyncOp, policy string) {
+	if op.Action != driveSyncActionConflict {
+		return
+	}
+	localGone := op.LocalChange == driveSyncDeleted
+	remoteGone := op.RemoteChange == driveSyncDeleted
+	switch policy {
+	case driveSyncOnConflictKeepLocal:
+		op.Action = driveSyncActionPush
+		if localGone {
+			op.Action = driveSyncActionDeleteRemote
+		}
+	case driveSyncOnConflictKeepRemote:
+		op.Action = driveSyncActionPull
+		if remoteGone {
+			op.Action = driveSyncActionDeleteLocal
+		}
+	case driveSyncOnConflictKeepBoth:
+		switch {
+		case localGone:
+			op.Action = driveSyncActionPull
+		case remoteGone:
+			op.Action = driveSyncActionPush
+		default:
+			op.Action = driveSyncActionKeepBoth
+		}
+	}
+}
+
+// driveSyncConflictPath 为 keep-both 的本地版本生成两侧都未占用的新路径：
+// a/report.pdf + ".conflict-local" → a/report.conflict-local.pdf，重名时追加 -2、-3…
+func driveSyncConflictPath(rel, suffix string, locals map[string]driveSyncLocal, remotes map[string]client.DriveRemoteEntry) string {
+	ext := path.Ext(rel)
+	base := strings.TrimSuffix(rel, ext)
+	for n := 1; ; n++ {
+		candidate := base + suffix + ext
+		if n > 1 {
+			candidate = base + suffix + "-" + strconv.Itoa(n) + ext
+		}
+		_, localTaken := locals[candidate]
+		_, remoteTaken := remotes[candidate]
+		if !localTaken && !remoteTaken {
+			return candidate
+		}
+	}
+}
+
+// driveSyncSession 持有执行同步计划所需的上下文
+type driveSyncSession struct {
+	root        string
+	folderToken string
+	userToken   string
+	manifest    *driveSyncManifest
+	locals      map[string]driveSyncLocal
+	remotes     map[string]client.DriveRemoteEntry
+	folderCache map[string]string
+}
+
+// apply 执行单个动作，成功后更新 manifest 中对应记录；失败时记录保持不变
+func (s *driveSyncSession) apply(op *driveSyncOp) error {
+	rel := op.RelPath
+	switch op.Action {
+	case driveSyncActionUnchanged:
+		// 刷新远端修改时间，以及本地 size/mtime（内容未变但 touch 过）
+		local, remote := s.locals[rel], s.remotes[rel]
+		s.manifest.Files[rel] = driveSyncRecord{
+			FileToken:    remote.FileToken,
+			ModifiedTime: remote.ModifiedTime,
+			Hash:         local.Hash,
+			Size:         local.Size,
+			MTime:        local.MTime,
+		}
+	case driveSyncActionPush:
+		return s.push(rel, rel)
+	case driveSyncActionPull:
+		return s.pull(rel)
+	case driveSyncActionDeleteRemote:
+		if err := client.DeleteDriveFileByToken(s.remotes[rel].FileToken, s.userToken); err != nil {
+			return err
+		}
+		delete(s.manifest.Files, rel)
+	case driveSyncActionDeleteLocal:
+		if err := os.Remove(s.locals[rel].Abs); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		delete(s.manifest.Files, rel)
+	case driveSyncActionForget:
+		delete(s.manifest.Files, rel)
+	case driveSyncActionKeepBoth:
+		target := filepath.Join(s.root, filepath.FromSlash(op.ConflictPath))
+		if err := os.Rename(s.locals[rel].Abs, target); err != nil {
+			return fmt.Errorf("重命名本地冲突版本失败: %w", err)
+		}
+		local := s.locals[rel]
+		local.Abs = target
+		s.locals[op.ConflictPath] = local
+		delete(s.locals, rel)
+		if err := s.push(op.ConflictPath, op.ConflictPath); err != nil {
+			return err
+		}
+		return s.pull(rel)
+	}
+	return nil
+}
+
+// push 把本地 localRel 上传到远端 remoteRel；远端已有同路径文件时先删除再上传（upload_all 没有 update 语义）
+func (s *driveSyncSession) push(localRel, remoteRel string) error {
+	local := s.locals[localRel]
+	parentToken, err := ensureRemoteFolder(s.folderToken, pushParentRel(remoteRel), s.folderCache, s.userToken)
+	if err != nil {
+		return err
+	}
+	if existing, ok := s.remotes[remoteRel]; ok {
+		if err := client.DeleteDriveFileByToken(existing.FileToken, s.userToken); err != nil {
+			return err
+		}
+		delete(s.remotes, remoteRel)
+		delete(s.manifest.Files, remoteRel)
+	}
+	token, err := client.UploadFileWithToken(local.Abs, parentToken, path.Base(remoteRel), s.userToken)
+	if err != nil {
+		return err
+	}
+	s.remotes[remoteRel] = client.DriveRemoteEntry{FileToken: token, Type: "file", RelPath: remoteRel}
+	s.manifest.Files[remoteRel] = driveSyncRecord{
+		FileToken: token,
+		Hash:      local.Hash,
+		Size:      local.Size,
+		MTime:     local.MTime,
+	}
+	return nil
+}
+
+// pull 下载远端 rel 到本地同路径，并以落盘后的 size/mtime/哈希更新 manifest
+func (s *driveSyncSession) pull(rel string) error {
+	remote := s.remotes[rel]
+	target := filepath.Join(s.root, filepath.FromSlash(rel))
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return fmt.Errorf("本地同路径是目录，远端是文件")
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := client.DownloadFileWithToken(remote.FileToken, target, s.userToken); err != nil {
+		return err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	hash, err := client.HashLocalFile(target)
+	if err != nil {
+		return err
+	}
+	s.locals[rel] = driveSyncLocal{Abs: target, Hash: hash, Size: info.Size(), MTime: info.ModTime().UnixNano()}
+	s.manifest.Files[rel] = driveSyncRecord{
+		FileToken:    remote.FileToken,
+		ModifiedTime: remote.ModifiedTime,
+		Hash:         hash,
+		Size:         info.Size(),
+		MTime:        info.ModTime().UnixNano(),
+	}
+	return nil
+}
+
+// summarizeDriveSync 按动作计数；失败项单独计入 failed
+func summarizeDriveSync(ops []driveSyncOp) map[string]int {
+	summary := map[string]int{
+		driveSyncActionUnchanged:    0,
+		driveSyncActionPush:         0,
+		driveSyncActionPull:         0,
+		driveSyncActionDeleteRemote: 0,
+		driveSyncActionDeleteLocal:  0,
+		driveSyncActionKeepBoth:     0,
+		driveSyncActionConflict:     0,
+		"failed":                    0,
+	}
+	for _, op := range ops {
+		if op.Error != "" {
+			summary["failed"]++
+			continue
+		}
+		if op.Action != driveSyncActionForget {
+			summary[op.Action]++
+		}
+	}
+	return summary
+}
+
+// driveSyncVisibleOps 过滤掉无需展示的 unchanged / forget 条目
+func driveSyncVisibleOps(ops []driveSyncOp) []driveSyncOp {
+	visible := []driveSyncOp{}
+	for _, op := range ops {
+		if op.Error == "" && (op.Action == driveSyncActionUnchanged || op.Action == driveSyncActionForget) {
+			continue
+		}
+		visible = append(visible, op)
+	}
+	return visible
+}
+
+func init() {
+	driveCmd.AddCommand(driveSyncCmd)
+	driveSyncCmd.Flags().String("folder-token", "", "云盘根文件夹 token（必填）")
+	driveSyncCmd.Flags().String("local-dir", "", "本地根目录（必填，必须在 cwd 子树内）")
+	driveSyncCmd.Flags().String("on-conflict", driveSyncOnConflictReport, "冲突策略: report / keep-local / keep-remote / keep-both")
+	driveSyncCmd.Flags().String("conflict-suffix", ".conflict-local", "keep-both 时本地版本的文件名后缀（加在扩展名之前）")
+	driveSyncCmd.Flags().Bool("dry-run", false, "只输出同步计划，不执行")
+	driveSyncCmd.Flags().Int("workers", 4, "并发 hash worker 数（本地+远端）")
+	driveSyncCmd.Flags().StringP("output", "o", "", "输出格式（json）")
+	driveSyncCmd.Flags().String("user-access-token", "", "User Access Token（覆盖登录态）")
+	mustMarkFlagRequired(driveSyncCmd, "folder-token")
+	mustMarkFlagRequired(driveSyncCmd, "local-dir")
+}
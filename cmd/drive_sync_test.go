@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+)
+
+func TestPlanDriveSync(t *testing.T) {
+	manifest := &driveSyncManifest{Files: map[string]driveSyncRecord{
+		"same.txt":         {FileToken: "t1", ModifiedTime: "100", Hash: "h1"},
+		"local-edit.txt":   {FileToken: "t2", ModifiedTime: "100", Hash: "h2"},
+		"remote-edit.txt":  {FileToken: "t3", ModifiedTime: "100", Hash: "h3"},
+		"both-edit.txt":    {FileToken: "t4", ModifiedTime: "100", Hash: "h4"},
+		"local-gone.txt":   {FileToken: "t5", ModifiedTime: "100", Hash: "h5"},
+		"remote-gone.txt":  {FileToken: "t6", ModifiedTime: "100", Hash: "h6"},
+		"both-gone.txt":    {FileToken: "t7", ModifiedTime: "100", Hash: "h7"},
+		"reuploaded.txt":   {FileToken: "t8", ModifiedTime: "100", Hash: "h8"},
+		"pushed.txt":       {FileToken: "t9", Hash: "h9"}, // 上传后修改时间未能回填
+		"edit-vs-gone.txt": {FileToken: "t10", ModifiedTime: "100", Hash: "h10"},
+	}}
+	locals := map[string]driveSyncLocal{
+		"same.txt":         {Hash: "h1"},
+		"local-edit.txt":   {Hash: "h2-new"},
+		"remote-edit.txt":  {Hash: "h3"},
+		"both-edit.txt":    {Hash: "h4-new"},
+		"remote-gone.txt":  {Hash: "h6"},
+		"reuploaded.txt":   {Hash: "h8"},
+		"pushed.txt":       {Hash: "h9"},
+		"edit-vs-gone.txt": {Hash: "h10-new"},
+		"new-local.txt":    {Hash: "n1"},
+		"new-both.txt":     {Hash: "n2"},
+	}
+	remote := func(token, mtime string) client.DriveRemoteEntry {
+		return client.DriveRemoteEntry{FileToken: token, Type: "file", ModifiedTime: mtime}
+	}
+	remotes := map[string]client.DriveRemoteEntry{
+		"same.txt":        remote("t1", "100"),
+		"local-edit.txt":  remote("t2", "100"),
+		"remote-edit.txt": remote("t3", "200"),
+		"both-edit.txt":   remote("t4", "200"),
+		"local-gone.txt":  remote("t5", "100"),
+		"reuploaded.txt":  remote("t8-new", "100"),
+		"pushed.txt":      remote("t9", "300"),
+		"new-remote.txt":  remote("n3", "100"),
+		"new-both.txt":    remote("n4", "100"),
+	}
+
+	want := map[string]string{
+		"same.txt":         driveSyncActionUnchanged,
+		"local-edit.txt":   driveSyncActionPush,
+		"remote-edit.txt":  driveSyncActionPull,
+		"both-edit.txt":    driveSyncActionConflict,
+		"local-gone.txt":   driveSyncActionDeleteRemote,
+		"remote-gone.txt":  driveSyncActionDeleteLocal,
+		"both-gone.txt":    driveSyncActionForget,
+		"reuploaded.txt":   driveSyncActionPull,
+		"pushed.txt":       driveSyncActionPull, // 修改时间未知且未经哈希确认，按远端已修改处理
+		"edit-vs-gone.txt": driveSyncActionConflict,
+		"new-local.txt":    driveSyncActionPush,
+		"new-remote.txt":   driveSyncActionPull,
+		"new-both.txt":     driveSyncActionConflict,
+	}
+
+	ops := planDriveSync(manifest, locals, remotes)
+	if len(ops) != len(want) {
+		t.Fatalf("planDriveSync 返回 %d 项，期望 %d 项: %+v", len(ops), len(want), ops)
+	}
+	for i, op := range ops {
+		if i > 0 && ops[i-1].RelPath >= op.RelPath {
+			t.Fatalf("结果未按路径排序: %q 在 %q 之后", op.RelPath, ops[i-1].RelPath)
+		}
+		if op.Action != want[op.RelPath] {
+			t.Errorf("%s: action = %s (local=%q remote=%q), want %s",
+				op.RelPath, op.Action, op.LocalChange, op.RemoteChange, want[op.RelPath])
+		}
+	}
+}
+
+func TestDriveSyncPushThenRemoteEdit(t *testing.T) {
+	// 第一次同步上传 a.txt：上传接口不返回修改时间，记录里为空
+	manifest := &driveSyncManifest{Files: map[string]driveSyncRecord{
+		"a.txt": {FileToken: "t1", Hash: "h1"},
+	}}
+	remote := func(mtime string) map[string]client.DriveRemoteEntry {
+		return map[string]client.DriveRemoteEntry{"a.txt": {FileToken: "t1", Type: "file", ModifiedTime: mtime}}
+	}
+	if got := driveSyncPendingMTime(manifest, remote("100")); len(got) != 1 || got[0] != "a.txt" {
+		t.Fatalf("pending = %v", got)
+	}
+
+	// 上传后重新列举回填修改时间
+	backfillDriveSyncModifiedTime(manifest, remote("100"))
+	if manifest.Files["a.txt"].ModifiedTime != "100" {
+		t.Fatalf("未回填修改时间: %+v", manifest.Files["a.txt"])
+	}
+	if got := driveSyncPendingMTime(manifest, remote("100")); len(got) != 0 {
+		t.Fatalf("回填后不应再有待确认记录: %v", got)
+	}
+
+	// 远端原地编辑（token 不变、修改时间变化）后再次同步应下载
+	locals := map[string]driveSyncLocal{"a.txt": {Hash: "h1"}}
+	ops := planDriveSync(manifest, locals, remote("200"))
+	if len(ops) != 1 || ops[0].Action != driveSyncActionPull {
+		t.Fatalf("远端原地编辑应 pull: %+v", ops)
+	}
+
+	// token 已变化的条目不回填，交给 planDriveSync 按重新上传处理
+	manifest.Files["a.txt"] = driveSyncRecord{FileToken: "t1", Hash: "h1"}
+	backfillDriveSyncModifiedTime(manifest, map[string]client.DriveRemoteEntry{"a.txt": {FileToken: "t2", Type: "file", ModifiedTime: "300"}})
+	if manifest.Files["a.txt"].ModifiedTime != "" {
+		t.Fatalf("token 不一致时不应回填: %+v", manifest.Files["a.txt"])
+	}
+}
+
+func TestResolveDriveSyncConflict(t *testing.T) {
+	cases := []struct {
+		local, remote string
+		policy        string
+		want          string
+	}{
+		{driveSyncModified, driveSyncModified, driveSyncOnConflictReport, driveSyncActionConflict},
+		{driveSyncModified, driveSyncModified, driveSyncOnConflictKeepLocal, driveSyncActionPush},
+		{driveSyncModified, driveSyncModified, driveSyncOnConflictKeepRemote, driveSyncActionPull},
+		{driveSyncModified, driveSyncModified, driveSyncOnConflictKeepBoth, driveSyncActionKeepBoth},
+		{driveSyncDeleted, driveSyncModified, driveSyncOnConflictKeepLocal, driveSyncActionDeleteRemote},
+		{driveSyncDeleted, driveSyncModified, driveSyncOnConflictKeepBoth, driveSyncActionPull},
+		{driveSyncModified, driveSyncDeleted, driveSyncOnConflictKeepRemote, driveSyncActionDeleteLocal},
+		{driveSyncModified, driveSyncDeleted, driveSyncOnConflictKeepBoth, driveSyncActionPush},
+		{driveSyncAdded, driveSyncAdded, driveSyncOnConflictKeepBoth, driveSyncActionKeepBoth},
+	}
+	for _, c := range cases {
+		op := driveSyncOp{RelPath: "a.txt", Action: driveSyncActionConflict, LocalChange: c.local, RemoteChange: c.remote}
+		resolveDriveSyncConflict(&op, c.policy)
+		if op.Action != c.want {
+			t.Errorf("local=%s remote=%s policy=%s: got %s, want %s", c.local, c.remote, c.policy, op.Action, c.want)
+		}
+	}
+
+	// 非冲突动作不受策略影响
+	op := driveSyncOp{Action: driveSyncActionPull}
+	resolveDriveSyncConflict(&op, driveSyncOnConflictKeepLocal)
+	if op.Action != driveSyncActionPull {
+		t.Fatalf("非冲突动作被改写为 %s", op.Action)
+	}
+}
+
+func TestDriveSyncConflictPath(t *testing.T) {
+	locals := map[string]driveSyncLocal{"docs/a.conflict-local.pdf": {}}
+	remotes := map[string]client.DriveRemoteEntry{"docs/a.conflict-local-2.pdf": {}}
+	if got := driveSyncConflictPath("docs/a.pdf", ".conflict-local", locals, remotes); got != "docs/a.conflict-local-3.pdf" {
+		t.Fatalf("got %q", got)
+	}
+	if got := driveSyncConflictPath("Makefile", "-mine", nil, nil); got != "Makefile-mine" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestDriveSyncManifestRoundTrip(t *testing.T) {
+	p := filepath.Join(t.TempDir(), driveSyncManifestName)
+
+	m, err := loadDriveSyncManifest(p, "fld1")
+	if err != nil {
+		t.Fatalf("首次加载不应报错: %v", err)
+	}
+	if len(m.Files) != 0 || m.FolderToken != "fld1" {
+		t.Fatalf("首次加载应返回空 manifest: %+v", m)
+	}
+	m.Files["a.txt"] = driveSyncRecord{FileToken: "t1", Hash: "h1", Size: 3, MTime: 42}
+	if err := saveDriveSyncManifest(p, m); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadDriveSyncManifest(p, "fld1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Files["a.txt"] != m.Files["a.txt"] {
+		t.Fatalf("round trip 不一致: %+v", got.Files["a.txt"])
+	}
+	if _, err := loadDriveSyncManifest(p, "fld2"); err == nil {
+		t.Fatal("folder_token 不一致时应报错")
+	}
+}
+
+func TestScanDriveSyncLocalReusesHash(t *testing.T) {
+	root := t.TempDir()
+	abs := filepath.Join(root, "a.txt")
+	if err := os.WriteFile(abs, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, driveSyncManifestName), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(abs)
+
+	// size/mtime 一致时沿用 manifest 中的哈希（故意写一个假值以验证未重算）
+	m := &driveSyncManifest{Files: map[string]driveSyncRecord{
+		"a.txt": {Hash: "cached", Size: info.Size(), MTime: info.ModTime().UnixNano()},
+	}}
+	locals, err := scanDriveSyncLocal(root, m, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := locals[driveSyncManifestName]; ok {
+		t.Fatal("manifest 文件不应参与同步")
+	}
+	if locals["a.txt"].Hash != "cached" {
+		t.Fatalf("应复用缓存哈希，got %q", locals["a.txt"].Hash)
+	}
+
+	m.Files["a.txt"] = driveSyncRecord{Hash: "cached", Size: info.Size() + 1}
+	locals, err = scanDriveSyncLocal(root, m, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locals["a.txt"].Hash == "cached" {
+		t.Fatal("size 变化后应重新计算哈希")
+	}
+}
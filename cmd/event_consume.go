@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,10 +16,17 @@ import (
 )
 
 var eventConsumeCmd = &cobra.Command{
-	Use:   "consume <event_key>",
+	Use:   "consume <event_key>...",
 	Short: "订阅 EventKey 并把事件流写到 stdout",
 	Long: `通过飞书 WebSocket 长连接订阅指定 EventKey，每条事件作为一行 JSON（NDJSON）写到 stdout。
 
+多 EventKey:
+  可一次传入多个 key（空格或逗号分隔），也可用通配：im.*（im Domain 全部 key）、
+  im.message.*（im.message. 前缀的 key）。所有 key 共用一条 WebSocket 连接，
+  在 bus.json 中登记为一个 consumer。
+  多 key 时每条事件 JSON 头部插入 "event_key" 字段标明来源（--filter / --jq 可直接用 .event_key）；
+  单 key 时保持原始 payload 不变。
+
 启动协议:
   本命令启动后会先在 stderr 输出一行 [event] ready event_key=<key>。
   AI Agent / subprocess 父进程应阻塞等待该 ready marker，再开始读 stdout。
@@ -29,14 +37,31 @@ var eventConsumeCmd = &cobra.Command{
   - Ctrl-C / SIGTERM
   - stdin EOF（非 TTY 模式）—— 适配子进程场景：父进程关闭 stdin 即触发优雅退出
 
-简单 jq 支持:
-  --jq 仅支持 . 点路径访问，例如 .event.message 提取消息子树。
-  完整 jq 语法请通过 pipe 外部 jq 处理：feishu-cli event consume <key> | jq '.event'
+过滤与变换（内置 gojq，完整 jq 语法，无需外部 jq）:
+  --filter 谓词表达式，结果为 null/false/无输出的事件直接丢弃，不计入 --max-events。
+  --jq     变换表达式，每个非 null 输出写一行 NDJSON（支持 select / map / 对象构造 / 多输出）；
+           没有任何非 null 输出的事件同样丢弃、不计数。
+  两者同时指定时先 --filter 再 --jq。单条事件求值出错只在 stderr 记录并跳过，不中断订阅。
 
 文件输出:
   --output-dir 非空时，每条事件额外 dump 为 <event_id>.json 落盘。
   路径必须是安全相对路径；不做 ~ 展开，不接受绝对路径或 ..。
 
+去重:
+  --dedup 按 event_id 持久化去重（~/.feishu-cli/events/<app_id>/dedup/<event_key>.ndjson），
+  WebSocket 重连、飞书重推、consume 重启后同一事件只输出 / 处理一次。重复事件先于 --filter 丢弃，
+  不计入 --max-events。记录保留 --dedup-ttl（默认 24h），最多 --dedup-max 条（默认 10000，超出淘汰最旧）。
+
+处理器模式（通过 --filter 的每条事件额外交给处理器，stdout NDJSON 照常输出）:
+  --exec '<cmd>'      经 sh -c（Windows 为 cmd /C）执行，事件原始 JSON 写到 stdin；
+                      环境变量 FEISHU_EVENT_KEY / FEISHU_EVENT_TYPE / FEISHU_EVENT_ID / FEISHU_EVENT_CHAT_ID。
+                      非 0 退出码视为失败；子进程 stdout 丢弃，stderr 附在失败日志里。
+  --forward-url <url> 把事件原始 JSON POST 到该地址（Header 带 X-Feishu-Event-Type / -Id / -Key），非 2xx 视为失败。
+  --handler-concurrency 并发上限，满载时阻塞接收形成背压；--handler-timeout 单次执行超时；
+  --handler-retries 失败重试次数（间隔从 --handler-backoff 起指数翻倍，上限 30s）；
+  --dead-letter-dir 重试耗尽的事件写为 <event_id>.<exec|forward>.json（含错误信息与原始事件）。
+  退出时不再重试，但会等待在途处理器执行完毕。
+
 重连:
   oapi-sdk-go ws.Client 默认 WithAutoReconnect(true)，断线后无限重试（间隔 2 分钟 + 首次抖动）。
   长时间断线建议结合 --timeout 主动退出，由父进程拉起。
@@ -56,28 +81,75 @@ var eventConsumeCmd = &cobra.Command{
   # 静默模式 + 落盘
   feishu-cli event consume im.message.receive_v1 --output-dir ./events --quiet
 
-  # 配合 jq 实时过滤群消息
-  feishu-cli event consume im.message.receive_v1 | jq 'select(.event.message.chat_type=="group")'`,
-	Args: cobra.ExactArgs(1),
+  # 只处理指定群里的消息，收满 10 条即退出
+  feishu-cli event consume im.message.receive_v1 --max-events 10 \
+    --filter '.event.message.chat_id == "oc_xxx"'
+
+  # 一个连接同时收消息、表情回复和卡片回调
+  feishu-cli event consume im.message.receive_v1 im.message.reaction.* card.action.trigger
+
+  # 处理器模式：每条群消息交给本地脚本，失败重试 3 次后落 dead-letter
+  feishu-cli event consume im.message.receive_v1 --filter '.event.message.chat_type == "group"' \
+    --exec './bot.sh' --handler-retries 3 --dead-letter-dir ./dead-letter > /dev/null
+
+  # 转发到本地 HTTP 服务
+  feishu-cli event consume im.message.receive_v1 --forward-url http://127.0.0.1:8080/feishu > /dev/null
+
+  # 只要包含关键字的消息，并提炼为精简对象
+  feishu-cli event consume im.message.receive_v1 \
+    --filter '.event.message.content | fromjson | .text // "" | contains("部署")' \
+    --jq '{chat_id: .event.message.chat_id, sender: .event.sender.sender_id.open_id, text: (.event.message.content | fromjson | .text)}'`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		key := args[0]
-		def, ok := event.Lookup(key)
-		if !ok {
-			return fmt.Errorf("未知 EventKey: %q（运行 `feishu-cli event list` 查看支持的 key）", key)
+		var patterns []string
+		for _, arg := range args {
+			for _, p := range strings.Split(arg, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					patterns = append(patterns, p)
+				}
+			}
+		}
+		defs, err := event.ResolveKeys(patterns)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(defs))
+		for _, def := range defs {
+			keys = append(keys, def.Key)
 		}
 
 		maxEvents, _ := cmd.Flags().GetInt("max-events")
 		timeout, _ := cmd.Flags().GetDuration("timeout")
+		filterExpr, _ := cmd.Flags().GetString("filter")
 		jqExpr, _ := cmd.Flags().GetString("jq")
 		outputDir, _ := cmd.Flags().GetString("output-dir")
 		quiet, _ := cmd.Flags().GetBool("quiet")
 
-		if err := event.ValidateDotPathExpr(jqExpr); err != nil {
+		dedup, _ := cmd.Flags().GetBool("dedup")
+		dedupTTL, _ := cmd.Flags().GetDuration("dedup-ttl")
+		dedupMax, _ := cmd.Flags().GetInt("dedup-max")
+
+		handlers := event.HandlerOptions{}
+		handlers.ExecCmd, _ = cmd.Flags().GetString("exec")
+		handlers.ForwardURL, _ = cmd.Flags().GetString("forward-url")
+		handlers.Concurrency, _ = cmd.Flags().GetInt("handler-concurrency")
+		handlers.Timeout, _ = cmd.Flags().GetDuration("handler-timeout")
+		handlers.Retries, _ = cmd.Flags().GetInt("handler-retries")
+		handlers.Backoff, _ = cmd.Flags().GetDuration("handler-backoff")
+		handlers.DeadLetterDir, _ = cmd.Flags().GetString("dead-letter-dir")
+
+		if err := event.ValidateJQExpr("filter", filterExpr); err != nil {
+			return err
+		}
+		if err := event.ValidateJQExpr("jq", jqExpr); err != nil {
 			return err
 		}
 		if err := event.ValidateOutputDir(outputDir); err != nil {
 			return err
 		}
+		if err := event.ValidateHandlerOptions(handlers); err != nil {
+			return err
+		}
 
 		if err := config.Validate(); err != nil {
 			return err
@@ -106,12 +178,17 @@ var eventConsumeCmd = &cobra.Command{
 		runtime := event.NewRuntime(event.ConsumeOptions{
 			AppID:           cfg.AppID,
 			AppSecret:       cfg.AppSecret,
-			EventKey:        def.Key,
+			EventKeys:       keys,
 			BaseURL:         baseURL,
 			Out:             os.Stdout,
 			ErrOut:          errOut,
+			FilterExpr:      filterExpr,
 			JQExpr:          jqExpr,
 			OutputDir:       outputDir,
+			Handlers:        handlers,
+			Dedup:           dedup,
+			DedupTTL:        dedupTTL,
+			DedupMaxEntries: dedupMax,
 			MaxEvents:       maxEvents,
 			Timeout:         timeout,
 			UserAccessToken: userToken,
@@ -148,7 +225,17 @@ var eventConsumeCmd = &cobra.Command{
 		reason, runErr := runtime.Run(ctx)
 		elapsed := time.Since(start)
 
-		fmt.Fprintf(errOut, "[event] exited — elapsed=%s reason=%s\n", elapsed.Round(time.Millisecond), reason)
+		emitted, dropped := runtime.Counts()
+		fmt.Fprintf(errOut, "[event] exited — elapsed=%s reason=%s emitted=%d dropped=%d\n",
+			elapsed.Round(time.Millisecond), reason, emitted, dropped)
+		if dedup {
+			seen, dups := runtime.DedupCounts()
+			fmt.Fprintf(errOut, "[event] dedup — seen=%d duplicates=%d\n", seen, dups)
+		}
+		if handlers.Enabled() {
+			ok, failed := runtime.HandlerCounts()
+			fmt.Fprintf(errOut, "[event] handlers — succeeded=%d failed=%d\n", ok, failed)
+		}
 		return runErr
 	},
 }
@@ -167,8 +254,19 @@ func init() {
 	eventCmd.AddCommand(eventConsumeCmd)
 	eventConsumeCmd.Flags().Int("max-events", 0, "接收到 N 条事件后退出（0=不限制）")
 	eventConsumeCmd.Flags().Duration("timeout", 0, "运行 D 时长后退出（如 30s / 5m，0=不限制）")
-	eventConsumeCmd.Flags().String("jq", "", "极简点路径过滤，如 .event.message（不支持完整 jq 语法）")
+	eventConsumeCmd.Flags().String("filter", "", "jq 谓词，结果非 null/false 的事件才输出并计入 --max-events")
+	eventConsumeCmd.Flags().String("jq", "", "jq 变换表达式，每个非 null 输出写一行 NDJSON")
 	eventConsumeCmd.Flags().String("user-access-token", "", "User Access Token（审批等需服务端订阅注册的 EventKey 使用）")
 	eventConsumeCmd.Flags().String("output-dir", "", "把每条事件 dump 为 <event_id>.json 到该目录（不影响 stdout）")
+	eventConsumeCmd.Flags().String("exec", "", "每条事件执行一次的 shell 命令（事件 JSON 走 stdin，元信息走 FEISHU_EVENT_* 环境变量）")
+	eventConsumeCmd.Flags().String("forward-url", "", "把每条事件 POST 到该 HTTP 地址（如 http://127.0.0.1:8080/hook）")
+	eventConsumeCmd.Flags().Int("handler-concurrency", event.DefaultHandlerConcurrency, "处理器最大并发数")
+	eventConsumeCmd.Flags().Duration("handler-timeout", event.DefaultHandlerTimeout, "单次处理器执行超时")
+	eventConsumeCmd.Flags().Int("handler-retries", event.DefaultHandlerRetries, "处理器失败后的重试次数（不含首次）")
+	eventConsumeCmd.Flags().Duration("handler-backoff", event.DefaultHandlerBackoff, "首次重试等待时长，之后指数翻倍（上限 30s）")
+	eventConsumeCmd.Flags().String("dead-letter-dir", "", "重试耗尽的事件写到该目录（安全相对路径）")
+	eventConsumeCmd.Flags().Bool("dedup", false, "按 event_id 持久化去重，跨重连 / 重推 / 进程重启生效")
+	eventConsumeCmd.Flags().Duration("dedup-ttl", event.DefaultDedupTTL, "去重记录保留时长")
+	eventConsumeCmd.Flags().Int("dedup-max", event.DefaultDedupMaxEntries, "去重记录最大条数（超出淘汰最旧）")
 	eventConsumeCmd.Flags().Bool("quiet", false, "静默模式：抑制 stderr 诊断（不影响 stdout 事件流；ready marker 仍会输出，便于父进程判断就绪）")
 }
@@ -51,7 +51,7 @@ func TestSmokeEventConsume(t *testing.T) {
 	runtime := event.NewRuntime(event.ConsumeOptions{
 		AppID:     cfg.AppID,
 		AppSecret: cfg.AppSecret,
-		EventKey:  "im.message.receive_v1",
+		EventKeys: []string{"im.message.receive_v1"},
 		BaseURL:   "https://open.feishu.cn",
 		Out:       stdout,
 		ErrOut:    io.MultiWriter(stderr, os.Stderr),
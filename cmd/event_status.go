@@ -21,7 +21,8 @@ var eventStatusCmd = &cobra.Command{
   - EventKey
   - 启动时间 / 运行时长
   - max-events / timeout 限制（若配置）
-  - output-dir / jq 配置（若配置）
+  - output-dir / filter / jq / exec / forward-url 配置（若配置）
+  - --dedup 去重统计：已检查事件数 / 重复丢弃数（consume 每 5s 写回一次）
 
 实现说明:
   状态来源：~/.feishu-cli/events/<app_id>/bus.json（每个 consume 启动时写入，退出时移除）。
@@ -81,9 +82,21 @@ var eventStatusCmd = &cobra.Command{
 			if c.OutputDir != "" {
 				extra = append(extra, "output-dir="+c.OutputDir)
 			}
+			if c.FilterExpr != "" {
+				extra = append(extra, "filter="+c.FilterExpr)
+			}
 			if c.JQExpr != "" {
 				extra = append(extra, "jq="+c.JQExpr)
 			}
+			if c.ExecCmd != "" {
+				extra = append(extra, "exec="+c.ExecCmd)
+			}
+			if c.ForwardURL != "" {
+				extra = append(extra, "forward-url="+c.ForwardURL)
+			}
+			if c.Dedup {
+				extra = append(extra, fmt.Sprintf("dedup(seen=%d dup=%d)", c.DedupSeen, c.DedupDuplicates))
+			}
 			extraStr := "-"
 			if len(extra) > 0 {
 				extraStr = stringJoin(extra, " ")
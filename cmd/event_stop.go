@@ -16,7 +16,7 @@ var eventStopCmd = &cobra.Command{
 	Long: `停止本机活跃的 consume 进程。可以按以下三种方式之一指定目标：
 
   --pid <N>          按 PID 精确停止
-  --event-key <key>  停止订阅该 EventKey 的所有进程
+  --event-key <key>  停止订阅该 EventKey 的所有进程（含同时订阅多个 key 的进程）
   --all              停止所有 consume 进程（当前 AppID）
 
 实现:
@@ -65,7 +65,7 @@ var eventStopCmd = &cobra.Command{
 				targets = append(targets, c)
 				continue
 			}
-			if eventKey != "" && c.EventKey == eventKey {
+			if eventKey != "" && c.HasKey(eventKey) {
 				targets = append(targets, c)
 			}
 		}
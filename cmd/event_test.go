@@ -75,7 +75,9 @@ func TestEventStopCmd_RejectsNoArgs(t *testing.T) {
 
 func TestEventConsumeCmd_RegisteredFlags(t *testing.T) {
 	// 验证关键 flag 已注册（避免后续重构遗漏）
-	for _, flag := range []string{"max-events", "timeout", "jq", "output-dir", "quiet"} {
+	for _, flag := range []string{"max-events", "timeout", "filter", "jq", "output-dir", "quiet",
+		"exec", "forward-url", "handler-concurrency", "handler-timeout", "handler-retries", "handler-backoff", "dead-letter-dir",
+		"dedup", "dedup-ttl", "dedup-max"} {
 		if eventConsumeCmd.Flag(flag) == nil {
 			t.Errorf("consume 命令缺少 --%s flag", flag)
 		}
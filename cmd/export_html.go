@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/converter"
+)
+
+// defaultHTMLAssetsDir 返回 HTML 导出的默认资源目录：输出文件旁的 <文件名>_assets/，
+// 输出到 stdout 时为 ./assets。
+func defaultHTMLAssetsDir(output string) string {
+	if output == "" {
+		return "assets"
+	}
+	base := strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+	return filepath.Join(filepath.Dir(output), base+"_assets")
+}
+
+// exportDocumentHTML 把文档块渲染为 HTML 并写出；资源路径相对于输出文件所在目录。
+func exportDocumentHTML(blocks []*larkdocx.Block, documentID, output, assetsDir, userAccessToken string, inlineAssets, expandMentions, expandSheets bool) error {
+	options := converter.ConvertOptions{
+		AssetsDir:       assetsDir,
+		DocumentID:      documentID,
+		UserAccessToken: userAccessToken,
+		Debug:           config.Get().Debug,
+		ExpandMentions:  expandMentions,
+		ExpandSheets:    expandSheets,
+	}
+	htmlOptions := converter.HTMLOptions{InlineAssets: inlineAssets}
+	if output != "" {
+		htmlOptions.AssetsBaseDir = filepath.Dir(output)
+	}
+
+	var conv *converter.BlockToHTML
+	if expandMentions {
+		conv = converter.NewBlockToHTMLWithResolver(blocks, options, htmlOptions, &FeishuUserResolver{})
+	} else {
+		conv = converter.NewBlockToHTML(blocks, options, htmlOptions)
+	}
+	page, err := conv.Convert()
+	if err != nil {
+		return fmt.Errorf("转换为 HTML 失败: %w", err)
+	}
+
+	if output == "" {
+		fmt.Print(page)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.WriteFile(output, []byte(page), 0600); err != nil {
+		return fmt.Errorf("写入输出文件失败: %w", err)
+	}
+	fmt.Printf("已导出到 %s\n", output)
+	return nil
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultHTMLAssetsDir(t *testing.T) {
+	tests := map[string]string{
+		"":                   "assets",
+		"doc.html":           "doc_assets",
+		"site/posts/a.b.htm": filepath.Join("site", "posts", "a.b_assets"),
+	}
+	for output, want := range tests {
+		if got := defaultHTMLAssetsDir(output); got != want {
+			t.Errorf("defaultHTMLAssetsDir(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
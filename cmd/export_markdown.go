@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 
 	"github.com/riba2534/feishu-cli/internal/client"
 	"github.com/riba2534/feishu-cli/internal/config"
@@ -13,8 +14,8 @@ import (
 
 var exportMarkdownCmd = &cobra.Command{
 	Use:   "export <document_id|url>",
-	Short: "导出文档为 Markdown",
-	Long: `将飞书文档导出为 Markdown 格式。
+	Short: "导出文档为 Markdown / HTML",
+	Long: `将飞书文档导出为 Markdown 格式，或用 --format html 在本地渲染为 HTML。
 
 支持通过文档 ID 或 URL 导出：
   feishu-cli doc export ABC123def456
@@ -26,11 +27,18 @@ var exportMarkdownCmd = &cobra.Command{
 内嵌飞书电子表格默认会自动展开为 Markdown 表格，可用 --expand-sheets=false 保留为 <sheet/> 引用。
 跨文档引用同步块会自动读取源文档并展开；权限或 API 异常时输出带源标识的 WARNING 占位和 stderr 诊断，不会静默丢失内容。
 
+--format html 输出语义化 HTML：表格保留合并单元格与列宽，高亮块、分栏（CSS Grid）、
+文字颜色原样保留，代码块带 language-xxx class，画板以 SVG 嵌入。图片和画板总是下载，
+默认写入输出文件旁的 <文件名>_assets/ 目录（或 --assets-dir），--inline-assets 则内联为
+data URI，生成单个自包含的 HTML 文件。
+
 示例:
   feishu-cli doc export ABC123def456
   feishu-cli doc export ABC123def456 --output doc.md
   feishu-cli doc export ABC123def456 --download-images
-  feishu-cli doc export ABC123def456 --download-images --assets-dir ./images`,
+  feishu-cli doc export ABC123def456 --download-images --assets-dir ./images
+  feishu-cli doc export ABC123def456 --format html -o site/doc.html
+  feishu-cli doc export ABC123def456 --format html --inline-assets -o doc.html`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.Validate(); err != nil {
@@ -44,6 +52,14 @@ var exportMarkdownCmd = &cobra.Command{
 		output, _ := cmd.Flags().GetString("output")
 		downloadImages, _ := cmd.Flags().GetBool("download-images")
 		assetsDir, _ := cmd.Flags().GetString("assets-dir")
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "md" {
+			format = "markdown"
+		}
+		if format != "markdown" && format != "html" {
+			return fmt.Errorf("不支持的导出格式: %s（支持 markdown/html）", format)
+		}
 
 		// 获取可选的 User Access Token（用于访问无 App 权限的文档）
 		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
@@ -60,6 +76,14 @@ var exportMarkdownCmd = &cobra.Command{
 		expandMentions, _ := cmd.Flags().GetBool("expand-mentions")
 		expandSheets, _ := cmd.Flags().GetBool("expand-sheets")
 
+		if format == "html" {
+			inlineAssets, _ := cmd.Flags().GetBool("inline-assets")
+			if !cmd.Flags().Changed("assets-dir") {
+				assetsDir = defaultHTMLAssetsDir(output)
+			}
+			return exportDocumentHTML(blocks, documentID, output, assetsDir, userAccessToken, inlineAssets, expandMentions, expandSheets)
+		}
+
 		// Convert to Markdown
 		cfg := config.Get()
 		options := converter.ConvertOptions{
@@ -132,6 +156,8 @@ func extractDocToken(input string) (string, error) {
 func init() {
 	docCmd.AddCommand(exportMarkdownCmd)
 	exportMarkdownCmd.Flags().StringP("output", "o", "", "输出文件路径")
+	exportMarkdownCmd.Flags().StringP("format", "f", "markdown", "导出格式（markdown/html）")
+	exportMarkdownCmd.Flags().Bool("inline-assets", false, "HTML 格式下把图片和画板内联为 data URI，生成单文件 HTML")
 	exportMarkdownCmd.Flags().Bool("download-images", false, "下载图片和画板到本地目录（画板自动导出为 PNG）")
 	exportMarkdownCmd.Flags().String("assets-dir", "./assets", "图片和画板的保存目录")
 	exportMarkdownCmd.Flags().Bool("front-matter", false, "添加 YAML front matter (标题和文档 ID)")
@@ -127,14 +127,16 @@ var exportWikiCmd = &cobra.Command{
 
 // exportDocxToMarkdown 导出 docx 类型文档为 Markdown
 func exportDocxToMarkdown(docToken, userAccessToken string, cmd *cobra.Command) (string, error) {
-	return exportDocxToMarkdownWithAssets(docToken, userAccessToken, cmd, "")
+	md, _, err := exportDocxToMarkdownWithAssets(docToken, userAccessToken, cmd, "")
+	return md, err
 }
 
-func exportDocxToMarkdownWithAssets(docToken, userAccessToken string, cmd *cobra.Command, assetsDirOverride string) (string, error) {
+// exportDocxToMarkdownWithAssets 导出 docx 为 Markdown，同时返回标题块的锚点（block_id → 锚点）
+func exportDocxToMarkdownWithAssets(docToken, userAccessToken string, cmd *cobra.Command, assetsDirOverride string) (string, map[string]string, error) {
 	fmt.Println("正在获取文档内容...")
 	blocks, err := client.GetAllBlocksWithToken(docToken, userAccessToken)
 	if err != nil {
-		return "", fmt.Errorf("获取块失败: %w", err)
+		return "", nil, fmt.Errorf("获取块失败: %w", err)
 	}
 
 	downloadImages, _ := cmd.Flags().GetBool("download-images")
@@ -161,9 +163,9 @@ func exportDocxToMarkdownWithAssets(docToken, userAccessToken string, cmd *cobra
 	conv := newExportBlockToMarkdownConverter(blocks, options, &FeishuUserResolver{})
 	md, err := conv.Convert()
 	if err != nil {
-		return "", fmt.Errorf("转换为 Markdown 失败: %w", err)
+		return "", nil, fmt.Errorf("转换为 Markdown 失败: %w", err)
 	}
-	return md, nil
+	return md, conv.HeadingAnchors(), nil
 }
 
 func readExpandMentionsFlag(cmd *cobra.Command) bool {
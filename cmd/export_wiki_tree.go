@@ -27,6 +27,16 @@ var exportWikiTreeCmd = &cobra.Command{
   sheet     电子表格（读取数据转为 Markdown 表格）
   其它类型（doc / bitable / mindnote / file / slides）会被跳过并计入 unsupported。
 
+增量导出（--incremental）:
+  状态文件记录每个节点的 obj_edit_time、本地路径、文件摘要与链接关系。再次运行时只导出
+  新增、编辑过、改名/移动（旧文件随之移走）以及本地文件缺失或被改动的节点；链接到改名/移动节点的
+  未变化页面会一并刷新。已删除或移出子树的节点默认保留本地文件，--prune 时删除。
+
+文档间链接:
+  指向本次导出节点的飞书链接（/wiki/<token>、/docx/<token> 等）和 @文档 会在全部导出后
+  改写为本地相对路径，指向标题块的链接附带 #锚点，导出的目录可离线浏览或直接发布为静态站点。
+  指向子树之外文档的链接保持原样，并记录到悬空链接报告（默认 <output-dir>/.feishu-dangling-links.json）。
+
 示例:
   # 导出整棵子树到当前目录
   feishu-cli wiki export-tree LHAswV4ahiqVM4kwtbZcHhvynth
@@ -43,7 +53,13 @@ var exportWikiTreeCmd = &cobra.Command{
   # 只导 docx，跳过 sheet
   feishu-cli wiki export-tree <token> -o ./backup --include-types docx
 
-  # 增量同步：已存在的 md 跳过
+  # 增量同步：只导出上次以来编辑过、新增或改名/移动的节点（状态记录在 <output-dir>/.feishu-export-state.json）
+  feishu-cli wiki export-tree <token> -o ./backup --incremental
+
+  # 增量同步并删除已从知识库移除的节点对应的本地文件
+  feishu-cli wiki export-tree <token> -o ./backup --incremental --prune
+
+  # 只按文件是否存在跳过（不检查页面是否被修改）
   feishu-cli wiki export-tree <token> -o ./backup --skip-existing
 
   # 单文档失败时立即中断（默认 continue）
@@ -56,7 +72,10 @@ var exportWikiTreeCmd = &cobra.Command{
   feishu-cli wiki export-tree <token> -o ./backup --expand-sheets=false
 
   # 保留 @用户为可导入还原的标签
-  feishu-cli wiki export-tree <token> -o ./backup --expand-mentions=false`,
+  feishu-cli wiki export-tree <token> -o ./backup --expand-mentions=false
+
+  # 保留文档间的飞书链接，不改写为本地路径
+  feishu-cli wiki export-tree <token> -o ./backup --rewrite-links=false`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.Validate(); err != nil {
@@ -80,6 +99,20 @@ var exportWikiTreeCmd = &cobra.Command{
 		includeTypes, _ := cmd.Flags().GetStringSlice("include-types")
 		skipExisting, _ := cmd.Flags().GetBool("skip-existing")
 		continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+		rewriteLinks, _ := cmd.Flags().GetBool("rewrite-links")
+		linkReport, _ := cmd.Flags().GetString("link-report")
+		if linkReport == "" {
+			linkReport = filepath.Join(outputDir, defaultDanglingLinkReport)
+		}
+		incremental, _ := cmd.Flags().GetBool("incremental")
+		prune, _ := cmd.Flags().GetBool("prune")
+		statePath, _ := cmd.Flags().GetString("state-file")
+		if statePath == "" {
+			statePath = filepath.Join(outputDir, defaultExportTreeStateFile)
+		}
+		if prune && !incremental {
+			return fmt.Errorf("--prune 需要与 --incremental 一起使用")
+		}
 
 		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
 
@@ -100,87 +133,198 @@ var exportWikiTreeCmd = &cobra.Command{
 		}
 		fmt.Printf("共发现 %d 个节点，开始导出\n\n", len(jobs))
 
-		// 3. 逐个导出
+		// 3. 逐个导出（--incremental 时先对比状态文件，只导出变化的节点）
 		stats := newTreeStats(len(jobs))
+		index := newWikiTreeExportIndex()
+		var exported []treeJob
+		var state *exportTreeState
+		var reasons, removed []string
+		if incremental {
+			if state, err = loadExportTreeState(statePath); err != nil {
+				return err
+			}
+			if err := state.bind(rootToken); err != nil {
+				return err
+			}
+			reasons, removed = planIncrementalExport(jobs, state, outputDir, includeTypes)
+		}
+		currentPaths := make(map[string]bool, len(jobs))
+		for _, job := range jobs {
+			currentPaths[exportTreeRelPath(outputDir, job.OutputPath)] = true
+		}
+		// staleTargets 收集本次路径或锚点发生变化的节点，链接到它们的未变化文件需要重新导出
+		staleTargets := make(map[string]bool)
+
+		// exportJob 导出单个节点并写入文件，返回是否成功；增量模式下同步更新状态，节点移动/改名时删除旧文件。
+		// 只有 --continue-on-error=false 时的失败才返回 error 中断整个导出。
+		exportJob := func(i int, progress, note string) (bool, error) {
+			job := jobs[i]
+			rel := exportTreeRelPath(outputDir, job.OutputPath)
+			anchors, label, err := exportWikiTreeNode(cmd, job, outputDir, userAccessToken)
+			if err != nil {
+				index.addMissing(job, "节点导出失败")
+				stats.Failed++
+				stats.Failures = append(stats.Failures, treeFailure{
+					NodeToken: job.Node.NodeToken,
+					Title:     job.Node.Title,
+					Path:      rel,
+					Error:     fmt.Sprintf("%s: %v", label, err),
+				})
+				fmt.Printf("%s ✗ %s  (%s: %v)\n", progress, rel, label, err)
+				if !continueOnError {
+					return false, fmt.Errorf("%s %s: %w", job.Node.Title, label, err)
+				}
+				return false, nil
+			}
+			index.addExported(job, anchors)
+			if note != "" {
+				note = "  (" + note + ")"
+			}
+			fmt.Printf("%s ✓ %s%s\n", progress, rel, note)
+			if state == nil {
+				return true, nil
+			}
+
+			prev, existed := state.Nodes[job.Node.NodeToken]
+			if existed && prev.Path != rel {
+				staleTargets[job.Node.NodeToken] = true
+				stats.Moved++
+				if !currentPaths[prev.Path] {
+					if err := removeExportedFile(outputDir, prev.Path, prev.Assets); err != nil {
+						fmt.Printf("    ⚠ 删除旧文件 %s 失败: %v\n", prev.Path, err)
+					}
+				}
+			} else if existed && !sameStringMap(prev.Anchors, anchors) {
+				staleTargets[job.Node.NodeToken] = true
+			}
+			state.Nodes[job.Node.NodeToken] = exportTreeStateNode{
+				ObjToken: job.Node.ObjToken,
+				ObjType:  job.Node.ObjType,
+				Title:    job.Node.Title,
+				Path:     rel,
+				EditTime: job.Node.ObjEditTime,
+				Assets:   exportedAssetFiles(outputDir, wikiTreeNodeAssetsDir(cmd, outputDir, job)),
+				Anchors:  anchors,
+			}
+			return true, nil
+		}
+
 		for i, job := range jobs {
 			progress := fmt.Sprintf("[%d/%d]", i+1, len(jobs))
-			rel, _ := filepath.Rel(outputDir, job.OutputPath)
-			if rel == "" {
-				rel = job.OutputPath
-			}
+			rel := exportTreeRelPath(outputDir, job.OutputPath)
 
 			// 类型过滤
 			if !isExportableWikiType(job.Node.ObjType, includeTypes) {
 				fmt.Printf("%s ⊘ %s  (skip: 不在 --include-types 列表，obj_type=%s)\n", progress, rel, job.Node.ObjType)
 				stats.Unsupported++
+				index.addMissing(job, fmt.Sprintf("目标节点类型 %s 未导出", job.Node.ObjType))
+				continue
+			}
+
+			// 增量：未变化的节点沿用本地文件与状态中记录的锚点
+			if incremental && reasons[i] == "" {
+				stats.Unchanged++
+				index.addExported(job, state.Nodes[job.Node.NodeToken].Anchors)
+				exported = append(exported, job)
 				continue
 			}
 
 			// skip-existing
-			if skipExisting && fileExistsAndNonEmpty(job.OutputPath) {
+			if !incremental && skipExisting && fileExistsAndNonEmpty(job.OutputPath) {
 				fmt.Printf("%s ⏭  %s  (已存在，跳过)\n", progress, rel)
 				stats.Skipped++
+				index.addExported(job, nil)
+				exported = append(exported, job)
 				continue
 			}
 
-			// 确保父目录存在
-			if err := os.MkdirAll(filepath.Dir(job.OutputPath), 0700); err != nil {
-				stats.Failed++
-				stats.Failures = append(stats.Failures, treeFailure{
-					NodeToken: job.Node.NodeToken,
-					Title:     job.Node.Title,
-					Path:      rel,
-					Error:     fmt.Sprintf("创建目录失败: %v", err),
-				})
-				fmt.Printf("%s ✗ %s  (创建目录失败: %v)\n", progress, rel, err)
-				if !continueOnError {
-					return fmt.Errorf("创建目录失败: %w", err)
-				}
-				continue
+			note := ""
+			if incremental {
+				note = reasons[i]
 			}
-
-			assetsDirOverride := wikiTreeNodeAssetsDir(cmd, outputDir, job)
-			markdown, err := exportWikiNodeMarkdown(job.Node, userAccessToken, cmd, assetsDirOverride)
+			ok, err := exportJob(i, progress, note)
 			if err != nil {
-				stats.Failed++
-				stats.Failures = append(stats.Failures, treeFailure{
-					NodeToken: job.Node.NodeToken,
-					Title:     job.Node.Title,
-					Path:      rel,
-					Error:     err.Error(),
-				})
-				fmt.Printf("%s ✗ %s  (导出失败: %v)\n", progress, rel, err)
-				if !continueOnError {
-					return fmt.Errorf("%s 导出失败: %w", job.Node.Title, err)
+				return err
+			}
+			if ok {
+				stats.Success++
+				exported = append(exported, job)
+				// 每导出一批保存一次状态，中断后重跑不必从头开始（文件摘要在链接改写后统一补齐）
+				if state != nil && stats.Success%50 == 0 {
+					if err := state.save(statePath); err != nil {
+						return err
+					}
 				}
-				continue
 			}
+		}
 
-			// 将图片路径从 CWD 相对路径改为文档相对路径，确保 md 预览图片正确解析
-			if assetsDirOverride != "" && markdown != "" {
-				markdown = makeImagePathsDocumentRelative(markdown, assetsDirOverride, job.OutputPath)
+		if incremental {
+			// 已从知识库删除（或移出导出范围）的节点：--prune 时删除本地文件
+			for _, token := range removed {
+				prev := state.Nodes[token]
+				if currentPaths[prev.Path] {
+					// 路径已被其它节点占用，只丢弃旧记录
+					delete(state.Nodes, token)
+					continue
+				}
+				if !prune {
+					stats.Orphaned++
+					fmt.Printf("  ⚠ %s 对应的节点已不在导出范围内（已删除或移出子树，--prune 可删除本地文件）\n", prev.Path)
+					continue
+				}
+				if err := removeExportedFile(outputDir, prev.Path, prev.Assets); err != nil {
+					return fmt.Errorf("删除 %s 失败: %w", prev.Path, err)
+				}
+				delete(state.Nodes, token)
+				staleTargets[token] = true
+				stats.Removed++
+				fmt.Printf("  - %s  (节点已不在导出范围内，移除本地文件)\n", prev.Path)
 			}
 
-			if err := os.WriteFile(job.OutputPath, []byte(markdown), 0600); err != nil {
-				stats.Failed++
-				stats.Failures = append(stats.Failures, treeFailure{
-					NodeToken: job.Node.NodeToken,
-					Title:     job.Node.Title,
-					Path:      rel,
-					Error:     fmt.Sprintf("写入文件失败: %v", err),
-				})
-				fmt.Printf("%s ✗ %s  (写入失败: %v)\n", progress, rel, err)
-				if !continueOnError {
-					return fmt.Errorf("写入文件失败: %w", err)
+			// 链接到已移动/删除/标题变化节点的未变化文件需要重新导出，刷新其中的相对路径与锚点
+			if rewriteLinks {
+				for _, i := range staleLinkers(jobs, reasons, state, staleTargets) {
+					stats.Unchanged--
+					ok, err := exportJob(i, "[刷新]", "链接目标已变化")
+					if err != nil {
+						return err
+					}
+					if ok {
+						stats.Success++
+					}
 				}
-				continue
 			}
+		}
+
+		// 4. 文档间链接改写为本地相对路径（需要全部节点导出完成后才知道目标文件和标题锚点）
+		var linked map[string][]string
+		if rewriteLinks {
+			if linked, err = rewriteWikiTreeExportLinks(exported, index, outputDir, linkReport); err != nil {
+				return err
+			}
+		}
 
-			stats.Success++
-			fmt.Printf("%s ✓ %s\n", progress, rel)
+		// 5. 增量：记录每个文件的最终摘要与链接关系
+		if incremental {
+			for _, job := range exported {
+				entry, ok := state.Nodes[job.Node.NodeToken]
+				if !ok {
+					continue
+				}
+				data, err := os.ReadFile(job.OutputPath)
+				if err != nil {
+					continue
+				}
+				entry.Hash = wikiTreeContentHash(string(data))
+				entry.Links = mergeLinkTokens(entry.Links, linked[job.OutputPath])
+				state.Nodes[job.Node.NodeToken] = entry
+			}
+			if err := state.save(statePath); err != nil {
+				return err
+			}
 		}
 
-		// 4. 总结
+		// 6. 总结
 		printTreeSummary(stats, outputDir)
 		if stats.Failed > 0 {
 			return fmt.Errorf("递归导出完成但有 %d 个节点失败", stats.Failed)
@@ -210,6 +354,10 @@ type treeStats struct {
 	Skipped     int
 	Unsupported int
 	Failed      int
+	Unchanged   int // --incremental：未变化
+	Moved       int // --incremental：标题或位置变化，本地文件已移动
+	Removed     int // --incremental --prune：节点已删除，本地文件已移除
+	Orphaned    int // --incremental：节点已删除但保留了本地文件
 	Failures    []treeFailure
 }
 
@@ -369,15 +517,41 @@ func fileExistsAndNonEmpty(path string) bool {
 }
 
 // exportWikiNodeMarkdown 复用现有 export_wiki.go 里的转换逻辑，根据 obj_type 分发。
-func exportWikiNodeMarkdown(node *client.WikiNode, userAccessToken string, cmd *cobra.Command, assetsDirOverride string) (string, error) {
+// 第二个返回值是 docx 标题块的锚点，供链接改写使用（sheet 没有）。
+func exportWikiNodeMarkdown(node *client.WikiNode, userAccessToken string, cmd *cobra.Command, assetsDirOverride string) (string, map[string]string, error) {
 	switch node.ObjType {
 	case "docx":
 		return exportDocxToMarkdownWithAssets(node.ObjToken, userAccessToken, cmd, assetsDirOverride)
 	case "sheet":
-		return exportSheetToMarkdown(node.ObjToken, node.Title, userAccessToken)
+		md, err := exportSheetToMarkdown(node.ObjToken, node.Title, userAccessToken)
+		return md, nil, err
 	default:
-		return "", fmt.Errorf("不支持的节点类型: %s", node.ObjType)
+		return "", nil, fmt.Errorf("不支持的节点类型: %s", node.ObjType)
+	}
+}
+
+// exportWikiTreeNode 导出单个节点并写入 job.OutputPath，返回标题锚点；失败时第二个返回值说明失败的环节
+func exportWikiTreeNode(cmd *cobra.Command, job treeJob, outputDir, userAccessToken string) (map[string]string, string, error) {
+	// 确保父目录存在
+	if err := os.MkdirAll(filepath.Dir(job.OutputPath), 0700); err != nil {
+		return nil, "创建目录失败", err
+	}
+
+	assetsDirOverride := wikiTreeNodeAssetsDir(cmd, outputDir, job)
+	markdown, anchors, err := exportWikiNodeMarkdown(job.Node, userAccessToken, cmd, assetsDirOverride)
+	if err != nil {
+		return nil, "导出失败", err
+	}
+
+	// 将图片路径从 CWD 相对路径改为文档相对路径，确保 md 预览图片正确解析
+	if assetsDirOverride != "" && markdown != "" {
+		markdown = makeImagePathsDocumentRelative(markdown, assetsDirOverride, job.OutputPath)
 	}
+
+	if err := os.WriteFile(job.OutputPath, []byte(markdown), 0600); err != nil {
+		return nil, "写入文件失败", err
+	}
+	return anchors, "", nil
 }
 
 func wikiTreeNodeAssetsDir(cmd *cobra.Command, outputDir string, job treeJob) string {
@@ -408,6 +582,18 @@ func printTreeSummary(stats *treeStats, outputDir string) {
 	if stats.Unsupported > 0 {
 		fmt.Printf("  跳过(不支持): %d\n", stats.Unsupported)
 	}
+	if stats.Unchanged > 0 {
+		fmt.Printf("  未变化:   %d\n", stats.Unchanged)
+	}
+	if stats.Moved > 0 {
+		fmt.Printf("  移动/改名: %d\n", stats.Moved)
+	}
+	if stats.Removed > 0 {
+		fmt.Printf("  已删除:   %d\n", stats.Removed)
+	}
+	if stats.Orphaned > 0 {
+		fmt.Printf("  已删除(保留本地文件): %d\n", stats.Orphaned)
+	}
 	if stats.Failed > 0 {
 		fmt.Printf("  失败:     %d\n", stats.Failed)
 		fmt.Println()
@@ -738,7 +924,12 @@ func init() {
 	exportWikiTreeCmd.Flags().String("assets-dir", "./assets", "图片下载目录（透传给底层 export）")
 	exportWikiTreeCmd.Flags().Bool("expand-sheets", true, "展开内嵌电子表格为 Markdown 表格（false 时保留 <sheet/> 引用）")
 	exportWikiTreeCmd.Flags().Bool("expand-mentions", true, "展开 @用户为友好格式（false 时保留 <mention-user/> 标签以支持导入还原）")
-	exportWikiTreeCmd.Flags().Bool("skip-existing", false, "已存在且非空的 md 跳过（适合增量同步）")
+	exportWikiTreeCmd.Flags().Bool("skip-existing", false, "已存在且非空的 md 跳过（只看文件是否存在，已修改的页面不会刷新；推荐改用 --incremental）")
+	exportWikiTreeCmd.Flags().Bool("incremental", false, "增量导出：按状态文件记录的 obj_edit_time 与内容摘要只导出变化的节点，并处理改名/移动")
+	exportWikiTreeCmd.Flags().String("state-file", "", "增量导出状态文件路径（默认 <output-dir>/"+defaultExportTreeStateFile+"）")
+	exportWikiTreeCmd.Flags().Bool("prune", false, "增量导出时删除已从知识库移除的节点对应的本地文件（需配合 --incremental）")
 	exportWikiTreeCmd.Flags().Bool("continue-on-error", true, "单个节点导出失败时是否继续后续节点")
+	exportWikiTreeCmd.Flags().Bool("rewrite-links", true, "把指向本次导出节点的飞书链接改写为本地相对路径（false 时保留原链接）")
+	exportWikiTreeCmd.Flags().String("link-report", "", "悬空链接报告路径（默认 <output-dir>/"+defaultDanglingLinkReport+"）")
 	exportWikiTreeCmd.Flags().String("user-access-token", "", "User Access Token（可选；默认优先使用 auth login 登录态，失败时回退 App Token）")
 }
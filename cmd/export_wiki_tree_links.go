@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultDanglingLinkReport 是 export-tree 悬空链接报告的默认文件名（位于输出目录下，隐藏文件不影响静态站点生成）
+const defaultDanglingLinkReport = ".feishu-dangling-links.json"
+
+var (
+	// feishuDocURLRe 匹配飞书文档链接的路径部分：/wiki/<token>、/docx/<token> 等，可带 query 与 #块锚点
+	feishuDocURLRe = regexp.MustCompile(`^(?:https?://([^/?#\s]+))?/(wiki|docx|docs|doc|sheets|base|mindnote|file|slides)/([A-Za-z0-9]+)/?(?:\?[^#]*)?(?:#(.*))?$`)
+	// mentionDocTagRe 匹配导出的 <mention-doc token="..." type="...">标题</mention-doc>
+	mentionDocTagRe = regexp.MustCompile(`<mention-doc token="([^"]*)" type="([^"]*)">(.*?)</mention-doc>`)
+)
+
+// wikiTreeExportIndex 是一次 export-tree 中已导出节点的索引，用于把文档间链接改写为本地相对路径
+type wikiTreeExportIndex struct {
+	paths   map[string]string            // node_token / obj_token → 输出文件路径
+	anchors map[string]map[string]string // 输出文件路径 → block_id → 标题锚点
+	missing map[string]string            // 子树内未导出的节点 token → 原因
+}
+
+func newWikiTreeExportIndex() *wikiTreeExportIndex {
+	return &wikiTreeExportIndex{
+		paths:   make(map[string]string),
+		anchors: make(map[string]map[string]string),
+		missing: make(map[string]string),
+	}
+}
+
+// addExported 登记一个已写出的节点；anchors 为空表示没有标题锚点信息（如 --skip-existing 跳过的文件）
+func (idx *wikiTreeExportIndex) addExported(job treeJob, anchors map[string]string) {
+	idx.paths[job.Node.NodeToken] = job.OutputPath
+	if job.Node.ObjToken != "" {
+		idx.paths[job.Node.ObjToken] = job.OutputPath
+	}
+	if len(anchors) > 0 {
+		idx.anchors[job.OutputPath] = anchors
+	}
+}
+
+// addMissing 登记子树内未能导出的节点，悬空链接报告中给出具体原因
+func (idx *wikiTreeExportIndex) addMissing(job treeJob, reason string) {
+	idx.missing[job.Node.NodeToken] = reason
+	if job.Node.ObjToken != "" {
+		idx.missing[job.Node.ObjToken] = reason
+	}
+}
+
+// danglingLink 是一条指向导出范围之外飞书文档的链接
+type danglingLink struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	URL    string `json:"url"`
+	Token  string `json:"token"`
+	Reason string `json:"reason"`
+}
+
+// danglingReason 返回 token 未能改写的原因
+func (idx *wikiTreeExportIndex) danglingReason(token string) string {
+	if reason, ok := idx.missing[token]; ok {
+		return reason
+	}
+	return "目标文档不在本次导出的子树中"
+}
+
+// resolve 把飞书文档链接解析为 fromPath 所在目录下的相对路径。
+// 第二个返回值为文档 token（不是飞书文档链接时为空），第三个返回值表示是否改写成功。
+func (idx *wikiTreeExportIndex) resolve(link, fromPath string) (string, string, bool) {
+	m := feishuDocURLRe.FindStringSubmatch(link)
+	if m == nil {
+		return "", "", false
+	}
+	if host := strings.ToLower(m[1]); host != "" && !strings.Contains(host, "feishu") && !strings.Contains(host, "lark") {
+		return "", "", false
+	}
+	token := m[3]
+	target, ok := idx.paths[token]
+	if !ok {
+		return "", token, false
+	}
+
+	anchor := ""
+	if blockID := feishuBlockFragment(m[4]); blockID != "" {
+		anchor = idx.anchors[target][blockID]
+	}
+	if target == fromPath {
+		if anchor != "" {
+			return "#" + anchor, token, true
+		}
+		return escapeLocalLinkPath(filepath.Base(target)), token, true
+	}
+	rel, err := filepath.Rel(filepath.Dir(fromPath), target)
+	if err != nil {
+		return "", token, false
+	}
+	rel = escapeLocalLinkPath(filepath.ToSlash(rel))
+	if anchor != "" {
+		rel += "#" + anchor
+	}
+	return rel, token, true
+}
+
+// feishuBlockFragment 从链接 #片段 中取出块 ID：飞书「复制块链接」形如 #share-<block_id> 或 #part-<block_id>
+func feishuBlockFragment(fragment string) string {
+	fragment = strings.TrimSpace(fragment)
+	for _, prefix := range []string{"share-", "part-"} {
+		if strings.HasPrefix(fragment, prefix) {
+			return fragment[len(prefix):]
+		}
+	}
+	return fragment
+}
+
+// escapeLocalLinkPath 转义相对路径中会破坏 Markdown 链接语法的字符
+func escapeLocalLinkPath(p string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(p)
+}
+
+// rewriteExportedDocLinks 把 Markdown 中指向本次导出节点的飞书链接与 <mention-doc> 改写为本地相对路径，
+// 返回改写后的内容、被改写链接的目标 token（按出现顺序）与悬空链接。代码块与行内代码中的内容保持原样。
+func rewriteExportedDocLinks(markdown, fromPath string, idx *wikiTreeExportIndex) (string, []string, []danglingLink) {
+	var rewritten []string
+	var dangling []danglingLink
+
+	lines := strings.SplitAfter(markdown, "\n")
+	inFence := false
+	fenceChar := byte(0)
+	fenceLength := 0
+	for i, line := range lines {
+		content := exportedMediaContainerContent(line)
+		if char, length, trailing, ok := exportedMediaFence(content); ok {
+			if !inFence {
+				inFence, fenceChar, fenceLength = true, char, length
+			} else if char == fenceChar && length >= fenceLength && trailing == "" {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		report := func(link, token string) {
+			dangling = append(dangling, danglingLink{File: fromPath, Line: i + 1, URL: link, Token: token, Reason: idx.danglingReason(token)})
+		}
+		lines[i] = mapOutsideInlineCode(line, func(text string) string {
+			text = wikiTreeInlineLinkRe.ReplaceAllStringFunc(text, func(match string) string {
+				sub := wikiTreeInlineLinkRe.FindStringSubmatch(match)
+				target := strings.TrimSuffix(strings.TrimPrefix(sub[1], "<"), ">")
+				if unescaped, err := url.PathUnescape(target); err == nil {
+					target = unescaped
+				}
+				local, token, ok := idx.resolve(target, fromPath)
+				if !ok {
+					if token != "" {
+						report(target, token)
+					}
+					return match
+				}
+				rewritten = append(rewritten, token)
+				return "](" + local + sub[2] + ")"
+			})
+			return mentionDocTagRe.ReplaceAllStringFunc(text, func(match string) string {
+				sub := mentionDocTagRe.FindStringSubmatch(match)
+				token, title := sub[1], sub[3]
+				local, _, ok := idx.resolve("/"+mentionDocPathKind(sub[2])+"/"+token, fromPath)
+				if !ok {
+					report(match, token)
+					return match
+				}
+				if title == "" {
+					title = strings.TrimSuffix(path.Base(local), ".md")
+				}
+				rewritten = append(rewritten, token)
+				return "[" + escapeLinkText(title) + "](" + local + ")"
+			})
+		})
+	}
+	return strings.Join(lines, ""), rewritten, dangling
+}
+
+// mentionDocPathKind 把 mention-doc 的 type 映射为链接路径段（wiki 节点与云文档都按 token 查索引，路径段只用于匹配）
+func mentionDocPathKind(docType string) string {
+	switch docType {
+	case "wiki", "docx", "doc", "file", "mindnote", "slides":
+		return docType
+	case "sheet":
+		return "sheets"
+	case "bitable":
+		return "base"
+	}
+	return "docx"
+}
+
+// escapeLinkText 转义链接文字中的方括号
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+}
+
+// writeDanglingLinkReport 把悬空链接写入 JSON 报告
+func writeDanglingLinkReport(reportPath string, links []danglingLink) error {
+	if links == nil {
+		links = []danglingLink{}
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"total": len(links),
+		"links": links,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(reportPath, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("写入悬空链接报告失败: %w", err)
+	}
+	return nil
+}
+
+// rewriteWikiTreeExportLinks 对已导出的文件逐个改写文档间链接，并写出悬空链接报告。
+// 返回每个被改写文件（输出路径）中链接到的节点 token，供增量导出记录链接关系。
+func rewriteWikiTreeExportLinks(exported []treeJob, index *wikiTreeExportIndex, outputDir, reportPath string) (map[string][]string, error) {
+	fmt.Printf("\n=== 改写文档间链接 ===\n")
+	total := 0
+	linked := make(map[string][]string)
+	var dangling []danglingLink
+	for _, job := range exported {
+		data, err := os.ReadFile(job.OutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %w", job.OutputPath, err)
+		}
+		markdown, tokens, links := rewriteExportedDocLinks(string(data), job.OutputPath, index)
+		rel, err := filepath.Rel(outputDir, job.OutputPath)
+		if err != nil {
+			rel = job.OutputPath
+		}
+		for i := range links {
+			links[i].File = filepath.ToSlash(rel)
+		}
+		dangling = append(dangling, links...)
+		if len(tokens) == 0 {
+			continue
+		}
+		if err := os.WriteFile(job.OutputPath, []byte(markdown), 0600); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", job.OutputPath, err)
+		}
+		total += len(tokens)
+		linked[job.OutputPath] = tokens
+	}
+	if err := writeDanglingLinkReport(reportPath, dangling); err != nil {
+		return nil, err
+	}
+	fmt.Printf("已改写 %d 个文件中的 %d 个链接\n", len(linked), total)
+	if len(dangling) > 0 {
+		fmt.Printf("悬空链接 %d 个（指向导出范围之外），详见 %s\n", len(dangling), reportPath)
+	}
+	return linked, nil
+}
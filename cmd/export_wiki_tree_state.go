@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultExportTreeStateFile 是 export-tree --incremental 状态文件的默认文件名（位于输出目录下）
+const defaultExportTreeStateFile = ".feishu-export-state.json"
+
+// exportTreeState 记录上一次增量导出时每个节点的版本与本地文件，下次运行据此只导出变化的节点
+type exportTreeState struct {
+	RootNode string                         `json:"root_node"`
+	Nodes    map[string]exportTreeStateNode `json:"nodes"` // node_token → 节点状态
+}
+
+// exportTreeStateNode 是状态文件中的一个节点
+type exportTreeStateNode struct {
+	ObjToken string            `json:"obj_token"`
+	ObjType  string            `json:"obj_type"`
+	Title    string            `json:"title"`
+	Path     string            `json:"path"`              // 相对输出目录，/ 分隔
+	EditTime string            `json:"obj_edit_time"`     // 导出时节点的 obj_edit_time
+	Hash     string            `json:"hash"`              // 本地文件内容摘要，用于发现本地被改动或损坏的文件
+	Assets   []string          `json:"assets,omitempty"`  // 该节点下载的图片等素材（--download-images），相对输出目录；移动/删除时逐个清理
+	Anchors  map[string]string `json:"anchors,omitempty"` // 标题块 → 锚点，供未变化文件的链接改写复用
+	Links    []string          `json:"links,omitempty"`   // 文件中已改写为本地路径的目标节点 token
+}
+
+// loadExportTreeState 读取状态文件，文件不存在时返回空状态
+func loadExportTreeState(p string) (*exportTreeState, error) {
+	st := &exportTreeState{Nodes: map[string]exportTreeStateNode{}}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("解析状态文件 %s 失败: %w", p, err)
+	}
+	if st.Nodes == nil {
+		st.Nodes = map[string]exportTreeStateNode{}
+	}
+	return st, nil
+}
+
+// bind 校验状态文件属于同一个根节点；空状态直接绑定
+func (st *exportTreeState) bind(rootToken string) error {
+	if len(st.Nodes) > 0 && st.RootNode != "" && st.RootNode != rootToken {
+		return fmt.Errorf("状态文件记录的根节点为 %s，与本次导出的 %s 不一致（请换一个输出目录或用 --state-file 指定其它状态文件）", st.RootNode, rootToken)
+	}
+	st.RootNode = rootToken
+	return nil
+}
+
+func (st *exportTreeState) save(p string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	return nil
+}
+
+// exportTreeRelPath 返回输出文件相对输出目录的 / 分隔路径
+func exportTreeRelPath(outputDir, outputPath string) string {
+	rel, err := filepath.Rel(outputDir, outputPath)
+	if err != nil {
+		return filepath.ToSlash(outputPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// planIncrementalExport 对比状态文件决定每个节点是否需要重新导出：
+// 返回与 jobs 等长的原因列表（空字符串表示未变化），以及状态中有记录但已不在本次导出范围内的节点。
+func planIncrementalExport(jobs []treeJob, st *exportTreeState, outputDir string, includeTypes []string) ([]string, []string) {
+	reasons := make([]string, len(jobs))
+	current := make(map[string]bool, len(jobs))
+	for i, job := range jobs {
+		if !isExportableWikiType(job.Node.ObjType, includeTypes) {
+			continue
+		}
+		current[job.Node.NodeToken] = true
+		prev, ok := st.Nodes[job.Node.NodeToken]
+		switch {
+		case !ok:
+			reasons[i] = "新节点"
+		case prev.EditTime == "" || prev.EditTime != job.Node.ObjEditTime:
+			reasons[i] = "内容已更新"
+		case prev.Path != exportTreeRelPath(outputDir, job.OutputPath):
+			reasons[i] = "标题或位置变化，原路径 " + prev.Path
+		default:
+			data, err := os.ReadFile(job.OutputPath)
+			if err != nil {
+				reasons[i] = "本地文件缺失"
+			} else if wikiTreeContentHash(string(data)) != prev.Hash {
+				reasons[i] = "本地文件被修改"
+			}
+		}
+	}
+
+	var removed []string
+	for token := range st.Nodes {
+		if !current[token] {
+			removed = append(removed, token)
+		}
+	}
+	sort.Strings(removed)
+	return reasons, removed
+}
+
+// staleLinkers 返回未变化、但链接到 targets 中任一节点的节点下标：这些文件里的相对路径或锚点已经失效，需要重新导出
+func staleLinkers(jobs []treeJob, reasons []string, st *exportTreeState, targets map[string]bool) []int {
+	if len(targets) == 0 {
+		return nil
+	}
+	var stale []int
+	for i, job := range jobs {
+		if reasons[i] != "" {
+			continue
+		}
+		prev, ok := st.Nodes[job.Node.NodeToken]
+		if !ok {
+			continue
+		}
+		for _, token := range prev.Links {
+			if targets[token] {
+				stale = append(stale, i)
+				break
+			}
+		}
+	}
+	return stale
+}
+
+// exportedAssetFiles 列出节点素材目录下直接存放的文件，返回相对输出目录的 / 分隔路径。
+// 子节点的素材目录嵌套在父节点的目录之下（assets/guide/child 在 assets/guide 内），只取文件、不递归
+func exportedAssetFiles(outputDir, assetsDir string) []string {
+	if assetsDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(assetsDir)
+	if err != nil {
+		return nil
+	}
+	base, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil
+	}
+	dir, err := filepath.Abs(assetsDir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if rel, err := filepath.Rel(base, filepath.Join(dir, e.Name())); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	return files
+}
+
+// removeExportedFile 删除一个已导出的文件及其记录的素材文件，并清理因此变空的上级目录（不超出输出目录）。
+// 素材只按文件逐个删除、所在目录变空才移除，嵌套在其中的子节点素材不受影响
+func removeExportedFile(outputDir, relPath string, assets []string) error {
+	if err := os.Remove(filepath.Join(outputDir, filepath.FromSlash(relPath))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, asset := range assets {
+		p := filepath.Join(outputDir, filepath.FromSlash(asset))
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		_ = os.Remove(filepath.Dir(p)) // 目录非空时失败，忽略
+	}
+	for dir := path.Dir(relPath); dir != "." && dir != "/" && !strings.HasPrefix(dir, ".."); dir = path.Dir(dir) {
+		if os.Remove(filepath.Join(outputDir, filepath.FromSlash(dir))) != nil {
+			break // 目录非空或无法删除时停止
+		}
+	}
+	return nil
+}
+
+// sameStringMap 比较两个锚点表是否相同
+func sameStringMap(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLinkTokens 合并已记录与本次新改写的链接目标（去重，保持顺序）
+func mergeLinkTokens(prev, added []string) []string {
+	seen := make(map[string]bool, len(prev)+len(added))
+	var merged []string
+	for _, token := range append(append([]string{}, prev...), added...) {
+		if !seen[token] {
+			seen[token] = true
+			merged = append(merged, token)
+		}
+	}
+	return merged
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -363,3 +364,172 @@ func TestMakeImagePathsDocumentRelative(t *testing.T) {
 		})
 	}
 }
+
+func TestRewriteExportedDocLinks(t *testing.T) {
+	out := filepath.Join("backup")
+	guide := treeJob{Node: &client.WikiNode{NodeToken: "wikGuide", ObjToken: "doxGuide"}, OutputPath: filepath.Join(out, "指南", "指南.md")}
+	install := treeJob{Node: &client.WikiNode{NodeToken: "wikInstall", ObjToken: "doxInstall"}, OutputPath: filepath.Join(out, "指南", "安装 说明.md")}
+	table := treeJob{Node: &client.WikiNode{NodeToken: "wikTable", ObjToken: "basTable", ObjType: "bitable"}}
+
+	idx := newWikiTreeExportIndex()
+	idx.addExported(guide, map[string]string{"doxcnHead": "快速开始"})
+	idx.addExported(install, nil)
+	idx.addMissing(table, "目标节点类型 bitable 未导出")
+
+	md := "见 [安装](https://acme.feishu.cn/wiki/wikInstall) 与 [开始](https://acme.feishu.cn/docx/doxGuide#share-doxcnHead)\n" +
+		"[本页](https://acme.feishu.cn/wiki/wikInstall?from=x) <mention-doc token=\"doxGuide\" type=\"docx\">指南 [v2]</mention-doc>\n" +
+		"`[代码](https://acme.feishu.cn/wiki/wikGuide)` [外部](https://acme.feishu.cn/wiki/wikOther) [表](https://acme.feishu.cn/base/basTable)\n" +
+		"```\n[块内](https://acme.feishu.cn/wiki/wikGuide)\n```\n" +
+		"[GitHub](https://github.com/x/wiki/wikGuide)\n"
+	got, tokens, dangling := rewriteExportedDocLinks(md, install.OutputPath, idx)
+	want := "见 [安装](安装%20说明.md) 与 [开始](指南.md#快速开始)\n" +
+		"[本页](安装%20说明.md) [指南 \\[v2\\]](指南.md)\n" +
+		"`[代码](https://acme.feishu.cn/wiki/wikGuide)` [外部](https://acme.feishu.cn/wiki/wikOther) [表](https://acme.feishu.cn/base/basTable)\n" +
+		"```\n[块内](https://acme.feishu.cn/wiki/wikGuide)\n```\n" +
+		"[GitHub](https://github.com/x/wiki/wikGuide)\n"
+	if got != want {
+		t.Errorf("rewriteExportedDocLinks =\n%s\nwant\n%s", got, want)
+	}
+	if want := []string{"wikInstall", "doxGuide", "wikInstall", "doxGuide"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("改写的目标 = %v, want %v", tokens, want)
+	}
+	if len(dangling) != 2 || dangling[0].Token != "wikOther" || dangling[0].Line != 3 ||
+		dangling[1].Reason != "目标节点类型 bitable 未导出" {
+		t.Errorf("悬空链接 = %+v", dangling)
+	}
+
+	// 从子目录外的文件链接进来时使用相对路径
+	root := filepath.Join(out, "首页.md")
+	got, _, _ = rewriteExportedDocLinks("[x](https://acme.feishu.cn/wiki/wikGuide#doxcnOther)", root, idx)
+	if got != "[x](指南/指南.md)" {
+		t.Errorf("跨目录链接 = %q", got)
+	}
+}
+
+func TestPlanIncrementalExport(t *testing.T) {
+	out := t.TempDir()
+	job := func(token, editTime, rel, objType string) treeJob {
+		return treeJob{
+			Node:       &client.WikiNode{NodeToken: token, ObjToken: "obj" + token, ObjType: objType, ObjEditTime: editTime},
+			OutputPath: filepath.Join(out, filepath.FromSlash(rel)),
+		}
+	}
+	write := func(rel, content string) string {
+		p := filepath.Join(out, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return wikiTreeContentHash(content)
+	}
+
+	st := &exportTreeState{Nodes: map[string]exportTreeStateNode{
+		"same":    {Path: "same.md", EditTime: "100", Hash: write("same.md", "a"), Links: []string{"moved"}},
+		"edited":  {Path: "edited.md", EditTime: "100", Hash: write("edited.md", "b")},
+		"moved":   {Path: "old/moved.md", EditTime: "100", Hash: write("old/moved.md", "c")},
+		"touched": {Path: "touched.md", EditTime: "100", Hash: "stale"},
+		"gone":    {Path: "gone.md", EditTime: "100"},
+		"sheet":   {Path: "sheet.md", EditTime: "100"},
+	}}
+	write("touched.md", "d")
+	jobs := []treeJob{
+		job("same", "100", "same.md", "docx"),
+		job("edited", "200", "edited.md", "docx"),
+		job("moved", "100", "new/moved.md", "docx"),
+		job("touched", "100", "touched.md", "docx"),
+		job("fresh", "100", "fresh.md", "docx"),
+		job("sheet", "100", "sheet.md", "sheet"),
+	}
+
+	reasons, removed := planIncrementalExport(jobs, st, out, []string{"docx"})
+	want := []string{"", "内容已更新", "标题或位置变化，原路径 old/moved.md", "本地文件被修改", "新节点", ""}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("reasons = %q, want %q", reasons, want)
+	}
+	if want := []string{"gone", "sheet"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if got := staleLinkers(jobs, reasons, st, map[string]bool{"moved": true}); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("staleLinkers = %v, want [0]", got)
+	}
+
+	if err := removeExportedFile(out, "old/moved.md", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "old")); !os.IsNotExist(err) {
+		t.Errorf("删除文件后应清理空目录: %v", err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("不应删除输出目录本身: %v", err)
+	}
+}
+
+func TestExportTreeStateBind(t *testing.T) {
+	p := filepath.Join(t.TempDir(), defaultExportTreeStateFile)
+	st, err := loadExportTreeState(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.bind("wikRoot"); err != nil {
+		t.Fatal(err)
+	}
+	st.Nodes["wikRoot"] = exportTreeStateNode{Path: "Root.md", EditTime: "1"}
+	if err := st.save(p); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadExportTreeState(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, st) {
+		t.Errorf("状态文件往返不一致: %+v vs %+v", loaded, st)
+	}
+	if err := loaded.bind("wikOther"); err == nil {
+		t.Error("根节点不一致时应报错")
+	}
+}
+
+func TestRemoveExportedFileKeepsNestedChildAssets(t *testing.T) {
+	out := t.TempDir()
+	write := func(rel string) {
+		p := filepath.Join(out, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, rel := range []string{"guide.md", "guide/child.md", "assets/guide/a.png", "assets/guide/child/b.png"} {
+		write(rel)
+	}
+
+	// 父节点的素材只记录自己目录下的文件，不包括嵌套的子节点目录
+	parentAssets := exportedAssetFiles(out, filepath.Join(out, "assets", "guide"))
+	if !reflect.DeepEqual(parentAssets, []string{"assets/guide/a.png"}) {
+		t.Fatalf("parent assets = %v", parentAssets)
+	}
+	if err := removeExportedFile(out, "guide.md", parentAssets); err != nil {
+		t.Fatal(err)
+	}
+	for rel, want := range map[string]bool{
+		"guide.md":                 false,
+		"assets/guide/a.png":       false,
+		"guide/child.md":           true,
+		"assets/guide/child/b.png": true,
+	} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(rel))); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, want)
+		}
+	}
+
+	// 子节点删除后素材目录变空，一并移除
+	if err := removeExportedFile(out, "guide/child.md", []string{"assets/guide/child/b.png"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "assets", "guide", "child")); !os.IsNotExist(err) {
+		t.Errorf("空素材目录应被移除: %v", err)
+	}
+}
@@ -37,8 +37,11 @@ func syncPrintf(format string, a ...any) {
 
 // segment 表示 Markdown 中的一个片段
 type segment struct {
-	kind    string // "markdown"、"mermaid"、"plantuml" 或 "svg"
-	content string
+	kind           string // "markdown"、"html"、"mermaid"、"plantuml" 或 "svg"
+	content        string
+	attachmentsDir string // 仅 html 片段：Confluence 附件所在目录
+	line           int    // 片段在源文件中的起始行号（图表片段为开始围栏所在行），供 doc lint 定位
+	unclosed       bool   // 仅图表片段：缺少结束围栏，其后内容都被当作图表源码
 }
 
 // parseMarkdownSegments 将 Markdown 解析为片段，分离出 mermaid、plantuml 和 svg 代码块
@@ -60,6 +63,7 @@ func parseMarkdownSegments(markdown string) []segment {
 	var segments []segment
 	lines := strings.Split(markdown, "\n")
 	var buf []string
+	bufLine := 1
 	i := 0
 
 	// 跟踪外层代码围栏状态，避免将嵌套代码围栏内的 ```mermaid 误识别
@@ -98,11 +102,12 @@ func parseMarkdownSegments(markdown string) []segment {
 		if diagramKind != "" {
 			// 先保存之前的普通内容
 			if len(buf) > 0 {
-				segments = append(segments, segment{kind: "markdown", content: strings.Join(buf, "\n")})
+				segments = append(segments, segment{kind: "markdown", content: strings.Join(buf, "\n"), line: bufLine})
 				buf = nil
 			}
 
 			// 收集图表代码块内容
+			fenceLine := i + 1
 			i++
 			var diagramLines []string
 			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
@@ -110,12 +115,14 @@ func parseMarkdownSegments(markdown string) []segment {
 				i++
 			}
 			// 跳过结束的 ```
-			if i < len(lines) {
+			unclosed := i >= len(lines)
+			if !unclosed {
 				i++
 			}
+			bufLine = i + 1
 
 			if len(diagramLines) > 0 {
-				segments = append(segments, segment{kind: diagramKind, content: strings.Join(diagramLines, "\n")})
+				segments = append(segments, segment{kind: diagramKind, content: strings.Join(diagramLines, "\n"), line: fenceLine, unclosed: unclosed})
 			}
 		} else {
 			// 检查是否进入非图表代码围栏（4+ 反引号，或 3 反引号 + 非图表语言）
@@ -134,7 +141,7 @@ func parseMarkdownSegments(markdown string) []segment {
 
 	// 保存剩余的普通内容
 	if len(buf) > 0 {
-		segments = append(segments, segment{kind: "markdown", content: strings.Join(buf, "\n")})
+		segments = append(segments, segment{kind: "markdown", content: strings.Join(buf, "\n"), line: bufLine})
 	}
 
 	return segments
@@ -249,12 +256,21 @@ type imageResult struct {
 	err     error
 }
 
-// videoTask 表示一个待上传的视频任务（底层使用 File Block）
+// videoTask 表示一个待上传的视频/附件任务（底层使用 File Block）
 type videoTask struct {
 	index       int
 	fileBlockID string
 	source      string
 	basePath    string
+	label       string // 消息前缀，空默认"视频"；HTML/Confluence 导入的非视频附件为"附件"
+}
+
+// kindLabel 返回进度/告警消息里的文件类别前缀。
+func (t videoTask) kindLabel() string {
+	if t.label != "" {
+		return t.label
+	}
+	return "视频"
 }
 
 // videoResult 表示视频上传结果
@@ -306,7 +322,7 @@ func (s *importStats) progressf(format string, a ...any) {
 
 var importMarkdownCmd = &cobra.Command{
 	Use:   "import <file.md>",
-	Short: "从 Markdown 导入创建/更新文档",
+	Short: "从 Markdown / HTML 导入创建/更新文档",
 	Long: `从 Markdown 文件导入内容，创建新的飞书文档或更新已有文档。
 
 特性:
@@ -314,11 +330,21 @@ var importMarkdownCmd = &cobra.Command{
   - Mermaid/PlantUML 图表自动转换为飞书画板 (重试+失败降级为代码块)
   - 表格并发填充，大表格自动拆分
   - 详细进度和耗时统计
+  - 支持导入 HTML 与 Confluence storage format（XHTML）：info/warning 等宏转高亮块，
+    code 宏转代码块，expand 宏转引用容器，附件上传为图片/文件块
+  - --incremental: 与已有文档逐块比对（类型 + 内容），只更新变化的段落，
+    保留未变块的 block_id、评论锚点和跨文档引用；图片/画板按类型与位置匹配，
+    其内容变化不会被检测，需要刷新时请去掉 --incremental 重新导入
+  - 预检（默认开启）: 写入前先按 doc lint 的规则离线检查 Markdown，打印会降级的写法后继续导入，
+    --preflight=false 可跳过；加 --strict 时发现 error 级问题（如本地图片不存在、
+    整段会被丢弃的 HTML）即中止，不创建也不修改文档
 
 示例:
   feishu-cli doc import doc.md --title "我的文档"
   feishu-cli doc import doc.md --document-id ABC123def456
+  feishu-cli doc import doc.md --document-id ABC123def456 --incremental --dry-run
   feishu-cli doc import doc.md --title "我的文档" --verbose
+  feishu-cli doc import page.xml --from confluence --attachments-dir ./attachments --title "迁移页面"
   feishu-cli doc import doc.md --title "测试" --diagram-workers 5 --table-workers 8`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -342,6 +368,22 @@ var importMarkdownCmd = &cobra.Command{
 			return err
 		}
 		output, _ := cmd.Flags().GetString("output")
+		incremental, _ := cmd.Flags().GetBool("incremental")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		from, _ := cmd.Flags().GetString("from")
+		attachmentsDir, _ := cmd.Flags().GetString("attachments-dir")
+		preflight, _ := cmd.Flags().GetBool("preflight")
+		strict, _ := cmd.Flags().GetBool("strict")
+		format, err := resolveImportFormat(from, filePath)
+		if err != nil {
+			return err
+		}
+		if incremental && documentID == "" {
+			return fmt.Errorf("--incremental 需要配合 --document-id 指定已有文档")
+		}
+		if dryRun && !incremental {
+			return fmt.Errorf("--dry-run 仅用于 --incremental 模式")
+		}
 		userAccessToken := resolveOptionalUserToken(cmd)
 		var progressOut io.Writer = os.Stdout
 		if output == "json" {
@@ -386,9 +428,34 @@ var importMarkdownCmd = &cobra.Command{
 
 		basePath := filepath.Dir(filePath)
 		markdownText := string(content)
+		if attachmentsDir == "" {
+			attachmentsDir = basePath
+		}
+		if attachmentsDir, err = filepath.Abs(attachmentsDir); err != nil {
+			return fmt.Errorf("解析附件目录失败: %w", err)
+		}
 
-		// 统计图表数量
-		mermaidCount, plantumlCount, svgCount := countDiagramBlocks(markdownText)
+		// 预检：写入任何内容之前离线检查会降级或丢失的写法（HTML 源文件走另一套转换，不做预检）
+		if (preflight || strict) && format == "markdown" {
+			issues, err := lintMarkdownImport(markdownText, basePath, uploadImages, colWidthMode, colWidthValues, userAccessToken)
+			if err != nil {
+				return err
+			}
+			if len(issues) > 0 {
+				fmt.Fprintln(progressOut, "=== 预检 ===")
+				printLintIssues(progressOut, filePath, issues)
+				fmt.Fprintln(progressOut)
+			}
+			if n := countLintErrors(issues); n > 0 && strict {
+				return fmt.Errorf("预检发现 %d 个 error 级问题，已中止导入（未写入飞书）；修正后重试，或去掉 --strict 照常导入", n)
+			}
+		}
+
+		// 统计图表数量（HTML 中没有 Mermaid/PlantUML 代码块语法）
+		var mermaidCount, plantumlCount, svgCount int
+		if format == "markdown" {
+			mermaidCount, plantumlCount, svgCount = countDiagramBlocks(markdownText)
+		}
 		diagramCount := mermaidCount + plantumlCount + svgCount
 		if verbose && diagramCount > 0 {
 			var parts []string
@@ -430,8 +497,13 @@ var importMarkdownCmd = &cobra.Command{
 			fmt.Fprintf(progressOut, "链接: https://feishu.cn/docx/%s\n\n", documentID)
 		}
 
-		// 解析 Markdown 为片段
-		segments := parseMarkdownSegments(markdownText)
+		// 解析 Markdown 为片段；HTML / Confluence 整篇作为一个片段转换
+		var segments []segment
+		if format == "html" {
+			segments = []segment{{kind: "html", content: markdownText, attachmentsDir: attachmentsDir}}
+		} else {
+			segments = parseMarkdownSegments(markdownText)
+		}
 
 		stats := &importStats{
 			diagramTotal:  diagramCount,
@@ -441,14 +513,42 @@ var importMarkdownCmd = &cobra.Command{
 			progress:      progressOut,
 		}
 
+		var dTasks []diagramTask
+		var tTasks []tableTask
+		var iTasks []imageTask
+		var vTasks []videoTask
+		var plan *syncPlan
+		if incremental {
+			items, err := buildSyncItems(segments, documentID, uploadImages, basePath, colWidthMode, colWidthValues, userAccessToken)
+			if err != nil {
+				return err
+			}
+			plan, err = buildSyncPlan(documentID, items, userAccessToken)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return printSyncPlan(progressOut, documentID, plan, output)
+			}
+		}
+
 		// === 阶段 1/3: 顺序创建文档块 ===
-		fmt.Fprintln(progressOut, "=== 阶段 1/3: 创建文档块 ===")
 		phase1Start := time.Now()
-
-		dTasks, tTasks, iTasks, vTasks, err := phase1CreateBlocks(documentID, segments, uploadImages, basePath, stats, verbose, userAccessToken, colWidthMode, colWidthValues)
+		if incremental {
+			fmt.Fprintf(progressOut, "=== 阶段 1/3: 增量同步文档块 (更新 %d, 插入 %d, 删除 %d, 移动 %d) ===\n",
+				plan.summary.Updated, plan.summary.Inserted, plan.summary.Deleted, plan.summary.Moved)
+			dTasks, tTasks, iTasks, vTasks, err = phase1SyncBlocks(documentID, plan, basePath, stats, verbose, userAccessToken)
+		} else {
+			fmt.Fprintln(progressOut, "=== 阶段 1/3: 创建文档块 ===")
+			dTasks, tTasks, iTasks, vTasks, err = phase1CreateBlocks(documentID, segments, uploadImages, basePath, stats, verbose, userAccessToken, colWidthMode, colWidthValues)
+		}
 		if err != nil {
 			return err
 		}
+		if incremental {
+			// 保留块中的画板不会重新导入，图表统计只计新插入的
+			stats.diagramTotal = len(dTasks) + stats.diagramFailed
+		}
 
 		stats.phase1Duration = time.Since(phase1Start)
 		stats.tableTotal = len(tTasks)
@@ -464,72 +564,20 @@ var importMarkdownCmd = &cobra.Command{
 		}
 		fmt.Fprintln(progressOut, phase1Summary+"\n")
 
-		// === 阶段 2/3: 并发处理 ===
-		if len(dTasks) > 0 || len(tTasks) > 0 || len(iTasks) > 0 || len(vTasks) > 0 {
-			// 阶段 1 大量 API 调用后等待配额恢复，避免阶段 2 立即触发频率限制
-			if stats.totalBlocks > 30 {
-				cooldown := 5 * time.Second
-				if verbose {
-					fmt.Fprintf(progressOut, "等待 API 配额恢复 (%.0fs)...\n", cooldown.Seconds())
-				}
-				time.Sleep(cooldown)
-			}
-			phase2Header := fmt.Sprintf("=== 阶段 2/3: 并发处理 (图表×%d, 表格×%d", diagramWorkers, tableWorkers)
-			if len(iTasks) > 0 && len(vTasks) > 0 {
-				phase2Header += fmt.Sprintf(", 图片+视频×%d", imageWorkers)
-			} else if len(iTasks) > 0 {
-				phase2Header += fmt.Sprintf(", 图片×%d", imageWorkers)
-			} else if len(vTasks) > 0 {
-				phase2Header += fmt.Sprintf(", 视频×%d", imageWorkers)
-			}
-			phase2Header += ") ==="
-			fmt.Fprintln(progressOut, phase2Header)
-			phase2Start := time.Now()
-
-			failedDiagrams := phase2ConcurrentProcess(documentID, dTasks, tTasks, iTasks, vTasks, diagramWorkers, tableWorkers, imageWorkers, diagramRetries, stats, verbose, userAccessToken)
-
-			stats.phase2Duration = time.Since(phase2Start)
-			imageUploadTotal := stats.imageTotal - stats.imageSkipped
-			videoUploadTotal := stats.videoTotal - stats.videoSkipped
-			var mediaInfo string
-			if imageUploadTotal > 0 {
-				mediaInfo = fmt.Sprintf(", 图片: %d/%d", stats.imageSuccess, imageUploadTotal)
-			}
-			if videoUploadTotal > 0 {
-				mediaInfo += fmt.Sprintf(", 视频: %d/%d", stats.videoSuccess, videoUploadTotal)
-			}
-			fmt.Fprintf(progressOut, "[阶段2] 完成 (%.1fs), 图表: %d/%d, 表格: %d/%d%s\n\n",
-				stats.phase2Duration.Seconds(),
-				stats.diagramSuccess, stats.diagramTotal,
-				stats.tableSuccess, stats.tableTotal,
-				mediaInfo)
-
-			// === 阶段 2.5: 表格单元格图片嵌入（issue #164）===
-			// 表格已填充完成（含追加行），此时单元格齐全，按最终单元格顺序为带图单元格建 Image 子块并上传。
-			embedTableCellImages(documentID, tTasks, basePath, imageWorkers, stats, verbose, userAccessToken)
-			if stats.cellImageTotal > 0 {
-				fmt.Fprintf(progressOut, "[阶段2.5] 单元格图片: %d/%d 成功\n\n", stats.cellImageSuccess, stats.cellImageTotal)
-			}
-
-			// === 阶段 3/3: 降级处理 ===
-			if len(failedDiagrams) > 0 {
-				fmt.Fprintf(progressOut, "=== 阶段 3/3: 降级处理 (%d 个) ===\n", len(failedDiagrams))
-				phase3Start := time.Now()
-
-				phase3HandleFallbacks(documentID, failedDiagrams, stats, verbose, userAccessToken)
-
-				stats.phase3Duration = time.Since(phase3Start)
-				fmt.Fprintf(progressOut, "[阶段3] 完成 (%.1fs), 降级成功: %d/%d\n\n",
-					stats.phase3Duration.Seconds(),
-					stats.fallbackSuccess, stats.fallbackSuccess+stats.fallbackFailed)
-			}
-		}
+		// === 阶段 2/3 + 3/3: 并发处理与降级 ===
+		runImportPhase2And3(documentID, dTasks, tTasks, iTasks, vTasks, basePath, importPipelineOptions{
+			diagramWorkers: diagramWorkers,
+			tableWorkers:   tableWorkers,
+			imageWorkers:   imageWorkers,
+			diagramRetries: diagramRetries,
+			verbose:        verbose,
+		}, stats, userAccessToken)
 
 		// === 输出结果 ===
 		totalDuration := stats.phase1Duration + stats.phase2Duration + stats.phase3Duration
 
 		if output == "json" {
-			if err := printJSON(map[string]any{
+			result := map[string]any{
 				"document_id":        documentID,
 				"blocks":             stats.totalBlocks,
 				"diagram_total":      stats.diagramTotal,
@@ -557,13 +605,21 @@ var importMarkdownCmd = &cobra.Command{
 				"phase1_seconds":     stats.phase1Duration.Seconds(),
 				"phase2_seconds":     stats.phase2Duration.Seconds(),
 				"phase3_seconds":     stats.phase3Duration.Seconds(),
-			}); err != nil {
+			}
+			if plan != nil {
+				result["sync"] = plan.summary
+			}
+			if err := printJSON(result); err != nil {
 				return err
 			}
 		} else {
 			fmt.Println("导入完成!")
 			fmt.Printf("  文档ID: %s\n", documentID)
 			fmt.Printf("  添加块数: %d\n", stats.totalBlocks)
+			if plan != nil {
+				fmt.Printf("  增量同步: 保留 %d, 更新 %d, 插入 %d, 删除 %d, 移动 %d\n",
+					plan.summary.Kept, plan.summary.Updated, plan.summary.Inserted, plan.summary.Deleted, plan.summary.Moved)
+			}
 			if stats.imageTotal > 0 {
 				if stats.imageSkipped == stats.imageTotal {
 					fmt.Printf("  图片: %d 张 (已创建占位块，feishu:// 引用需手动上传)\n", stats.imageSkipped)
@@ -620,6 +676,99 @@ var importMarkdownCmd = &cobra.Command{
 	},
 }
 
+// importTasks 汇总阶段 1 产生、交给阶段 2 并发处理的任务
+type importTasks struct {
+	diagrams []diagramTask
+	tables   []tableTask
+	images   []imageTask
+	videos   []videoTask
+}
+
+// importPipelineOptions 是导入流水线阶段 2/3 的并发与重试参数
+type importPipelineOptions struct {
+	diagramWorkers int
+	tableWorkers   int
+	imageWorkers   int
+	diagramRetries int
+	verbose        bool
+}
+
+// runImportPhase2And3 执行阶段 1 之后的并发处理（图表/表格/图片/视频）、单元格图片嵌入和图表降级，
+// 进度写入 stats.progress，统计累加到 stats
+func runImportPhase2And3(
+	documentID string,
+	dTasks []diagramTask,
+	tTasks []tableTask,
+	iTasks []imageTask,
+	vTasks []videoTask,
+	basePath string,
+	opts importPipelineOptions,
+	stats *importStats,
+	userAccessToken string,
+) {
+	if len(dTasks) == 0 && len(tTasks) == 0 && len(iTasks) == 0 && len(vTasks) == 0 {
+		return
+	}
+
+	// 阶段 1 大量 API 调用后等待配额恢复，避免阶段 2 立即触发频率限制
+	if stats.totalBlocks > 30 {
+		cooldown := 5 * time.Second
+		if opts.verbose {
+			stats.progressf("等待 API 配额恢复 (%.0fs)...\n", cooldown.Seconds())
+		}
+		time.Sleep(cooldown)
+	}
+	phase2Header := fmt.Sprintf("=== 阶段 2/3: 并发处理 (图表×%d, 表格×%d", opts.diagramWorkers, opts.tableWorkers)
+	if len(iTasks) > 0 && len(vTasks) > 0 {
+		phase2Header += fmt.Sprintf(", 图片+视频×%d", opts.imageWorkers)
+	} else if len(iTasks) > 0 {
+		phase2Header += fmt.Sprintf(", 图片×%d", opts.imageWorkers)
+	} else if len(vTasks) > 0 {
+		phase2Header += fmt.Sprintf(", 视频×%d", opts.imageWorkers)
+	}
+	phase2Header += ") ==="
+	stats.progressf("%s\n", phase2Header)
+	phase2Start := time.Now()
+
+	failedDiagrams := phase2ConcurrentProcess(documentID, dTasks, tTasks, iTasks, vTasks, opts.diagramWorkers, opts.tableWorkers, opts.imageWorkers, opts.diagramRetries, stats, opts.verbose, userAccessToken)
+
+	stats.phase2Duration = time.Since(phase2Start)
+	imageUploadTotal := stats.imageTotal - stats.imageSkipped
+	videoUploadTotal := stats.videoTotal - stats.videoSkipped
+	var mediaInfo string
+	if imageUploadTotal > 0 {
+		mediaInfo = fmt.Sprintf(", 图片: %d/%d", stats.imageSuccess, imageUploadTotal)
+	}
+	if videoUploadTotal > 0 {
+		mediaInfo += fmt.Sprintf(", 视频: %d/%d", stats.videoSuccess, videoUploadTotal)
+	}
+	stats.progressf("[阶段2] 完成 (%.1fs), 图表: %d/%d, 表格: %d/%d%s\n\n",
+		stats.phase2Duration.Seconds(),
+		stats.diagramSuccess, stats.diagramTotal,
+		stats.tableSuccess, stats.tableTotal,
+		mediaInfo)
+
+	// === 阶段 2.5: 表格单元格图片嵌入（issue #164）===
+	// 表格已填充完成（含追加行），此时单元格齐全，按最终单元格顺序为带图单元格建 Image 子块并上传。
+	embedTableCellImages(documentID, tTasks, basePath, opts.imageWorkers, stats, opts.verbose, userAccessToken)
+	if stats.cellImageTotal > 0 {
+		stats.progressf("[阶段2.5] 单元格图片: %d/%d 成功\n\n", stats.cellImageSuccess, stats.cellImageTotal)
+	}
+
+	// === 阶段 3/3: 降级处理 ===
+	if len(failedDiagrams) > 0 {
+		stats.progressf("=== 阶段 3/3: 降级处理 (%d 个) ===\n", len(failedDiagrams))
+		phase3Start := time.Now()
+
+		phase3HandleFallbacks(documentID, failedDiagrams, stats, opts.verbose, userAccessToken)
+
+		stats.phase3Duration = time.Since(phase3Start)
+		stats.progressf("[阶段3] 完成 (%.1fs), 降级成功: %d/%d\n\n",
+			stats.phase3Duration.Seconds(),
+			stats.fallbackSuccess, stats.fallbackSuccess+stats.fallbackFailed)
+	}
+}
+
 // phase1CreateBlocks 顺序创建所有文档块，收集待处理的图表、表格和图片任务
 func phase1CreateBlocks(
 	documentID string,
@@ -632,27 +781,16 @@ func phase1CreateBlocks(
 	colWidthMode string,
 	colWidthValues []int,
 ) ([]diagramTask, []tableTask, []imageTask, []videoTask, error) {
-	var dTasks []diagramTask
-	var tTasks []tableTask
-	var iTasks []imageTask
-	var vTasks []videoTask
+	tasks := &importTasks{}
 	diagramIdx := 0
 
 	for segIdx, seg := range segments {
-		if seg.kind == "markdown" {
+		if seg.kind == "markdown" || seg.kind == "html" {
 			if strings.TrimSpace(seg.content) == "" {
 				continue
 			}
 
-			options := converter.ConvertOptions{
-				UploadImages:     uploadImages,
-				EmbedTableImages: true, // 表格单元格图片真嵌入（issue #164），由阶段 2.5 落库
-				DocumentID:       documentID,
-			}
-			applyColumnWidthOptions(&options, colWidthMode, colWidthValues)
-
-			conv := converter.NewMarkdownToBlock([]byte(seg.content), options, basePath)
-			result, err := conv.ConvertWithTableData()
+			result, err := convertImportSegment(seg, documentID, uploadImages, basePath, colWidthMode, colWidthValues, userAccessToken)
 			if err != nil {
 				return nil, nil, nil, nil, fmt.Errorf("转换 Markdown 失败 (段落 %d): %w", segIdx+1, err)
 			}
@@ -661,159 +799,232 @@ func phase1CreateBlocks(
 			stats.imageSkipped += result.ImageStats.Skipped
 			stats.videoSkipped += result.VideoStats.Skipped
 
-			if len(result.BlockNodes) == 0 {
-				continue
+			if _, err := createConvertedBlocks(documentID, result, -1, fmt.Sprintf("段落 %d", segIdx+1), basePath, tasks, stats, verbose, userAccessToken); err != nil {
+				return nil, nil, nil, nil, err
 			}
+		} else if seg.kind == "mermaid" || seg.kind == "plantuml" || seg.kind == "svg" {
+			diagramIdx++
+			createDiagramBoard(documentID, seg, diagramIdx, -1, tasks, stats, verbose, userAccessToken)
+		}
+	}
 
-			// 提取顶层块，嵌套子块稍后按 BlockNode 树顺序创建
-			var topLevelBlocks []*larkdocx.Block
-
-			for _, node := range result.BlockNodes {
-				topLevelBlocks = append(topLevelBlocks, node.Block)
-			}
+	return tasks.diagrams, tasks.tables, tasks.images, tasks.videos, nil
+}
 
-			// 记录表格块的索引
-			var tableIndices []int
-			for i, block := range topLevelBlocks {
-				if block.BlockType != nil {
-					switch *block.BlockType {
-					case int(converter.BlockTypeTable):
-						tableIndices = append(tableIndices, i)
-					}
-				}
-			}
+// convertImportSegment 按 doc import 的选项把一个 Markdown / HTML 片段转换为块树
+func convertImportSegment(seg segment, documentID string, uploadImages bool, basePath, colWidthMode string, colWidthValues []int, userAccessToken string) (*converter.ConvertResult, error) {
+	options := converter.ConvertOptions{
+		UploadImages:     uploadImages,
+		EmbedTableImages: true, // 表格单元格图片真嵌入（issue #164），由阶段 2.5 落库
+		DocumentID:       documentID,
+		UserAccessToken:  userAccessToken, // 决定 <!-- feishu-okr --> 能否重建为 OKR 块
+	}
+	applyColumnWidthOptions(&options, colWidthMode, colWidthValues)
 
-			// 批量添加顶层块（飞书 API 限制每次最多 50 个块）
-			const batchSize = 50
-			var createdBlockIDs []string
-			for i := 0; i < len(topLevelBlocks); i += batchSize {
-				end := i + batchSize
-				if end > len(topLevelBlocks) {
-					end = len(topLevelBlocks)
-				}
-				batch := topLevelBlocks[i:end]
-
-				createResult := client.DoWithRetry(func() ([]*larkdocx.Block, http.Header, error) {
-					return client.CreateBlock(documentID, documentID, batch, -1, userAccessToken)
-				}, client.RetryConfig{
-					MaxRetries:       5,
-					RetryOnRateLimit: true,
-				})
-				if createResult.Err != nil {
-					return nil, nil, nil, nil, fmt.Errorf("添加内容失败 (段落 %d): %w", segIdx+1, createResult.Err)
-				}
-				stats.totalBlocks += len(createResult.Value)
+	if seg.kind == "html" {
+		return converter.NewHTMLToBlock([]byte(seg.content), options, basePath, seg.attachmentsDir).ConvertWithTableData()
+	}
+	conv := converter.NewMarkdownToBlock([]byte(seg.content), options, basePath)
+	return conv.ConvertWithTableData()
+}
 
-				for _, block := range createResult.Value {
-					if block.BlockId != nil {
-						createdBlockIDs = append(createdBlockIDs, *block.BlockId)
-					}
-				}
-			}
+// resolveImportFormat 根据 --from 或文件扩展名确定导入格式："markdown" 或 "html"（含 Confluence storage format）
+func resolveImportFormat(from, filePath string) (string, error) {
+	switch strings.ToLower(from) {
+	case "":
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".html", ".htm", ".xhtml", ".xml":
+			return "html", nil
+		}
+		return "markdown", nil
+	case "markdown", "md":
+		return "markdown", nil
+	case "html", "confluence":
+		return "html", nil
+	}
+	return "", fmt.Errorf("--from 仅支持 markdown、html、confluence，当前值: %s", from)
+}
 
-			nestedCreatedByTop := map[int][]createdBlockNode{}
+// createConvertedBlocks 在文档根块的 index 处（-1 表示追加到末尾）创建一段转换结果的全部块，
+// 表格/图片/视频的后续处理追加到 tasks。返回创建的顶层块数，供调用方推进插入位置。
+func createConvertedBlocks(
+	documentID string,
+	result *converter.ConvertResult,
+	index int,
+	label string,
+	basePath string,
+	tasks *importTasks,
+	stats *importStats,
+	verbose bool,
+	userAccessToken string,
+) (int, error) {
+	if len(result.BlockNodes) == 0 {
+		return 0, nil
+	}
 
-			// 递归创建嵌套子块（如嵌套列表）
-			for idx, node := range result.BlockNodes {
-				if idx >= len(createdBlockIDs) || len(node.Children) == 0 {
-					continue
-				}
-				parentID := createdBlockIDs[idx]
+	// 提取顶层块，嵌套子块稍后按 BlockNode 树顺序创建
+	var topLevelBlocks []*larkdocx.Block
 
-				nestedCount, nestedCreated, nestedErr := createNestedChildren(documentID, parentID, node.Children, userAccessToken)
-				if nestedErr != nil {
-					if verbose {
-						syncPrintf("  ⚠ 段落 %d 嵌套子块创建失败: %v\n", segIdx+1, nestedErr)
-					}
-				}
-				stats.totalBlocks += nestedCount
-				nestedCreatedByTop[idx] = nestedCreated
-			}
+	for _, node := range result.BlockNodes {
+		topLevelBlocks = append(topLevelBlocks, node.Block)
+	}
 
-			// QuoteContainer / Callout：遍历所有顶层节点清理飞书 API 异步生成的空子块。
-			// 在所有 createNestedChildren 完成后执行，覆盖有子块和无子块的容器节点。
-			for i, node := range result.BlockNodes {
-				if i >= len(createdBlockIDs) {
-					break
-				}
-				if node.Block.BlockType != nil {
-					deleteContainerAutoEmptyBlock(documentID, createdBlockIDs[i], *node.Block.BlockType, userAccessToken)
-				}
+	// 记录表格块的索引
+	var tableIndices []int
+	for i, block := range topLevelBlocks {
+		if block.BlockType != nil {
+			switch *block.BlockType {
+			case int(converter.BlockTypeTable):
+				tableIndices = append(tableIndices, i)
 			}
+		}
+	}
 
-			if verbose {
-				stats.progressf("  [段落 %d] 创建 %d 个块, %d 个表格\n", segIdx+1, len(createdBlockIDs), len(tableIndices))
-			}
+	// 批量添加顶层块（飞书 API 限制每次最多 50 个块）
+	const batchSize = 50
+	var createdBlockIDs []string
+	for i := 0; i < len(topLevelBlocks); i += batchSize {
+		end := i + batchSize
+		if end > len(topLevelBlocks) {
+			end = len(topLevelBlocks)
+		}
+		batch := topLevelBlocks[i:end]
+		batchIndex := -1
+		if index >= 0 {
+			batchIndex = index + len(createdBlockIDs)
+		}
 
-			// 收集表格任务（不立即填充）
-			tableDataIdx := 0
-			for _, tableIdx := range tableIndices {
-				if tableIdx >= len(createdBlockIDs) || tableDataIdx >= len(result.TableDatas) {
-					continue
-				}
+		createResult := client.DoWithRetry(func() ([]*larkdocx.Block, http.Header, error) {
+			return client.CreateBlock(documentID, documentID, batch, batchIndex, userAccessToken)
+		}, client.RetryConfig{
+			MaxRetries:       5,
+			RetryOnRateLimit: true,
+		})
+		if createResult.Err != nil {
+			return len(createdBlockIDs), fmt.Errorf("添加内容失败 (%s): %w", label, createResult.Err)
+		}
+		stats.totalBlocks += len(createResult.Value)
 
-				tTasks = append(tTasks, tableTask{
-					index:        len(tTasks) + 1,
-					tableBlockID: createdBlockIDs[tableIdx],
-					tableData:    result.TableDatas[tableDataIdx],
-				})
-				tableDataIdx++
+		for _, block := range createResult.Value {
+			if block.BlockId != nil {
+				createdBlockIDs = append(createdBlockIDs, *block.BlockId)
 			}
+		}
+	}
 
-			iTasks = appendImageTasks(iTasks, result.BlockNodes, createdBlockIDs, nestedCreatedByTop, result.ImageSources, basePath)
-			vTasks = appendVideoTasks(vTasks, result.BlockNodes, createdBlockIDs, nestedCreatedByTop, result.VideoSources, basePath)
+	nestedCreatedByTop := map[int][]createdBlockNode{}
 
-		} else if seg.kind == "mermaid" || seg.kind == "plantuml" || seg.kind == "svg" {
-			diagramIdx++
-			syntaxLabel := diagramSyntaxLabel(seg.kind)
+	// 递归创建嵌套子块（如嵌套列表）
+	for idx, node := range result.BlockNodes {
+		if idx >= len(createdBlockIDs) || len(node.Children) == 0 {
+			continue
+		}
+		parentID := createdBlockIDs[idx]
 
+		nestedCount, nestedCreated, nestedErr := createNestedChildren(documentID, parentID, node.Children, userAccessToken)
+		if nestedErr != nil {
 			if verbose {
-				stats.progressf("  [%s %d] 创建画板占位块...\n", syntaxLabel, diagramIdx)
+				syncPrintf("  ⚠ %s 嵌套子块创建失败: %v\n", label, nestedErr)
 			}
+		}
+		stats.totalBlocks += nestedCount
+		nestedCreatedByTop[idx] = nestedCreated
+	}
 
-			// 只创建画板占位块，不导入图表
-			createResult := client.DoWithRetry(func() (*client.AddBoardResult, http.Header, error) {
-				return client.AddBoard(documentID, "", -1, userAccessToken)
-			}, client.RetryConfig{
-				MaxRetries:       5,
-				RetryOnRateLimit: true,
-				OnRetry: func(attempt int, err error, wait time.Duration) {
-					if verbose {
-						stats.progressf("  ⚠ %s %d 创建画板重试 %d/5 (等待 %.1fs): %v\n",
-							syntaxLabel, diagramIdx, attempt, wait.Seconds(), err)
-					}
-				},
-			})
-			if createResult.Err != nil {
-				stats.progressf("  ✗ %s %d 创建画板失败: %v\n", syntaxLabel, diagramIdx, createResult.Err)
-				stats.diagramFailed++
-				continue
-			}
-			boardResult := createResult.Value
+	// QuoteContainer / Callout：遍历所有顶层节点清理飞书 API 异步生成的空子块。
+	// 在所有 createNestedChildren 完成后执行，覆盖有子块和无子块的容器节点。
+	for i, node := range result.BlockNodes {
+		if i >= len(createdBlockIDs) {
+			break
+		}
+		if node.Block.BlockType != nil {
+			deleteContainerAutoEmptyBlock(documentID, createdBlockIDs[i], *node.Block.BlockType, userAccessToken)
+		}
+	}
 
-			if boardResult.WhiteboardID == "" {
-				stats.progressf("  ✗ %s %d 未返回画板 ID\n", syntaxLabel, diagramIdx)
-				stats.diagramFailed++
-				continue
-			}
+	if verbose {
+		stats.progressf("  [%s] 创建 %d 个块, %d 个表格\n", label, len(createdBlockIDs), len(tableIndices))
+	}
+
+	// 收集表格任务（不立即填充）
+	tableDataIdx := 0
+	for _, tableIdx := range tableIndices {
+		if tableIdx >= len(createdBlockIDs) || tableDataIdx >= len(result.TableDatas) {
+			continue
+		}
+
+		tasks.tables = append(tasks.tables, tableTask{
+			index:        len(tasks.tables) + 1,
+			tableBlockID: createdBlockIDs[tableIdx],
+			tableData:    result.TableDatas[tableDataIdx],
+		})
+		tableDataIdx++
+	}
+
+	tasks.images = appendImageTasks(tasks.images, result.BlockNodes, createdBlockIDs, nestedCreatedByTop, result.ImageSources, basePath)
+	tasks.videos = appendVideoTasks(tasks.videos, result.BlockNodes, createdBlockIDs, nestedCreatedByTop, result.VideoSources, basePath)
 
-			stats.totalBlocks++
+	return len(createdBlockIDs), nil
+}
+
+// createDiagramBoard 在文档根块的 index 处（-1 表示追加到末尾）创建画板占位块，
+// 图表导入任务追加到 tasks。返回是否创建成功。
+func createDiagramBoard(
+	documentID string,
+	seg segment,
+	diagramIdx int,
+	index int,
+	tasks *importTasks,
+	stats *importStats,
+	verbose bool,
+	userAccessToken string,
+) bool {
+	syntaxLabel := diagramSyntaxLabel(seg.kind)
 
-			dTasks = append(dTasks, diagramTask{
-				index:        diagramIdx,
-				content:      seg.content,
-				syntax:       seg.kind,
-				boardBlockID: boardResult.BlockID,
-				whiteboardID: boardResult.WhiteboardID,
-			})
+	if verbose {
+		stats.progressf("  [%s %d] 创建画板占位块...\n", syntaxLabel, diagramIdx)
+	}
 
+	// 只创建画板占位块，不导入图表
+	createResult := client.DoWithRetry(func() (*client.AddBoardResult, http.Header, error) {
+		return client.AddBoard(documentID, "", index, userAccessToken)
+	}, client.RetryConfig{
+		MaxRetries:       5,
+		RetryOnRateLimit: true,
+		OnRetry: func(attempt int, err error, wait time.Duration) {
 			if verbose {
-				stats.progressf("  [%s %d] 画板已创建: %s\n", syntaxLabel, diagramIdx, boardResult.WhiteboardID)
+				stats.progressf("  ⚠ %s %d 创建画板重试 %d/5 (等待 %.1fs): %v\n",
+					syntaxLabel, diagramIdx, attempt, wait.Seconds(), err)
 			}
-		}
+		},
+	})
+	if createResult.Err != nil {
+		stats.progressf("  ✗ %s %d 创建画板失败: %v\n", syntaxLabel, diagramIdx, createResult.Err)
+		stats.diagramFailed++
+		return false
 	}
+	boardResult := createResult.Value
 
-	return dTasks, tTasks, iTasks, vTasks, nil
+	if boardResult.WhiteboardID == "" {
+		stats.progressf("  ✗ %s %d 未返回画板 ID\n", syntaxLabel, diagramIdx)
+		stats.diagramFailed++
+		return boardResult.BlockID != ""
+	}
+
+	stats.totalBlocks++
+
+	tasks.diagrams = append(tasks.diagrams, diagramTask{
+		index:        diagramIdx,
+		content:      seg.content,
+		syntax:       seg.kind,
+		boardBlockID: boardResult.BlockID,
+		whiteboardID: boardResult.WhiteboardID,
+	})
+
+	if verbose {
+		stats.progressf("  [%s %d] 画板已创建: %s\n", syntaxLabel, diagramIdx, boardResult.WhiteboardID)
+	}
+	return true
 }
 
 // phase2ConcurrentProcess 并发处理图表导入、表格填充和图片上传
@@ -1333,7 +1544,7 @@ func replaceFailedCellImageBlock(documentID, cellID, imageBlockID, source string
 	}
 }
 
-// processVideoTask 处理单个视频上传任务（File Block）
+// processVideoTask 处理单个视频/附件上传任务（File Block）
 func processVideoTask(documentID string, task videoTask, verbose bool, userAccessToken string) videoResult {
 	const maxRetries = 5
 
@@ -1349,21 +1560,21 @@ func processVideoTask(documentID string, task videoTask, verbose bool, userAcces
 
 	localPath, fileName, cleanup, err := resolveMediaSource(task.source, task.basePath, ".mp4")
 	if err != nil {
-		syncPrintf("  ✗ 视频 %d 解析失败 (%s): %v\n", task.index, task.source, err)
+		syncPrintf("  ✗ %s %d 解析失败 (%s): %v\n", task.kindLabel(), task.index, task.source, err)
 		return failWith(fmt.Sprintf("解析失败: %v", err), err)
 	}
 	defer cleanup()
 
 	fi, err := os.Stat(localPath)
 	if err != nil {
-		syncPrintf("  ✗ 视频 %d 文件信息获取失败: %v\n", task.index, err)
+		syncPrintf("  ✗ %s %d 文件信息获取失败: %v\n", task.kindLabel(), task.index, err)
 		return failWith(fmt.Sprintf("文件信息获取失败: %v", err), err)
 	}
 	if fi.Size() > maxInlineVideoSize {
 		sizeMB := float64(fi.Size()) / (1024 * 1024)
-		err := fmt.Errorf("视频超过 %.1f MB 限制 (当前 %.1f MB)，当前上传通道暂不支持大文件分块上传",
-			float64(maxInlineVideoSize)/(1024*1024), sizeMB)
-		syncPrintf("  ✗ 视频 %d: %v\n", task.index, err)
+		err := fmt.Errorf("%s超过 %.1f MB 限制 (当前 %.1f MB)，当前上传通道暂不支持大文件分块上传",
+			task.kindLabel(), float64(maxInlineVideoSize)/(1024*1024), sizeMB)
+		syncPrintf("  ✗ %s %d: %v\n", task.kindLabel(), task.index, err)
 		return failWith(fmt.Sprintf("超过 %.1f MB 限制", float64(maxInlineVideoSize)/(1024*1024)), err)
 	}
 
@@ -1374,8 +1585,8 @@ func processVideoTask(documentID string, task videoTask, verbose bool, userAcces
 		RetryOnRateLimit: true,
 		OnRetry: func(attempt int, err error, wait time.Duration) {
 			if verbose {
-				syncPrintf("  ⚠ 视频 %d 上传重试 %d/%d (等待 %.1fs): %v\n",
-					task.index, attempt, maxRetries, wait.Seconds(), err)
+				syncPrintf("  ⚠ %s %d 上传重试 %d/%d (等待 %.1fs): %v\n",
+					task.kindLabel(), task.index, attempt, maxRetries, wait.Seconds(), err)
 			}
 		},
 	}
@@ -1384,7 +1595,7 @@ func processVideoTask(documentID string, task videoTask, verbose bool, userAcces
 		return client.UploadMediaWithExtra(localPath, "docx_file", task.fileBlockID, fileName, extra, userAccessToken)
 	}, retryCfg)
 	if uploadResult.Err != nil {
-		syncPrintf("  ✗ 视频 %d 上传失败 (%s): %v\n", task.index, task.source, uploadResult.Err)
+		syncPrintf("  ✗ %s %d 上传失败 (%s): %v\n", task.kindLabel(), task.index, task.source, uploadResult.Err)
 		return failWith(fmt.Sprintf("上传失败: %v", uploadResult.Err), uploadResult.Err)
 	}
 
@@ -1395,12 +1606,12 @@ func processVideoTask(documentID string, task videoTask, verbose bool, userAcces
 		}, userAccessToken)
 	}, retryCfg)
 	if replaceResult.Err != nil {
-		syncPrintf("  ✗ 视频 %d 绑定失败 (token=%s): %v\n", task.index, fileToken, replaceResult.Err)
+		syncPrintf("  ✗ %s %d 绑定失败 (token=%s): %v\n", task.kindLabel(), task.index, fileToken, replaceResult.Err)
 		return failWith(fmt.Sprintf("绑定失败: %v", replaceResult.Err), replaceResult.Err)
 	}
 
 	if verbose {
-		syncPrintf("  ✓ 视频 %d 成功 (%s)\n", task.index, task.source)
+		syncPrintf("  ✓ %s %d 成功 (%s)\n", task.kindLabel(), task.index, task.source)
 	}
 	return videoResult{task: task, success: true}
 }
@@ -1410,12 +1621,12 @@ func processVideoTask(documentID string, task videoTask, verbose bool, userAcces
 // 由于飞书 PatchBlock 不支持跨类型变更，这里采用"删除 + 同位置插入 Text"的策略，
 // 模式与 phase3HandleFallbacks 一致。占位失败仅记日志，不让整体导入崩溃。
 func replaceFailedVideoBlock(documentID string, task videoTask, fileName, reason, userAccessToken string) {
-	placeholder := fmt.Sprintf("[视频上传失败：%s (%s)]", fileName, reason)
+	placeholder := fmt.Sprintf("[%s上传失败：%s (%s)]", task.kindLabel(), fileName, reason)
 
 	// 1. 在文档顶层子块中找到该 File 块的索引
 	children, err := client.GetAllBlockChildren(documentID, documentID, userAccessToken)
 	if err != nil {
-		syncPrintf("  ⚠ 视频 %d 占位块创建失败（无法获取子块列表）: %v\n", task.index, err)
+		syncPrintf("  ⚠ %s %d 占位块创建失败（无法获取子块列表）: %v\n", task.kindLabel(), task.index, err)
 		return
 	}
 	idx := -1
@@ -1426,13 +1637,13 @@ func replaceFailedVideoBlock(documentID string, task videoTask, fileName, reason
 		}
 	}
 	if idx < 0 {
-		syncPrintf("  ⚠ 视频 %d 占位块创建跳过（File 块未在顶层找到，可能位于嵌套容器内）\n", task.index)
+		syncPrintf("  ⚠ %s %d 占位块创建跳过（File 块未在顶层找到，可能位于嵌套容器内）\n", task.kindLabel(), task.index)
 		return
 	}
 
 	// 2. 删除空 File 块
 	if _, err := client.DeleteBlocks(documentID, documentID, idx, idx+1, userAccessToken); err != nil {
-		syncPrintf("  ⚠ 视频 %d 占位块创建失败（删除原 File 块失败）: %v\n", task.index, err)
+		syncPrintf("  ⚠ %s %d 占位块创建失败（删除原 File 块失败）: %v\n", task.kindLabel(), task.index, err)
 		return
 	}
 
@@ -1447,7 +1658,7 @@ func replaceFailedVideoBlock(documentID string, task videoTask, fileName, reason
 		},
 	}
 	if _, _, err := client.CreateBlock(documentID, documentID, []*larkdocx.Block{textBlock}, idx, userAccessToken); err != nil {
-		syncPrintf("  ⚠ 视频 %d 占位块创建失败（插入 Text 块失败）: %v\n", task.index, err)
+		syncPrintf("  ⚠ %s %d 占位块创建失败（插入 Text 块失败）: %v\n", task.kindLabel(), task.index, err)
 		return
 	}
 }
@@ -1649,15 +1860,19 @@ func appendVideoTasks(
 	sourceIdx := 0
 
 	appendIfVideo := func(node *converter.BlockNode, blockID string) {
-		if sourceIdx >= len(videoSources) || !isVideoBlockNode(node) {
+		if sourceIdx >= len(videoSources) || !isUploadFileBlockNode(node) {
 			return
 		}
-		tasks = append(tasks, videoTask{
+		task := videoTask{
 			index:       len(tasks) + 1,
 			fileBlockID: blockID,
 			source:      videoSources[sourceIdx],
 			basePath:    basePath,
-		})
+		}
+		if !converter.IsVideoFilename(*node.Block.File.Name) {
+			task.label = "附件"
+		}
+		tasks = append(tasks, task)
 		sourceIdx++
 	}
 
@@ -1674,14 +1889,16 @@ func appendVideoTasks(
 	return tasks
 }
 
-func isVideoBlockNode(node *converter.BlockNode) bool {
+// isUploadFileBlockNode 判断节点是否为待上传的 File 块（视频或附件）：有文件名但还没有 token
+func isUploadFileBlockNode(node *converter.BlockNode) bool {
 	if node == nil || node.Block == nil || node.Block.BlockType == nil {
 		return false
 	}
 	if *node.Block.BlockType != int(converter.BlockTypeFile) || node.Block.File == nil || node.Block.File.Name == nil {
 		return false
 	}
-	return converter.IsVideoFilename(*node.Block.File.Name)
+	file := node.Block.File
+	return *file.Name != "" && (file.Token == nil || *file.Token == "")
 }
 
 // phase3HandleFallbacks 处理失败的图表，降级为代码块
@@ -1799,6 +2016,12 @@ func init() {
 	importMarkdownCmd.Flags().String("user-access-token", "", "User Access Token（可选，使用用户身份访问文档）")
 	importMarkdownCmd.Flags().String("table-column-width", "auto",
 		"Markdown 表格列宽策略：auto（按内容启发式）| fixed（按文档宽度均分）| 像素列表如 80,200,*,120（* 表示该列走 auto）")
+	importMarkdownCmd.Flags().Bool("incremental", false, "增量同步：与 --document-id 现有内容逐块比对，只应用插入/更新/删除，未变化的块保留原 block_id 与评论")
+	importMarkdownCmd.Flags().String("from", "", "源文件格式: markdown | html | confluence（默认按扩展名判断，.html/.htm/.xhtml/.xml 视为 HTML）")
+	importMarkdownCmd.Flags().String("attachments-dir", "", "Confluence 附件目录，ri:attachment 引用的文件名相对该目录解析（默认源文件所在目录）")
+	importMarkdownCmd.Flags().Bool("dry-run", false, "仅打印增量同步的编辑脚本，不写入文档（需 --incremental）")
+	importMarkdownCmd.Flags().Bool("preflight", true, "导入前离线预检 Markdown（同 doc lint），只打印问题不中止")
+	importMarkdownCmd.Flags().Bool("strict", false, "预检发现 error 级问题时中止导入（隐含 --preflight）")
 	// 向后兼容别名
 	importMarkdownCmd.Flags().Int("mermaid-workers", 5, "图表并发导入数 (--diagram-workers 别名)")
 	importMarkdownCmd.Flags().Int("mermaid-retries", 10, "图表最大重试次数 (--diagram-retries 别名)")
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/converter"
+)
+
+// syncItem 是增量同步中「目标文档」侧的一个顶层块：Markdown 转换出的块节点，或一个图表画板
+type syncItem struct {
+	node         *converter.BlockNode
+	tableData    *converter.TableData
+	imageSources []string
+	videoSources []string
+	diagram      *segment
+	diagramIdx   int
+	entry        converter.BlockDiffEntry
+}
+
+// syncHunk 是相邻两个保留块之间的一段改动：删除旧块 [oldStart, oldEnd)，再在 oldStart 处插入 items
+type syncHunk struct {
+	oldStart int
+	oldEnd   int
+	items    []int // syncItem 下标
+}
+
+// syncPlan 是增量同步的完整计划
+type syncPlan struct {
+	oldIDs  []string
+	items   []syncItem
+	edits   []converter.BlockEdit
+	summary converter.BlockDiffSummary
+}
+
+// buildSyncItems 把 Markdown / HTML 片段转换为顶层 syncItem 列表，并按 phase1 的对齐规则
+// 把表格数据、图片/视频来源分配到各自所属的顶层节点
+func buildSyncItems(segments []segment, documentID string, uploadImages bool, basePath, colWidthMode string, colWidthValues []int, userAccessToken string) ([]syncItem, error) {
+	var items []syncItem
+	diagramIdx := 0
+	for segIdx, seg := range segments {
+		if seg.kind == "mermaid" || seg.kind == "plantuml" || seg.kind == "svg" {
+			diagramIdx++
+			s := seg
+			items = append(items, syncItem{
+				diagram:    &s,
+				diagramIdx: diagramIdx,
+				entry:      boardDiffEntry(),
+			})
+			continue
+		}
+		if (seg.kind != "markdown" && seg.kind != "html") || strings.TrimSpace(seg.content) == "" {
+			continue
+		}
+
+		result, err := convertImportSegment(seg, documentID, uploadImages, basePath, colWidthMode, colWidthValues, userAccessToken)
+		if err != nil {
+			return nil, fmt.Errorf("转换 Markdown 失败 (段落 %d): %w", segIdx+1, err)
+		}
+
+		tableIdx, imageIdx, videoIdx := 0, 0, 0
+		for _, node := range result.BlockNodes {
+			item := syncItem{node: node}
+			if node.Block.BlockType != nil && *node.Block.BlockType == int(converter.BlockTypeTable) && tableIdx < len(result.TableDatas) {
+				item.tableData = result.TableDatas[tableIdx]
+				tableIdx++
+			}
+			images := countBlockNodes(node, isUploadImageBlockNode)
+			item.imageSources = sliceSources(result.ImageSources, &imageIdx, images)
+			videos := countBlockNodes(node, isUploadFileBlockNode)
+			item.videoSources = sliceSources(result.VideoSources, &videoIdx, videos)
+			item.entry = converter.NodeBlockDiffEntry(node, item.tableData)
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// boardDiffEntry 返回图表画板的比较摘要。画板内容无法从文档侧还原为源码，
+// 与 DocumentBlockDiffEntry 对已有画板的结果一致：只按类型 + 位置匹配。
+func boardDiffEntry() converter.BlockDiffEntry {
+	kind := fmt.Sprint(int(converter.BlockTypeBoard))
+	return converter.BlockDiffEntry{Kind: kind, Signature: kind + "{}"}
+}
+
+// countBlockNodes 按先序遍历统计子树中满足 match 的节点数（与 appendImageTasks 的对齐顺序一致）
+func countBlockNodes(node *converter.BlockNode, match func(*converter.BlockNode) bool) int {
+	if node == nil {
+		return 0
+	}
+	n := 0
+	if match(node) {
+		n++
+	}
+	for _, child := range node.Children {
+		n += countBlockNodes(child, match)
+	}
+	return n
+}
+
+func sliceSources(sources []string, cursor *int, count int) []string {
+	start := min(*cursor, len(sources))
+	end := min(start+count, len(sources))
+	*cursor = end
+	return sources[start:end]
+}
+
+// buildSyncPlan 拉取文档现有块树，与 Markdown 转换结果逐个顶层块比对，生成编辑脚本
+func buildSyncPlan(documentID string, items []syncItem, userAccessToken string) (*syncPlan, error) {
+	blocks, err := client.GetAllBlocksWithToken(documentID, userAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("获取文档现有内容失败: %w", err)
+	}
+	blockMap := make(map[string]*larkdocx.Block, len(blocks))
+	var page *larkdocx.Block
+	for _, b := range blocks {
+		if b == nil || b.BlockId == nil {
+			continue
+		}
+		blockMap[*b.BlockId] = b
+		if b.BlockType != nil && *b.BlockType == int(converter.BlockTypePage) && page == nil {
+			page = b
+		}
+	}
+	if p, ok := blockMap[documentID]; ok {
+		page = p
+	}
+	if page == nil {
+		return nil, fmt.Errorf("文档 %s 未返回根块", documentID)
+	}
+
+	plan := &syncPlan{items: items}
+	var oldEntries []converter.BlockDiffEntry
+	for _, id := range page.Children {
+		plan.oldIDs = append(plan.oldIDs, id)
+		oldEntries = append(oldEntries, converter.DocumentBlockDiffEntry(blockMap[id], blockMap))
+	}
+	newEntries := make([]converter.BlockDiffEntry, len(items))
+	for i, item := range items {
+		newEntries[i] = item.entry
+	}
+
+	plan.edits = converter.DiffBlocks(oldEntries, newEntries)
+	plan.summary = converter.SummarizeBlockEdits(plan.edits)
+	return plan, nil
+}
+
+// hunks 把编辑脚本切分为以保留/更新块为界的改动段（按文档顺序）
+func (p *syncPlan) hunks() []syncHunk {
+	var hunks []syncHunk
+	cur := syncHunk{}
+	flush := func(nextOld int) {
+		if cur.oldEnd > cur.oldStart || len(cur.items) > 0 {
+			hunks = append(hunks, cur)
+		}
+		cur = syncHunk{oldStart: nextOld, oldEnd: nextOld}
+	}
+	for _, e := range p.edits {
+		switch e.Op {
+		case converter.BlockDiffKeep, converter.BlockDiffUpdate:
+			flush(e.OldIndex + 1)
+		case converter.BlockDiffDelete:
+			cur.oldEnd = e.OldIndex + 1
+		case converter.BlockDiffInsert:
+			cur.items = append(cur.items, e.NewIndex)
+		}
+	}
+	flush(len(p.oldIDs))
+	return hunks
+}
+
+// textUpdates 收集编辑脚本中的原地更新
+func (p *syncPlan) textUpdates() []client.TextElementsUpdate {
+	var updates []client.TextElementsUpdate
+	for _, e := range p.edits {
+		if e.Op != converter.BlockDiffUpdate {
+			continue
+		}
+		body := converter.BlockTextBody(p.items[e.NewIndex].node.Block)
+		if body == nil {
+			continue
+		}
+		updates = append(updates, client.TextElementsUpdate{
+			BlockID:  p.oldIDs[e.OldIndex],
+			Elements: body.Elements,
+		})
+	}
+	return updates
+}
+
+// phase1SyncBlocks 是 --incremental 模式下的阶段 1：只对差异块执行更新/删除/插入，
+// 新插入块的表格、图片、图表任务与全量导入一样交给阶段 2/3 处理。
+func phase1SyncBlocks(
+	documentID string,
+	plan *syncPlan,
+	basePath string,
+	stats *importStats,
+	verbose bool,
+	userAccessToken string,
+) ([]diagramTask, []tableTask, []imageTask, []videoTask, error) {
+	tasks := &importTasks{}
+
+	if updates := plan.textUpdates(); len(updates) > 0 {
+		if err := client.BatchUpdateTextElements(documentID, updates, userAccessToken); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("更新块内容失败: %w", err)
+		}
+		if verbose {
+			stats.progressf("  [同步] 原地更新 %d 个块\n", len(updates))
+		}
+	}
+
+	// 从后往前应用改动段，前面段的索引不受影响
+	hunks := plan.hunks()
+	for h := len(hunks) - 1; h >= 0; h-- {
+		hunk := hunks[h]
+		if hunk.oldEnd > hunk.oldStart {
+			res := client.DoVoidWithRetry(func() (http.Header, error) {
+				return client.DeleteBlocks(documentID, documentID, hunk.oldStart, hunk.oldEnd, userAccessToken)
+			}, client.RetryConfig{
+				MaxRetries:       5,
+				RetryOnRateLimit: true,
+			})
+			if res.Err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("删除块 [%d, %d) 失败: %w", hunk.oldStart, hunk.oldEnd, res.Err)
+			}
+			if verbose {
+				stats.progressf("  [同步] 删除位置 %d-%d 的 %d 个块\n", hunk.oldStart, hunk.oldEnd-1, hunk.oldEnd-hunk.oldStart)
+			}
+		}
+
+		pos := hunk.oldStart
+		for i := 0; i < len(hunk.items); {
+			item := plan.items[hunk.items[i]]
+			if item.diagram != nil {
+				if createDiagramBoard(documentID, *item.diagram, item.diagramIdx, pos, tasks, stats, verbose, userAccessToken) {
+					pos++
+				}
+				i++
+				continue
+			}
+
+			// 连续的 Markdown 块合并为一次批量创建
+			run := &converter.ConvertResult{}
+			for ; i < len(hunk.items) && plan.items[hunk.items[i]].diagram == nil; i++ {
+				it := plan.items[hunk.items[i]]
+				run.BlockNodes = append(run.BlockNodes, it.node)
+				if it.tableData != nil {
+					run.TableDatas = append(run.TableDatas, it.tableData)
+				}
+				run.ImageSources = append(run.ImageSources, it.imageSources...)
+				run.VideoSources = append(run.VideoSources, it.videoSources...)
+			}
+			created, err := createConvertedBlocks(documentID, run, pos, fmt.Sprintf("位置 %d", pos), basePath, tasks, stats, verbose, userAccessToken)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			pos += created
+		}
+	}
+
+	return tasks.diagrams, tasks.tables, tasks.images, tasks.videos, nil
+}
+
+// printSyncPlan 输出 --dry-run 的编辑脚本
+func printSyncPlan(w io.Writer, documentID string, plan *syncPlan, output string) error {
+	if output == "json" {
+		edits := make([]map[string]any, 0, len(plan.edits))
+		for _, e := range plan.edits {
+			if e.Op == converter.BlockDiffKeep {
+				continue
+			}
+			entry := map[string]any{
+				"op":        string(e.Op),
+				"old_index": e.OldIndex,
+				"new_index": e.NewIndex,
+				"moved":     e.Moved,
+			}
+			if e.OldIndex >= 0 {
+				entry["block_id"] = plan.oldIDs[e.OldIndex]
+			}
+			edits = append(edits, entry)
+		}
+		return printJSON(map[string]any{
+			"document_id": documentID,
+			"dry_run":     true,
+			"summary":     plan.summary,
+			"edits":       edits,
+		})
+	}
+
+	fmt.Fprintf(w, "增量同步计划 (未写入): 保留 %d, 更新 %d, 插入 %d, 删除 %d, 移动 %d\n",
+		plan.summary.Kept, plan.summary.Updated, plan.summary.Inserted, plan.summary.Deleted, plan.summary.Moved)
+	for _, e := range plan.edits {
+		switch e.Op {
+		case converter.BlockDiffUpdate:
+			fmt.Fprintf(w, "  ~ 更新 #%d (%s)\n", e.OldIndex, plan.oldIDs[e.OldIndex])
+		case converter.BlockDiffDelete:
+			if e.Moved {
+				fmt.Fprintf(w, "  > 移动 #%d (%s) → 新位置 #%d\n", e.OldIndex, plan.oldIDs[e.OldIndex], e.NewIndex)
+			} else {
+				fmt.Fprintf(w, "  - 删除 #%d (%s)\n", e.OldIndex, plan.oldIDs[e.OldIndex])
+			}
+		case converter.BlockDiffInsert:
+			if !e.Moved {
+				fmt.Fprintf(w, "  + 插入新块 #%d\n", e.NewIndex)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/converter"
+)
+
+func TestBuildSyncItemsAlignsTablesImagesAndDiagrams(t *testing.T) {
+	segments := parseMarkdownSegments("# 标题\n\n![a](./a.png)\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```mermaid\ngraph TD\nA-->B\n```\n\n![b](./b.png)\n")
+	items, err := buildSyncItems(segments, "doc", true, "/tmp", "auto", nil, "")
+	if err != nil {
+		t.Fatalf("buildSyncItems: %v", err)
+	}
+
+	var kinds []string
+	for _, item := range items {
+		switch {
+		case item.diagram != nil:
+			kinds = append(kinds, "diagram")
+		case item.tableData != nil:
+			kinds = append(kinds, "table")
+		case len(item.imageSources) > 0:
+			kinds = append(kinds, "image:"+item.imageSources[0])
+		default:
+			kinds = append(kinds, converter.BlockTypeName(converter.BlockType(*item.node.Block.BlockType)))
+		}
+	}
+	want := []string{"Heading1", "image:./a.png", "table", "diagram", "image:./b.png"}
+	if len(kinds) != len(want) {
+		t.Fatalf("items = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("items = %v, want %v", kinds, want)
+		}
+	}
+}
+
+func TestSyncPlanHunks(t *testing.T) {
+	plan := &syncPlan{
+		oldIDs: []string{"a", "b", "c", "d"},
+		edits: []converter.BlockEdit{
+			{Op: converter.BlockDiffKeep, OldIndex: 0, NewIndex: 0},
+			{Op: converter.BlockDiffDelete, OldIndex: 1, NewIndex: -1},
+			{Op: converter.BlockDiffInsert, OldIndex: -1, NewIndex: 1},
+			{Op: converter.BlockDiffInsert, OldIndex: -1, NewIndex: 2},
+			{Op: converter.BlockDiffUpdate, OldIndex: 2, NewIndex: 3},
+			{Op: converter.BlockDiffDelete, OldIndex: 3, NewIndex: -1},
+		},
+	}
+	hunks := plan.hunks()
+	if len(hunks) != 2 {
+		t.Fatalf("len(hunks) = %d, want 2: %+v", len(hunks), hunks)
+	}
+	if h := hunks[0]; h.oldStart != 1 || h.oldEnd != 2 || len(h.items) != 2 || h.items[0] != 1 {
+		t.Errorf("hunks[0] = %+v, want delete [1,2) then insert new 1,2", h)
+	}
+	if h := hunks[1]; h.oldStart != 3 || h.oldEnd != 4 || len(h.items) != 0 {
+		t.Errorf("hunks[1] = %+v, want delete [3,4)", h)
+	}
+}
+
+func TestSyncPlanHunksLeadingInsert(t *testing.T) {
+	plan := &syncPlan{
+		oldIDs: []string{"a"},
+		edits: []converter.BlockEdit{
+			{Op: converter.BlockDiffInsert, OldIndex: -1, NewIndex: 0},
+			{Op: converter.BlockDiffKeep, OldIndex: 0, NewIndex: 1},
+		},
+	}
+	hunks := plan.hunks()
+	if len(hunks) != 1 || hunks[0].oldStart != 0 || hunks[0].oldEnd != 0 || len(hunks[0].items) != 1 {
+		t.Fatalf("hunks = %+v, want single insert at 0", hunks)
+	}
+}
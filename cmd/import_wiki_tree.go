@@ -0,0 +1,675 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// defaultWikiTreeMappingFile 是 import-tree 映射文件的默认文件名，位于导入根目录下
+const defaultWikiTreeMappingFile = ".feishu-wiki-map.json"
+
+var importWikiTreeCmd = &cobra.Command{
+	Use:   "import-tree <dir>",
+	Short: "把本地 Markdown 目录树批量导入为知识库节点层级",
+	Long: `把本地目录中的 Markdown 文件按目录结构导入知识库，是 wiki export-tree 的反向操作。
+
+目录布局:
+  <dir>/a.md                   # 挂在 --parent-node 下的页面「a」
+  <dir>/guide/index.md         # 目录「guide」的章节页（index.md / README.md / guide.md 任一）
+  <dir>/guide/install.md       # 「guide」的子页面
+  <dir>/empty-section/x.md     # 没有章节页的目录会创建一个空白的同名节点承载子页面
+
+执行流程:
+  1. 扫描目录（跳过隐藏文件/目录和不含 .md 的目录），按层级创建知识库节点
+  2. 逐个导入文件内容（与 doc import 相同的三阶段流水线：图表、表格、图片）
+     导入前把指向其他 .md 文件或目录的相对链接改写为对应节点的链接
+  3. 把「相对路径 → 节点」写入映射文件（默认 <dir>/.feishu-wiki-map.json）
+
+再次运行时按映射文件更新已有节点（覆盖内容、同步标题）而不是重复创建；
+内容未变化的文件直接跳过（--force 强制重新导入）。要重建某个节点，从映射文件中删掉对应条目即可。
+
+示例:
+  # 导入到知识空间根目录
+  feishu-cli wiki import-tree ./docs --space-id 7012345678901234567
+
+  # 导入到指定父节点下
+  feishu-cli wiki import-tree ./docs --space-id 7012345678901234567 --parent-node wikcnXXXXXX
+
+  # 预览将要创建/更新的节点
+  feishu-cli wiki import-tree ./docs --space-id 7012345678901234567 --dry-run
+
+  # 再次同步（space-id 与父节点从映射文件读取）
+  feishu-cli wiki import-tree ./docs`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		rootDir, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("解析目录失败: %w", err)
+		}
+		if info, err := os.Stat(rootDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s 不是目录", args[0])
+		}
+
+		spaceID, _ := cmd.Flags().GetString("space-id")
+		parentNode, _ := cmd.Flags().GetString("parent-node")
+		mappingPath, _ := cmd.Flags().GetString("mapping")
+		uploadImages, _ := cmd.Flags().GetBool("upload-images")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if mappingPath == "" {
+			mappingPath = filepath.Join(rootDir, defaultWikiTreeMappingFile)
+		}
+		if parentNode != "" {
+			if parentNode, err = extractWikiToken(parentNode); err != nil {
+				return err
+			}
+		}
+
+		mapping, err := loadWikiTreeMapping(mappingPath)
+		if err != nil {
+			return err
+		}
+		if err := mapping.bind(spaceID, parentNode); err != nil {
+			return err
+		}
+
+		entries, err := scanWikiTree(rootDir)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("目录 %s 中没有 Markdown 文件", args[0])
+		}
+
+		if dryRun {
+			printWikiTreePlan(entries, mapping)
+			return nil
+		}
+
+		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
+		stats := newTreeStats(len(entries))
+
+		// 1. 按先序创建节点（父节点总在子节点之前），映射文件每建一个节点保存一次，中断后可续跑
+		fmt.Printf("=== 创建节点层级 (%d 个) ===\n", len(entries))
+		failed := make([]bool, len(entries))
+		createdNow := make([]bool, len(entries))
+		for i, e := range entries {
+			progress := fmt.Sprintf("[%d/%d]", i+1, len(entries))
+			if e.Parent >= 0 && failed[e.Parent] {
+				failed[i] = true
+				stats.Failed++
+				stats.Failures = append(stats.Failures, treeFailure{Title: e.Title, Path: e.RelPath, Error: "父节点创建失败"})
+				fmt.Printf("%s ⊘ %s  (父节点创建失败，跳过)\n", progress, e.RelPath)
+				continue
+			}
+			created, err := ensureWikiTreeNode(mapping, entries, i, userAccessToken)
+			if err == nil && created {
+				err = mapping.save(mappingPath)
+			}
+			if err != nil {
+				failed[i] = true
+				stats.Failed++
+				stats.Failures = append(stats.Failures, treeFailure{Title: e.Title, Path: e.RelPath, Error: err.Error()})
+				fmt.Printf("%s ✗ %s  (%v)\n", progress, e.RelPath, err)
+				if !continueOnError {
+					return fmt.Errorf("%s 创建节点失败: %w", e.RelPath, err)
+				}
+				continue
+			}
+			createdNow[i] = created
+			if created {
+				fmt.Printf("%s + %s → %s\n", progress, e.RelPath, mapping.Nodes[e.RelPath].NodeToken)
+			} else if verbose {
+				fmt.Printf("%s = %s → %s\n", progress, e.RelPath, mapping.Nodes[e.RelPath].NodeToken)
+			}
+		}
+
+		// 2. 导入内容：此时所有节点都已存在，相对 .md 链接可以一次改写到位
+		fmt.Printf("\n=== 导入内容 ===\n")
+		links := wikiTreeLinkTargets(entries, mapping)
+		opts := importPipelineOptions{diagramWorkers: 5, tableWorkers: 3, imageWorkers: 2, diagramRetries: 10, verbose: verbose}
+		for i, e := range entries {
+			progress := fmt.Sprintf("[%d/%d]", i+1, len(entries))
+			if failed[i] {
+				continue
+			}
+			if e.FilePath == "" {
+				// 目录空白页没有内容可导入：本次新建记为成功，已存在记为未变化
+				if createdNow[i] {
+					stats.Success++
+				} else {
+					stats.Skipped++
+				}
+				if verbose {
+					fmt.Printf("%s ⏭  %s  (目录节点，无内容)\n", progress, e.RelPath)
+				}
+				continue
+			}
+			node := mapping.Nodes[e.RelPath]
+			content, err := os.ReadFile(e.FilePath)
+			if err == nil {
+				err = validateMarkdownEncoding(content)
+			}
+			if err != nil {
+				stats.Failed++
+				stats.Failures = append(stats.Failures, treeFailure{NodeToken: node.NodeToken, Title: e.Title, Path: e.RelPath, Error: err.Error()})
+				fmt.Printf("%s ✗ %s  (%v)\n", progress, e.RelPath, err)
+				if !continueOnError {
+					return err
+				}
+				continue
+			}
+			markdown := rewriteWikiTreeLinks(string(content), e.RelPath, links)
+			hash := wikiTreeContentHash(markdown)
+			if node.Hash == hash && !force {
+				stats.Skipped++
+				fmt.Printf("%s ⏭  %s  (未变化，跳过)\n", progress, e.RelPath)
+				continue
+			}
+
+			var progressOut io.Writer = io.Discard
+			if verbose {
+				progressOut = os.Stdout
+			}
+			importStats, err := importMarkdownIntoDocument(node.ObjToken, markdown, filepath.Dir(e.FilePath), uploadImages, opts, progressOut, userAccessToken)
+			if err != nil {
+				// 导入前文档已被清空，失败后页面可能为空或只写了一半：清掉摘要，下次运行必须重新导入
+				node.recordImport("", 1)
+				mapping.Nodes[e.RelPath] = node
+				if saveErr := mapping.save(mappingPath); saveErr != nil {
+					return saveErr
+				}
+				stats.Failed++
+				stats.Failures = append(stats.Failures, treeFailure{NodeToken: node.NodeToken, Title: e.Title, Path: e.RelPath, Error: err.Error()})
+				fmt.Printf("%s ✗ %s  (导入失败: %v)\n", progress, e.RelPath, err)
+				if !continueOnError {
+					return fmt.Errorf("%s 导入失败: %w", e.RelPath, err)
+				}
+				continue
+			}
+
+			partial := importStats.failedCount()
+			node.recordImport(hash, partial)
+			mapping.Nodes[e.RelPath] = node
+			if err := mapping.save(mappingPath); err != nil {
+				return err
+			}
+			stats.Success++
+			if partial > 0 {
+				fmt.Printf("%s ⚠ %s  (%d 个图表/表格/图片处理失败，下次运行将重新导入)\n", progress, e.RelPath, partial)
+			} else {
+				fmt.Printf("%s ✓ %s\n", progress, e.RelPath)
+			}
+		}
+
+		printWikiTreeImportSummary(stats, mapping, mappingPath)
+		if stats.Failed > 0 {
+			return fmt.Errorf("批量导入完成但有 %d 个文件失败", stats.Failed)
+		}
+		return nil
+	},
+}
+
+// wikiTreeEntry 是本地目录树中的一个待导入节点
+type wikiTreeEntry struct {
+	RelPath  string // 映射键：文件为相对根目录的路径（/ 分隔）；没有章节页的目录以 / 结尾
+	FilePath string // 本地文件路径，没有章节页的目录节点为空
+	Dir      string // 目录节点对应的目录（相对根目录），普通页面为空
+	Title    string
+	Parent   int // 父节点在 entries 中的下标，-1 表示挂在 --parent-node 下
+}
+
+// wikiTreeMapping 是映射文件内容：记录每个相对路径对应的知识库节点，再次运行时据此更新而非重复创建
+type wikiTreeMapping struct {
+	SpaceID    string                        `json:"space_id"`
+	ParentNode string                        `json:"parent_node,omitempty"`
+	Nodes      map[string]wikiTreeMappedNode `json:"nodes"`
+}
+
+// wikiTreeMappedNode 是映射文件中的一个节点
+type wikiTreeMappedNode struct {
+	NodeToken string `json:"node_token"`
+	ObjToken  string `json:"obj_token"`
+	Title     string `json:"title"`
+	Hash      string `json:"hash,omitempty"`    // 最近一次完整导入的内容摘要，空表示尚未导入或需要重试
+	Partial   bool   `json:"partial,omitempty"` // 最近一次导入有图表/表格/图片处理失败，下次运行会重新导入
+}
+
+// recordImport 记录一次导入结果：全部成功才保存内容摘要；有资源失败或整体导入失败（failed>0）时
+// 清空摘要并打上 partial 标记，下次运行即使内容未变也会重新导入。
+func (n *wikiTreeMappedNode) recordImport(hash string, failed int) {
+	if failed > 0 {
+		n.Hash = ""
+		n.Partial = true
+		return
+	}
+	n.Hash = hash
+	n.Partial = false
+}
+
+// loadWikiTreeMapping 读取映射文件，文件不存在时返回空映射
+func loadWikiTreeMapping(p string) (*wikiTreeMapping, error) {
+	m := &wikiTreeMapping{Nodes: map[string]wikiTreeMappedNode{}}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取映射文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析映射文件 %s 失败: %w", p, err)
+	}
+	if m.Nodes == nil {
+		m.Nodes = map[string]wikiTreeMappedNode{}
+	}
+	return m, nil
+}
+
+// bind 校验命令行参数与映射文件记录的目标位置一致；参数为空时沿用映射文件中的值
+func (m *wikiTreeMapping) bind(spaceID, parentNode string) error {
+	if len(m.Nodes) == 0 {
+		if spaceID == "" {
+			return fmt.Errorf("首次导入需要指定 --space-id")
+		}
+		m.SpaceID, m.ParentNode = spaceID, parentNode
+		return nil
+	}
+	if spaceID != "" && spaceID != m.SpaceID {
+		return fmt.Errorf("映射文件记录的知识空间为 %s，与 --space-id %s 不一致（如需导入到新位置，请用 --mapping 指定新的映射文件）", m.SpaceID, spaceID)
+	}
+	if parentNode != "" && parentNode != m.ParentNode {
+		return fmt.Errorf("映射文件记录的父节点为 %q，与 --parent-node %s 不一致（如需导入到新位置，请用 --mapping 指定新的映射文件）", m.ParentNode, parentNode)
+	}
+	return nil
+}
+
+func (m *wikiTreeMapping) save(p string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("写入映射文件失败: %w", err)
+	}
+	return nil
+}
+
+// wikiTreeSectionPages 是目录章节页的候选文件名（按优先级，大小写不敏感）；
+// 其后再尝试目录同名 .md（export-tree 的输出布局）
+var wikiTreeSectionPages = []string{"index.md", "readme.md"}
+
+// scanWikiTree 以先序遍历扫描目录，返回待导入节点（父节点总在子节点之前）。
+// 隐藏文件/目录和不含任何 .md 文件的目录被忽略；根目录自身不对应节点。
+func scanWikiTree(rootDir string) ([]wikiTreeEntry, error) {
+	var entries []wikiTreeEntry
+	if err := scanWikiTreeDir(rootDir, "", "", -1, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// scanWikiTreeDir 扫描 dir 的直接子项；section 为该目录已作为父节点内容的章节页文件名，扫描时跳过
+func scanWikiTreeDir(dir, relDir, section string, parent int, entries *[]wikiTreeEntry) error {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("读取目录失败: %w", err)
+	}
+	for _, item := range items {
+		name := item.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		rel := path.Join(relDir, name)
+		full := filepath.Join(dir, name)
+
+		if !item.IsDir() {
+			if !isMarkdownFile(name) || name == section {
+				continue
+			}
+			*entries = append(*entries, wikiTreeEntry{
+				RelPath:  rel,
+				FilePath: full,
+				Title:    strings.TrimSuffix(name, filepath.Ext(name)),
+				Parent:   parent,
+			})
+			continue
+		}
+
+		if !dirHasMarkdown(full) {
+			continue
+		}
+		entry := wikiTreeEntry{RelPath: rel + "/", Dir: rel, Title: name, Parent: parent}
+		childSection := findWikiTreeSectionPage(full, name)
+		if childSection != "" {
+			entry.RelPath = path.Join(rel, childSection)
+			entry.FilePath = filepath.Join(full, childSection)
+		}
+		*entries = append(*entries, entry)
+		if err := scanWikiTreeDir(full, rel, childSection, len(*entries)-1, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// findWikiTreeSectionPage 返回目录的章节页文件名，按 index.md、README.md、<目录名>.md 的顺序取第一个存在的
+func findWikiTreeSectionPage(dir, dirName string) string {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	candidates := append(append([]string{}, wikiTreeSectionPages...), strings.ToLower(dirName)+".md")
+	for _, candidate := range candidates {
+		for _, item := range items {
+			if !item.IsDir() && strings.ToLower(item.Name()) == candidate {
+				return item.Name()
+			}
+		}
+	}
+	return ""
+}
+
+// dirHasMarkdown 判断目录（递归，忽略隐藏项）中是否有 Markdown 文件
+func dirHasMarkdown(dir string) bool {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, item := range items {
+		if strings.HasPrefix(item.Name(), ".") {
+			continue
+		}
+		if item.IsDir() {
+			if dirHasMarkdown(filepath.Join(dir, item.Name())) {
+				return true
+			}
+		} else if isMarkdownFile(item.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureWikiTreeNode 确保 entries[i] 对应的节点存在：映射中没有则创建，有则在标题变化时同步标题。
+// 返回是否新建了节点。
+func ensureWikiTreeNode(m *wikiTreeMapping, entries []wikiTreeEntry, i int, userAccessToken string) (bool, error) {
+	e := entries[i]
+	if node, ok := m.Nodes[e.RelPath]; ok {
+		if node.Title != e.Title {
+			if err := client.UpdateWikiNode(m.SpaceID, node.NodeToken, e.Title, userAccessToken); err != nil {
+				return false, err
+			}
+			node.Title = e.Title
+			m.Nodes[e.RelPath] = node
+		}
+		return false, nil
+	}
+
+	parentToken := m.ParentNode
+	if e.Parent >= 0 {
+		parentToken = m.Nodes[entries[e.Parent].RelPath].NodeToken
+	}
+	result, err := client.CreateWikiNode(m.SpaceID, e.Title, parentToken, "docx", "origin", userAccessToken)
+	if err != nil {
+		return false, err
+	}
+	m.Nodes[e.RelPath] = wikiTreeMappedNode{NodeToken: result.NodeToken, ObjToken: result.ObjToken, Title: e.Title}
+	return true, nil
+}
+
+// wikiTreeLinkTargets 返回「相对路径 → 节点链接」：页面按文件路径索引，目录节点额外按目录路径索引
+func wikiTreeLinkTargets(entries []wikiTreeEntry, m *wikiTreeMapping) map[string]string {
+	targets := make(map[string]string, len(entries))
+	for _, e := range entries {
+		node, ok := m.Nodes[e.RelPath]
+		if !ok {
+			continue
+		}
+		link := "https://feishu.cn/wiki/" + node.NodeToken
+		if e.FilePath != "" {
+			targets[e.RelPath] = link
+		}
+		if e.Dir != "" {
+			targets[e.Dir] = link
+		}
+	}
+	return targets
+}
+
+var (
+	// wikiTreeInlineLinkRe 匹配行内链接 ](target) / ](target "title")，不含图片语法的区分（图片指向 .md 的情况可忽略）
+	wikiTreeInlineLinkRe = regexp.MustCompile(`\]\((<[^>\n]*>|[^()\s]+)(\s+"[^"\n]*")?\)`)
+	// wikiTreeRefLinkRe 匹配引用式链接定义 [id]: target
+	wikiTreeRefLinkRe = regexp.MustCompile(`^(\s{0,3}\[[^\]]+\]:\s*)(\S+)(.*)$`)
+)
+
+// rewriteWikiTreeLinks 把指向目录树内其他 .md 文件（或目录）的相对链接改写为对应节点的链接。
+// fromRel 为当前文件相对根目录的路径；代码块与行内代码中的内容保持原样，锚点（#...）被丢弃。
+func rewriteWikiTreeLinks(markdown, fromRel string, targets map[string]string) string {
+	lines := strings.SplitAfter(markdown, "\n")
+	inFence := false
+	fenceChar := byte(0)
+	fenceLength := 0
+	for i, line := range lines {
+		if char, length, trailing, ok := exportedMediaFence(line); ok {
+			if !inFence {
+				inFence, fenceChar, fenceLength = true, char, length
+			} else if char == fenceChar && length >= fenceLength && trailing == "" {
+				inFence = false
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := wikiTreeRefLinkRe.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+			if link, ok := resolveWikiTreeLink(strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">"), fromRel, targets); ok {
+				lines[i] = m[1] + link + m[3] + line[len(strings.TrimRight(line, "\r\n")):]
+			}
+			continue
+		}
+		lines[i] = mapOutsideInlineCode(line, func(text string) string {
+			return wikiTreeInlineLinkRe.ReplaceAllStringFunc(text, func(match string) string {
+				sub := wikiTreeInlineLinkRe.FindStringSubmatch(match)
+				target := strings.TrimSuffix(strings.TrimPrefix(sub[1], "<"), ">")
+				link, ok := resolveWikiTreeLink(target, fromRel, targets)
+				if !ok {
+					return match
+				}
+				return "](" + link + sub[2] + ")"
+			})
+		})
+	}
+	return strings.Join(lines, "")
+}
+
+// resolveWikiTreeLink 把相对链接解析为目录树内节点的链接；外部链接、绝对路径和未知文件返回 false
+func resolveWikiTreeLink(target, fromRel string, targets map[string]string) (string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || strings.Contains(target, ":") {
+		return "", false
+	}
+	target, _, _ = strings.Cut(target, "#")
+	target, _, _ = strings.Cut(target, "?")
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	resolved := path.Clean(path.Join(path.Dir(fromRel), target))
+	if resolved == "." || strings.HasPrefix(resolved, "../") {
+		return "", false
+	}
+	if !isMarkdownFile(resolved) && !strings.HasSuffix(target, "/") {
+		// 只改写 .md 链接和显式的目录链接（以 / 结尾），避免误伤指向图片/附件的相对路径
+		return "", false
+	}
+	link, ok := targets[resolved]
+	return link, ok
+}
+
+// mapOutsideInlineCode 对行中行内代码（`...`）以外的部分应用 fn
+func mapOutsideInlineCode(line string, fn func(string) string) string {
+	var sb strings.Builder
+	last := 0
+	for i := 0; i < len(line); {
+		if line[i] != '`' || exportedMarkdownEscaped(line, i) {
+			i++
+			continue
+		}
+		length := 1
+		for i+length < len(line) && line[i+length] == '`' {
+			length++
+		}
+		closeAt := -1
+		for j := i + length; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			run := 1
+			for j+run < len(line) && line[j+run] == '`' {
+				run++
+			}
+			if run == length {
+				closeAt = j + run
+				break
+			}
+			j += run
+		}
+		if closeAt < 0 {
+			i += length
+			continue
+		}
+		sb.WriteString(fn(line[last:i]))
+		sb.WriteString(line[i:closeAt])
+		last, i = closeAt, closeAt
+	}
+	sb.WriteString(fn(line[last:]))
+	return sb.String()
+}
+
+func wikiTreeContentHash(markdown string) string {
+	sum := sha256.Sum256([]byte(markdown))
+	return hex.EncodeToString(sum[:])
+}
+
+// failedCount 返回导入中处理失败（已降级或丢弃）的资源数
+func (s *importStats) failedCount() int {
+	return s.diagramFailed + s.tableFailed + s.imageFailed + s.videoFailed + s.cellImageFailed
+}
+
+// importMarkdownIntoDocument 清空文档后用 doc import 的三阶段流水线写入 Markdown。
+// 新建的空文档同样先清空，这样上次中途失败留下的半截内容不会与本次导入叠加。
+func importMarkdownIntoDocument(documentID, markdown, basePath string, uploadImages bool, opts importPipelineOptions, progress io.Writer, userAccessToken string) (*importStats, error) {
+	if err := clearDocumentContent(documentID, userAccessToken); err != nil {
+		return nil, err
+	}
+	mermaidCount, plantumlCount, svgCount := countDiagramBlocks(markdown)
+	stats := &importStats{
+		diagramTotal:  mermaidCount + plantumlCount + svgCount,
+		mermaidCount:  mermaidCount,
+		plantumlCount: plantumlCount,
+		svgCount:      svgCount,
+		progress:      progress,
+	}
+	dTasks, tTasks, iTasks, vTasks, err := phase1CreateBlocks(documentID, parseMarkdownSegments(markdown), uploadImages, basePath, stats, opts.verbose, userAccessToken, "auto", nil)
+	if err != nil {
+		return nil, err
+	}
+	stats.tableTotal = len(tTasks)
+	stats.imageTotal = stats.imageSkipped + len(iTasks)
+	stats.videoTotal = stats.videoSkipped + len(vTasks)
+	runImportPhase2And3(documentID, dTasks, tTasks, iTasks, vTasks, basePath, opts, stats, userAccessToken)
+	return stats, nil
+}
+
+// printWikiTreePlan 打印 --dry-run 的节点计划
+func printWikiTreePlan(entries []wikiTreeEntry, m *wikiTreeMapping) {
+	fmt.Printf("知识空间: %s\n", m.SpaceID)
+	if m.ParentNode != "" {
+		fmt.Printf("父节点:   %s\n", m.ParentNode)
+	}
+	fmt.Println()
+	creates := 0
+	for _, e := range entries {
+		action := "创建"
+		if node, ok := m.Nodes[e.RelPath]; ok {
+			action = "更新 " + node.NodeToken
+		} else {
+			creates++
+		}
+		source := e.RelPath
+		if e.FilePath == "" {
+			source += "（目录，空白页）"
+		}
+		fmt.Printf("%s%s  [%s]  ← %s\n", strings.Repeat("  ", wikiTreeDepth(entries, e)), e.Title, action, source)
+	}
+	fmt.Printf("\n共 %d 个节点：新建 %d，更新 %d（dry-run，未写入）\n", len(entries), creates, len(entries)-creates)
+}
+
+// wikiTreeDepth 返回节点在知识库层级中的深度（挂在父节点下的顶层为 0）
+func wikiTreeDepth(entries []wikiTreeEntry, e wikiTreeEntry) int {
+	depth := 0
+	for p := e.Parent; p >= 0; p = entries[p].Parent {
+		depth++
+	}
+	return depth
+}
+
+// printWikiTreeImportSummary 打印批量导入总结
+func printWikiTreeImportSummary(stats *treeStats, m *wikiTreeMapping, mappingPath string) {
+	fmt.Println()
+	fmt.Println("=== 导入完成 ===")
+	fmt.Printf("  知识空间: %s\n", m.SpaceID)
+	fmt.Printf("  映射文件: %s\n", mappingPath)
+	fmt.Printf("  总节点:   %d\n", stats.Total)
+	fmt.Printf("  成功:     %d\n", stats.Success)
+	if stats.Skipped > 0 {
+		fmt.Printf("  跳过(未变化): %d\n", stats.Skipped)
+	}
+	if stats.Failed > 0 {
+		fmt.Printf("  失败:     %d\n", stats.Failed)
+		fmt.Println()
+		fmt.Println("失败明细:")
+		for _, f := range stats.Failures {
+			fmt.Printf("  - %s\n    %s\n", f.Path, truncate(f.Error, 200))
+		}
+	}
+}
+
+func init() {
+	wikiCmd.AddCommand(importWikiTreeCmd)
+	importWikiTreeCmd.Flags().String("space-id", "", "知识空间 ID（首次导入必填，之后可从映射文件读取）")
+	importWikiTreeCmd.Flags().String("parent-node", "", "父节点 Token 或 URL（可选，不指定则导入到知识空间根目录）")
+	importWikiTreeCmd.Flags().String("mapping", "", "映射文件路径（默认 <dir>/"+defaultWikiTreeMappingFile+"）")
+	importWikiTreeCmd.Flags().Bool("upload-images", true, "上传本地和网络图片")
+	importWikiTreeCmd.Flags().Bool("force", false, "内容未变化的文件也重新导入")
+	importWikiTreeCmd.Flags().Bool("dry-run", false, "只打印将要创建/更新的节点，不调用 API")
+	importWikiTreeCmd.Flags().Bool("continue-on-error", true, "单个文件失败时是否继续后续文件")
+	importWikiTreeCmd.Flags().BoolP("verbose", "v", false, "显示每个文件的导入进度")
+	importWikiTreeCmd.Flags().String("user-access-token", "", "User Access Token（可选；默认优先使用 auth login 登录态，失败时回退 App Token）")
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/mock"
+)
+
+func TestScanWikiTree(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"a.md",
+		"guide/README.md",
+		"guide/install.md",
+		"guide/deep/deep.md",
+		"guide/deep/x.md",
+		"empty/sub.md",
+		"assets/logo.png",
+		".git/HEAD.md",
+		"notes.txt",
+	}
+	for _, f := range files {
+		p := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("# "+f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := scanWikiTree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type row struct {
+		RelPath, Dir, Title string
+		Parent              int
+		HasFile             bool
+	}
+	var got []row
+	for _, e := range entries {
+		got = append(got, row{e.RelPath, e.Dir, e.Title, e.Parent, e.FilePath != ""})
+	}
+	want := []row{
+		{"a.md", "", "a", -1, true},
+		{"empty/", "empty", "empty", -1, false},
+		{"empty/sub.md", "", "sub", 1, true},
+		{"guide/README.md", "guide", "guide", -1, true},
+		{"guide/deep/deep.md", "guide/deep", "deep", 3, true},
+		{"guide/deep/x.md", "", "x", 4, true},
+		{"guide/install.md", "", "install", 3, true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanWikiTree =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestRewriteWikiTreeLinks(t *testing.T) {
+	targets := map[string]string{
+		"a.md":            "https://feishu.cn/wiki/A",
+		"guide/README.md": "https://feishu.cn/wiki/G",
+		"guide":           "https://feishu.cn/wiki/G",
+		"guide/setup.md":  "https://feishu.cn/wiki/S",
+	}
+	md := "见 [安装](setup.md#step-1) 和 [首页](../a.md \"返回\")、[章节](./)、[外链](https://x.com/a.md)、[图](img.png)\n" +
+		"`[代码](setup.md)` 与 [未知](missing.md)\n" +
+		"```\n[块内](setup.md)\n```\n" +
+		"[ref]: setup%2Emd\n" +
+		"[ref2]: <../a.md>\n"
+	got := rewriteWikiTreeLinks(md, "guide/README.md", targets)
+	want := "见 [安装](https://feishu.cn/wiki/S) 和 [首页](https://feishu.cn/wiki/A \"返回\")、[章节](https://feishu.cn/wiki/G)、[外链](https://x.com/a.md)、[图](img.png)\n" +
+		"`[代码](setup.md)` 与 [未知](missing.md)\n" +
+		"```\n[块内](setup.md)\n```\n" +
+		"[ref]: https://feishu.cn/wiki/S\n" +
+		"[ref2]: https://feishu.cn/wiki/A\n"
+	if got != want {
+		t.Errorf("rewriteWikiTreeLinks =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := rewriteWikiTreeLinks("[ref]: ../a.md \"t\"\n", "guide/x.md", targets); got != "[ref]: https://feishu.cn/wiki/A \"t\"\n" {
+		t.Errorf("引用式链接改写 = %q", got)
+	}
+}
+
+func TestWikiTreeMappingBind(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, defaultWikiTreeMappingFile)
+
+	m, err := loadWikiTreeMapping(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.bind("", ""); err == nil {
+		t.Error("首次导入缺少 --space-id 应报错")
+	}
+	if err := m.bind("space1", "wikcnParent"); err != nil {
+		t.Fatal(err)
+	}
+	m.Nodes["a.md"] = wikiTreeMappedNode{NodeToken: "wikcnA", ObjToken: "doxA", Title: "a", Hash: "h"}
+	if err := m.save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadWikiTreeMapping(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("映射文件往返不一致: %+v vs %+v", loaded, m)
+	}
+	if err := loaded.bind("", ""); err != nil || loaded.SpaceID != "space1" || loaded.ParentNode != "wikcnParent" {
+		t.Errorf("再次运行应沿用映射文件中的位置: %v %+v", err, loaded)
+	}
+	if err := loaded.bind("space2", ""); err == nil {
+		t.Error("知识空间不一致时应报错")
+	}
+	if err := loaded.bind("space1", "wikcnOther"); err == nil {
+		t.Error("父节点不一致时应报错")
+	}
+}
+
+func TestWikiTreeRecordImport(t *testing.T) {
+	node := wikiTreeMappedNode{NodeToken: "wikcnA", Hash: "old"}
+
+	// 有资源失败：不记录摘要，下次运行内容未变也会重试
+	node.recordImport("h1", 2)
+	if node.Hash != "" || !node.Partial {
+		t.Fatalf("部分失败时不应记录摘要: %+v", node)
+	}
+
+	// 重试全部成功：记录摘要并清除标记
+	node.recordImport("h1", 0)
+	if node.Hash != "h1" || node.Partial {
+		t.Fatalf("全部成功时应记录摘要: %+v", node)
+	}
+}
+
+func TestImportWikiTreeRetriesFailedImport(t *testing.T) {
+	srv := mock.New()
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("FEISHU_BASE_URL", ts.URL)
+	t.Setenv("FEISHU_APP_ID", "cli_wiki_tree")
+	t.Setenv("FEISHU_APP_SECRET", "mock")
+
+	root := t.TempDir()
+	page := filepath.Join(root, "a.md")
+	if err := os.WriteFile(page, []byte("# A\n\nhello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	importTree := func() (string, error) {
+		stdout, _, err := runCLI(t, "wiki", "import-tree", root, "--space-id", "7000000000000000001")
+		return stdout, err
+	}
+	mapped := func() wikiTreeMappedNode {
+		m, err := loadWikiTreeMapping(filepath.Join(root, defaultWikiTreeMappingFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.Nodes["a.md"]
+	}
+
+	if _, err := importTree(); err != nil {
+		t.Fatal(err)
+	}
+	first := mapped()
+	if first.Hash == "" {
+		t.Fatalf("首次导入成功后应记录摘要: %+v", first)
+	}
+
+	// 第二次：内容有改动，清空文档后写入块失败
+	if err := os.WriteFile(page, []byte("# A\n\nhello again\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv.InjectFault("POST", "/open-apis/docx/v1/documents/"+first.ObjToken+"/blocks/"+first.ObjToken+"/children", 1, 200, 1770001, "invalid param")
+	if _, err := importTree(); err == nil {
+		t.Fatal("写入块失败时应报错")
+	}
+	if n := mapped(); n.Hash != "" || !n.Partial {
+		t.Fatalf("导入失败后应清掉摘要: %+v", n)
+	}
+
+	// 第三次：内容改回与首次相同，也必须重新导入而不是当作未变化跳过
+	if err := os.WriteFile(page, []byte("# A\n\nhello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := importTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout, "✓ a.md") {
+		t.Errorf("应重新导入 a.md:\n%s", stdout)
+	}
+	if n := mapped(); n.Hash != first.Hash || n.Partial {
+		t.Errorf("重新导入后 = %+v, want hash %s", n, first.Hash)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "离线 mock OpenAPI 服务（CI / 脚本测试）",
+	Long: `离线 mock OpenAPI 服务，在本机模拟飞书开放平台，供 CI 与脚本在无网络、无真实凭证时端到端测试。
+
+子命令:
+  serve          启动 mock 服务（阻塞，Ctrl-C 退出）
+
+模拟范围:
+  - token 接口     tenant_access_token / app_access_token，任意非空 app_id + app_secret 均可换取
+  - docx          文档创建 / 块增删改查（含表格单元格自动生成）/ raw_content
+  - drive         文件列表 / 创建文件夹 / 上传下载 / 移动复制删除 / 素材上传
+  - im            消息发送 / 回复 / 列表 / 获取 / 编辑 / 撤回（uuid 幂等）
+  - sheets        表格创建 / 工作表查询增删 / v2 单元格读写追加
+  - bitable       base/v3 与 bitable/v1 的数据表 / 记录 CRUD、批量与关键词搜索
+  - wiki          知识库节点创建（docx 节点同时生成空文档）/ 查询 / 子节点列表 / 改标题
+  - 其它          内置注册表（feishu-cli schema）登记的方法：校验必填参数后返回空 data 成功
+
+所有 /open-apis/ 请求都必须带 Authorization: Bearer <任意 token>，与线上一致。
+状态只保存在内存中，进程退出即丢弃。
+
+示例:
+  # 1. 启动 mock 服务
+  feishu-cli mock serve --addr 127.0.0.1:18080 &
+
+  # 2. 把 CLI 指向 mock 服务
+  export FEISHU_BASE_URL=http://127.0.0.1:18080
+  export FEISHU_APP_ID=cli_mock FEISHU_APP_SECRET=mock
+
+  # 3. 现有命令离线运行
+  feishu-cli doc import README.md --title "CI 导入"
+  feishu-cli msg send --receive-id-type chat_id --receive-id oc_ci --text "hello"
+
+  # 4. 断言 / 清空 mock 状态
+  curl -s http://127.0.0.1:18080/mock/state | jq '.messages | length'
+  curl -s -X POST http://127.0.0.1:18080/mock/reset`,
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+}
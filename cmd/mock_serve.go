@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/mock"
+	"github.com/spf13/cobra"
+)
+
+var mockServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "启动离线 mock OpenAPI 服务",
+	Long: `在本机启动 mock OpenAPI 服务（阻塞运行，SIGINT / SIGTERM 退出）。
+
+启动完成后 stderr 输出一行 [mock] ready addr=<url>，脚本可等待该行后再发请求；
+--addr 端口写 0 时由系统分配空闲端口，实际地址以 ready 行为准。
+
+管理接口（无需鉴权）:
+  GET  /mock/state    导出全部内存状态（文档块树 / 文件 / 消息 / 表格 / 多维表格记录、字段与视图 / 知识库节点）
+  POST /mock/reset    清空全部内存状态
+
+示例:
+  feishu-cli mock serve
+  feishu-cli mock serve --addr 127.0.0.1:0 2> mock.log &`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("监听 %s 失败: %w", addr, err)
+		}
+		srv := &http.Server{
+			Handler:           mock.New(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		errOut := cmd.ErrOrStderr()
+		url := "http://" + ln.Addr().String()
+		fmt.Fprintf(errOut, "[mock] ready addr=%s\n", url)
+		fmt.Fprintf(errOut, "[mock] export FEISHU_BASE_URL=%s FEISHU_APP_ID=cli_mock FEISHU_APP_SECRET=mock\n", url)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		serveErr := make(chan error, 1)
+		go func() { serveErr <- srv.Serve(ln) }()
+
+		select {
+		case err := <-serveErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("mock 服务异常退出: %w", err)
+			}
+			return nil
+		case <-ctx.Done():
+		}
+
+		fmt.Fprintln(errOut, "[mock] 正在关闭...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(errOut, "[mock] 关闭失败: %v\n", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	mockCmd.AddCommand(mockServeCmd)
+	mockServeCmd.Flags().String("addr", "127.0.0.1:18080", "监听地址（host:port，端口 0 表示随机）")
+}
@@ -5,7 +5,7 @@ import (
 )
 
 // profileCmd 是 profile 多配置管理的顶层命令。
-// 子命令：add / list / remove / rename / use / migrate / current
+// 子命令：add / list / remove / rename / use / migrate / migrate-secrets / current
 var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "管理多个 profile（如 work / personal）",
@@ -46,6 +46,7 @@ var profileCmd = &cobra.Command{
   feishu-cli profile remove temp --force
   feishu-cli profile current --json
   feishu-cli profile migrate              # 旧布局 → profiles/default/
+  feishu-cli profile migrate-secrets --backend file   # 明文凭证 → 口令加密存储
   feishu-cli --profile work msg send ...`,
 }
 
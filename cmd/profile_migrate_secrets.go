@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/riba2534/feishu-cli/internal/auth"
+	"github.com/riba2534/feishu-cli/internal/profile"
+	"github.com/riba2534/feishu-cli/internal/secretstore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileMigrateSecretsBackend string
+	profileMigrateSecretsName    string
+	profileMigrateSecretsJSON    bool
+)
+
+// secretMigration 是单个配置目录的迁移结果。
+type secretMigration struct {
+	Profile string   `json:"profile"`
+	Dir     string   `json:"dir"`
+	Config  []string `json:"config_fields"`
+	Token   bool     `json:"token"`
+}
+
+var profileMigrateSecretsCmd = &cobra.Command{
+	Use:   "migrate-secrets",
+	Short: "把 config.yaml / token.json 中的明文凭证移入加密存储",
+	Long: `把各 profile（以及旧布局 ~/.feishu-cli/）中的明文凭证移入 secret 存储后端，
+文件中只保留 secret://<backend>/<key> 引用：
+
+  config.yaml  app_secret、user_access_token
+  token.json   access_token、refresh_token
+
+后端:
+  file     口令加密文件 ~/.feishu-cli/secrets.enc（AES-256-GCM），
+           口令取自 FEISHU_SECRET_PASSPHRASE，终端下可交互输入
+  helper   外部凭证助手 FEISHU_SECRET_HELPER，调用约定:
+             <helper> get <key>    stdout 输出明文（不存在时退出码 44）
+             <helper> store <key>  stdin 读入明文
+             <helper> erase <key>  删除
+
+迁移后引用是粘性的：之后 auth login / token 刷新会继续写入同一后端，无需常设
+FEISHU_SECRET_STORE；新建 profile 时设置 FEISHU_SECRET_STORE=<backend> 即直接加密写入。
+已经是引用的字段保持不变，重复执行是安全的。
+
+示例:
+  FEISHU_SECRET_PASSPHRASE=xxx feishu-cli profile migrate-secrets --backend file
+  FEISHU_SECRET_HELPER=/usr/local/bin/feishu-secret feishu-cli profile migrate-secrets --backend helper
+  feishu-cli profile migrate-secrets --backend file --name work --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backend := profileMigrateSecretsBackend
+		if backend == "" {
+			var err error
+			if backend, err = secretstore.DefaultBackend(); err != nil {
+				return err
+			}
+		}
+		if backend == secretstore.BackendPlaintext {
+			return fmt.Errorf("请用 --backend 指定 %s 或 %s（或设置 %s）", secretstore.BackendFile, secretstore.BackendHelper, secretstore.EnvBackend)
+		}
+		root, err := profile.RootDir()
+		if err != nil {
+			return err
+		}
+		if _, err := secretstore.Open(backend, root); err != nil {
+			return err
+		}
+
+		targets, err := secretMigrationTargets(profileMigrateSecretsName)
+		if err != nil {
+			return err
+		}
+		results := make([]secretMigration, 0, len(targets))
+		for _, t := range targets {
+			fields, err := profile.MigrateConfigSecrets(t.Dir, backend)
+			if err != nil {
+				return fmt.Errorf("迁移 %s 的 config.yaml 失败: %w", t.Profile, err)
+			}
+			token, err := auth.MigrateTokenSecrets(filepath.Join(t.Dir, profile.TokenFileName), backend)
+			if err != nil {
+				return fmt.Errorf("迁移 %s 的 token.json 失败: %w", t.Profile, err)
+			}
+			t.Config, t.Token = fields, token
+			results = append(results, t)
+		}
+
+		if profileMigrateSecretsJSON {
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]any{
+				"ok":       true,
+				"backend":  backend,
+				"profiles": results,
+			})
+		}
+		out := cmd.OutOrStdout()
+		moved := 0
+		for _, r := range results {
+			if len(r.Config) == 0 && !r.Token {
+				fmt.Fprintf(out, "  %-16s 无明文凭证\n", r.Profile)
+				continue
+			}
+			items := append([]string{}, r.Config...)
+			if r.Token {
+				items = append(items, "token.json")
+			}
+			moved += len(items)
+			fmt.Fprintf(out, "  %-16s 已迁移 %v\n", r.Profile, items)
+		}
+		fmt.Fprintf(out, "完成：%d 项凭证已移入 %s 后端\n", moved, backend)
+		return nil
+	},
+}
+
+// secretMigrationTargets 返回要迁移的配置目录：指定 name 时只迁移该 profile，
+// 否则为全部 profile 加上仍存在的旧布局根目录。
+func secretMigrationTargets(name string) ([]secretMigration, error) {
+	if name != "" {
+		ok, err := profile.Exists(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", profile.ErrNotFound, name)
+		}
+		dir, err := profile.ProfileDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return []secretMigration{{Profile: name, Dir: dir}}, nil
+	}
+	var targets []secretMigration
+	if profile.HasLegacyLayout() {
+		root, err := profile.RootDir()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, secretMigration{Profile: "(legacy)", Dir: root})
+	}
+	names, err := profile.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range names {
+		dir, err := profile.ProfileDir(n)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, secretMigration{Profile: n, Dir: dir})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("没有找到任何配置（~/.feishu-cli/ 下既无 profile 也无旧布局文件）")
+	}
+	return targets, nil
+}
+
+func init() {
+	profileMigrateSecretsCmd.Flags().StringVar(&profileMigrateSecretsBackend, "backend", "", "目标后端: file | helper（默认取 FEISHU_SECRET_STORE）")
+	profileMigrateSecretsCmd.Flags().StringVar(&profileMigrateSecretsName, "name", "", "只迁移指定 profile（默认全部 profile + 旧布局）")
+	profileMigrateSecretsCmd.Flags().BoolVar(&profileMigrateSecretsJSON, "json", false, "JSON 输出")
+	profileCmd.AddCommand(profileMigrateSecretsCmd)
+}
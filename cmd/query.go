@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/riba2534/feishu-cli/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// queryRowCollector 把 recordExporter 展平后的记录收进内存：多值以逗号连接为文本
+type queryRowCollector struct{ rows [][]any }
+
+func (q *queryRowCollector) header(columns []string) error { return nil }
+
+func (q *queryRowCollector) row(recordID string, values []any) error {
+	row := make([]any, 0, len(values)+1)
+	row = append(row, recordID)
+	for _, v := range values {
+		if list, ok := v.([]string); ok {
+			v = nil
+			if len(list) > 0 {
+				v = exportCellString(list)
+			}
+		}
+		row = append(row, v)
+	}
+	q.rows = append(q.rows, row)
+	return nil
+}
+
+func (q *queryRowCollector) flush() error { return nil }
+func (q *queryRowCollector) close() error { return nil }
+
+// querySourceLoader 按 FROM / JOIN 中的数据源名称读取数据：
+//
+//	base.<base_token>.<table_id>          多维表格数据表的全部记录（列为 record_id + 字段名）
+//	sheet.<spreadsheet_token>.<sheet_id>  电子表格子表的 used range（首行为列名）
+//
+// sheet 的最后一段可写成 "<sheet_id>!A1:D100" 只读取指定区域（含 ! 需用双引号括起来）
+type querySourceLoader struct {
+	userToken string
+	loc       *time.Location
+	retry     client.RetryConfig
+}
+
+func (l *querySourceLoader) load(ref query.TableRef) (*query.Table, error) {
+	name := ref.Key()
+	if len(ref.Parts) != 3 {
+		return nil, fmt.Errorf("无法识别的数据源 %s（应为 base.<base_token>.<table_id> 或 sheet.<spreadsheet_token>.<sheet_id>）", name)
+	}
+	var (
+		t   *query.Table
+		err error
+	)
+	switch strings.ToLower(ref.Parts[0]) {
+	case "base":
+		t, err = l.loadBase(ref.Parts[1], ref.Parts[2])
+	case "sheet":
+		t, err = l.loadSheet(ref.Parts[1], ref.Parts[2])
+	default:
+		return nil, fmt.Errorf("未知的数据源类型 %q（支持 base / sheet）", ref.Parts[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", name, err)
+	}
+	fmt.Fprintf(os.Stderr, "已读取 %s: %d 行\n", name, len(t.Rows))
+	return t, nil
+}
+
+func (l *querySourceLoader) loadBase(baseToken, tableID string) (*query.Table, error) {
+	ex := &recordExporter{
+		call:      newBaseV3Caller(l.userToken),
+		baseToken: baseToken,
+		tableID:   tableID,
+		pageSize:  maxExportPageSize,
+		loc:       l.loc,
+		retry:     l.retry,
+	}
+	if err := ex.prepare(nil); err != nil {
+		return nil, err
+	}
+	var rows queryRowCollector
+	if _, err := ex.run(&rows, &exportCursor{}, nil); err != nil {
+		return nil, err
+	}
+	return &query.Table{Columns: append([]string{"record_id"}, ex.columnNames()...), Rows: rows.rows}, nil
+}
+
+func (l *querySourceLoader) loadSheet(token, sheetID string) (*query.Table, error) {
+	rangeStr := ""
+	if i := strings.Index(sheetID, "!"); i >= 0 {
+		sheetID, rangeStr = sheetID[:i], sheetID[i+1:]
+	}
+	result, err := client.ReadTable(client.Context(), token, sheetID, rangeStr, false, l.userToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Sheets) == 0 {
+		return &query.Table{}, nil
+	}
+	sheet := result.Sheets[0]
+	return &query.Table{Columns: sheet.Columns, Rows: sheet.Data}, nil
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <SQL>",
+	Short: "用 SQL 在本地查询、关联多维表格与电子表格",
+	Long: `把 SQL 中引用的多维表格数据表 / 电子表格读到本地，在内存中执行 SELECT 查询，
+适合跨表关联、分组统计等服务端筛选做不到的分析。数据全部拉取到本地，不修改任何数据。
+
+数据源（写在 FROM / JOIN 中，默认别名为最后一段，建议用 AS 指定短别名）:
+  base.<base_token>.<table_id>            多维表格数据表；列为 record_id 加全部字段名，
+                                          单元格按 record export 的规则展平（人员为姓名、
+                                          关联为主字段值、日期为 "2006-01-02 15:04:05"，多值以逗号连接）
+  sheet.<spreadsheet_token>.<sheet_id>    电子表格子表的 used range，首行为列名
+  sheet.<spreadsheet_token>."<sheet_id>!A1:D100"   只读取指定区域
+
+支持的 SQL:
+  SELECT [DISTINCT] 表达式 [AS 别名], ... | * | t.*
+  FROM 数据源 [AS] 别名
+  [[INNER | LEFT] JOIN 数据源 [AS] 别名 ON 条件] ...
+  [WHERE 条件] [GROUP BY ...] [HAVING 条件] [ORDER BY ... [ASC|DESC]] [LIMIT n [OFFSET m]]
+
+  运算符    = != <> < <= > >= AND OR NOT + - * / % ||
+            LIKE（% 与 _，不区分大小写）IN (...) BETWEEN ... AND ... IS [NOT] NULL
+  聚合      COUNT(*) COUNT([DISTINCT] x) SUM AVG MIN MAX GROUP_CONCAT(x[, 分隔符])
+  函数      LOWER UPPER LENGTH TRIM SUBSTR REPLACE COALESCE IFNULL ROUND ABS
+            CASE WHEN ... THEN ... ELSE ... END、CAST(x AS INTEGER|REAL|TEXT)
+
+  - 字段名含空格、标点或与关键字同名时用双引号括起来："完成时间"、t."order"
+  - 两边都是文本时按文本比较，否则能转成数字就按数字比较；与 NULL 比较结果为 NULL
+  - ORDER BY 可用输出列别名或序号（ORDER BY 2 DESC）
+  - 分组查询中 SELECT / HAVING / ORDER BY 在聚合函数之外只能引用 GROUP BY 的列或表达式
+  - ON 中含「左表列 = 右表列」时用哈希连接，否则逐对比较
+
+输出走通用 --format：json（默认）/ pretty / table / ndjson / csv，table 与 csv 按 SELECT 列顺序输出。
+
+示例:
+  feishu-cli query "SELECT 状态, COUNT(*) AS 数量 FROM base.<bt>.<tid> GROUP BY 状态 ORDER BY 数量 DESC" --format table
+  feishu-cli query "SELECT o.单号, o.金额, s.区域
+    FROM base.<bt>.<tid> AS o
+    LEFT JOIN sheet.<token>.<sheet_id> AS s ON s.客户 = o.客户
+    WHERE o.金额 > 1000" --format csv -o result.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		o, err := output.ParseOptions(cmd)
+		if err != nil {
+			return err
+		}
+		stmt, err := query.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("SQL 解析失败: %w", err)
+		}
+		tz, _ := cmd.Flags().GetString("timezone")
+		loc := time.Local
+		if tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return fmt.Errorf("无效的 --timezone: %w", err)
+			}
+		}
+
+		loader := &querySourceLoader{
+			userToken: resolveOptionalUserTokenWithFallback(cmd),
+			loc:       loc,
+			retry: client.RetryConfig{
+				MaxRetries:       5,
+				RetryOnRateLimit: true,
+				OnRetry: func(attempt int, err error, wait time.Duration) {
+					fmt.Fprintf(os.Stderr, "读取失败，%v 后第 %d 次重试: %v\n", wait.Round(time.Millisecond), attempt, err)
+				},
+			},
+		}
+		result, err := query.Execute(stmt, loader.load)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]map[string]any, len(result.Rows))
+		for i, row := range result.Rows {
+			m := make(map[string]any, len(row))
+			for j, v := range row {
+				m[result.Columns[j]] = v
+			}
+			rows[i] = m
+		}
+		o.Columns = result.Columns
+		return output.Render(o, rows)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.Flags().String("timezone", "", "多维表格日期字段的展平时区（例：Asia/Shanghai，默认本机时区）")
+	queryCmd.Flags().String("user-access-token", "", "User Access Token")
+	output.AddOutputFlags(queryCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/profile"
+	"github.com/riba2534/feishu-cli/internal/ratelimit"
+)
+
+// envRateLimitClasses 以 "docx:write=3,im=50" 形式覆盖 config.yaml 的 rate_limit.classes。
+const envRateLimitClasses = "FEISHU_RATE_LIMIT_CLASSES"
+
+// newRateLimiter 按配置构建跨进程限流器；未启用（rate_limit.enabled / FEISHU_RATE_LIMIT_ENABLED）时返回 nil。
+func newRateLimiter() (*ratelimit.Limiter, error) {
+	cfg := config.Get()
+	if !cfg.RateLimit.Enabled || cfg.AppID == "" {
+		return nil, nil
+	}
+	classes := map[string]float64{}
+	for k, v := range cfg.RateLimit.Classes {
+		classes[k] = v
+	}
+	if env := os.Getenv(envRateLimitClasses); env != "" {
+		override, err := ratelimit.ParseClasses(env)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range override {
+			classes[k] = v
+		}
+	}
+	root, err := profile.RootDir()
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.New(root, cfg.AppID, ratelimit.Config{Default: cfg.RateLimit.Default, Classes: classes}), nil
+}
+
+// setupHTTPClient 为 SDK client 安装访问网络的 HTTP 客户端：启用跨进程限流时替换为限流客户端，
+// 否则保持 SDK 默认。--record / --replay 由随后的 setupCassette 在此之上替换。
+func setupHTTPClient() error {
+	limiter, err := newRateLimiter()
+	if err != nil {
+		return err
+	}
+	if limiter != nil {
+		client.SetHTTPClient(ratelimit.NewClient(limiter, http.DefaultClient))
+	}
+	return nil
+}
+
+// networkHTTPClient 返回真正访问网络的 HTTP 客户端：启用跨进程限流时包一层 limiter。
+// --record 把它作为内层，录制的是限流后的真实往返；--replay 不访问网络，不经过它。
+func networkHTTPClient() (ratelimit.HTTPClient, error) {
+	limiter, err := newRateLimiter()
+	if err != nil || limiter == nil {
+		return http.DefaultClient, err
+	}
+	return ratelimit.NewClient(limiter, http.DefaultClient), nil
+}
@@ -58,10 +58,12 @@ var rootCmd = &cobra.Command{
   markdown  Drive 原生 Markdown 文件 CRUD（.md 整体读写，不转换飞书 docx 块）
   search    搜索操作（消息、应用搜索，需要用户授权）
   event     实时事件订阅（WebSocket 长连接 + daemon 进程模型；list/consume/schema/status/stop）
+  mock      离线 mock OpenAPI 服务（CI / 脚本测试；base_url 指向它即可离线运行现有命令）
   slides    Slides 演示文稿（创建 + 媒体上传）
   okr       OKR 操作（周期列表、进展记录列表与创建）
   schema    本地浏览飞书 OpenAPI 方法（纯本地查询，不需 token；service.resource.method 路径）
   api       通用 OpenAPI 透传调用（feishu-cli api GET/POST /open-apis/...，自动鉴权 + 错误码翻译）
+  query     本地 SQL 查询（关联多维表格数据表与电子表格，过滤 / 分组聚合 / 排序）
   sheet     电子表格（基础读写 + filter-view 创建/列表/删除 + dropdown 数据验证）
   chat      群聊管理（拉人/踢人/改名/成员列表；reaction/pin 等互动）
   profile   多 App 配置切换（add/list/use/current/rename/remove/migrate）
@@ -84,6 +86,10 @@ var rootCmd = &cobra.Command{
   目录 / User Token: --profile > FEISHU_PROFILE > active-profile 指针 > 旧布局
   App 凭证: --bot-app-id/--bot-app-secret > FEISHU_APP_ID/FEISHU_APP_SECRET > 选中目录的 config.yaml
 
+录制与回放（复现问题）:
+  feishu-cli doc export <document_id> --record ./cassette   # 录制 OpenAPI 往返（Token/secret 脱敏）
+  feishu-cli doc export <document_id> --replay ./cassette   # 离线回放，无需凭证与网络
+
 快速开始:
   # 创建文档
   feishu-cli doc create --title "我的文档"
@@ -138,7 +144,10 @@ var rootCmd = &cobra.Command{
 			cfg.Debug = true
 		}
 
-		return nil
+		if err := setupHTTPClient(); err != nil {
+			return err
+		}
+		return setupCassette()
 	},
 }
 
@@ -166,7 +175,7 @@ func shouldSkipConfigInit(cmd *cobra.Command) bool {
 	case "init", "help", "completion", "version", "doctor", "schema":
 		return true
 	}
-	return cmd.Parent() != nil && (cmd.Parent().Name() == "schema" || cmd.Parent().Name() == "profile")
+	return cmd.Parent() != nil && (cmd.Parent() == schemaCmd || cmd.Parent().Name() == "profile" || cmd.Parent().Name() == "mock")
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -191,6 +200,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&botAppIDFlag, "bot-app-id", "", "本次命令使用的 Bot app_id（优先于环境变量和配置文件，不写盘）")
 	rootCmd.PersistentFlags().StringVar(&botAppSecretFlag, "bot-app-secret", "", "本次命令使用的 Bot app_secret（优先于环境变量和配置文件，不写盘；会进入 shell 历史与 ps 输出，共享机器慎用）")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "启用调试模式")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "把本次命令的 OpenAPI 请求/响应录制到目录（Token 与 app_secret 脱敏），用于提交可复现的 bug report")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "从 --record 录制的目录回放响应，离线、确定性地重跑命令")
 	// R1 review fix: RunE 返回 error 时不再打印整页 usage 淹没真错误（11/13 PR 未单独设此 flag → root 统一处理）
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
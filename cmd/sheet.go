@@ -8,7 +8,7 @@ import (
 var sheetCmd = &cobra.Command{
 	Use:   "sheet",
 	Short: "电子表格操作",
-	Long:  "电子表格操作命令组，包括创建、读写、工作表管理、筛选视图管理（filter-view）和下拉菜单设置（dropdown）等功能",
+	Long:  "电子表格操作命令组，包括创建、读写、工作表管理、筛选视图管理（filter-view）和下拉菜单设置（dropdown）、表格比对（diff）与合并写回（patch）等功能",
 }
 
 func init() {
@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var sheetDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "按单元格比较两张电子表格，或电子表格与本地 table-get JSON / CSV",
+	Long: `按单元格比较两份表格数据，报告修改的单元格、新增/删除的行和列。只读取，不修改任何数据。
+
+比较对象（<old> 与 <new> 各写一个）:
+  <spreadsheet_token>:<sheet_id>           电子表格子表的 used range（首行为列名）
+  <spreadsheet_token>:<sheet_id>!A1:D100   只读取指定区域
+  https://xxx.feishu.cn/sheets/<token>?sheet=<sheet_id>
+  table.json                               本地 table-get 输出（取 sheets[0]，range 决定单元格地址）
+  table.csv                                本地 CSV，首行为列名，视为从 A1 开始
+
+匹配规则:
+  - 列按列名匹配，列顺序不同不算差异
+  - 指定 --key 时按 key 列的值匹配行（可重复指定组成复合 key，key 在各侧必须唯一），
+    否则按行序号逐行比较
+  - 单元格按显示文本比较，空单元格与空字符串相等；两侧都是数值或该列为数字列时按数值比较
+    （1200 与 1200.0 相等），文本列中 "00123" 与 "123" 视为不同
+
+输出:
+  默认         文本，每行一处差异：~ 修改单元格（地址取 <old> 一侧）、+/- 新增/删除的行或列
+  --format / --jq  结构化输出（-o json 等价 --format json）
+
+示例:
+  feishu-cli sheet diff shtcnA:0b12 shtcnB:0b12 --key 单号
+  feishu-cli sheet diff shtcnxxx:0b12 shtcnxxx:1c34
+  feishu-cli sheet diff shtcnxxx:0b12 edited.csv --key 单号 --key 月份
+  feishu-cli sheet diff base.json shtcnxxx:0b12 --key 单号 -o json --jq '.summary'
+  feishu-cli sheet diff shtcnxxx:0b12 snapshot.json --exit-code   # 有差异时以非 0 状态退出`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		keys, _ := cmd.Flags().GetStringArray("key")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+		o, structured, err := resolveMarkdownDiffOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
+		oldTable, err := loadSheetTable(args[0], userAccessToken)
+		if err != nil {
+			return err
+		}
+		newTable, err := loadSheetTable(args[1], userAccessToken)
+		if err != nil {
+			return err
+		}
+		result, err := buildSheetDiff(oldTable, newTable, keys)
+		if err != nil {
+			return err
+		}
+
+		if structured {
+			if err := output.Render(o, result); err != nil {
+				return err
+			}
+		} else {
+			printSheetDiff(os.Stdout, result)
+		}
+		if exitCode && !result.Identical {
+			return fmt.Errorf("两侧内容存在 %d 处差异", len(result.Changes))
+		}
+		return nil
+	},
+}
+
+// sheetTable 是参与比较的一张表：首行为列名，其余为数据行
+type sheetTable struct {
+	label   string
+	token   string // 电子表格 token，本地文件为空
+	sheetID string
+	columns []string
+	rows    [][]any
+	dtypes  map[string]string
+	col0    int // 表头首列（0-based）
+	row0    int // 表头所在行（1-based）
+}
+
+// cellAddr 返回第 row 个数据行、第 col 列的 A1 地址
+func (t *sheetTable) cellAddr(col, row int) string {
+	return client.ColumnIndexToLetter(t.col0+col) + strconv.Itoa(t.rowNum(row))
+}
+
+// rowNum 返回第 row 个数据行在表格中的行号（1-based）
+func (t *sheetTable) rowNum(row int) int {
+	return t.row0 + 1 + row
+}
+
+func (t *sheetTable) columnIndex() map[string]int {
+	idx := make(map[string]int, len(t.columns))
+	for i, c := range t.columns {
+		idx[c] = i
+	}
+	return idx
+}
+
+// cell 返回第 row 行 col 列的值，行或列不存在时为 nil
+func (t *sheetTable) cell(row int, col string, idx map[string]int) any {
+	c, ok := idx[col]
+	if !ok || row < 0 || row >= len(t.rows) || c >= len(t.rows[row]) {
+		return nil
+	}
+	return t.rows[row][c]
+}
+
+// loadSheetTable 按比较对象描述读取表格：已存在的本地文件按扩展名解析，否则视为电子表格子表
+func loadSheetTable(spec, userAccessToken string) (*sheetTable, error) {
+	if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+		return readSheetTableFile(spec)
+	}
+	token, sheetID, rangeStr, err := parseSheetSource(spec)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.ReadTable(client.Context(), token, sheetID, rangeStr, false, userAccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", spec, err)
+	}
+	if len(result.Sheets) == 0 {
+		return nil, fmt.Errorf("读取 %s 失败: 未返回数据", spec)
+	}
+	t := sheetTableFromGet(spec, result.Sheets[0])
+	t.token, t.sheetID = token, sheetID
+	return t, nil
+}
+
+// parseSheetSource 解析 <token>:<sheet_id>[!range] 或带 ?sheet= 的电子表格 URL
+func parseSheetSource(spec string) (token, sheetID, rangeStr string, err error) {
+	spec = unescapeSheetRange(spec)
+	if strings.Contains(spec, "://") {
+		if token, err = extractSpreadsheetToken(spec); err != nil {
+			return "", "", "", err
+		}
+		u, _ := url.Parse(spec)
+		if sheetID = u.Query().Get("sheet"); sheetID == "" {
+			return "", "", "", fmt.Errorf("电子表格 URL 缺少 ?sheet=<sheet_id>: %s", spec)
+		}
+		return token, sheetID, "", nil
+	}
+	token, rest, ok := strings.Cut(spec, ":")
+	if !ok || token == "" || rest == "" {
+		return "", "", "", fmt.Errorf("无法识别的比较对象 %q（应为本地 .json/.csv 文件、<spreadsheet_token>:<sheet_id>[!A1:D100] 或带 ?sheet= 的表格 URL）", spec)
+	}
+	sheetID, rangeStr, _ = strings.Cut(rest, "!")
+	return token, sheetID, rangeStr, nil
+}
+
+// readSheetTableFile 读取本地 table-get JSON 或 CSV
+func readSheetTableFile(path string) (*sheetTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(f)
+		dec.UseNumber()
+		var result client.TableGetResult
+		if err := dec.Decode(&result); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败（应为 table-get 输出形状）: %w", path, err)
+		}
+		if len(result.Sheets) != 1 {
+			return nil, fmt.Errorf("%s 须恰好包含 1 个 sheet（当前 %d 个）", path, len(result.Sheets))
+		}
+		return sheetTableFromGet(path, result.Sheets[0]), nil
+	case ".csv":
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+		}
+		t := &sheetTable{label: path, row0: 1}
+		if len(records) == 0 {
+			return t, nil
+		}
+		t.columns = records[0]
+		for _, rec := range records[1:] {
+			row := make([]any, len(t.columns))
+			for i := range row {
+				if i < len(rec) && rec[i] != "" {
+					row[i] = rec[i]
+				}
+			}
+			t.rows = append(t.rows, row)
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("不支持的文件类型 %s（支持 .json / .csv）", path)
+	}
+}
+
+func sheetTableFromGet(label string, s client.TableGetSheet) *sheetTable {
+	col0, row0 := sheetRangeOrigin(s.Range)
+	return &sheetTable{label: label, columns: s.Columns, rows: s.Data, dtypes: s.Dtypes, col0: col0, row0: row0}
+}
+
+// sheetRangeOrigin 取区域左上角单元格，如 "0b12!B3:F20" → (1, 3)；无法解析时为 A1
+func sheetRangeOrigin(rangeStr string) (col0, row0 int) {
+	_, cells := client.ParseSheetRange(rangeStr)
+	start, _, _ := strings.Cut(cells, ":")
+	i := 0
+	for i < len(start) && (start[i] >= 'A' && start[i] <= 'Z' || start[i] >= 'a' && start[i] <= 'z') {
+		i++
+	}
+	row, err := strconv.Atoi(start[i:])
+	if i == 0 || err != nil || row < 1 {
+		return 0, 1
+	}
+	return client.ColumnToIndex(strings.ToUpper(start[:i])), row
+}
+
+// sheetCellText 返回单元格的比较文本：空为 ""，布尔为 TRUE/FALSE
+func sheetCellText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// sheetCellEqual 按文本精确比较；两侧都是数值（而非形似数字的文本），或 numeric（该列为数字列）时按数值比较，
+// 因此文本列中的 "00123" 与 "123" 算作不同
+func sheetCellEqual(a, b any, numeric bool) bool {
+	sa, sb := sheetCellText(a), sheetCellText(b)
+	if sa == sb {
+		return true
+	}
+	if !numeric && !(sheetCellIsNumber(a) && sheetCellIsNumber(b)) {
+		return false
+	}
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(sa), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(sb), 64)
+	return errA == nil && errB == nil && fa == fb
+}
+
+func sheetCellIsNumber(v any) bool {
+	switch v.(type) {
+	case json.Number, float64, int64, int:
+		return true
+	}
+	return false
+}
+
+// sheetNumericColumn 判断列在任一张表中被推断为数字列（dtype float64，CSV 没有 dtype）
+func sheetNumericColumn(col string, tables ...*sheetTable) bool {
+	for _, t := range tables {
+		if t.dtypes[col] == "float64" {
+			return true
+		}
+	}
+	return false
+}
+
+// sheetRowKeys 为每行计算匹配键：指定 key 列时为 key 列值，否则为行序号。
+// 返回每行的键、键到行下标的映射，以及每行 key 列的显示值（按行序号匹配时为 nil）。
+// key 列全为空的行（常见于表尾空行）键为 ""，不进映射也不参与重复检查，任何一侧都匹配不到它
+func sheetRowKeys(t *sheetTable, keys []string) ([]string, map[string]int, [][]string, error) {
+	idx := t.columnIndex()
+	for _, k := range keys {
+		if _, ok := idx[k]; !ok {
+			return nil, nil, nil, fmt.Errorf("%s 中没有 key 列 %q", t.label, k)
+		}
+	}
+	rowKeys := make([]string, len(t.rows))
+	display := make([][]string, len(t.rows))
+	byKey := make(map[string]int, len(t.rows))
+	for i := range t.rows {
+		if len(keys) == 0 {
+			rowKeys[i] = strconv.Itoa(i)
+		} else {
+			vals := make([]string, len(keys))
+			for j, k := range keys {
+				vals[j] = sheetCellText(t.cell(i, k, idx))
+			}
+			display[i] = vals
+			if strings.Join(vals, "") == "" {
+				continue
+			}
+			rowKeys[i] = strings.Join(vals, "\x1f")
+		}
+		if prev, dup := byKey[rowKeys[i]]; dup {
+			return nil, nil, nil, fmt.Errorf("%s 第 %d 行与第 %d 行的 key 重复: %s",
+				t.label, t.rowNum(prev), t.rowNum(i), strings.Join(display[i], ", "))
+		}
+		byKey[rowKeys[i]] = i
+	}
+	if len(keys) == 0 {
+		display = nil
+	}
+	return rowKeys, byKey, display, nil
+}
+
+// sheetDiffChange 是一处差异
+type sheetDiffChange struct {
+	Op     string   `json:"op"`            // modified / row_added / row_removed / column_added / column_removed
+	Key    []string `json:"key,omitempty"` // 行的 key 列值（指定 --key 时）
+	OldRow int      `json:"old_row,omitempty"`
+	NewRow int      `json:"new_row,omitempty"`
+	Column string   `json:"column,omitempty"`
+	Cell   string   `json:"cell,omitempty"` // 修改单元格在 <old> 一侧的地址
+	Old    any      `json:"old,omitempty"`
+	New    any      `json:"new,omitempty"`
+	Values []any    `json:"values,omitempty"` // 新增/删除行的整行值（按该侧列顺序）
+}
+
+type sheetDiffSummary struct {
+	ModifiedCells  int `json:"modified_cells"`
+	AddedRows      int `json:"added_rows"`
+	RemovedRows    int `json:"removed_rows"`
+	AddedColumns   int `json:"added_columns"`
+	RemovedColumns int `json:"removed_columns"`
+}
+
+// sheetDiffResult 是 sheet diff 的完整结果
+type sheetDiffResult struct {
+	Old       string            `json:"old"`
+	New       string            `json:"new"`
+	Key       []string          `json:"key,omitempty"`
+	Identical bool              `json:"identical"`
+	Summary   sheetDiffSummary  `json:"summary"`
+	Changes   []sheetDiffChange `json:"changes"`
+}
+
+// buildSheetDiff 比较两张表：先报告列的增删，再按 <new> 的行序报告修改与新增的行，最后是删除的行
+func buildSheetDiff(oldT, newT *sheetTable, keys []string) (*sheetDiffResult, error) {
+	_, oldByKey, oldDisplay, err := sheetRowKeys(oldT, keys)
+	if err != nil {
+		return nil, err
+	}
+	newKeys, _, newDisplay, err := sheetRowKeys(newT, keys)
+	if err != nil {
+		return nil, err
+	}
+	oldIdx, newIdx := oldT.columnIndex(), newT.columnIndex()
+
+	result := &sheetDiffResult{Old: oldT.label, New: newT.label, Key: keys, Changes: []sheetDiffChange{}}
+	add := func(c sheetDiffChange) { result.Changes = append(result.Changes, c) }
+	for _, c := range oldT.columns {
+		if _, ok := newIdx[c]; !ok {
+			add(sheetDiffChange{Op: "column_removed", Column: c})
+			result.Summary.RemovedColumns++
+		}
+	}
+	var common []string
+	for _, c := range newT.columns {
+		if _, ok := oldIdx[c]; ok {
+			common = append(common, c)
+		} else {
+			add(sheetDiffChange{Op: "column_added", Column: c})
+			result.Summary.AddedColumns++
+		}
+	}
+
+	matched := make([]bool, len(oldT.rows))
+	for i := range newT.rows {
+		var key []string
+		if newDisplay != nil {
+			key = newDisplay[i]
+		}
+		oi, ok := oldByKey[newKeys[i]]
+		if !ok {
+			add(sheetDiffChange{Op: "row_added", Key: key, NewRow: newT.rowNum(i), Values: newT.rows[i]})
+			result.Summary.AddedRows++
+			continue
+		}
+		matched[oi] = true
+		for _, c := range common {
+			ov, nv := oldT.cell(oi, c, oldIdx), newT.cell(i, c, newIdx)
+			if sheetCellEqual(ov, nv, sheetNumericColumn(c, oldT, newT)) {
+				continue
+			}
+			add(sheetDiffChange{
+				Op: "modified", Key: key, OldRow: oldT.rowNum(oi), NewRow: newT.rowNum(i),
+				Column: c, Cell: oldT.cellAddr(oldIdx[c], oi), Old: ov, New: nv,
+			})
+			result.Summary.ModifiedCells++
+		}
+	}
+	for i := range oldT.rows {
+		if matched[i] {
+			continue
+		}
+		var key []string
+		if oldDisplay != nil {
+			key = oldDisplay[i]
+		}
+		add(sheetDiffChange{Op: "row_removed", Key: key, OldRow: oldT.rowNum(i), Values: oldT.rows[i]})
+		result.Summary.RemovedRows++
+	}
+	result.Identical = len(result.Changes) == 0
+	return result, nil
+}
+
+// sheetValueLabel 返回单元格的显示文本，空单元格显示为（空）
+func sheetValueLabel(v any) string {
+	if s := sheetCellText(v); s != "" {
+		return s
+	}
+	return "（空）"
+}
+
+func sheetRowLabel(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = sheetCellText(v)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func sheetKeyLabel(key []string) string {
+	if len(key) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(key, ", ") + "]"
+}
+
+// printSheetDiff 逐行输出差异
+func printSheetDiff(w io.Writer, r *sheetDiffResult) {
+	if r.Identical {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", r.Old, r.New)
+	for _, c := range r.Changes {
+		switch c.Op {
+		case "column_removed":
+			fmt.Fprintf(w, "- 列 %s\n", c.Column)
+		case "column_added":
+			fmt.Fprintf(w, "+ 列 %s\n", c.Column)
+		case "modified":
+			fmt.Fprintf(w, "~ %s%s %s: %s → %s\n", c.Cell, sheetKeyLabel(c.Key), c.Column, sheetValueLabel(c.Old), sheetValueLabel(c.New))
+		case "row_added":
+			fmt.Fprintf(w, "+ 新第 %d 行%s: %s\n", c.NewRow, sheetKeyLabel(c.Key), sheetRowLabel(c.Values))
+		case "row_removed":
+			fmt.Fprintf(w, "- 旧第 %d 行%s: %s\n", c.OldRow, sheetKeyLabel(c.Key), sheetRowLabel(c.Values))
+		}
+	}
+	s := r.Summary
+	fmt.Fprintf(w, "共 %d 处差异：修改单元格 %d，新增行 %d，删除行 %d，新增列 %d，删除列 %d\n",
+		len(r.Changes), s.ModifiedCells, s.AddedRows, s.RemovedRows, s.AddedColumns, s.RemovedColumns)
+}
+
+func init() {
+	sheetCmd.AddCommand(sheetDiffCmd)
+	sheetDiffCmd.Flags().StringArray("key", nil, "按该列的值匹配行（可重复，组成复合 key；缺省按行序号匹配）")
+	sheetDiffCmd.Flags().Bool("exit-code", false, "有差异时以非 0 状态退出")
+	sheetDiffCmd.Flags().StringP("output", "o", "", "[兼容] -o json 等价 --format json；缺省输出文本")
+	output.AddFormatFlags(sheetDiffCmd)
+	sheetDiffCmd.Flags().String("user-access-token", "", "User Access Token")
+}
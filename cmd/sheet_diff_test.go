@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSheetDiffByKey(t *testing.T) {
+	oldT := &sheetTable{
+		label: "old", columns: []string{"单号", "金额", "备注"}, row0: 1,
+		dtypes: map[string]string{"金额": "float64"}, // 数字列：CSV 一侧的 "100.0" 与 100 相等
+		rows: [][]any{
+			{"A1", json.Number("100"), "x"},
+			{"A2", json.Number("200"), nil},
+			{"A3", json.Number("300"), "y"},
+		},
+	}
+	newT := &sheetTable{
+		label: "new.csv", columns: []string{"金额", "单号", "负责人"}, row0: 1,
+		rows: [][]any{
+			{"250", "A2", "张三"},
+			{"100.0", "A1", nil},
+			{"50", "A4", nil},
+		},
+	}
+	r, err := buildSheetDiff(oldT, newT, []string{"单号"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sheetDiffSummary{ModifiedCells: 1, AddedRows: 1, RemovedRows: 1, AddedColumns: 1, RemovedColumns: 1}
+	if r.Summary != want || r.Identical {
+		t.Fatalf("summary = %+v", r.Summary)
+	}
+	var ops []string
+	for _, c := range r.Changes {
+		ops = append(ops, c.Op+":"+c.Cell+":"+c.Column+":"+strings.Join(c.Key, ","))
+	}
+	wantOps := []string{"column_removed::备注:", "column_added::负责人:", "modified:B3:金额:A2", "row_added:::A4", "row_removed:::A3"}
+	if !reflect.DeepEqual(ops, wantOps) {
+		t.Errorf("changes = %q", ops)
+	}
+	if c := r.Changes[2]; c.OldRow != 3 || c.NewRow != 2 || sheetCellText(c.Old) != "200" || c.New != "250" {
+		t.Errorf("modified = %+v", c)
+	}
+}
+
+func TestSheetCellEqual(t *testing.T) {
+	cases := []struct {
+		a, b    any
+		numeric bool
+		want    bool
+	}{
+		{json.Number("100"), 100.0, false, true},
+		{json.Number("1200"), "1200.0", true, true},
+		{"1200", "1200.0", false, false}, // 文本列按文本比较
+		{"00123", "123", false, false},
+		{"00123", json.Number("123"), false, false},
+		{"abc", "abc", false, true},
+		{nil, "", false, true},
+	}
+	for _, tc := range cases {
+		if got := sheetCellEqual(tc.a, tc.b, tc.numeric); got != tc.want {
+			t.Errorf("sheetCellEqual(%#v, %#v, %v) = %v, want %v", tc.a, tc.b, tc.numeric, got, tc.want)
+		}
+	}
+}
+
+func TestBuildSheetDiffByPosition(t *testing.T) {
+	oldT := &sheetTable{label: "a", columns: []string{"x", "y"}, col0: 1, row0: 3, rows: [][]any{{"1", true}, {"2", false}}}
+	newT := &sheetTable{label: "b", columns: []string{"x", "y"}, row0: 1, rows: [][]any{{"1", "TRUE"}}}
+	r, err := buildSheetDiff(oldT, newT, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Changes) != 1 || r.Changes[0].Op != "row_removed" || r.Changes[0].OldRow != 5 {
+		t.Errorf("changes = %+v", r.Changes)
+	}
+
+	newT.rows[0][0] = "9"
+	r, _ = buildSheetDiff(oldT, newT, nil)
+	if r.Changes[0].Cell != "B4" {
+		t.Errorf("cell = %s, want B4", r.Changes[0].Cell)
+	}
+
+	identical, _ := buildSheetDiff(oldT, oldT, nil)
+	if !identical.Identical {
+		t.Errorf("identical = %+v", identical)
+	}
+}
+
+func TestSheetRowKeysErrors(t *testing.T) {
+	tb := &sheetTable{label: "t", columns: []string{"k", "v"}, row0: 1, rows: [][]any{{"a", "1"}, {"a", "2"}}}
+	if _, _, _, err := sheetRowKeys(tb, []string{"k"}); err == nil || !strings.Contains(err.Error(), "第 2 行与第 3 行的 key 重复") {
+		t.Errorf("err = %v", err)
+	}
+	if _, _, _, err := sheetRowKeys(tb, []string{"nope"}); err == nil || !strings.Contains(err.Error(), "没有 key 列") {
+		t.Errorf("err = %v", err)
+	}
+	// 复合 key 可区分
+	if _, _, _, err := sheetRowKeys(tb, []string{"k", "v"}); err != nil {
+		t.Errorf("composite key: %v", err)
+	}
+	// key 为空的行不算重复，也不进映射
+	blank := &sheetTable{label: "t", columns: []string{"k", "v"}, row0: 1, rows: [][]any{{"a", "1"}, {"", "2"}, {nil, nil}}}
+	rowKeys, byKey, _, err := sheetRowKeys(blank, []string{"k"})
+	if err != nil || rowKeys[1] != "" || rowKeys[2] != "" || len(byKey) != 1 {
+		t.Errorf("blank keys = %q %v %v", rowKeys, byKey, err)
+	}
+}
+
+func TestParseSheetSource(t *testing.T) {
+	cases := []struct {
+		in, token, sheet, rng string
+	}{
+		{"shtA:0b12", "shtA", "0b12", ""},
+		{`shtA:0b12\!A1:D10`, "shtA", "0b12", "A1:D10"},
+		{"https://x.feishu.cn/sheets/shtB?sheet=1c34", "shtB", "1c34", ""},
+	}
+	for _, tc := range cases {
+		token, sheet, rng, err := parseSheetSource(tc.in)
+		if err != nil || token != tc.token || sheet != tc.sheet || rng != tc.rng {
+			t.Errorf("parseSheetSource(%q) = %q %q %q %v", tc.in, token, sheet, rng, err)
+		}
+	}
+	for _, bad := range []string{"shtA", "missing.csv", "https://x.feishu.cn/sheets/shtB"} {
+		if _, _, _, err := parseSheetSource(bad); err == nil {
+			t.Errorf("parseSheetSource(%q) expected error", bad)
+		}
+	}
+}
+
+func TestSheetRangeOrigin(t *testing.T) {
+	cases := map[string][2]int{
+		"0b12!B3:F20": {1, 3},
+		"A1:D4":       {0, 1},
+		"0b12!AA10":   {26, 10},
+		"":            {0, 1},
+		"0b12":        {0, 1},
+	}
+	for in, want := range cases {
+		if c, r := sheetRangeOrigin(in); c != want[0] || r != want[1] {
+			t.Errorf("sheetRangeOrigin(%q) = %d, %d", in, c, r)
+		}
+	}
+}
+
+func TestReadSheetTableFile(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "t.json")
+	os.WriteFile(jsonPath, []byte(`{"sheets":[{"name":"0b12","range":"0b12!C2:D4","columns":["id","amount"],"data":[["a",1.50],["b",null]],"dtypes":{"amount":"float64"}}]}`), 0o644)
+	tb, err := readSheetTableFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.col0 != 2 || tb.row0 != 2 || tb.cellAddr(1, 1) != "D4" || tb.rows[0][1] != json.Number("1.50") || tb.dtypes["amount"] != "float64" {
+		t.Errorf("json table = %+v", tb)
+	}
+
+	csvPath := filepath.Join(dir, "t.csv")
+	os.WriteFile(csvPath, []byte("id,amount\na,1.5\nb\n"), 0o644)
+	tb, err = readSheetTableFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tb.rows, [][]any{{"a", "1.5"}, {"b", nil}}) || tb.cellAddr(0, 0) != "A2" {
+		t.Errorf("csv rows = %v", tb.rows)
+	}
+
+	txtPath := filepath.Join(dir, "t.txt")
+	os.WriteFile(txtPath, []byte("x"), 0o644)
+	if _, err := readSheetTableFile(txtPath); err == nil {
+		t.Error("expected unsupported file type error")
+	}
+}
+
+func TestPrintSheetDiff(t *testing.T) {
+	r := &sheetDiffResult{Old: "a", New: "b", Changes: []sheetDiffChange{
+		{Op: "modified", Key: []string{"A1"}, Cell: "B2", Column: "金额", Old: json.Number("1"), New: nil},
+		{Op: "row_added", NewRow: 5, Values: []any{"x", json.Number("2")}},
+	}, Summary: sheetDiffSummary{ModifiedCells: 1, AddedRows: 1}}
+	var sb strings.Builder
+	printSheetDiff(&sb, r)
+	for _, want := range []string{"--- a\n+++ b\n", "~ B2 [A1] 金额: 1 → （空）\n", "+ 新第 5 行: x | 2\n", "共 2 处差异"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, sb.String())
+		}
+	}
+}
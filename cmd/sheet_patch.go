@@ -0,0 +1,533 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+	"github.com/riba2534/feishu-cli/internal/config"
+	"github.com/riba2534/feishu-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var sheetPatchCmd = &cobra.Command{
+	Use:   "patch <spreadsheet_token|url> <sheet_id>",
+	Short: "把本地 table-get JSON / CSV 的改动只写回变化的单元格（支持三方合并）",
+	Long: `比较本地文件与电子表格，只把有变化的单元格和新增的行写回（V3 batch_update），
+不整表覆盖。适合离线编辑表格副本后合并回去。
+
+两种模式:
+  二方（缺省）  以本地文件为准：远端与本地不同的单元格改为本地值，本地新增的行追加到表尾
+  三方 --base   base 为离线编辑前的快照（通常是当时 table-get 的输出）。只写本地相对 base 的改动：
+                  - 远端仍等于 base 的单元格写入本地值
+                  - 远端已等于本地值的单元格跳过
+                  - 远端也改过且与本地不同的单元格记为冲突，不写入（--force 以本地值覆盖）
+                远端已删除的行上的本地改动同样记为冲突
+
+行按 --key 列的值匹配（可重复指定组成复合 key），缺省按行序号匹配；按行序号匹配时本地新增的行
+一律追加到远端表尾。指定 --key 时，本地新增的 key 若远端已存在且内容不同，记为冲突。
+本地删除的行、新增或删除的列只报告，不写入（行列结构变化请用 add-rows / delete-rows 等命令处理）。
+
+写入规则同 table-put：数字写数值，datetime 列的 ISO 日期写 Excel 序列号，其余写文本；
+CSV 中的文本按远端列类型转换（远端为数字列且文本可解析为数字时写数值）。
+追加的行写在远端数据末行之后，不自动扩容：行数不足时先用 sheet add-rows 预扩容。
+指定 --range 时追加写在区域末行之后；区域下方还有数据时不追加，记为冲突。
+指定 --key 时 key 列全为空的行（如表尾空行）不参与匹配，本地这类行有内容时报告为未写入。
+
+存在冲突时，其余改动照常写入，命令以非 0 状态退出。建议先加 --dry-run 查看计划。
+
+输出:
+  默认             文本计划：~ 更新单元格、+ 追加行、! 冲突、· 未写入的变化
+  --format / --jq  结构化输出
+
+示例:
+  feishu-cli sheet table-get shtcnxxx 0b12 > base.json
+  cp base.json edited.json   # 离线编辑 edited.json
+  feishu-cli sheet patch shtcnxxx 0b12 --file edited.json --base base.json --key 单号 --dry-run
+  feishu-cli sheet patch shtcnxxx 0b12 --file edited.json --base base.json --key 单号
+  feishu-cli sheet patch shtcnxxx 0b12 --file edited.csv --key 单号 --format json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		token, err := extractSpreadsheetToken(args[0])
+		if err != nil {
+			return err
+		}
+		sheetID := args[1]
+		filePath, _ := cmd.Flags().GetString("file")
+		basePath, _ := cmd.Flags().GetString("base")
+		keys, _ := cmd.Flags().GetStringArray("key")
+		rangeStr, _ := cmd.Flags().GetString("range")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		userIDType, _ := cmd.Flags().GetString("user-id-type")
+		o, structured, err := resolveMarkdownDiffOutput(cmd)
+		if err != nil {
+			return err
+		}
+
+		local, err := readSheetTableFile(filePath)
+		if err != nil {
+			return err
+		}
+		var base *sheetTable
+		if basePath != "" {
+			if base, err = readSheetTableFile(basePath); err != nil {
+				return err
+			}
+		}
+		userAccessToken := resolveOptionalUserTokenWithFallback(cmd)
+		result, err := client.ReadTable(client.Context(), token, sheetID, unescapeSheetRange(rangeStr), false, userAccessToken)
+		if err != nil {
+			return err
+		}
+		if len(result.Sheets) == 0 {
+			return fmt.Errorf("读取 sheet %s 失败: 未返回数据", sheetID)
+		}
+		remote := sheetTableFromGet(sheetID, result.Sheets[0])
+		if len(remote.columns) == 0 {
+			return fmt.Errorf("sheet %s 为空，请先用 table-put 写入整表", sheetID)
+		}
+		if base == nil {
+			base = remote
+		}
+
+		ops, err := planSheetPatch(base, local, remote, keys, force)
+		if err != nil {
+			return err
+		}
+		if rangeStr != "" && summarizeSheetPatch(ops).Appends > 0 {
+			// --range 只读了部分区域，区域下方可能还有数据，追加前按整张子表确认末行
+			lastRow, err := sheetLastDataRow(token, sheetID, userAccessToken)
+			if err != nil {
+				return err
+			}
+			blockSheetPatchAppends(ops, lastRow)
+		}
+		summary := summarizeSheetPatch(ops)
+		if !structured {
+			printSheetPatch(os.Stdout, ops, summary)
+		}
+
+		var written int
+		if !dryRun {
+			write := func(ranges []*client.ValueRangeV3) error {
+				return client.WriteCellsV3(client.Context(), token, sheetID, ranges, userIDType, userAccessToken)
+			}
+			if written, err = applySheetPatch(remote, local, sheetID, ops, write); err != nil {
+				return err
+			}
+		}
+		if structured {
+			if err := output.Render(o, map[string]any{
+				"spreadsheet_token": token,
+				"sheet_id":          sheetID,
+				"dry_run":           dryRun,
+				"summary":           summary,
+				"written_cells":     written,
+				"operations":        ops,
+			}); err != nil {
+				return err
+			}
+		} else if written > 0 {
+			fmt.Printf("已写入 %d 个单元格\n", written)
+		}
+		if summary.Conflicts > 0 {
+			return fmt.Errorf("存在 %d 处冲突未写入（确认以本地为准可加 --force）", summary.Conflicts)
+		}
+		return nil
+	},
+}
+
+// sheetPatchOp 是 patch 计划中的一项
+type sheetPatchOp struct {
+	Action string   `json:"action"`           // update / append / conflict / skip
+	Key    []string `json:"key,omitempty"`    // 行的 key 列值（指定 --key 时）
+	Row    int      `json:"row,omitempty"`    // 远端行号；append 为写入的行号
+	Column string   `json:"column,omitempty"` // 单元格或列级变化所在的列
+	Cell   string   `json:"cell,omitempty"`
+	Base   any      `json:"base,omitempty"`
+	Remote any      `json:"remote,omitempty"`
+	Local  any      `json:"local,omitempty"`
+	Values []any    `json:"values,omitempty"` // append 行按远端列顺序的值
+	Reason string   `json:"reason,omitempty"`
+}
+
+type sheetPatchSummary struct {
+	Updates   int `json:"updates"`
+	Appends   int `json:"appends"`
+	Conflicts int `json:"conflicts"`
+	Skipped   int `json:"skipped"`
+}
+
+func summarizeSheetPatch(ops []sheetPatchOp) sheetPatchSummary {
+	var s sheetPatchSummary
+	for _, op := range ops {
+		switch op.Action {
+		case "update":
+			s.Updates++
+		case "append":
+			s.Appends++
+		case "conflict":
+			s.Conflicts++
+		default:
+			s.Skipped++
+		}
+	}
+	return s
+}
+
+// planSheetPatch 计算把 local 相对 base 的改动合并到 remote 所需的操作。
+// 二方模式下 base 即 remote，本地与远端的所有差异都视为本地改动
+func planSheetPatch(base, local, remote *sheetTable, keys []string, force bool) ([]sheetPatchOp, error) {
+	baseKeys, baseByKey, baseDisplay, err := sheetRowKeys(base, keys)
+	if err != nil {
+		return nil, err
+	}
+	localKeys, localByKey, localDisplay, err := sheetRowKeys(local, keys)
+	if err != nil {
+		return nil, err
+	}
+	_, remoteByKey, _, err := sheetRowKeys(remote, keys)
+	if err != nil {
+		return nil, err
+	}
+	baseIdx, localIdx, remoteIdx := base.columnIndex(), local.columnIndex(), remote.columnIndex()
+
+	var ops []sheetPatchOp
+	// 只合并远端存在的列；本地新增/删除的列只报告
+	var columns []string
+	numeric := map[string]bool{}
+	for _, c := range local.columns {
+		if _, ok := remoteIdx[c]; ok {
+			columns = append(columns, c)
+			numeric[c] = sheetNumericColumn(c, base, local, remote)
+		} else {
+			ops = append(ops, sheetPatchOp{Action: "skip", Column: c, Reason: "远端没有该列，未写入"})
+		}
+	}
+	for _, c := range base.columns {
+		_, inLocal := localIdx[c]
+		_, inRemote := remoteIdx[c]
+		if !inLocal && inRemote {
+			ops = append(ops, sheetPatchOp{Action: "skip", Column: c, Reason: "本地没有该列，未应用"})
+		}
+	}
+
+	nextRow := len(remote.rows)
+	for i := range local.rows {
+		var key []string
+		if localDisplay != nil {
+			key = localDisplay[i]
+		}
+		if len(keys) > 0 && localKeys[i] == "" {
+			if !sheetRowEmpty(local.rows[i]) {
+				ops = append(ops, sheetPatchOp{Action: "skip", Reason: fmt.Sprintf("本地第 %d 行 key 为空，无法匹配，未写入", local.rowNum(i))})
+			}
+			continue
+		}
+		bi, inBase := baseByKey[localKeys[i]]
+		ri, inRemote := remoteByKey[localKeys[i]]
+		switch {
+		case !inBase && (len(keys) == 0 || !inRemote):
+			// 本地新增的行：按远端列顺序追加到表尾
+			values := make([]any, len(remote.columns))
+			for j, c := range remote.columns {
+				values[j] = local.cell(i, c, localIdx)
+			}
+			ops = append(ops, sheetPatchOp{Action: "append", Key: key, Row: remote.rowNum(nextRow), Values: values})
+			nextRow++
+		case !inBase:
+			// 本地新增的 key 远端也已新增
+			for _, c := range columns {
+				lv, rv := local.cell(i, c, localIdx), remote.cell(ri, c, remoteIdx)
+				if sheetCellEqual(lv, rv, numeric[c]) {
+					continue
+				}
+				op := sheetPatchOp{Key: key, Row: remote.rowNum(ri), Column: c, Cell: remote.cellAddr(remoteIdx[c], ri), Remote: rv, Local: lv}
+				op.Action, op.Reason = "conflict", "本地与远端都新增了该 key"
+				if force {
+					op.Action, op.Reason = "update", "覆盖远端新增的行"
+				}
+				ops = append(ops, op)
+			}
+		default:
+			for _, c := range columns {
+				lv, bv := local.cell(i, c, localIdx), base.cell(bi, c, baseIdx)
+				if sheetCellEqual(lv, bv, numeric[c]) {
+					continue
+				}
+				if !inRemote {
+					reason := fmt.Sprintf("远端已删除该行（本地第 %d 行）", local.rowNum(i))
+					ops = append(ops, sheetPatchOp{Action: "conflict", Key: key, Column: c, Base: bv, Local: lv, Reason: reason})
+					continue
+				}
+				rv := remote.cell(ri, c, remoteIdx)
+				if sheetCellEqual(rv, lv, numeric[c]) {
+					continue
+				}
+				op := sheetPatchOp{Action: "update", Key: key, Row: remote.rowNum(ri), Column: c, Cell: remote.cellAddr(remoteIdx[c], ri), Base: bv, Remote: rv, Local: lv}
+				if base != remote && !sheetCellEqual(rv, bv, numeric[c]) {
+					op.Action, op.Reason = "conflict", "远端已修改"
+					if force {
+						op.Action, op.Reason = "update", "覆盖远端修改"
+					}
+				}
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	// 本地删除的行（远端仍存在）只报告
+	for i, k := range baseKeys {
+		if _, ok := localByKey[k]; ok {
+			continue
+		}
+		if ri, ok := remoteByKey[k]; ok {
+			var key []string
+			if baseDisplay != nil {
+				key = baseDisplay[i]
+			}
+			ops = append(ops, sheetPatchOp{Action: "skip", Key: key, Row: remote.rowNum(ri), Reason: "本地删除了该行，未应用"})
+		}
+	}
+	return ops, nil
+}
+
+// sheetLastDataRow 读取整张子表，返回最后一个非空行的行号；子表为空时为 0
+func sheetLastDataRow(token, sheetID, userAccessToken string) (int, error) {
+	result, err := client.ReadTable(client.Context(), token, sheetID, "", true, userAccessToken)
+	if err != nil {
+		return 0, fmt.Errorf("读取 sheet %s 末行失败: %w", sheetID, err)
+	}
+	if len(result.Sheets) == 0 || len(result.Sheets[0].Data) == 0 {
+		return 0, nil
+	}
+	_, row0 := sheetRangeOrigin(result.Sheets[0].Range)
+	return row0 + len(result.Sheets[0].Data) - 1, nil
+}
+
+// blockSheetPatchAppends 追加的首行不在 lastRow 之后时（--range 下方还有数据），
+// 把全部追加改记为冲突，避免覆盖区域下方的数据
+func blockSheetPatchAppends(ops []sheetPatchOp, lastRow int) {
+	for i := range ops {
+		op := &ops[i]
+		if op.Action != "append" {
+			continue
+		}
+		if op.Row > lastRow {
+			return
+		}
+		op.Action = "conflict"
+		op.Reason = fmt.Sprintf("--range 下方第 %d 行还有数据，追加会覆盖；请扩大 --range 使其包含全部数据行", lastRow)
+	}
+}
+
+// sheetRowEmpty 判断一行是否所有单元格都为空
+func sheetRowEmpty(values []any) bool {
+	for _, v := range values {
+		if sheetCellText(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sheetPatchMaxCells 单次 batch_update 的单元格上限
+const sheetPatchMaxCells = 5000
+
+// applySheetPatch 把计划中的 update 与 append 写入远端：同一行相邻列的更新合并为一个区域，
+// 追加的行合并为一个矩形区域，按单元格上限分批提交。返回写入的单元格数
+func applySheetPatch(remote, local *sheetTable, sheetID string, ops []sheetPatchOp, write func([]*client.ValueRangeV3) error) (int, error) {
+	dtypes := make(map[string]string, len(remote.columns))
+	for _, c := range remote.columns {
+		dtypes[c] = remote.dtypes[c]
+		if d := local.dtypes[c]; d != "" {
+			dtypes[c] = d
+		}
+	}
+	remoteIdx := remote.columnIndex()
+
+	type cellWrite struct {
+		row, col int
+		cell     *client.CellElement
+	}
+	var updates []cellWrite
+	var appendRows [][]*client.CellElement
+	appendStart := 0
+	for _, op := range ops {
+		switch op.Action {
+		case "update":
+			updates = append(updates, cellWrite{op.Row, remoteIdx[op.Column], sheetPatchCell(dtypes[op.Column], op.Local)})
+		case "append":
+			if appendRows == nil {
+				appendStart = op.Row
+			}
+			row := make([]*client.CellElement, len(op.Values))
+			for j, v := range op.Values {
+				row[j] = sheetPatchCell(dtypes[remote.columns[j]], v)
+			}
+			appendRows = append(appendRows, row)
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].row != updates[j].row {
+			return updates[i].row < updates[j].row
+		}
+		return updates[i].col < updates[j].col
+	})
+
+	var ranges []*client.ValueRangeV3
+	var pending, written int
+	flush := func() error {
+		if len(ranges) == 0 {
+			return nil
+		}
+		if err := write(ranges); err != nil {
+			return fmt.Errorf("写入单元格失败: %w", err)
+		}
+		written += pending
+		ranges, pending = nil, 0
+		return nil
+	}
+	// addRange 把从 (col, row) 开始的矩形区域加入当前批次，超出单元格上限时先提交
+	addRange := func(col, row int, values [][]*client.CellElement) error {
+		cols := len(values[0])
+		if pending+cols*len(values) > sheetPatchMaxCells {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		first, last := client.ColumnIndexToLetter(remote.col0+col), client.ColumnIndexToLetter(remote.col0+col+cols-1)
+		vr := &client.ValueRangeV3{Range: fmt.Sprintf("%s!%s%d:%s%d", sheetID, first, row, last, row+len(values)-1)}
+		for _, r := range values {
+			cells := make([][]*client.CellElement, len(r))
+			for j, c := range r {
+				cells[j] = []*client.CellElement{c}
+			}
+			vr.Values = append(vr.Values, cells)
+		}
+		ranges = append(ranges, vr)
+		pending += cols * len(values)
+		return nil
+	}
+
+	for i := 0; i < len(updates); {
+		j := i + 1
+		for j < len(updates) && updates[j].row == updates[i].row && updates[j].col == updates[j-1].col+1 {
+			j++
+		}
+		row := make([]*client.CellElement, 0, j-i)
+		for _, u := range updates[i:j] {
+			row = append(row, u.cell)
+		}
+		if err := addRange(updates[i].col, updates[i].row, [][]*client.CellElement{row}); err != nil {
+			return written, err
+		}
+		i = j
+	}
+	if len(appendRows) > 0 {
+		batch := max(sheetPatchMaxCells/len(remote.columns), 1)
+		for start := 0; start < len(appendRows); start += batch {
+			end := min(start+batch, len(appendRows))
+			if err := addRange(0, appendStart+start, appendRows[start:end]); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, flush()
+}
+
+// sheetPatchCell 按值与列类型构造 V3 单元格元素：数字写数值、datetime 列的 ISO 日期写序列号，
+// 文本在数字列中能解析为数字时写数值，其余写文本；空值写空文本元素
+func sheetPatchCell(dtype string, v any) *client.CellElement {
+	text := sheetCellText(v)
+	textCell := &client.CellElement{Type: "text", Text: &client.TextElement{Text: text}}
+	if text == "" {
+		return textCell
+	}
+	col := client.TableColSpec{Type: client.TableColTypeString}
+	var raw json.RawMessage
+	switch v.(type) {
+	case json.Number, float64:
+		col.Type, raw = client.TableColTypeNumber, json.RawMessage(text)
+	case string:
+		switch client.ColTypeForDtype(dtype) {
+		case client.TableColTypeNumber:
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				col.Type, raw = client.TableColTypeNumber, json.RawMessage(text)
+			}
+		case client.TableColTypeDate:
+			col.Type = client.TableColTypeDate
+			raw, _ = json.Marshal(text)
+		}
+	}
+	if raw == nil {
+		return textCell
+	}
+	if cell, err := client.BuildTypedCell(col, raw); err == nil {
+		return cell
+	}
+	return textCell
+}
+
+// sheetPatchSymbols 是计划项在文本输出中的前缀
+var sheetPatchSymbols = map[string]string{
+	"update":   "~",
+	"append":   "+",
+	"conflict": "!",
+	"skip":     "·",
+}
+
+// printSheetPatch 逐行输出 patch 计划
+func printSheetPatch(w io.Writer, ops []sheetPatchOp, s sheetPatchSummary) {
+	for _, op := range ops {
+		line := sheetPatchSymbols[op.Action] + " "
+		switch {
+		case op.Action == "append":
+			line += fmt.Sprintf("第 %d 行%s: %s", op.Row, sheetKeyLabel(op.Key), sheetRowLabel(op.Values))
+		case op.Cell != "":
+			line += fmt.Sprintf("%s%s %s: %s → %s", op.Cell, sheetKeyLabel(op.Key), op.Column, sheetValueLabel(op.Remote), sheetValueLabel(op.Local))
+		case op.Row > 0:
+			line += fmt.Sprintf("第 %d 行%s", op.Row, sheetKeyLabel(op.Key))
+		case op.Action == "conflict":
+			line += fmt.Sprintf("%s%s: 本地改为 %s", op.Column, sheetKeyLabel(op.Key), sheetValueLabel(op.Local))
+		default:
+			line += "列 " + op.Column
+		}
+		switch {
+		case op.Action == "conflict" && op.Cell != "":
+			line += fmt.Sprintf("（冲突：%s，基线 %s）", op.Reason, sheetValueLabel(op.Base))
+		case op.Action == "conflict":
+			line += "（冲突：" + op.Reason + "）"
+		case op.Reason != "":
+			line += "（" + op.Reason + "）"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if s.Updates+s.Appends+s.Conflicts+s.Skipped == 0 {
+		fmt.Fprintln(w, "无变更：远端已包含本地的全部改动")
+		return
+	}
+	fmt.Fprintf(w, "共更新 %d 个单元格，追加 %d 行，冲突 %d 处，未写入 %d 项\n", s.Updates, s.Appends, s.Conflicts, s.Skipped)
+}
+
+func init() {
+	sheetCmd.AddCommand(sheetPatchCmd)
+	sheetPatchCmd.Flags().StringP("file", "f", "", "编辑后的本地文件（table-get JSON 或 CSV）")
+	sheetPatchCmd.Flags().String("base", "", "编辑前的快照（table-get JSON 或 CSV），指定后做三方合并")
+	sheetPatchCmd.Flags().StringArray("key", nil, "按该列的值匹配行（可重复，组成复合 key；缺省按行序号匹配）")
+	sheetPatchCmd.Flags().String("range", "", "远端读取区域（如 A1:F200，缺省读整张 used range）")
+	sheetPatchCmd.Flags().Bool("force", false, "冲突时以本地值覆盖远端")
+	sheetPatchCmd.Flags().Bool("dry-run", false, "只打印计划，不写入")
+	sheetPatchCmd.Flags().String("user-id-type", "", "用户 ID 类型: open_id, union_id, user_id")
+	sheetPatchCmd.Flags().String("user-access-token", "", "User Access Token")
+	output.AddFormatFlags(sheetPatchCmd)
+	mustMarkFlagRequired(sheetPatchCmd, "file")
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/client"
+)
+
+func patchTestTables() (base, local, remote *sheetTable) {
+	cols := []string{"单号", "金额", "状态"}
+	base = &sheetTable{label: "base.json", columns: cols, row0: 1, rows: [][]any{
+		{"A1", json.Number("100"), "待审"},
+		{"A2", json.Number("200"), "待审"},
+		{"A3", json.Number("300"), "待审"},
+		{"A4", json.Number("400"), "待审"},
+	}}
+	local = &sheetTable{label: "edited.csv", columns: cols, row0: 1, rows: [][]any{
+		{"A1", "150", "待审"}, // 远端未动 → 更新
+		{"A2", "200", "通过"}, // 远端已是同值 → 跳过
+		{"A3", "350", "待审"}, // 远端也改了 → 冲突
+		{"A5", "500", "新建"}, // 本地新增 → 追加；A4 本地删除 → 只报告
+	}}
+	remote = &sheetTable{label: "0b12", columns: cols, row0: 1, dtypes: map[string]string{"金额": "float64"}, rows: [][]any{
+		{"A1", json.Number("100"), "待审"},
+		{"A2", json.Number("200"), "通过"},
+		{"A3", json.Number("310"), "待审"},
+		{"A4", json.Number("400"), "待审"},
+	}}
+	return base, local, remote
+}
+
+func patchOpsText(ops []sheetPatchOp) []string {
+	var out []string
+	for _, op := range ops {
+		out = append(out, op.Action+":"+op.Cell+":"+strings.Join(op.Key, ","))
+	}
+	return out
+}
+
+func TestPlanSheetPatchThreeWay(t *testing.T) {
+	base, local, remote := patchTestTables()
+	ops, err := planSheetPatch(base, local, remote, []string{"单号"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"update:B2:A1", "conflict:B4:A3", "append::A5", "skip::A4"}
+	if got := patchOpsText(ops); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %q, want %q", got, want)
+	}
+	if ops[2].Row != 6 || !reflect.DeepEqual(ops[2].Values, []any{"A5", "500", "新建"}) {
+		t.Errorf("append = %+v", ops[2])
+	}
+	if s := summarizeSheetPatch(ops); s != (sheetPatchSummary{Updates: 1, Appends: 1, Conflicts: 1, Skipped: 1}) {
+		t.Errorf("summary = %+v", s)
+	}
+
+	ops, _ = planSheetPatch(base, local, remote, []string{"单号"}, true)
+	if ops[1].Action != "update" || ops[1].Reason != "覆盖远端修改" {
+		t.Errorf("force op = %+v", ops[1])
+	}
+}
+
+func TestPlanSheetPatchTwoWay(t *testing.T) {
+	_, local, remote := patchTestTables()
+	ops, err := planSheetPatch(remote, local, remote, []string{"单号"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 二方模式以本地为准，不产生冲突
+	want := []string{"update:B2:A1", "update:B4:A3", "append::A5", "skip::A4"}
+	if got := patchOpsText(ops); !reflect.DeepEqual(got, want) {
+		t.Errorf("ops = %q, want %q", got, want)
+	}
+}
+
+func TestPlanSheetPatchRemoteChanges(t *testing.T) {
+	base, local, remote := patchTestTables()
+	// 远端删除了 A1、新增了与本地同 key 但内容不同的 A5
+	remote.rows = append(remote.rows[1:], []any{"A5", json.Number("500"), "远端"})
+	local.columns = append(local.columns, "备注")
+	for i := range local.rows {
+		local.rows[i] = append(local.rows[i], nil)
+	}
+	ops, err := planSheetPatch(base, local, remote, []string{"单号"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"skip::", "conflict::A1", "conflict:B3:A3", "conflict:C5:A5", "skip::A4"}
+	if got := patchOpsText(ops); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %q, want %q", got, want)
+	}
+	if ops[0].Column != "备注" || !strings.Contains(ops[1].Reason, "远端已删除该行") {
+		t.Errorf("ops = %+v", ops[:2])
+	}
+}
+
+func TestPlanSheetPatchByPosition(t *testing.T) {
+	base, local, remote := patchTestTables()
+	// 远端在表尾新增了一行：按行序号匹配时本地新增的行仍追加到远端表尾
+	remote.rows = append(remote.rows, []any{"A9", json.Number("900"), "远端"})
+	local.rows[3] = []any{"A4", "400", "待审"}
+	local.rows = append(local.rows, []any{"A5", "500", "新建"})
+	ops, err := planSheetPatch(base, local, remote, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"update:B2:", "conflict:B4:", "append::"}
+	if got := patchOpsText(ops); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %q, want %q", got, want)
+	}
+	if ops[2].Row != 7 {
+		t.Errorf("append row = %d, want 7", ops[2].Row)
+	}
+}
+
+func TestPlanSheetPatchBlankKeys(t *testing.T) {
+	base, local, remote := patchTestTables()
+	// 两侧表尾都有 key 为空的行：不算 key 重复，也不匹配；本地有内容的空 key 行只报告
+	remote.rows = append(remote.rows, []any{"", nil, nil}, []any{nil, nil, "备注"})
+	local.rows = append(local.rows, []any{"", nil, nil}, []any{"", "9", ""})
+	ops, err := planSheetPatch(base, local, remote, []string{"单号"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"update:B2:A1", "conflict:B4:A3", "append::A5", "skip::", "skip::A4"}
+	if got := patchOpsText(ops); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %q, want %q", got, want)
+	}
+	if ops[2].Row != 8 || !strings.Contains(ops[3].Reason, "本地第 7 行 key 为空") {
+		t.Errorf("ops = %+v", ops[2:4])
+	}
+}
+
+func TestBlockSheetPatchAppends(t *testing.T) {
+	base, local, remote := patchTestTables()
+	ops, err := planSheetPatch(base, local, remote, []string{"单号"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 追加从第 6 行开始：整表末行为第 5 行时照常追加
+	blockSheetPatchAppends(ops, 5)
+	if ops[2].Action != "append" {
+		t.Fatalf("append op = %+v", ops[2])
+	}
+	// --range 只覆盖到第 5 行而第 9 行还有数据：追加会覆盖下方数据，改记冲突
+	blockSheetPatchAppends(ops, 9)
+	if ops[2].Action != "conflict" || !strings.Contains(ops[2].Reason, "第 9 行还有数据") {
+		t.Errorf("append op = %+v", ops[2])
+	}
+	if s := summarizeSheetPatch(ops); s.Appends != 0 || s.Conflicts != 2 {
+		t.Errorf("summary = %+v", s)
+	}
+}
+
+func TestApplySheetPatch(t *testing.T) {
+	remote := &sheetTable{columns: []string{"id", "amount", "date", "note"}, col0: 1, row0: 2, rows: make([][]any, 3),
+		dtypes: map[string]string{"amount": "float64", "date": "datetime64[ns]"}}
+	local := &sheetTable{}
+	ops := []sheetPatchOp{
+		{Action: "update", Row: 4, Column: "date", Local: "2024-01-15"},
+		{Action: "update", Row: 4, Column: "amount", Local: "12.5"},
+		{Action: "update", Row: 3, Column: "note", Local: nil},
+		{Action: "conflict", Row: 5, Column: "id", Local: "x"},
+		{Action: "append", Row: 6, Values: []any{"n1", json.Number("7"), nil, "abc"}},
+		{Action: "append", Row: 7, Values: []any{"n2", "oops", nil, nil}},
+	}
+	var calls [][]*client.ValueRangeV3
+	written, err := applySheetPatch(remote, local, "0b12", ops, func(r []*client.ValueRangeV3) error {
+		calls = append(calls, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 11 || len(calls) != 1 {
+		t.Fatalf("written = %d, calls = %d", written, len(calls))
+	}
+	var rngs []string
+	for _, vr := range calls[0] {
+		rngs = append(rngs, vr.Range)
+	}
+	if want := []string{"0b12!E3:E3", "0b12!C4:D4", "0b12!B6:E7"}; !reflect.DeepEqual(rngs, want) {
+		t.Errorf("ranges = %q, want %q", rngs, want)
+	}
+	amount, date := calls[0][1].Values[0][0][0], calls[0][1].Values[0][1][0]
+	if amount.Type != "value" || amount.Value.Value != "12.5" || date.Type != "value" || date.Value.Value != "45306" {
+		t.Errorf("typed cells = %+v %+v", amount.Value, date.Value)
+	}
+	if note := calls[0][0].Values[0][0][0]; note.Type != "text" || note.Text.Text != "" {
+		t.Errorf("empty cell = %+v", note)
+	}
+	// 数字列中无法解析的文本按文本写入
+	if oops := calls[0][2].Values[1][1][0]; oops.Type != "text" || oops.Text.Text != "oops" {
+		t.Errorf("fallback cell = %+v", oops)
+	}
+}
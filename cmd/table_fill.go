@@ -12,6 +12,7 @@ import (
 //  1. 根据当前实际行数计算还缺多少行（重试场景不会重复追加，避免行数倍增）
 //  2. 通过 insert_table_row API 追加缺失的扩展行
 //  3. 填充所有单元格（FillTableCells 对同一 cell 写同值是幂等的）
+//  4. 按 td.Merges 还原合并单元格（已合并的区域跳过）
 //
 // 被 doc import（processTableTask）和 doc add/content-update（fillTableWithRetry）共用。
 // onAppendProgress 可为 nil；由调用方决定如何展示（syncPrintf / fmt.Printf）。
@@ -84,7 +85,7 @@ func fillTableWithExtraRows(
 	}
 
 	if len(td.ExtraRowContents) == 0 {
-		return nil
+		return applyTableMerges(documentID, tableBlockID, td, userAccessToken)
 	}
 
 	// 扁平化扩展行内容 + 填充。追加行的新 cell 已由上方局部补建进 cellMap，
@@ -100,14 +101,49 @@ func fillTableWithExtraRows(
 		if err := client.FillTableCellsRichWithMap(documentID, newCellIDs, extraElements, extraContents, cellMap, userAccessToken); err != nil {
 			return fmt.Errorf("填充扩展行失败: %w", err)
 		}
-		return nil
+		return applyTableMerges(documentID, tableBlockID, td, userAccessToken)
 	}
 	if err := client.FillTableCells(documentID, newCellIDs, extraContents, userAccessToken); err != nil {
 		return fmt.Errorf("填充扩展行失败: %w", err)
 	}
+	return applyTableMerges(documentID, tableBlockID, td, userAccessToken)
+}
+
+// applyTableMerges 在单元格填充完成后逐个还原合并区域（与 doc table merge-cells 同一 API）。
+// 先读取表格当前的 merge_info，已按相同跨度合并的区域直接跳过，保证重试时幂等。
+func applyTableMerges(documentID, tableBlockID string, td *converter.TableData, userAccessToken string) error {
+	if len(td.Merges) == 0 {
+		return nil
+	}
+	block, err := client.GetBlock(documentID, tableBlockID, userAccessToken)
+	if err != nil {
+		return fmt.Errorf("获取表格合并信息失败: %w", err)
+	}
+	var mergeInfo []*larkdocx.TableMergeInfo
+	if block.Table != nil && block.Table.Property != nil {
+		mergeInfo = block.Table.Property.MergeInfo
+	}
+	for _, m := range td.Merges {
+		if tableMergeApplied(mergeInfo, m, td.Cols) {
+			continue
+		}
+		if err := client.MergeTableCells(documentID, tableBlockID, m.Row, m.Row+m.RowSpan, m.Col, m.Col+m.ColSpan, userAccessToken); err != nil {
+			return fmt.Errorf("合并单元格（行 %d-%d，列 %d-%d）失败: %w", m.Row, m.Row+m.RowSpan-1, m.Col, m.Col+m.ColSpan-1, err)
+		}
+	}
 	return nil
 }
 
+// tableMergeApplied 判断合并区域左上角单元格的 merge_info 是否已是目标跨度。
+func tableMergeApplied(mergeInfo []*larkdocx.TableMergeInfo, m converter.TableMerge, cols int) bool {
+	idx := m.Row*cols + m.Col
+	if idx >= len(mergeInfo) || mergeInfo[idx] == nil {
+		return false
+	}
+	info := mergeInfo[idx]
+	return info.RowSpan != nil && *info.RowSpan == m.RowSpan && info.ColSpan != nil && *info.ColSpan == m.ColSpan
+}
+
 // buildTableCellMap 按单个表格局部拉取直接子块（cell 块，响应自带 Children 字段），
 // 构建 cellID -> 默认空 text 子块 ID 的映射。与全文档 buildCellTextBlockMap 相比，
 // 读成本按表格自身规模计费（≤500 cell 单次分页调用），适合 content-update 面向
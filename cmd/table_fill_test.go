@@ -195,3 +195,26 @@ func TestMergeCellMaps(t *testing.T) {
 		t.Fatalf("nil shared 合并不符: %v", m2)
 	}
 }
+
+func TestTableMergeApplied(t *testing.T) {
+	one, two := 1, 2
+	info := []*larkdocx.TableMergeInfo{
+		{RowSpan: &one, ColSpan: &two}, {RowSpan: &one, ColSpan: &one},
+		{RowSpan: &two, ColSpan: &one}, nil,
+	}
+	cases := []struct {
+		m    converter.TableMerge
+		want bool
+	}{
+		{converter.TableMerge{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}, true},
+		{converter.TableMerge{Row: 0, Col: 0, RowSpan: 2, ColSpan: 2}, false}, // 跨度不同需重新合并
+		{converter.TableMerge{Row: 1, Col: 0, RowSpan: 2, ColSpan: 1}, true},
+		{converter.TableMerge{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1}, false}, // nil 项
+		{converter.TableMerge{Row: 5, Col: 0, RowSpan: 2, ColSpan: 1}, false}, // 越界（追加行前的旧 merge_info）
+	}
+	for _, tc := range cases {
+		if got := tableMergeApplied(info, tc.m, 2); got != tc.want {
+			t.Errorf("tableMergeApplied(%+v) = %v, want %v", tc.m, got, tc.want)
+		}
+	}
+}
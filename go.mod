@@ -1,6 +1,6 @@
 module github.com/riba2534/feishu-cli
 
-go 1.21
+go 1.26.0
 
 require (
 	github.com/itchyny/gojq v0.12.17
@@ -10,7 +10,10 @@ require (
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.18.2
 	github.com/yuin/goldmark v1.7.0
+	golang.org/x/crypto v0.57.0
 	golang.org/x/image v0.18.0
+	golang.org/x/net v0.58.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -32,8 +35,7 @@ require (
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.9.0 // indirect
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9 // indirect
-	golang.org/x/sys v0.20.0 // indirect
-	golang.org/x/text v0.16.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/riba2534/feishu-cli/internal/profile"
+	"github.com/riba2534/feishu-cli/internal/secretstore"
 )
 
 // TokenStore 存储 OAuth token 信息
@@ -48,7 +49,30 @@ func LoadToken() (*TokenStore, error) {
 }
 
 // LoadTokenFrom 从指定路径加载 token.json，文件不存在返回 nil, nil。
+// access_token / refresh_token 若是 secretstore 引用，会还原为明文。
 func LoadTokenFrom(path string) (*TokenStore, error) {
+	t, err := loadRawToken(path)
+	if err != nil || t == nil {
+		return t, err
+	}
+	if !secretstore.IsRef(t.AccessToken) && !secretstore.IsRef(t.RefreshToken) {
+		return t, nil
+	}
+	root, err := profile.RootDir()
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken, err = secretstore.Resolve(t.AccessToken, root); err != nil {
+		return nil, fmt.Errorf("读取 access_token 失败: %w", err)
+	}
+	if t.RefreshToken, err = secretstore.Resolve(t.RefreshToken, root); err != nil {
+		return nil, fmt.Errorf("读取 refresh_token 失败: %w", err)
+	}
+	return t, nil
+}
+
+// loadRawToken 读取 token.json 原文，不解析 secret 引用。
+func loadRawToken(path string) (*TokenStore, error) {
 	if path == "" {
 		return nil, nil
 	}
@@ -68,13 +92,93 @@ func LoadTokenFrom(path string) (*TokenStore, error) {
 	return &t, nil
 }
 
-// SaveToken 保存 token 到文件（0600 权限）
+// sealToken 按 backend 把 token 中的敏感字段换成 secretstore 引用，prev 为文件中的旧内容（复用其 key）。
+func sealToken(t, prev *TokenStore, backend, root string) (*TokenStore, error) {
+	if prev == nil {
+		prev = &TokenStore{}
+	}
+	sealed := *t
+	var err error
+	if sealed.AccessToken, err = secretstore.Seal(backend, root, "access_token", t.AccessToken, prev.AccessToken); err != nil {
+		return nil, err
+	}
+	if sealed.RefreshToken, err = secretstore.Seal(backend, root, "refresh_token", t.RefreshToken, prev.RefreshToken); err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// tokenBackend 决定写 token.json 时用的后端：已有引用沿用原后端，否则取 FEISHU_SECRET_STORE。
+func tokenBackend(prev *TokenStore) (string, error) {
+	if prev != nil {
+		for _, v := range []string{prev.RefreshToken, prev.AccessToken} {
+			if secretstore.IsRef(v) {
+				return secretstore.BackendOf(v), nil
+			}
+		}
+	}
+	return secretstore.DefaultBackend()
+}
+
+// MigrateTokenSecrets 把 path 处 token.json 中的明文 token 移入 backend，返回是否有改动。
+// 已是引用的字段保持不变；文件不存在时返回 false。
+func MigrateTokenSecrets(path, backend string) (bool, error) {
+	raw, err := loadRawToken(path)
+	if err != nil || raw == nil {
+		return false, err
+	}
+	if (raw.AccessToken == "" || secretstore.IsRef(raw.AccessToken)) && (raw.RefreshToken == "" || secretstore.IsRef(raw.RefreshToken)) {
+		return false, nil
+	}
+	root, err := profile.RootDir()
+	if err != nil {
+		return false, err
+	}
+	plain, err := LoadTokenFrom(path)
+	if err != nil {
+		return false, err
+	}
+	sealed, err := sealToken(plain, raw, backend, root)
+	if err != nil {
+		return false, err
+	}
+	if err := writeTokenFile(path, sealed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SaveToken 保存 token 到文件（0600 权限）。
+// 启用 secretstore 后（FEISHU_SECRET_STORE 或文件中已有引用），token 明文写入后端，文件只保存引用。
 func SaveToken(t *TokenStore) error {
 	path, err := TokenPath()
 	if err != nil {
 		return err
 	}
 
+	prev, _ := loadRawToken(path) // 旧文件损坏时按全新写入处理
+	backend, err := tokenBackend(prev)
+	if err != nil {
+		return err
+	}
+	if backend != secretstore.BackendPlaintext {
+		root, err := profile.RootDir()
+		if err != nil {
+			return err
+		}
+		if t, err = sealToken(t, prev, backend, root); err != nil {
+			return err
+		}
+	}
+	if err := writeTokenFile(path, t); err != nil {
+		return err
+	}
+
+	clearCurrentUserCacheBestEffort()
+	return nil
+}
+
+func writeTokenFile(path string, t *TokenStore) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("创建目录失败: %w", err)
@@ -88,18 +192,23 @@ func SaveToken(t *TokenStore) error {
 	if err := os.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("写入 token 文件失败: %w", err)
 	}
-
-	clearCurrentUserCacheBestEffort()
 	return nil
 }
 
-// DeleteToken 删除 token 文件
+// DeleteToken 删除 token 文件，并尽力清理其引用的 secretstore 条目。
 func DeleteToken() error {
 	path, err := TokenPath()
 	if err != nil {
 		return err
 	}
 
+	if raw, _ := loadRawToken(path); raw != nil {
+		if root, err := profile.RootDir(); err == nil {
+			_ = secretstore.Erase(raw.AccessToken, root)
+			_ = secretstore.Erase(raw.RefreshToken, root)
+		}
+	}
+
 	if err := os.Remove(path); err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("删除 token 文件失败: %w", err)
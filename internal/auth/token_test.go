@@ -3,8 +3,12 @@ package auth
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/riba2534/feishu-cli/internal/profile"
+	"github.com/riba2534/feishu-cli/internal/secretstore"
 )
 
 func TestTokenStoreValidation(t *testing.T) {
@@ -166,3 +170,66 @@ func TestLoadTokenNotExist(t *testing.T) {
 		t.Error("expected nil for nonexistent file")
 	}
 }
+
+func TestSaveTokenWithSecretStore(t *testing.T) {
+	home := t.TempDir()
+	restore := profile.SetHomeFunc(func() (string, error) { return home, nil })
+	defer restore()
+	tokenFile := filepath.Join(home, ".feishu-cli", "token.json")
+	tokenPathFunc = func() (string, error) { return tokenFile, nil }
+	defer func() { tokenPathFunc = originalTokenPath }()
+	t.Setenv(secretstore.EnvPassphrase, "test-pass")
+	t.Setenv(secretstore.EnvBackend, secretstore.BackendFile)
+
+	token := &TokenStore{AccessToken: "u-plain-access", RefreshToken: "ur-plain-refresh", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := SaveToken(token); err != nil {
+		t.Fatalf("SaveToken() error: %v", err)
+	}
+	data, _ := os.ReadFile(tokenFile)
+	if strings.Contains(string(data), "plain-access") || strings.Contains(string(data), "plain-refresh") {
+		t.Fatalf("token.json 不应含明文:\n%s", data)
+	}
+	raw, _ := loadRawToken(tokenFile)
+	firstRef := raw.RefreshToken
+
+	// 迁移后引用是粘性的：不再设置 FEISHU_SECRET_STORE 也继续加密写入，并复用同一 key
+	t.Setenv(secretstore.EnvBackend, "")
+	token.RefreshToken = "ur-rotated"
+	if err := SaveToken(token); err != nil {
+		t.Fatalf("SaveToken() error: %v", err)
+	}
+	raw, _ = loadRawToken(tokenFile)
+	if raw.RefreshToken != firstRef {
+		t.Errorf("刷新后应复用原 key: %q → %q", firstRef, raw.RefreshToken)
+	}
+	loaded, err := LoadToken()
+	if err != nil {
+		t.Fatalf("LoadToken() error: %v", err)
+	}
+	if loaded.AccessToken != "u-plain-access" || loaded.RefreshToken != "ur-rotated" {
+		t.Errorf("LoadToken 应还原明文: %+v", loaded)
+	}
+}
+
+func TestMigrateTokenSecrets(t *testing.T) {
+	home := t.TempDir()
+	restore := profile.SetHomeFunc(func() (string, error) { return home, nil })
+	defer restore()
+	t.Setenv(secretstore.EnvPassphrase, "test-pass")
+	path := filepath.Join(home, "token.json")
+	if err := os.WriteFile(path, []byte(`{"access_token":"u-a","refresh_token":"ur-r"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := MigrateTokenSecrets(path, secretstore.BackendFile)
+	if err != nil || !changed {
+		t.Fatalf("MigrateTokenSecrets = %v, %v", changed, err)
+	}
+	if changed, err = MigrateTokenSecrets(path, secretstore.BackendFile); err != nil || changed {
+		t.Fatalf("重复迁移应无改动: %v, %v", changed, err)
+	}
+	loaded, err := LoadTokenFrom(path)
+	if err != nil || loaded.AccessToken != "u-a" || loaded.RefreshToken != "ur-r" {
+		t.Fatalf("迁移后读取 = %+v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,384 @@
+// Package cassette 录制并回放 SDK 发出的 HTTP 往返（--record / --replay）。
+//
+// 一个 cassette 是一个目录，每次往返存成一个按顺序编号的 JSON 文件（0001.json、0002.json…），
+// 便于人工审阅和在 bug report 里附带。写盘前会脱敏：Authorization / Cookie 头、
+// app_secret 与各类 access/refresh token 字段一律替换为 REDACTED；只保存 path+query，
+// 不保存 host，回放与录制时的 base_url 无关。
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Redacted 是脱敏后的占位值。
+const Redacted = "REDACTED"
+
+// secretKeys 是 JSON body 与 query 中需要脱敏的字段名。
+var secretKeys = map[string]bool{
+	"app_secret":          true,
+	"client_secret":       true,
+	"app_access_token":    true,
+	"tenant_access_token": true,
+	"user_access_token":   true,
+	"access_token":        true,
+	"refresh_token":       true,
+}
+
+// secretHeaders 是需要脱敏的请求/响应头。
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Interaction 是一次 HTTP 往返。
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request 是脱敏后的请求；URL 只含 path 与 query。
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"` // 空为 UTF-8 文本，"base64" 为二进制
+}
+
+// Response 是脱敏后的响应。
+type Response struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// HTTPClient 与 larkcore.HttpClient 同形，避免本包依赖 SDK。
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Recorder 透传请求并把每次往返写入 cassette 目录。
+type Recorder struct {
+	dir   string
+	inner HTTPClient
+	mu    sync.Mutex
+	seq   int
+}
+
+// NewRecorder 创建录制器；dir 不存在时创建，已含录制文件时报错，避免混入上一次的往返。
+func NewRecorder(dir string, inner HTTPClient) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建 cassette 目录失败: %w", err)
+	}
+	existing, err := interactionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("cassette 目录 %s 已有 %d 条录制，请换一个空目录", dir, len(existing))
+	}
+	if inner == nil {
+		inner = http.DefaultClient
+	}
+	return &Recorder{dir: dir, inner: inner}, nil
+}
+
+// Do 实现 HTTPClient：发出真实请求，完整读取响应后落盘，再把响应原样交还调用方。
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := drainRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.inner.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	it := Interaction{
+		Request: Request{Method: req.Method, URL: requestURI(req.URL), Header: redactHeader(req.Header)},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: redactHeader(resp.Header),
+		},
+	}
+	it.Request.Body, it.Request.BodyEncoding = encodeBody(redactBody(reqBody))
+	it.Response.Body, it.Response.BodyEncoding = encodeBody(redactBody(respBody))
+	if err := r.write(&it); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) write(it *Interaction) error {
+	data, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	path := filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.seq))
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("写入 cassette 失败: %w", err)
+	}
+	return nil
+}
+
+// Player 从 cassette 目录回放响应，不发出任何网络请求。
+//
+// 匹配规则：先找同 method + path + query 的第一条未用录制；没有则退到同 method + path
+// （query 里常有时间戳等易变参数）；都用完后重复最后一条同 method + path 的录制
+// （如进程内重新获取 tenant_access_token）。
+type Player struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Load 读取 cassette 目录。
+func Load(dir string) (*Player, error) {
+	files, err := interactionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cassette 目录 %s 中没有录制文件", dir)
+	}
+	p := &Player{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("读取 cassette 失败: %w", err)
+		}
+		var it Interaction
+		if err := json.Unmarshal(data, &it); err != nil {
+			return nil, fmt.Errorf("解析 cassette %s 失败: %w", filepath.Base(f), err)
+		}
+		p.interactions = append(p.interactions, it)
+	}
+	p.used = make([]bool, len(p.interactions))
+	return p, nil
+}
+
+// UsedUserToken 报告录制时是否有请求携带 User Access Token（u- 前缀）。
+// 回放端据此决定是否需要伪造一个 User Token，让命令走与录制时相同的身份分支。
+func (p *Player) UsedUserToken() bool {
+	for _, it := range p.interactions {
+		if strings.HasPrefix(it.Request.Header.Get("Authorization"), "Bearer u-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Do 实现 HTTPClient：返回匹配的录制响应，找不到时报错而不是访问网络。
+func (p *Player) Do(req *http.Request) (*http.Response, error) {
+	if _, err := drainRequest(req); err != nil {
+		return nil, err
+	}
+	uri := requestURI(req.URL)
+	path := pathOf(uri)
+
+	p.mu.Lock()
+	idx := p.find(func(it *Interaction, i int) bool { return !p.used[i] && it.Request.URL == uri }, req.Method)
+	if idx < 0 {
+		idx = p.find(func(it *Interaction, i int) bool { return !p.used[i] && pathOf(it.Request.URL) == path }, req.Method)
+	}
+	if idx < 0 {
+		idx = p.findLast(func(it *Interaction) bool { return pathOf(it.Request.URL) == path }, req.Method)
+	}
+	if idx >= 0 {
+		p.used[idx] = true
+	}
+	p.mu.Unlock()
+	if idx < 0 {
+		return nil, fmt.Errorf("cassette 中没有匹配的录制: %s %s", req.Method, uri)
+	}
+
+	rec := p.interactions[idx].Response
+	body, err := decodeBody(rec.Body, rec.BodyEncoding)
+	if err != nil {
+		return nil, err
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	// 脱敏可能改变 body 长度，录制时的 Content-Length 不再可信
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (p *Player) find(match func(*Interaction, int) bool, method string) int {
+	for i := range p.interactions {
+		if it := &p.interactions[i]; it.Request.Method == method && match(it, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Player) findLast(match func(*Interaction) bool, method string) int {
+	for i := len(p.interactions) - 1; i >= 0; i-- {
+		if it := &p.interactions[i]; it.Request.Method == method && match(it) {
+			return i
+		}
+	}
+	return -1
+}
+
+// interactionFiles 按编号顺序列出目录中的录制文件。
+func interactionFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9][0-9]*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// drainRequest 读出请求体并放回，使其仍可被真实发送。
+func drainRequest(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// requestURI 返回脱敏后的 path?query，query 按 key 排序以保证匹配稳定。
+func requestURI(u *url.URL) string {
+	q := u.Query()
+	for k := range q {
+		if secretKeys[k] {
+			q.Set(k, Redacted)
+		}
+	}
+	uri := u.EscapedPath()
+	if enc := q.Encode(); enc != "" {
+		uri += "?" + enc
+	}
+	return uri
+}
+
+func pathOf(uri string) string {
+	return strings.SplitN(uri, "?", 2)[0]
+}
+
+// redactHeader 复制 header 并替换敏感头；Bearer token 保留 t-/u-/a- 前缀以标识身份类型。
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if out.Get(name) == "" {
+			continue
+		}
+		value := Redacted
+		if name == "Authorization" {
+			value = "Bearer " + tokenPrefix(strings.TrimPrefix(out.Get(name), "Bearer ")) + Redacted
+		}
+		out.Set(name, value)
+	}
+	return out
+}
+
+func tokenPrefix(token string) string {
+	if len(token) > 2 && token[1] == '-' {
+		return token[:2]
+	}
+	return ""
+}
+
+// redactBody 对 JSON body 中的敏感字段脱敏；非 JSON（multipart、二进制下载等）原样返回。
+func redactBody(body []byte) []byte {
+	if len(body) == 0 || !json.Valid(body) {
+		return body
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || !redactValue(v) {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactValue 递归替换敏感字段的字符串值，报告是否有改动。
+func redactValue(v any) bool {
+	changed := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if s, ok := item.(string); ok && secretKeys[k] && s != "" {
+				val[k] = Redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(item) || changed
+		}
+	case []any:
+		for _, item := range val {
+			changed = redactValue(item) || changed
+		}
+	}
+	return changed
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("解码 cassette body 失败: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("未知的 cassette body_encoding: %s", encoding)
+	}
+}
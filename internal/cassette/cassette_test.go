@@ -0,0 +1,119 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/open-apis/auth/v3/tenant_access_token/internal":
+			io.WriteString(w, `{"code":0,"tenant_access_token":"t-secret-token","expire":7200}`)
+		case "/bin":
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		default:
+			io.WriteString(w, `{"code":0,"data":{"q":"`+r.URL.Query().Get("q")+`"}}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func send(t *testing.T, c HTTPClient, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer u-real-user-token")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordRedactAndReplay(t *testing.T) {
+	upstream := newUpstream(t)
+	dir := filepath.Join(t.TempDir(), "cassette")
+	rec, err := NewRecorder(dir, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token := send(t, rec, http.MethodPost, upstream.URL+"/open-apis/auth/v3/tenant_access_token/internal", `{"app_id":"cli_a","app_secret":"s3cret"}`)
+	if !strings.Contains(token, "t-secret-token") {
+		t.Fatalf("录制时调用方应拿到真实响应: %s", token)
+	}
+	send(t, rec, http.MethodGet, upstream.URL+"/echo?q=1", "")
+	send(t, rec, http.MethodGet, upstream.URL+"/echo?q=2", "")
+	send(t, rec, http.MethodGet, upstream.URL+"/bin", "")
+
+	files, _ := interactionFiles(dir)
+	if len(files) != 4 {
+		t.Fatalf("应录制 4 条往返，实际 %d", len(files))
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		for _, secret := range []string{"s3cret", "t-secret-token", "u-real-user-token"} {
+			if strings.Contains(string(data), secret) {
+				t.Fatalf("%s 未脱敏 %q:\n%s", filepath.Base(f), secret, data)
+			}
+		}
+	}
+
+	player, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !player.UsedUserToken() {
+		t.Error("录制含 u- Bearer，UsedUserToken 应为 true")
+	}
+	// 回放不依赖录制时的 host
+	const offline = "http://127.0.0.1:1"
+	if _, body := send(t, player, http.MethodGet, offline+"/echo?q=2", ""); !strings.Contains(body, `"q":"2"`) {
+		t.Errorf("精确 query 匹配失败: %s", body)
+	}
+	if _, body := send(t, player, http.MethodGet, offline+"/echo?q=9", ""); !strings.Contains(body, `"q":"1"`) {
+		t.Errorf("query 不同应退到同 path 的未用录制: %s", body)
+	}
+	if _, body := send(t, player, http.MethodGet, offline+"/echo?q=2", ""); !strings.Contains(body, `"q":"2"`) {
+		t.Errorf("录制用完后应重复最后一条同 path 录制: %s", body)
+	}
+	if _, body := send(t, player, http.MethodGet, offline+"/bin", ""); body != "\xff\x00\xfe" {
+		t.Errorf("二进制 body 回放不一致: %q", body)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, offline+"/echo", nil)
+	if _, err := player.Do(req); err == nil {
+		t.Error("没有匹配的录制应报错而不是访问网络")
+	}
+}
+
+func TestNewRecorderRejectsNonEmptyCassette(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0001.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRecorder(dir, nil); err == nil {
+		t.Fatal("已有录制的目录应报错")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	got := string(redactBody([]byte(`{"data":{"items":[{"access_token":"x","refresh_token":"y","name":"n"}]},"code":0}`)))
+	if strings.Contains(got, `"x"`) || strings.Contains(got, `"y"`) || !strings.Contains(got, `"name":"n"`) {
+		t.Fatalf("redactBody = %s", got)
+	}
+	plain := []byte("not json app_secret")
+	if string(redactBody(plain)) != string(plain) {
+		t.Fatal("非 JSON body 应原样返回")
+	}
+}
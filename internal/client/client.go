@@ -17,6 +17,8 @@ const defaultTimeout = 30 * time.Second
 var (
 	mu       sync.Mutex
 	instance *lark.Client
+	// httpClient 非空时替换 SDK 底层 HTTP 客户端（--record / --replay 注入）
+	httpClient larkcore.HttpClient
 	// lastCfg 用于检测配置变更，不存储敏感信息的明文
 	lastCfg struct {
 		appID   string
@@ -54,6 +56,9 @@ func GetClient() (*lark.Client, error) {
 		if cfg.Debug {
 			opts = append(opts, lark.WithLogLevel(larkcore.LogLevelDebug))
 		}
+		if httpClient != nil {
+			opts = append(opts, lark.WithHttpClient(httpClient))
+		}
 		instance = lark.NewClient(cfg.AppID, cfg.AppSecret, opts...)
 
 		// Save current config (不存储 secret 明文)
@@ -66,6 +71,15 @@ func GetClient() (*lark.Client, error) {
 	return instance, nil
 }
 
+// SetHTTPClient 替换 GetClient 所建 SDK client 的底层 HTTP 客户端，nil 恢复 SDK 默认。
+// 用于 --record / --replay 把录制回放层插到 SDK 与网络之间；调用后下次 GetClient 重建实例。
+func SetHTTPClient(c larkcore.HttpClient) {
+	mu.Lock()
+	defer mu.Unlock()
+	httpClient = c
+	instance = nil
+}
+
 // Context returns a context with timeout for API calls.
 // 默认超时时间为 30 秒，防止 API 调用无限阻塞。
 // 通过 goroutine 等待 ctx.Done 后调用 cancel，释放关联的计时器资源。
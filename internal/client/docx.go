@@ -109,6 +109,11 @@ func ListBlocks(documentID string, pageToken string, pageSize int) ([]*larkdocx.
 
 // ListBlocksWithToken retrieves all blocks in a document, optionally using a User Access Token
 func ListBlocksWithToken(documentID string, pageToken string, pageSize int, userAccessToken string) ([]*larkdocx.Block, string, error) {
+	return listBlocks(documentID, 0, pageToken, pageSize, userAccessToken)
+}
+
+// listBlocks 分页获取文档块；revisionID > 0 时读取指定历史版本，否则读取最新版本
+func listBlocks(documentID string, revisionID int, pageToken string, pageSize int, userAccessToken string) ([]*larkdocx.Block, string, error) {
 	client, err := GetClient()
 	if err != nil {
 		return nil, "", err
@@ -118,6 +123,9 @@ func ListBlocksWithToken(documentID string, pageToken string, pageSize int, user
 		DocumentId(documentID).
 		PageSize(pageSize)
 
+	if revisionID > 0 {
+		reqBuilder.DocumentRevisionId(revisionID)
+	}
 	if pageToken != "" {
 		reqBuilder.PageToken(pageToken)
 	}
@@ -143,6 +151,11 @@ func GetAllBlocks(documentID string) ([]*larkdocx.Block, error) {
 
 // GetAllBlocksWithToken retrieves all blocks in a document with pagination, optionally using a User Access Token
 func GetAllBlocksWithToken(documentID string, userAccessToken string) ([]*larkdocx.Block, error) {
+	return GetAllBlocksAtRevision(documentID, 0, userAccessToken)
+}
+
+// GetAllBlocksAtRevision 分页获取文档在指定版本（revisionID <= 0 表示最新版本）的全部块
+func GetAllBlocksAtRevision(documentID string, revisionID int, userAccessToken string) ([]*larkdocx.Block, error) {
 	var allBlocks []*larkdocx.Block
 	pageToken := ""
 	pageSize := 500
@@ -153,7 +166,7 @@ func GetAllBlocksWithToken(documentID string, userAccessToken string) ([]*larkdo
 		if pageCount >= maxPages {
 			return nil, fmt.Errorf("超过最大分页限制 %d，文档可能有异常", maxPages)
 		}
-		blocks, nextToken, err := ListBlocksWithToken(documentID, pageToken, pageSize, userAccessToken)
+		blocks, nextToken, err := listBlocks(documentID, revisionID, pageToken, pageSize, userAccessToken)
 		if err != nil {
 			return nil, err
 		}
@@ -435,6 +448,44 @@ func BatchUpdateBlocks(documentID string, requestsJSON string, opts BatchUpdateB
 	return result, headers, nil
 }
 
+// TextElementsUpdate 描述一次 update_text_elements：把 BlockID 的文本元素整体替换为 Elements
+type TextElementsUpdate struct {
+	BlockID  string
+	Elements []*larkdocx.TextElement
+}
+
+// BatchUpdateTextElements 分批调用 batch_update 原地替换多个块的文本元素（block_id 保持不变）。
+// 与 buildElementsJSON 不同，这里直接序列化 SDK 结构，保留 @用户、@文档、行内公式等非 text_run 元素。
+func BatchUpdateTextElements(documentID string, updates []TextElementsUpdate, userAccessToken string) error {
+	for start := 0; start < len(updates); start += fillBatchSize {
+		end := min(start+fillBatchSize, len(updates))
+		requests := make([]map[string]any, 0, end-start)
+		for _, u := range updates[start:end] {
+			requests = append(requests, map[string]any{
+				"block_id":             u.BlockID,
+				"update_text_elements": map[string]any{"elements": u.Elements},
+			})
+		}
+		payload, err := json.Marshal(requests)
+		if err != nil {
+			return fmt.Errorf("序列化批量更新请求失败: %w", err)
+		}
+		res := DoVoidWithRetry(func() (http.Header, error) {
+			_, headers, err := BatchUpdateBlocks(documentID, string(payload), BatchUpdateBlocksOptions{
+				UserAccessToken: userAccessToken,
+			})
+			return headers, err
+		}, RetryConfig{
+			MaxRetries:       5,
+			RetryOnRateLimit: true,
+		})
+		if res.Err != nil {
+			return res.Err
+		}
+	}
+	return nil
+}
+
 // GetBlockChildren retrieves children of a block (first page only)
 func GetBlockChildren(documentID string, blockID string, userAccessToken ...string) ([]*larkdocx.Block, http.Header, error) {
 	client, err := GetClient()
@@ -115,7 +115,7 @@ var docLimiterQPSOverride int
 // 通常已经回满，第二次 acquire 多在 0~50ms 内返回，单次开销可接受。
 //
 // 跨进程：limiter 仅约束本进程；多 CLI 实例并发写同一文档时仍可能触发服务端 429，
-// 因此 retry-on-429 必须保留作为兜底。
+// 因此 retry-on-429 必须保留作为兜底。应用级的跨进程配额见 internal/ratelimit（rate_limit 配置）。
 func AcquireDocWriteSlot(ctx context.Context, documentID string) error {
 	if documentID == "" {
 		return nil
@@ -380,6 +380,8 @@ type DriveRemoteEntry struct {
 	FileToken string
 	Type      string // file / folder / docx / sheet / bitable / mindnote / slides / shortcut
 	RelPath   string
+	// ModifiedTime 为列举接口返回的修改时间（秒级时间戳字符串），drive sync 用来判断远端是否变更
+	ModifiedTime string
 }
 
 // ListFolderRecursive 递归列出 folderToken 下的所有条目（每个 type 都收，包括 folder/docx/...）。
@@ -408,7 +410,7 @@ func listFolderRecursiveInner(folderToken, relBase, userAccessToken string, out
 			if relBase != "" {
 				rel = relBase + "/" + f.Name
 			}
-			out[rel] = DriveRemoteEntry{FileToken: f.Token, Type: f.Type, RelPath: rel}
+			out[rel] = DriveRemoteEntry{FileToken: f.Token, Type: f.Type, RelPath: rel, ModifiedTime: f.ModifiedTime}
 			if f.Type == "folder" {
 				if err := listFolderRecursiveInner(f.Token, rel, userAccessToken, out); err != nil {
 					return err
@@ -149,6 +149,12 @@ func dtypeToTypeFormat(dtype string) (TableColType, string) {
 	}
 }
 
+// ColTypeForDtype 返回 pandas dtype 对应的列类型（映射规则同 table-put）。
+func ColTypeForDtype(dtype string) TableColType {
+	t, _ := dtypeToTypeFormat(dtype)
+	return t
+}
+
 func isNumericDtype(lower string) bool {
 	// interval 以 "int" 开头但不是数值列（pandas IntervalDtype，如
 	// "interval[int64, right]"），其值是区间字符串，须按文本处理，显式排除。
@@ -11,14 +11,15 @@ import (
 
 // TaskInfo represents simplified task information
 type TaskInfo struct {
-	Guid        string `json:"guid"`
-	Summary     string `json:"summary"`
-	Description string `json:"description,omitempty"`
-	DueTime     string `json:"due_time,omitempty"`
-	CompletedAt string `json:"completed_at,omitempty"`
-	CreatedAt   string `json:"created_at,omitempty"`
-	Creator     string `json:"creator,omitempty"`
-	OriginHref  string `json:"origin_href,omitempty"`
+	Guid        string   `json:"guid"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description,omitempty"`
+	DueTime     string   `json:"due_time,omitempty"`
+	CompletedAt string   `json:"completed_at,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	Creator     string   `json:"creator,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+	OriginHref  string   `json:"origin_href,omitempty"`
 }
 
 // CreateTaskOptions represents options for creating a task
@@ -1152,6 +1153,12 @@ func taskToInfo(task *larktask.Task) *TaskInfo {
 		info.Creator = StringVal(task.Creator.Id)
 	}
 
+	for _, m := range task.Members {
+		if m != nil && StringVal(m.Role) == "assignee" && StringVal(m.Id) != "" {
+			info.Assignees = append(info.Assignees, StringVal(m.Id))
+		}
+	}
+
 	if task.Origin != nil && task.Origin.Href != nil {
 		info.OriginHref = StringVal(task.Origin.Href.Url)
 	}
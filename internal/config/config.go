@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/riba2534/feishu-cli/internal/profile"
+	"github.com/riba2534/feishu-cli/internal/secretstore"
 	"github.com/spf13/viper"
 )
 
@@ -21,6 +22,7 @@ type Config struct {
 	Debug             bool         `mapstructure:"debug"`
 	Export            ExportConfig `mapstructure:"export"`
 	Import            ImportConfig `mapstructure:"import"`
+	RateLimit         RateLimit    `mapstructure:"rate_limit"`
 }
 
 // ExportConfig holds export-related configuration
@@ -34,6 +36,13 @@ type ImportConfig struct {
 	UploadImages bool `mapstructure:"upload_images"`
 }
 
+// RateLimit holds the cross-process client-side rate limit configuration
+type RateLimit struct {
+	Enabled bool               `mapstructure:"enabled"`
+	Default float64            `mapstructure:"default"` // 未单独配置的 API 族的每秒请求数
+	Classes map[string]float64 `mapstructure:"classes"` // <family>[:read|:write] → 每秒请求数
+}
+
 var cfg *Config
 
 // 命令行 --bot-app-id / --bot-app-secret，优先于环境变量和配置文件，不写盘。
@@ -113,6 +122,7 @@ func Init(cfgFile string) error {
 	viper.SetDefault("export.download_images", false)
 	viper.SetDefault("export.assets_dir", "./assets")
 	viper.SetDefault("import.upload_images", true)
+	viper.SetDefault("rate_limit.enabled", false)
 
 	// 3. 环境变量支持（优先级最高）
 	viper.SetEnvPrefix("FEISHU")
@@ -126,6 +136,7 @@ func Init(cfgFile string) error {
 	_ = viper.BindEnv("owner_email", "FEISHU_OWNER_EMAIL")
 	_ = viper.BindEnv("transfer_ownership", "FEISHU_TRANSFER_OWNERSHIP")
 	_ = viper.BindEnv("debug", "FEISHU_DEBUG")
+	_ = viper.BindEnv("rate_limit.enabled", "FEISHU_RATE_LIMIT_ENABLED")
 
 	// 4. 读取配置文件
 	if err := viper.ReadInConfig(); err != nil {
@@ -145,6 +156,26 @@ func Init(cfgFile string) error {
 	// 5. 命令行凭证覆盖环境变量和文件，不写盘
 	applyBotFlagCredentials()
 
+	// 6. config.yaml 中的凭证可能是 secretstore 引用（profile migrate-secrets 之后），
+	// 放在覆盖之后：--bot-app-secret / 环境变量已给出明文时不必解锁存储
+	return resolveSecrets(cfg)
+}
+
+// resolveSecrets 把 app_secret / user_access_token 的 secretstore 引用还原为明文。
+func resolveSecrets(c *Config) error {
+	if !secretstore.IsRef(c.AppSecret) && !secretstore.IsRef(c.UserAccessToken) {
+		return nil
+	}
+	root, err := profile.RootDir()
+	if err != nil {
+		return err
+	}
+	if c.AppSecret, err = secretstore.Resolve(c.AppSecret, root); err != nil {
+		return fmt.Errorf("读取 app_secret 失败: %w", err)
+	}
+	if c.UserAccessToken, err = secretstore.Resolve(c.UserAccessToken, root); err != nil {
+		return fmt.Errorf("读取 user_access_token 失败: %w", err)
+	}
 	return nil
 }
 
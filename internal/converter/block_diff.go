@@ -0,0 +1,517 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// BlockDiffOp 表示块级编辑脚本中的操作类型
+type BlockDiffOp string
+
+const (
+	BlockDiffKeep   BlockDiffOp = "keep"   // 内容完全一致，保留原块
+	BlockDiffUpdate BlockDiffOp = "update" // 同类型叶子块，原地更新文本元素（block_id 不变）
+	BlockDiffInsert BlockDiffOp = "insert" // 新增块
+	BlockDiffDelete BlockDiffOp = "delete" // 删除块
+)
+
+// BlockDiffEntry 是参与比较的一个块的摘要。
+// Kind 为类型 + 影响渲染的块级样式（代码语言、待办完成状态等）；
+// Signature 为 Kind + 全部文本内容 + 子树签名，相同即视为内容完全一致。
+type BlockDiffEntry struct {
+	Kind      string
+	Signature string
+	Leaf      bool // 无子块的文本类块，可通过 update_text_elements 原地更新
+}
+
+// BlockEdit 是编辑脚本中的一步。OldIndex/NewIndex 为 -1 表示该侧不存在。
+// Moved 为 true 时，该 delete/insert 是一次「移动」的两半：
+// delete 的 NewIndex 指向目标位置，insert 的 OldIndex 指向来源位置。
+// 飞书 docx API 没有移动原语，应用时仍按删除 + 插入执行。
+type BlockEdit struct {
+	Op       BlockDiffOp
+	OldIndex int
+	NewIndex int
+	Moved    bool
+}
+
+// BlockDiffSummary 汇总编辑脚本，移动成对计数且不计入 inserted/deleted
+type BlockDiffSummary struct {
+	Kept     int `json:"kept"`
+	Updated  int `json:"updated"`
+	Inserted int `json:"inserted"`
+	Deleted  int `json:"deleted"`
+	Moved    int `json:"moved"`
+}
+
+// maxMyersTraceCells 限制 Myers 回溯轨迹的内存（int 个数），超出后中段整体按删除 + 插入处理
+const maxMyersTraceCells = 16 * 1024 * 1024
+
+// DiffBlocks 计算把 oldEntries 变为 newEntries 的最小编辑脚本。
+//
+// 流程：按 Signature 做 LCS（前后缀裁剪 + Myers）得到保留块；相邻保留块之间的空档里，
+// 同 Kind 的叶子块按顺序配对为 update；剩余块中签名相同的 delete/insert 标记为移动。
+// 返回的脚本按文档顺序排列，每个旧块与新块恰好出现一次。
+func DiffBlocks(oldEntries, newEntries []BlockDiffEntry) []BlockEdit {
+	oldSigs := make([]string, len(oldEntries))
+	for i, e := range oldEntries {
+		oldSigs[i] = e.Signature
+	}
+	newSigs := make([]string, len(newEntries))
+	for i, e := range newEntries {
+		newSigs[i] = e.Signature
+	}
+	matches := lcsMatches(oldSigs, newSigs)
+
+	var edits []BlockEdit
+	oi, ni := 0, 0
+	flushGap := func(oldEnd, newEnd int) {
+		edits = append(edits, diffGap(oldEntries, newEntries, oi, oldEnd, ni, newEnd)...)
+	}
+	for _, m := range matches {
+		flushGap(m[0], m[1])
+		edits = append(edits, BlockEdit{Op: BlockDiffKeep, OldIndex: m[0], NewIndex: m[1]})
+		oi, ni = m[0]+1, m[1]+1
+	}
+	flushGap(len(oldEntries), len(newEntries))
+
+	markMoves(edits, oldEntries, newEntries)
+	return edits
+}
+
+// SummarizeBlockEdits 统计编辑脚本中各类操作的数量
+func SummarizeBlockEdits(edits []BlockEdit) BlockDiffSummary {
+	var s BlockDiffSummary
+	for _, e := range edits {
+		switch e.Op {
+		case BlockDiffKeep:
+			s.Kept++
+		case BlockDiffUpdate:
+			s.Updated++
+		case BlockDiffInsert:
+			if e.Moved {
+				s.Moved++
+			} else {
+				s.Inserted++
+			}
+		case BlockDiffDelete:
+			if !e.Moved {
+				s.Deleted++
+			}
+		}
+	}
+	return s
+}
+
+// diffGap 处理两个保留块之间的空档：同 Kind 叶子块贪心配对为 update，
+// 每个 update 之前未配对的旧块先删除、新块再插入。
+func diffGap(oldEntries, newEntries []BlockDiffEntry, oldStart, oldEnd, newStart, newEnd int) []BlockEdit {
+	var edits []BlockEdit
+	oi, ni := oldStart, newStart
+	emitUntil := func(o, n int) {
+		for ; oi < o; oi++ {
+			edits = append(edits, BlockEdit{Op: BlockDiffDelete, OldIndex: oi, NewIndex: -1})
+		}
+		for ; ni < n; ni++ {
+			edits = append(edits, BlockEdit{Op: BlockDiffInsert, OldIndex: -1, NewIndex: ni})
+		}
+	}
+	for o := oldStart; o < oldEnd; o++ {
+		if !oldEntries[o].Leaf {
+			continue
+		}
+		for n := ni; n < newEnd; n++ {
+			if newEntries[n].Leaf && newEntries[n].Kind == oldEntries[o].Kind {
+				emitUntil(o, n)
+				edits = append(edits, BlockEdit{Op: BlockDiffUpdate, OldIndex: o, NewIndex: n})
+				oi, ni = o+1, n+1
+				break
+			}
+		}
+	}
+	emitUntil(oldEnd, newEnd)
+	return edits
+}
+
+// markMoves 把签名相同的 delete/insert 成对标记为移动
+func markMoves(edits []BlockEdit, oldEntries, newEntries []BlockDiffEntry) {
+	pendingDeletes := map[string][]int{}
+	for i, e := range edits {
+		if e.Op == BlockDiffDelete {
+			sig := oldEntries[e.OldIndex].Signature
+			pendingDeletes[sig] = append(pendingDeletes[sig], i)
+		}
+	}
+	for i, e := range edits {
+		if e.Op != BlockDiffInsert {
+			continue
+		}
+		sig := newEntries[e.NewIndex].Signature
+		candidates := pendingDeletes[sig]
+		if len(candidates) == 0 {
+			continue
+		}
+		d := candidates[0]
+		pendingDeletes[sig] = candidates[1:]
+		edits[d].Moved = true
+		edits[d].NewIndex = e.NewIndex
+		edits[i].Moved = true
+		edits[i].OldIndex = edits[d].OldIndex
+	}
+}
+
+// lcsMatches 返回 a、b 最长公共子序列的下标对（升序）。
+// 先裁剪公共前后缀，中段用 Myers 算法；中段差异过大时放弃匹配（整体视为替换）。
+func lcsMatches(a, b []string) [][2]int {
+	var matches [][2]int
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches = append(matches, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	for _, m := range myersMatches(midA, midB) {
+		matches = append(matches, [2]int{m[0] + prefix, m[1] + prefix})
+	}
+	for i := suffix; i > 0; i-- {
+		matches = append(matches, [2]int{len(a) - i, len(b) - i})
+	}
+	return matches
+}
+
+// myersMatches 用 Myers O((N+M)D) 算法求 LCS 下标对
+func myersMatches(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+	maxD := n + m
+	offset := maxD
+	width := 2*maxD + 2
+	v := make([]int, width)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		if (d+1)*width > maxMyersTraceCells {
+			return nil
+		}
+		snapshot := make([]int, width)
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(trace, offset, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+func myersBacktrack(trace [][]int, offset, n, m int) [][2]int {
+	var reversed [][2]int
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, [2]int{x - 1, y - 1})
+			x--
+			y--
+		}
+		if d > 0 {
+			x, y = prevX, prevY
+		}
+	}
+	matches := make([][2]int, len(reversed))
+	for i := range reversed {
+		matches[i] = reversed[len(reversed)-1-i]
+	}
+	return matches
+}
+
+// ============================================================
+// 块签名
+// ============================================================
+
+// BlockTextBody 返回文本类块（正文/标题/列表/代码/引用/待办）的 Text 载体，其他块返回 nil
+func BlockTextBody(block *larkdocx.Block) *larkdocx.Text {
+	if block == nil || block.BlockType == nil {
+		return nil
+	}
+	bt := BlockType(*block.BlockType)
+	if bt >= BlockTypeHeading1 && bt <= BlockTypeHeading9 {
+		elements, style := getHeadingTextAndStyle(block, bt)
+		if elements == nil && style == nil {
+			return nil
+		}
+		return &larkdocx.Text{Elements: elements, Style: style}
+	}
+	switch bt {
+	case BlockTypeText:
+		return block.Text
+	case BlockTypeBullet:
+		return block.Bullet
+	case BlockTypeOrdered:
+		return block.Ordered
+	case BlockTypeCode:
+		return block.Code
+	case BlockTypeQuote:
+		return block.Quote
+	case BlockTypeTodo:
+		return block.Todo
+	}
+	return nil
+}
+
+// blockDiffKind 返回块类型 + 会影响渲染但 update_text_elements 无法修改的块级样式
+func blockDiffKind(block *larkdocx.Block) string {
+	if block == nil || block.BlockType == nil {
+		return "0"
+	}
+	kind := strconv.Itoa(*block.BlockType)
+	body := BlockTextBody(block)
+	if body == nil || body.Style == nil {
+		return kind
+	}
+	switch BlockType(*block.BlockType) {
+	case BlockTypeCode:
+		if body.Style.Language != nil {
+			kind += ":lang=" + strconv.Itoa(*body.Style.Language)
+		}
+	case BlockTypeTodo:
+		kind += ":done=" + strconv.FormatBool(body.Style.Done != nil && *body.Style.Done)
+	}
+	return kind
+}
+
+// TextElementsSignature 把文本元素归一为可比较的字符串：合并相邻同样式文本，
+// 只保留 Markdown 能表达的行内样式，链接 URL 统一解码。
+func TextElementsSignature(elements []*larkdocx.TextElement) string {
+	var parts []string
+	lastRunStyle := "" // 上一个 part 是文本时的样式 key，非文本 part 置空
+	lastIsRun := false
+	for _, elem := range elements {
+		if elem == nil {
+			continue
+		}
+		switch {
+		case elem.TextRun != nil && elem.TextRun.Content != nil:
+			content := *elem.TextRun.Content
+			if content == "" {
+				continue
+			}
+			style := textRunStyleKey(elem.TextRun.TextElementStyle)
+			if lastIsRun && lastRunStyle == style {
+				parts[len(parts)-1] += content
+				continue
+			}
+			parts = append(parts, "t"+style+"|"+content)
+			lastRunStyle, lastIsRun = style, true
+			continue
+		case elem.MentionUser != nil && elem.MentionUser.UserId != nil:
+			parts = append(parts, "@"+*elem.MentionUser.UserId)
+		case elem.MentionDoc != nil && elem.MentionDoc.Token != nil:
+			parts = append(parts, "d"+*elem.MentionDoc.Token)
+		case elem.Equation != nil && elem.Equation.Content != nil:
+			parts = append(parts, "e"+strings.TrimSpace(*elem.Equation.Content))
+		default:
+			continue
+		}
+		lastIsRun = false
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+func textRunStyleKey(style *larkdocx.TextElementStyle) string {
+	if style == nil {
+		return ""
+	}
+	var sb strings.Builder
+	flag := func(b *bool, c byte) {
+		if b != nil && *b {
+			sb.WriteByte(c)
+		}
+	}
+	flag(style.Bold, 'b')
+	flag(style.Italic, 'i')
+	flag(style.Strikethrough, 's')
+	flag(style.Underline, 'u')
+	flag(style.InlineCode, 'c')
+	if style.Link != nil && style.Link.Url != nil {
+		link := *style.Link.Url
+		if decoded, err := url.PathUnescape(link); err == nil {
+			link = decoded
+		}
+		sb.WriteString("<" + link + ">")
+	}
+	return sb.String()
+}
+
+// blockContentSignature 返回块自身（不含子块）的内容签名
+func blockContentSignature(block *larkdocx.Block) string {
+	if body := BlockTextBody(block); body != nil {
+		return TextElementsSignature(body.Elements)
+	}
+	if block == nil || block.BlockType == nil {
+		return ""
+	}
+	switch BlockType(*block.BlockType) {
+	case BlockTypeEquation:
+		if block.Equation != nil {
+			return TextElementsSignature(block.Equation.Elements)
+		}
+	case BlockTypeCallout:
+		if block.Callout != nil && block.Callout.EmojiId != nil {
+			return *block.Callout.EmojiId
+		}
+	}
+	// 图片、画板、文件等资源块无法从 Markdown 侧得到可比较的内容，按类型 + 位置匹配
+	return ""
+}
+
+// DocumentBlockDiffEntry 为文档中已有的块（含子孙）计算签名，blockMap 为 block_id → Block 的全量映射
+func DocumentBlockDiffEntry(block *larkdocx.Block, blockMap map[string]*larkdocx.Block) BlockDiffEntry {
+	kind := blockDiffKind(block)
+	if block != nil && block.BlockType != nil && BlockType(*block.BlockType) == BlockTypeTable {
+		return BlockDiffEntry{Kind: kind, Signature: kind + documentTableSignature(block, blockMap)}
+	}
+	var children []string
+	if block != nil {
+		for _, childID := range block.Children {
+			if child, ok := blockMap[childID]; ok {
+				children = append(children, DocumentBlockDiffEntry(child, blockMap).Signature)
+			}
+		}
+	}
+	return BlockDiffEntry{
+		Kind:      kind,
+		Signature: composeBlockSignature(kind, blockContentSignature(block), children),
+		Leaf:      len(children) == 0 && BlockTextBody(block) != nil,
+	}
+}
+
+// NodeBlockDiffEntry 为 MarkdownToBlock 转换出的块节点计算签名；表格需传入对应的 TableData
+func NodeBlockDiffEntry(node *BlockNode, tableData *TableData) BlockDiffEntry {
+	if node == nil {
+		return BlockDiffEntry{Kind: "0", Signature: "0"}
+	}
+	kind := blockDiffKind(node.Block)
+	if tableData != nil {
+		return BlockDiffEntry{Kind: kind, Signature: kind + tableDataSignature(tableData)}
+	}
+	var children []string
+	for _, child := range node.Children {
+		children = append(children, NodeBlockDiffEntry(child, nil).Signature)
+	}
+	return BlockDiffEntry{
+		Kind:      kind,
+		Signature: composeBlockSignature(kind, blockContentSignature(node.Block), children),
+		Leaf:      len(children) == 0 && BlockTextBody(node.Block) != nil,
+	}
+}
+
+func composeBlockSignature(kind, content string, children []string) string {
+	sig := kind + "{" + content + "}"
+	if len(children) > 0 {
+		sig += "[" + strings.Join(children, "\x1e") + "]"
+	}
+	return sig
+}
+
+// documentTableSignature 以行列数 + 各单元格纯文本作为表格签名
+func documentTableSignature(table *larkdocx.Block, blockMap map[string]*larkdocx.Block) string {
+	rows, cols := 0, 0
+	if table.Table != nil && table.Table.Property != nil {
+		if table.Table.Property.RowSize != nil {
+			rows = *table.Table.Property.RowSize
+		}
+		if table.Table.Property.ColumnSize != nil {
+			cols = *table.Table.Property.ColumnSize
+		}
+	}
+	var cells []string
+	for _, cellID := range table.Children {
+		cell, ok := blockMap[cellID]
+		if !ok {
+			cells = append(cells, "")
+			continue
+		}
+		var lines []string
+		for _, childID := range cell.Children {
+			if child, ok := blockMap[childID]; ok {
+				if body := BlockTextBody(child); body != nil {
+					lines = append(lines, plainTextOfElements(body.Elements))
+				}
+			}
+		}
+		cells = append(cells, strings.TrimSpace(strings.Join(lines, "\n")))
+	}
+	return fmt.Sprintf("(%dx%d)", rows, cols) + strings.Join(cells, "\x1f")
+}
+
+// tableDataSignature 与 documentTableSignature 同构：最终行数（含追加行）× 列数 + 单元格纯文本
+func tableDataSignature(td *TableData) string {
+	var cells []string
+	for i, content := range td.CellContents {
+		if i < len(td.CellElements) && td.CellElements[i] != nil {
+			content = plainTextOfElements(td.CellElements[i])
+		}
+		cells = append(cells, strings.TrimSpace(content))
+	}
+	for r, row := range td.ExtraRowContents {
+		for c, content := range row {
+			if r < len(td.ExtraRowElements) && c < len(td.ExtraRowElements[r]) && td.ExtraRowElements[r][c] != nil {
+				content = plainTextOfElements(td.ExtraRowElements[r][c])
+			}
+			cells = append(cells, strings.TrimSpace(content))
+		}
+	}
+	return fmt.Sprintf("(%dx%d)", td.Rows+len(td.ExtraRowContents), td.Cols) + strings.Join(cells, "\x1f")
+}
+
+func plainTextOfElements(elements []*larkdocx.TextElement) string {
+	var sb strings.Builder
+	for _, elem := range elements {
+		if elem == nil {
+			continue
+		}
+		if elem.TextRun != nil && elem.TextRun.Content != nil {
+			sb.WriteString(*elem.TextRun.Content)
+		}
+		if elem.Equation != nil && elem.Equation.Content != nil {
+			sb.WriteString(*elem.Equation.Content)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func diffTextBlock(bt BlockType, content string) *larkdocx.Block {
+	t := int(bt)
+	text := &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &content}}}}
+	b := &larkdocx.Block{BlockType: &t}
+	switch bt {
+	case BlockTypeHeading1:
+		b.Heading1 = text
+	case BlockTypeBullet:
+		b.Bullet = text
+	default:
+		b.Text = text
+	}
+	return b
+}
+
+func diffEntries(blocks ...*larkdocx.Block) []BlockDiffEntry {
+	var entries []BlockDiffEntry
+	for _, b := range blocks {
+		entries = append(entries, NodeBlockDiffEntry(&BlockNode{Block: b}, nil))
+	}
+	return entries
+}
+
+func TestDiffBlocksUnchanged(t *testing.T) {
+	old := diffEntries(diffTextBlock(BlockTypeHeading1, "标题"), diffTextBlock(BlockTypeText, "正文"))
+	edits := DiffBlocks(old, old)
+	s := SummarizeBlockEdits(edits)
+	if s.Kept != 2 || s.Updated+s.Inserted+s.Deleted+s.Moved != 0 {
+		t.Fatalf("summary = %+v, want 2 kept only", s)
+	}
+}
+
+func TestDiffBlocksUpdateInPlace(t *testing.T) {
+	old := diffEntries(diffTextBlock(BlockTypeHeading1, "标题"), diffTextBlock(BlockTypeText, "旧段落"), diffTextBlock(BlockTypeText, "结尾"))
+	new := diffEntries(diffTextBlock(BlockTypeHeading1, "标题"), diffTextBlock(BlockTypeText, "新段落"), diffTextBlock(BlockTypeText, "结尾"))
+	edits := DiffBlocks(old, new)
+	if len(edits) != 3 {
+		t.Fatalf("len(edits) = %d, want 3: %+v", len(edits), edits)
+	}
+	if edits[1].Op != BlockDiffUpdate || edits[1].OldIndex != 1 || edits[1].NewIndex != 1 {
+		t.Errorf("edits[1] = %+v, want update 1→1", edits[1])
+	}
+}
+
+func TestDiffBlocksTypeChangeIsReplace(t *testing.T) {
+	old := diffEntries(diffTextBlock(BlockTypeText, "a"))
+	new := diffEntries(diffTextBlock(BlockTypeBullet, "a"))
+	s := SummarizeBlockEdits(DiffBlocks(old, new))
+	if s.Deleted != 1 || s.Inserted != 1 || s.Updated != 0 {
+		t.Fatalf("summary = %+v, want 1 delete + 1 insert", s)
+	}
+}
+
+func TestDiffBlocksInsertAndDelete(t *testing.T) {
+	old := diffEntries(diffTextBlock(BlockTypeText, "a"), diffTextBlock(BlockTypeText, "b"), diffTextBlock(BlockTypeText, "c"))
+	new := diffEntries(diffTextBlock(BlockTypeText, "a"), diffTextBlock(BlockTypeHeading1, "new"), diffTextBlock(BlockTypeText, "c"), diffTextBlock(BlockTypeText, "d"))
+	edits := DiffBlocks(old, new)
+	s := SummarizeBlockEdits(edits)
+	// b → 与新 Heading 类型不同，不能原地更新；b 与 d 之间隔着保留块 c，也不配对
+	if s.Kept != 2 || s.Deleted != 1 || s.Inserted != 2 || s.Updated != 0 {
+		t.Fatalf("summary = %+v", s)
+	}
+	seenOld, seenNew := map[int]bool{}, map[int]bool{}
+	for _, e := range edits {
+		if e.OldIndex >= 0 && e.Op != BlockDiffInsert {
+			seenOld[e.OldIndex] = true
+		}
+		if e.NewIndex >= 0 && e.Op != BlockDiffDelete {
+			seenNew[e.NewIndex] = true
+		}
+	}
+	if len(seenOld) != 3 || len(seenNew) != 4 {
+		t.Fatalf("every block must appear once: old=%v new=%v", seenOld, seenNew)
+	}
+}
+
+func TestDiffBlocksDetectsMove(t *testing.T) {
+	old := diffEntries(diffTextBlock(BlockTypeHeading1, "A"), diffTextBlock(BlockTypeHeading1, "B"), diffTextBlock(BlockTypeHeading1, "C"))
+	new := diffEntries(diffTextBlock(BlockTypeHeading1, "B"), diffTextBlock(BlockTypeHeading1, "C"), diffTextBlock(BlockTypeHeading1, "A"))
+	edits := DiffBlocks(old, new)
+	s := SummarizeBlockEdits(edits)
+	if s.Moved != 1 || s.Kept != 2 || s.Inserted != 0 || s.Deleted != 0 {
+		t.Fatalf("summary = %+v, want 1 move + 2 kept", s)
+	}
+	for _, e := range edits {
+		if e.Op == BlockDiffDelete && (!e.Moved || e.OldIndex != 0 || e.NewIndex != 2) {
+			t.Errorf("move source = %+v, want old 0 → new 2", e)
+		}
+	}
+}
+
+func TestDiffBlocksMiddleChangeInLargeDocument(t *testing.T) {
+	var oldBlocks, newBlocks []*larkdocx.Block
+	for i := 0; i < 3000; i++ {
+		content := string(rune('a'+i%26)) + string(rune('0'+i%10)) + string(rune(i))
+		oldBlocks = append(oldBlocks, diffTextBlock(BlockTypeText, content))
+		if i == 10 || i == 2990 {
+			content += "!"
+		}
+		newBlocks = append(newBlocks, diffTextBlock(BlockTypeText, content))
+	}
+	s := SummarizeBlockEdits(DiffBlocks(diffEntries(oldBlocks...), diffEntries(newBlocks...)))
+	if s.Kept != 2998 || s.Updated != 2 {
+		t.Fatalf("summary = %+v, want 2998 kept + 2 updated", s)
+	}
+}
+
+func TestTextElementsSignatureNormalizesRunsAndLinks(t *testing.T) {
+	bold := true
+	a, b := "hello ", "world"
+	encoded := "https%3A%2F%2Fexample.com%2Fx"
+	plain := "https://example.com/x"
+	split := []*larkdocx.TextElement{
+		{TextRun: &larkdocx.TextRun{Content: &a, TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold}}},
+		{TextRun: &larkdocx.TextRun{Content: &b, TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold, Link: &larkdocx.Link{Url: &encoded}}}},
+	}
+	joined := "hello "
+	merged := []*larkdocx.TextElement{
+		{TextRun: &larkdocx.TextRun{Content: &joined, TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold}}},
+		{TextRun: &larkdocx.TextRun{Content: &b, TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold, Link: &larkdocx.Link{Url: &plain}}}},
+	}
+	if got, want := TextElementsSignature(split), TextElementsSignature(merged); got != want {
+		t.Fatalf("signature mismatch:\n%q\n%q", got, want)
+	}
+}
+
+func TestDocumentAndNodeTableSignaturesMatch(t *testing.T) {
+	tableType, cellType, textType := int(BlockTypeTable), int(BlockTypeTableCell), int(BlockTypeText)
+	rows, cols := 1, 2
+	contents := []string{"名称", "值"}
+	blockMap := map[string]*larkdocx.Block{}
+	table := &larkdocx.Block{
+		BlockType: &tableType,
+		Table:     &larkdocx.Table{Property: &larkdocx.TableProperty{RowSize: &rows, ColumnSize: &cols}},
+	}
+	for i, c := range contents {
+		cellID, textID := "cell"+string(rune('0'+i)), "text"+string(rune('0'+i))
+		content := c
+		blockMap[cellID] = &larkdocx.Block{BlockType: &cellType, Children: []string{textID}}
+		blockMap[textID] = &larkdocx.Block{BlockType: &textType, Text: &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &content}}}}}
+		table.Children = append(table.Children, cellID)
+	}
+	doc := DocumentBlockDiffEntry(table, blockMap)
+	node := NodeBlockDiffEntry(&BlockNode{Block: &larkdocx.Block{BlockType: &tableType}}, &TableData{Rows: 1, Cols: 2, CellContents: contents})
+	if doc.Signature != node.Signature {
+		t.Fatalf("table signatures differ:\n%q\n%q", doc.Signature, node.Signature)
+	}
+}
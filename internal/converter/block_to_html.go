@@ -0,0 +1,731 @@
+package converter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// HTMLOptions 控制 BlockToHTML 的输出
+type HTMLOptions struct {
+	Title string // <title>，为空时使用文档 Page 块的标题
+	// InlineAssets 为 true 时图片 / 画板以 data URI 内联，生成单文件 HTML；
+	// 否则写入 ConvertOptions.AssetsDir，HTML 中引用相对路径。
+	InlineAssets bool
+	// AssetsBaseDir 是 HTML 文件所在目录，用于把资源路径转换为相对路径；为空时原样使用 AssetsDir 下的路径。
+	AssetsBaseDir string
+}
+
+// BlockToHTML 把飞书文档块渲染为语义化 HTML。
+// 与 BlockToMarkdown 共享块索引、用户解析与素材下载，额外保留 Markdown 表达不了的
+// 表格合并单元格、分栏比例、文字颜色等信息。
+type BlockToHTML struct {
+	md         *BlockToMarkdown
+	options    HTMLOptions
+	assetCount int
+}
+
+// NewBlockToHTML creates a new HTML renderer
+func NewBlockToHTML(blocks []*larkdocx.Block, options ConvertOptions, htmlOptions HTMLOptions) *BlockToHTML {
+	if options.AssetsDir == "" {
+		options.AssetsDir = "assets"
+	}
+	return &BlockToHTML{md: NewBlockToMarkdown(blocks, options), options: htmlOptions}
+}
+
+// NewBlockToHTMLWithResolver 创建支持 @用户 展开的 HTML 渲染器
+func NewBlockToHTMLWithResolver(blocks []*larkdocx.Block, options ConvertOptions, htmlOptions HTMLOptions, resolver UserResolver) *BlockToHTML {
+	c := NewBlockToHTML(blocks, options, htmlOptions)
+	c.md.userResolver = resolver
+	c.md.resolveMentionUsers()
+	return c
+}
+
+// htmlStyle 是内嵌的最小样式表：表格边框、高亮块、分栏与待办列表。
+const htmlStyle = `body{max-width:960px;margin:0 auto;padding:24px;font-family:-apple-system,BlinkMacSystemFont,"PingFang SC","Microsoft YaHei",sans-serif;line-height:1.7;color:#1f2329}
+table{border-collapse:collapse;margin:12px 0}
+th,td{border:1px solid #dee0e3;padding:6px 10px;vertical-align:top}
+th{background:#f5f6f7}
+td>p:first-child,th>p:first-child{margin-top:0}
+td>p:last-child,th>p:last-child{margin-bottom:0}
+pre{background:#f5f6f7;padding:12px;overflow:auto;border-radius:4px}
+blockquote{margin:12px 0;padding-left:12px;border-left:3px solid #bbbfc4;color:#646a73}
+.callout{display:flex;gap:8px;margin:12px 0;padding:12px 16px;border:1px solid transparent;border-radius:6px;background:#f5f6f7}
+.callout-body{flex:1;min-width:0}
+.grid{display:grid;gap:16px;margin:12px 0}
+.todo-list{list-style:none;padding-left:4px}
+.todo-list .done{color:#8f959e;text-decoration:line-through}
+figure{margin:12px 0}
+figure img{max-width:100%}
+.feishu-embed{color:#646a73}`
+
+// Convert 输出完整的 HTML 文档
+func (c *BlockToHTML) Convert() (string, error) {
+	title := c.options.Title
+	var top []*larkdocx.Block
+	for _, block := range c.md.blocks {
+		if block.BlockType == nil {
+			continue
+		}
+		if *block.BlockType == int(BlockTypePage) {
+			if title == "" && block.Page != nil {
+				title = c.md.convertTextElementsRaw(block.Page.Elements)
+			}
+			continue
+		}
+		if block.BlockId != nil && c.md.childBlockIDs[*block.BlockId] {
+			continue
+		}
+		top = append(top, block)
+	}
+
+	body, err := c.renderSequence(top, 0)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"zh-CN\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	sb.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	sb.WriteString("<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n<article class=\"feishu-doc\">\n")
+	if title != "" {
+		sb.WriteString("<h1 class=\"doc-title\">" + html.EscapeString(title) + "</h1>\n")
+	}
+	sb.WriteString(body)
+	sb.WriteString("</article>\n</body>\n</html>\n")
+	return sb.String(), nil
+}
+
+// renderChildren 渲染子块 ID 列表
+func (c *BlockToHTML) renderChildren(childIDs []string, depth int) (string, error) {
+	blocks := make([]*larkdocx.Block, 0, len(childIDs))
+	for _, id := range childIDs {
+		if block := c.md.blockMap[id]; block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return c.renderSequence(blocks, depth)
+}
+
+// renderSequence 按顺序渲染同级块，连续的同类列表项合并到同一个 <ul>/<ol>
+func (c *BlockToHTML) renderSequence(blocks []*larkdocx.Block, depth int) (string, error) {
+	if depth > maxRecursionDepth {
+		return "<!-- 递归深度超限 -->\n", nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(blocks); i++ {
+		if blocks[i].BlockType == nil {
+			continue
+		}
+		bt := BlockType(*blocks[i].BlockType)
+		if isListBlockType(bt) {
+			j := i + 1
+			for j < len(blocks) && blocks[j].BlockType != nil && BlockType(*blocks[j].BlockType) == bt {
+				j++
+			}
+			list, err := c.renderList(bt, blocks[i:j], depth)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(list)
+			i = j - 1
+			continue
+		}
+		out, err := c.renderBlock(blocks[i], depth)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(out)
+	}
+	return sb.String(), nil
+}
+
+func (c *BlockToHTML) renderList(bt BlockType, items []*larkdocx.Block, depth int) (string, error) {
+	open, closeTag := "<ul>\n", "</ul>\n"
+	switch bt {
+	case BlockTypeOrdered:
+		open, closeTag = "<ol>\n", "</ol>\n"
+		if first := items[0].Ordered; first != nil && first.Style != nil && first.Style.Sequence != nil {
+			if n, err := strconv.Atoi(*first.Style.Sequence); err == nil && n != 1 {
+				open = fmt.Sprintf("<ol start=\"%d\">\n", n)
+			}
+		}
+	case BlockTypeTodo:
+		open = "<ul class=\"todo-list\">\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(open)
+	for _, item := range items {
+		var elements []*larkdocx.TextElement
+		liOpen := "<li>"
+		switch bt {
+		case BlockTypeBullet:
+			if item.Bullet != nil {
+				elements = item.Bullet.Elements
+			}
+		case BlockTypeOrdered:
+			if item.Ordered != nil {
+				elements = item.Ordered.Elements
+			}
+		case BlockTypeTodo:
+			if item.Todo == nil {
+				continue
+			}
+			elements = item.Todo.Elements
+			if item.Todo.Style != nil && item.Todo.Style.Done != nil && *item.Todo.Style.Done {
+				liOpen = "<li class=\"done\"><input type=\"checkbox\" disabled checked> "
+			} else {
+				liOpen = "<li><input type=\"checkbox\" disabled> "
+			}
+		}
+		sb.WriteString(liOpen)
+		sb.WriteString(c.renderInline(elements))
+		if len(item.Children) > 0 {
+			children, err := c.renderChildren(item.Children, depth+1)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString("\n" + children)
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString(closeTag)
+	return sb.String(), nil
+}
+
+func (c *BlockToHTML) renderBlock(block *larkdocx.Block, depth int) (string, error) {
+	bt := BlockType(*block.BlockType)
+	switch bt {
+	case BlockTypePage, BlockTypeTableCell, BlockTypeGridColumn:
+		return "", nil
+	case BlockTypeText:
+		if block.Text == nil {
+			return "", nil
+		}
+		text := c.renderInline(block.Text.Elements)
+		if strings.TrimSpace(text) == "" {
+			return "", nil
+		}
+		return "<p" + alignAttr(block.Text.Style) + ">" + text + "</p>\n", nil
+	case BlockTypeHeading1, BlockTypeHeading2, BlockTypeHeading3,
+		BlockTypeHeading4, BlockTypeHeading5, BlockTypeHeading6,
+		BlockTypeHeading7, BlockTypeHeading8, BlockTypeHeading9:
+		return c.renderHeading(block, bt), nil
+	case BlockTypeCode:
+		if block.Code == nil {
+			return "", nil
+		}
+		class := ""
+		if block.Code.Style != nil && block.Code.Style.Language != nil {
+			if lang := languageCodeToName(*block.Code.Style.Language); lang != "" {
+				class = " class=\"language-" + html.EscapeString(lang) + "\""
+			}
+		}
+		return "<pre><code" + class + ">" + html.EscapeString(c.md.convertTextElementsRaw(block.Code.Elements)) + "</code></pre>\n", nil
+	case BlockTypeQuote:
+		if block.Quote == nil {
+			return "", nil
+		}
+		return "<blockquote><p>" + c.renderInline(block.Quote.Elements) + "</p></blockquote>\n", nil
+	case BlockTypeQuoteContainer:
+		children, err := c.renderChildren(block.Children, depth+1)
+		return "<blockquote>\n" + children + "</blockquote>\n", err
+	case BlockTypeEquation:
+		if block.Equation == nil {
+			return "", nil
+		}
+		return "<div class=\"math-display\">\\[" + html.EscapeString(c.md.convertTextElementsRaw(block.Equation.Elements)) + "\\]</div>\n", nil
+	case BlockTypeDivider:
+		return "<hr>\n", nil
+	case BlockTypeImage:
+		return c.renderImage(block), nil
+	case BlockTypeTable:
+		return c.renderTable(block, depth)
+	case BlockTypeCallout:
+		return c.renderCallout(block, depth)
+	case BlockTypeGrid:
+		return c.renderGrid(block, depth)
+	case BlockTypeBoard:
+		return c.renderBoard(block), nil
+	case BlockTypeIframe:
+		if block.Iframe == nil || block.Iframe.Component == nil || block.Iframe.Component.Url == nil {
+			return "", nil
+		}
+		src := *block.Iframe.Component.Url
+		if decoded, err := url.QueryUnescape(src); err == nil {
+			src = decoded
+		}
+		if !isSafeHTMLURL(src) {
+			c.warnf("内嵌网页地址 %q 的协议不受支持，已跳过", src)
+			return "", nil
+		}
+		return "<iframe src=\"" + html.EscapeString(src) + "\" style=\"width:100%;min-height:400px;border:0\" allowfullscreen></iframe>\n", nil
+	case BlockTypeFile:
+		if block.File == nil {
+			return "", nil
+		}
+		return fmt.Sprintf("<p class=\"feishu-file\" data-token=\"%s\">📎 %s</p>\n",
+			html.EscapeString(stringValue(block.File.Token)), html.EscapeString(stringValue(block.File.Name))), nil
+	case BlockTypeAgenda, BlockTypeAgendaItem, BlockTypeAgendaItemContent, BlockTypeSyncSource:
+		return c.renderChildren(block.Children, depth+1)
+	case BlockTypeSyncReference:
+		if len(block.Children) > 0 {
+			return c.renderChildren(block.Children, depth+1)
+		}
+	case BlockTypeAgendaItemTitle:
+		if block.Text == nil {
+			return "", nil
+		}
+		return "<p><strong>" + c.renderInline(block.Text.Elements) + "</strong></p>\n", nil
+	}
+
+	// 其余块（电子表格、多维表格、任务 / OKR 等嵌入块）沿用 Markdown 导出结果，原样展示
+	md, err := c.md.convertBlockWithDepth(block, 0, depth)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(md) == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("<pre class=\"feishu-embed\" data-block-type=\"%s\">%s</pre>\n",
+		BlockTypeName(bt), html.EscapeString(strings.TrimRight(md, "\n"))), nil
+}
+
+func (c *BlockToHTML) renderHeading(block *larkdocx.Block, bt BlockType) string {
+	level := int(bt) - int(BlockTypeHeading1) + 1
+	elements, style := getHeadingTextAndStyle(block, bt)
+	tagLevel := level
+	if tagLevel > 6 {
+		tagLevel = 6
+	}
+	text := c.renderInline(elements)
+	if seq := c.md.computeHeadingSeq(tagLevel, style); seq != "" {
+		text = html.EscapeString(seq) + text
+	}
+	extra := alignAttr(style)
+	if level > 6 {
+		extra += fmt.Sprintf(" data-level=\"%d\"", level)
+	}
+	return fmt.Sprintf("<h%d%s>%s</h%d>\n", tagLevel, extra, text, tagLevel)
+}
+
+// alignAttr 把 TextStyle.Align（1 左 / 2 居中 / 3 右）转换为 style 属性；左对齐为默认值不输出。
+func alignAttr(style *larkdocx.TextStyle) string {
+	if style == nil || style.Align == nil {
+		return ""
+	}
+	switch *style.Align {
+	case 2:
+		return " style=\"text-align:center\""
+	case 3:
+		return " style=\"text-align:right\""
+	}
+	return ""
+}
+
+// renderTable 输出真实的 <table>：按 merge_info 还原 rowspan / colspan，首行 / 首列标题映射为 <th>。
+func (c *BlockToHTML) renderTable(block *larkdocx.Block, depth int) (string, error) {
+	if block.Table == nil || block.Table.Property == nil {
+		return "", nil
+	}
+	prop := block.Table.Property
+	rows, cols := 0, 0
+	if prop.RowSize != nil {
+		rows = *prop.RowSize
+	}
+	if prop.ColumnSize != nil {
+		cols = *prop.ColumnSize
+	}
+	cells := block.Table.Cells
+	if cols == 0 || rows == 0 {
+		return "", nil
+	}
+	if len(cells) < rows*cols {
+		rows = len(cells) / cols
+		if rows == 0 {
+			return "", nil
+		}
+	}
+	headerRow := prop.HeaderRow != nil && *prop.HeaderRow
+	headerCol := prop.HeaderColumn != nil && *prop.HeaderColumn
+
+	var sb strings.Builder
+	sb.WriteString("<table>\n")
+	if len(prop.ColumnWidth) == cols {
+		sb.WriteString("<colgroup>")
+		for _, w := range prop.ColumnWidth {
+			sb.WriteString(fmt.Sprintf("<col style=\"width:%dpx\">", w))
+		}
+		sb.WriteString("</colgroup>\n")
+	}
+
+	spans := make(map[int]TableMerge)
+	covered := make([]bool, rows*cols)
+	for _, m := range tableMergesFromInfo(prop.MergeInfo, rows, cols) {
+		spans[m.Row*cols+m.Col] = m
+		for r := m.Row; r < m.Row+m.RowSpan; r++ {
+			for k := m.Col; k < m.Col+m.ColSpan; k++ {
+				covered[r*cols+k] = true
+			}
+		}
+	}
+	for i := 0; i < rows; i++ {
+		if i == 0 && headerRow {
+			sb.WriteString("<thead>\n")
+		}
+		if (i == 0 && !headerRow) || (i == 1 && headerRow) {
+			sb.WriteString("<tbody>\n")
+		}
+		sb.WriteString("<tr>")
+		for j := 0; j < cols; j++ {
+			idx := i*cols + j
+			m, isMerge := spans[idx]
+			if covered[idx] && !isMerge {
+				continue
+			}
+			rowSpan, colSpan := 1, 1
+			if isMerge {
+				rowSpan, colSpan = m.RowSpan, m.ColSpan
+			}
+
+			tag := "td"
+			if (i == 0 && headerRow) || (j == 0 && headerCol) {
+				tag = "th"
+			}
+			sb.WriteString("<" + tag)
+			if rowSpan > 1 {
+				sb.WriteString(fmt.Sprintf(" rowspan=\"%d\"", rowSpan))
+			}
+			if colSpan > 1 {
+				sb.WriteString(fmt.Sprintf(" colspan=\"%d\"", colSpan))
+			}
+			sb.WriteString(">")
+			if cell := c.md.blockMap[cells[idx]]; cell != nil {
+				content, err := c.renderChildren(cell.Children, depth+1)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(strings.TrimRight(content, "\n"))
+			}
+			sb.WriteString("</" + tag + ">")
+		}
+		sb.WriteString("</tr>\n")
+		if i == 0 && headerRow {
+			sb.WriteString("</thead>\n")
+		}
+	}
+	if !headerRow || rows > 1 {
+		sb.WriteString("</tbody>\n")
+	}
+	sb.WriteString("</table>\n")
+	return sb.String(), nil
+}
+
+// calloutEmoji 是常见高亮块图标 emoji_id 到字符的映射，未收录的图标不输出。
+var calloutEmoji = map[string]string{
+	"bulb":               "💡",
+	"warning":            "⚠️",
+	"exclamation":        "❗",
+	"question":           "❓",
+	"pushpin":            "📌",
+	"memo":               "📝",
+	"star":               "⭐",
+	"fire":               "🔥",
+	"white_check_mark":   "✅",
+	"x":                  "❌",
+	"information_source": "ℹ️",
+}
+
+func (c *BlockToHTML) renderCallout(block *larkdocx.Block, depth int) (string, error) {
+	if block.Callout == nil {
+		return "", nil
+	}
+	var styles []string
+	if v := block.Callout.BackgroundColor; v != nil {
+		if color, ok := fontBgColorMap[*v]; ok {
+			styles = append(styles, "background-color:"+color)
+		}
+	}
+	if v := block.Callout.BorderColor; v != nil {
+		if color, ok := fontColorMap[*v]; ok {
+			styles = append(styles, "border-color:"+color)
+		}
+	}
+	if v := block.Callout.TextColor; v != nil {
+		if color, ok := fontColorMap[*v]; ok {
+			styles = append(styles, "color:"+color)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<div class=\"callout\"")
+	if len(styles) > 0 {
+		sb.WriteString(" style=\"" + strings.Join(styles, ";") + "\"")
+	}
+	sb.WriteString(">\n")
+	if emoji := calloutEmoji[stringValue(block.Callout.EmojiId)]; emoji != "" {
+		sb.WriteString("<span class=\"callout-emoji\">" + emoji + "</span>\n")
+	}
+	children, err := c.renderChildren(block.Children, depth+1)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString("<div class=\"callout-body\">\n" + children + "</div>\n</div>\n")
+	return sb.String(), nil
+}
+
+// renderGrid 把分栏输出为 CSS Grid，列宽按 width_ratio 分配
+func (c *BlockToHTML) renderGrid(block *larkdocx.Block, depth int) (string, error) {
+	var columns []string
+	var body strings.Builder
+	for _, childID := range block.Children {
+		col := c.md.blockMap[childID]
+		if col == nil || col.BlockType == nil || BlockType(*col.BlockType) != BlockTypeGridColumn {
+			continue
+		}
+		ratio := 1
+		if col.GridColumn != nil && col.GridColumn.WidthRatio != nil && *col.GridColumn.WidthRatio > 0 {
+			ratio = *col.GridColumn.WidthRatio
+		}
+		columns = append(columns, fmt.Sprintf("%dfr", ratio))
+		content, err := c.renderChildren(col.Children, depth+1)
+		if err != nil {
+			return "", err
+		}
+		body.WriteString("<div class=\"grid-column\">\n" + content + "</div>\n")
+	}
+	if len(columns) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("<div class=\"grid\" style=\"grid-template-columns:%s\">\n%s</div>\n", strings.Join(columns, " "), body.String()), nil
+}
+
+func (c *BlockToHTML) renderImage(block *larkdocx.Block) string {
+	if block.Image == nil {
+		return ""
+	}
+	token := stringValue(block.Image.Token)
+	caption := c.md.imageCaption(block)
+
+	var attrs []string
+	if w := block.Image.Width; w != nil && *w > 0 {
+		attrs = append(attrs, fmt.Sprintf("width=\"%d\"", *w))
+	}
+	if h := block.Image.Height; h != nil && *h > 0 {
+		attrs = append(attrs, fmt.Sprintf("height=\"%d\"", *h))
+	}
+	if token != "" {
+		src, err := c.saveAsset("image.png", func(path string) (string, error) {
+			return path, c.md.downloadImage(token, path)
+		})
+		if err != nil {
+			c.warnf("图片 %s 下载失败，保留 token: %v", token, err)
+			attrs = append(attrs, fmt.Sprintf("data-token=\"%s\"", html.EscapeString(token)))
+		} else {
+			attrs = append([]string{"src=\"" + html.EscapeString(src) + "\""}, attrs...)
+		}
+	}
+	alt := caption
+	if alt == "" {
+		alt = "image"
+	}
+	attrs = append(attrs, "alt=\""+html.EscapeString(alt)+"\"")
+
+	style := ""
+	if block.Image.Align != nil {
+		switch *block.Image.Align {
+		case 2:
+			style = " style=\"text-align:center\""
+		case 3:
+			style = " style=\"text-align:right\""
+		}
+	}
+	out := "<figure" + style + "><img " + strings.Join(attrs, " ") + ">"
+	if caption != "" {
+		out += "<figcaption>" + html.EscapeString(caption) + "</figcaption>"
+	}
+	return out + "</figure>\n"
+}
+
+// renderBoard 把画板导出为 SVG（ExportWhiteboardSVG）后嵌入
+func (c *BlockToHTML) renderBoard(block *larkdocx.Block) string {
+	if block.Board == nil {
+		return ""
+	}
+	token := stringValue(block.Board.Token)
+	if token == "" {
+		return ""
+	}
+	svg, err := c.md.services.exportBoardSVG(token, c.md.options.UserAccessToken)
+	var src string
+	if err == nil {
+		src, err = c.saveAsset("board.svg", func(path string) (string, error) {
+			return path, os.WriteFile(path, []byte(svg), 0644)
+		})
+	}
+	if err != nil {
+		c.warnf("画板 %s 导出 SVG 失败: %v", token, err)
+		return fmt.Sprintf("<figure class=\"board\" data-token=\"%s\"><figcaption>[画板]</figcaption></figure>\n", html.EscapeString(token))
+	}
+	return fmt.Sprintf("<figure class=\"board\"><img src=\"%s\" alt=\"画板\"></figure>\n", html.EscapeString(src))
+}
+
+// saveAsset 保存一个资源并返回 HTML 中的引用：内联模式下为 data URI，否则为相对路径。
+// name 形如 "image.png"，实际文件名追加序号；write 返回最终写入的路径。
+func (c *BlockToHTML) saveAsset(name string, write func(path string) (string, error)) (string, error) {
+	c.assetCount++
+	ext := filepath.Ext(name)
+	filename := fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), c.assetCount, ext)
+
+	if !c.options.InlineAssets {
+		if err := os.MkdirAll(c.md.options.AssetsDir, 0755); err != nil {
+			return "", fmt.Errorf("创建资源目录失败: %w", err)
+		}
+		saved, err := write(filepath.Join(c.md.options.AssetsDir, filename))
+		if err != nil {
+			return "", err
+		}
+		if c.options.AssetsBaseDir != "" {
+			if rel, err := filepath.Rel(c.options.AssetsBaseDir, saved); err == nil {
+				saved = rel
+			}
+		}
+		return filepath.ToSlash(saved), nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "feishu-html-asset-")
+	if err != nil {
+		return "", fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	saved, err := write(filepath.Join(tmpDir, filename))
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		return "", fmt.Errorf("读取资源失败: %w", err)
+	}
+	// 图片扩展名按约定写死为 .png，实际格式以内容嗅探为准；SVG 嗅探结果为 text/xml，按扩展名处理
+	mimeType := http.DetectContentType(data)
+	if filepath.Ext(saved) == ".svg" {
+		mimeType = mime.TypeByExtension(".svg")
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (c *BlockToHTML) warnf(format string, args ...interface{}) {
+	if c.md.diagnostics != nil {
+		fmt.Fprintf(c.md.diagnostics, "警告: "+format+"\n", args...)
+	}
+}
+
+// renderInline 渲染文本元素：样式映射为语义标签，颜色输出为 inline style
+func (c *BlockToHTML) renderInline(elements []*larkdocx.TextElement) string {
+	var sb strings.Builder
+	for _, elem := range mergeAdjacentElements(elements) {
+		if elem == nil {
+			continue
+		}
+		switch {
+		case elem.TextRun != nil:
+			text := strings.ReplaceAll(html.EscapeString(stringValue(elem.TextRun.Content)), "\n", "<br>")
+			sb.WriteString(c.styledHTML(text, elem.TextRun.TextElementStyle, ""))
+		case elem.MentionUser != nil:
+			userID := stringValue(elem.MentionUser.UserId)
+			name := userID
+			if info, ok := c.md.userCache[userID]; ok && info.Name != "" {
+				name = info.Name
+			}
+			sb.WriteString("<span class=\"mention-user\">@" + html.EscapeString(name) + "</span>")
+		case elem.MentionDoc != nil:
+			title := stringValue(elem.MentionDoc.Title)
+			href := stringValue(elem.MentionDoc.Url)
+			if decoded, err := url.QueryUnescape(href); err == nil {
+				href = decoded
+			}
+			sb.WriteString(c.styledHTML(html.EscapeString(title), elem.MentionDoc.TextElementStyle, href))
+		case elem.Equation != nil:
+			sb.WriteString("<span class=\"math-inline\">\\(" + html.EscapeString(stringValue(elem.Equation.Content)) + "\\)</span>")
+		case elem.LinkPreview != nil:
+			text, linkURL := inlineLinkPreviewTextAndURL(elem.LinkPreview)
+			if text == "" {
+				continue
+			}
+			sb.WriteString(c.styledHTML(html.EscapeString(text), elem.LinkPreview.TextElementStyle, stringValue(linkURL)))
+		}
+	}
+	return sb.String()
+}
+
+// styledHTML 按由内到外的顺序包裹样式：代码 → 加粗 / 斜体 / 删除线 / 下划线 → 颜色 → 链接
+func (c *BlockToHTML) styledHTML(text string, style *larkdocx.TextElementStyle, linkURL string) string {
+	if style != nil {
+		if style.InlineCode != nil && *style.InlineCode {
+			text = "<code>" + text + "</code>"
+		}
+		if style.Bold != nil && *style.Bold {
+			text = "<strong>" + text + "</strong>"
+		}
+		if style.Italic != nil && *style.Italic {
+			text = "<em>" + text + "</em>"
+		}
+		if style.Strikethrough != nil && *style.Strikethrough {
+			text = "<del>" + text + "</del>"
+		}
+		if style.Underline != nil && *style.Underline {
+			text = "<u>" + text + "</u>"
+		}
+		var css []string
+		if style.TextColor != nil {
+			if color, ok := fontColorMap[*style.TextColor]; ok {
+				css = append(css, "color:"+color)
+			}
+		}
+		if style.BackgroundColor != nil {
+			if color, ok := fontBgColorMap[*style.BackgroundColor]; ok {
+				css = append(css, "background-color:"+color)
+			}
+		}
+		if len(css) > 0 {
+			text = "<span style=\"" + strings.Join(css, ";") + "\">" + text + "</span>"
+		}
+		if linkURL == "" && style.Link != nil && style.Link.Url != nil {
+			linkURL = *style.Link.Url
+		}
+	}
+	if linkURL != "" {
+		if decoded, err := url.PathUnescape(linkURL); err == nil {
+			linkURL = decoded
+		}
+		// javascript:、data: 等协议的链接只保留文字
+		if isSafeHTMLURL(linkURL) {
+			text = "<a href=\"" + html.EscapeString(linkURL) + "\">" + text + "</a>"
+		}
+	}
+	return text
+}
+
+// isSafeHTMLURL 判断链接能否写入 href / src：只允许 http、https、mailto 与相对地址。
+func isSafeHTMLURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
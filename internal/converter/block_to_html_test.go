@@ -0,0 +1,210 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/client"
+)
+
+func htmlTextBlock(id string, bt BlockType, elements ...*larkdocx.TextElement) *larkdocx.Block {
+	b := embeddedBlock(id, bt)
+	text := &larkdocx.Text{Elements: elements}
+	switch bt {
+	case BlockTypeText:
+		b.Text = text
+	case BlockTypeHeading2:
+		b.Heading2 = text
+	case BlockTypeBullet:
+		b.Bullet = text
+	case BlockTypeOrdered:
+		b.Ordered = text
+	case BlockTypeCode:
+		b.Code = text
+	}
+	return b
+}
+
+func htmlRun(s string, style *larkdocx.TextElementStyle) *larkdocx.TextElement {
+	return &larkdocx.TextElement{TextRun: &larkdocx.TextRun{Content: &s, TextElementStyle: style}}
+}
+
+func TestBlockToHTMLTextListsAndCode(t *testing.T) {
+	page := embeddedBlock("page", BlockTypePage, "h", "p", "b1", "b2", "o1", "code")
+	page.Page = okrText("周报 <草稿>")
+	bold, red := true, 1
+	link := "https%3A%2F%2Fexample.com%2F%3Fq%3D1"
+	blocks := []*larkdocx.Block{
+		page,
+		htmlTextBlock("h", BlockTypeHeading2, htmlRun("进展", nil)),
+		htmlTextBlock("p", BlockTypeText,
+			htmlRun("a < b ", nil),
+			htmlRun("重点", &larkdocx.TextElementStyle{Bold: &bold, TextColor: &red}),
+			htmlRun("链接", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: &link}})),
+		htmlTextBlock("b1", BlockTypeBullet, htmlRun("一", nil)),
+		htmlTextBlock("b2", BlockTypeBullet, htmlRun("二", nil)),
+		htmlTextBlock("o1", BlockTypeOrdered, htmlRun("步骤", nil)),
+		htmlTextBlock("code", BlockTypeCode, htmlRun("if a < b {}", nil)),
+	}
+	lang := 22 // go
+	blocks[6].Code.Style = &larkdocx.TextStyle{Language: &lang}
+
+	out, err := NewBlockToHTML(blocks, ConvertOptions{}, HTMLOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>周报 &lt;草稿&gt;</title>",
+		"<h2>进展</h2>",
+		`<p>a &lt; b <span style="color:#ef4444"><strong>重点</strong></span><a href="https://example.com/?q=1">链接</a></p>`,
+		"<ul>\n<li>一</li>\n<li>二</li>\n</ul>\n<ol>\n<li>步骤</li>\n</ol>",
+		`<pre><code class="language-` + languageCodeToName(22) + `">if a &lt; b {}</code></pre>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBlockToHTMLUnsafeURLs(t *testing.T) {
+	page := embeddedBlock("page", BlockTypePage, "p", "f1", "f2")
+	js, data, mail, rel := "javascript%3Aalert(1)", " data:text/html,<b>x</b>", "mailto:a@example.com", "../b.html#sec"
+	blocks := []*larkdocx.Block{
+		page,
+		htmlTextBlock("p", BlockTypeText,
+			htmlRun("脚本", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: &js}}),
+			htmlRun("数据", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: &data}}),
+			htmlRun("邮件", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: &mail}}),
+			htmlRun("相对", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: &rel}})),
+		embeddedBlock("f1", BlockTypeIframe),
+		embeddedBlock("f2", BlockTypeIframe),
+	}
+	evil, ok := "JavaScript:alert(1)", "https%3A%2F%2Fexample.com%2Fembed"
+	blocks[2].Iframe = &larkdocx.Iframe{Component: &larkdocx.IframeComponent{Url: &evil}}
+	blocks[3].Iframe = &larkdocx.Iframe{Component: &larkdocx.IframeComponent{Url: &ok}}
+
+	out, err := NewBlockToHTML(blocks, ConvertOptions{}, HTMLOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"javascript:", "JavaScript:", "data:text"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("输出不应包含 %q:\n%s", bad, out)
+		}
+	}
+	for _, want := range []string{
+		`<p>脚本数据<a href="mailto:a@example.com">邮件</a><a href="../b.html#sec">相对</a></p>`,
+		`<iframe src="https://example.com/embed"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBlockToHTMLMergedTable(t *testing.T) {
+	// 3x3 表格：(0,0) 横跨两列，(1,2) 纵跨两行，首行为标题行
+	cellIDs := []string{"c00", "c01", "c02", "c10", "c11", "c12", "c20", "c21", "c22"}
+	rows, cols, header := 3, 3, true
+	merge := make([]*larkdocx.TableMergeInfo, 9)
+	for i := range merge {
+		one := 1
+		merge[i] = &larkdocx.TableMergeInfo{RowSpan: &one, ColSpan: &one}
+	}
+	two := 2
+	merge[0].ColSpan = &two
+	merge[5].RowSpan = &two
+
+	table := embeddedBlock("t", BlockTypeTable)
+	table.Table = &larkdocx.Table{Cells: cellIDs, Property: &larkdocx.TableProperty{
+		RowSize: &rows, ColumnSize: &cols, MergeInfo: merge, HeaderRow: &header, ColumnWidth: []int{100, 120, 140},
+	}}
+	blocks := []*larkdocx.Block{embeddedBlock("page", BlockTypePage, "t"), table}
+	for _, id := range cellIDs {
+		blocks = append(blocks, embeddedBlock(id, BlockTypeTableCell, id+"-p"), htmlTextBlock(id+"-p", BlockTypeText, htmlRun(id, nil)))
+	}
+
+	out, err := NewBlockToHTML(blocks, ConvertOptions{}, HTMLOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<table>\n" +
+		`<colgroup><col style="width:100px"><col style="width:120px"><col style="width:140px"></colgroup>` + "\n" +
+		"<thead>\n<tr><th colspan=\"2\"><p>c00</p></th><th><p>c02</p></th></tr>\n</thead>\n" +
+		"<tbody>\n<tr><td><p>c10</p></td><td><p>c11</p></td><td rowspan=\"2\"><p>c12</p></td></tr>\n" +
+		"<tr><td><p>c20</p></td><td><p>c21</p></td></tr>\n</tbody>\n</table>\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("合并单元格表格不符:\n%s", out)
+	}
+	if strings.Contains(out, ">c01<") || strings.Contains(out, ">c22<") {
+		t.Errorf("被合并的单元格不应输出:\n%s", out)
+	}
+}
+
+func TestBlockToHTMLCalloutGridAndAssets(t *testing.T) {
+	bg, border, ratio1, ratio2 := 4, 4, 30, 70
+	callout := embeddedBlock("callout", BlockTypeCallout, "cp")
+	callout.Callout = &larkdocx.Callout{BackgroundColor: &bg, BorderColor: &border, EmojiId: strPtr("bulb")}
+	grid := embeddedBlock("grid", BlockTypeGrid, "col1", "col2")
+	grid.Grid = &larkdocx.Grid{}
+	col1 := embeddedBlock("col1", BlockTypeGridColumn, "img")
+	col1.GridColumn = &larkdocx.GridColumn{WidthRatio: &ratio1}
+	col2 := embeddedBlock("col2", BlockTypeGridColumn, "board")
+	col2.GridColumn = &larkdocx.GridColumn{WidthRatio: &ratio2}
+	img := embeddedBlock("img", BlockTypeImage)
+	img.Image = &larkdocx.Image{Token: strPtr("img-token")}
+	board := embeddedBlock("board", BlockTypeBoard)
+	board.Board = &larkdocx.Board{Token: strPtr("board-token")}
+	blocks := []*larkdocx.Block{
+		embeddedBlock("page", BlockTypePage, "callout", "grid"),
+		callout, htmlTextBlock("cp", BlockTypeText, htmlRun("提示", nil)),
+		grid, col1, col2, img, board,
+	}
+
+	stub := func(c *BlockToHTML) {
+		c.md.services.getMediaTempURL = func(string, client.DownloadMediaOptions) (string, error) { return "https://example.com/img", nil }
+		c.md.services.downloadFromURL = func(_, path string) error {
+			return os.WriteFile(path, []byte("\x89PNG\r\n\x1a\n0000"), 0644)
+		}
+		c.md.services.exportBoardSVG = func(token, _ string) (string, error) {
+			return `<svg xmlns="http://www.w3.org/2000/svg"></svg>`, nil
+		}
+	}
+
+	dir := t.TempDir()
+	conv := NewBlockToHTML(blocks, ConvertOptions{AssetsDir: filepath.Join(dir, "doc_assets")}, HTMLOptions{AssetsBaseDir: dir})
+	stub(conv)
+	out, err := conv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<div class="callout" style="background-color:#f0fdf4;border-color:#22c55e">` + "\n<span class=\"callout-emoji\">💡</span>",
+		`<div class="grid" style="grid-template-columns:30fr 70fr">`,
+		`<img src="doc_assets/image_1.png" alt="image">`,
+		`<img src="doc_assets/board_2.svg" alt="画板">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "doc_assets", "board_2.svg")); err != nil {
+		t.Errorf("画板 SVG 未写入资源目录: %v", err)
+	}
+
+	inline := NewBlockToHTML(blocks, ConvertOptions{AssetsDir: filepath.Join(dir, "unused")}, HTMLOptions{InlineAssets: true})
+	stub(inline)
+	out, err = inline.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `src="data:image/png;base64,`) || !strings.Contains(out, `src="data:image/svg+xml;base64,`) {
+		t.Errorf("内联模式应输出 data URI:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unused")); !os.IsNotExist(err) {
+		t.Errorf("内联模式不应创建资源目录")
+	}
+}
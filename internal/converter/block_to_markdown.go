@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
@@ -39,23 +40,26 @@ type blockToMarkdownServices struct {
 	downloadFromURL func(string, string) error
 	downloadMedia   func(string, string, client.DownloadMediaOptions) error
 	getBoardImage   func(string, string, string) (string, error)
+	getTask         func(string, string) (*client.TaskInfo, error)
+	exportBoardSVG  func(string, string) (string, error)
 }
 
 // BlockToMarkdown converts Feishu blocks to Markdown
 type BlockToMarkdown struct {
-	blocks        []*larkdocx.Block
-	blockMap      map[string]*larkdocx.Block
-	childBlockIDs map[string]bool // 子块 ID 集合，这些块不应独立处理
-	options       ConvertOptions
-	imageCount    int
-	videoCount    int
-	videoFiles    map[string]bool
-	headingSeqs   []string                   // 标题自动编号状态，按深度索引（depth-1）
-	userCache     map[string]MentionUserInfo // 用户 ID → 信息缓存
-	userResolver  UserResolver
-	syncState     *syncExpansionState
-	services      *blockToMarkdownServices
-	diagnostics   io.Writer
+	blocks         []*larkdocx.Block
+	blockMap       map[string]*larkdocx.Block
+	childBlockIDs  map[string]bool // 子块 ID 集合，这些块不应独立处理
+	options        ConvertOptions
+	imageCount     int
+	videoCount     int
+	videoFiles     map[string]bool
+	headingSeqs    []string                   // 标题自动编号状态，按深度索引（depth-1）
+	headingAnchors *headingAnchorSet          // 标题块 → 锚点
+	userCache      map[string]MentionUserInfo // 用户 ID → 信息缓存
+	userResolver   UserResolver
+	syncState      *syncExpansionState
+	services       *blockToMarkdownServices
+	diagnostics    io.Writer
 }
 
 // NewBlockToMarkdown creates a new converter
@@ -120,7 +124,7 @@ func NewBlockToMarkdown(blocks []*larkdocx.Block, options ConvertOptions) *Block
 			}
 		case BlockTypeAddOns, BlockTypeSyncSource, BlockTypeSyncReference,
 			BlockTypeAgenda, BlockTypeAgendaItem, BlockTypeAgendaItemContent,
-			BlockTypeLinkPreview:
+			BlockTypeLinkPreview, BlockTypeOKR, BlockTypeOKRObjective, BlockTypeOKRKeyResult:
 			// 容器块：子块由父块递归展开
 			if block.Children != nil {
 				for _, childID := range block.Children {
@@ -132,10 +136,11 @@ func NewBlockToMarkdown(blocks []*larkdocx.Block, options ConvertOptions) *Block
 	}
 
 	return &BlockToMarkdown{
-		blocks:        blocks,
-		blockMap:      blockMap,
-		childBlockIDs: childBlockIDs,
-		options:       options,
+		blocks:         blocks,
+		blockMap:       blockMap,
+		childBlockIDs:  childBlockIDs,
+		options:        options,
+		headingAnchors: newHeadingAnchorSet(),
 		syncState: &syncExpansionState{
 			cache:  make(map[syncReferenceKey]syncExpansionResult),
 			active: make(map[syncReferenceKey]bool),
@@ -154,6 +159,16 @@ func NewBlockToMarkdown(blocks []*larkdocx.Block, options ConvertOptions) *Block
 			getBoardImage: func(token, outputPath, userAccessToken string) (string, error) {
 				return client.GetBoardImage(token, outputPath, userAccessToken)
 			},
+			getTask: func(taskID, userAccessToken string) (*client.TaskInfo, error) {
+				return client.GetTask(taskID, userAccessToken)
+			},
+			exportBoardSVG: func(token, userAccessToken string) (string, error) {
+				result, err := client.ExportWhiteboardSVG(token, userAccessToken)
+				if err != nil {
+					return "", err
+				}
+				return result.SVG, nil
+			},
 		},
 		diagnostics: os.Stderr,
 	}
@@ -274,22 +289,27 @@ func (c *BlockToMarkdown) Convert() (string, error) {
 
 	var prevBlockType BlockType
 
-	// Process blocks in order
+	// 先筛出顶层块：跳过 page 块与子块（子块通过父块处理）
+	var top []*larkdocx.Block
 	for _, block := range c.blocks {
-		if block.BlockType == nil {
-			continue
-		}
-
-		// Skip page block
-		if *block.BlockType == int(BlockTypePage) {
+		if block.BlockType == nil || *block.BlockType == int(BlockTypePage) {
 			continue
 		}
-
-		// 跳过子块（它们会通过父块处理）
 		if block.BlockId != nil && c.childBlockIDs[*block.BlockId] {
 			continue
 		}
+		top = append(top, block)
+	}
+
+	// 文末的脚注区单独输出为 [^n]: 定义
+	footnoteStart, footnotes := footnoteSection(top)
+	if footnoteStart >= 0 {
+		top = top[:footnoteStart]
+	}
 
+	// Process blocks in order
+	for i := 0; i < len(top); i++ {
+		block := top[i]
 		currentBlockType := BlockType(*block.BlockType)
 
 		// 列表类型切换时插入额外空行
@@ -304,6 +324,19 @@ func (c *BlockToMarkdown) Convert() (string, error) {
 			}
 		}
 
+		// 带标记的术语块 + 紧随的缩进文本块 → 定义列表
+		if isDefinitionTerm(block) && i+1 < len(top) && isDefinitionDescription(top[i+1]) {
+			j := i + 1
+			for j < len(top) && isDefinitionDescription(top[j]) {
+				j++
+			}
+			sb.WriteString(c.convertDefinitionGroup(block, top[i+1:j]))
+			sb.WriteString("\n")
+			prevBlockType = currentBlockType
+			i = j - 1
+			continue
+		}
+
 		md, err := c.convertBlock(block, 0)
 		if err != nil {
 			return "", err
@@ -315,6 +348,11 @@ func (c *BlockToMarkdown) Convert() (string, error) {
 		}
 	}
 
+	if len(footnotes) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(c.convertFootnoteSection(footnotes))
+	}
+
 	output := strings.TrimRight(sb.String(), "\n") + "\n"
 
 	// 规范化连续空行（最多保留一个空行，即两个换行符）
@@ -324,6 +362,16 @@ func (c *BlockToMarkdown) Convert() (string, error) {
 	return output, nil
 }
 
+// ConvertBlockByID 把单个块（含子块）转换为 Markdown，供块级 diff 逐块渲染；块不存在时返回空字符串
+func (c *BlockToMarkdown) ConvertBlockByID(blockID string) (string, error) {
+	block := c.blockMap[blockID]
+	if block == nil || block.BlockType == nil {
+		return "", nil
+	}
+	md, err := c.convertBlock(block, 0)
+	return strings.TrimRight(md, "\n"), err
+}
+
 func (c *BlockToMarkdown) convertBlock(block *larkdocx.Block, indent int) (string, error) {
 	return c.convertBlockWithDepth(block, indent, 0)
 }
@@ -424,6 +472,15 @@ func (c *BlockToMarkdown) convertBlockWithDepth(block *larkdocx.Block, indent in
 		return c.convertWikiCatalog(block)
 	case BlockTypeISV:
 		return c.convertISV(block)
+	case BlockTypeTask:
+		return c.convertTask(block)
+	case BlockTypeOKR:
+		return c.convertOKR(block)
+	case BlockTypeOKRObjective, BlockTypeOKRKeyResult, BlockTypeOKRProgress:
+		// 由 OKR 块统一输出
+		return "", nil
+	case BlockTypeJiraIssue:
+		return c.convertJiraIssue(block)
 	case BlockTypeAgenda:
 		// 议程块：分隔线 + 递归展开子块
 		var sb strings.Builder
@@ -621,6 +678,7 @@ func (c *BlockToMarkdown) newNestedBlockToMarkdown(blocks []*larkdocx.Block, doc
 	child.videoCount = c.videoCount
 	child.videoFiles = c.videoFiles
 	child.headingSeqs = c.headingSeqs
+	child.headingAnchors = c.headingAnchors
 	return child
 }
 
@@ -796,6 +854,7 @@ func (c *BlockToMarkdown) convertHeading(block *larkdocx.Block, blockType BlockT
 	if seqPrefix != "" {
 		text = seqPrefix + text
 	}
+	c.headingAnchors.add(block, seqPrefix)
 
 	return fmt.Sprintf("%s %s\n", strings.Repeat("#", level), text), nil
 }
@@ -965,19 +1024,9 @@ func (c *BlockToMarkdown) convertImage(block *larkdocx.Block) (string, error) {
 		token = *block.Image.Token
 	}
 
-	// 提取 alt 文本（从子块中获取）
-	alt := "image"
-	if block.Children != nil {
-		for _, childID := range block.Children {
-			childBlock := c.blockMap[childID]
-			if childBlock != nil && childBlock.Text != nil {
-				extracted := c.convertTextElementsRaw(childBlock.Text.Elements)
-				if extracted != "" {
-					alt = extracted
-				}
-				break
-			}
-		}
+	alt := c.imageCaption(block)
+	if alt == "" {
+		alt = "image"
 	}
 
 	if token == "" {
@@ -994,29 +1043,8 @@ func (c *BlockToMarkdown) convertImage(block *larkdocx.Block) (string, error) {
 		}
 
 		localPath := filepath.Join(c.options.AssetsDir, filename)
-
-		dlOpts := client.DownloadMediaOptions{
-			UserAccessToken: c.options.UserAccessToken,
-			DocToken:        c.options.DocumentID,
-		}
-
-		// 方式一：获取临时 URL 后下载
-		tmpURL, urlErr := c.services.getMediaTempURL(token, dlOpts)
-		if urlErr == nil {
-			if dlErr := c.services.downloadFromURL(tmpURL, localPath); dlErr == nil {
-				return fmt.Sprintf("![%s](%s)\n", alt, localPath), nil
-			} else if c.options.Debug {
-				fmt.Fprintf(os.Stderr, "[Debug] 图片下载失败 (URL方式): %v\n", dlErr)
-			}
-		} else if c.options.Debug {
-			fmt.Fprintf(os.Stderr, "[Debug] 获取图片临时URL失败: %v\n", urlErr)
-		}
-
-		// 方式二：SDK 直接下载
-		if sdkErr := c.services.downloadMedia(token, localPath, dlOpts); sdkErr == nil {
+		if err := c.downloadImage(token, localPath); err == nil {
 			return fmt.Sprintf("![%s](%s)\n", alt, localPath), nil
-		} else if c.options.Debug {
-			fmt.Fprintf(os.Stderr, "[Debug] 图片SDK下载失败: %v\n", sdkErr)
 		}
 
 		// 全部失败，输出 <image> 标签（可 roundtrip）
@@ -1027,6 +1055,44 @@ func (c *BlockToMarkdown) convertImage(block *larkdocx.Block) (string, error) {
 	return c.formatImageTag(block.Image), nil
 }
 
+// imageCaption 从图片块的文本子块提取说明文字，没有时返回空串。
+func (c *BlockToMarkdown) imageCaption(block *larkdocx.Block) string {
+	for _, childID := range block.Children {
+		childBlock := c.blockMap[childID]
+		if childBlock != nil && childBlock.Text != nil {
+			return c.convertTextElementsRaw(childBlock.Text.Elements)
+		}
+	}
+	return ""
+}
+
+// downloadImage 下载图片素材到 localPath：先走临时 URL，失败时回落 SDK 直接下载。
+func (c *BlockToMarkdown) downloadImage(token, localPath string) error {
+	dlOpts := client.DownloadMediaOptions{
+		UserAccessToken: c.options.UserAccessToken,
+		DocToken:        c.options.DocumentID,
+	}
+
+	// 方式一：获取临时 URL 后下载
+	tmpURL, urlErr := c.services.getMediaTempURL(token, dlOpts)
+	if urlErr == nil {
+		if dlErr := c.services.downloadFromURL(tmpURL, localPath); dlErr == nil {
+			return nil
+		} else if c.options.Debug {
+			fmt.Fprintf(os.Stderr, "[Debug] 图片下载失败 (URL方式): %v\n", dlErr)
+		}
+	} else if c.options.Debug {
+		fmt.Fprintf(os.Stderr, "[Debug] 获取图片临时URL失败: %v\n", urlErr)
+	}
+
+	// 方式二：SDK 直接下载
+	sdkErr := c.services.downloadMedia(token, localPath, dlOpts)
+	if sdkErr != nil && c.options.Debug {
+		fmt.Fprintf(os.Stderr, "[Debug] 图片SDK下载失败: %v\n", sdkErr)
+	}
+	return sdkErr
+}
+
 // formatImageTag 将 Image 块格式化为 <image .../> HTML 标签
 func (c *BlockToMarkdown) formatImageTag(img *larkdocx.Image) string {
 	token := ""
@@ -1122,6 +1188,11 @@ func (c *BlockToMarkdown) convertTable(block *larkdocx.Block) (string, error) {
 	// Build markdown table
 	var sb strings.Builder
 
+	// GFM 表格无法表达合并单元格，用注释记录合并区域，导入时据此还原
+	if block.Table.Property != nil {
+		sb.WriteString(formatMergeComment(tableMergesFromInfo(block.Table.Property.MergeInfo, len(table), cols)))
+	}
+
 	// Header row
 	if len(table) > 0 {
 		sb.WriteString("| ")
@@ -1724,6 +1795,10 @@ func (c *BlockToMarkdown) convertTextElements(elements []*larkdocx.TextElement)
 				text = *elem.TextRun.Content
 			}
 
+			if n := footnoteRefOf(elem.TextRun.TextElementStyle); n > 0 {
+				result.WriteString("[^" + strconv.Itoa(n) + "]")
+				continue
+			}
 			result.WriteString(c.formatStyledText(text, elem.TextRun.TextElementStyle, nil))
 		}
 
@@ -1373,3 +1373,29 @@ func TestBlockTypeName(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockToMd_HeadingAnchors(t *testing.T) {
+	numbered := createHeadingBlock("h3", 2, "安装")
+	numbered.Heading2.Style = &larkdocx.TextStyle{Sequence: strPtr("auto")}
+	blocks := []*larkdocx.Block{
+		createHeadingBlock("h1", 1, "Getting Started!"),
+		createHeadingBlock("h2", 1, "Getting Started"),
+		numbered,
+		createTextBlock("t1", "正文"),
+	}
+
+	converter := NewBlockToMarkdown(blocks, ConvertOptions{})
+	if _, err := converter.Convert(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"h1": "getting-started", "h2": "getting-started-1", "h3": "1-安装"}
+	got := converter.HeadingAnchors()
+	if len(got) != len(want) {
+		t.Fatalf("HeadingAnchors() = %v, want %v", got, want)
+	}
+	for id, anchor := range want {
+		if got[id] != anchor {
+			t.Errorf("HeadingAnchors()[%s] = %q, want %q", id, got[id], anchor)
+		}
+	}
+}
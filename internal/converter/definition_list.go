@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// 定义列表（PHP Markdown Extra 语法）在飞书 docx 中没有对应块，映射为：
+//
+//	Term          → 以 roundTripMarker 开头、全部加粗的文本块
+//	: Description → 首行缩进一级（IndentationLevel=OneLevelIndent）的文本块，每段一个
+//
+// 导出时只把"带标记的术语块 + 紧随的缩进文本块"还原为 Term / : Description，
+// 普通文档中的加粗段落 + 缩进段落保持原样。
+
+// indentOneLevel 是 TextStyle.IndentationLevel 的一级缩进取值。
+const indentOneLevel = "OneLevelIndent"
+
+// convertDefinitionList 把 DefinitionList 展开为术语块与缩进的释义块。
+func (c *MarkdownToBlock) convertDefinitionList(node *east.DefinitionList) []*BlockNode {
+	var nodes []*BlockNode
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *east.DefinitionTerm:
+			elements := c.extractTextElements(n)
+			if !hasNonEmptyContent(elements) {
+				continue
+			}
+			for _, elem := range elements {
+				applyTextStyle(elem, true, false, false)
+			}
+			elements = append([]*larkdocx.TextElement{newMarkerRun()}, elements...)
+			nodes = append(nodes, &BlockNode{Block: newTextBlock(elements, nil)})
+		case *east.DefinitionDescription:
+			indent := indentOneLevel
+			for para := n.FirstChild(); para != nil; para = para.NextSibling() {
+				for _, line := range c.extractParagraphLines(para) {
+					if len(line) == 0 {
+						continue
+					}
+					nodes = append(nodes, &BlockNode{Block: newTextBlock(line, &larkdocx.TextStyle{IndentationLevel: &indent})})
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+func newTextBlock(elements []*larkdocx.TextElement, style *larkdocx.TextStyle) *larkdocx.Block {
+	bt := int(BlockTypeText)
+	return &larkdocx.Block{
+		BlockType: &bt,
+		Text:      &larkdocx.Text{Elements: elements, Style: style},
+	}
+}
+
+// newMarkerRun 返回只含 roundTripMarker 的加粗文本元素，与术语同样式，避免被飞书拆成单独的样式段。
+func newMarkerRun() *larkdocx.TextElement {
+	marker := roundTripMarker
+	bold := true
+	return &larkdocx.TextElement{TextRun: &larkdocx.TextRun{
+		Content:          &marker,
+		TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold},
+	}}
+}
+
+// isDefinitionTerm 判断文本块是否为导入时生成的定义列表术语：首个文本元素以 roundTripMarker 开头。
+func isDefinitionTerm(block *larkdocx.Block) bool {
+	if block == nil || block.BlockType == nil || BlockType(*block.BlockType) != BlockTypeText || block.Text == nil {
+		return false
+	}
+	if len(block.Text.Elements) == 0 {
+		return false
+	}
+	first := block.Text.Elements[0]
+	return first != nil && first.TextRun != nil && first.TextRun.Content != nil &&
+		strings.HasPrefix(*first.TextRun.Content, roundTripMarker)
+}
+
+// isDefinitionDescription 判断文本块是否为一级缩进的释义段。
+func isDefinitionDescription(block *larkdocx.Block) bool {
+	if block == nil || block.BlockType == nil || BlockType(*block.BlockType) != BlockTypeText || block.Text == nil {
+		return false
+	}
+	style := block.Text.Style
+	return style != nil && style.IndentationLevel != nil && *style.IndentationLevel == indentOneLevel
+}
+
+// convertDefinitionGroup 输出一个术语及其释义：Term 行去掉标记与加粗，释义行以 ": " 开头。
+func (c *BlockToMarkdown) convertDefinitionGroup(term *larkdocx.Block, descriptions []*larkdocx.Block) string {
+	elements := make([]*larkdocx.TextElement, 0, len(term.Text.Elements))
+	for i, elem := range term.Text.Elements {
+		if elem == nil || elem.TextRun == nil {
+			elements = append(elements, elem)
+			continue
+		}
+		run := *elem.TextRun
+		if i == 0 && run.Content != nil {
+			content := strings.TrimPrefix(*run.Content, roundTripMarker)
+			run.Content = &content
+		}
+		if run.TextElementStyle != nil {
+			style := *run.TextElementStyle
+			style.Bold = nil
+			run.TextElementStyle = &style
+		}
+		elements = append(elements, &larkdocx.TextElement{TextRun: &run})
+	}
+	var sb strings.Builder
+	sb.WriteString(c.convertTextElements(elements))
+	sb.WriteString("\n")
+	for _, desc := range descriptions {
+		sb.WriteString(": ")
+		sb.WriteString(c.convertTextElements(desc.Text.Elements))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
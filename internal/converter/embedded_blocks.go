@@ -0,0 +1,331 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/yuin/goldmark/ast"
+)
+
+// 任务 / OKR / Jira 块只保存外部对象的 ID，导出时展开为可读 Markdown，并用一对 HTML 注释包裹：
+//
+//	<!-- feishu-task id="..." -->
+//
+//	- [ ] 任务标题（负责人: 张三；截止: 2026-10-20 18:00:00）
+//
+//	<!-- /feishu-task -->
+//
+// 注释在渲染后的 Markdown 中不可见；导入时识别开始注释并重建同一个嵌入块，跳过到结束注释为止的可读内容。
+// 无法重建的场景（Jira 块 OpenAPI 不支持创建；OKR 块只能以用户身份创建）保留可读内容作为普通 Markdown 导入。
+
+const (
+	embedTaskTag = "feishu-task"
+	embedOKRTag  = "feishu-okr"
+	embedJiraTag = "feishu-jira"
+)
+
+var (
+	embedOpenCommentRe  = regexp.MustCompile(`^<!--\s*(feishu-task|feishu-okr|feishu-jira)((?:\s+[a-z_]+="[^"]*")*)\s*-->$`)
+	embedCloseCommentRe = regexp.MustCompile(`^<!--\s*/(feishu-task|feishu-okr|feishu-jira)\s*-->$`)
+	embedAttrRe         = regexp.MustCompile(`([a-z_]+)="([^"]*)"`)
+)
+
+// formatEmbedSection 用开始 / 结束注释包裹可读内容；attrs 为 key, value 交替排列，空值省略。
+func formatEmbedSection(tag string, body string, attrs ...string) string {
+	var sb strings.Builder
+	sb.WriteString("<!-- ")
+	sb.WriteString(tag)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(" %s=\"%s\"", attrs[i], strings.ReplaceAll(attrs[i+1], "\"", "&quot;")))
+	}
+	sb.WriteString(" -->\n\n")
+	sb.WriteString(body)
+	sb.WriteString("\n<!-- /")
+	sb.WriteString(tag)
+	sb.WriteString(" -->\n")
+	return sb.String()
+}
+
+// parseEmbedOpenComment 解析开始注释，返回标签名与属性。
+func parseEmbedOpenComment(raw string) (string, map[string]string, bool) {
+	m := embedOpenCommentRe.FindStringSubmatch(strings.TrimSpace(raw))
+	if m == nil {
+		return "", nil, false
+	}
+	attrs := make(map[string]string)
+	for _, am := range embedAttrRe.FindAllStringSubmatch(m[2], -1) {
+		attrs[am[1]] = strings.ReplaceAll(am[2], "&quot;", "\"")
+	}
+	return m[1], attrs, true
+}
+
+// embedCloseTag 返回结束注释对应的标签名，不是结束注释时返回空串。
+func embedCloseTag(raw string) string {
+	if m := embedCloseCommentRe.FindStringSubmatch(strings.TrimSpace(raw)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// ---------- 导出 ----------
+
+func (c *BlockToMarkdown) convertTask(block *larkdocx.Block) (string, error) {
+	if block.Task == nil || block.Task.TaskId == nil || *block.Task.TaskId == "" {
+		return "", nil
+	}
+	taskID := *block.Task.TaskId
+
+	line := fmt.Sprintf("- [ ] 任务 %s\n", taskID)
+	info, err := c.services.getTask(taskID, c.options.UserAccessToken)
+	if err != nil {
+		if c.diagnostics != nil {
+			fmt.Fprintf(c.diagnostics, "警告: 获取任务 %s 详情失败，仅导出任务 ID: %v\n", taskID, err)
+		}
+	} else if info != nil {
+		checked := " "
+		if info.CompletedAt != "" {
+			checked = "x"
+		}
+		var meta []string
+		if len(info.Assignees) > 0 {
+			meta = append(meta, "负责人: "+strings.Join(c.resolveUserNames(info.Assignees), "、"))
+		}
+		if info.DueTime != "" {
+			meta = append(meta, "截止: "+info.DueTime)
+		}
+		if info.CompletedAt != "" {
+			meta = append(meta, "完成于: "+info.CompletedAt)
+		}
+		line = fmt.Sprintf("- [%s] %s", checked, escapeMarkdown(info.Summary))
+		if len(meta) > 0 {
+			line += "（" + strings.Join(meta, "；") + "）"
+		}
+		line += "\n"
+	}
+	return formatEmbedSection(embedTaskTag, line, "id", taskID), nil
+}
+
+// resolveUserNames 把用户 ID 解析为姓名；未配置解析器或解析失败时保留原 ID。
+func (c *BlockToMarkdown) resolveUserNames(userIDs []string) []string {
+	if c.userCache == nil {
+		c.userCache = make(map[string]MentionUserInfo)
+	}
+	var missing []string
+	for _, id := range userIDs {
+		if _, ok := c.userCache[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if c.userResolver != nil && len(missing) > 0 {
+		for id, info := range c.userResolver.BatchResolve(missing) {
+			c.userCache[id] = info
+		}
+	}
+	names := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if info, ok := c.userCache[id]; ok && info.Name != "" {
+			names = append(names, info.Name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+// convertOKR 输出 OKR 块：周期标题 + 目标 / 关键结果两级列表，附进度。
+// 目标与关键结果的文本和进度随子块（OKRObjective / OKRKeyResult）返回，无需再调 OKR 接口。
+func (c *BlockToMarkdown) convertOKR(block *larkdocx.Block) (string, error) {
+	if block.Okr == nil {
+		return "", nil
+	}
+	okr := block.Okr
+
+	title := "OKR"
+	if period := firstNonEmpty(okr.PeriodNameZh, okr.PeriodNameEn); period != "" {
+		title += " · " + period
+	}
+	var sb strings.Builder
+	sb.WriteString("**" + title + "**\n\n")
+
+	var objectives []string
+	for _, childID := range block.Children {
+		obj := c.blockMap[childID]
+		if obj == nil || obj.OkrObjective == nil {
+			continue
+		}
+		o := obj.OkrObjective
+		sb.WriteString(fmt.Sprintf("- **O%d** %s%s\n", okrPosition(o.Position, len(objectives)), c.okrContent(o.Content), formatOKRProgress(o.ProgressRate)))
+
+		var krIDs []string
+		for _, krChildID := range obj.Children {
+			kr := c.blockMap[krChildID]
+			if kr == nil || kr.OkrKeyResult == nil {
+				continue
+			}
+			k := kr.OkrKeyResult
+			sb.WriteString(fmt.Sprintf("  - **KR%d** %s%s\n", okrPosition(k.Position, len(krIDs)), c.okrContent(k.Content), formatOKRProgress(k.ProgressRate)))
+			krIDs = append(krIDs, stringValue(k.KrId))
+		}
+		objectives = append(objectives, formatOKRObjectiveRef(stringValue(o.ObjectiveId), krIDs))
+	}
+
+	// 子块缺失时退回块本身记录的 Objective / KR ID
+	if len(objectives) == 0 {
+		for _, o := range okr.Objectives {
+			if o != nil && o.ObjectiveId != nil {
+				objectives = append(objectives, formatOKRObjectiveRef(*o.ObjectiveId, o.KrIds))
+			}
+		}
+	}
+
+	return formatEmbedSection(embedOKRTag, sb.String(),
+		"id", stringValue(okr.OkrId),
+		"objectives", strings.Join(objectives, ";"),
+		"user_id", stringValue(okr.UserId)), nil
+}
+
+func (c *BlockToMarkdown) okrContent(content *larkdocx.Text) string {
+	if content == nil {
+		return ""
+	}
+	return c.convertTextElements(content.Elements)
+}
+
+// okrPosition 返回 O / KR 的序号：优先使用块内 position，缺失时按出现顺序编号。
+func okrPosition(position *int, index int) int {
+	if position != nil && *position > 0 {
+		return *position
+	}
+	return index + 1
+}
+
+// formatOKRProgress 输出"（进度 40%）"；advanced 模式按 start/current/target 折算百分比。
+func formatOKRProgress(rate *larkdocx.OkrProgressRate) string {
+	if rate == nil {
+		return ""
+	}
+	var percent float64
+	switch {
+	case rate.Percent != nil:
+		percent = *rate.Percent
+	case rate.Current != nil && rate.Target != nil:
+		start := 0.0
+		if rate.Start != nil {
+			start = *rate.Start
+		}
+		if *rate.Target == start {
+			return ""
+		}
+		percent = (*rate.Current - start) / (*rate.Target - start) * 100
+	default:
+		return ""
+	}
+	return "（进度 " + strconv.FormatFloat(math.Round(percent*10)/10, 'f', -1, 64) + "%）"
+}
+
+// formatOKRObjectiveRef 把目标及其关键结果编码为 "objective_id:kr1,kr2"。
+func formatOKRObjectiveRef(objectiveID string, krIDs []string) string {
+	if len(krIDs) == 0 {
+		return objectiveID
+	}
+	return objectiveID + ":" + strings.Join(krIDs, ",")
+}
+
+func (c *BlockToMarkdown) convertJiraIssue(block *larkdocx.Block) (string, error) {
+	if block.JiraIssue == nil {
+		return "", nil
+	}
+	key := stringValue(block.JiraIssue.Key)
+	id := stringValue(block.JiraIssue.Id)
+	label := key
+	if label == "" {
+		label = id
+	}
+	return formatEmbedSection(embedJiraTag, "Jira: "+escapeMarkdown(label)+"\n", "id", id, "key", key), nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func firstNonEmpty(values ...*string) string {
+	for _, v := range values {
+		if v != nil && *v != "" {
+			return *v
+		}
+	}
+	return ""
+}
+
+// ---------- 导入 ----------
+
+// handleEmbedComment 处理 feishu-task / feishu-okr / feishu-jira 开始注释。
+// 能重建嵌入块且后面有配对的结束注释时返回该块，并由调用方跳过中间的可读内容；
+// 否则返回 nil，可读内容按普通 Markdown 导入。
+func (c *MarkdownToBlock) handleEmbedComment(node *ast.HTMLBlock, tag string, attrs map[string]string) *BlockNode {
+	if !c.hasEmbedClose(node, tag) {
+		return nil
+	}
+	switch tag {
+	case embedTaskTag:
+		taskID := attrs["id"]
+		if taskID == "" {
+			return nil
+		}
+		bt := int(BlockTypeTask)
+		return &BlockNode{Block: &larkdocx.Block{BlockType: &bt, Task: &larkdocx.Task{TaskId: &taskID}}}
+	case embedOKRTag:
+		// OKR 块只能以用户身份创建，没有 User Token 时保留可读内容
+		okrID := attrs["id"]
+		if okrID == "" || c.options.UserAccessToken == "" {
+			return nil
+		}
+		bt := int(BlockTypeOKR)
+		return &BlockNode{Block: &larkdocx.Block{BlockType: &bt, Okr: &larkdocx.Okr{
+			OkrId:      &okrID,
+			Objectives: parseOKRObjectiveRefs(attrs["objectives"]),
+		}}}
+	}
+	// Jira 块 OpenAPI 不支持创建
+	return nil
+}
+
+// hasEmbedClose 检查开始注释之后的兄弟节点中是否有配对的结束注释，避免结束注释缺失时吞掉后文。
+func (c *MarkdownToBlock) hasEmbedClose(node ast.Node, tag string) bool {
+	for n := node.NextSibling(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.HTMLBlock); ok && embedCloseTag(c.getHTMLBlockText(h)) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOKRObjectiveRefs 解析 "o1:kr1,kr2;o2"；为空时返回 nil，由服务端插入全部目标。
+func parseOKRObjectiveRefs(s string) []*larkdocx.ObjectiveIdWithKrId {
+	var refs []*larkdocx.ObjectiveIdWithKrId
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		objectiveID, krs, _ := strings.Cut(part, ":")
+		ref := &larkdocx.ObjectiveIdWithKrId{ObjectiveId: &objectiveID}
+		for _, kr := range strings.Split(krs, ",") {
+			if kr = strings.TrimSpace(kr); kr != "" {
+				ref.KrIds = append(ref.KrIds, kr)
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
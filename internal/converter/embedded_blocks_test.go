@@ -0,0 +1,160 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/riba2534/feishu-cli/internal/client"
+)
+
+type stubUserResolver map[string]MentionUserInfo
+
+func (r stubUserResolver) BatchResolve(ids []string) map[string]MentionUserInfo {
+	out := make(map[string]MentionUserInfo)
+	for _, id := range ids {
+		if info, ok := r[id]; ok {
+			out[id] = info
+		}
+	}
+	return out
+}
+
+func embeddedBlock(id string, bt BlockType, children ...string) *larkdocx.Block {
+	t := int(bt)
+	return &larkdocx.Block{BlockId: &id, BlockType: &t, Children: children}
+}
+
+func okrText(s string) *larkdocx.Text {
+	return &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &s}}}}
+}
+
+func TestExportTaskBlock(t *testing.T) {
+	page := embeddedBlock("page", BlockTypePage, "task", "missing")
+	task := embeddedBlock("task", BlockTypeTask)
+	task.Task = &larkdocx.Task{TaskId: strPtr("guid-1")}
+	missing := embeddedBlock("missing", BlockTypeTask)
+	missing.Task = &larkdocx.Task{TaskId: strPtr("guid-2")}
+
+	conv := NewBlockToMarkdownWithResolver([]*larkdocx.Block{page, task, missing}, ConvertOptions{}, stubUserResolver{"ou_a": {Name: "张三"}})
+	var diag bytes.Buffer
+	conv.diagnostics = &diag
+	conv.services.getTask = func(id, _ string) (*client.TaskInfo, error) {
+		if id != "guid-1" {
+			return nil, errors.New("无权限")
+		}
+		return &client.TaskInfo{Guid: id, Summary: "写周报", DueTime: "2026-10-20 18:00:00", CompletedAt: "2026-10-19 10:00:00", Assignees: []string{"ou_a", "ou_b"}}, nil
+	}
+	out, err := conv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<!-- feishu-task id=\"guid-1\" -->\n\n- [x] 写周报（负责人: 张三、ou_b；截止: 2026-10-20 18:00:00；完成于: 2026-10-19 10:00:00）\n\n<!-- /feishu-task -->",
+		"<!-- feishu-task id=\"guid-2\" -->\n\n- [ ] 任务 guid-2\n\n<!-- /feishu-task -->",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(diag.String(), "guid-2") {
+		t.Errorf("获取失败应输出警告: %q", diag.String())
+	}
+}
+
+func TestExportOKRAndJiraBlocks(t *testing.T) {
+	page := embeddedBlock("page", BlockTypePage, "okr", "jira")
+	okr := embeddedBlock("okr", BlockTypeOKR, "o1")
+	okr.Okr = &larkdocx.Okr{OkrId: strPtr("okr-1"), PeriodNameZh: strPtr("2026 年 10 月 - 12 月")}
+	o1 := embeddedBlock("o1", BlockTypeOKRObjective, "kr1", "kr2")
+	percent := 40.0
+	o1.OkrObjective = &larkdocx.OkrObjective{ObjectiveId: strPtr("obj-1"), Position: intPtr(1), Content: okrText("提升导出质量"), ProgressRate: &larkdocx.OkrProgressRate{Percent: &percent}}
+	kr1 := embeddedBlock("kr1", BlockTypeOKRKeyResult)
+	start, current, target := 0.0, 3.0, 4.0
+	kr1.OkrKeyResult = &larkdocx.OkrKeyResult{KrId: strPtr("kr-1"), Position: intPtr(1), Content: okrText("覆盖嵌入块"), ProgressRate: &larkdocx.OkrProgressRate{Start: &start, Current: &current, Target: &target}}
+	kr2 := embeddedBlock("kr2", BlockTypeOKRKeyResult)
+	kr2.OkrKeyResult = &larkdocx.OkrKeyResult{KrId: strPtr("kr-2"), Position: intPtr(2), Content: okrText("补齐文档")}
+	jira := embeddedBlock("jira", BlockTypeJiraIssue)
+	jira.JiraIssue = &larkdocx.JiraIssue{Id: strPtr("10001"), Key: strPtr("PROJ-1")}
+
+	out, err := NewBlockToMarkdown([]*larkdocx.Block{page, okr, o1, kr1, kr2, jira}, ConvertOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOKR := "<!-- feishu-okr id=\"okr-1\" objectives=\"obj-1:kr-1,kr-2\" -->\n\n" +
+		"**OKR · 2026 年 10 月 - 12 月**\n\n" +
+		"- **O1** 提升导出质量（进度 40%）\n" +
+		"  - **KR1** 覆盖嵌入块（进度 75%）\n" +
+		"  - **KR2** 补齐文档\n\n" +
+		"<!-- /feishu-okr -->"
+	if !strings.Contains(out, wantOKR) {
+		t.Errorf("OKR 输出不符:\n%s", out)
+	}
+	if strings.Count(out, "覆盖嵌入块") != 1 {
+		t.Errorf("OKR 子块不应再被独立输出:\n%s", out)
+	}
+	if !strings.Contains(out, "<!-- feishu-jira id=\"10001\" key=\"PROJ-1\" -->\n\nJira: PROJ-1\n\n<!-- /feishu-jira -->") {
+		t.Errorf("Jira 输出不符:\n%s", out)
+	}
+}
+
+func TestImportEmbeddedBlockComments(t *testing.T) {
+	md := "前文\n\n" +
+		"<!-- feishu-task id=\"guid-1\" -->\n\n- [x] 写周报（截止: 2026-10-20 18:00:00）\n\n<!-- /feishu-task -->\n\n" +
+		"<!-- feishu-okr id=\"okr-1\" objectives=\"obj-1:kr-1,kr-2;obj-2\" -->\n\n**OKR**\n\n- **O1** 目标\n\n<!-- /feishu-okr -->\n\n" +
+		"<!-- feishu-jira id=\"10001\" key=\"PROJ-1\" -->\n\nJira: PROJ-1\n\n<!-- /feishu-jira -->\n\n" +
+		"后文\n"
+
+	convert := func(opts ConvertOptions) []*larkdocx.Block {
+		t.Helper()
+		blocks, err := NewMarkdownToBlock([]byte(md), opts, "").Convert()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blocks
+	}
+
+	blocks := convert(ConvertOptions{UserAccessToken: "u-test"})
+	var types []BlockType
+	for _, b := range blocks {
+		types = append(types, BlockType(*b.BlockType))
+	}
+	want := []BlockType{BlockTypeText, BlockTypeTask, BlockTypeOKR, BlockTypeText, BlockTypeText}
+	if len(types) != len(want) {
+		t.Fatalf("块类型 = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("块类型 = %v, want %v", types, want)
+		}
+	}
+	if *blocks[1].Task.TaskId != "guid-1" {
+		t.Errorf("task_id = %s", *blocks[1].Task.TaskId)
+	}
+	objs := blocks[2].Okr.Objectives
+	if *blocks[2].Okr.OkrId != "okr-1" || len(objs) != 2 || *objs[0].ObjectiveId != "obj-1" ||
+		strings.Join(objs[0].KrIds, ",") != "kr-1,kr-2" || *objs[1].ObjectiveId != "obj-2" || objs[1].KrIds != nil {
+		t.Errorf("OKR = %+v", blocks[2].Okr)
+	}
+	if got := textOf(blocks[3].Text.Elements); got != "Jira: PROJ-1" {
+		t.Errorf("Jira 应保留可读内容，得到 %q", got)
+	}
+
+	// 无 User Token 时 OKR 块无法创建，保留可读内容
+	for _, b := range convert(ConvertOptions{}) {
+		if BlockType(*b.BlockType) == BlockTypeOKR {
+			t.Fatal("无 User Token 时不应重建 OKR 块")
+		}
+	}
+
+	// 缺少结束注释时不重建，也不吞掉后文
+	blocks, err := NewMarkdownToBlock([]byte("<!-- feishu-task id=\"guid-1\" -->\n\n- [ ] 写周报\n\n后文\n"), ConvertOptions{}, "").Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || BlockType(*blocks[0].BlockType) != BlockTypeTodo || textOf(blocks[1].Text.Elements) != "后文" {
+		t.Errorf("缺少结束注释时应按普通 Markdown 导入: %+v", blocks)
+	}
+}
@@ -0,0 +1,171 @@
+package converter
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// 飞书 docx 没有脚注块、上标样式，也不支持指向文档内块的链接，脚注按以下约定落地：
+//
+//	正文 [^label]   → 上标编号文本 ⁽ⁿ⁾，链接到本文档并带 #feishu-fn-n 片段（n 为首次引用顺序，与 goldmark 的编号一致）
+//	[^label]: 内容  → 文档末尾自动生成的 "Footnotes" 二级标题（以 roundTripMarker 开头）+ 有序列表，第 n 项即脚注 n
+//
+// 导出时只还原带标记的结构：#feishu-fn-n 链接还原为 [^n]，带标记的标题及其后只含有序列表的区段
+// 还原为 [^n]: 定义。普通文档里的上标文字或名为 "Footnotes" 的标题不受影响。原始 label 不保留，往返后统一为数字。
+
+// FootnotesHeading 是导入时生成的脚注区标题（不含 roundTripMarker）。
+const FootnotesHeading = "Footnotes"
+
+// roundTripMarker 是导入时写入块文本开头的不可见字符（U+2063 INVISIBLE SEPARATOR），
+// 标记该块由定义列表 / 脚注语法生成，导出时据此还原，作用与 Markdown 侧的 feishu-merge 注释相同。
+const roundTripMarker = "\u2063"
+
+// footnoteRefFragment 是脚注引用链接的 URL 片段前缀，后接脚注编号。
+const footnoteRefFragment = "#feishu-fn-"
+
+const superscriptDigits = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+
+var footnoteRefURLRe = regexp.MustCompile(regexp.QuoteMeta(footnoteRefFragment) + `([0-9]+)$`)
+
+// footnoteMarker 返回脚注 n 在正文中的上标标记，如 12 → ⁽¹²⁾。
+func footnoteMarker(n int) string {
+	digits := []rune(superscriptDigits)
+	var sb strings.Builder
+	sb.WriteString("⁽")
+	for _, d := range strconv.Itoa(n) {
+		sb.WriteRune(digits[d-'0'])
+	}
+	sb.WriteString("⁾")
+	return sb.String()
+}
+
+// footnoteRefURL 返回脚注 n 的引用链接：指向本文档（documentID 为空时指向飞书首页），片段携带编号。
+func footnoteRefURL(documentID string, n int) string {
+	base := "https://feishu.cn/"
+	if documentID != "" {
+		base += "docx/" + documentID
+	}
+	return base + footnoteRefFragment + strconv.Itoa(n)
+}
+
+// footnoteRefNumber 从导出的链接 URL 中取出脚注编号；不是脚注引用链接时返回 0。
+func footnoteRefNumber(linkURL string) int {
+	if decoded, err := url.PathUnescape(linkURL); err == nil {
+		linkURL = decoded
+	}
+	m := footnoteRefURLRe.FindStringSubmatch(linkURL)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// footnoteRefOf 返回脚注引用链接对应的编号；不是脚注引用时返回 0。
+func footnoteRefOf(style *larkdocx.TextElementStyle) int {
+	if style == nil || style.Link == nil || style.Link.Url == nil {
+		return 0
+	}
+	return footnoteRefNumber(*style.Link.Url)
+}
+
+// inlineFootnoteRefs 把 FootnoteLink 替换为指向 footnoteRefURL 的上标编号链接、删去 goldmark 追加的 FootnoteBacklink。
+// 换成 ast.Link 后，段落 / 列表 / 引用 / 表格等所有内联提取路径都能原样带出编号和链接。
+func inlineFootnoteRefs(doc ast.Node, documentID string) {
+	var links, backlinks []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *east.FootnoteLink:
+			links = append(links, n)
+		case *east.FootnoteBacklink:
+			backlinks = append(backlinks, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, n := range links {
+		index := n.(*east.FootnoteLink).Index
+		link := ast.NewLink()
+		link.Destination = []byte(footnoteRefURL(documentID, index))
+		link.AppendChild(link, ast.NewString([]byte(footnoteMarker(index))))
+		parent := n.Parent()
+		parent.ReplaceChild(parent, n, link)
+	}
+	for _, n := range backlinks {
+		n.Parent().RemoveChild(n.Parent(), n)
+	}
+}
+
+// convertFootnoteList 把 goldmark 汇总到文末的 FootnoteList 转为 "Footnotes" 标题 + 有序列表。
+// 一条脚注内的多段 / 多行合并为一个列表项，以空格连接。
+func (c *MarkdownToBlock) convertFootnoteList(list *east.FootnoteList) []*BlockNode {
+	var items []*BlockNode
+	for fn := list.FirstChild(); fn != nil; fn = fn.NextSibling() {
+		var elements []*larkdocx.TextElement
+		for para := fn.FirstChild(); para != nil; para = para.NextSibling() {
+			for _, line := range c.extractParagraphLines(para) {
+				if len(line) == 0 {
+					continue
+				}
+				if len(elements) > 0 {
+					space := " "
+					elements = append(elements, &larkdocx.TextElement{TextRun: &larkdocx.TextRun{Content: &space}})
+				}
+				elements = append(elements, line...)
+			}
+		}
+		if !hasNonEmptyContent(elements) {
+			empty := ""
+			elements = []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &empty}}}
+		}
+		bt := int(BlockTypeOrdered)
+		items = append(items, &BlockNode{Block: &larkdocx.Block{
+			BlockType: &bt,
+			Ordered:   &larkdocx.Text{Elements: elements},
+		}})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	title := roundTripMarker + FootnotesHeading
+	bt := int(BlockTypeHeading2)
+	heading := &BlockNode{Block: &larkdocx.Block{
+		BlockType: &bt,
+		Heading2:  &larkdocx.Text{Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &title}}}},
+	}}
+	return append([]*BlockNode{heading}, items...)
+}
+
+// footnoteSection 在顶层块中识别文末脚注区：以 roundTripMarker 开头的标题，其后直到文末只有有序列表。
+// 命中时返回标题下标与各脚注块，否则返回 -1。
+func footnoteSection(top []*larkdocx.Block) (int, []*larkdocx.Block) {
+	for i := len(top) - 1; i >= 0; i-- {
+		if BlockType(*top[i].BlockType) == BlockTypeOrdered && top[i].Ordered != nil {
+			continue
+		}
+		if _, text, ok := HeadingInfo(top[i]); ok && strings.HasPrefix(text, roundTripMarker) && i < len(top)-1 {
+			return i, top[i+1:]
+		}
+		return -1, nil
+	}
+	return -1, nil
+}
+
+// convertFootnoteSection 输出 [^n]: 定义行。
+func (c *BlockToMarkdown) convertFootnoteSection(notes []*larkdocx.Block) string {
+	var sb strings.Builder
+	for i, note := range notes {
+		sb.WriteString("[^" + strconv.Itoa(i+1) + "]: ")
+		sb.WriteString(c.convertTextElements(note.Ordered.Elements))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
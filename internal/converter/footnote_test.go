@@ -0,0 +1,112 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func TestFootnoteImport(t *testing.T) {
+	md := "RFC 草案[^rfc]，参见实现[^impl]。再次引用[^rfc]。\n\n[^rfc]: RFC 9110 *HTTP Semantics*\n[^impl]: 见 `internal/client`\n"
+	conv := NewMarkdownToBlock([]byte(md), ConvertOptions{DocumentID: "doxcn1"}, "")
+	blocks, err := conv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("期望 正文 + 标题 + 2 条脚注共 4 块，得到 %d", len(blocks))
+	}
+	body := textOf(blocks[0].Text.Elements)
+	if body != "RFC 草案⁽¹⁾，参见实现⁽²⁾。再次引用⁽¹⁾。" {
+		t.Errorf("正文 = %q", body)
+	}
+	ref := blocks[0].Text.Elements[1].TextRun
+	if *ref.Content != "⁽¹⁾" || ref.TextElementStyle == nil || ref.TextElementStyle.Link == nil ||
+		*ref.TextElementStyle.Link.Url != "https://feishu.cn/docx/doxcn1#feishu-fn-1" {
+		t.Errorf("脚注引用应为指向本文档的上标链接: %+v", ref)
+	}
+	if _, title, ok := HeadingInfo(blocks[1]); !ok || title != roundTripMarker+FootnotesHeading {
+		t.Errorf("第 2 块应为 %s 标题，得到 %+v", FootnotesHeading, blocks[1])
+	}
+	if got := textOf(blocks[2].Ordered.Elements); got != "RFC 9110 HTTP Semantics" {
+		t.Errorf("脚注 1 = %q", got)
+	}
+	if got := textOf(blocks[3].Ordered.Elements); got != "见 internal/client" {
+		t.Errorf("脚注 2 = %q", got)
+	}
+}
+
+func TestFootnoteRoundTrip(t *testing.T) {
+	md := "正文[^a]与更多[^b]。\n\n[^a]: 第一条 **加粗**\n[^b]: 第二条\n"
+	out := roundTrip(t, md)
+	want := "正文[^1]与更多[^2]。\n\n[^1]: 第一条 **加粗**\n[^2]: 第二条\n"
+	if out != want {
+		t.Errorf("往返不一致:\n  输出: %q\n  期望: %q", out, want)
+	}
+	// 再走一轮保持稳定
+	if again := roundTrip(t, out); again != want {
+		t.Errorf("二次往返不稳定: %q", again)
+	}
+}
+
+func TestFootnoteMarkerWithoutSection(t *testing.T) {
+	// 没有导入标记时上标文本与 Footnotes 标题原样保留，不臆造 [^n]
+	md := "面积 10 m² 与标注⁽¹⁾\n\n## Footnotes\n\n1. 普通列表\n"
+	out := roundTrip(t, md)
+	if out != md {
+		t.Errorf("无标记时不应改写:\n  输出: %q\n  期望: %q", out, md)
+	}
+	if got := footnoteMarker(30); got != "⁽³⁰⁾" {
+		t.Errorf("footnoteMarker(30) = %q", got)
+	}
+	if got := footnoteRefNumber("https%3A%2F%2Ffeishu.cn%2Fdocx%2Fdoxcn1%23feishu-fn-12"); got != 12 {
+		t.Errorf("footnoteRefNumber = %d", got)
+	}
+	if got := footnoteRefNumber("https://example.com/#fn-1"); got != 0 {
+		t.Errorf("普通链接不应识别为脚注引用: %d", got)
+	}
+}
+
+func TestDefinitionListUnmarkedBlocks(t *testing.T) {
+	// 普通文档中的 加粗段落 + 缩进段落 不应被改写为定义列表
+	bold, indent := true, indentOneLevel
+	term, desc := "重要", "缩进的说明"
+	blocks := []*larkdocx.Block{
+		newTextBlock([]*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &term, TextElementStyle: &larkdocx.TextElementStyle{Bold: &bold}}}}, nil),
+		newTextBlock([]*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &desc}}}, &larkdocx.TextStyle{IndentationLevel: &indent}),
+	}
+	out, err := NewBlockToMarkdown(blocks, ConvertOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, ": ") || !strings.Contains(out, "**重要**") {
+		t.Errorf("未标记的块不应转为定义列表: %q", out)
+	}
+}
+
+func TestDefinitionListRoundTrip(t *testing.T) {
+	md := "术语 `API`\n: 应用编程接口\n: 第二条释义\n\n另一个术语\n: 说明文字\n\n普通段落\n"
+	conv := NewMarkdownToBlock([]byte(md), ConvertOptions{}, "")
+	blocks, err := conv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isDefinitionTerm(blocks[0]) || !isDefinitionDescription(blocks[1]) || !isDefinitionDescription(blocks[2]) {
+		t.Fatalf("定义列表结构不符: %+v", blocks[:3])
+	}
+	out := roundTrip(t, md)
+	if out != md {
+		t.Errorf("往返不一致:\n  输出: %q\n  期望: %q", out, md)
+	}
+}
+
+func textOf(elements []*larkdocx.TextElement) string {
+	var sb strings.Builder
+	for _, elem := range elements {
+		if elem.TextRun != nil && elem.TextRun.Content != nil {
+			sb.WriteString(*elem.TextRun.Content)
+		}
+	}
+	return sb.String()
+}
@@ -1,7 +1,9 @@
 package converter
 
 import (
+	"fmt"
 	"strings"
+	"unicode"
 
 	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
 )
@@ -25,3 +27,58 @@ func HeadingInfo(block *larkdocx.Block) (int, string, bool) {
 	}
 	return int(bt) - int(BlockTypeHeading1) + 1, strings.TrimSpace(sb.String()), true
 }
+
+// HeadingAnchor 按 GitHub 的规则把标题文本转为锚点：转小写，去掉除 - 和 _ 以外的标点符号，空格换成 -。
+func HeadingAnchor(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			sb.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// headingAnchorSet 记录导出时每个标题块对应的锚点，同名标题依次追加 -1、-2（与 GitHub 一致）。
+// 同步块展开时父子转换器共享同一个集合，保证整篇文档内锚点唯一。
+type headingAnchorSet struct {
+	byBlock map[string]string
+	counts  map[string]int
+}
+
+func newHeadingAnchorSet() *headingAnchorSet {
+	return &headingAnchorSet{byBlock: make(map[string]string), counts: make(map[string]int)}
+}
+
+func (s *headingAnchorSet) add(block *larkdocx.Block, seqPrefix string) {
+	if s == nil || block == nil || block.BlockId == nil {
+		return
+	}
+	if _, ok := s.byBlock[*block.BlockId]; ok {
+		return
+	}
+	_, text, ok := HeadingInfo(block)
+	if !ok {
+		return
+	}
+	anchor := HeadingAnchor(seqPrefix + text)
+	if n := s.counts[anchor]; n > 0 {
+		s.counts[anchor] = n + 1
+		anchor = fmt.Sprintf("%s-%d", anchor, n)
+	} else {
+		s.counts[anchor] = 1
+	}
+	s.byBlock[*block.BlockId] = anchor
+}
+
+// HeadingAnchors 返回 Convert 输出中每个标题块的锚点（block_id → 不含 # 的锚点），
+// 供 wiki export-tree 把指向标题块的链接改写为 本地文件#锚点。
+func (c *BlockToMarkdown) HeadingAnchors() map[string]string {
+	if c.headingAnchors == nil {
+		return nil
+	}
+	return c.headingAnchors.byBlock
+}
@@ -167,3 +167,31 @@ func mapObjTypeToDocType(objType *int) string {
 		return "docx"
 	}
 }
+
+// supportedBlockHTMLTags 是 handleBlockHTMLTag 能转换的块级标签，其余块级 HTML 整段会被丢弃
+var supportedBlockHTMLTags = map[string]bool{
+	blockEquationHTMLTag: true,
+	"image":              true,
+	"callout":            true,
+	"grid":               true,
+	"whiteboard":         true,
+	"sheet":              true,
+	"bitable":            true,
+	"file":               true,
+	"video":              true,
+}
+
+// supportedInlineHTMLTags 是行内提取能识别的标签，其余行内标签被忽略、只保留其中文字
+var supportedInlineHTMLTags = map[string]bool{
+	"br":           true,
+	"u":            true,
+	"mention-user": true,
+	"mention-doc":  true,
+}
+
+// htmlStyleTags 是常见的 HTML 样式标签：导入时样式丢失，只保留文字
+var htmlStyleTags = map[string]bool{
+	"b": true, "strong": true, "i": true, "em": true, "s": true, "del": true, "strike": true,
+	"ins": true, "sup": true, "sub": true, "span": true, "font": true, "small": true, "big": true,
+	"kbd": true, "code": true, "center": true,
+}
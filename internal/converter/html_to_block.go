@@ -0,0 +1,969 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+	"golang.org/x/net/html"
+)
+
+// HTMLToBlock 把 Confluence storage format（XHTML + ac:/ri: 宏）或普通 HTML 转换为与
+// MarkdownToBlock 相同的 BlockNode 树和 TableData，供 doc import 的三阶段流水线直接复用。
+//
+// 宏映射：info/note/tip/warning/panel → 高亮块，code/noformat → 代码块（保留语言），
+// expand → 引用容器（首行为标题），附件（ac:image、view-file 等）→ 待上传的图片/文件块。
+// 无法识别的宏保留其正文，没有正文时输出 [Confluence 宏: name] 占位并提示。
+type HTMLToBlock struct {
+	md             *MarkdownToBlock // 复用图片/视频上传登记、建表拆分与列宽计算
+	source         []byte
+	attachmentsDir string // Confluence 附件所在目录，ri:attachment 的文件名相对它解析
+	tableDatas     []*TableData
+	nested         int             // >0 表示位于高亮块/引用/列表等容器内，表格无法作为顶层块创建
+	inCell         int             // >0 表示位于表格单元格内，图片和附件降级为文字
+	warned         map[string]bool // 已提示过的宏名，避免重复刷屏
+}
+
+// NewHTMLToBlock 创建 HTML 转换器。basePath 用于解析相对图片路径；attachmentsDir 为空时
+// 附件文件名同样相对 basePath 解析。
+func NewHTMLToBlock(source []byte, options ConvertOptions, basePath, attachmentsDir string) *HTMLToBlock {
+	return &HTMLToBlock{
+		md:             NewMarkdownToBlock(nil, options, basePath),
+		source:         source,
+		attachmentsDir: attachmentsDir,
+		warned:         make(map[string]bool),
+	}
+}
+
+// htmlNode 是解析后的轻量 DOM 节点。元素名与属性名统一小写并保留命名空间前缀（如 "ac:structured-macro"）；
+// 文本节点 name 为空。
+type htmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+}
+
+// htmlVoidElements 是没有结束标签的 HTML 元素。
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlSkippedElements 内容不参与转换的元素。
+var htmlSkippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "title": true, "noscript": true, "template": true,
+	"ac:placeholder": true,
+}
+
+var (
+	htmlScriptRe     = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	htmlStyleRe      = regexp.MustCompile(`(?is)<style\b.*?</style\s*>`)
+	htmlXMLDeclRe    = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+	htmlStartTagRe   = regexp.MustCompile(`<[A-Za-z][^<>]*>`)
+	htmlUnquotedAttr = regexp.MustCompile(`(\s[A-Za-z_:][-A-Za-z0-9_:.]*)=([^\s"'=<>]+)`)
+	htmlSpaceRe      = regexp.MustCompile(`[ \t\r\n\f\x{00a0}]+`)
+	htmlConfluenceRe = regexp.MustCompile(`(?i)<(ac|ri):[a-z]`)
+)
+
+// parseHTMLTree 解析源文件：含 ac:/ri: 元素的 Confluence storage format 走 XHTML 容错解析，
+// 其余按 HTML5 规范解析，隐式闭合的 <li>、<td>、<p> 等由 golang.org/x/net/html 补全。
+func parseHTMLTree(source []byte) (*htmlNode, error) {
+	if htmlConfluenceRe.Match(source) {
+		return parseXHTMLTree(source)
+	}
+	doc, err := html.Parse(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("解析 HTML 失败: %w", err)
+	}
+	root := &htmlNode{name: "feishu-root"}
+	appendHTML5Children(root, doc)
+	return root, nil
+}
+
+// appendHTML5Children 把 x/net/html 的节点转换为 htmlNode，注释与 doctype 丢弃。
+func appendHTML5Children(dst *htmlNode, src *html.Node) {
+	for c := src.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			dst.children = append(dst.children, &htmlNode{text: c.Data})
+		case html.ElementNode:
+			n := &htmlNode{name: strings.ToLower(c.Data), attrs: make(map[string]string, len(c.Attr))}
+			for _, a := range c.Attr {
+				key := a.Key
+				if a.Namespace != "" {
+					key = a.Namespace + ":" + a.Key
+				}
+				n.attrs[strings.ToLower(key)] = a.Val
+			}
+			appendHTML5Children(n, c)
+			dst.children = append(dst.children, n)
+		}
+	}
+}
+
+// parseXHTMLTree 容错解析 Confluence storage format（XHTML）：标签不配对时按栈就近闭合，空元素无需结束标签，
+// 支持 HTML 实体和未声明的命名空间前缀（ac:/ri:）。
+func parseXHTMLTree(source []byte) (*htmlNode, error) {
+	src := htmlXMLDeclRe.ReplaceAll(source, nil)
+	src = htmlScriptRe.ReplaceAll(src, nil)
+	src = htmlStyleRe.ReplaceAll(src, nil)
+	// encoding/xml 不接受未加引号的属性值（<img src=a.png>），先补上引号
+	src = htmlStartTagRe.ReplaceAllFunc(src, func(tag []byte) []byte {
+		return htmlUnquotedAttr.ReplaceAll(tag, []byte(`$1="$2"`))
+	})
+
+	dec := xml.NewDecoder(io.MultiReader(strings.NewReader("<feishu-root>"), bytes.NewReader(src), strings.NewReader("</feishu-root>")))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+
+	root := &htmlNode{name: "feishu-root"}
+	stack := []*htmlNode{root}
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 HTML 失败: %w", err)
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &htmlNode{name: htmlName(t.Name), attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs[htmlName(a.Name)] = a.Value
+			}
+			top.children = append(top.children, n)
+			if !htmlVoidElements[n.name] {
+				stack = append(stack, n)
+			}
+		case xml.EndElement:
+			name := htmlName(t.Name)
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		case xml.CharData:
+			top.children = append(top.children, &htmlNode{text: string(t)})
+		}
+	}
+	return root, nil
+}
+
+func htmlName(n xml.Name) string {
+	if n.Space != "" {
+		return strings.ToLower(n.Space + ":" + n.Local)
+	}
+	return strings.ToLower(n.Local)
+}
+
+// textContent 返回节点下全部文本（不折叠空白）。
+func (n *htmlNode) textContent() string {
+	if n.name == "" {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, child := range n.children {
+		sb.WriteString(child.textContent())
+	}
+	return sb.String()
+}
+
+// child 返回第一个名为 name 的直接子元素。
+func (n *htmlNode) child(name string) *htmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// macroParam 返回宏的 ac:parameter 子元素。
+func (n *htmlNode) macroParam(name string) *htmlNode {
+	for _, c := range n.children {
+		if c.name == "ac:parameter" && strings.EqualFold(c.attrs["ac:name"], name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *htmlNode) macroParamText(name string) string {
+	if p := n.macroParam(name); p != nil {
+		return strings.TrimSpace(p.textContent())
+	}
+	return ""
+}
+
+// ConvertWithTableData 执行转换，返回块树、表格数据以及待上传图片/附件的来源。
+func (c *HTMLToBlock) ConvertWithTableData() (*ConvertResult, error) {
+	root, err := parseHTMLTree(c.source)
+	if err != nil {
+		return nil, err
+	}
+	nodes := c.convertChildren(root)
+	return &ConvertResult{
+		BlockNodes:   nodes,
+		TableDatas:   c.tableDatas,
+		ImageStats:   c.md.imageStats,
+		ImageSources: c.md.imageSources,
+		VideoStats:   c.md.videoStats,
+		VideoSources: c.md.videoSources,
+	}, nil
+}
+
+// htmlInlineStyle 是行内元素累积的文本样式。
+type htmlInlineStyle struct {
+	bold, italic, strike, underline, code bool
+	link                                  string
+}
+
+func (st htmlInlineStyle) element(text string) *larkdocx.TextElement {
+	var elem *larkdocx.TextElement
+	if st.link != "" {
+		elem = createLinkElement(text, st.link)
+	} else {
+		elem = &larkdocx.TextElement{TextRun: &larkdocx.TextRun{Content: &text}}
+	}
+	applyTextStyle(elem, st.bold, st.italic, st.strike)
+	if st.underline || st.code {
+		if elem.TextRun.TextElementStyle == nil {
+			elem.TextRun.TextElementStyle = &larkdocx.TextElementStyle{}
+		}
+		if st.underline {
+			underline := true
+			elem.TextRun.TextElementStyle.Underline = &underline
+		}
+		if st.code {
+			code := true
+			elem.TextRun.TextElementStyle.InlineCode = &code
+		}
+	}
+	return elem
+}
+
+// htmlFlow 收集一段连续的行内内容，遇到块级元素时把已收集的文字落为一个 Text 块。
+type htmlFlow struct {
+	nodes []*BlockNode
+	elems []*larkdocx.TextElement
+}
+
+func (f *htmlFlow) addText(text string, st htmlInlineStyle) {
+	text = htmlSpaceRe.ReplaceAllString(text, " ")
+	if text == "" {
+		return
+	}
+	// 折叠跨元素的连续空白，段首空白直接丢弃
+	if strings.HasPrefix(text, " ") && (len(f.elems) == 0 || strings.HasSuffix(elementText(f.elems[len(f.elems)-1]), " ") ||
+		strings.HasSuffix(elementText(f.elems[len(f.elems)-1]), "\n")) {
+		text = text[1:]
+	}
+	if text == "" {
+		return
+	}
+	f.elems = append(f.elems, st.element(text))
+}
+
+func (f *htmlFlow) addBreak() {
+	f.elems = append(f.elems, htmlInlineStyle{}.element("\n"))
+}
+
+func (f *htmlFlow) add(nodes ...*BlockNode) {
+	f.flush()
+	f.nodes = append(f.nodes, nodes...)
+}
+
+// takeElements 取出已收集的行内元素（去掉首尾空白），并清空缓冲。
+func (f *htmlFlow) takeElements() []*larkdocx.TextElement {
+	elems := mergeAdjacentPlainTextRuns(f.elems)
+	f.elems = nil
+	for len(elems) > 0 {
+		first := elems[0].TextRun
+		if first == nil || first.Content == nil {
+			break
+		}
+		trimmed := strings.TrimLeft(*first.Content, " \n")
+		if trimmed != "" {
+			first.Content = &trimmed
+			break
+		}
+		elems = elems[1:]
+	}
+	for len(elems) > 0 {
+		last := elems[len(elems)-1].TextRun
+		if last == nil || last.Content == nil {
+			break
+		}
+		trimmed := strings.TrimRight(*last.Content, " \n")
+		if trimmed != "" {
+			last.Content = &trimmed
+			break
+		}
+		elems = elems[:len(elems)-1]
+	}
+	return elems
+}
+
+func (f *htmlFlow) flush() {
+	elems := f.takeElements()
+	if len(elems) > 0 && hasNonEmptyContent(elems) {
+		f.nodes = append(f.nodes, textBlockNode(elems))
+	}
+}
+
+func elementText(e *larkdocx.TextElement) string {
+	if e == nil || e.TextRun == nil || e.TextRun.Content == nil {
+		return ""
+	}
+	return *e.TextRun.Content
+}
+
+func textBlockNode(elems []*larkdocx.TextElement) *BlockNode {
+	blockType := int(BlockTypeText)
+	return &BlockNode{Block: &larkdocx.Block{BlockType: &blockType, Text: &larkdocx.Text{Elements: elems}}}
+}
+
+func boldTextBlockNode(text string) *BlockNode {
+	return textBlockNode([]*larkdocx.TextElement{htmlInlineStyle{bold: true}.element(text)})
+}
+
+// convertChildren 把元素的子节点转换为块序列。
+func (c *HTMLToBlock) convertChildren(n *htmlNode) []*BlockNode {
+	f := &htmlFlow{}
+	for _, child := range n.children {
+		c.walk(f, child, htmlInlineStyle{})
+	}
+	f.flush()
+	return f.nodes
+}
+
+// convertNested 在容器（高亮块、引用、列表项等）内转换子节点。
+func (c *HTMLToBlock) convertNested(n *htmlNode) []*BlockNode {
+	c.nested++
+	defer func() { c.nested-- }()
+	return c.convertChildren(n)
+}
+
+// inlineElements 只收集元素内的行内文字（用于标题、单元格等只能承载文本的位置）。
+func (c *HTMLToBlock) inlineElements(n *htmlNode) []*larkdocx.TextElement {
+	c.inCell++
+	defer func() { c.inCell-- }()
+	f := &htmlFlow{}
+	for _, child := range n.children {
+		c.walk(f, child, htmlInlineStyle{})
+	}
+	// 块级子元素在这里按换行拼接回文字
+	var elems []*larkdocx.TextElement
+	for _, node := range f.nodes {
+		if text := blockTextElements(node.Block); len(text) > 0 {
+			if len(elems) > 0 {
+				elems = append(elems, htmlInlineStyle{}.element("\n"))
+			}
+			elems = append(elems, text...)
+		}
+	}
+	if rest := f.takeElements(); len(rest) > 0 {
+		if len(elems) > 0 {
+			elems = append(elems, htmlInlineStyle{}.element("\n"))
+		}
+		elems = append(elems, rest...)
+	}
+	return elems
+}
+
+// blockTextElements 返回文本类块的行内元素。
+func blockTextElements(b *larkdocx.Block) []*larkdocx.TextElement {
+	for _, t := range []*larkdocx.Text{b.Text, b.Heading1, b.Heading2, b.Heading3, b.Heading4, b.Heading5, b.Heading6,
+		b.Bullet, b.Ordered, b.Todo, b.Code, b.Quote} {
+		if t != nil {
+			return t.Elements
+		}
+	}
+	return nil
+}
+
+func (c *HTMLToBlock) walk(f *htmlFlow, n *htmlNode, st htmlInlineStyle) {
+	if n.name == "" {
+		f.addText(n.text, st)
+		return
+	}
+	if htmlSkippedElements[n.name] {
+		return
+	}
+
+	switch n.name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		f.add(c.headingNode(n))
+	case "ul", "ol":
+		f.add(c.convertList(n, n.name == "ol")...)
+	case "ac:task-list":
+		f.add(c.convertTaskList(n)...)
+	case "pre":
+		lang := codeLanguageFromClass(n.attrs["class"])
+		if code := n.child("code"); code != nil && lang == "" {
+			lang = codeLanguageFromClass(code.attrs["class"])
+		}
+		f.add(codeBlockNode(n.textContent(), lang))
+	case "blockquote":
+		f.add(c.quoteNode("", n))
+	case "table":
+		f.add(c.convertTable(n)...)
+	case "hr":
+		f.add(&BlockNode{Block: c.md.createDividerBlock()})
+	case "br":
+		f.addBreak()
+	case "img":
+		c.addImage(f, n.attrs["src"], n.attrs["alt"])
+	case "video":
+		if c.inCell > 0 {
+			f.addText(fmt.Sprintf("[视频: %s]", n.attrs["src"]), st)
+			return
+		}
+		attrs := map[string]string{"src": n.attrs["src"]}
+		if src := n.child("source"); attrs["src"] == "" && src != nil {
+			attrs["src"] = src.attrs["src"]
+		}
+		f.add(c.md.handleHTMLVideoBlock(&HTMLTag{Name: "video", Attrs: attrs})...)
+	case "ac:image":
+		c.addImage(f, c.resourceSource(n), n.attrs["ac:alt"])
+	case "ac:structured-macro", "ac:macro":
+		c.convertMacro(f, n, st)
+	case "ac:link":
+		text, href := c.linkTarget(n)
+		if href != "" {
+			st.link = href
+		}
+		f.addText(text, st)
+	case "ac:emoticon":
+		if fallback := n.attrs["ac:emoji-fallback"]; fallback != "" {
+			f.addText(fallback, st)
+		}
+	case "time":
+		f.addText(firstNonBlank(n.attrs["datetime"], n.textContent()), st)
+	case "strong", "b":
+		st.bold = true
+		c.walkChildren(f, n, st)
+	case "em", "i", "cite":
+		st.italic = true
+		c.walkChildren(f, n, st)
+	case "s", "del", "strike":
+		st.strike = true
+		c.walkChildren(f, n, st)
+	case "u", "ins":
+		st.underline = true
+		c.walkChildren(f, n, st)
+	case "code", "kbd", "tt", "samp":
+		st.code = true
+		c.walkChildren(f, n, st)
+	case "a":
+		if href := n.attrs["href"]; href != "" {
+			st.link = href
+		}
+		c.walkChildren(f, n, st)
+	case "p", "div", "section", "article", "main", "header", "footer", "body", "html", "figure", "figcaption",
+		"center", "dl", "dt", "dd", "ac:layout", "ac:layout-section", "ac:layout-cell", "ac:rich-text-body":
+		// 块级容器：前后各断一次段落
+		f.flush()
+		c.walkChildren(f, n, st)
+		f.flush()
+	default:
+		// span、font、sup、ac:inline-comment-marker 等行内或未知元素：透明处理
+		c.walkChildren(f, n, st)
+	}
+}
+
+func (c *HTMLToBlock) walkChildren(f *htmlFlow, n *htmlNode, st htmlInlineStyle) {
+	for _, child := range n.children {
+		c.walk(f, child, st)
+	}
+}
+
+func (c *HTMLToBlock) headingNode(n *htmlNode) *BlockNode {
+	level := int(n.name[1] - '0')
+	blockType := int(BlockTypeHeading1) + level - 1
+	block := &larkdocx.Block{BlockType: &blockType}
+	text := &larkdocx.Text{Elements: c.inlineElements(n)}
+	switch level {
+	case 1:
+		block.Heading1 = text
+	case 2:
+		block.Heading2 = text
+	case 3:
+		block.Heading3 = text
+	case 4:
+		block.Heading4 = text
+	case 5:
+		block.Heading5 = text
+	case 6:
+		block.Heading6 = text
+	}
+	return &BlockNode{Block: block}
+}
+
+// codeLanguageFromClass 从 class="language-go" / "lang-go" / "brush: go;" 提取代码语言。
+func codeLanguageFromClass(class string) string {
+	for _, field := range strings.Fields(strings.ReplaceAll(class, ";", " ")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(field, prefix); ok {
+				return lang
+			}
+		}
+	}
+	if _, rest, ok := strings.Cut(class, "brush:"); ok {
+		if fields := strings.Fields(strings.ReplaceAll(rest, ";", " ")); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+func codeBlockNode(text, lang string) *BlockNode {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	langCode := languageNameToCode(lang)
+	blockType := int(BlockTypeCode)
+	return &BlockNode{Block: &larkdocx.Block{
+		BlockType: &blockType,
+		Code: &larkdocx.Text{
+			Elements: []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &text}}},
+			Style:    &larkdocx.TextStyle{Language: &langCode},
+		},
+	}}
+}
+
+// quoteNode 生成引用容器；title 非空时作为加粗首行（expand 宏的标题）。
+func (c *HTMLToBlock) quoteNode(title string, body *htmlNode) *BlockNode {
+	blockType := int(BlockTypeQuoteContainer)
+	var children []*BlockNode
+	if title != "" {
+		children = append(children, boldTextBlockNode(title))
+	}
+	if body != nil {
+		children = append(children, c.convertNested(body)...)
+	}
+	return &BlockNode{
+		Block:    &larkdocx.Block{BlockType: &blockType, QuoteContainer: &larkdocx.QuoteContainer{}},
+		Children: children,
+	}
+}
+
+// confluenceCalloutColors 把 Confluence 提示类宏映射为高亮块背景色，与 [!TYPE] 语法配色一致
+// （2 红、4 黄、5 绿、6 蓝）。
+var confluenceCalloutColors = map[string]int{
+	"info":    6,
+	"note":    4,
+	"tip":     5,
+	"warning": 2,
+	"panel":   6,
+}
+
+func (c *HTMLToBlock) calloutNode(macro string, title string, body *htmlNode) *BlockNode {
+	bgColor := confluenceCalloutColors[macro]
+	blockType := int(BlockTypeCallout)
+	var children []*BlockNode
+	if title != "" {
+		children = append(children, boldTextBlockNode(title))
+	}
+	if body != nil {
+		children = append(children, c.convertNested(body)...)
+	}
+	return &BlockNode{
+		Block:    &larkdocx.Block{BlockType: &blockType, Callout: &larkdocx.Callout{BackgroundColor: &bgColor}},
+		Children: children,
+	}
+}
+
+// convertMacro 处理 ac:structured-macro（以及旧版 ac:macro）。
+func (c *HTMLToBlock) convertMacro(f *htmlFlow, n *htmlNode, st htmlInlineStyle) {
+	name := strings.ToLower(n.attrs["ac:name"])
+	body := n.child("ac:rich-text-body")
+	switch name {
+	case "info", "note", "tip", "warning", "panel":
+		if c.inCell > 0 {
+			c.walkChildrenOf(f, body, st)
+			return
+		}
+		f.add(c.calloutNode(name, n.macroParamText("title"), body))
+	case "code", "noformat":
+		text := ""
+		if plain := n.child("ac:plain-text-body"); plain != nil {
+			text = plain.textContent()
+		}
+		if c.inCell > 0 {
+			st.code = true
+			f.addText(text, st)
+			return
+		}
+		f.add(codeBlockNode(text, n.macroParamText("language")))
+	case "expand":
+		title := firstNonBlank(n.macroParamText("title"), "展开")
+		if c.inCell > 0 {
+			c.walkChildrenOf(f, body, st)
+			return
+		}
+		f.add(c.quoteNode(title, body))
+	case "view-file", "viewpdf", "viewdoc", "viewxls", "viewppt", "multimedia", "widget-connector":
+		if p := n.macroParam("name"); p != nil {
+			if att := p.child("ri:attachment"); att != nil {
+				c.addAttachment(f, att.attrs["ri:filename"])
+				return
+			}
+		}
+		if url := n.macroParamText("url"); url != "" {
+			f.addText(url, htmlInlineStyle{link: url})
+			return
+		}
+		c.warnMacro(f, name, st)
+	case "status":
+		st.bold = true
+		f.addText("["+firstNonBlank(n.macroParamText("title"), n.macroParamText("colour"))+"]", st)
+	case "jira":
+		f.addText("Jira: "+firstNonBlank(n.macroParamText("key"), n.macroParamText("jqlQuery")), st)
+	case "anchor", "toc", "toc-zone", "children", "pagetree", "recently-updated", "contentbylabel", "excerpt-include":
+		// 导航/目录类宏依赖 Confluence 页面结构，飞书文档自带目录，直接丢弃
+		if name == "toc-zone" {
+			c.walkChildrenOf(f, body, st)
+		}
+	case "excerpt", "section", "column", "details", "div", "span":
+		c.walkChildrenOf(f, body, st)
+	default:
+		if body != nil {
+			c.walkChildrenOf(f, body, st)
+			return
+		}
+		c.warnMacro(f, name, st)
+	}
+}
+
+func (c *HTMLToBlock) walkChildrenOf(f *htmlFlow, n *htmlNode, st htmlInlineStyle) {
+	if n == nil {
+		return
+	}
+	f.flush()
+	c.walkChildren(f, n, st)
+	f.flush()
+}
+
+// warnMacro 为无法转换的宏输出占位文字，并在 stderr 提示（同名宏只提示一次）。
+func (c *HTMLToBlock) warnMacro(f *htmlFlow, name string, st htmlInlineStyle) {
+	if !c.warned[name] {
+		c.warned[name] = true
+		fmt.Fprintf(os.Stderr, "[警告] 不支持的 Confluence 宏 %q，已保留为占位文字\n", name)
+	}
+	f.addText(fmt.Sprintf("[Confluence 宏: %s]", name), st)
+}
+
+// linkTarget 解析 ac:link 的显示文字与链接地址（页面/用户链接没有可用地址时只保留文字）。
+func (c *HTMLToBlock) linkTarget(n *htmlNode) (text, href string) {
+	for _, bodyName := range []string{"ac:plain-text-link-body", "ac:link-body"} {
+		if body := n.child(bodyName); body != nil {
+			text = strings.TrimSpace(body.textContent())
+		}
+	}
+	if page := n.child("ri:page"); page != nil && text == "" {
+		text = page.attrs["ri:content-title"]
+	}
+	if att := n.child("ri:attachment"); att != nil && text == "" {
+		text = att.attrs["ri:filename"]
+	}
+	if user := n.child("ri:user"); user != nil && text == "" {
+		text = "@" + firstNonBlank(user.attrs["ri:username"], user.attrs["ri:userkey"], user.attrs["ri:account-id"])
+	}
+	if u := n.child("ri:url"); u != nil {
+		href = u.attrs["ri:value"]
+		if text == "" {
+			text = href
+		}
+	}
+	if text == "" {
+		text = n.attrs["ac:anchor"]
+	}
+	return text, href
+}
+
+// resourceSource 返回 ac:image 引用的资源：附件解析为本地路径，ri:url 保留 URL。
+func (c *HTMLToBlock) resourceSource(n *htmlNode) string {
+	if att := n.child("ri:attachment"); att != nil {
+		return c.attachmentPath(att.attrs["ri:filename"])
+	}
+	if u := n.child("ri:url"); u != nil {
+		return u.attrs["ri:value"]
+	}
+	return ""
+}
+
+func (c *HTMLToBlock) attachmentPath(filename string) string {
+	if c.attachmentsDir == "" {
+		return filename
+	}
+	return filepath.Join(c.attachmentsDir, filename)
+}
+
+func (c *HTMLToBlock) addImage(f *htmlFlow, src, alt string) {
+	if src == "" {
+		return
+	}
+	if c.inCell > 0 || strings.HasPrefix(src, "data:") {
+		f.addText(fmt.Sprintf("[图片: %s]", firstNonBlank(alt, filepath.Base(src))), htmlInlineStyle{})
+		return
+	}
+	f.add(c.md.handleHTMLImageBlock(&HTMLTag{Name: "image", Attrs: map[string]string{"url": src}})...)
+}
+
+// addAttachment 把附件登记为待上传块：图片走图片上传，其余（含视频）建 File 块由导入层上传。
+func (c *HTMLToBlock) addAttachment(f *htmlFlow, filename string) {
+	if filename == "" {
+		return
+	}
+	src := c.attachmentPath(filename)
+	if isImageFilename(filename) {
+		c.addImage(f, src, filename)
+		return
+	}
+	if c.inCell > 0 {
+		f.addText(fmt.Sprintf("[附件: %s]", filename), htmlInlineStyle{})
+		return
+	}
+	if !c.md.options.UploadImages {
+		c.md.videoStats.Skipped++
+		f.add(&BlockNode{Block: c.md.createMediaPlaceholder("File", src)})
+		return
+	}
+	name := filepath.Base(filename)
+	viewType := 1 // 卡片视图
+	if IsVideoFilename(name) {
+		viewType = 2
+	}
+	c.md.videoStats.Total++
+	c.md.videoSources = append(c.md.videoSources, src)
+	blockType := int(BlockTypeFile)
+	f.add(&BlockNode{Block: &larkdocx.Block{
+		BlockType: &blockType,
+		File:      &larkdocx.File{Name: &name, ViewType: &viewType},
+	}})
+}
+
+func isImageFilename(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp", ".svg", ".tif", ".tiff":
+		return true
+	}
+	return false
+}
+
+// convertList 转换 ul/ol：每个 li 的首段文字作为列表项内容，其余块（嵌套列表、代码等）作为子块。
+func (c *HTMLToBlock) convertList(n *htmlNode, ordered bool) []*BlockNode {
+	var out []*BlockNode
+	for _, li := range n.children {
+		if li.name != "li" {
+			continue
+		}
+		checked, isTodo := listItemCheckbox(li)
+		elems, children := c.splitItemContent(c.convertNested(li))
+		if !hasNonEmptyContent(elems) && len(children) == 0 {
+			continue
+		}
+		var block *larkdocx.Block
+		switch {
+		case isTodo:
+			blockType := int(BlockTypeTodo)
+			block = &larkdocx.Block{BlockType: &blockType, Todo: &larkdocx.Text{Elements: elems, Style: &larkdocx.TextStyle{Done: &checked}}}
+		case ordered:
+			blockType := int(BlockTypeOrdered)
+			block = &larkdocx.Block{BlockType: &blockType, Ordered: &larkdocx.Text{Elements: elems}}
+		default:
+			blockType := int(BlockTypeBullet)
+			block = &larkdocx.Block{BlockType: &blockType, Bullet: &larkdocx.Text{Elements: elems}}
+		}
+		out = append(out, &BlockNode{Block: block, Children: children})
+	}
+	return out
+}
+
+// listItemCheckbox 识别以 <input type="checkbox"> 开头的列表项（GitHub 风格任务列表）。
+func listItemCheckbox(li *htmlNode) (checked, ok bool) {
+	for _, child := range li.children {
+		if child.name == "" && strings.TrimSpace(child.text) == "" {
+			continue
+		}
+		if child.name == "input" && strings.EqualFold(child.attrs["type"], "checkbox") {
+			_, checked = child.attrs["checked"]
+			return checked, true
+		}
+		return false, false
+	}
+	return false, false
+}
+
+// convertTaskList 转换 Confluence 任务列表为待办块。
+func (c *HTMLToBlock) convertTaskList(n *htmlNode) []*BlockNode {
+	var out []*BlockNode
+	for _, task := range n.children {
+		if task.name != "ac:task" {
+			continue
+		}
+		done := false
+		if status := task.child("ac:task-status"); status != nil {
+			done = strings.TrimSpace(status.textContent()) == "complete"
+		}
+		body := task.child("ac:task-body")
+		if body == nil {
+			continue
+		}
+		elems, children := c.splitItemContent(c.convertNested(body))
+		if !hasNonEmptyContent(elems) && len(children) == 0 {
+			continue
+		}
+		blockType := int(BlockTypeTodo)
+		out = append(out, &BlockNode{
+			Block:    &larkdocx.Block{BlockType: &blockType, Todo: &larkdocx.Text{Elements: elems, Style: &larkdocx.TextStyle{Done: &done}}},
+			Children: children,
+		})
+	}
+	return out
+}
+
+// splitItemContent 取首个 Text 块的内容作为列表项文字，其余块作为子块。
+func (c *HTMLToBlock) splitItemContent(nodes []*BlockNode) ([]*larkdocx.TextElement, []*BlockNode) {
+	if len(nodes) > 0 && nodes[0].Block.Text != nil {
+		return nodes[0].Block.Text.Elements, nodes[1:]
+	}
+	empty := ""
+	return []*larkdocx.TextElement{{TextRun: &larkdocx.TextRun{Content: &empty}}}, nodes
+}
+
+// convertTable 把 HTML 表格转换为飞书表格：rowspan/colspan 还原为合并区域，首行全部为 th 时作为表头。
+// 容器内的表格无法作为顶层块创建，按行降级为文字。
+func (c *HTMLToBlock) convertTable(n *htmlNode) []*BlockNode {
+	var rows []*htmlNode
+	var collect func(*htmlNode)
+	collect = func(node *htmlNode) {
+		for _, child := range node.children {
+			switch child.name {
+			case "tr":
+				rows = append(rows, child)
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	type gridCell struct {
+		elems []*larkdocx.TextElement
+		th    bool
+	}
+	var grid [][]*gridCell
+	var merges []TableMerge
+	occupied := map[[2]int]bool{}
+	cols := 0
+	for r, tr := range rows {
+		for len(grid) <= r {
+			grid = append(grid, nil)
+		}
+		col := 0
+		for _, td := range tr.children {
+			if td.name != "td" && td.name != "th" {
+				continue
+			}
+			for occupied[[2]int{r, col}] {
+				col++
+			}
+			rowSpan := max(parseHTMLIntAttrDefault(td.attrs["rowspan"], 1), 1)
+			colSpan := max(parseHTMLIntAttrDefault(td.attrs["colspan"], 1), 1)
+			rowSpan = min(rowSpan, len(rows)-r)
+			for i := r; i < r+rowSpan; i++ {
+				for j := col; j < col+colSpan; j++ {
+					occupied[[2]int{i, j}] = true
+				}
+			}
+			for len(grid[r]) <= col {
+				grid[r] = append(grid[r], nil)
+			}
+			grid[r][col] = &gridCell{elems: c.inlineElements(td), th: td.name == "th"}
+			if rowSpan > 1 || colSpan > 1 {
+				merges = append(merges, TableMerge{Row: r, Col: col, RowSpan: rowSpan, ColSpan: colSpan})
+			}
+			col += colSpan
+			cols = max(cols, col)
+		}
+	}
+
+	if c.nested > 0 {
+		var out []*BlockNode
+		for _, row := range grid {
+			var elems []*larkdocx.TextElement
+			for j, cell := range row {
+				if j > 0 {
+					elems = append(elems, htmlInlineStyle{}.element(" | "))
+				}
+				if cell != nil {
+					elems = append(elems, cell.elems...)
+				}
+			}
+			if hasNonEmptyContent(elems) {
+				out = append(out, textBlockNode(elems))
+			}
+		}
+		return out
+	}
+
+	hasHeader := len(grid[0]) > 0
+	for _, cell := range grid[0] {
+		if cell != nil && !cell.th {
+			hasHeader = false
+		}
+	}
+	var t tableCells
+	t.cols, t.hasHeader, t.merges = cols, hasHeader, merges
+	for r, row := range grid {
+		contents := make([]string, cols)
+		elements := make([][]*larkdocx.TextElement, cols)
+		for j := 0; j < cols; j++ {
+			if j < len(row) && row[j] != nil {
+				elements[j] = row[j].elems
+				var sb strings.Builder
+				for _, e := range row[j].elems {
+					sb.WriteString(elementText(e))
+				}
+				contents[j] = sb.String()
+			}
+		}
+		if r == 0 && hasHeader {
+			t.headerContents, t.headerElements = contents, elements
+			continue
+		}
+		t.dataRows = append(t.dataRows, contents)
+		t.dataRowElements = append(t.dataRowElements, elements)
+	}
+
+	var out []*BlockNode
+	for _, result := range c.md.buildTableResults(t) {
+		out = append(out, &BlockNode{Block: result.Block})
+		c.tableDatas = append(c.tableDatas, result.TableData)
+	}
+	return out
+}
+
+// firstNonBlank 返回第一个非空白的字符串。
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
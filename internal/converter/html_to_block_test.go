@@ -0,0 +1,219 @@
+package converter
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func nodeText(n *BlockNode) string {
+	var sb strings.Builder
+	for _, e := range blockTextElements(n.Block) {
+		sb.WriteString(elementText(e))
+	}
+	return sb.String()
+}
+
+func TestHTMLToBlockConfluenceMacros(t *testing.T) {
+	src := `<?xml version="1.0" encoding="UTF-8"?>
+<h2>概览&nbsp;说明</h2>
+<p>普通 <strong>加粗</strong> 与 <a href="https://example.com">链接</a></p>
+<ac:structured-macro ac:name="warning" ac:schema-version="1">
+  <ac:parameter ac:name="title">注意</ac:parameter>
+  <ac:rich-text-body><p>不要在生产环境执行</p></ac:rich-text-body>
+</ac:structured-macro>
+<ac:structured-macro ac:name="code">
+  <ac:parameter ac:name="language">go</ac:parameter>
+  <ac:plain-text-body><![CDATA[if a < b {
+	return
+}]]></ac:plain-text-body>
+</ac:structured-macro>
+<ac:structured-macro ac:name="expand">
+  <ac:rich-text-body><p>隐藏内容</p></ac:rich-text-body>
+</ac:structured-macro>
+<ac:structured-macro ac:name="toc"/>
+<ac:structured-macro ac:name="mystery"/>
+<p>状态 <ac:structured-macro ac:name="status"><ac:parameter ac:name="title">DONE</ac:parameter></ac:structured-macro>
+<ac:link><ri:page ri:content-title="设计文档"/></ac:link></p>`
+
+	res, err := NewHTMLToBlock([]byte(src), ConvertOptions{}, "", "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := res.BlockNodes
+	if len(nodes) != 7 {
+		for _, n := range nodes {
+			t.Logf("%d %q", *n.Block.BlockType, nodeText(n))
+		}
+		t.Fatalf("应有 7 个顶层块，实际 %d", len(nodes))
+	}
+
+	if nodes[0].Block.Heading2 == nil || nodeText(nodes[0]) != "概览 说明" {
+		t.Errorf("标题转换错误: %q", nodeText(nodes[0]))
+	}
+	elems := nodes[1].Block.Text.Elements
+	if len(elems) != 4 || elementText(elems[1]) != "加粗" || !*elems[1].TextRun.TextElementStyle.Bold ||
+		*elems[3].TextRun.TextElementStyle.Link.Url != "https://example.com" {
+		t.Errorf("行内样式转换错误: %q", nodeText(nodes[1]))
+	}
+
+	callout := nodes[2]
+	if callout.Block.Callout == nil || *callout.Block.Callout.BackgroundColor != 2 || len(callout.Children) != 2 ||
+		nodeText(callout.Children[0]) != "注意" || nodeText(callout.Children[1]) != "不要在生产环境执行" {
+		t.Errorf("warning 宏应转为红色高亮块: %+v", callout)
+	}
+
+	code := nodes[3].Block.Code
+	if code == nil || nodeText(nodes[3]) != "if a < b {\n\treturn\n}" || *code.Style.Language != languageNameToCode("go") {
+		t.Errorf("code 宏转换错误: %q", nodeText(nodes[3]))
+	}
+
+	quote := nodes[4]
+	if quote.Block.QuoteContainer == nil || len(quote.Children) != 2 || nodeText(quote.Children[0]) != "展开" ||
+		nodeText(quote.Children[1]) != "隐藏内容" {
+		t.Errorf("expand 宏应转为带标题的引用容器: %+v", quote)
+	}
+
+	if got := nodeText(nodes[5]); got != "[Confluence 宏: mystery]" {
+		t.Errorf("未知宏占位 = %q", got)
+	}
+	if got := nodeText(nodes[6]); got != "状态 [DONE] 设计文档" {
+		t.Errorf("status/link 转换 = %q", got)
+	}
+}
+
+func TestHTMLToBlockListsAndTasks(t *testing.T) {
+	src := `<ul><li>一<ul><li>一.一</li></ul></li><li><input type="checkbox" checked> 完成项</li></ul>
+<ol><li><p>步骤</p><pre><code class="language-bash">ls -l</code></pre></li></ol>
+<ac:task-list><ac:task><ac:task-status>complete</ac:task-status><ac:task-body>写文档</ac:task-body></ac:task>
+<ac:task><ac:task-status>incomplete</ac:task-status><ac:task-body>评审</ac:task-body></ac:task></ac:task-list>`
+
+	res, err := NewHTMLToBlock([]byte(src), ConvertOptions{}, "", "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := res.BlockNodes
+	if len(nodes) != 5 {
+		t.Fatalf("应有 5 个顶层块，实际 %d", len(nodes))
+	}
+	if nodes[0].Block.Bullet == nil || nodeText(nodes[0]) != "一" || len(nodes[0].Children) != 1 ||
+		nodeText(nodes[0].Children[0]) != "一.一" {
+		t.Errorf("嵌套无序列表转换错误")
+	}
+	if nodes[1].Block.Todo == nil || !*nodes[1].Block.Todo.Style.Done || nodeText(nodes[1]) != "完成项" {
+		t.Errorf("复选框列表项应转为已完成待办")
+	}
+	if nodes[2].Block.Ordered == nil || len(nodes[2].Children) != 1 || nodes[2].Children[0].Block.Code == nil {
+		t.Errorf("有序列表项内的代码块应作为子块")
+	}
+	if !*nodes[3].Block.Todo.Style.Done || *nodes[4].Block.Todo.Style.Done || nodeText(nodes[4]) != "评审" {
+		t.Errorf("Confluence 任务列表转换错误")
+	}
+}
+
+func TestHTMLToBlockTableMerges(t *testing.T) {
+	src := `<table><tbody>
+<tr><th colspan="2">合并表头</th><th>C</th></tr>
+<tr><td rowspan=2>纵向</td><td>b1</td><td>c1</td></tr>
+<tr><td>b2</td><td><p>c2</p><p>第二段</p></td></tr>
+</tbody></table>
+<ac:structured-macro ac:name="info"><ac:rich-text-body>
+<table><tr><td>x</td><td>y</td></tr></table>
+</ac:rich-text-body></ac:structured-macro>`
+
+	res, err := NewHTMLToBlock([]byte(src), ConvertOptions{}, "", "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TableDatas) != 1 {
+		t.Fatalf("只有顶层表格生成 TableData，实际 %d", len(res.TableDatas))
+	}
+	td := res.TableDatas[0]
+	if td.Rows != 3 || td.Cols != 3 {
+		t.Fatalf("表格尺寸 = %dx%d", td.Rows, td.Cols)
+	}
+	want := []TableMerge{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}, {Row: 1, Col: 0, RowSpan: 2, ColSpan: 1}}
+	if !reflect.DeepEqual(td.Merges, want) {
+		t.Errorf("Merges = %v, want %v", td.Merges, want)
+	}
+	if got := td.CellContents[8]; got != "c2\n第二段" {
+		t.Errorf("多段单元格内容 = %q", got)
+	}
+
+	callout := res.BlockNodes[1]
+	if callout.Block.Callout == nil || len(callout.Children) != 1 || nodeText(callout.Children[0]) != "x | y" {
+		t.Errorf("高亮块内的表格应降级为文字行")
+	}
+}
+
+func TestHTMLToBlockImpliedEndTags(t *testing.T) {
+	src := `<!DOCTYPE html><html><head><title>t</title></head><body>
+<ul><li>one<li>two</ul>
+<table><tr><td>1<td>2<tr><td>3<td>4</table>
+<p>段一<p>段二
+</body></html>`
+
+	res, err := NewHTMLToBlock([]byte(src), ConvertOptions{}, "", "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := res.BlockNodes
+	if len(nodes) != 5 {
+		for _, n := range nodes {
+			t.Logf("%d %q", *n.Block.BlockType, nodeText(n))
+		}
+		t.Fatalf("应有 5 个顶层块，实际 %d", len(nodes))
+	}
+	for i, want := range []string{"one", "two"} {
+		if nodes[i].Block.Bullet == nil || nodeText(nodes[i]) != want || len(nodes[i].Children) != 0 {
+			t.Errorf("第 %d 个列表项应为无嵌套的 %q，实际 %q（子块 %d）", i, want, nodeText(nodes[i]), len(nodes[i].Children))
+		}
+	}
+
+	if len(res.TableDatas) != 1 {
+		t.Fatalf("应生成 1 个表格，实际 %d", len(res.TableDatas))
+	}
+	td := res.TableDatas[0]
+	if td.Rows != 2 || td.Cols != 2 || !reflect.DeepEqual(td.CellContents, []string{"1", "2", "3", "4"}) {
+		t.Errorf("表格 = %dx%d %q，期望 2x2 [1 2 3 4]", td.Rows, td.Cols, td.CellContents)
+	}
+
+	if nodeText(nodes[3]) != "段一" || nodeText(nodes[4]) != "段二" {
+		t.Errorf("隐式闭合的段落 = %q, %q", nodeText(nodes[3]), nodeText(nodes[4]))
+	}
+}
+
+func TestHTMLToBlockAttachments(t *testing.T) {
+	src := `<p><ac:image><ri:attachment ri:filename="arch.png"/></ac:image></p>
+<ac:structured-macro ac:name="view-file"><ac:parameter ac:name="name"><ri:attachment ri:filename="spec.pdf"/></ac:parameter></ac:structured-macro>
+<ac:structured-macro ac:name="multimedia"><ac:parameter ac:name="name"><ri:attachment ri:filename="demo.mp4"/></ac:parameter></ac:structured-macro>
+<img src=local.jpg alt="本地">`
+
+	dir := filepath.Join("export", "attachments")
+	res, err := NewHTMLToBlock([]byte(src), ConvertOptions{UploadImages: true}, "", dir).ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "arch.png"), "local.jpg"}; !reflect.DeepEqual(res.ImageSources, want) {
+		t.Errorf("ImageSources = %v, want %v", res.ImageSources, want)
+	}
+	if want := []string{filepath.Join(dir, "spec.pdf"), filepath.Join(dir, "demo.mp4")}; !reflect.DeepEqual(res.VideoSources, want) {
+		t.Errorf("VideoSources = %v, want %v", res.VideoSources, want)
+	}
+	files := []*larkdocx.File{res.BlockNodes[1].Block.File, res.BlockNodes[2].Block.File}
+	if files[0] == nil || *files[0].Name != "spec.pdf" || *files[0].ViewType != 1 ||
+		files[1] == nil || *files[1].Name != "demo.mp4" || *files[1].ViewType != 2 {
+		t.Errorf("附件应转为待上传的 File 块: %+v", files)
+	}
+
+	res, err = NewHTMLToBlock([]byte(src), ConvertOptions{}, "", dir).ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.VideoSources) != 0 || res.VideoStats.Skipped != 2 || nodeText(res.BlockNodes[1]) != "[File: "+filepath.Join(dir, "spec.pdf")+"]" {
+		t.Errorf("关闭上传时附件应降级为占位文字: %q", nodeText(res.BlockNodes[1]))
+	}
+}
@@ -0,0 +1,168 @@
+package converter
+
+import (
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// InlineDiffSpan 是两段富文本逐字比较后的一段结果。
+// Op 为 " "（不变）、"-"（删除）、"+"（新增）或 "~"（文字相同、行内样式变化）。
+type InlineDiffSpan struct {
+	Op       string   `json:"op"`
+	Text     string   `json:"text"`
+	OldStyle []string `json:"old_style,omitempty"` // 仅 Op 为 "~" 时给出
+	NewStyle []string `json:"new_style,omitempty"`
+}
+
+// inlineUnit 是参与比较的最小单位：文本中的一个字符，或一个 @用户/@文档/公式元素
+type inlineUnit struct {
+	text  string
+	style string
+}
+
+// DiffTextElements 逐字比较两组文本元素，返回按新文本顺序合并后的差异段。
+// 文字由 LCS 对齐；对齐上的字符若行内样式（加粗、斜体、链接等）不同，标记为样式变化。
+func DiffTextElements(oldElements, newElements []*larkdocx.TextElement) []InlineDiffSpan {
+	oldUnits := inlineUnits(oldElements)
+	newUnits := inlineUnits(newElements)
+	oldTexts := make([]string, len(oldUnits))
+	for i, u := range oldUnits {
+		oldTexts[i] = u.text
+	}
+	newTexts := make([]string, len(newUnits))
+	for i, u := range newUnits {
+		newTexts[i] = u.text
+	}
+
+	var spans []InlineDiffSpan
+	add := func(op, text, oldStyle, newStyle string) {
+		span := InlineDiffSpan{Op: op, Text: text}
+		if op == "~" {
+			span.OldStyle, span.NewStyle = inlineStyleNames(oldStyle), inlineStyleNames(newStyle)
+		}
+		if n := len(spans); n > 0 && spans[n-1].Op == op && sameStrings(spans[n-1].OldStyle, span.OldStyle) && sameStrings(spans[n-1].NewStyle, span.NewStyle) {
+			spans[n-1].Text += text
+			return
+		}
+		spans = append(spans, span)
+	}
+
+	oi, ni := 0, 0
+	for _, m := range append(lcsMatches(oldTexts, newTexts), [2]int{len(oldUnits), len(newUnits)}) {
+		for ; oi < m[0]; oi++ {
+			add("-", oldUnits[oi].text, "", "")
+		}
+		for ; ni < m[1]; ni++ {
+			add("+", newUnits[ni].text, "", "")
+		}
+		if m[0] == len(oldUnits) {
+			break
+		}
+		if oldStyle, newStyle := oldUnits[m[0]].style, newUnits[m[1]].style; oldStyle != newStyle {
+			add("~", newUnits[m[1]].text, oldStyle, newStyle)
+		} else {
+			add(" ", newUnits[m[1]].text, "", "")
+		}
+		oi, ni = m[0]+1, m[1]+1
+	}
+	return spans
+}
+
+// RenderInlineDiff 以 git --word-diff 风格渲染差异段：[-删除-]、{+新增+}，
+// 样式变化写作 {~文字~}(旧样式 → 新样式)
+func RenderInlineDiff(spans []InlineDiffSpan) string {
+	var sb strings.Builder
+	for _, span := range spans {
+		switch span.Op {
+		case "-":
+			sb.WriteString("[-" + span.Text + "-]")
+		case "+":
+			sb.WriteString("{+" + span.Text + "+}")
+		case "~":
+			sb.WriteString("{~" + span.Text + "~}(" + describeInlineStyle(span.OldStyle) + " → " + describeInlineStyle(span.NewStyle) + ")")
+		default:
+			sb.WriteString(span.Text)
+		}
+	}
+	return sb.String()
+}
+
+// InlineDiffChanged 判断差异段中是否有任何改动
+func InlineDiffChanged(spans []InlineDiffSpan) bool {
+	for _, span := range spans {
+		if span.Op != " " {
+			return true
+		}
+	}
+	return false
+}
+
+func inlineUnits(elements []*larkdocx.TextElement) []inlineUnit {
+	var units []inlineUnit
+	for _, elem := range elements {
+		if elem == nil {
+			continue
+		}
+		switch {
+		case elem.TextRun != nil && elem.TextRun.Content != nil:
+			style := textRunStyleKey(elem.TextRun.TextElementStyle)
+			for _, r := range *elem.TextRun.Content {
+				units = append(units, inlineUnit{text: string(r), style: style})
+			}
+		case elem.MentionUser != nil && elem.MentionUser.UserId != nil:
+			units = append(units, inlineUnit{text: "@" + *elem.MentionUser.UserId})
+		case elem.MentionDoc != nil && elem.MentionDoc.Token != nil:
+			text := *elem.MentionDoc.Token
+			if elem.MentionDoc.Title != nil && *elem.MentionDoc.Title != "" {
+				text = *elem.MentionDoc.Title
+			}
+			units = append(units, inlineUnit{text: "@" + text})
+		case elem.Equation != nil && elem.Equation.Content != nil:
+			units = append(units, inlineUnit{text: "$" + strings.TrimSpace(*elem.Equation.Content) + "$"})
+		}
+	}
+	return units
+}
+
+// inlineStyleNames 把 textRunStyleKey 生成的样式 key 还原为样式名列表
+func inlineStyleNames(key string) []string {
+	names := []string{}
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case 'b':
+			names = append(names, "bold")
+		case 'i':
+			names = append(names, "italic")
+		case 's':
+			names = append(names, "strikethrough")
+		case 'u':
+			names = append(names, "underline")
+		case 'c':
+			names = append(names, "inline_code")
+		case '<':
+			names = append(names, "link:"+strings.TrimSuffix(key[i+1:], ">"))
+			return names
+		}
+	}
+	return names
+}
+
+func describeInlineStyle(names []string) string {
+	if len(names) == 0 {
+		return "plain"
+	}
+	return strings.Join(names, "+")
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
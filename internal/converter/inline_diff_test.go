@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func TestDiffTextElements(t *testing.T) {
+	run := func(content string, style *larkdocx.TextElementStyle) *larkdocx.TextElement {
+		return &larkdocx.TextElement{TextRun: &larkdocx.TextRun{Content: strPtr(content), TextElementStyle: style}}
+	}
+	oldElements := []*larkdocx.TextElement{run("详见文档", nil), run("说明", &larkdocx.TextElementStyle{Bold: boolPtr(true)})}
+	newElements := []*larkdocx.TextElement{
+		run("详见", nil),
+		run("文档", &larkdocx.TextElementStyle{Link: &larkdocx.Link{Url: strPtr("https://a.com")}}),
+		run("说明！", &larkdocx.TextElementStyle{Bold: boolPtr(true), Italic: boolPtr(true)}),
+	}
+
+	spans := DiffTextElements(oldElements, newElements)
+	want := []InlineDiffSpan{
+		{Op: " ", Text: "详见"},
+		{Op: "~", Text: "文档", OldStyle: []string{}, NewStyle: []string{"link:https://a.com"}},
+		{Op: "~", Text: "说明", OldStyle: []string{"bold"}, NewStyle: []string{"bold", "italic"}},
+		{Op: "+", Text: "！"},
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Fatalf("spans = %+v, want %+v", spans, want)
+	}
+	if got := RenderInlineDiff(spans); got != "详见{~文档~}(plain → link:https://a.com){~说明~}(bold → bold+italic){+！+}" {
+		t.Errorf("RenderInlineDiff = %q", got)
+	}
+	if InlineDiffChanged(DiffTextElements(oldElements, oldElements)) {
+		t.Error("相同文本不应有差异")
+	}
+}
@@ -0,0 +1,250 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// 问题级别
+const (
+	LintError   = "error"   // 导入时内容会丢失或失败
+	LintWarning = "warning" // 导入后效果降级
+)
+
+// largeTableRows 超过此数据行数的表格逐行追加耗时明显，建议改用电子表格
+const largeTableRows = 200
+
+// LintIssue 是 Markdown 转换为飞书块时会降级或丢失的一处写法
+type LintIssue struct {
+	Line    int    `json:"line"`  // 源文件行号（1-based），0 表示无法定位
+	Level   string `json:"level"` // LintError 或 LintWarning
+	Kind    string `json:"kind"`  // table / image / video / html / style / link / heading / diagram
+	Message string `json:"message"`
+}
+
+// deepHeadingRe 匹配 7 级及以上的 ATX 标题写法：Markdown 只识别到 6 级，其余会成为普通段落
+var deepHeadingRe = regexp.MustCompile(`^ {0,3}#{7,}(?:[ \t]|$)`)
+
+// addIssue 记录一个问题；node 为 nil 时行号留空，由 attributeIssues 归到外层节点
+func (c *MarkdownToBlock) addIssue(node ast.Node, level, kind, format string, args ...any) {
+	line := 0
+	if node != nil {
+		line = c.nodeLine(node)
+	}
+	c.issues = append(c.issues, LintIssue{Line: line, Level: level, Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+// attributeIssues 把 issues[from:] 中没有行号的问题归到 node 所在行（如 HTML 标签内部、表格拆分时产生的问题）
+func (c *MarkdownToBlock) attributeIssues(from int, node ast.Node) {
+	line := 0
+	for i := from; i < len(c.issues); i++ {
+		if c.issues[i].Line != 0 {
+			continue
+		}
+		if line == 0 {
+			line = c.nodeLine(node)
+		}
+		c.issues[i].Line = line
+	}
+}
+
+// Issues 返回转换中发现的问题（按行号排序并去重）
+func (c *MarkdownToBlock) Issues() []LintIssue {
+	seen := make(map[LintIssue]bool, len(c.issues))
+	var issues []LintIssue
+	for _, issue := range c.issues {
+		if !seen[issue] {
+			seen[issue] = true
+			issues = append(issues, issue)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// nodeLine 返回节点在原始 Markdown 中的行号：取节点（或最近的祖先）第一段源码的位置，
+// 再经 lineMap 换算回预处理（引用块补空行、块级公式改写）之前的行号
+func (c *MarkdownToBlock) nodeLine(node ast.Node) int {
+	for n := node; n != nil; n = n.Parent() {
+		off := firstSourceOffset(n)
+		if off < 0 || off > len(c.source) {
+			continue
+		}
+		line := bytes.Count(c.source[:off], []byte("\n")) + 1
+		if line-1 < len(c.lineMap) {
+			return c.lineMap[line-1]
+		}
+		return line
+	}
+	return 0
+}
+
+// firstSourceOffset 返回节点第一段源码的字节偏移，节点及其子孙都没有源码位置时返回 -1
+func firstSourceOffset(n ast.Node) int {
+	switch v := n.(type) {
+	case *ast.Text:
+		return v.Segment.Start
+	case *ast.RawHTML:
+		if v.Segments.Len() > 0 {
+			return v.Segments.At(0).Start
+		}
+	}
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		return n.Lines().At(0).Start
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if off := firstSourceOffset(child); off >= 0 {
+			return off
+		}
+	}
+	return -1
+}
+
+// sourceLineMap 建立预处理后各行到原始行号（1-based）的映射。预处理只会插入空行、
+// 或把多行 $$ 公式合并为一行，因此按内容顺序对齐即可：对不上的行归到当前原始行。
+func sourceLineMap(original, processed []byte) []int {
+	orig := strings.Split(string(original), "\n")
+	proc := strings.Split(string(processed), "\n")
+	lineMap := make([]int, len(proc))
+	j := 0
+	for i, line := range proc {
+		k := j
+		if line != "" {
+			for k < len(orig) && orig[k] != line {
+				k++
+			}
+		}
+		if k < len(orig) && orig[k] == line {
+			lineMap[i] = k + 1
+			j = k + 1
+			continue
+		}
+		lineMap[i] = min(j+1, len(orig))
+	}
+	return lineMap
+}
+
+// lintNode 检查一个顶层节点中导入时会丢失的写法：不支持的 HTML 标签、非 http(s) 链接、7 级以上的标题
+func (c *MarkdownToBlock) lintNode(node ast.Node) {
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.CodeSpan:
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph:
+			if lines := v.Lines(); lines.Len() > 0 {
+				if first := lines.At(0); deepHeadingRe.Match(first.Value(c.source)) {
+					c.addIssue(v, LintWarning, "heading", "Markdown 只识别 6 级标题，7 个及以上 # 的行将作为普通段落导入")
+				}
+			}
+		case *ast.HTMLBlock:
+			c.lintHTMLBlock(v)
+		case *ast.RawHTML:
+			c.lintInlineHTML(v)
+		case *ast.Link:
+			c.lintLink(v, string(v.Destination))
+		case *ast.AutoLink:
+			c.lintLink(v, string(v.URL(c.source)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// lintHTMLBlock 检查块级 HTML：handleBlockHTMLTag 不认识的标签整段丢弃
+func (c *MarkdownToBlock) lintHTMLBlock(node *ast.HTMLBlock) {
+	raw := c.getHTMLBlockText(node)
+	if strings.HasPrefix(raw, "<!--") {
+		return
+	}
+	tag := ParseHTMLTag(raw)
+	if tag == nil || supportedBlockHTMLTags[tag.Name] || tag.Name == "br" {
+		return
+	}
+	c.addIssue(node, LintError, "html", "不支持的块级 HTML 标签 <%s>，整段内容将被丢弃%s", tag.Name, htmlTagHint(tag.Name))
+}
+
+// lintInlineHTML 检查行内 HTML：未识别的标签被忽略，样式类标签的效果丢失
+func (c *MarkdownToBlock) lintInlineHTML(node *ast.RawHTML) {
+	var buf bytes.Buffer
+	for i := 0; i < node.Segments.Len(); i++ {
+		seg := node.Segments.At(i)
+		buf.Write(c.source[seg.Start:seg.Stop])
+	}
+	tag := ParseHTMLTag(buf.String())
+	if tag == nil || supportedInlineHTMLTags[tag.Name] || supportedBlockHTMLTags[tag.Name] {
+		return
+	}
+	switch {
+	case tag.Name == "img":
+		c.addIssue(node, LintError, "image", "不支持 HTML <img> 标签，图片将丢失%s", htmlTagHint(tag.Name))
+	case tag.Name == "a":
+		c.addIssue(node, LintWarning, "link", "不支持 HTML <a> 标签，导入后只保留文字%s", htmlTagHint(tag.Name))
+	case tag.Name == "mark":
+		c.addIssue(node, LintWarning, "style", "<mark> 高亮以下划线近似，背景色不会保留")
+	case htmlStyleTags[tag.Name]:
+		c.addIssue(node, LintWarning, "style", "HTML 样式标签 <%s> 不受支持，导入后样式丢失、只保留文字", tag.Name)
+	default:
+		c.addIssue(node, LintWarning, "html", "不支持的 HTML 标签 <%s>，标签将被忽略、只保留其中文字", tag.Name)
+	}
+}
+
+// htmlTagHint 给出常见 HTML 标签的 Markdown 替代写法
+func htmlTagHint(name string) string {
+	switch name {
+	case "img":
+		return "（请改用 ![说明](路径) 或 <image url=\"路径\"/>）"
+	case "a":
+		return "（请改用 [文字](链接)）"
+	case "table":
+		return "（请改用 Markdown 表格）"
+	}
+	return ""
+}
+
+// lintLink 检查链接地址：飞书只接受 http(s) 链接，相对路径、锚点、mailto 等只保留文字
+func (c *MarkdownToBlock) lintLink(node ast.Node, dest string) {
+	if dest == "" || hasValidURLPrefix(normalizeURL(dest)) {
+		return
+	}
+	c.addIssue(node, LintWarning, "link", "链接 %s 不是 http(s) 地址，导入后只保留文字", dest)
+}
+
+// noteImageFallback 记录图片被降级为占位文字的原因
+func (c *MarkdownToBlock) noteImageFallback(node ast.Node, dest string, inline bool) {
+	switch {
+	case strings.HasPrefix(dest, "feishu://media/"):
+		c.addIssue(node, LintWarning, "image", "飞书内部图片引用 %s 无法跨文档复用，将以占位文字导入（导出时加 --download-images 下载原图）", dest)
+	case !c.options.UploadImages:
+		c.addIssue(node, LintWarning, "image", "未开启图片上传，图片 %s 将以占位文字导入", dest)
+	case inline:
+		c.addIssue(node, LintWarning, "image", "行内图片 %s 将以占位文字导入（图片单独成段才会上传）", dest)
+	}
+}
+
+// checkLocalMedia 按导入时的规则（相对路径基于 basePath）检查本地图片/视频文件是否存在
+func (c *MarkdownToBlock) checkLocalMedia(node ast.Node, kind, src string) {
+	if src == "" || hasValidURLPrefix(src) {
+		return
+	}
+	p := src
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(c.basePath, p)
+	}
+	if _, err := os.Stat(p); err == nil {
+		return
+	}
+	label := "图片"
+	if kind == "video" {
+		label = "视频"
+	}
+	c.addIssue(node, LintError, kind, "%s文件不存在: %s（导入时将以占位文字代替）", label, p)
+}
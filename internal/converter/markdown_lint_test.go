@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToBlockIssues(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ok.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wide := "| " + strings.Repeat("h | ", 11) + "\n|" + strings.Repeat("---|", 11) + "\n| " + strings.Repeat("v | ", 11) + "\n"
+	// 引用后紧跟段落、多行 $$ 公式都会在预处理中改变行数，之后的行号仍应对应原文
+	md := `# 标题
+
+> 引用
+紧跟引用的段落
+
+$$
+E = mc^2
+$$
+
+![ok](ok.png)
+
+![缺失](missing.png)
+
+文字 ![行内](ok.png) 和 <sup>2</sup>
+
+见 [其它文档](other.md) 与 [官网](https://a.com)
+
+<details>
+<summary>折叠</summary>
+</details>
+
+####### 太深的标题
+
+` + "```html\n<div>代码块里的 HTML 不检查</div>\n```" + `
+
+<image url="gone.png"/>
+
+` + wide
+	lineOf := func(substr string) int {
+		return strings.Count(md[:strings.Index(md, substr)], "\n") + 1
+	}
+
+	conv := NewMarkdownToBlock([]byte(md), ConvertOptions{UploadImages: true}, dir)
+	result, err := conv.ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		Line  int
+		Level string
+		Kind  string
+	}
+	var got []row
+	for _, issue := range result.Issues {
+		got = append(got, row{issue.Line, issue.Level, issue.Kind})
+	}
+	want := []row{
+		{lineOf("![缺失]"), LintError, "image"},
+		{lineOf("<sup>"), LintWarning, "style"},
+		{lineOf("<sup>"), LintWarning, "image"},
+		{lineOf("[其它文档]"), LintWarning, "link"},
+		{lineOf("<details>"), LintError, "html"},
+		{lineOf("#######"), LintWarning, "heading"},
+		{lineOf("<image"), LintError, "image"},
+		{lineOf("| h |"), LintWarning, "table"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues =\n%+v\nwant\n%+v\nfull: %+v", got, want, result.Issues)
+	}
+}
+
+func TestMarkdownToBlockIssuesImageUploadOff(t *testing.T) {
+	md := "![a](feishu://media/abc)\n\n![b](b.png)\n"
+	conv := NewMarkdownToBlock([]byte(md), ConvertOptions{}, t.TempDir())
+	result, err := conv.ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 2 || result.Issues[0].Line != 1 || result.Issues[1].Line != 3 {
+		t.Fatalf("issues = %+v", result.Issues)
+	}
+	if !strings.Contains(result.Issues[0].Message, "--download-images") || !strings.Contains(result.Issues[1].Message, "未开启图片上传") {
+		t.Errorf("降级原因不符: %+v", result.Issues)
+	}
+}
+
+func TestSourceLineMap(t *testing.T) {
+	original := "> q\nnext\n\n$$\nx\n$$\nafter"
+	processed := "> q\n\nnext\n\n<block-equation>\n\nafter"
+	got := sourceLineMap([]byte(original), []byte(processed))
+	want := []int{1, 2, 2, 3, 4, 4, 7}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sourceLineMap = %v, want %v", got, want)
+	}
+}
@@ -289,9 +289,9 @@ func clampColumnWidths(widths []int) []int {
 	return widths
 }
 
-// isTableOrColWidthHTMLBlock 判断节点是否是『可消费 pendingColWidth』的两类节点：
+// isTableOrColWidthHTMLBlock 判断节点是否是『可消费 pendingColWidth / pendingMerges』的两类节点：
 //   - *east.Table：紧邻其下的表格，会消费 pending
-//   - *ast.HTMLBlock 且原文匹配 colWidthCommentRe：列宽注释本身，会写入 pending
+//   - *ast.HTMLBlock 且原文匹配 colWidthCommentRe / mergeCommentRe：表格注释本身，会写入 pending
 //
 // 其他任何块（Heading/Paragraph/CodeBlock/FencedCodeBlock/TextBlock/List/Blockquote/ThematicBreak/
 // 普通 HTMLBlock 等）都不应保留 pending，必须在主 walk 入口清空，
@@ -302,7 +302,7 @@ func (c *MarkdownToBlock) isTableOrColWidthHTMLBlock(n ast.Node) bool {
 	}
 	if h, ok := n.(*ast.HTMLBlock); ok {
 		raw := c.getHTMLBlockText(h)
-		if colWidthCommentRe.MatchString(raw) {
+		if colWidthCommentRe.MatchString(raw) || mergeCommentRe.MatchString(raw) {
 			return true
 		}
 	}
@@ -326,6 +326,18 @@ type MarkdownToBlock struct {
 	// 由紧邻其下的 ast.Table 消费一次后清空。0 表示该列走 auto。
 	// 当列数与表实际列数不一致时，不足补 minColumnWidth、超出截断。
 	pendingColWidth []int
+
+	// pendingMerges 暂存最近一条 <!-- feishu-merge: ... --> 注释解析出的合并区域，
+	// 与 pendingColWidth 相同，只由紧邻其下的表格消费一次。
+	pendingMerges []TableMerge
+
+	// skipEmbed 非空时表示已按 <!-- feishu-task/okr/jira --> 注释重建嵌入块，
+	// 跳过后续顶层节点直到对应的结束注释。
+	skipEmbed string
+
+	// issues 记录转换中发现的会降级或丢失的写法；lineMap 把预处理后的行号换算回原始行号（未改写时为 nil）
+	issues  []LintIssue
+	lineMap []int
 }
 
 // NewMarkdownToBlock creates a new converter
@@ -675,12 +687,16 @@ func rewriteBlockEquationsToHTML(source []byte) ([]byte, bool) {
 // ConvertWithTableData converts Markdown to Feishu blocks and returns table data for content filling
 func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 	// 预处理：确保引用块后有空行分隔，避免 goldmark 的 lazy continuation
+	original := c.source
 	c.source = normalizeBlockquoteEnding(c.source)
 
 	c.source, _ = rewriteBlockEquationsToHTML(c.source)
+	if !bytes.Equal(original, c.source) {
+		c.lineMap = sourceLineMap(original, c.source)
+	}
 
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithExtensions(extension.GFM, extension.Footnote, extension.DefinitionList),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
@@ -688,6 +704,7 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 
 	reader := text.NewReader(c.source)
 	doc := md.Parser().Parse(reader)
+	inlineFootnoteRefs(doc, c.options.DocumentID)
 
 	result := &ConvertResult{}
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -695,6 +712,13 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 			return ast.WalkContinue, nil
 		}
 
+		if c.skipEmbed != "" && n.Parent() == doc {
+			if h, ok := n.(*ast.HTMLBlock); ok && embedCloseTag(c.getHTMLBlockText(h)) == c.skipEmbed {
+				c.skipEmbed = ""
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
 		// pendingColWidth 守护：注释只对紧邻其下的 Table 生效。
 		// 任何 Document 直接子节点（不是 Document 本身），如果不是 Table 也不是命中
 		// colWidthCommentRe 的 HTMLBlock，都要清空 pending —— 防止悬浮注释跨越
@@ -703,7 +727,9 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 		if n.Parent() == doc {
 			if !c.isTableOrColWidthHTMLBlock(n) {
 				c.pendingColWidth = nil
+				c.pendingMerges = nil
 			}
+			c.lintNode(n)
 		}
 
 		switch node := n.(type) {
@@ -766,6 +792,14 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 			result.BlockNodes = append(result.BlockNodes, &BlockNode{Block: c.createDividerBlock()})
 			return ast.WalkContinue, nil
 
+		case *east.DefinitionList:
+			result.BlockNodes = append(result.BlockNodes, c.convertDefinitionList(node)...)
+			return ast.WalkSkipChildren, nil
+
+		case *east.FootnoteList:
+			result.BlockNodes = append(result.BlockNodes, c.convertFootnoteList(node)...)
+			return ast.WalkSkipChildren, nil
+
 		case *ast.HTMLBlock:
 			// 处理块级 HTML 标签（如 <image/>, <callout>...</callout>）
 			raw := c.getHTMLBlockText(node)
@@ -777,10 +811,24 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 				c.pendingColWidth = parseColWidthList(m[1])
 				return ast.WalkSkipChildren, nil
 			}
+			if m := mergeCommentRe.FindStringSubmatch(raw); m != nil {
+				c.pendingMerges = parseMergeList(m[1])
+				return ast.WalkSkipChildren, nil
+			}
+
+			if tag, attrs, ok := parseEmbedOpenComment(raw); ok {
+				if embed := c.handleEmbedComment(node, tag, attrs); embed != nil {
+					result.BlockNodes = append(result.BlockNodes, embed)
+					c.skipEmbed = tag
+				}
+				return ast.WalkSkipChildren, nil
+			}
 
 			tag := ParseHTMLTag(raw)
 			if tag != nil {
+				issuesBefore := len(c.issues)
 				blocks := c.handleBlockHTMLTag(tag)
+				c.attributeIssues(issuesBefore, node)
 				result.BlockNodes = append(result.BlockNodes, blocks...)
 			}
 			return ast.WalkSkipChildren, nil
@@ -797,6 +845,7 @@ func (c *MarkdownToBlock) ConvertWithTableData() (*ConvertResult, error) {
 	result.ImageSources = c.imageSources
 	result.VideoStats = c.videoStats
 	result.VideoSources = c.videoSources
+	result.Issues = c.Issues()
 	return result, nil
 }
 
@@ -865,6 +914,8 @@ func (c *MarkdownToBlock) convertParagraph(node *ast.Paragraph) ([]*BlockNode, e
 	}
 
 	// 检查段落是否只包含一个 <image> HTML 标签
+	issuesBefore := len(c.issues)
+	defer c.attributeIssues(issuesBefore, node)
 	if block := c.tryConvertHTMLImageParagraph(node); block != nil {
 		return []*BlockNode{{Block: block}}, nil
 	}
@@ -1796,13 +1847,16 @@ func (c *MarkdownToBlock) convertImage(node *ast.Image) (*larkdocx.Block, error)
 	// 导出时应使用 --download-images 下载实际文件，导入时自动上传。
 	if strings.HasPrefix(dest, "feishu://media/") {
 		c.imageStats.Skipped++
+		c.noteImageFallback(node, dest, false)
 		return c.createImagePlaceholder(dest), nil
 	}
 
 	if !c.options.UploadImages {
 		c.imageStats.Skipped++
+		c.noteImageFallback(node, dest, false)
 		return c.createImagePlaceholder(dest), nil
 	}
+	c.checkLocalMedia(node, "image", dest)
 
 	// 图片三步法上传：
 	// 1. 创建空 Image Block → 获得 imageBlockID
@@ -1859,6 +1913,9 @@ type TableData struct {
 	// （表头单元格 + 初始数据行单元格 + 追加行单元格，长度 = 最终行数 × 列数）。
 	// 仅在 EmbedTableImages 开启且表内确有单元格图片时非 nil；空表示该格无图。导入层据此在单元格内建 Image 子块。
 	CellImages [][]string
+	// Merges: 由 <!-- feishu-merge: ... --> 注释给出的合并区域（行索引含表头、覆盖追加行），
+	// 导入层在填充完单元格后逐个调用 merge_table_cells 还原。
+	Merges []TableMerge
 }
 
 // ConvertTableResult contains both the block and the table data for content filling
@@ -1992,6 +2049,53 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 		}
 	}
 
+	issuesBefore := len(c.issues)
+	defer c.attributeIssues(issuesBefore, node)
+	if cols > maxTableCols {
+		c.addIssue(node, LintWarning, "table", "表格有 %d 列，超过飞书单表 %d 列上限，将拆分为 %d 个表格（首列在各表中重复）",
+			cols, maxTableCols, len(splitColumnGroups(cols)))
+	}
+	if len(dataRows) > largeTableRows {
+		c.addIssue(node, LintWarning, "table", "表格有 %d 行数据，超出单次创建上限 %d 行的部分需逐行追加，导入耗时较长，建议改用电子表格",
+			len(dataRows), maxTableRows)
+	}
+
+	merges := c.pendingMerges
+	c.pendingMerges = nil // 消费即清空
+	return c.buildTableResults(tableCells{
+		cols:            cols,
+		hasHeader:       hasHeader,
+		headerContents:  headerContents,
+		headerElements:  headerElements,
+		headerImages:    headerImages,
+		dataRows:        dataRows,
+		dataRowElements: dataRowElements,
+		dataRowImages:   dataRowImages,
+		merges:          merges,
+	})
+}
+
+// tableCells 是解析完成的表格内容，Markdown 与 HTML 两种来源共用 buildTableResults 建表。
+// 各切片按行列对齐：headerXxx 为表头行，dataRowXxx 为数据行；images 可为 nil。
+type tableCells struct {
+	cols            int
+	hasHeader       bool
+	headerContents  []string
+	headerElements  [][]*larkdocx.TextElement
+	headerImages    [][]string
+	dataRows        [][]string
+	dataRowElements [][][]*larkdocx.TextElement
+	dataRowImages   [][][]string
+	merges          []TableMerge
+}
+
+// buildTableResults 按飞书 API 限制把表格内容转换为一个或多个表格块：
+// 列数 > maxTableCols 时按列组拆分，行数 > maxTableRows 时剩余行交给 insert_table_row 追加。
+func (c *MarkdownToBlock) buildTableResults(t tableCells) []*ConvertTableResult {
+	cols, hasHeader := t.cols, t.hasHeader
+	headerContents, headerElements, headerImages := t.headerContents, t.headerElements, t.headerImages
+	dataRows, dataRowElements, dataRowImages := t.dataRows, t.dataRowElements, t.dataRowImages
+
 	totalRows := len(dataRows)
 	if hasHeader {
 		totalRows++
@@ -1999,6 +2103,7 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 	if totalRows == 0 || cols == 0 {
 		return nil
 	}
+	merges := validTableMerges(t.merges, totalRows, cols)
 
 	// 按列分组（超过 maxTableCols 列时拆分，保留首列作为标识列）
 	colGroups := splitColumnGroups(cols)
@@ -2006,7 +2111,7 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 	// buildRowSplitResults 对一组列的数据执行行拆分，返回拆分后的子表格列表
 	buildRowSplitResults := func(groupCols int, groupHeader []string, groupHeaderElems [][]*larkdocx.TextElement,
 		groupDataRows [][]string, groupDataRowElements [][][]*larkdocx.TextElement, groupColWidths []int, groupHasHeader bool,
-		groupHeaderImages [][]string, groupDataImages [][][]string) []*ConvertTableResult {
+		groupHeaderImages [][]string, groupDataImages [][][]string, groupMerges []TableMerge) []*ConvertTableResult {
 
 		groupTotalRows := len(groupDataRows)
 		if groupHasHeader {
@@ -2074,6 +2179,7 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 			td.ExtraRowContents = extraDataRows
 			td.ExtraRowElements = extraDataElements
 		}
+		td.Merges = groupMerges
 
 		// 组装单元格图片源，顺序与最终表格单元格行优先一致：表头 + 初始数据行 + 追加行。
 		// 仅当确有图片时才挂载（保持无图表格 CellImages 为 nil，导入层据此跳过嵌入）。
@@ -2098,7 +2204,24 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 	// 无需列拆分：直接走行拆分逻辑
 	if colGroups == nil {
 		columnWidths := c.resolveColumnWidths(headerContents, dataRows, cols)
-		return buildRowSplitResults(cols, headerContents, headerElements, dataRows, dataRowElements, columnWidths, hasHeader, headerImages, dataRowImages)
+		return buildRowSplitResults(cols, headerContents, headerElements, dataRows, dataRowElements, columnWidths, hasHeader, headerImages, dataRowImages, merges)
+	}
+
+	// 合并区域按列组映射；跨越拆分边界（不在任何一组内连续）的区域无法还原，提示后丢弃
+	for _, m := range merges {
+		fits := false
+		for _, colIndices := range colGroups {
+			if _, ok := mergeColumnStart(m, colIndices); ok {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			fmt.Fprintf(os.Stderr, "[警告] 表格列数超过 %d 被拆分，合并区域 %d,%d,%d,%d 跨越拆分边界，已忽略\n",
+				maxTableCols, m.Row, m.Col, m.RowSpan, m.ColSpan)
+			c.addIssue(nil, LintWarning, "table", "表格拆分后合并区域 %d,%d,%d,%d 跨越拆分边界，合并将被忽略",
+				m.Row, m.Col, m.RowSpan, m.ColSpan)
+		}
 	}
 
 	// 需要列拆分：对每个列组提取数据，再分别行拆分
@@ -2157,7 +2280,7 @@ func (c *MarkdownToBlock) convertTableWithDataMultiple(node *east.Table) []*Conv
 		groupColWidths := c.resolveColumnWidths(groupHeader, groupDataRows, groupCols)
 
 		// 对该列组执行行拆分
-		results = append(results, buildRowSplitResults(groupCols, groupHeader, groupHeaderElems, groupDataRows, groupDataRowElements, groupColWidths, hasHeader, groupHeaderImages, groupDataImages)...)
+		results = append(results, buildRowSplitResults(groupCols, groupHeader, groupHeaderElems, groupDataRows, groupDataRowElements, groupColWidths, hasHeader, groupHeaderImages, groupDataImages, mergesForColumns(merges, colIndices))...)
 	}
 
 	return results
@@ -2536,6 +2659,7 @@ func (c *MarkdownToBlock) extractChildElements(node ast.Node) []*larkdocx.TextEl
 			// 表格单元格真嵌入场景：收集可嵌入图片源，跳过占位文本（导入层会在单元格内建 Image 子块）。
 			if c.cellImageSink != nil && c.options.UploadImages && isEmbeddableImageDest(dest) {
 				*c.cellImageSink = append(*c.cellImageSink, dest)
+				c.checkLocalMedia(n, "image", dest)
 				continue
 			}
 			// 其它场景（非单元格 / 关闭上传 / feishu:// 内部引用）：降级为占位文本或链接，避免静默丢失。
@@ -2616,6 +2740,7 @@ func (c *MarkdownToBlock) cellFallbackContent(node ast.Node, elems []*larkdocx.T
 // 下划线等上下文样式由各调用点按自身状态叠加。
 func (c *MarkdownToBlock) imageInlinePlaceholder(node *ast.Image) *larkdocx.TextElement {
 	dest := string(node.Destination)
+	c.noteImageFallback(node, dest, true)
 	alt := c.getNodeText(node)
 	if alt == "" {
 		alt = dest
@@ -2848,12 +2973,15 @@ func (c *MarkdownToBlock) handleHTMLImageBlock(tag *HTMLTag) []*BlockNode {
 	if imgURL != "" {
 		if strings.HasPrefix(imgURL, "feishu://media/") {
 			c.imageStats.Skipped++
+			c.noteImageFallback(nil, imgURL, false)
 			return []*BlockNode{{Block: c.createImagePlaceholder(imgURL)}}
 		}
 		if !c.options.UploadImages {
 			c.imageStats.Skipped++
+			c.noteImageFallback(nil, imgURL, false)
 			return []*BlockNode{{Block: c.createImagePlaceholder(imgURL)}}
 		}
+		c.checkLocalMedia(nil, "image", imgURL)
 		c.imageStats.Total++
 		c.imageSources = append(c.imageSources, imgURL)
 		blockType := int(BlockTypeImage)
@@ -3002,6 +3130,11 @@ func (c *MarkdownToBlock) convertInnerMarkdown(markdown string) []*BlockNode {
 	c.videoStats.Total += inner.videoStats.Total
 	c.videoStats.Skipped += inner.videoStats.Skipped
 	c.videoSources = append(c.videoSources, inner.videoSources...)
+	// 内嵌内容的行号相对标签内部，统一归到外层标签所在行
+	for _, issue := range result.Issues {
+		issue.Line = 0
+		c.issues = append(c.issues, issue)
+	}
 	return result.BlockNodes
 }
 
@@ -3087,12 +3220,14 @@ func (c *MarkdownToBlock) handleHTMLFileBlock(tag *HTMLTag) []*BlockNode {
 	if token == "" && name == "" {
 		return nil
 	}
+	// 没有 token 的文件块无法引用任何素材，导入层也只会为登记了来源的 File 块上传，
+	// 保留为占位文字避免与附件/视频上传任务错位
+	if token == "" {
+		return []*BlockNode{{Block: c.createMediaPlaceholder("File", name)}}
+	}
 
 	blockType := int(BlockTypeFile)
-	file := &larkdocx.File{}
-	if token != "" {
-		file.Token = &token
-	}
+	file := &larkdocx.File{Token: &token}
 	if name != "" {
 		file.Name = &name
 	}
@@ -3146,8 +3281,10 @@ func (c *MarkdownToBlock) handleHTMLVideoBlock(tag *HTMLTag) []*BlockNode {
 
 	if !c.options.UploadImages {
 		c.videoStats.Skipped++
+		c.addIssue(nil, LintWarning, "video", "未开启上传，视频 %s 将以占位文字导入", src)
 		return []*BlockNode{{Block: c.createMediaPlaceholder("Video", src)}}
 	}
+	c.checkLocalMedia(nil, "video", src)
 
 	if name == "." || name == string(filepath.Separator) || name == "" {
 		name = filepath.Base(src)
@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// TableMerge 描述表格中的一个合并区域：左上角单元格 (Row, Col)，向下跨 RowSpan 行、向右跨 ColSpan 列。
+// 行列索引均从 0 开始，包含表头行。
+type TableMerge struct {
+	Row     int
+	Col     int
+	RowSpan int
+	ColSpan int
+}
+
+// mergeCommentRe 匹配独占一行的 `<!-- feishu-merge: 0,0,1,2; 1,2,2,1 -->` 注释。
+// 每个区域为「行,列,跨行,跨列」，区域之间用分号分隔；与 feishu-colwidth 一样只作用于紧邻其下的表格。
+var mergeCommentRe = regexp.MustCompile(`(?s)^\s*<!--\s*feishu-merge\s*:\s*([^>]*?)-->\s*$`)
+
+// formatMergeComment 生成表格上方的合并单元格注释，无合并区域时返回空串。
+func formatMergeComment(merges []TableMerge) string {
+	if len(merges) == 0 {
+		return ""
+	}
+	parts := make([]string, len(merges))
+	for i, m := range merges {
+		parts[i] = fmt.Sprintf("%d,%d,%d,%d", m.Row, m.Col, m.RowSpan, m.ColSpan)
+	}
+	return "<!-- feishu-merge: " + strings.Join(parts, "; ") + " -->\n"
+}
+
+// parseMergeList 解析注释中的合并区域列表。格式错误或未跨越多格的片段被忽略并提示。
+func parseMergeList(s string) []TableMerge {
+	var merges []TableMerge
+	for _, raw := range strings.Split(s, ";") {
+		p := strings.TrimSpace(raw)
+		if p == "" {
+			continue
+		}
+		fields := strings.Split(p, ",")
+		if len(fields) != 4 {
+			fmt.Fprintf(os.Stderr, "[警告] feishu-merge 片段 %q 格式错误（应为 行,列,跨行,跨列），已忽略\n", p)
+			continue
+		}
+		var v [4]int
+		ok := true
+		for i, f := range fields {
+			n, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil || n < 0 {
+				ok = false
+				break
+			}
+			v[i] = n
+		}
+		m := TableMerge{Row: v[0], Col: v[1], RowSpan: v[2], ColSpan: v[3]}
+		if !ok || m.RowSpan < 1 || m.ColSpan < 1 || m.RowSpan*m.ColSpan == 1 {
+			fmt.Fprintf(os.Stderr, "[警告] feishu-merge 片段 %q 不是有效的合并区域，已忽略\n", p)
+			continue
+		}
+		merges = append(merges, m)
+	}
+	return merges
+}
+
+// tableMergesFromInfo 从表格属性的 merge_info（按行优先与 cells 对齐）提取合并区域。
+// 被其他区域覆盖的单元格不再单独计入，超出表格边界的跨度截断到边界。
+func tableMergesFromInfo(info []*larkdocx.TableMergeInfo, rows, cols int) []TableMerge {
+	var merges []TableMerge
+	covered := make([]bool, rows*cols)
+	for idx := 0; idx < len(info) && idx < rows*cols; idx++ {
+		if covered[idx] || info[idx] == nil {
+			continue
+		}
+		r, c := idx/cols, idx%cols
+		m := TableMerge{Row: r, Col: c, RowSpan: 1, ColSpan: 1}
+		if info[idx].RowSpan != nil && *info[idx].RowSpan > 1 {
+			m.RowSpan = min(*info[idx].RowSpan, rows-r)
+		}
+		if info[idx].ColSpan != nil && *info[idx].ColSpan > 1 {
+			m.ColSpan = min(*info[idx].ColSpan, cols-c)
+		}
+		if m.RowSpan*m.ColSpan == 1 {
+			continue
+		}
+		for i := r; i < r+m.RowSpan; i++ {
+			for j := c; j < c+m.ColSpan; j++ {
+				covered[i*cols+j] = true
+			}
+		}
+		merges = append(merges, m)
+	}
+	return merges
+}
+
+// validTableMerges 过滤掉超出 rows×cols 或与前面区域重叠的合并区域，并提示被丢弃的项。
+func validTableMerges(merges []TableMerge, rows, cols int) []TableMerge {
+	var out []TableMerge
+	covered := make([]bool, rows*cols)
+	for _, m := range merges {
+		if m.Row+m.RowSpan > rows || m.Col+m.ColSpan > cols {
+			fmt.Fprintf(os.Stderr, "[警告] feishu-merge 区域 %d,%d,%d,%d 超出 %d×%d 表格，已忽略\n",
+				m.Row, m.Col, m.RowSpan, m.ColSpan, rows, cols)
+			continue
+		}
+		overlap := false
+		for i := m.Row; i < m.Row+m.RowSpan && !overlap; i++ {
+			for j := m.Col; j < m.Col+m.ColSpan; j++ {
+				if covered[i*cols+j] {
+					overlap = true
+					break
+				}
+			}
+		}
+		if overlap {
+			fmt.Fprintf(os.Stderr, "[警告] feishu-merge 区域 %d,%d,%d,%d 与其他区域重叠，已忽略\n",
+				m.Row, m.Col, m.RowSpan, m.ColSpan)
+			continue
+		}
+		for i := m.Row; i < m.Row+m.RowSpan; i++ {
+			for j := m.Col; j < m.Col+m.ColSpan; j++ {
+				covered[i*cols+j] = true
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// mergesForColumns 把整表的合并区域映射到列拆分后的某一组（colIndices 为该组包含的原始列），
+// 只保留覆盖的列在组内连续出现的区域。
+func mergesForColumns(merges []TableMerge, colIndices []int) []TableMerge {
+	var kept []TableMerge
+	for _, m := range merges {
+		if start, ok := mergeColumnStart(m, colIndices); ok {
+			kept = append(kept, TableMerge{Row: m.Row, Col: start, RowSpan: m.RowSpan, ColSpan: m.ColSpan})
+		}
+	}
+	return kept
+}
+
+// mergeColumnStart 返回合并区域首列在 colIndices 中的位置；区域的列在组内不连续时 ok 为 false。
+func mergeColumnStart(m TableMerge, colIndices []int) (int, bool) {
+	for i, col := range colIndices {
+		if col != m.Col {
+			continue
+		}
+		for j := 1; j < m.ColSpan; j++ {
+			if i+j >= len(colIndices) || colIndices[i+j] != m.Col+j {
+				return 0, false
+			}
+		}
+		return i, true
+	}
+	return 0, false
+}
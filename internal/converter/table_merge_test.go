@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func TestParseMergeList(t *testing.T) {
+	got := parseMergeList(" 0,0,1,2; 1, 2, 2, 1 ;;x,1,1,2; 0,0,1,1; 1,1,2")
+	want := []TableMerge{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}, {Row: 1, Col: 2, RowSpan: 2, ColSpan: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMergeList = %v, want %v", got, want)
+	}
+}
+
+// TestTableMergeRoundTrip 验证带 merge_info 的表格导出为 feishu-merge 注释，再导入时还原到 TableData.Merges。
+func TestTableMergeRoundTrip(t *testing.T) {
+	cellIDs := []string{"c00", "c01", "c02", "c10", "c11", "c12", "c20", "c21", "c22"}
+	rows, cols := 3, 3
+	merge := make([]*larkdocx.TableMergeInfo, 9)
+	for i := range merge {
+		one := 1
+		merge[i] = &larkdocx.TableMergeInfo{RowSpan: &one, ColSpan: &one}
+	}
+	two := 2
+	merge[0].ColSpan = &two
+	merge[5].RowSpan = &two
+
+	table := embeddedBlock("t", BlockTypeTable)
+	table.Table = &larkdocx.Table{Cells: cellIDs, Property: &larkdocx.TableProperty{RowSize: &rows, ColumnSize: &cols, MergeInfo: merge}}
+	blocks := []*larkdocx.Block{embeddedBlock("page", BlockTypePage, "t"), table}
+	for _, id := range cellIDs {
+		cell := embeddedBlock(id, BlockTypeTableCell, id+"-p")
+		cell.TableCell = &larkdocx.TableCell{}
+		blocks = append(blocks, cell, htmlTextBlock(id+"-p", BlockTypeText, htmlRun(id, nil)))
+	}
+
+	md, err := NewBlockToMarkdown(blocks, ConvertOptions{}).Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md, "<!-- feishu-merge: 0,0,1,2; 1,2,2,1 -->\n| c00 | c01 | c02 |") {
+		t.Fatalf("导出缺少合并注释:\n%s", md)
+	}
+
+	res, err := NewMarkdownToBlock([]byte(md), ConvertOptions{}, "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TableDatas) != 1 {
+		t.Fatalf("应有 1 张表，实际 %d", len(res.TableDatas))
+	}
+	want := []TableMerge{{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2}, {Row: 1, Col: 2, RowSpan: 2, ColSpan: 1}}
+	if got := res.TableDatas[0].Merges; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merges = %v, want %v", got, want)
+	}
+
+	// 无合并的表格不输出注释
+	for _, m := range merge {
+		one := 1
+		m.RowSpan, m.ColSpan = &one, &one
+	}
+	md, _ = NewBlockToMarkdown(blocks, ConvertOptions{}).Convert()
+	if strings.Contains(md, "feishu-merge") {
+		t.Errorf("无合并时不应输出注释:\n%s", md)
+	}
+}
+
+func TestTableMergeComment_ColumnSplitAndInvalid(t *testing.T) {
+	// 11 列触发列拆分：第一组 col0..col8，第二组 col0+col9+col10
+	header := "| c0 | c1 | c2 | c3 | c4 | c5 | c6 | c7 | c8 | c9 | c10 |"
+	sep := "|---|---|---|---|---|---|---|---|---|---|---|"
+	row := "| 0 | 1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 |"
+	comment := "<!-- feishu-merge: 0,0,2,1; 1,1,1,2; 1,8,1,2; 0,9,1,2; 1,3,5,1 -->"
+	md := comment + "\n" + header + "\n" + sep + "\n" + row + "\n" + row + "\n"
+	res, err := NewMarkdownToBlock([]byte(md), ConvertOptions{}, "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TableDatas) != 2 {
+		t.Fatalf("11 列应拆成 2 张表，实际 %d", len(res.TableDatas))
+	}
+	want1 := []TableMerge{{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1}, {Row: 1, Col: 1, RowSpan: 1, ColSpan: 2}}
+	if got := res.TableDatas[0].Merges; !reflect.DeepEqual(got, want1) {
+		t.Errorf("group1 Merges = %v, want %v", got, want1)
+	}
+	want2 := []TableMerge{{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1}, {Row: 0, Col: 1, RowSpan: 1, ColSpan: 2}}
+	if got := res.TableDatas[1].Merges; !reflect.DeepEqual(got, want2) {
+		t.Errorf("group2 Merges = %v, want %v", got, want2)
+	}
+
+	// 注释与表格之间隔了段落时不生效
+	md = comment + "\n\n说明\n\n| a | b |\n|---|---|\n| 1 | 2 |\n"
+	res, err = NewMarkdownToBlock([]byte(md), ConvertOptions{}, "").ConvertWithTableData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TableDatas) != 1 || res.TableDatas[0].Merges != nil {
+		t.Errorf("悬浮的合并注释不应作用于下游表格: %+v", res.TableDatas)
+	}
+}
@@ -141,6 +141,7 @@ type ConvertResult struct {
 	ImageSources []string     // 每个 Image Block 对应的图片来源路径，与 BlockNodes 中的 Image Block 按序对应
 	VideoStats   VideoStats   // 视频处理统计
 	VideoSources []string     // 每个 Video(File) Block 对应的视频来源路径，与 BlockNodes 中的视频块按序对应
+	Issues       []LintIssue  // 转换中发现的会降级或丢失的写法，按行号排序（doc lint / 导入预检使用）
 }
 
 // ImageStats 记录图片处理统计
@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/riba2534/feishu-cli/internal/filelock"
 	"github.com/riba2534/feishu-cli/internal/profile"
 )
 
@@ -68,14 +69,42 @@ func sanitizeAppID(appID string) string {
 
 // ConsumerEntry 是 bus.json 中的一条 consumer 记录。
 // 一个 (PID, EventKey) 元组对应一个独立的 consume 进程。
+// 多 key 会话只登记一条：EventKey 为逗号拼接的展示名，EventKeys 为完整列表。
 type ConsumerEntry struct {
 	PID        int       `json:"pid"`
 	EventKey   string    `json:"event_key"`
+	EventKeys  []string  `json:"event_keys,omitempty"`
 	StartedAt  time.Time `json:"started_at"`
 	OutputDir  string    `json:"output_dir,omitempty"`
+	FilterExpr string    `json:"filter_expr,omitempty"`
 	JQExpr     string    `json:"jq_expr,omitempty"`
+	ExecCmd    string    `json:"exec_cmd,omitempty"`
+	ForwardURL string    `json:"forward_url,omitempty"`
 	MaxEvents  int       `json:"max_events,omitempty"`
 	TimeoutSec int       `json:"timeout_sec,omitempty"`
+
+	// 去重统计（--dedup 时由 consume 定期写回，非实时）
+	Dedup           bool  `json:"dedup,omitempty"`
+	DedupSeen       int64 `json:"dedup_seen,omitempty"`
+	DedupDuplicates int64 `json:"dedup_duplicates,omitempty"`
+}
+
+// Keys 返回该 consumer 订阅的全部 EventKey（兼容只写了 EventKey 的旧条目）。
+func (c ConsumerEntry) Keys() []string {
+	if len(c.EventKeys) > 0 {
+		return c.EventKeys
+	}
+	return []string{c.EventKey}
+}
+
+// HasKey 报告该 consumer 是否订阅了 key。
+func (c ConsumerEntry) HasKey(key string) bool {
+	for _, k := range c.Keys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
 }
 
 // IsAlive 检查 PID 对应的进程是否还存活（不可移植：仅 Unix）。
@@ -176,7 +205,7 @@ func (b *Bus) withLock(fn func(state *BusState) error) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	return withBusFileLock(b.LockFile(), func() error {
+	return filelock.With(b.LockFile(), func() error {
 		state, err := b.load()
 		if err != nil {
 			return err
@@ -218,6 +247,21 @@ func (b *Bus) Unregister(pid int, eventKey string) error {
 	})
 }
 
+// UpdateDedupStats 更新指定 (PID, EventKey) 条目的去重统计；条目不存在时忽略。
+func (b *Bus) UpdateDedupStats(pid int, eventKey string, seen, duplicates int64) error {
+	return b.withLock(func(state *BusState) error {
+		for i := range state.Consumers {
+			c := &state.Consumers[i]
+			if c.PID == pid && c.EventKey == eventKey {
+				c.DedupSeen = seen
+				c.DedupDuplicates = duplicates
+				return b.save(state)
+			}
+		}
+		return nil
+	})
+}
+
 // Snapshot 返回当前 consumer 列表的拷贝（status 查询用）。
 // 同时清理已不存活的 PID 记录，保持 bus.json 不积累僵尸条目。
 func (b *Bus) Snapshot() (*BusState, error) {
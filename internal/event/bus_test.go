@@ -161,3 +161,44 @@ func TestConsumerEntry_IsAlive(t *testing.T) {
 		t.Errorf("PID 0 不应判定为存活")
 	}
 }
+
+func TestBus_UpdateDedupStats(t *testing.T) {
+	bus := setupBus(t)
+	entry := ConsumerEntry{PID: os.Getpid(), EventKey: "approval.instance.status_changed_v4", StartedAt: time.Now(), Dedup: true}
+	if err := bus.Register(entry); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := bus.UpdateDedupStats(entry.PID, entry.EventKey, 12, 3); err != nil {
+		t.Fatalf("UpdateDedupStats: %v", err)
+	}
+	// 不存在的条目静默忽略
+	if err := bus.UpdateDedupStats(entry.PID, "im.message.receive_v1", 1, 1); err != nil {
+		t.Fatalf("UpdateDedupStats(unknown): %v", err)
+	}
+
+	snap, err := bus.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if len(snap.Consumers) != 1 {
+		t.Fatalf("Snapshot 期望 1 条 consumer，实际 %d", len(snap.Consumers))
+	}
+	c := snap.Consumers[0]
+	if !c.Dedup || c.DedupSeen != 12 || c.DedupDuplicates != 3 {
+		t.Errorf("去重统计不符: %+v", c)
+	}
+}
+
+func TestConsumerEntry_HasKey(t *testing.T) {
+	single := ConsumerEntry{EventKey: "im.message.receive_v1"}
+	if !single.HasKey("im.message.receive_v1") || single.HasKey("card.action.trigger") {
+		t.Errorf("单 key 条目匹配不符: %+v", single.Keys())
+	}
+	multi := ConsumerEntry{
+		EventKey:  "im.message.receive_v1,card.action.trigger",
+		EventKeys: []string{"im.message.receive_v1", "card.action.trigger"},
+	}
+	if !multi.HasKey("card.action.trigger") || multi.HasKey("im.message.receive_v1,card.action.trigger") {
+		t.Errorf("多 key 条目应按列表匹配: %+v", multi.Keys())
+	}
+}
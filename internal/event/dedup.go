@@ -0,0 +1,277 @@
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/filelock"
+)
+
+// 去重默认参数：飞书对未 ACK 事件的重推窗口在小时级，24h 足够覆盖重连 + 进程重启。
+const (
+	DefaultDedupTTL        = 24 * time.Hour
+	DefaultDedupMaxEntries = 10000
+)
+
+// dedupRecord 是去重日志中的一行（NDJSON）。
+type dedupRecord struct {
+	ID string `json:"id"`
+	TS int64  `json:"ts"` // 首次见到的 Unix 秒
+}
+
+// DedupStore 是按 event_id 去重的持久化存储，跨 consume 重启生效。
+//
+// 存储格式：<AppDir>/dedup/<event_key>.ndjson，每行一个 dedupRecord，只追加不改写；
+// 打开时与追加行数超过上限时做一次压缩（重新读入日志、丢弃过期 / 超量的最旧记录后 tmp + rename 重写）。
+// 每个 EventKey 独立文件：不同 key 的 consume 进程互不争用。
+// 同一 EventKey 同时跑多个 consume 进程时各自在内存中去重，不保证跨进程互斥；
+// 但追加与压缩都在 <path>.lock 文件锁内进行，追加前发现日志已被其它进程压缩替换会重新打开，不丢记录。
+type DedupStore struct {
+	path       string
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	seen     map[string]int64 // event_id → 首次见到的 Unix 秒
+	order    []dedupRecord    // 按时间顺序，用于淘汰最旧记录
+	file     *os.File         // 追加句柄
+	appended int              // 上次压缩后追加的行数
+	now      func() time.Time // 测试注入
+}
+
+// DedupPath 返回某个 EventKey 的去重日志路径。
+func DedupPath(appID, eventKey string) (string, error) {
+	dir, err := AppDir(appID)
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "dedup")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("创建去重目录失败: %w", err)
+	}
+	return filepath.Join(dir, sanitizeEventKey(eventKey)+".ndjson"), nil
+}
+
+// OpenDedupStore 打开（或创建）去重日志并加载未过期记录。
+// ttl<=0 用 DefaultDedupTTL，maxEntries<=0 用 DefaultDedupMaxEntries。
+func OpenDedupStore(path string, ttl time.Duration, maxEntries int) (*DedupStore, error) {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultDedupMaxEntries
+	}
+	s := &DedupStore{
+		path:       path,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		seen:       make(map[string]int64),
+		now:        time.Now,
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load 读取日志；损坏行（如进程在写入中途被 kill）直接跳过。
+func (s *DedupStore) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取去重日志失败: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec dedupRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.ID == "" {
+			continue
+		}
+		if _, dup := s.seen[rec.ID]; dup {
+			continue
+		}
+		s.seen[rec.ID] = rec.TS
+		s.order = append(s.order, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取去重日志失败: %w", err)
+	}
+	return nil
+}
+
+func (s *DedupStore) lockPath() string { return s.path + ".lock" }
+
+// compact 在文件锁内重新读入日志（含其它进程的追加），淘汰过期 / 超量记录后重写，
+// 然后重新打开追加句柄。调用方持 mu 或处于构造阶段。
+func (s *DedupStore) compact() error {
+	return filelock.With(s.lockPath(), s.compactLocked)
+}
+
+func (s *DedupStore) compactLocked() error {
+	s.seen = make(map[string]int64)
+	s.order = nil
+	if err := s.load(); err != nil {
+		return err
+	}
+	sort.SliceStable(s.order, func(i, j int) bool { return s.order[i].TS < s.order[j].TS })
+	s.evict()
+
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	tmp := s.path + ".tmp." + strconv.Itoa(os.Getpid())
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("写入去重日志失败: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, rec := range s.order {
+		line, _ := json.Marshal(rec)
+		_, _ = w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入去重日志失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("写入去重日志失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("替换去重日志失败: %w", err)
+	}
+
+	s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开去重日志失败: %w", err)
+	}
+	s.appended = 0
+	return nil
+}
+
+// evict 从最旧一端淘汰过期记录，再按 maxEntries 截断。
+func (s *DedupStore) evict() {
+	cutoff := s.now().Add(-s.ttl).Unix()
+	drop := 0
+	for drop < len(s.order) && s.order[drop].TS < cutoff {
+		drop++
+	}
+	if over := len(s.order) - drop - s.maxEntries; over > 0 {
+		drop += over
+	}
+	if drop == 0 {
+		return
+	}
+	for _, rec := range s.order[:drop] {
+		delete(s.seen, rec.ID)
+	}
+	s.order = append([]dedupRecord(nil), s.order[drop:]...)
+}
+
+// Seen 报告 eventID 是否已处理过；未处理过时记录并持久化，返回 false。
+// 空 eventID 无法去重，总是返回 false 且不记录。
+// 持久化失败不影响判定结果（内存中已记录），错误返回给调用方记日志。
+func (s *DedupStore) Seen(eventID string) (bool, error) {
+	if eventID == "" {
+		return false, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 先淘汰过期记录：order 按时间有序，过期的 event_id 会从 map 中一并移除，不会被误判为重复
+	s.evict()
+	if _, ok := s.seen[eventID]; ok {
+		return true, nil
+	}
+
+	rec := dedupRecord{ID: eventID, TS: s.now().Unix()}
+	s.seen[eventID] = rec.TS
+	s.order = append(s.order, rec)
+	if len(s.order) > s.maxEntries {
+		s.evict()
+	}
+
+	if err := filelock.With(s.lockPath(), func() error { return s.appendLocked(rec) }); err != nil {
+		return false, err
+	}
+	s.appended++
+	// 日志行数膨胀到上限两倍时压缩，避免文件无界增长
+	if s.appended > s.maxEntries {
+		if err := s.compact(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// appendLocked 追加一行记录；日志已被其它进程压缩替换时先重新打开，避免写进已被删除的旧文件。
+func (s *DedupStore) appendLocked(rec dedupRecord) error {
+	if s.file == nil {
+		return fmt.Errorf("去重日志未打开")
+	}
+	cur, err := os.Stat(s.path)
+	open, openErr := s.file.Stat()
+	if err != nil || openErr != nil || !os.SameFile(cur, open) {
+		_ = s.file.Close()
+		if s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600); err != nil {
+			return fmt.Errorf("打开去重日志失败: %w", err)
+		}
+	}
+	line, _ := json.Marshal(rec)
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("写入去重日志失败: %w", err)
+	}
+	return nil
+}
+
+// Len 返回当前保留的 event_id 数。
+func (s *DedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.seen)
+}
+
+// Close 关闭追加句柄。
+func (s *DedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// sanitizeEventKey 把 EventKey 映射为安全文件名（EventKey 形如 im.message.receive_v1，保留 .）。
+func sanitizeEventKey(key string) string {
+	b := make([]rune, 0, len(key))
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9',
+			r == '_' || r == '-' || r == '.':
+			b = append(b, r)
+		}
+	}
+	out := string(b)
+	if out == "" || out == "." || out == ".." {
+		return "unknown"
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+package event
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func openTestDedup(t *testing.T, path string, ttl time.Duration, max int, now *time.Time) *DedupStore {
+	t.Helper()
+	s, err := OpenDedupStore(path, ttl, max)
+	if err != nil {
+		t.Fatalf("OpenDedupStore: %v", err)
+	}
+	if now != nil {
+		s.now = func() time.Time { return *now }
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func mustSeen(t *testing.T, s *DedupStore, id string) bool {
+	t.Helper()
+	dup, err := s.Seen(id)
+	if err != nil {
+		t.Fatalf("Seen(%q): %v", id, err)
+	}
+	return dup
+}
+
+func TestDedupStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "im.message.receive_v1.ndjson")
+	s := openTestDedup(t, path, 0, 0, nil)
+	if mustSeen(t, s, "ev_1") {
+		t.Fatal("首次出现不应判为重复")
+	}
+	if !mustSeen(t, s, "ev_1") {
+		t.Fatal("同进程内重推应判为重复")
+	}
+	if mustSeen(t, s, "") || mustSeen(t, s, "") {
+		t.Fatal("空 event_id 无法去重，应总是放行")
+	}
+	_ = s.Close()
+
+	// 模拟 consume 重启
+	s2 := openTestDedup(t, path, 0, 0, nil)
+	if !mustSeen(t, s2, "ev_1") {
+		t.Fatal("重启后已处理过的 event_id 应判为重复")
+	}
+	if mustSeen(t, s2, "ev_2") {
+		t.Fatal("新 event_id 不应判为重复")
+	}
+}
+
+func TestDedupStore_TTLExpires(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k.ndjson")
+	now := time.Unix(1_700_000_000, 0)
+	s := openTestDedup(t, path, time.Hour, 0, &now)
+	mustSeen(t, s, "ev_old")
+
+	now = now.Add(2 * time.Hour)
+	if mustSeen(t, s, "ev_old") {
+		t.Error("超过 TTL 的记录应过期，不再判为重复")
+	}
+	if !mustSeen(t, s, "ev_old") {
+		t.Error("过期后重新记录，再次出现应判为重复")
+	}
+}
+
+func TestDedupStore_MaxEntriesEvictsOldestAndCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k.ndjson")
+	s := openTestDedup(t, path, 0, 3, nil)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		mustSeen(t, s, id)
+	}
+	if s.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", s.Len())
+	}
+	if mustSeen(t, s, "a") {
+		t.Error("超出上限的最旧记录应被淘汰")
+	}
+
+	// 持续写入后日志行数应被压缩在 2×上限之内
+	for _, id := range []string{"e", "f", "g", "h", "i", "j"} {
+		mustSeen(t, s, id)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		lines++
+	}
+	if lines > 6 {
+		t.Errorf("日志未压缩: %d 行", lines)
+	}
+}
+
+func TestDedupStore_CompactKeepsOtherProcessAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k.ndjson")
+	a := openTestDedup(t, path, 0, 0, nil)
+	b := openTestDedup(t, path, 0, 0, nil)
+
+	mustSeen(t, b, "ev_b1")
+	mustSeen(t, a, "ev_a1")
+	// a 压缩重写日志：应读入 b 已追加的记录
+	if err := a.compact(); err != nil {
+		t.Fatal(err)
+	}
+	// b 仍持有被替换掉的旧文件句柄，追加前应重新打开
+	mustSeen(t, b, "ev_b2")
+
+	c := openTestDedup(t, path, 0, 0, nil)
+	for _, id := range []string{"ev_a1", "ev_b1", "ev_b2"} {
+		if !mustSeen(t, c, id) {
+			t.Errorf("%s 应保留在日志中", id)
+		}
+	}
+}
+
+func TestDedupStore_SkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k.ndjson")
+	data := "{\"id\":\"ev_1\",\"ts\":" + strconv.FormatInt(time.Now().Unix(), 10) + "}\n{\"id\":\"ev_2\",\"t"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s := openTestDedup(t, path, 0, 0, nil)
+	if !mustSeen(t, s, "ev_1") {
+		t.Error("完整行应被加载")
+	}
+	if mustSeen(t, s, "ev_2") {
+		t.Error("截断行应被跳过")
+	}
+}
+
+func TestSanitizeEventKey(t *testing.T) {
+	cases := map[string]string{
+		"im.message.receive_v1": "im.message.receive_v1",
+		"../../etc/passwd":      "....etcpasswd",
+		"..":                    "unknown",
+		"":                      "unknown",
+	}
+	for in, want := range cases {
+		if got := sanitizeEventKey(in); got != want {
+			t.Errorf("sanitizeEventKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
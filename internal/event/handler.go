@@ -0,0 +1,325 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 处理器默认参数：并发与超时面向"本地脚本/本地服务"量级，重试覆盖短暂抖动。
+const (
+	DefaultHandlerConcurrency = 4
+	DefaultHandlerTimeout     = 30 * time.Second
+	DefaultHandlerRetries     = 2
+	DefaultHandlerBackoff     = time.Second
+
+	maxHandlerBackoff  = 30 * time.Second
+	handlerOutputLimit = 4 << 10 // 失败时附带的 stderr / 响应体上限
+)
+
+// HandlerOptions 配置 consume 的事件处理器模式：每条通过 --filter 的事件
+// 交给 --exec 命令（stdin 传事件 JSON）和/或 POST 到 --forward-url。
+type HandlerOptions struct {
+	ExecCmd    string // 非空时每条事件执行一次（sh -c / cmd /C），事件 JSON 写到 stdin
+	ForwardURL string // 非空时每条事件 POST 到该 URL（body 为事件 JSON）
+
+	Concurrency   int           // 同时执行的处理器上限；<=0 用 DefaultHandlerConcurrency
+	Timeout       time.Duration // 单次执行超时；<=0 用 DefaultHandlerTimeout
+	Retries       int           // 失败后的重试次数（不含首次）；<0 视为 0
+	Backoff       time.Duration // 首次重试等待，之后指数翻倍（上限 30s）；<=0 用 DefaultHandlerBackoff
+	DeadLetterDir string        // 非空时把重试耗尽的事件写为 <event_id>.<handler>.json
+}
+
+// Enabled 报告是否配置了任一处理器。
+func (o HandlerOptions) Enabled() bool {
+	return o.ExecCmd != "" || o.ForwardURL != ""
+}
+
+// ValidateHandlerOptions 校验处理器相关 flag；未配置处理器时只检查 dead-letter 目录的路径边界。
+func ValidateHandlerOptions(o HandlerOptions) error {
+	if o.ForwardURL != "" {
+		u, err := url.Parse(o.ForwardURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--forward-url 必须是 http(s):// 开头的完整 URL，如 http://127.0.0.1:8080/hook")
+		}
+	}
+	if o.Retries < 0 {
+		return fmt.Errorf("--handler-retries 不能为负数")
+	}
+	if err := validateSafeRelDir("dead-letter-dir", o.DeadLetterDir); err != nil {
+		return err
+	}
+	if o.DeadLetterDir != "" && !o.Enabled() {
+		return fmt.Errorf("--dead-letter-dir 需要配合 --exec 或 --forward-url 使用")
+	}
+	return nil
+}
+
+// handlerEvent 是交给处理器的一条事件：原始 body + 用于 env/header/文件名的元信息。
+type handlerEvent struct {
+	body      []byte
+	eventKey  string
+	eventID   string
+	eventType string
+	chatID    string
+}
+
+// handlerDispatcher 以有界并发执行处理器，负责超时、重试退避与 dead-letter 落盘。
+type handlerDispatcher struct {
+	opts   HandlerOptions
+	errOut io.Writer
+	client *http.Client
+
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+func newHandlerDispatcher(opts HandlerOptions, errOut io.Writer) *handlerDispatcher {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultHandlerConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultHandlerTimeout
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultHandlerBackoff
+	}
+	return &handlerDispatcher{
+		opts:   opts,
+		errOut: errOut,
+		// 超时由每次执行的 context 控制，这里不再设 Client.Timeout
+		client: &http.Client{},
+		sem:    make(chan struct{}, opts.Concurrency),
+	}
+}
+
+// dispatch 占用一个并发槽后异步执行处理器。槽位满时阻塞调用方（SDK 回调）形成背压，
+// 而不是无限堆积 goroutine；ctx 取消时放弃派发并返回 false。
+// ctx 同时控制重试退避：consume 退出后不再重试，剩余失败直接进 dead-letter。
+func (d *handlerDispatcher) dispatch(ctx context.Context, ev handlerEvent) bool {
+	select {
+	case d.sem <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	d.wg.Add(1)
+	go func() {
+		defer func() {
+			<-d.sem
+			d.wg.Done()
+		}()
+		if d.opts.ExecCmd != "" {
+			d.runWithRetry(ctx, ev, "exec", d.runExec)
+		}
+		if d.opts.ForwardURL != "" {
+			d.runWithRetry(ctx, ev, "forward", d.runForward)
+		}
+	}()
+	return true
+}
+
+// wait 等待所有在途处理器结束（consume 退出前调用，保证已派发的事件不被中途丢弃）。
+func (d *handlerDispatcher) wait() {
+	d.wg.Wait()
+}
+
+// counts 返回处理成功 / 最终失败的次数（按处理器计，exec 与 forward 各算一次）。
+func (d *handlerDispatcher) counts() (succeeded, failed int64) {
+	return d.succeeded.Load(), d.failed.Load()
+}
+
+// runWithRetry 执行一次处理器 + 最多 Retries 次重试。
+// 单次执行用独立的超时 context（不继承 retryCtx），consume 收到退出信号时在途执行可以跑完。
+func (d *handlerDispatcher) runWithRetry(retryCtx context.Context, ev handlerEvent, name string,
+	run func(context.Context, handlerEvent) error) {
+	attempts := 0
+	backoff := d.opts.Backoff
+	var lastErr error
+	for {
+		attempts++
+		execCtx, cancel := context.WithTimeout(context.Background(), d.opts.Timeout)
+		lastErr = run(execCtx, ev)
+		cancel()
+		if lastErr == nil {
+			d.succeeded.Add(1)
+			return
+		}
+		if attempts > d.opts.Retries {
+			break
+		}
+		fmt.Fprintf(d.errOut, "[event] %s 处理失败（第 %d 次），%s 后重试 event_id=%s: %v\n",
+			name, attempts, backoff, ev.eventID, lastErr)
+		select {
+		case <-time.After(backoff):
+		case <-retryCtx.Done():
+			lastErr = fmt.Errorf("consume 退出，放弃重试: %w", lastErr)
+			d.fail(ev, name, attempts, lastErr)
+			return
+		}
+		backoff *= 2
+		if backoff > maxHandlerBackoff {
+			backoff = maxHandlerBackoff
+		}
+	}
+	d.fail(ev, name, attempts, lastErr)
+}
+
+// fail 记录最终失败并按需写 dead-letter。
+func (d *handlerDispatcher) fail(ev handlerEvent, name string, attempts int, err error) {
+	d.failed.Add(1)
+	fmt.Fprintf(d.errOut, "[event] %s 处理最终失败（共 %d 次）event_id=%s: %v\n", name, attempts, ev.eventID, err)
+	if d.opts.DeadLetterDir == "" {
+		return
+	}
+	path, werr := d.writeDeadLetter(ev, name, attempts, err)
+	if werr != nil {
+		fmt.Fprintf(d.errOut, "[event] 警告: 写 dead-letter 失败: %v\n", werr)
+		return
+	}
+	fmt.Fprintf(d.errOut, "[event] 已写入 dead-letter: %s\n", path)
+}
+
+// deadLetterRecord 是 dead-letter 文件的结构；event 保留原始 JSON，便于修复后重放。
+type deadLetterRecord struct {
+	EventKey string          `json:"event_key"`
+	EventID  string          `json:"event_id,omitempty"`
+	Handler  string          `json:"handler"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+	Event    json.RawMessage `json:"event"`
+}
+
+// writeDeadLetter 写 <dir>/<event_id>.<handler>.json；event_id 不可用时以时间戳代替。
+func (d *handlerDispatcher) writeDeadLetter(ev handlerEvent, name string, attempts int, err error) (string, error) {
+	now := time.Now()
+	rec := deadLetterRecord{
+		EventKey: ev.eventKey,
+		EventID:  ev.eventID,
+		Handler:  name,
+		Attempts: attempts,
+		Error:    err.Error(),
+		FailedAt: now,
+		Event:    json.RawMessage(ev.body),
+	}
+	if !json.Valid(ev.body) {
+		raw, _ := json.Marshal(string(ev.body))
+		rec.Event = raw
+	}
+	data, merr := json.MarshalIndent(rec, "", "  ")
+	if merr != nil {
+		return "", merr
+	}
+	id := sanitizeEventID(ev.eventID)
+	if id == "" {
+		id = "noid-" + now.UTC().Format("20060102T150405.000000000")
+	}
+	path := filepath.Join(d.opts.DeadLetterDir, id+"."+name+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// runExec 通过系统 shell 执行 ExecCmd：事件 JSON 写 stdin，元信息走 FEISHU_EVENT_* 环境变量。
+// 非 0 退出码、超时都视为失败。子进程 stdout 丢弃（不能混进 consume 的 NDJSON 流），
+// stderr 截断后附在错误里便于排查。
+func (d *handlerDispatcher) runExec(ctx context.Context, ev handlerEvent) error {
+	var cmd *exec.Cmd
+	if goruntime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", d.opts.ExecCmd)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", d.opts.ExecCmd)
+	}
+	cmd.Stdin = bytes.NewReader(ev.body)
+	cmd.Env = append(os.Environ(), d.handlerEnv(ev)...)
+	stderr := &limitedBuffer{limit: handlerOutputLimit}
+	cmd.Stderr = stderr
+	// sh -c 派生的孙进程可能在超时 kill 后仍持有 stderr 管道，WaitDelay 兜底避免 Run 卡住
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("执行超时（%s）", d.opts.Timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w, stderr: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// handlerEnv 生成传给 --exec 子进程的环境变量。
+func (d *handlerDispatcher) handlerEnv(ev handlerEvent) []string {
+	return []string{
+		"FEISHU_EVENT_KEY=" + ev.eventKey,
+		"FEISHU_EVENT_TYPE=" + ev.eventType,
+		"FEISHU_EVENT_ID=" + ev.eventID,
+		"FEISHU_EVENT_CHAT_ID=" + ev.chatID,
+	}
+}
+
+// runForward 把事件 JSON POST 到 ForwardURL；只有 2xx 视为成功。
+func (d *handlerDispatcher) runForward(ctx context.Context, ev handlerEvent) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.opts.ForwardURL, bytes.NewReader(ev.body))
+	if err != nil {
+		return fmt.Errorf("构造转发请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Feishu-Event-Key", ev.eventKey)
+	req.Header.Set("X-Feishu-Event-Type", ev.eventType)
+	req.Header.Set("X-Feishu-Event-Id", ev.eventID)
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("转发超时（%s）", d.opts.Timeout)
+		}
+		return fmt.Errorf("转发失败: %w", err)
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, handlerOutputLimit))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("转发失败: HTTP %d, body: %s", resp.StatusCode, truncateForErr(respBody))
+	}
+	return nil
+}
+
+// limitedBuffer 只保留前 limit 字节的 io.Writer，防止失控的子进程 stderr 撑爆内存。
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,191 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func sampleHandlerEvent() handlerEvent {
+	return handlerEvent{
+		body:      []byte(sampleMessageEvent),
+		eventKey:  "im.message.receive_v1",
+		eventID:   "ev_1",
+		eventType: "im.message.receive_v1",
+		chatID:    "oc_a",
+	}
+}
+
+func TestValidateHandlerOptions(t *testing.T) {
+	valid := []HandlerOptions{
+		{},
+		{ExecCmd: "cat"},
+		{ForwardURL: "http://127.0.0.1:8080/hook", DeadLetterDir: "./dead"},
+	}
+	for _, o := range valid {
+		if err := ValidateHandlerOptions(o); err != nil {
+			t.Errorf("ValidateHandlerOptions(%+v) unexpected error: %v", o, err)
+		}
+	}
+	invalid := []HandlerOptions{
+		{ForwardURL: "127.0.0.1:8080"},
+		{ForwardURL: "ftp://host/x"},
+		{ExecCmd: "cat", Retries: -1},
+		{ExecCmd: "cat", DeadLetterDir: "../dead"},
+		{ExecCmd: "cat", DeadLetterDir: "/tmp/dead"},
+		{DeadLetterDir: "./dead"},
+	}
+	for _, o := range invalid {
+		if err := ValidateHandlerOptions(o); err == nil {
+			t.Errorf("ValidateHandlerOptions(%+v) expected error", o)
+		}
+	}
+}
+
+func TestHandlerExecReceivesStdinAndEnv(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("依赖 sh")
+	}
+	out := filepath.Join(t.TempDir(), "out.txt")
+	d := newHandlerDispatcher(HandlerOptions{
+		ExecCmd: `{ echo "$FEISHU_EVENT_KEY $FEISHU_EVENT_TYPE $FEISHU_EVENT_ID $FEISHU_EVENT_CHAT_ID"; cat; } > ` + out,
+	}, io.Discard)
+	d.dispatch(context.Background(), sampleHandlerEvent())
+	d.wait()
+
+	if ok, failed := d.counts(); ok != 1 || failed != 0 {
+		t.Fatalf("counts = %d/%d, want 1/0", ok, failed)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "im.message.receive_v1 im.message.receive_v1 ev_1 oc_a\n" + sampleMessageEvent
+	if string(data) != want {
+		t.Errorf("exec 输出不符:\n got %q\nwant %q", data, want)
+	}
+}
+
+func TestHandlerForwardPostsEvent(t *testing.T) {
+	var gotBody []byte
+	var gotType, gotID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s", r.Method)
+		}
+		gotBody, _ = io.ReadAll(r.Body)
+		gotType = r.Header.Get("X-Feishu-Event-Type")
+		gotID = r.Header.Get("X-Feishu-Event-Id")
+	}))
+	defer srv.Close()
+
+	d := newHandlerDispatcher(HandlerOptions{ForwardURL: srv.URL}, io.Discard)
+	d.dispatch(context.Background(), sampleHandlerEvent())
+	d.wait()
+
+	if ok, _ := d.counts(); ok != 1 {
+		t.Fatalf("forward 应成功")
+	}
+	if string(gotBody) != sampleMessageEvent || gotType != "im.message.receive_v1" || gotID != "ev_1" {
+		t.Errorf("转发内容不符: type=%s id=%s body=%s", gotType, gotID, gotBody)
+	}
+}
+
+func TestHandlerRetryThenDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	d := newHandlerDispatcher(HandlerOptions{
+		ForwardURL:    srv.URL,
+		Retries:       2,
+		Backoff:       time.Millisecond,
+		DeadLetterDir: dir,
+	}, io.Discard)
+	d.dispatch(context.Background(), sampleHandlerEvent())
+	d.wait()
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("应首次 + 重试 2 次共 3 次调用，实际 %d", n)
+	}
+	if _, failed := d.counts(); failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ev_1.forward.json"))
+	if err != nil {
+		t.Fatalf("dead-letter 文件缺失: %v", err)
+	}
+	var rec deadLetterRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Attempts != 3 || rec.Handler != "forward" || !strings.Contains(rec.Error, "HTTP 500") {
+		t.Errorf("dead-letter 记录不符: %+v", rec)
+	}
+	if !json.Valid(rec.Event) || !strings.Contains(string(rec.Event), "om_x") {
+		t.Errorf("dead-letter 应保留原始事件 JSON: %s", rec.Event)
+	}
+}
+
+func TestHandlerExecTimeout(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("依赖 sh")
+	}
+	d := newHandlerDispatcher(HandlerOptions{
+		ExecCmd: "sleep 5",
+		Timeout: 50 * time.Millisecond,
+	}, io.Discard)
+	start := time.Now()
+	d.dispatch(context.Background(), sampleHandlerEvent())
+	d.wait()
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("超时未生效，耗时 %s", elapsed)
+	}
+	if _, failed := d.counts(); failed != 1 {
+		t.Errorf("超时应计为失败")
+	}
+}
+
+func TestHandlerStopsRetryingOnShutdown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := newHandlerDispatcher(HandlerOptions{
+		ForwardURL: srv.URL,
+		Retries:    5,
+		Backoff:    time.Hour,
+	}, io.Discard)
+	d.dispatch(ctx, sampleHandlerEvent())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("consume 退出后不应继续等待重试退避")
+	}
+	if _, failed := d.counts(); failed != 1 {
+		t.Errorf("放弃重试的事件应计为失败")
+	}
+}
@@ -2,18 +2,23 @@
 //
 // 设计要点：
 //   - 静态 EventKey 目录（KeyDefinition）：覆盖 IM/Contact/Calendar/Drive/Approval 等常用事件
-//   - 进程模型：每个 consume 命令 = 一个独立 OS 进程 + 一个 WebSocket 长连接（一个 EventKey）
-//   - 状态文件：~/.feishu-cli/events/<app_id>/bus.json 记录所有 active 进程（PID + EventKey + 启动时间）
+//   - 进程模型：每个 consume 命令 = 一个独立 OS 进程 + 一个 WebSocket 长连接（一个或多个 EventKey）
+//   - 状态文件：~/.feishu-cli/events/<app_id>/bus.json 记录所有 active 进程（PID + EventKey 列表 + 启动时间）
 //   - 文件锁：bus.json 读写走 flock，避免多进程同时写入损坏
 //   - 进程探活：status / stop 通过 PID 信号 0 检测进程是否存活
 //
 // 设计取舍：
 //   - 不跑独立 bus 守护进程做事件 fan-out；feishu-cli 简化为
-//     每个 consume 直接连 WebSocket（一个 EventKey 一个连接），不做事件分发——足够覆盖
-//     AI Agent 单 EventKey 订阅的主线场景
+//     每个 consume 直接连 WebSocket，同一进程内的多个 EventKey 共用这条连接，
+//     不跨进程做事件分发
 //   - 重连策略复用 oapi-sdk-go v3 ws.Client.WithAutoReconnect（默认开启，无限重试）
 package event
 
+import (
+	"fmt"
+	"strings"
+)
+
 // KeyDefinition 描述一个可订阅的 EventKey。
 //
 // 字段说明：
@@ -367,3 +372,65 @@ func Domains() []string {
 	}
 	return out
 }
+
+// ResolveKeys 把 consume 的 key 参数展开为去重后的 KeyDefinition 列表（保持首次出现顺序）。
+//
+// 每个参数可以是：
+//   - 精确 EventKey：im.message.receive_v1
+//   - Domain 通配：im.*（该 Domain 下全部 key，Domain 取自 Domains()）
+//   - 前缀通配：im.message.*（Domain 为 im 且 key 以 im.message. 开头）
+//
+// 未知 key、未知 Domain、通配不命中任何 key 都报错，避免静默少订阅。
+func ResolveKeys(patterns []string) ([]KeyDefinition, error) {
+	var out []KeyDefinition
+	seen := map[string]bool{}
+	add := func(def KeyDefinition) {
+		if !seen[def.Key] {
+			seen[def.Key] = true
+			out = append(out, def)
+		}
+	}
+	for _, p := range patterns {
+		if !strings.HasSuffix(p, ".*") {
+			def, ok := Lookup(p)
+			if !ok {
+				return nil, fmt.Errorf("未知 EventKey: %q（运行 `feishu-cli event list` 查看支持的 key）", p)
+			}
+			add(def)
+			continue
+		}
+		prefix := strings.TrimSuffix(p, "*")
+		domain := strings.SplitN(prefix, ".", 2)[0]
+		if !containsString(Domains(), domain) {
+			return nil, fmt.Errorf("未知 Domain: %q（可用: %s）", domain, strings.Join(Domains(), ", "))
+		}
+		matched := false
+		for _, def := range keyRegistry {
+			if def.Domain != domain {
+				continue
+			}
+			// 仅 Domain 通配（im.*）时不要求 key 前缀：Domain 下可能有非同名前缀的 key（如 card.action.trigger）
+			if prefix != domain+"." && !strings.HasPrefix(def.Key, prefix) {
+				continue
+			}
+			add(def)
+			matched = true
+		}
+		if !matched {
+			return nil, fmt.Errorf("通配 %q 未匹配任何 EventKey（运行 `feishu-cli event list` 查看支持的 key）", p)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("至少指定一个 EventKey")
+	}
+	return out, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -113,17 +113,21 @@ func TestIMKeyDefinitionsIncludeOfficialMetadata(t *testing.T) {
 	}
 }
 
-func TestValidateDotPathExpr(t *testing.T) {
-	valid := []string{"", ".", ".event", ".event.message", ".event.message_id", ".event.message-type"}
+func TestValidateJQExpr(t *testing.T) {
+	valid := []string{"", ".", ".event", ".event.message", ".event[0]", ".event | .header",
+		`select(.event.message.chat_type == "group") | {id: .header.event_id}`}
 	for _, expr := range valid {
-		if err := ValidateDotPathExpr(expr); err != nil {
-			t.Errorf("ValidateDotPathExpr(%q) unexpected error: %v", expr, err)
+		if err := ValidateJQExpr("jq", expr); err != nil {
+			t.Errorf("ValidateJQExpr(%q) unexpected error: %v", expr, err)
 		}
 	}
-	invalid := []string{"event", ".event[0]", ".event | .header", ".event..message", ".event.message."}
+	invalid := []string{".event.message.", ".event | (", "select(", "{a:}"}
 	for _, expr := range invalid {
-		if err := ValidateDotPathExpr(expr); err == nil {
-			t.Errorf("ValidateDotPathExpr(%q) expected error", expr)
+		err := ValidateJQExpr("filter", expr)
+		if err == nil {
+			t.Errorf("ValidateJQExpr(%q) expected error", expr)
+		} else if !strings.Contains(err.Error(), "--filter") {
+			t.Errorf("ValidateJQExpr(%q) error should name the flag: %v", expr, err)
 		}
 	}
 }
@@ -143,11 +147,36 @@ func TestValidateOutputDir(t *testing.T) {
 	}
 }
 
-func containsString(items []string, want string) bool {
-	for _, item := range items {
-		if item == want {
-			return true
+func TestResolveKeys(t *testing.T) {
+	keysOf := func(defs []KeyDefinition) []string {
+		var out []string
+		for _, d := range defs {
+			out = append(out, d.Key)
+		}
+		return out
+	}
+
+	defs, err := ResolveKeys([]string{"im.message.receive_v1", "im.message.reaction.*", "im.message.receive_v1"})
+	if err != nil {
+		t.Fatalf("ResolveKeys: %v", err)
+	}
+	want := []string{"im.message.receive_v1", "im.message.reaction.created_v1", "im.message.reaction.deleted_v1"}
+	if got := keysOf(defs); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ResolveKeys = %v, want %v（应去重并保持顺序）", got, want)
+	}
+
+	// Domain 通配包含该 Domain 下非同名前缀的 key
+	defs, err = ResolveKeys([]string{"im.*"})
+	if err != nil {
+		t.Fatalf("ResolveKeys(im.*): %v", err)
+	}
+	if got := keysOf(defs); !containsString(got, "card.action.trigger") || containsString(got, "contact.user.created_v3") {
+		t.Errorf("im.* 展开不符: %v", got)
+	}
+
+	for _, bad := range [][]string{{"im.nope_v1"}, {"nosuch.*"}, {"im.nope.*"}, {}} {
+		if _, err := ResolveKeys(bad); err == nil {
+			t.Errorf("ResolveKeys(%v) expected error", bad)
 		}
 	}
-	return false
 }
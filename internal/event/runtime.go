@@ -19,22 +19,32 @@ import (
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkws "github.com/larksuite/oapi-sdk-go/v3/ws"
+	"github.com/riba2534/feishu-cli/internal/output"
 )
 
 // ConsumeOptions 控制 consume 行为。
 type ConsumeOptions struct {
 	AppID     string
 	AppSecret string
-	EventKey  string
-	BaseURL   string // 飞书 API 域名（默认 https://open.feishu.cn）
+	EventKeys []string // 共用一条 WebSocket 连接订阅的 EventKey（已由 ResolveKeys 展开通配）
+	BaseURL   string   // 飞书 API 域名（默认 https://open.feishu.cn）
 
 	// 输出控制
 	Out    io.Writer // 事件 NDJSON 写到这里（通常是 stdout）
 	ErrOut io.Writer // 诊断日志写到这里（通常是 stderr）
 
-	// 业务过滤
-	JQExpr    string // 暂未实现完整 jq，留作未来扩展（v3.5.3 SDK 不带 jq 库）
-	OutputDir string // 非空时把每条事件 dump 为 <event_id>.json 文件
+	// 业务过滤（均为完整 jq 语法，由 internal/output 内置的 gojq 求值）
+	FilterExpr string // 谓词：结果为 null/false/无输出的事件直接丢弃，不计入 MaxEvents
+	JQExpr     string // 变换：每个非 null 输出写一行 NDJSON；无输出时该事件不计数
+	OutputDir  string // 非空时把每条事件 dump 为 <event_id>.json 文件
+
+	// 处理器模式：通过过滤的事件额外交给 --exec 命令 / --forward-url（见 handler.go）
+	Handlers HandlerOptions
+
+	// 去重：按 event_id 持久化到 AppDir/dedup/，重连重推 / 进程重启后同一事件只处理一次（见 dedup.go）
+	Dedup           bool
+	DedupTTL        time.Duration // <=0 用 DefaultDedupTTL
+	DedupMaxEntries int           // <=0 用 DefaultDedupMaxEntries
 
 	// 退出条件（whichever fires first）
 	MaxEvents int           // 0 = 不限制
@@ -53,6 +63,19 @@ type ConsumeOptions struct {
 type Runtime struct {
 	opts ConsumeOptions
 
+	filter *output.JQProgram // FilterExpr 编译结果，nil = 不过滤
+	jq     *output.JQProgram // JQExpr 编译结果，nil = 原样输出
+
+	tagKeys bool // 多 EventKey 会话时为 true：每条事件头部插入 "event_key" 字段
+
+	handlers   *handlerDispatcher // Handlers 未配置时为 nil
+	handlerCtx context.Context    // 派发/重试退避跟随 Run 的 subCtx，退出后不再派发新事件
+
+	dedup           map[string]*DedupStore // 按 EventKey 分文件；Dedup=false 时为 nil
+	dedupSeen       atomic.Int64           // 经过去重检查的事件数（有 event_id 的）
+	dedupDuplicates atomic.Int64           // 其中判定为重复而丢弃的
+
+	dropped  atomic.Int64       // 被 FilterExpr/JQExpr 丢弃的事件计数
 	received atomic.Int64       // 已发出的事件计数（受 MaxEvents 约束）
 	stopOnce atomic.Bool        // 多触发源（signal/timeout/maxEvents）下保证 cancel 只触发一次
 	cancel   context.CancelFunc // emit 触发 max-events 退出时调用，由 Run 在派生 subCtx 后注入
@@ -84,22 +107,47 @@ func NewRuntime(opts ConsumeOptions) *Runtime {
 //
 // 退出码 0 表示正常完成；非 0 表示 startup 失败或不可恢复错误。
 func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
-	def, ok := Lookup(r.opts.EventKey)
-	if !ok {
-		return "error", fmt.Errorf("未知 EventKey: %q（运行 `feishu-cli event list` 查看支持的 key）", r.opts.EventKey)
+	defs, err := ResolveKeys(r.opts.EventKeys)
+	if err != nil {
+		return "error", err
 	}
-	if err := ValidateDotPathExpr(r.opts.JQExpr); err != nil {
+	// 多 key 会话给每行输出打上 event_key 标签；label 用于 bus.json / ready marker / 日志
+	r.tagKeys = len(defs) > 1
+	label := r.label()
+	if err := r.compileFilters(); err != nil {
 		return "error", err
 	}
 	if err := ValidateOutputDir(r.opts.OutputDir); err != nil {
 		return "error", err
 	}
+	if err := ValidateHandlerOptions(r.opts.Handlers); err != nil {
+		return "error", err
+	}
 
 	// 需要服务端订阅注册的 EventKey（如审批 v4）：连 WS 前先以 User 身份注册订阅关系，
 	// 否则连上也收不到事件。订阅是持久用户级关系，进程退出不注销。
-	if def.SubscribePath != "" {
-		if err := r.registerSubscriptions(ctx, def); err != nil {
-			return "error", err
+	for _, def := range defs {
+		if def.SubscribePath != "" {
+			if err := r.registerSubscriptions(ctx, def); err != nil {
+				return "error", err
+			}
+		}
+	}
+
+	if r.opts.Dedup {
+		r.dedup = make(map[string]*DedupStore, len(defs))
+		for _, def := range defs {
+			path, err := DedupPath(r.opts.AppID, def.Key)
+			if err != nil {
+				return "error", err
+			}
+			store, err := OpenDedupStore(path, r.opts.DedupTTL, r.opts.DedupMaxEntries)
+			if err != nil {
+				return "error", err
+			}
+			defer store.Close()
+			r.dedup[def.Key] = store
+			fmt.Fprintf(r.opts.ErrOut, "[event] 去重已启用: %s（已记录 %d 个 event_id）\n", path, store.Len())
 		}
 	}
 
@@ -107,18 +155,27 @@ func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
 	if r.opts.Bus != nil {
 		entry := ConsumerEntry{
 			PID:        os.Getpid(),
-			EventKey:   r.opts.EventKey,
+			EventKey:   label,
 			StartedAt:  time.Now(),
 			OutputDir:  r.opts.OutputDir,
+			FilterExpr: r.opts.FilterExpr,
 			JQExpr:     r.opts.JQExpr,
+			ExecCmd:    r.opts.Handlers.ExecCmd,
+			ForwardURL: r.opts.Handlers.ForwardURL,
 			MaxEvents:  r.opts.MaxEvents,
 			TimeoutSec: int(r.opts.Timeout.Seconds()),
+			Dedup:      r.opts.Dedup,
+		}
+		if len(defs) > 1 {
+			for _, def := range defs {
+				entry.EventKeys = append(entry.EventKeys, def.Key)
+			}
 		}
 		if err := r.opts.Bus.Register(entry); err != nil {
 			fmt.Fprintf(r.opts.ErrOut, "[event] 警告: 注册到 bus.json 失败: %v\n", err)
 		}
 		defer func() {
-			_ = r.opts.Bus.Unregister(os.Getpid(), r.opts.EventKey)
+			_ = r.opts.Bus.Unregister(os.Getpid(), label)
 		}()
 	}
 
@@ -129,10 +186,28 @@ func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
 		}
 	}
 
+	// 处理器：先于 subCtx 的 cancel 注册 wait，defer 逆序执行保证先 cancel（停止重试退避）
+	// 再等在途处理器跑完，已派发的事件不会因进程退出被截断。
+	if r.opts.Handlers.Enabled() {
+		if dir := r.opts.Handlers.DeadLetterDir; dir != "" {
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return "error", fmt.Errorf("创建 dead-letter 目录失败: %w", err)
+			}
+		}
+		r.handlers = newHandlerDispatcher(r.opts.Handlers, r.opts.ErrOut)
+		defer r.handlers.wait()
+	}
+
 	// 派生子上下文以便多触发源 cancel
 	subCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	r.cancel = cancel // emit 在 max-events 触发时通过 r.cancel 退出
+	r.handlerCtx = subCtx
+
+	// 去重统计定期刷到 bus.json 供 event status 展示（逐条写会让 flock 成为热点）
+	if r.dedup != nil && r.opts.Bus != nil {
+		go r.syncDedupStats(subCtx)
+	}
 
 	// 超时
 	if r.opts.Timeout > 0 {
@@ -148,21 +223,24 @@ func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
 		}()
 	}
 
-	// 构造 dispatcher：卡片回调走 callback 分发通道（与普通事件是不同的 WS 帧类型），
-	// 其余走 OnCustomizedEvent 原样透传。
+	// 构造 dispatcher：所有 key 注册到同一个 dispatcher，共用一条 WS 连接。
+	// 卡片回调走 callback 分发通道（与普通事件是不同的 WS 帧类型），其余走 OnCustomizedEvent 原样透传。
 	dis := dispatcher.NewEventDispatcher("", "")
-	if def.CardCallback {
-		dis.OnP2CardActionTrigger(func(ctx context.Context, ev *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
-			if ev != nil && ev.EventReq != nil {
-				_ = r.emit(ev.EventReq)
-			}
-			// 返回空响应 = ACK 且不更新卡片；卡片回写由消费方用 event.token 调 OpenAPI 完成
-			return &callback.CardActionTriggerResponse{}, nil
-		})
-	} else {
-		dis.OnCustomizedEvent(def.EventType, func(ctx context.Context, ev *larkevent.EventReq) error {
-			return r.emit(ev)
-		})
+	for _, def := range defs {
+		key := def.Key
+		if def.CardCallback {
+			dis.OnP2CardActionTrigger(func(ctx context.Context, ev *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
+				if ev != nil && ev.EventReq != nil {
+					_ = r.emit(key, ev.EventReq)
+				}
+				// 返回空响应 = ACK 且不更新卡片；卡片回写由消费方用 event.token 调 OpenAPI 完成
+				return &callback.CardActionTriggerResponse{}, nil
+			})
+		} else {
+			dis.OnCustomizedEvent(def.EventType, func(ctx context.Context, ev *larkevent.EventReq) error {
+				return r.emit(key, ev)
+			})
+		}
 	}
 
 	// 安装 panic recover 包装的 logger，避免 SDK 日志炸 stderr
@@ -180,7 +258,7 @@ func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
 	//   导致 orchestrator 父进程永远等不到 marker。
 	// ★ 语义提示：父进程看到 marker 后**还需额外等 1-3s 让 WS 握手完成**才能可靠收到事件；
 	//   生产环境推荐父进程发"自检事件"+ 等待 echo 来确认链路通。
-	fmt.Fprintf(os.Stderr, "[event] ready event_key=%s (init complete; WS handshake in progress)\n", r.opts.EventKey)
+	fmt.Fprintf(os.Stderr, "[event] ready event_key=%s (init complete; WS handshake in progress)\n", label)
 
 	// ws.Client.Start 阻塞，需要外部 cancel；包一层 goroutine 让 ctx 控制退出
 	errCh := make(chan error, 1)
@@ -209,6 +287,50 @@ func (r *Runtime) Run(ctx context.Context) (reason string, err error) {
 	}
 }
 
+// Counts 返回已输出与被 --filter/--jq 丢弃的事件数（Run 结束后读取用于退出摘要）。
+func (r *Runtime) Counts() (emitted, dropped int64) {
+	return r.received.Load(), r.dropped.Load()
+}
+
+// HandlerCounts 返回处理器成功 / 重试耗尽失败的次数；未配置处理器时均为 0。
+func (r *Runtime) HandlerCounts() (succeeded, failed int64) {
+	if r.handlers == nil {
+		return 0, 0
+	}
+	return r.handlers.counts()
+}
+
+// DedupCounts 返回经过去重检查的事件数与其中被判定为重复丢弃的数量；未启用去重时均为 0。
+func (r *Runtime) DedupCounts() (seen, duplicates int64) {
+	return r.dedupSeen.Load(), r.dedupDuplicates.Load()
+}
+
+// dedupStatsInterval 去重统计写回 bus.json 的间隔。
+const dedupStatsInterval = 5 * time.Second
+
+// syncDedupStats 在计数变化时把去重统计写回 bus.json，直到 ctx 取消。
+func (r *Runtime) syncDedupStats(ctx context.Context) {
+	ticker := time.NewTicker(dedupStatsInterval)
+	defer ticker.Stop()
+	var lastSeen int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			seen, dup := r.DedupCounts()
+			if seen == lastSeen {
+				continue
+			}
+			if err := r.opts.Bus.UpdateDedupStats(os.Getpid(), r.label(), seen, dup); err != nil {
+				fmt.Fprintf(r.opts.ErrOut, "[event] 警告: 更新 bus.json 去重统计失败: %v\n", err)
+				continue
+			}
+			lastSeen = seen
+		}
+	}
+}
+
 // subscribeHTTPTimeout 订阅注册请求的超时上限：注册是 consume 启动的前置步骤，
 // 不能因端点挂起阻塞整个启动流程。
 const subscribeHTTPTimeout = 15 * time.Second
@@ -290,43 +412,57 @@ func (r *Runtime) getReason() string {
 	return r.reason
 }
 
-// emit 把一条事件输出到 stdout（NDJSON）+ 可选 output-dir 文件，并维护计数。
-func (r *Runtime) emit(ev *larkevent.EventReq) error {
-	// 解析事件以提取 event_id（用于文件名）；失败也不阻塞输出。
+// emit 把一条 key 事件输出到 stdout（NDJSON）+ 可选 output-dir 文件 + 可选处理器，并维护计数。
+func (r *Runtime) emit(key string, ev *larkevent.EventReq) error {
+	// 解析事件以提取 event_id（用于文件名）与处理器元信息；失败也不阻塞输出。
 	body := ev.Body
 	var meta struct {
 		Header struct {
 			EventID   string `json:"event_id"`
 			EventType string `json:"event_type"`
 		} `json:"header"`
+		Event struct {
+			Message struct {
+				ChatID string `json:"chat_id"`
+			} `json:"message"`
+			Context struct {
+				OpenChatID string `json:"open_chat_id"` // 卡片回调的会话 ID
+			} `json:"context"`
+		} `json:"event"`
 	}
 	_ = json.Unmarshal(body, &meta)
 
-	// 简单 jq 支持：仅支持 `.event.xxx` / `.header.xxx` 这种点路径（避免引入 itchyny/gojq 依赖）
-	output := body
-	if r.opts.JQExpr != "" {
-		filtered, ok := applyDotPath(body, r.opts.JQExpr)
-		if !ok {
-			// jq 不匹配则 skip 该事件
+	// 去重先于过滤：重复事件既不输出也不计入 dropped / MaxEvents
+	if store := r.dedup[key]; store != nil && meta.Header.EventID != "" {
+		r.dedupSeen.Add(1)
+		dup, err := store.Seen(meta.Header.EventID)
+		if err != nil {
+			fmt.Fprintf(r.opts.ErrOut, "[event] 警告: %v\n", err)
+		}
+		if dup {
+			r.dedupDuplicates.Add(1)
+			fmt.Fprintf(r.opts.ErrOut, "[event] 重复事件已丢弃 event_id=%s\n", meta.Header.EventID)
 			return nil
 		}
-		output = filtered
 	}
 
-	// 写 stdout（NDJSON）：每条事件一行 + \n
-	// 注意：原始 body 已是 JSON，保持紧凑序列化（不 indent）
-	var line []byte
-	if isCompactJSON(output) {
-		line = output
-	} else {
-		var v interface{}
-		if err := json.Unmarshal(output, &v); err == nil {
-			line, _ = json.Marshal(v)
-		} else {
-			line = output
-		}
+	// 多 key 会话：先打标签再过滤，--filter / --jq 里可以直接用 .event_key 分流
+	if r.tagKeys {
+		body = tagEventKey(body, key)
+	}
+
+	lines, ok := r.render(body, meta.Header.EventID)
+	if !ok {
+		r.dropped.Add(1)
+		return nil
+	}
+
+	// 写 stdout（NDJSON）：每条输出一行 + \n
+	var buf []byte
+	for _, line := range lines {
+		buf = append(append(buf, line...), '\n')
 	}
-	if _, err := r.opts.Out.Write(append(line, '\n')); err != nil {
+	if _, err := r.opts.Out.Write(buf); err != nil {
 		// stdout 关闭（下游 pipe broken）= 立刻退出。
 		// ★ 必须主动 cancel，否则 Run 卡在 select{<-subCtx.Done()}
 		//   直到外部 Ctrl-C；这是 fix 引入 stopOnce 控制 cancel 后的对称要求。
@@ -350,6 +486,21 @@ func (r *Runtime) emit(ev *larkevent.EventReq) error {
 		}
 	}
 
+	// 处理器（可选）：交给处理器的始终是原始事件 JSON（多 key 时带 event_key 标签），--jq 只影响 stdout
+	if r.handlers != nil {
+		chatID := meta.Event.Message.ChatID
+		if chatID == "" {
+			chatID = meta.Event.Context.OpenChatID
+		}
+		r.handlers.dispatch(r.handlerCtx, handlerEvent{
+			body:      body,
+			eventKey:  key,
+			eventID:   meta.Header.EventID,
+			eventType: meta.Header.EventType,
+			chatID:    chatID,
+		})
+	}
+
 	// 计数 + 触发 max-events 退出
 	n := r.received.Add(1)
 	if r.opts.MaxEvents > 0 && n >= int64(r.opts.MaxEvents) {
@@ -365,31 +516,13 @@ func (r *Runtime) emit(ev *larkevent.EventReq) error {
 	return nil
 }
 
-// ValidateDotPathExpr 校验 consume --jq 的极简表达式。
-// 当前只支持空值、"." 或 ".a.b.c" 这种 map 点路径，复杂过滤必须交给外部 jq。
-func ValidateDotPathExpr(expr string) error {
-	expr = strings.TrimSpace(expr)
-	if expr == "" || expr == "." {
+// ValidateJQExpr 校验 consume --jq / --filter 的 jq 表达式能否编译；空表达式合法。
+func ValidateJQExpr(flag, expr string) error {
+	if strings.TrimSpace(expr) == "" {
 		return nil
 	}
-	if !strings.HasPrefix(expr, ".") {
-		return fmt.Errorf("--jq 仅支持 .a.b.c 点路径表达式，复杂过滤请用管道接外部 jq")
-	}
-	segments := strings.Split(strings.TrimPrefix(expr, "."), ".")
-	for _, seg := range segments {
-		if seg == "" {
-			return fmt.Errorf("--jq 仅支持 .a.b.c 点路径表达式，路径段不能为空")
-		}
-		for _, r := range seg {
-			switch {
-			case r >= 'A' && r <= 'Z':
-			case r >= 'a' && r <= 'z':
-			case r >= '0' && r <= '9':
-			case r == '_' || r == '-':
-			default:
-				return fmt.Errorf("--jq 仅支持 .a.b.c 点路径表达式，不支持过滤器、管道或数组下标")
-			}
-		}
+	if _, err := output.CompileJQ(expr); err != nil {
+		return fmt.Errorf("--%s: %w", flag, err)
 	}
 	return nil
 }
@@ -397,60 +530,123 @@ func ValidateDotPathExpr(expr string) error {
 // ValidateOutputDir 校验 consume --output-dir 的路径边界。
 // 为避免把事件写出项目/工作目录，只允许安全相对路径，不展开 ~，不接受绝对路径或 ..。
 func ValidateOutputDir(dir string) error {
+	return validateSafeRelDir("output-dir", dir)
+}
+
+// validateSafeRelDir 是 --output-dir / --dead-letter-dir 共用的安全相对路径校验。
+func validateSafeRelDir(flag, dir string) error {
 	dir = strings.TrimSpace(dir)
 	if dir == "" {
 		return nil
 	}
 	if strings.HasPrefix(dir, "~") {
-		return fmt.Errorf("--output-dir 不支持 ~ 展开，请用安全相对路径如 ./events")
+		return fmt.Errorf("--%s 不支持 ~ 展开，请用安全相对路径如 ./events", flag)
 	}
 	if filepath.IsAbs(dir) || filepath.VolumeName(dir) != "" {
-		return fmt.Errorf("--output-dir 只支持安全相对路径，不支持绝对路径")
+		return fmt.Errorf("--%s 只支持安全相对路径，不支持绝对路径", flag)
 	}
 	for _, seg := range strings.FieldsFunc(dir, func(r rune) bool {
 		return r == '/' || r == '\\'
 	}) {
 		if seg == ".." {
-			return fmt.Errorf("--output-dir 不能包含 .. 路径段")
+			return fmt.Errorf("--%s 不能包含 .. 路径段", flag)
 		}
 	}
 	return nil
 }
 
-// applyDotPath 实现极简 jq：仅支持 `.a.b.c` 形式（不支持过滤器/管道/数组下标）。
-// 返回 (子树 JSON, 是否命中)。
-func applyDotPath(data []byte, expr string) ([]byte, bool) {
-	expr = strings.TrimSpace(expr)
-	if expr == "." || expr == "" {
-		return data, true
-	}
-	if err := ValidateDotPathExpr(expr); err != nil {
-		return nil, false
-	}
-	var v interface{}
-	if err := json.Unmarshal(data, &v); err != nil {
-		return nil, false
+// label 返回会话的 EventKey 展示名：单 key 即 key 本身，多 key 用逗号拼接。
+// 同时作为 bus.json 条目的 EventKey 字段，供 Unregister / UpdateDedupStats 定位。
+func (r *Runtime) label() string {
+	return strings.Join(r.opts.EventKeys, ",")
+}
+
+// tagEventKey 在事件 JSON 对象头部插入 "event_key" 字段；body 不是对象时原样返回。
+// 直接拼接字节而非 Unmarshal/Marshal，避免改写原始 payload 的字段顺序与数字精度。
+func tagEventKey(body []byte, key string) []byte {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return body
+	}
+	rest := bytes.TrimLeft(trimmed[1:], " \t\r\n")
+	k, _ := json.Marshal(key)
+	out := make([]byte, 0, len(body)+len(k)+16)
+	out = append(out, `{"event_key":`...)
+	out = append(out, k...)
+	if len(rest) > 0 && rest[0] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, rest...)
+}
+
+// compileFilters 编译 FilterExpr / JQExpr；"." 等价于不变换，不编译以保持原始 body 输出。
+func (r *Runtime) compileFilters() error {
+	if expr := strings.TrimSpace(r.opts.FilterExpr); expr != "" {
+		prog, err := output.CompileJQ(expr)
+		if err != nil {
+			return fmt.Errorf("--filter: %w", err)
+		}
+		r.filter = prog
 	}
-	cur := v
-	for _, seg := range strings.Split(strings.TrimPrefix(expr, "."), ".") {
-		if seg == "" {
-			continue
+	if expr := strings.TrimSpace(r.opts.JQExpr); expr != "" && expr != "." {
+		prog, err := output.CompileJQ(expr)
+		if err != nil {
+			return fmt.Errorf("--jq: %w", err)
 		}
-		m, ok := cur.(map[string]interface{})
-		if !ok {
+		r.jq = prog
+	}
+	return nil
+}
+
+// render 对一条事件依次应用 --filter 与 --jq，返回要写出的 NDJSON 行。
+// ok=false 表示事件被丢弃（谓词不成立、jq 无非 null 输出或求值出错），不计入 MaxEvents。
+// 求值错误只记诊断日志不中断 consume：单条异常 payload 不应拖垮整个订阅。
+func (r *Runtime) render(body []byte, eventID string) (lines [][]byte, ok bool) {
+	if r.filter != nil {
+		match, err := r.filter.Match(body)
+		if err != nil {
+			fmt.Fprintf(r.opts.ErrOut, "[event] --filter 求值失败，跳过事件 %s: %v\n", eventID, err)
 			return nil, false
 		}
-		next, exists := m[seg]
-		if !exists {
+		if !match {
 			return nil, false
 		}
-		cur = next
 	}
-	out, err := json.Marshal(cur)
+
+	if r.jq == nil {
+		return [][]byte{compactLine(body)}, true
+	}
+	results, err := r.jq.EvalJSON(body)
 	if err != nil {
+		fmt.Fprintf(r.opts.ErrOut, "[event] --jq 求值失败，跳过事件 %s: %v\n", eventID, err)
 		return nil, false
 	}
-	return out, true
+	for _, v := range results {
+		if v == nil {
+			continue
+		}
+		line, err := output.CompactJSON(v)
+		if err != nil {
+			fmt.Fprintf(r.opts.ErrOut, "[event] --jq 结果编码失败，跳过事件 %s: %v\n", eventID, err)
+			return nil, false
+		}
+		lines = append(lines, []byte(line))
+	}
+	return lines, len(lines) > 0
+}
+
+// compactLine 保证原始 body 以单行形式输出（SDK 推过来的 body 通常已是 compact JSON）。
+func compactLine(body []byte) []byte {
+	if isCompactJSON(body) {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		if line, err := json.Marshal(v); err == nil {
+			return line
+		}
+	}
+	return body
 }
 
 // isCompactJSON 粗略判断 b 是否已是 compact JSON（无换行）。SDK 推过来的 body 通常就是。
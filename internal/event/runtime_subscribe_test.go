@@ -22,7 +22,7 @@ func newSubscribeRuntime(baseURL string) *Runtime {
 	return NewRuntime(ConsumeOptions{
 		AppID:           "cli_test",
 		AppSecret:       "secret",
-		EventKey:        "approval.instance.status_changed_v4",
+		EventKeys:       []string{"approval.instance.status_changed_v4"},
 		BaseURL:         baseURL,
 		UserAccessToken: "u-test",
 		ErrOut:          io.Discard,
@@ -116,7 +116,7 @@ func TestRegisterSubscriptionsCtxCancel(t *testing.T) {
 }
 
 func TestRegisterSubscriptionsRequiresUserToken(t *testing.T) {
-	r := NewRuntime(ConsumeOptions{EventKey: "x", BaseURL: "http://127.0.0.1:1", ErrOut: io.Discard})
+	r := NewRuntime(ConsumeOptions{EventKeys: []string{"x"}, BaseURL: "http://127.0.0.1:1", ErrOut: io.Discard})
 	err := r.registerSubscriptions(context.Background(), subscribeTestDef(""))
 	if err == nil || !strings.Contains(err.Error(), "auth login") {
 		t.Fatalf("缺 User Token 应报错并提示登录，实际: %v", err)
@@ -1,56 +1,87 @@
 package event
 
 import (
+	"bytes"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	larkevent "github.com/larksuite/oapi-sdk-go/v3/event"
 )
 
-func TestApplyDotPath_Identity(t *testing.T) {
-	body := []byte(`{"a":1,"b":{"c":"hi"}}`)
-	got, ok := applyDotPath(body, ".")
-	if !ok {
-		t.Fatal(". 应总是命中")
+// newRenderRuntime 构造只用于 render 测试的 runtime（不连 WS）
+func newRenderRuntime(t *testing.T, filter, jq string) (*Runtime, *bytes.Buffer) {
+	t.Helper()
+	errOut := &bytes.Buffer{}
+	r := NewRuntime(ConsumeOptions{FilterExpr: filter, JQExpr: jq, ErrOut: errOut})
+	if err := r.compileFilters(); err != nil {
+		t.Fatalf("compileFilters: %v", err)
 	}
-	if string(got) != string(body) {
-		t.Errorf(". 应返回原始 body 完全相同")
+	return r, errOut
+}
+
+const sampleMessageEvent = `{"header":{"event_id":"ev_1","event_type":"im.message.receive_v1"},` +
+	`"event":{"message":{"chat_id":"oc_a","message_id":"om_x","content":"{\"text\":\"请部署 v2\"}"},` +
+	`"sender":{"sender_id":{"open_id":"ou_1"}},"seq":7030776512726958083}}`
+
+func TestRender_PassThrough(t *testing.T) {
+	r, _ := newRenderRuntime(t, "", ".")
+	body := []byte(sampleMessageEvent)
+	lines, ok := r.render(body, "ev_1")
+	if !ok || len(lines) != 1 || string(lines[0]) != sampleMessageEvent {
+		t.Fatalf("未配置过滤时应原样输出 body，got ok=%v lines=%q", ok, lines)
 	}
 }
 
-func TestApplyDotPath_Nested(t *testing.T) {
-	body := []byte(`{"header":{"event_id":"abc"},"event":{"message":{"message_id":"om_x"}}}`)
-	got, ok := applyDotPath(body, ".event.message")
-	if !ok {
-		t.Fatal(".event.message 应命中")
+func TestRender_Filter(t *testing.T) {
+	r, _ := newRenderRuntime(t, `.event.message.chat_id == "oc_a"`, "")
+	if _, ok := r.render([]byte(sampleMessageEvent), "ev_1"); !ok {
+		t.Fatal("chat_id 命中的事件应保留")
+	}
+	r, _ = newRenderRuntime(t, `.event.message.chat_id == "oc_b"`, "")
+	if _, ok := r.render([]byte(sampleMessageEvent), "ev_1"); ok {
+		t.Fatal("谓词为 false 的事件应丢弃")
 	}
-	if !strings.Contains(string(got), "om_x") {
-		t.Errorf("子树缺少 om_x: %s", got)
+	r, _ = newRenderRuntime(t, `.event.message.content | fromjson | .text | contains("部署")`, "")
+	if _, ok := r.render([]byte(sampleMessageEvent), "ev_1"); !ok {
+		t.Fatal("关键字谓词应命中")
 	}
 }
 
-func TestApplyDotPath_Miss(t *testing.T) {
-	body := []byte(`{"a":1}`)
-	_, ok := applyDotPath(body, ".no.such.path")
-	if ok {
-		t.Errorf("不存在的路径应返回 ok=false")
+func TestRender_JQProgram(t *testing.T) {
+	r, _ := newRenderRuntime(t, "", `{chat: .event.message.chat_id, seq: .event.seq}`)
+	lines, ok := r.render([]byte(sampleMessageEvent), "ev_1")
+	if !ok || len(lines) != 1 {
+		t.Fatalf("对象构造应输出一行，got ok=%v lines=%q", ok, lines)
+	}
+	// 19 位整数不能被 float64 截断
+	if string(lines[0]) != `{"chat":"oc_a","seq":7030776512726958083}` {
+		t.Errorf("输出不符: %s", lines[0])
+	}
+
+	r, _ = newRenderRuntime(t, "", `.header.event_id, .event.message.message_id`)
+	lines, ok = r.render([]byte(sampleMessageEvent), "ev_1")
+	if !ok || len(lines) != 2 || string(lines[0]) != `"ev_1"` || string(lines[1]) != `"om_x"` {
+		t.Fatalf("多输出应逐行写出，got %q", lines)
 	}
 }
 
-func TestApplyDotPath_RejectsNonDot(t *testing.T) {
-	body := []byte(`{"a":1}`)
-	_, ok := applyDotPath(body, "select(.a==1)")
-	if ok {
-		t.Errorf("非 . 开头的复杂 jq 表达式应直接拒绝（不假装支持）")
+func TestRender_JQNoOutputDrops(t *testing.T) {
+	for _, expr := range []string{`.no.such.path`, `select(.event.message.chat_id == "oc_b")`, `empty`} {
+		r, _ := newRenderRuntime(t, "", expr)
+		if lines, ok := r.render([]byte(sampleMessageEvent), "ev_1"); ok {
+			t.Errorf("%s 无非 null 输出时应丢弃事件，got %q", expr, lines)
+		}
 	}
 }
 
-func TestApplyDotPath_LeafScalar(t *testing.T) {
-	body := []byte(`{"header":{"event_id":"abc"}}`)
-	got, ok := applyDotPath(body, ".header.event_id")
-	if !ok {
-		t.Fatal("叶子标量应可访问")
+func TestRender_EvalErrorSkipsEvent(t *testing.T) {
+	r, errOut := newRenderRuntime(t, `.event.message.chat_id + 1`, "")
+	if _, ok := r.render([]byte(sampleMessageEvent), "ev_1"); ok {
+		t.Fatal("求值出错的事件应丢弃")
 	}
-	if string(got) != `"abc"` {
-		t.Errorf("期望字符串 \"abc\"，实际 %s", got)
+	if !strings.Contains(errOut.String(), "ev_1") {
+		t.Errorf("求值错误应记录到 ErrOut 并带 event_id，got %q", errOut.String())
 	}
 }
 
@@ -82,3 +113,54 @@ func TestIsContextCanceled(t *testing.T) {
 type errString string
 
 func (e errString) Error() string { return string(e) }
+
+func TestEmit_DedupDropsRedelivery(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := NewRuntime(ConsumeOptions{Out: out, ErrOut: &bytes.Buffer{}})
+	store, err := OpenDedupStore(filepath.Join(t.TempDir(), "k.ndjson"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	r.dedup = map[string]*DedupStore{"im.message.receive_v1": store}
+
+	for i := 0; i < 3; i++ {
+		if err := r.emit("im.message.receive_v1", &larkevent.EventReq{Body: []byte(sampleMessageEvent)}); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Errorf("重推的同一 event_id 只应输出 1 次，实际 %d 次", n)
+	}
+	seen, dups := r.DedupCounts()
+	emitted, dropped := r.Counts()
+	if seen != 3 || dups != 2 || emitted != 1 || dropped != 0 {
+		t.Errorf("计数不符: seen=%d dups=%d emitted=%d dropped=%d", seen, dups, emitted, dropped)
+	}
+}
+
+func TestTagEventKey(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`{"header":{"event_id":"e"}}`, `{"event_key":"k","header":{"event_id":"e"}}`},
+		{` { "a":1}`, `{"event_key":"k","a":1}`},
+		{`{}`, `{"event_key":"k"}`},
+		{`[1]`, `[1]`},
+	}
+	for _, tc := range cases {
+		if got := string(tagEventKey([]byte(tc.in), "k")); got != tc.want {
+			t.Errorf("tagEventKey(%s) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRender_FilterByTaggedKey(t *testing.T) {
+	r, _ := newRenderRuntime(t, `.event_key == "im.message.receive_v1"`, ".header.event_id")
+	body := tagEventKey([]byte(sampleMessageEvent), "im.message.receive_v1")
+	lines, ok := r.render(body, "ev_1")
+	if !ok || len(lines) != 1 || string(lines[0]) != `"ev_1"` {
+		t.Fatalf("多 key 会话应能按 .event_key 过滤，got ok=%v lines=%q", ok, lines)
+	}
+	if _, ok := r.render(tagEventKey([]byte(sampleMessageEvent), "card.action.trigger"), "ev_1"); ok {
+		t.Fatal("其他 key 的事件应被过滤")
+	}
+}
@@ -0,0 +1,6 @@
+// Package filelock 提供跨进程的文件互斥锁，供限流状态、secret 存储、事件总线与去重日志等
+// 多个 CLI 进程共享的本地文件使用。
+//
+// Unix 上对锁文件加 flock；Windows 上以创建 <path>.lockdir 目录作为锁（超时 5s，
+// 超过 1 分钟未释放视为持有进程已崩溃，强制清理）。
+package filelock
@@ -0,0 +1,40 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithSerializesReadModifyWrite(t *testing.T) {
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "counter")
+	lock := counter + ".lock"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := With(lock, func() error {
+				data, _ := os.ReadFile(counter)
+				n, _ := strconv.Atoi(string(data))
+				return os.WriteFile(counter, []byte(strconv.Itoa(n+1)), 0600)
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "20" {
+		t.Errorf("counter = %s, want 20", data)
+	}
+}
@@ -1,6 +1,6 @@
 //go:build !windows
 
-package event
+package filelock
 
 import (
 	"fmt"
@@ -8,15 +8,16 @@ import (
 	"syscall"
 )
 
-func withBusFileLock(path string, fn func() error) error {
+// With 在持有 path 上的独占锁期间执行 fn；锁文件不存在时自动创建。
+func With(path string, fn func() error) error {
 	lockFD, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
-		return fmt.Errorf("打开 bus.lock 失败: %w", err)
+		return fmt.Errorf("打开文件锁 %s 失败: %w", path, err)
 	}
 	defer lockFD.Close()
 
 	if err := syscall.Flock(int(lockFD.Fd()), syscall.LOCK_EX); err != nil {
-		return fmt.Errorf("获取 bus.lock 失败: %w", err)
+		return fmt.Errorf("获取文件锁 %s 失败: %w", path, err)
 	}
 	defer func() {
 		_ = syscall.Flock(int(lockFD.Fd()), syscall.LOCK_UN)
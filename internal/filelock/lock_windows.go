@@ -0,0 +1,49 @@
+//go:build windows
+
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	pollInterval = 20 * time.Millisecond
+	timeout      = 5 * time.Second
+	staleAfter   = time.Minute
+)
+
+// With 在持有 path 上的独占锁期间执行 fn；锁文件不存在时自动创建。
+func With(path string, fn func() error) error {
+	lockDir := path + ".lockdir"
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := os.Mkdir(lockDir, 0700)
+		if err == nil {
+			_ = touch(path)
+			defer os.Remove(lockDir)
+			return fn()
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("获取文件锁 %s 失败: %w", path, err)
+		}
+		if info, statErr := os.Stat(lockDir); statErr == nil && time.Since(info.ModTime()) > staleAfter {
+			_ = os.Remove(lockDir)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("获取文件锁 %s 超时", path)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func touch(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
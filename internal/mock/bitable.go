@@ -0,0 +1,426 @@
+package mock
+
+import (
+	"net/http"
+	"strings"
+)
+
+// base 是一个多维表格（base/v3 的 base_token 与 bitable/v1 的 app_token 共用同一份数据）。
+type base struct {
+	tables     map[string]*table
+	tableOrder []string
+}
+
+// table 是一张数据表，记录按创建顺序保存。
+type table struct {
+	id      string
+	name    string
+	records map[string]map[string]any // record_id → fields
+	order   []string
+	fields  []map[string]any // base/v3 字段定义，按创建顺序，首个为主字段
+	views   []*view
+}
+
+func (s *Server) bitableRoutes() []*route {
+	const v3 = "/open-apis/base/v3/bases/{app_token}/tables"
+	const v1 = "/open-apis/bitable/v1/apps/{app_token}/tables"
+	return []*route{
+		newRoute(http.MethodGet, v3, s.listTables),
+		newRoute(http.MethodPost, v3, s.createTable),
+		newRoute(http.MethodDelete, v3+"/{table_id}", s.deleteTable),
+		newRoute(http.MethodGet, v3+"/{table_id}/records", s.listRecords),
+		newRoute(http.MethodPost, v3+"/{table_id}/records", s.createRecordV3),
+		newRoute(http.MethodGet, v3+"/{table_id}/records/{record_id}", s.getRecordV3),
+		newRoute(http.MethodPatch, v3+"/{table_id}/records/{record_id}", s.updateRecordV3),
+		newRoute(http.MethodDelete, v3+"/{table_id}/records/{record_id}", s.deleteRecord),
+		newRoute(http.MethodPost, v3+"/{table_id}/records/search", s.searchRecords),
+		newRoute(http.MethodPost, v3+"/{table_id}/records/batch_create", s.batchCreateRecordsV3),
+		newRoute(http.MethodPost, v3+"/{table_id}/records/batch_update", s.batchUpdateRecordsV3),
+		newRoute(http.MethodPost, v3+"/{table_id}/records/batch_delete", s.batchDeleteRecords),
+		newRoute(http.MethodGet, v3+"/{table_id}/fields", s.listFields),
+		newRoute(http.MethodPost, v3+"/{table_id}/fields", s.createField),
+		newRoute(http.MethodPut, v3+"/{table_id}/fields/{field_id}", s.updateField),
+		newRoute(http.MethodDelete, v3+"/{table_id}/fields/{field_id}", s.deleteField),
+		newRoute(http.MethodGet, v3+"/{table_id}/views", s.listViews),
+		newRoute(http.MethodPost, v3+"/{table_id}/views", s.createView),
+		newRoute(http.MethodGet, v3+"/{table_id}/views/{view_id}", s.getView),
+		newRoute(http.MethodPatch, v3+"/{table_id}/views/{view_id}", s.renameView),
+		newRoute(http.MethodDelete, v3+"/{table_id}/views/{view_id}", s.deleteView),
+		newRoute(http.MethodGet, v3+"/{table_id}/views/{view_id}/{kind}", s.getViewConfig),
+		newRoute(http.MethodPut, v3+"/{table_id}/views/{view_id}/{kind}", s.setViewConfig),
+
+		newRoute(http.MethodGet, v1, s.listTables),
+		newRoute(http.MethodPost, v1, s.createTable),
+		newRoute(http.MethodGet, v1+"/{table_id}/records", s.listRecords),
+		newRoute(http.MethodPost, v1+"/{table_id}/records", s.createRecordV1),
+		newRoute(http.MethodGet, v1+"/{table_id}/records/{record_id}", s.getRecordV1),
+		newRoute(http.MethodPut, v1+"/{table_id}/records/{record_id}", s.updateRecordV1),
+		newRoute(http.MethodDelete, v1+"/{table_id}/records/{record_id}", s.deleteRecord),
+		newRoute(http.MethodPost, v1+"/{table_id}/records/search", s.searchRecords),
+		newRoute(http.MethodPost, v1+"/{table_id}/records/batch_create", s.batchCreateRecordsV1),
+		newRoute(http.MethodPost, v1+"/{table_id}/records/batch_update", s.batchUpdateRecordsV1),
+		newRoute(http.MethodPost, v1+"/{table_id}/records/batch_delete", s.batchDeleteRecords),
+	}
+}
+
+// base 返回（必要时创建）一个多维表格：mock 不要求先建 base，任意 token 首次访问即存在。
+func (s *Server) base(r *request) *base {
+	token := r.param("app_token")
+	b := s.state.bases[token]
+	if b == nil {
+		b = &base{tables: make(map[string]*table)}
+		s.state.bases[token] = b
+	}
+	return b
+}
+
+// table 返回（必要时创建）数据表，同 base 一样按需创建，脚本可直接往任意 table_id 写记录。
+func (s *Server) table(r *request) *table {
+	b := s.base(r)
+	id := r.param("table_id")
+	t := b.tables[id]
+	if t == nil {
+		t = &table{id: id, name: id, records: make(map[string]map[string]any)}
+		b.tables[id] = t
+		b.tableOrder = append(b.tableOrder, id)
+	}
+	return t
+}
+
+func (s *Server) listTables(r *request) (*response, *apiError) {
+	b := s.base(r)
+	var items []any
+	for _, id := range b.tableOrder {
+		t := b.tables[id]
+		items = append(items, map[string]any{"table_id": t.id, "name": t.name, "revision": len(t.order)})
+	}
+	page, hasMore, next := paginate(items, r, 100)
+	return ok(map[string]any{"items": page, "has_more": hasMore, "page_token": next, "total": len(items)})
+}
+
+// createTable 兼容 v1 {"table":{"name":...}} 与 v3 {"name":...} 两种请求体。
+func (s *Server) createTable(r *request) (*response, *apiError) {
+	name := str(r.body["name"])
+	if t := asMap(r.body["table"]); t != nil {
+		name = str(t["name"])
+	}
+	if name == "" {
+		return nil, errInvalid("table name is required")
+	}
+	b := s.base(r)
+	id := s.newID("tbl")
+	t := &table{id: id, name: name, records: make(map[string]map[string]any)}
+	// 与线上一致：新表自带一个文本主字段和一个表格视图
+	t.fields = []map[string]any{{"id": s.newID("fld"), "name": "文本", "type": "text", "is_primary": true}}
+	t.views = []*view{{id: s.newID("vew"), name: "表格", typ: "grid", configs: map[string]any{}}}
+	b.tables[id] = t
+	b.tableOrder = append(b.tableOrder, id)
+	return ok(map[string]any{"table_id": id, "default_view_id": t.views[0].id})
+}
+
+func (s *Server) deleteTable(r *request) (*response, *apiError) {
+	b := s.base(r)
+	id := r.param("table_id")
+	if b.tables[id] == nil {
+		return nil, errNotFound("table", id)
+	}
+	delete(b.tables, id)
+	for i, tid := range b.tableOrder {
+		if tid == id {
+			b.tableOrder = append(b.tableOrder[:i], b.tableOrder[i+1:]...)
+			break
+		}
+	}
+	return ok(map[string]any{"deleted": true, "table_id": id})
+}
+
+func (t *table) record(id string) map[string]any {
+	fields := t.records[id]
+	if fields == nil {
+		return nil
+	}
+	return map[string]any{"record_id": id, "fields": fields}
+}
+
+func (s *Server) addRecord(t *table, fields map[string]any) map[string]any {
+	id := s.newID("rec")
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	t.records[id] = cloneJSON(fields).(map[string]any)
+	t.order = append(t.order, id)
+	return t.record(id)
+}
+
+func (t *table) update(id string, fields map[string]any) (map[string]any, *apiError) {
+	cur := t.records[id]
+	if cur == nil {
+		return nil, errNotFound("record", id)
+	}
+	for k, v := range fields {
+		cur[k] = cloneJSON(v)
+	}
+	return t.record(id), nil
+}
+
+func (t *table) remove(id string) bool {
+	if t.records[id] == nil {
+		return false
+	}
+	delete(t.records, id)
+	for i, rid := range t.order {
+		if rid == id {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// listRecords 列出记录；field_id 投影只保留指定字段。
+func (s *Server) listRecords(r *request) (*response, *apiError) {
+	t := s.table(r)
+	q := r.URL.Query()
+	return s.recordPage(r, t, t.order, q["field_id"], q.Get("offset"), q.Get("limit"))
+}
+
+// searchRecords 按 keyword 在 search_fields（缺省为全部文本字段）中做包含匹配；filter / sort 不做解释。
+func (s *Server) searchRecords(r *request) (*response, *apiError) {
+	t := s.table(r)
+	keyword := str(r.body["keyword"])
+	searchFields := strSlice(r.body["search_fields"])
+	var ids []string
+	for _, id := range t.order {
+		if keyword == "" || recordContains(t.records[id], searchFields, keyword) {
+			ids = append(ids, id)
+		}
+	}
+	// v3 用 select_fields，v1 用 field_names
+	fields := strSlice(r.body["select_fields"])
+	if fields == nil {
+		fields = strSlice(r.body["field_names"])
+	}
+	return s.recordPage(r, t, ids, fields, str(r.body["offset"]), str(r.body["limit"]))
+}
+
+// recordPage 输出一页记录：v3 的 offset / limit 优先，否则按 page_size / page_token 分页。
+func (s *Server) recordPage(r *request, t *table, ids []string, fields []string, offset, limit string) (*response, *apiError) {
+	items := make([]any, 0, len(ids))
+	for _, id := range ids {
+		rec := t.record(id)
+		if len(fields) > 0 {
+			projected := map[string]any{}
+			for _, f := range fields {
+				if v, ok := t.records[id][f]; ok {
+					projected[f] = v
+				}
+			}
+			rec["fields"] = projected
+		}
+		items = append(items, rec)
+	}
+	if offset != "" || limit != "" {
+		start, _ := toInt(offset)
+		n, valid := toInt(limit)
+		if !valid || n <= 0 {
+			n = len(items)
+		}
+		start = min(max(start, 0), len(items))
+		end := min(start+n, len(items))
+		return ok(map[string]any{"items": items[start:end], "has_more": end < len(items), "total": len(items)})
+	}
+	page, hasMore, next := paginate(items, r, 100)
+	return ok(map[string]any{"items": page, "has_more": hasMore, "page_token": next, "total": len(items)})
+}
+
+func recordContains(fields map[string]any, searchFields []string, keyword string) bool {
+	for name, v := range fields {
+		if len(searchFields) > 0 && !containsStr(searchFields, name) {
+			continue
+		}
+		if s, ok := v.(string); ok && strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStr(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func strSlice(v any) []string {
+	var out []string
+	for _, item := range asSlice(v) {
+		out = append(out, str(item))
+	}
+	return out
+}
+
+func (s *Server) deleteRecord(r *request) (*response, *apiError) {
+	t := s.table(r)
+	id := r.param("record_id")
+	if !t.remove(id) {
+		return nil, errNotFound("record", id)
+	}
+	return ok(map[string]any{"deleted": true, "record_id": id})
+}
+
+// batchDeleteRecords 兼容 v3 {"record_id_list":[...]} 与 v1 {"records":[...]}。
+func (s *Server) batchDeleteRecords(r *request) (*response, *apiError) {
+	t := s.table(r)
+	ids := asSlice(r.body["record_id_list"])
+	if ids == nil {
+		ids = asSlice(r.body["records"])
+	}
+	var results []any
+	for _, raw := range ids {
+		id := str(raw)
+		results = append(results, map[string]any{"deleted": t.remove(id), "record_id": id})
+	}
+	return ok(map[string]any{"records": results})
+}
+
+// ---------- base/v3：字段映射直接位于 body 顶层 ----------
+
+func (s *Server) createRecordV3(r *request) (*response, *apiError) {
+	return ok(s.addRecord(s.table(r), r.body))
+}
+
+func (s *Server) getRecordV3(r *request) (*response, *apiError) {
+	t := s.table(r)
+	rec := t.record(r.param("record_id"))
+	if rec == nil {
+		return nil, errNotFound("record", r.param("record_id"))
+	}
+	return ok(rec)
+}
+
+func (s *Server) updateRecordV3(r *request) (*response, *apiError) {
+	rec, err := s.table(r).update(r.param("record_id"), r.body)
+	if err != nil {
+		return nil, err
+	}
+	return ok(rec)
+}
+
+// batchCreateRecordsV3 支持行式 {"create_records":[{...}]} 与列式 {"fields":[...],"rows":[[...]]}。
+func (s *Server) batchCreateRecordsV3(r *request) (*response, *apiError) {
+	t := s.table(r)
+	var rows []map[string]any
+	if recs := asSlice(r.body["create_records"]); recs != nil {
+		for _, raw := range recs {
+			rows = append(rows, asMap(raw))
+		}
+	} else {
+		names := asSlice(r.body["fields"])
+		for _, raw := range asSlice(r.body["rows"]) {
+			fields := map[string]any{}
+			for i, v := range asSlice(raw) {
+				if i < len(names) && v != nil {
+					fields[str(names[i])] = v
+				}
+			}
+			rows = append(rows, fields)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, errInvalid("create_records or fields+rows is required")
+	}
+	var ids []any
+	for _, fields := range rows {
+		ids = append(ids, s.addRecord(t, fields)["record_id"])
+	}
+	return ok(map[string]any{"record_id_list": ids})
+}
+
+// batchUpdateRecordsV3 支持 {"record_id_list":[...],"patch":{...}} 与 {"update_records":{id:{...}}}。
+func (s *Server) batchUpdateRecordsV3(r *request) (*response, *apiError) {
+	t := s.table(r)
+	updates := map[string]map[string]any{}
+	if patch := asMap(r.body["patch"]); patch != nil {
+		for _, id := range asSlice(r.body["record_id_list"]) {
+			updates[str(id)] = patch
+		}
+	}
+	for id, fields := range asMap(r.body["update_records"]) {
+		updates[id] = asMap(fields)
+	}
+	if len(updates) == 0 {
+		return nil, errInvalid("record_id_list+patch or update_records is required")
+	}
+	var ids []any
+	for _, id := range sortedKeys(updates) {
+		if _, err := t.update(id, updates[id]); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ok(map[string]any{"record_id_list": ids})
+}
+
+// ---------- bitable/v1：记录包在 {"fields":{...}} 中 ----------
+
+func (s *Server) createRecordV1(r *request) (*response, *apiError) {
+	return ok(map[string]any{"record": s.addRecord(s.table(r), asMap(r.body["fields"]))})
+}
+
+func (s *Server) getRecordV1(r *request) (*response, *apiError) {
+	rec := s.table(r).record(r.param("record_id"))
+	if rec == nil {
+		return nil, errNotFound("record", r.param("record_id"))
+	}
+	return ok(map[string]any{"record": rec})
+}
+
+func (s *Server) updateRecordV1(r *request) (*response, *apiError) {
+	rec, err := s.table(r).update(r.param("record_id"), asMap(r.body["fields"]))
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"record": rec})
+}
+
+func (s *Server) batchCreateRecordsV1(r *request) (*response, *apiError) {
+	t := s.table(r)
+	var records []any
+	for _, raw := range asSlice(r.body["records"]) {
+		records = append(records, s.addRecord(t, asMap(asMap(raw)["fields"])))
+	}
+	return ok(map[string]any{"records": records})
+}
+
+func (s *Server) batchUpdateRecordsV1(r *request) (*response, *apiError) {
+	t := s.table(r)
+	var records []any
+	for _, raw := range asSlice(r.body["records"]) {
+		rec := asMap(raw)
+		updated, err := t.update(str(rec["record_id"]), asMap(rec["fields"]))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, updated)
+	}
+	return ok(map[string]any{"records": records})
+}
+
+func (b *base) snapshot() map[string]any {
+	tables := make(map[string]any, len(b.tables))
+	for id, t := range b.tables {
+		records := make([]any, 0, len(t.order))
+		for _, rid := range t.order {
+			records = append(records, t.record(rid))
+		}
+		views := make([]any, 0, len(t.views))
+		for _, v := range t.views {
+			views = append(views, v.toMap())
+		}
+		tables[id] = map[string]any{"name": t.name, "records": records, "fields": t.fieldList(), "views": views}
+	}
+	return map[string]any{"tables": tables}
+}
@@ -0,0 +1,221 @@
+package mock
+
+// base/v3 的字段与视图：只做结构存储，不校验字段属性、不让视图配置影响记录查询。
+
+// view 是数据表的一个视图；configs 保存 filter/sort/group 等配置的原始请求体。
+type view struct {
+	id      string
+	name    string
+	typ     string
+	configs map[string]any
+}
+
+// viewConfigKinds 是视图配置接口支持的路径段。
+var viewConfigKinds = map[string]bool{
+	"filter": true, "sort": true, "group": true, "visible_fields": true, "timebar": true, "card": true,
+}
+
+func (v *view) toMap() map[string]any {
+	return map[string]any{"id": v.id, "name": v.name, "type": v.typ}
+}
+
+func (t *table) fieldList() []any {
+	items := make([]any, 0, len(t.fields))
+	for _, f := range t.fields {
+		items = append(items, cloneJSON(f))
+	}
+	return items
+}
+
+func (t *table) fieldIndex(id string) int {
+	for i, f := range t.fields {
+		if str(f["id"]) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// fieldNameTaken 判断字段名是否已被 exceptID 以外的字段占用。
+func (t *table) fieldNameTaken(name, exceptID string) bool {
+	for _, f := range t.fields {
+		if str(f["name"]) == name && str(f["id"]) != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *table) viewIndex(id string) int {
+	for i, v := range t.views {
+		if v.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// offsetPage 按 base/v3 的 offset / limit 分页。
+func offsetPage(items []any, r *request) (*response, *apiError) {
+	start, _ := toInt(r.query("offset"))
+	n, valid := toInt(r.query("limit"))
+	if !valid || n <= 0 {
+		n = 100
+	}
+	start = min(max(start, 0), len(items))
+	end := min(start+n, len(items))
+	return ok(map[string]any{"items": items[start:end], "has_more": end < len(items), "total": len(items)})
+}
+
+func (s *Server) listFields(r *request) (*response, *apiError) {
+	return offsetPage(s.table(r).fieldList(), r)
+}
+
+func (s *Server) createField(r *request) (*response, *apiError) {
+	t := s.table(r)
+	name, typ := str(r.body["name"]), str(r.body["type"])
+	if name == "" || typ == "" {
+		return nil, errInvalid("field name and type are required")
+	}
+	if t.fieldNameTaken(name, "") {
+		return nil, errInvalid("field name duplicated: %s", name)
+	}
+	f := cloneJSON(r.body).(map[string]any)
+	f["id"] = s.newID("fld")
+	delete(f, "is_primary")
+	t.fields = append(t.fields, f)
+	return ok(cloneJSON(f))
+}
+
+// updateField 是 PUT 全量替换：除 id 与主字段标记外，旧属性全部丢弃。
+func (s *Server) updateField(r *request) (*response, *apiError) {
+	t := s.table(r)
+	id := r.param("field_id")
+	i := t.fieldIndex(id)
+	if i < 0 {
+		return nil, errNotFound("field", id)
+	}
+	name, typ := str(r.body["name"]), str(r.body["type"])
+	if name == "" || typ == "" {
+		return nil, errInvalid("field name and type are required")
+	}
+	if t.fieldNameTaken(name, id) {
+		return nil, errInvalid("field name duplicated: %s", name)
+	}
+	f := cloneJSON(r.body).(map[string]any)
+	f["id"] = id
+	delete(f, "is_primary")
+	if t.fields[i]["is_primary"] == true {
+		f["is_primary"] = true
+	}
+	t.fields[i] = f
+	return ok(cloneJSON(f))
+}
+
+func (s *Server) deleteField(r *request) (*response, *apiError) {
+	t := s.table(r)
+	id := r.param("field_id")
+	i := t.fieldIndex(id)
+	if i < 0 {
+		return nil, errNotFound("field", id)
+	}
+	if t.fields[i]["is_primary"] == true {
+		return nil, errInvalid("primary field cannot be deleted")
+	}
+	t.fields = append(t.fields[:i], t.fields[i+1:]...)
+	return ok(map[string]any{"deleted": true, "field_id": id})
+}
+
+func (s *Server) listViews(r *request) (*response, *apiError) {
+	t := s.table(r)
+	items := make([]any, 0, len(t.views))
+	for _, v := range t.views {
+		items = append(items, v.toMap())
+	}
+	return offsetPage(items, r)
+}
+
+func (s *Server) createView(r *request) (*response, *apiError) {
+	t := s.table(r)
+	name := str(r.body["name"])
+	if name == "" {
+		return nil, errInvalid("view name is required")
+	}
+	typ := str(r.body["type"])
+	if typ == "" {
+		typ = "grid"
+	}
+	v := &view{id: s.newID("vew"), name: name, typ: typ, configs: map[string]any{}}
+	t.views = append(t.views, v)
+	return ok(v.toMap())
+}
+
+func (s *Server) view(r *request) (*table, *view, *apiError) {
+	t := s.table(r)
+	id := r.param("view_id")
+	i := t.viewIndex(id)
+	if i < 0 {
+		return nil, nil, errNotFound("view", id)
+	}
+	return t, t.views[i], nil
+}
+
+func (s *Server) getView(r *request) (*response, *apiError) {
+	_, v, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	return ok(v.toMap())
+}
+
+func (s *Server) renameView(r *request) (*response, *apiError) {
+	_, v, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	if name := str(r.body["name"]); name != "" {
+		v.name = name
+	}
+	return ok(v.toMap())
+}
+
+func (s *Server) deleteView(r *request) (*response, *apiError) {
+	t, v, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.views) == 1 {
+		return nil, errInvalid("the last view cannot be deleted")
+	}
+	t.views = append(t.views[:t.viewIndex(v.id)], t.views[t.viewIndex(v.id)+1:]...)
+	return ok(map[string]any{"deleted": true, "view_id": v.id})
+}
+
+func (s *Server) getViewConfig(r *request) (*response, *apiError) {
+	_, v, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	kind := r.param("kind")
+	if !viewConfigKinds[kind] {
+		return nil, errNotFound("view config", kind)
+	}
+	cfg, _ := v.configs[kind].(map[string]any)
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+	return ok(cloneJSON(cfg))
+}
+
+func (s *Server) setViewConfig(r *request) (*response, *apiError) {
+	_, v, err := s.view(r)
+	if err != nil {
+		return nil, err
+	}
+	kind := r.param("kind")
+	if !viewConfigKinds[kind] {
+		return nil, errNotFound("view config", kind)
+	}
+	v.configs[kind] = cloneJSON(r.body)
+	return ok(cloneJSON(r.body))
+}
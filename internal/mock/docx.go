@@ -0,0 +1,677 @@
+package mock
+
+import (
+	"net/http"
+	"strings"
+)
+
+// docx 块类型（与 converter.BlockType* 取值一致，这里只列出 mock 需要特殊处理的）。
+const (
+	blockTypePage           = 1
+	blockTypeText           = 2
+	blockTypeCallout        = 19
+	blockTypeGrid           = 24
+	blockTypeGridColumn     = 25
+	blockTypeTable          = 31
+	blockTypeTableCell      = 32
+	blockTypeQuoteContainer = 34
+)
+
+// document 是一篇 docx 文档：块以 block_id 索引，根块（page）的 block_id 等于 document_id。
+type document struct {
+	id       string
+	title    string
+	folder   string
+	revision int
+	blocks   map[string]map[string]any
+}
+
+func (s *Server) docxRoutes() []*route {
+	const p = "/open-apis/docx/v1/documents"
+	return []*route{
+		newRoute(http.MethodPost, p, s.createDocument),
+		newRoute(http.MethodGet, p+"/{document_id}", s.getDocument),
+		newRoute(http.MethodGet, p+"/{document_id}/raw_content", s.getRawContent),
+		newRoute(http.MethodGet, p+"/{document_id}/blocks", s.listBlocks),
+		newRoute(http.MethodGet, p+"/{document_id}/blocks/{block_id}", s.getBlock),
+		newRoute(http.MethodPatch, p+"/{document_id}/blocks/{block_id}", s.patchBlock),
+		newRoute(http.MethodPatch, p+"/{document_id}/blocks/batch_update", s.batchUpdateBlocks),
+		newRoute(http.MethodGet, p+"/{document_id}/blocks/{block_id}/children", s.getBlockChildren),
+		newRoute(http.MethodPost, p+"/{document_id}/blocks/{block_id}/children", s.createBlockChildren),
+		newRoute(http.MethodPost, p+"/{document_id}/blocks/{block_id}/descendant", s.createBlockDescendant),
+		newRoute(http.MethodDelete, p+"/{document_id}/blocks/{block_id}/children/batch_delete", s.batchDeleteChildren),
+	}
+}
+
+func (s *Server) createDocument(r *request) (*response, *apiError) {
+	title := str(r.body["title"])
+	folder := str(r.body["folder_token"])
+	doc := s.newDocument(title, folder)
+	s.state.files[doc.id] = &driveFile{token: doc.id, name: title, fileType: "docx", parent: folder, created: s.nowSec(), url: r.base + "/docx/" + doc.id}
+	return ok(map[string]any{"document": doc.meta()})
+}
+
+// newDocument 创建一篇只有根块的空文档并登记到状态中。
+func (s *Server) newDocument(title, folder string) *document {
+	id := s.newID("doxcn")
+	doc := &document{id: id, title: title, folder: folder, revision: 1, blocks: make(map[string]map[string]any)}
+	doc.blocks[id] = map[string]any{
+		"block_id":   id,
+		"block_type": blockTypePage,
+		"parent_id":  "",
+		"children":   []any{},
+		"page":       map[string]any{"elements": textElements(title), "style": map[string]any{}},
+	}
+	s.state.documents[id] = doc
+	return doc
+}
+
+func (s *Server) document(r *request) (*document, *apiError) {
+	id := r.param("document_id")
+	doc := s.state.documents[id]
+	if doc == nil {
+		return nil, errNotFound("document", id)
+	}
+	return doc, nil
+}
+
+func (s *Server) getDocument(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"document": doc.meta()})
+}
+
+func (s *Server) getRawContent(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"content": doc.rawContent()})
+}
+
+func (s *Server) listBlocks(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	items, hasMore, next := paginate(doc.listBlocks(), r, 500)
+	return ok(map[string]any{"items": items, "has_more": hasMore, "page_token": next})
+}
+
+func (s *Server) getBlock(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"block": b})
+}
+
+func (s *Server) getBlockChildren(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	var items []any
+	if r.query("with_descendants") == "true" {
+		for _, id := range childIDs(parent) {
+			items = append(items, doc.subtree(id)...)
+		}
+	} else {
+		for _, id := range childIDs(parent) {
+			items = append(items, doc.blocks[id])
+		}
+	}
+	page, hasMore, next := paginate(items, r, 500)
+	return ok(map[string]any{"items": page, "has_more": hasMore, "page_token": next})
+}
+
+func (s *Server) createBlockChildren(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	children := asSlice(r.body["children"])
+	if len(children) == 0 {
+		return nil, errInvalid("children is required")
+	}
+	index := -1
+	if n, ok := toInt(r.body["index"]); ok {
+		index = n
+	}
+	var created []any
+	var ids []string
+	for _, raw := range children {
+		b, err := s.newBlock(doc, parent["block_id"].(string), asMap(raw), true)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, b)
+		ids = append(ids, b["block_id"].(string))
+	}
+	insertChildren(parent, index, ids)
+	doc.revision++
+	return ok(map[string]any{"children": created, "document_revision_id": doc.revision, "client_token": r.query("client_token")})
+}
+
+// createBlockDescendant 一次创建嵌套块树：descendants 中的 block_id 是临时 ID，
+// children_id 列出直接挂到父块下的临时 ID，返回临时 ID 到真实 ID 的映射。
+func (s *Server) createBlockDescendant(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	byTemp := make(map[string]map[string]any)
+	for _, raw := range asSlice(r.body["descendants"]) {
+		b := asMap(raw)
+		if tmp := str(b["block_id"]); tmp != "" {
+			byTemp[tmp] = b
+		}
+	}
+	var relations []any
+	var created []any
+	var build func(parentID, tmp string) (string, *apiError)
+	build = func(parentID, tmp string) (string, *apiError) {
+		raw := byTemp[tmp]
+		if raw == nil {
+			return "", errInvalid("descendant %s not found", tmp)
+		}
+		// 显式给出子块时（如表格带单元格）不再自动生成默认子结构
+		explicit := len(asSlice(raw["children"])) > 0
+		b, err := s.newBlock(doc, parentID, raw, !explicit)
+		if err != nil {
+			return "", err
+		}
+		id := b["block_id"].(string)
+		relations = append(relations, map[string]any{"temporary_block_id": tmp, "block_id": id})
+		created = append(created, b)
+		var kids []string
+		for _, child := range asSlice(raw["children"]) {
+			childID, err := build(id, str(child))
+			if err != nil {
+				return "", err
+			}
+			kids = append(kids, childID)
+		}
+		insertChildren(b, -1, kids)
+		if table := asMap(b["table"]); table != nil && explicit {
+			table["cells"] = toAnySlice(kids)
+		}
+		return id, nil
+	}
+	var top []string
+	for _, tmp := range asSlice(r.body["children_id"]) {
+		id, err := build(parent["block_id"].(string), str(tmp))
+		if err != nil {
+			return nil, err
+		}
+		top = append(top, id)
+	}
+	index := -1
+	if n, ok := toInt(r.body["index"]); ok {
+		index = n
+	}
+	insertChildren(parent, index, top)
+	doc.revision++
+	return ok(map[string]any{"children": created, "block_id_relations": relations, "document_revision_id": doc.revision})
+}
+
+func (s *Server) batchDeleteChildren(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	start, _ := toInt(r.body["start_index"])
+	end, _ := toInt(r.body["end_index"])
+	kids := childIDs(parent)
+	if start < 0 || end > len(kids) || start >= end {
+		return nil, errInvalid("invalid range [%d, %d) for %d children", start, end, len(kids))
+	}
+	for _, id := range kids[start:end] {
+		doc.deleteSubtree(id)
+	}
+	setChildren(parent, append(append([]string{}, kids[:start]...), kids[end:]...))
+	doc.revision++
+	return ok(map[string]any{"document_revision_id": doc.revision, "client_token": r.query("client_token")})
+}
+
+func (s *Server) patchBlock(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	b, err := doc.block(r.param("block_id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBlockUpdate(doc, b, r.body); err != nil {
+		return nil, err
+	}
+	doc.revision++
+	return ok(map[string]any{"block": b, "document_revision_id": doc.revision, "client_token": r.query("client_token")})
+}
+
+func (s *Server) batchUpdateBlocks(r *request) (*response, *apiError) {
+	doc, err := s.document(r)
+	if err != nil {
+		return nil, err
+	}
+	var updated []any
+	for _, raw := range asSlice(r.body["requests"]) {
+		req := asMap(raw)
+		b, err := doc.block(str(req["block_id"]))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.applyBlockUpdate(doc, b, req); err != nil {
+			return nil, err
+		}
+		updated = append(updated, b)
+	}
+	doc.revision++
+	return ok(map[string]any{"blocks": updated, "document_revision_id": doc.revision, "client_token": r.query("client_token")})
+}
+
+// applyBlockUpdate 执行 UpdateBlockRequest 中的一种操作。
+// 文本 / 图片 / 文件 / 表格行列增删按真实语义修改块；合并单元格等纯展示操作视为成功不改状态。
+func (s *Server) applyBlockUpdate(doc *document, b map[string]any, req map[string]any) *apiError {
+	if v := asMap(req["update_text_elements"]); v != nil {
+		field := textField(b)
+		if field == nil {
+			return errInvalid("block %s has no text elements", b["block_id"])
+		}
+		field["elements"] = cloneJSON(v["elements"])
+	}
+	if v := asMap(req["update_text"]); v != nil {
+		field := textField(b)
+		if field == nil {
+			return errInvalid("block %s has no text elements", b["block_id"])
+		}
+		field["elements"] = cloneJSON(v["elements"])
+		if style := v["style"]; style != nil {
+			field["style"] = cloneJSON(style)
+		}
+	}
+	if v := asMap(req["update_text_style"]); v != nil {
+		if field := textField(b); field != nil {
+			field["style"] = cloneJSON(v["style"])
+		}
+	}
+	if v := asMap(req["replace_image"]); v != nil {
+		mergeInto(b, "image", v)
+	}
+	if v := asMap(req["replace_file"]); v != nil {
+		mergeInto(b, "file", v)
+	}
+	if v := asMap(req["update_table_property"]); v != nil {
+		table := asMap(b["table"])
+		if table == nil {
+			return errInvalid("block %s is not a table", b["block_id"])
+		}
+		mergeInto(table, "property", v)
+	}
+	if v := asMap(req["insert_table_row"]); v != nil {
+		idx, _ := toInt(v["row_index"])
+		return s.insertTableRow(doc, b, idx)
+	}
+	if v := asMap(req["insert_table_column"]); v != nil {
+		idx, _ := toInt(v["column_index"])
+		return s.insertTableColumn(doc, b, idx)
+	}
+	if v := asMap(req["delete_table_rows"]); v != nil {
+		start, _ := toInt(v["row_start_index"])
+		end, _ := toInt(v["row_end_index"])
+		return deleteTableRows(doc, b, start, end)
+	}
+	if v := asMap(req["delete_table_columns"]); v != nil {
+		start, _ := toInt(v["column_start_index"])
+		end, _ := toInt(v["column_end_index"])
+		return deleteTableColumns(doc, b, start, end)
+	}
+	if v := asMap(req["merge_table_cells"]); v != nil {
+		rowStart, _ := toInt(v["row_start_index"])
+		rowEnd, _ := toInt(v["row_end_index"])
+		colStart, _ := toInt(v["column_start_index"])
+		colEnd, _ := toInt(v["column_end_index"])
+		return mergeTableCells(b, rowStart, rowEnd, colStart, colEnd)
+	}
+	return nil
+}
+
+// newBlock 按请求中的块结构创建一个新块（不含子块）。auto 为 true 时，表格 / 分栏 /
+// 高亮块 / 引用容器与线上一致地自动生成单元格、分栏列及空文本子块。
+func (s *Server) newBlock(doc *document, parentID string, raw map[string]any, auto bool) (map[string]any, *apiError) {
+	if raw == nil {
+		return nil, errInvalid("block is required")
+	}
+	b := cloneJSON(raw).(map[string]any)
+	blockType, ok := toInt(b["block_type"])
+	if !ok {
+		return nil, errInvalid("block_type is required")
+	}
+	id := s.newID("doxcn")
+	b["block_id"] = id
+	b["parent_id"] = parentID
+	b["block_type"] = blockType
+	b["children"] = []any{}
+	doc.blocks[id] = b
+	if !auto {
+		return b, nil
+	}
+
+	switch blockType {
+	case blockTypeTable:
+		table := asMap(b["table"])
+		prop := asMap(table["property"])
+		rows, _ := toInt(prop["row_size"])
+		cols, _ := toInt(prop["column_size"])
+		if rows <= 0 || cols <= 0 {
+			return nil, errInvalid("table.property.row_size / column_size must be positive")
+		}
+		cells := make([]string, 0, rows*cols)
+		for i := 0; i < rows*cols; i++ {
+			cells = append(cells, s.newContainer(doc, id, blockTypeTableCell, "table_cell"))
+		}
+		table["cells"] = toAnySlice(cells)
+		setChildren(b, cells)
+	case blockTypeGrid:
+		cols, _ := toInt(asMap(b["grid"])["column_size"])
+		if cols <= 0 {
+			cols = 2
+		}
+		var columns []string
+		for i := 0; i < cols; i++ {
+			columns = append(columns, s.newContainer(doc, id, blockTypeGridColumn, "grid_column"))
+		}
+		setChildren(b, columns)
+	case blockTypeCallout, blockTypeQuoteContainer:
+		setChildren(b, []string{s.newEmptyText(doc, id)})
+	}
+	return b, nil
+}
+
+// newContainer 创建带一个空文本子块的容器块（表格单元格 / 分栏列）。
+func (s *Server) newContainer(doc *document, parentID string, blockType int, field string) string {
+	id := s.newID("doxcn")
+	b := map[string]any{
+		"block_id":   id,
+		"parent_id":  parentID,
+		"block_type": blockType,
+		field:        map[string]any{},
+	}
+	doc.blocks[id] = b
+	setChildren(b, []string{s.newEmptyText(doc, id)})
+	return id
+}
+
+func (s *Server) newEmptyText(doc *document, parentID string) string {
+	id := s.newID("doxcn")
+	doc.blocks[id] = map[string]any{
+		"block_id":   id,
+		"parent_id":  parentID,
+		"block_type": blockTypeText,
+		"children":   []any{},
+		"text":       map[string]any{"elements": textElements(""), "style": map[string]any{}},
+	}
+	return id
+}
+
+func (s *Server) insertTableRow(doc *document, table map[string]any, rowIndex int) *apiError {
+	cells, rows, cols, err := tableShape(table)
+	if err != nil {
+		return err
+	}
+	if rowIndex < 0 || rowIndex > rows {
+		rowIndex = rows
+	}
+	row := make([]string, 0, cols)
+	for i := 0; i < cols; i++ {
+		row = append(row, s.newContainer(doc, table["block_id"].(string), blockTypeTableCell, "table_cell"))
+	}
+	at := rowIndex * cols
+	cells = append(cells[:at], append(row, cells[at:]...)...)
+	setTableShape(table, cells, rows+1, cols)
+	return nil
+}
+
+func (s *Server) insertTableColumn(doc *document, table map[string]any, colIndex int) *apiError {
+	cells, rows, cols, err := tableShape(table)
+	if err != nil {
+		return err
+	}
+	if colIndex < 0 || colIndex > cols {
+		colIndex = cols
+	}
+	out := make([]string, 0, rows*(cols+1))
+	for r := 0; r < rows; r++ {
+		row := cells[r*cols : (r+1)*cols]
+		out = append(out, row[:colIndex]...)
+		out = append(out, s.newContainer(doc, table["block_id"].(string), blockTypeTableCell, "table_cell"))
+		out = append(out, row[colIndex:]...)
+	}
+	setTableShape(table, out, rows, cols+1)
+	return nil
+}
+
+func deleteTableRows(doc *document, table map[string]any, start, end int) *apiError {
+	cells, rows, cols, err := tableShape(table)
+	if err != nil {
+		return err
+	}
+	if start < 0 || end > rows || start >= end {
+		return errInvalid("invalid row range [%d, %d)", start, end)
+	}
+	for _, id := range cells[start*cols : end*cols] {
+		doc.deleteSubtree(id)
+	}
+	out := append(append([]string{}, cells[:start*cols]...), cells[end*cols:]...)
+	setTableShape(table, out, rows-(end-start), cols)
+	return nil
+}
+
+func deleteTableColumns(doc *document, table map[string]any, start, end int) *apiError {
+	cells, rows, cols, err := tableShape(table)
+	if err != nil {
+		return err
+	}
+	if start < 0 || end > cols || start >= end {
+		return errInvalid("invalid column range [%d, %d)", start, end)
+	}
+	out := make([]string, 0, rows*(cols-(end-start)))
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			id := cells[r*cols+c]
+			if c >= start && c < end {
+				doc.deleteSubtree(id)
+				continue
+			}
+			out = append(out, id)
+		}
+	}
+	setTableShape(table, out, rows, cols-(end-start))
+	return nil
+}
+
+// mergeTableCells 只记录左上角单元格的跨度（与线上 merge_info 一致，被覆盖的单元格保持 1×1）。
+func mergeTableCells(table map[string]any, rowStart, rowEnd, colStart, colEnd int) *apiError {
+	_, rows, cols, err := tableShape(table)
+	if err != nil {
+		return err
+	}
+	if rowStart < 0 || colStart < 0 || rowEnd > rows || colEnd > cols || rowStart >= rowEnd || colStart >= colEnd {
+		return errInvalid("invalid merge range rows [%d, %d) columns [%d, %d)", rowStart, rowEnd, colStart, colEnd)
+	}
+	prop := asMap(asMap(table["table"])["property"])
+	info, _ := prop["merge_info"].([]any)
+	if len(info) != rows*cols {
+		info = make([]any, rows*cols)
+		for i := range info {
+			info[i] = map[string]any{"row_span": 1, "col_span": 1}
+		}
+	}
+	info[rowStart*cols+colStart] = map[string]any{"row_span": rowEnd - rowStart, "col_span": colEnd - colStart}
+	prop["merge_info"] = info
+	return nil
+}
+
+func tableShape(b map[string]any) ([]string, int, int, *apiError) {
+	table := asMap(b["table"])
+	if table == nil {
+		return nil, 0, 0, errInvalid("block %s is not a table", b["block_id"])
+	}
+	prop := asMap(table["property"])
+	if prop == nil {
+		return nil, 0, 0, errInvalid("table %s has no property", b["block_id"])
+	}
+	rows, _ := toInt(prop["row_size"])
+	cols, _ := toInt(prop["column_size"])
+	return childIDs(b), rows, cols, nil
+}
+
+func setTableShape(b map[string]any, cells []string, rows, cols int) {
+	table := asMap(b["table"])
+	prop := asMap(table["property"])
+	prop["row_size"] = rows
+	prop["column_size"] = cols
+	table["cells"] = toAnySlice(cells)
+	setChildren(b, cells)
+}
+
+func (d *document) meta() map[string]any {
+	return map[string]any{"document_id": d.id, "revision_id": d.revision, "title": d.title}
+}
+
+func (d *document) block(id string) (map[string]any, *apiError) {
+	b := d.blocks[id]
+	if b == nil {
+		return nil, errNotFound("block", id)
+	}
+	return b, nil
+}
+
+// listBlocks 按文档顺序（先序遍历）返回全部块。
+func (d *document) listBlocks() []any {
+	return d.subtree(d.id)
+}
+
+func (d *document) subtree(id string) []any {
+	b := d.blocks[id]
+	if b == nil {
+		return nil
+	}
+	out := []any{b}
+	for _, child := range childIDs(b) {
+		out = append(out, d.subtree(child)...)
+	}
+	return out
+}
+
+func (d *document) deleteSubtree(id string) {
+	b := d.blocks[id]
+	if b == nil {
+		return
+	}
+	for _, child := range childIDs(b) {
+		d.deleteSubtree(child)
+	}
+	delete(d.blocks, id)
+}
+
+// rawContent 拼接所有块的纯文本，每个文本类块一行，与 raw_content 接口一致。
+func (d *document) rawContent() string {
+	var sb strings.Builder
+	for _, raw := range d.listBlocks() {
+		field := textField(raw.(map[string]any))
+		if field == nil {
+			continue
+		}
+		for _, el := range asSlice(field["elements"]) {
+			e := asMap(el)
+			sb.WriteString(str(asMap(e["text_run"])["content"]))
+			sb.WriteString(str(asMap(e["equation"])["content"]))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// textField 返回块中承载 elements 的字段（text / heading1 / bullet / code / page ...）。
+func textField(b map[string]any) map[string]any {
+	for _, v := range b {
+		if m := asMap(v); m != nil {
+			if _, ok := m["elements"]; ok {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func textElements(content string) []any {
+	return []any{map[string]any{"text_run": map[string]any{"content": content, "text_element_style": map[string]any{}}}}
+}
+
+func childIDs(b map[string]any) []string {
+	var ids []string
+	for _, v := range asSlice(b["children"]) {
+		ids = append(ids, str(v))
+	}
+	return ids
+}
+
+func setChildren(b map[string]any, ids []string) {
+	b["children"] = toAnySlice(ids)
+}
+
+// insertChildren 把 ids 插入到 index 处；index 越界或为 -1 时追加到末尾。
+func insertChildren(b map[string]any, index int, ids []string) {
+	kids := childIDs(b)
+	if index < 0 || index > len(kids) {
+		index = len(kids)
+	}
+	out := make([]string, 0, len(kids)+len(ids))
+	out = append(out, kids[:index]...)
+	out = append(out, ids...)
+	out = append(out, kids[index:]...)
+	setChildren(b, out)
+}
+
+func mergeInto(b map[string]any, field string, v map[string]any) {
+	dst := asMap(b[field])
+	if dst == nil {
+		dst = map[string]any{}
+		b[field] = dst
+	}
+	for k, val := range v {
+		dst[k] = cloneJSON(val)
+	}
+}
+
+func toAnySlice(ids []string) []any {
+	out := make([]any, len(ids))
+	for i, id := range ids {
+		out[i] = id
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// mockRootFolder 是 mock 云盘根目录 token；列举时空 folder_token 等价于根目录。
+const mockRootFolder = "fldcnmockroot"
+
+// driveFile 是云盘中的一个条目（文件 / 文件夹 / 在线文档），媒体素材 parent 为空且不出现在列表中。
+type driveFile struct {
+	token    string
+	name     string
+	fileType string // file / folder / docx / sheet / bitable
+	parent   string
+	created  string
+	modified string
+	url      string
+	content  []byte
+	media    bool // medias/upload_all 上传的素材，不属于任何文件夹
+}
+
+func (f *driveFile) meta() map[string]any {
+	modified := f.modified
+	if modified == "" {
+		modified = f.created
+	}
+	return map[string]any{
+		"token":         f.token,
+		"name":          f.name,
+		"type":          f.fileType,
+		"parent_token":  f.parent,
+		"url":           f.url,
+		"created_time":  f.created,
+		"modified_time": modified,
+		"owner_id":      "ou_mock_owner",
+		"size":          len(f.content),
+	}
+}
+
+func (s *Server) driveRoutes() []*route {
+	const p = "/open-apis/drive/v1"
+	return []*route{
+		newRoute(http.MethodGet, "/open-apis/drive/explorer/v2/root_folder/meta", s.rootFolderMeta),
+		newRoute(http.MethodGet, p+"/files", s.listFiles),
+		newRoute(http.MethodPost, p+"/files/create_folder", s.createFolder),
+		newRoute(http.MethodPost, p+"/files/upload_all", s.uploadFile),
+		newRoute(http.MethodPost, p+"/medias/upload_all", s.uploadMedia),
+		newRoute(http.MethodGet, p+"/files/{file_token}/download", s.downloadFile),
+		newRoute(http.MethodGet, p+"/medias/{file_token}/download", s.downloadFile),
+		newRoute(http.MethodPost, p+"/files/{file_token}/move", s.moveFile),
+		newRoute(http.MethodPost, p+"/files/{file_token}/copy", s.copyFile),
+		newRoute(http.MethodDelete, p+"/files/{file_token}", s.deleteFile),
+	}
+}
+
+func (s *Server) rootFolderMeta(r *request) (*response, *apiError) {
+	return ok(map[string]any{"token": mockRootFolder, "id": "0", "user_id": "ou_mock_owner"})
+}
+
+// folderToken 把空 folder_token 归一为根目录。
+func folderToken(token string) string {
+	if token == "" {
+		return mockRootFolder
+	}
+	return token
+}
+
+func (s *Server) listFiles(r *request) (*response, *apiError) {
+	folder := folderToken(r.query("folder_token"))
+	var files []any
+	for _, token := range sortedKeys(s.state.files) {
+		f := s.state.files[token]
+		if !f.media && folderToken(f.parent) == folder {
+			files = append(files, f.meta())
+		}
+	}
+	page, hasMore, next := paginate(files, r, 200)
+	return ok(map[string]any{"files": page, "has_more": hasMore, "next_page_token": next})
+}
+
+func (s *Server) createFolder(r *request) (*response, *apiError) {
+	name := str(r.body["name"])
+	if name == "" {
+		return nil, errInvalid("name is required")
+	}
+	token := s.newID("fldcn")
+	url := r.base + "/drive/folder/" + token
+	s.state.files[token] = &driveFile{token: token, name: name, fileType: "folder", parent: str(r.body["folder_token"]), created: s.nowSec(), url: url}
+	return ok(map[string]any{"token": token, "url": url})
+}
+
+// readUpload 解析 upload_all 的 multipart 表单，返回文件名、父节点与内容。
+func readUpload(r *request) (string, string, []byte, *apiError) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return "", "", nil, errInvalid("multipart form: %v", err)
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", "", nil, errInvalid("file is required")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", "", nil, errInvalid("read file: %v", err)
+	}
+	name := r.FormValue("file_name")
+	if name == "" {
+		name = header.Filename
+	}
+	if size, err := strconv.Atoi(r.FormValue("size")); err == nil && size != len(data) {
+		return "", "", nil, errInvalid("size %d does not match file length %d", size, len(data))
+	}
+	return name, r.FormValue("parent_node"), data, nil
+}
+
+func (s *Server) uploadFile(r *request) (*response, *apiError) {
+	name, parent, data, err := readUpload(r)
+	if err != nil {
+		return nil, err
+	}
+	token := s.newID("boxcn")
+	s.state.files[token] = &driveFile{token: token, name: name, fileType: "file", parent: parent, created: s.nowSec(), url: r.base + "/file/" + token, content: data}
+	return ok(map[string]any{"file_token": token})
+}
+
+func (s *Server) uploadMedia(r *request) (*response, *apiError) {
+	name, parent, data, err := readUpload(r)
+	if err != nil {
+		return nil, err
+	}
+	token := s.newID("boxcn")
+	s.state.files[token] = &driveFile{token: token, name: name, fileType: "file", parent: parent, created: s.nowSec(), content: data, media: true}
+	return ok(map[string]any{"file_token": token})
+}
+
+func (s *Server) driveFile(r *request) (*driveFile, *apiError) {
+	token := r.param("file_token")
+	f := s.state.files[token]
+	if f == nil {
+		return nil, errNotFound("file", token)
+	}
+	return f, nil
+}
+
+func (s *Server) downloadFile(r *request) (*response, *apiError) {
+	f, err := s.driveFile(r)
+	if err != nil {
+		return nil, err
+	}
+	if f.fileType != "file" {
+		return nil, errInvalid("%s is a %s, not a downloadable file", f.token, f.fileType)
+	}
+	return &response{raw: f.content, filename: f.name}, nil
+}
+
+func (s *Server) moveFile(r *request) (*response, *apiError) {
+	f, err := s.driveFile(r)
+	if err != nil {
+		return nil, err
+	}
+	f.parent = str(r.body["folder_token"])
+	f.modified = s.nowSec()
+	return ok(map[string]any{"task_id": ""})
+}
+
+func (s *Server) copyFile(r *request) (*response, *apiError) {
+	f, err := s.driveFile(r)
+	if err != nil {
+		return nil, err
+	}
+	cp := *f
+	cp.token = s.newID("boxcn")
+	cp.name = str(r.body["name"])
+	cp.parent = str(r.body["folder_token"])
+	cp.created = s.nowSec()
+	cp.modified = ""
+	cp.url = r.base + "/file/" + cp.token
+	cp.content = append([]byte(nil), f.content...)
+	s.state.files[cp.token] = &cp
+	return ok(map[string]any{"file": cp.meta()})
+}
+
+// deleteFile 删除文件；删除文件夹时连同其下条目一起删除。
+func (s *Server) deleteFile(r *request) (*response, *apiError) {
+	f, err := s.driveFile(r)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteDriveTree(f.token)
+	return ok(map[string]any{"task_id": ""})
+}
+
+func (s *Server) deleteDriveTree(token string) {
+	for _, child := range sortedKeys(s.state.files) {
+		if s.state.files[child].parent == token {
+			s.deleteDriveTree(child)
+		}
+	}
+	delete(s.state.files, token)
+	delete(s.state.documents, token)
+	delete(s.state.spreadsheets, token)
+}
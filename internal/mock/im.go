@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// message 是一条 IM 消息。
+type message struct {
+	id       string
+	chatID   string
+	msgType  string
+	content  string
+	parentID string
+	rootID   string
+	threadID string
+	created  string
+	updated  string
+	deleted  bool
+	edited   bool
+}
+
+func (m *message) toMap() map[string]any {
+	updated := m.updated
+	if updated == "" {
+		updated = m.created
+	}
+	return map[string]any{
+		"message_id":  m.id,
+		"root_id":     m.rootID,
+		"parent_id":   m.parentID,
+		"thread_id":   m.threadID,
+		"msg_type":    m.msgType,
+		"create_time": m.created,
+		"update_time": updated,
+		"deleted":     m.deleted,
+		"updated":     m.edited,
+		"chat_id":     m.chatID,
+		"sender": map[string]any{
+			"id":          "cli_mock",
+			"id_type":     "app_id",
+			"sender_type": "app",
+			"tenant_key":  "mock",
+		},
+		"body":     map[string]any{"content": m.content},
+		"mentions": []any{},
+	}
+}
+
+func (s *Server) imRoutes() []*route {
+	const p = "/open-apis/im/v1/messages"
+	return []*route{
+		newRoute(http.MethodPost, p, s.createMessage),
+		newRoute(http.MethodGet, p, s.listMessages),
+		newRoute(http.MethodGet, p+"/{message_id}", s.getMessage),
+		newRoute(http.MethodPost, p+"/{message_id}/reply", s.replyMessage),
+		newRoute(http.MethodPatch, p+"/{message_id}", s.patchMessage),
+		newRoute(http.MethodPut, p+"/{message_id}", s.patchMessage),
+		newRoute(http.MethodDelete, p+"/{message_id}", s.deleteMessage),
+	}
+}
+
+// addMessage 写入一条消息；uuid 非空且已出现过时返回之前那条（与线上 1 小时内去重一致）。
+func (s *Server) addMessage(r *request, chatID, parentID, rootID string) *message {
+	uuid := str(r.body["uuid"])
+	if uuid != "" {
+		if id, ok := s.state.messageUUIDs[uuid]; ok {
+			return s.state.messages[id]
+		}
+	}
+	m := &message{
+		id:       s.newID("om_"),
+		chatID:   chatID,
+		msgType:  str(r.body["msg_type"]),
+		content:  str(r.body["content"]),
+		parentID: parentID,
+		rootID:   rootID,
+		created:  s.nowMilli(),
+	}
+	s.state.messages[m.id] = m
+	s.state.messageOrder = append(s.state.messageOrder, m.id)
+	if uuid != "" {
+		s.state.messageUUIDs[uuid] = m.id
+	}
+	return m
+}
+
+func (s *Server) createMessage(r *request) (*response, *apiError) {
+	idType := r.query("receive_id_type")
+	if idType == "" {
+		return nil, errInvalid("query receive_id_type is required")
+	}
+	receiveID := str(r.body["receive_id"])
+	if receiveID == "" || str(r.body["msg_type"]) == "" || str(r.body["content"]) == "" {
+		return nil, errInvalid("receive_id, msg_type and content are required")
+	}
+	// 非群聊目标统一落到一个按接收者派生的单聊会话，便于按 chat_id 回读
+	chatID := receiveID
+	if idType != "chat_id" {
+		chatID = "oc_mock_p2p_" + receiveID
+	}
+	return ok(s.addMessage(r, chatID, "", "").toMap())
+}
+
+func (s *Server) message(r *request) (*message, *apiError) {
+	id := r.param("message_id")
+	m := s.state.messages[id]
+	if m == nil || m.deleted {
+		return nil, errNotFound("message", id)
+	}
+	return m, nil
+}
+
+func (s *Server) replyMessage(r *request) (*response, *apiError) {
+	parent, err := s.message(r)
+	if err != nil {
+		return nil, err
+	}
+	if str(r.body["msg_type"]) == "" || str(r.body["content"]) == "" {
+		return nil, errInvalid("msg_type and content are required")
+	}
+	root := parent.rootID
+	if root == "" {
+		root = parent.id
+	}
+	m := s.addMessage(r, parent.chatID, parent.id, root)
+	if inThread, _ := r.body["reply_in_thread"].(bool); inThread {
+		if parent.threadID == "" {
+			parent.threadID = "omt_" + parent.id
+		}
+		m.threadID = parent.threadID
+	}
+	return ok(m.toMap())
+}
+
+func (s *Server) getMessage(r *request) (*response, *apiError) {
+	m, err := s.message(r)
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"items": []any{m.toMap()}})
+}
+
+// listMessages 按 container_id 列出会话消息，支持 start_time / end_time（秒）与 sort_type。
+func (s *Server) listMessages(r *request) (*response, *apiError) {
+	containerID := r.query("container_id")
+	if containerID == "" {
+		return nil, errInvalid("query container_id is required")
+	}
+	threadMode := r.query("container_id_type") == "thread"
+	start, _ := strconv.ParseInt(r.query("start_time"), 10, 64)
+	end, _ := strconv.ParseInt(r.query("end_time"), 10, 64)
+	var items []any
+	for _, id := range s.state.messageOrder {
+		m := s.state.messages[id]
+		if m.deleted {
+			continue
+		}
+		if threadMode && m.threadID != containerID || !threadMode && m.chatID != containerID {
+			continue
+		}
+		sec, _ := strconv.ParseInt(m.created, 10, 64)
+		sec /= 1000
+		if start > 0 && sec < start || end > 0 && sec > end {
+			continue
+		}
+		items = append(items, m.toMap())
+	}
+	if r.query("sort_type") == "ByCreateTimeDesc" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page, hasMore, next := paginate(items, r, 20)
+	return ok(map[string]any{"items": page, "has_more": hasMore, "page_token": next})
+}
+
+// patchMessage 处理卡片更新（PATCH）与消息编辑（PUT）：两者都只替换 content。
+func (s *Server) patchMessage(r *request) (*response, *apiError) {
+	m, err := s.message(r)
+	if err != nil {
+		return nil, err
+	}
+	content := str(r.body["content"])
+	if content == "" {
+		return nil, errInvalid("content is required")
+	}
+	m.content = content
+	if t := str(r.body["msg_type"]); t != "" {
+		m.msgType = t
+	}
+	m.updated = s.nowMilli()
+	m.edited = true
+	return ok(m.toMap())
+}
+
+func (s *Server) deleteMessage(r *request) (*response, *apiError) {
+	m, err := s.message(r)
+	if err != nil {
+		return nil, err
+	}
+	m.deleted = true
+	return ok(nil)
+}
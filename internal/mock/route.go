@@ -0,0 +1,183 @@
+package mock
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/riba2534/feishu-cli/internal/registry"
+)
+
+// route 是一条内存模型路由。pattern 形如 /open-apis/docx/v1/documents/{document_id}/blocks，
+// {name} 段匹配任意单段并以 name 写入 params。
+type route struct {
+	method   string
+	segments []string
+	handler  func(*request) (*response, *apiError)
+}
+
+func newRoute(method, pattern string, h func(*request) (*response, *apiError)) *route {
+	return &route{method: method, segments: splitPath(pattern), handler: h}
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+// matchSegments 按段匹配；返回匹配结果与字面量段数（越多越具体）。
+func matchSegments(pattern, segs []string) (map[string]string, int, bool) {
+	if len(pattern) != len(segs) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	literal := 0
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			v, err := url.PathUnescape(segs[i])
+			if err != nil || v == "" {
+				return nil, 0, false
+			}
+			params[p[1:len(p)-1]] = v
+			continue
+		}
+		if p != segs[i] {
+			return nil, 0, false
+		}
+		literal++
+	}
+	return params, literal, true
+}
+
+// matchRoute 选出方法与路径都匹配、且字面量段最多的路由：
+// blocks/batch_update 优先于 blocks/{block_id}。
+func matchRoute(routes []*route, method, escapedPath string) (*route, map[string]string) {
+	segs := splitPath(escapedPath)
+	var best *route
+	var bestParams map[string]string
+	bestLiteral := -1
+	for _, rt := range routes {
+		if rt.method != method {
+			continue
+		}
+		params, literal, ok := matchSegments(rt.segments, segs)
+		if ok && literal > bestLiteral {
+			best, bestParams, bestLiteral = rt, params, literal
+		}
+	}
+	return best, bestParams
+}
+
+// methodSpec 是注册表中一个方法的路由与校验信息。
+type methodSpec struct {
+	id          string // service.resource.method
+	httpMethod  string
+	segments    []string
+	pathParams  []string
+	queryParams []string // 必填 query 参数
+	bodyFields  []string // 必填顶层 body 字段
+}
+
+// catalog 是由内置注册表构建的方法目录。
+type catalog struct {
+	specs []*methodSpec
+}
+
+// loadCatalog 把 internal/registry 中所有服务的方法展开成可匹配的 methodSpec。
+func loadCatalog() *catalog {
+	c := &catalog{}
+	for _, name := range registry.ListFromMetaProjects() {
+		svc := registry.LoadFromMeta(name)
+		servicePath := registry.GetStrFromMap(svc, "servicePath")
+		resources, _ := svc["resources"].(map[string]interface{})
+		for resName, rawRes := range resources {
+			res, _ := rawRes.(map[string]interface{})
+			methods, _ := res["methods"].(map[string]interface{})
+			for methodName, rawMethod := range methods {
+				m, _ := rawMethod.(map[string]interface{})
+				if m == nil {
+					continue
+				}
+				spec := &methodSpec{
+					id:         name + "." + resName + "." + methodName,
+					httpMethod: strings.ToUpper(registry.GetStrFromMap(m, "httpMethod")),
+					segments:   splitPath(servicePath + "/" + registry.GetStrFromMap(m, "path")),
+				}
+				params, _ := m["parameters"].(map[string]interface{})
+				for pname, rawParam := range params {
+					p, _ := rawParam.(map[string]interface{})
+					required, _ := p["required"].(bool)
+					switch registry.GetStrFromMap(p, "location") {
+					case "path":
+						spec.pathParams = append(spec.pathParams, pname)
+					case "query":
+						if required {
+							spec.queryParams = append(spec.queryParams, pname)
+						}
+					}
+				}
+				body, _ := m["requestBody"].(map[string]interface{})
+				for field, rawField := range body {
+					f, _ := rawField.(map[string]interface{})
+					if required, _ := f["required"].(bool); required {
+						spec.bodyFields = append(spec.bodyFields, field)
+					}
+				}
+				c.specs = append(c.specs, spec)
+			}
+		}
+	}
+	return c
+}
+
+// match 查找与请求匹配的注册表方法，规则同 matchRoute。
+func (c *catalog) match(method, escapedPath string) (*methodSpec, map[string]string) {
+	segs := splitPath(escapedPath)
+	var best *methodSpec
+	var bestParams map[string]string
+	bestLiteral := -1
+	for _, spec := range c.specs {
+		if spec.httpMethod != method {
+			continue
+		}
+		params, literal, ok := matchSegments(spec.segments, segs)
+		if ok && literal > bestLiteral {
+			best, bestParams, bestLiteral = spec, params, literal
+		}
+	}
+	return best, bestParams
+}
+
+// validate 校验必填的 path / query 参数与顶层 body 字段（只校验存在性，不校验取值）。
+// body 为 nil（multipart 上传）时跳过 body 校验。
+func (spec *methodSpec) validate(r *http.Request, pathParams map[string]string, body map[string]any) *apiError {
+	for _, name := range spec.pathParams {
+		if pathParams[name] == "" {
+			return errInvalid("%s is required (%s)", name, spec.id)
+		}
+	}
+	q := r.URL.Query()
+	for _, name := range spec.queryParams {
+		if !q.Has(name) {
+			return errInvalid("query %s is required (%s)", name, spec.id)
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	for _, name := range spec.bodyFields {
+		if _, ok := body[name]; !ok {
+			return errInvalid("body %s is required (%s)", name, spec.id)
+		}
+	}
+	return nil
+}
+
+// registerRoutes 注册全部内存模型路由。
+func (s *Server) registerRoutes() {
+	s.routes = append(s.routes, s.docxRoutes()...)
+	s.routes = append(s.routes, s.driveRoutes()...)
+	s.routes = append(s.routes, s.imRoutes()...)
+	s.routes = append(s.routes, s.sheetsRoutes()...)
+	s.routes = append(s.routes, s.bitableRoutes()...)
+	s.routes = append(s.routes, s.wikiRoutes()...)
+}
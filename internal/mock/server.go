@@ -0,0 +1,351 @@
+// Package mock 提供离线的飞书 OpenAPI 模拟服务（feishu-cli mock serve）。
+//
+// 服务端在进程内维护内存状态：docx 块树、云盘文件、IM 消息、电子表格单元格、多维表格记录，
+// 对这些资源的读写按真实接口的路径与响应结构返回；内置注册表（internal/registry）中登记的
+// 其它方法统一做路径 / 必填参数校验后返回空 data 的成功响应。把 base_url 指向本服务即可
+// 让现有命令在 CI 中端到端离线运行。
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 飞书通用错误码（与线上保持一致，便于脚本按 code 分支）。
+const (
+	codeOK             = 0
+	codeInvalidParam   = 99992402 // field validation failed
+	codeMissingToken   = 99991661 // Missing access token for authorization
+	codeNotFound       = 99992404 // mock 专用：资源不存在
+	codeNotImplemented = 99992501 // mock 专用：路径未登记
+)
+
+// MockTokenTTL 是签发的 tenant/app access token 有效期（秒），与线上一致。
+const MockTokenTTL = 7200
+
+// Server 是 mock OpenAPI 服务，实现 http.Handler。零值不可用，请使用 New。
+type Server struct {
+	mu    sync.Mutex
+	state *state
+	seq   atomic.Int64
+
+	routes  []*route
+	catalog *catalog
+	faults  []*fault
+	now     func() time.Time // 测试注入
+}
+
+// fault 是一条注入的故障：接下来 times 次 method + path 的业务请求直接返回 err。
+type fault struct {
+	method string
+	path   string
+	times  int
+	err    *apiError
+}
+
+// New 创建一个空状态的 mock 服务。
+func New() *Server {
+	s := &Server{
+		state:   newState(),
+		catalog: loadCatalog(),
+		now:     time.Now,
+	}
+	s.registerRoutes()
+	return s
+}
+
+// Reset 清空全部内存状态及尚未触发的故障。
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = newState()
+	s.faults = nil
+}
+
+// InjectFault 让接下来 times 次 method + path（转义后的路径，不含 query）的请求返回 HTTP status 与错误码 code，
+// 不改动内存状态；用于测试限流重试、批次失败等分支。多条故障按注入顺序依次生效。
+func (s *Server) InjectFault(method, path string, times, status, code int, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{method: method, path: path, times: times, err: &apiError{status: status, code: code, msg: msg}})
+}
+
+// takeFault 取出并消耗一次匹配的故障，没有时返回 nil。
+func (s *Server) takeFault(method, path string) *apiError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if f.method != method || f.path != path {
+			continue
+		}
+		if f.times--; f.times <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f.err
+	}
+	return nil
+}
+
+// request 是一次已解析的请求，供各资源处理函数使用。
+type request struct {
+	*http.Request
+	params map[string]string // 路径参数
+	body   map[string]any    // JSON 请求体（非 JSON 时为 nil）
+	base   string            // 本服务对外地址，用于拼接文档 / 表格 URL
+}
+
+func (r *request) param(name string) string { return r.params[name] }
+
+func (r *request) query(name string) string { return r.URL.Query().Get(name) }
+
+// response 是处理函数的返回值：data 写入 {"code":0,"data":...}；raw 非 nil 时原样输出（文件下载）。
+type response struct {
+	data     any
+	raw      []byte
+	filename string
+}
+
+// apiError 是处理函数返回的业务错误，按飞书格式输出 {"code":N,"msg":"..."}。
+type apiError struct {
+	status int
+	code   int
+	msg    string
+}
+
+func (e *apiError) Error() string { return fmt.Sprintf("code=%d, msg=%s", e.code, e.msg) }
+
+func errInvalid(format string, args ...any) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: codeInvalidParam, msg: "field validation failed: " + fmt.Sprintf(format, args...)}
+}
+
+func errNotFound(kind, id string) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: codeNotFound, msg: fmt.Sprintf("mock: %s not found: %s", kind, id)}
+}
+
+func ok(data any) (*response, *apiError) { return &response{data: data}, nil }
+
+// ServeHTTP 分发请求：token 接口 → mock 管理接口 → 鉴权 → 注入的故障 → 内存模型路由 → 注册表兜底。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	switch {
+	case path == "/mock/state" && r.Method == http.MethodGet:
+		s.mu.Lock()
+		snap := s.state.snapshot()
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, snap)
+		return
+	case path == "/mock/reset" && r.Method == http.MethodPost:
+		s.Reset()
+		writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "success"})
+		return
+	case path == "/mock/fault" && r.Method == http.MethodPost:
+		s.serveFault(w, r)
+		return
+	case strings.HasPrefix(path, "/open-apis/auth/v3/"):
+		s.serveToken(w, r, strings.TrimPrefix(path, "/open-apis/auth/v3/"))
+		return
+	case !strings.HasPrefix(path, "/open-apis/"):
+		writeError(w, &apiError{status: http.StatusNotFound, code: codeNotImplemented, msg: "mock: unknown path " + r.URL.Path})
+		return
+	}
+
+	if !hasBearer(r) {
+		writeError(w, &apiError{status: http.StatusBadRequest, code: codeMissingToken, msg: "Missing access token for authorization. Please make a request with token attached."})
+		return
+	}
+
+	if err := s.takeFault(r.Method, path); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req := &request{Request: r, base: baseURL(r)}
+	if isJSON(r) {
+		body, err := decodeBody(r)
+		if err != nil {
+			writeError(w, errInvalid("invalid JSON body: %v", err))
+			return
+		}
+		req.body = body
+	}
+
+	rt, params := matchRoute(s.routes, r.Method, path)
+	spec, specParams := s.catalog.match(r.Method, path)
+	if spec != nil {
+		body := req.body
+		if body == nil && !isMultipart(r) {
+			body = map[string]any{}
+		}
+		if err := spec.validate(r, specParams, body); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	switch {
+	case rt != nil:
+		req.params = params
+		s.mu.Lock()
+		resp, apiErr := rt.handler(req)
+		s.mu.Unlock()
+		if apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+		writeResponse(w, resp)
+	case spec != nil:
+		// 注册表中登记但未建模的方法：参数合法即返回空 data
+		writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "success", "data": map[string]any{}})
+	default:
+		writeError(w, &apiError{status: http.StatusNotFound, code: codeNotImplemented, msg: fmt.Sprintf("mock: %s %s is not implemented", r.Method, r.URL.Path)})
+	}
+}
+
+// serveFault 处理 POST /mock/fault：{"method","path","times","status","code","msg"}，times 缺省 1、status 缺省 500。
+func (s *Server) serveFault(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody(r)
+	if err != nil {
+		writeError(w, errInvalid("invalid JSON body: %v", err))
+		return
+	}
+	method, path := strings.ToUpper(str(body["method"])), str(body["path"])
+	if method == "" || path == "" {
+		writeError(w, errInvalid("method and path are required"))
+		return
+	}
+	times, valid := toInt(body["times"])
+	if !valid || times <= 0 {
+		times = 1
+	}
+	status, valid := toInt(body["status"])
+	if !valid || status == 0 {
+		status = http.StatusInternalServerError
+	}
+	code, _ := toInt(body["code"])
+	msg := str(body["msg"])
+	if msg == "" {
+		msg = "mock: injected fault"
+	}
+	s.InjectFault(method, path, times, status, code, msg)
+	writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "success"})
+}
+
+// serveToken 签发 tenant_access_token / app_access_token；任何非空 app_id + app_secret 都视为合法。
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodPost {
+		writeError(w, &apiError{status: http.StatusNotFound, code: codeNotImplemented, msg: "mock: unknown auth endpoint"})
+		return
+	}
+	body, err := decodeBody(r)
+	if err != nil {
+		writeError(w, errInvalid("invalid JSON body: %v", err))
+		return
+	}
+	appID, _ := body["app_id"].(string)
+	appSecret, _ := body["app_secret"].(string)
+	if appID == "" || appSecret == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"code": 10003, "msg": "invalid param"})
+		return
+	}
+	token := fmt.Sprintf("t-mock-%s-%d", appID, s.seq.Add(1))
+	switch name {
+	case "tenant_access_token/internal":
+		writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "ok", "tenant_access_token": token, "expire": MockTokenTTL})
+	case "app_access_token/internal":
+		writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "ok", "app_access_token": "a" + token[1:], "tenant_access_token": token, "expire": MockTokenTTL})
+	default:
+		writeError(w, &apiError{status: http.StatusNotFound, code: codeNotImplemented, msg: "mock: unknown auth endpoint " + name})
+	}
+}
+
+// newID 生成形如 <prefix>mock0000000001 的资源 ID，单调递增、进程内唯一。
+func (s *Server) newID(prefix string) string {
+	return fmt.Sprintf("%smock%010d", prefix, s.seq.Add(1))
+}
+
+// nowSec / nowMilli 返回字符串形式的时间戳（飞书接口中时间字段多为字符串）。
+func (s *Server) nowSec() string   { return strconv.FormatInt(s.now().Unix(), 10) }
+func (s *Server) nowMilli() string { return strconv.FormatInt(s.now().UnixMilli(), 10) }
+
+func hasBearer(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	token, found := strings.CutPrefix(auth, "Bearer ")
+	return found && strings.TrimSpace(token) != ""
+}
+
+func isJSON(r *http.Request) bool {
+	if r.Body == nil || r.ContentLength == 0 {
+		return false
+	}
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt == "" || mt == "application/json"
+}
+
+func isMultipart(r *http.Request) bool {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return strings.HasPrefix(mt, "multipart/")
+}
+
+// decodeBody 解析 JSON 请求体；数字保留为 json.Number，原样写回时不丢精度。
+func decodeBody(r *http.Request) (map[string]any, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]any{}, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var body map[string]any
+	if err := dec.Decode(&body); err != nil {
+		return nil, err
+	}
+	if body == nil {
+		body = map[string]any{}
+	}
+	return body, nil
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, map[string]any{"code": e.code, "msg": e.msg})
+}
+
+func writeResponse(w http.ResponseWriter, resp *response) {
+	if resp.raw != nil {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resp.filename}))
+		w.Header().Set("Content-Length", strconv.Itoa(len(resp.raw)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(resp.raw)
+		return
+	}
+	data := resp.data
+	if data == nil {
+		data = map[string]any{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": codeOK, "msg": "success", "data": data})
+}
@@ -0,0 +1,344 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// result 是一次 mock 调用的状态码与解析后的响应体。
+type result struct {
+	status int
+	body   map[string]any
+}
+
+// call 发起一次带 Bearer 的 JSON 请求。
+func call(t *testing.T, srv *httptest.Server, method, path string, body any) result {
+	t.Helper()
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, srv.URL+path, rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer t-test")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	return do(t, req)
+}
+
+func do(t *testing.T, req *http.Request) result {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("%s %s: decode: %v", req.Method, req.URL.Path, err)
+	}
+	return result{resp.StatusCode, out}
+}
+
+// mustData 断言 code=0 并返回 data。
+func mustData(t *testing.T, res result) map[string]any {
+	t.Helper()
+	if res.status != http.StatusOK || res.body["code"] != float64(0) {
+		t.Fatalf("status=%d body=%v", res.status, res.body)
+	}
+	data, _ := res.body["data"].(map[string]any)
+	return data
+}
+
+func TestTokenAndAuth(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/open-apis/auth/v3/tenant_access_token/internal",
+		bytes.NewBufferString(`{"app_id":"cli_a","app_secret":"s"}`))
+	req.Header.Set("Content-Type", "application/json")
+	out := do(t, req).body
+	if out["code"] != float64(0) || out["tenant_access_token"] == "" || out["expire"] != float64(MockTokenTTL) {
+		t.Fatalf("token response = %v", out)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/open-apis/im/v1/messages?container_id=oc_1", nil)
+	res := do(t, req)
+	if res.status != http.StatusBadRequest || res.body["code"] != float64(codeMissingToken) {
+		t.Fatalf("无 token 应被拒绝: status=%d body=%v", res.status, res.body)
+	}
+}
+
+func TestRegistryFallback(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	// 注册表登记但未建模：缺必填字段报参数错误，补齐后返回空 data
+	res := call(t, srv, http.MethodPost, "/open-apis/task/v2/tasks", map[string]any{})
+	if res.status != http.StatusBadRequest || res.body["code"] != float64(codeInvalidParam) {
+		t.Fatalf("缺少 summary 应报参数错误: status=%d body=%v", res.status, res.body)
+	}
+	mustData(t, call(t, srv, http.MethodPost, "/open-apis/task/v2/tasks", map[string]any{"summary": "x"}))
+
+	res = call(t, srv, http.MethodGet, "/open-apis/nope/v1/things", nil)
+	if res.status != http.StatusNotFound || res.body["code"] != float64(codeNotImplemented) {
+		t.Fatalf("未知接口应返回 404: status=%d body=%v", res.status, res.body)
+	}
+}
+
+func TestDocxViaSDK(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	cli := lark.NewClient("cli_mock", "mock", lark.WithOpenBaseUrl(srv.URL))
+	ctx := context.Background()
+
+	created, err := cli.Docx.Document.Create(ctx, larkdocx.NewCreateDocumentReqBuilder().
+		Body(larkdocx.NewCreateDocumentReqBodyBuilder().Title("测试").Build()).Build())
+	if err != nil || !created.Success() {
+		t.Fatalf("create: err=%v resp=%+v", err, created)
+	}
+	docID := *created.Data.Document.DocumentId
+
+	text := "hello mock"
+	child := larkdocx.NewBlockBuilder().BlockType(2).
+		Text(larkdocx.NewTextBuilder().Elements([]*larkdocx.TextElement{
+			larkdocx.NewTextElementBuilder().TextRun(larkdocx.NewTextRunBuilder().Content(text).Build()).Build(),
+		}).Build()).Build()
+	added, err := cli.Docx.DocumentBlockChildren.Create(ctx, larkdocx.NewCreateDocumentBlockChildrenReqBuilder().
+		DocumentId(docID).BlockId(docID).
+		Body(larkdocx.NewCreateDocumentBlockChildrenReqBodyBuilder().Children([]*larkdocx.Block{child}).Build()).Build())
+	if err != nil || !added.Success() || len(added.Data.Children) != 1 {
+		t.Fatalf("children create: err=%v resp=%+v", err, added)
+	}
+
+	raw, err := cli.Docx.Document.RawContent(ctx, larkdocx.NewRawContentDocumentReqBuilder().DocumentId(docID).Build())
+	if err != nil || !raw.Success() || *raw.Data.Content != "测试\n"+text+"\n" {
+		t.Fatalf("raw_content: err=%v resp=%+v", err, raw)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	send := map[string]any{"receive_id": "oc_1", "msg_type": "text", "content": `{"text":"hi"}`, "uuid": "u1"}
+	first := mustData(t, call(t, srv, http.MethodPost, "/open-apis/im/v1/messages?receive_id_type=chat_id", send))
+	again := mustData(t, call(t, srv, http.MethodPost, "/open-apis/im/v1/messages?receive_id_type=chat_id", send))
+	if first["message_id"] != again["message_id"] {
+		t.Fatalf("相同 uuid 应幂等: %v vs %v", first["message_id"], again["message_id"])
+	}
+	id := first["message_id"].(string)
+	mustData(t, call(t, srv, http.MethodPost, "/open-apis/im/v1/messages/"+id+"/reply",
+		map[string]any{"msg_type": "text", "content": `{"text":"re"}`}))
+
+	list := mustData(t, call(t, srv, http.MethodGet, "/open-apis/im/v1/messages?container_id_type=chat&container_id=oc_1&sort_type=ByCreateTimeDesc", nil))
+	items := list["items"].([]any)
+	if len(items) != 2 || items[0].(map[string]any)["parent_id"] != id {
+		t.Fatalf("history = %v", items)
+	}
+
+	mustData(t, call(t, srv, http.MethodDelete, "/open-apis/im/v1/messages/"+id, nil))
+	if res := call(t, srv, http.MethodGet, "/open-apis/im/v1/messages/"+id, nil); res.body["code"] != float64(codeNotFound) {
+		t.Fatalf("删除后读取应报 not found: %v", res.body)
+	}
+}
+
+func TestSheetValues(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	data := mustData(t, call(t, srv, http.MethodPost, "/open-apis/sheets/v3/spreadsheets", map[string]any{"title": "t"}))
+	token := data["spreadsheet"].(map[string]any)["spreadsheet_token"].(string)
+	sheets := mustData(t, call(t, srv, http.MethodGet, "/open-apis/sheets/v3/spreadsheets/"+token+"/sheets/query", nil))
+	sheetID := sheets["sheets"].([]any)[0].(map[string]any)["sheet_id"].(string)
+
+	mustData(t, call(t, srv, http.MethodPut, "/open-apis/sheets/v2/spreadsheets/"+token+"/values", map[string]any{
+		"valueRange": map[string]any{"range": sheetID + "!A1:B2", "values": [][]any{{"a", 1}, {"b", 2}}},
+	}))
+	mustData(t, call(t, srv, http.MethodPost, "/open-apis/sheets/v2/spreadsheets/"+token+"/values_append", map[string]any{
+		"valueRange": map[string]any{"range": sheetID + "!A1:B1", "values": [][]any{{"c", 3}}},
+	}))
+
+	got := mustData(t, call(t, srv, http.MethodGet, "/open-apis/sheets/v2/spreadsheets/"+token+"/values/"+sheetID+"!A1:B3", nil))
+	values, _ := json.Marshal(got["valueRange"].(map[string]any)["values"])
+	if string(values) != `[["a",1],["b",2],["c",3]]` {
+		t.Fatalf("values = %s", values)
+	}
+}
+
+func TestBitableRecords(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	const p = "/open-apis/base/v3/bases/bas1/tables/tbl1/records"
+
+	created := mustData(t, call(t, srv, http.MethodPost, p+"/batch_create", map[string]any{
+		"create_records": []any{map[string]any{"名称": "Alpha", "备注": "B"}, map[string]any{"名称": "Beta"}},
+	}))
+	if ids := created["record_id_list"].([]any); len(ids) != 2 {
+		t.Fatalf("record_id_list = %v", ids)
+	}
+
+	found := mustData(t, call(t, srv, http.MethodPost, p+"/search", map[string]any{
+		"keyword": "B", "search_fields": []any{"名称"}, "select_fields": []any{"名称"},
+	}))
+	items := found["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("search 应只在 search_fields 中匹配: %v", items)
+	}
+	if fields := items[0].(map[string]any)["fields"].(map[string]any); len(fields) != 1 || fields["名称"] != "Beta" {
+		t.Fatalf("select_fields 投影 = %v", fields)
+	}
+
+	page := mustData(t, call(t, srv, http.MethodGet, p+"?offset=1&limit=1", nil))
+	if len(page["items"].([]any)) != 1 || page["has_more"] != false {
+		t.Fatalf("offset/limit 分页 = %v", page)
+	}
+}
+
+func TestBitableFieldsAndViews(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	const p = "/open-apis/base/v3/bases/bas1/tables"
+
+	tbl := mustData(t, call(t, srv, http.MethodPost, p, map[string]any{"name": "任务"}))
+	tp := p + "/" + tbl["table_id"].(string)
+
+	fields := mustData(t, call(t, srv, http.MethodGet, tp+"/fields", nil))["items"].([]any)
+	primary := fields[0].(map[string]any)
+	if len(fields) != 1 || primary["is_primary"] != true {
+		t.Fatalf("新表应自带主字段: %v", fields)
+	}
+	res := call(t, srv, http.MethodDelete, tp+"/fields/"+primary["id"].(string), nil)
+	if res.body["code"] == float64(0) {
+		t.Fatal("主字段不应可删除")
+	}
+
+	status := mustData(t, call(t, srv, http.MethodPost, tp+"/fields", map[string]any{
+		"name": "状态", "type": "select", "options": []any{map[string]any{"name": "待办"}},
+	}))
+	updated := mustData(t, call(t, srv, http.MethodPut, tp+"/fields/"+status["id"].(string), map[string]any{
+		"name": "状态", "type": "select", "multiple": true,
+	}))
+	if _, ok := updated["options"]; ok || updated["multiple"] != true {
+		t.Fatalf("PUT 应全量替换字段属性: %v", updated)
+	}
+
+	views := mustData(t, call(t, srv, http.MethodGet, tp+"/views", nil))["items"].([]any)
+	viewID := views[0].(map[string]any)["id"].(string)
+	if viewID != tbl["default_view_id"] {
+		t.Fatalf("默认视图 = %v, default_view_id = %v", views, tbl["default_view_id"])
+	}
+	sortCfg := map[string]any{"sort_config": []any{map[string]any{"field_id": status["id"], "desc": true}}}
+	mustData(t, call(t, srv, http.MethodPut, tp+"/views/"+viewID+"/sort", sortCfg))
+	got := mustData(t, call(t, srv, http.MethodGet, tp+"/views/"+viewID+"/sort", nil))
+	if len(got["sort_config"].([]any)) != 1 {
+		t.Fatalf("sort 配置 = %v", got)
+	}
+	if filter := mustData(t, call(t, srv, http.MethodGet, tp+"/views/"+viewID+"/filter", nil)); len(filter) != 0 {
+		t.Fatalf("未设置的配置应为空对象: %v", filter)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	const p = "/open-apis/base/v3/bases/bas1/tables/tbl1/records/batch_create"
+	body := map[string]any{"create_records": []any{map[string]any{"名称": "A"}}}
+
+	s.InjectFault(http.MethodPost, p, 1, http.StatusTooManyRequests, 99991400, "request trigger frequency limit")
+	res := call(t, srv, http.MethodPost, p, body)
+	if res.status != http.StatusTooManyRequests {
+		t.Fatalf("第一次应命中限流故障: %d %v", res.status, res.body)
+	}
+	// 管理接口注入的故障排在后面：HTTP 200 + 业务错误码
+	res = call(t, srv, http.MethodPost, "/mock/fault", map[string]any{
+		"method": "post", "path": p, "status": 200, "code": 1254045, "msg": "field not found",
+	})
+	if res.body["code"] != float64(0) {
+		t.Fatalf("/mock/fault = %v", res.body)
+	}
+	res = call(t, srv, http.MethodPost, p, body)
+	if res.status != http.StatusOK || res.body["code"] != float64(1254045) {
+		t.Fatalf("第二次应命中业务错误: %d %v", res.status, res.body)
+	}
+	mustData(t, call(t, srv, http.MethodPost, p, body))
+
+	state := mustData(t, call(t, srv, http.MethodGet, "/open-apis/base/v3/bases/bas1/tables/tbl1/records", nil))
+	if items := state["items"].([]any); len(items) != 1 {
+		t.Fatalf("故障请求不应写入记录: %v", items)
+	}
+}
+
+func TestDriveUploadDownload(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("file_name", "a.txt")
+	_ = mw.WriteField("parent_type", "explorer")
+	_ = mw.WriteField("size", "5")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	_, _ = fw.Write([]byte("hello"))
+	_ = mw.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/open-apis/drive/v1/files/upload_all", &buf)
+	req.Header.Set("Authorization", "Bearer t-test")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	token := mustData(t, do(t, req))["file_token"].(string)
+
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/open-apis/drive/v1/files/"+token+"/download", nil)
+	req.Header.Set("Authorization", "Bearer t-test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello" {
+		t.Fatalf("download = %q", body)
+	}
+}
+
+func TestWikiNodes(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	const p = "/open-apis/wiki/v2/spaces/sp1/nodes"
+
+	parent := mustData(t, call(t, srv, http.MethodPost, p, map[string]any{"title": "父", "obj_type": "docx", "node_type": "origin"}))["node"].(map[string]any)
+	child := mustData(t, call(t, srv, http.MethodPost, p, map[string]any{"title": "子", "obj_type": "docx", "node_type": "origin", "parent_node_token": parent["node_token"]}))["node"].(map[string]any)
+	docID := child["obj_token"].(string)
+	if doc := mustData(t, call(t, srv, http.MethodGet, "/open-apis/docx/v1/documents/"+docID, nil)); doc["document"] == nil {
+		t.Fatalf("docx 节点应同时生成文档: %v", doc)
+	}
+
+	mustData(t, call(t, srv, http.MethodPost, p+"/"+child["node_token"].(string)+"/update_title", map[string]any{"title": "新标题"}))
+	got := mustData(t, call(t, srv, http.MethodGet, "/open-apis/wiki/v2/spaces/get_node?token="+docID, nil))["node"].(map[string]any)
+	if got["title"] != "新标题" || got["parent_node_token"] != parent["node_token"] {
+		t.Fatalf("get_node = %v", got)
+	}
+
+	items := mustData(t, call(t, srv, http.MethodGet, p+"?parent_node_token="+parent["node_token"].(string), nil))["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["node_token"] != child["node_token"] {
+		t.Fatalf("子节点列表 = %v", items)
+	}
+	if res := call(t, srv, http.MethodPost, p, map[string]any{"title": "x", "obj_type": "docx", "node_type": "origin", "parent_node_token": "wikcnMissing"}); res.status == http.StatusOK {
+		t.Fatal("父节点不存在时应报错")
+	}
+}
@@ -0,0 +1,586 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// mock 工作表默认网格大小，与线上新建工作表一致。
+const (
+	defaultSheetRows = 200
+	defaultSheetCols = 20
+)
+
+// spreadsheet 是一个电子表格；sheets 按 index 排列。
+type spreadsheet struct {
+	token    string
+	title    string
+	folder   string
+	url      string
+	revision int
+	sheets   []*sheet
+}
+
+// sheet 是一个工作表，cells 为按行存储的稀疏网格（行 / 列按需增长）。
+type sheet struct {
+	id    string
+	title string
+	cells [][]any
+}
+
+// cellRange 是解析后的 A1 区域，行列均为 0 起始；endRow / endCol 为 -1 表示延伸到已用区域末尾。
+type cellRange struct {
+	sheet    *sheet
+	startRow int
+	startCol int
+	endRow   int
+	endCol   int
+}
+
+func (s *Server) sheetsRoutes() []*route {
+	const v3 = "/open-apis/sheets/v3/spreadsheets"
+	const v2 = "/open-apis/sheets/v2/spreadsheets/{spreadsheet_token}"
+	return []*route{
+		newRoute(http.MethodPost, v3, s.createSpreadsheet),
+		newRoute(http.MethodGet, v3+"/{spreadsheet_token}", s.getSpreadsheet),
+		newRoute(http.MethodPatch, v3+"/{spreadsheet_token}", s.patchSpreadsheet),
+		newRoute(http.MethodGet, v3+"/{spreadsheet_token}/sheets/query", s.querySheets),
+		newRoute(http.MethodGet, v3+"/{spreadsheet_token}/sheets/{sheet_id}", s.getSheet),
+		newRoute(http.MethodGet, v2+"/metainfo", s.sheetMetaInfo),
+		newRoute(http.MethodGet, v2+"/values/{range}", s.readValues),
+		newRoute(http.MethodPut, v2+"/values", s.writeValues),
+		newRoute(http.MethodGet, v2+"/values_batch_get", s.batchReadValues),
+		newRoute(http.MethodPost, v2+"/values_batch_update", s.batchWriteValues),
+		newRoute(http.MethodPost, v2+"/values_append", s.appendValues),
+		newRoute(http.MethodPost, v2+"/values_prepend", s.prependValues),
+		newRoute(http.MethodPost, v2+"/sheets_batch_update", s.batchUpdateSheets),
+	}
+}
+
+func (s *Server) newSheet(title string) *sheet {
+	// 线上 sheet_id 为 6 位短 ID
+	return &sheet{id: fmt.Sprintf("s%05d", s.seq.Add(1)), title: title}
+}
+
+func (s *Server) createSpreadsheet(r *request) (*response, *apiError) {
+	body := asMap(r.body["spreadsheet"])
+	if body == nil {
+		body = r.body
+	}
+	token := s.newID("shtcn")
+	ss := &spreadsheet{
+		token:    token,
+		title:    str(body["title"]),
+		folder:   str(body["folder_token"]),
+		url:      r.base + "/sheets/" + token,
+		revision: 1,
+	}
+	ss.sheets = []*sheet{s.newSheet("Sheet1")}
+	s.state.spreadsheets[token] = ss
+	s.state.files[token] = &driveFile{token: token, name: ss.title, fileType: "sheet", parent: ss.folder, created: s.nowSec(), url: ss.url}
+	return ok(map[string]any{"spreadsheet": map[string]any{
+		"title":             ss.title,
+		"folder_token":      ss.folder,
+		"url":               ss.url,
+		"spreadsheet_token": token,
+	}})
+}
+
+func (s *Server) spreadsheet(r *request) (*spreadsheet, *apiError) {
+	token := r.param("spreadsheet_token")
+	ss := s.state.spreadsheets[token]
+	if ss == nil {
+		return nil, errNotFound("spreadsheet", token)
+	}
+	return ss, nil
+}
+
+func (s *Server) getSpreadsheet(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{"spreadsheet": map[string]any{
+		"title":    ss.title,
+		"owner_id": "ou_mock_owner",
+		"token":    ss.token,
+		"url":      ss.url,
+	}})
+}
+
+func (s *Server) patchSpreadsheet(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	if title := str(r.body["title"]); title != "" {
+		ss.title = title
+		if f := s.state.files[ss.token]; f != nil {
+			f.name = title
+		}
+	}
+	return ok(nil)
+}
+
+func (ss *spreadsheet) sheetMeta(i int) map[string]any {
+	sh := ss.sheets[i]
+	rows, cols := sh.gridSize()
+	return map[string]any{
+		"sheet_id":      sh.id,
+		"title":         sh.title,
+		"index":         i,
+		"hidden":        false,
+		"resource_type": "sheet",
+		"grid_properties": map[string]any{
+			"frozen_row_count":    0,
+			"frozen_column_count": 0,
+			"row_count":           rows,
+			"column_count":        cols,
+		},
+	}
+}
+
+func (s *Server) querySheets(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	sheets := make([]any, len(ss.sheets))
+	for i := range ss.sheets {
+		sheets[i] = ss.sheetMeta(i)
+	}
+	return ok(map[string]any{"sheets": sheets})
+}
+
+func (s *Server) getSheet(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	for i, sh := range ss.sheets {
+		if sh.id == r.param("sheet_id") {
+			return ok(map[string]any{"sheet": ss.sheetMeta(i)})
+		}
+	}
+	return nil, errNotFound("sheet", r.param("sheet_id"))
+}
+
+func (s *Server) sheetMetaInfo(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	sheets := make([]any, len(ss.sheets))
+	for i, sh := range ss.sheets {
+		rows, cols := sh.gridSize()
+		sheets[i] = map[string]any{"sheetId": sh.id, "title": sh.title, "index": i, "rowCount": rows, "columnCount": cols}
+	}
+	return ok(map[string]any{
+		"spreadsheetToken": ss.token,
+		"properties":       map[string]any{"title": ss.title, "revision": ss.revision, "sheetCount": len(ss.sheets), "ownerUser": 0},
+		"sheets":           sheets,
+	})
+}
+
+func (s *Server) readValues(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := ss.parseRange(r.param("range"))
+	if err != nil {
+		return nil, err
+	}
+	return ok(map[string]any{
+		"revision":         ss.revision,
+		"spreadsheetToken": ss.token,
+		"valueRange":       ss.valueRange(rng),
+	})
+}
+
+func (s *Server) batchReadValues(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	var ranges []string
+	for _, v := range r.URL.Query()["ranges"] {
+		ranges = append(ranges, strings.Split(v, ",")...)
+	}
+	if len(ranges) == 0 {
+		return nil, errInvalid("query ranges is required")
+	}
+	var valueRanges []any
+	total := 0
+	for _, raw := range ranges {
+		rng, err := ss.parseRange(raw)
+		if err != nil {
+			return nil, err
+		}
+		vr := ss.valueRange(rng)
+		for _, row := range vr["values"].([][]any) {
+			total += len(row)
+		}
+		valueRanges = append(valueRanges, vr)
+	}
+	return ok(map[string]any{
+		"revision":         ss.revision,
+		"spreadsheetToken": ss.token,
+		"totalCells":       total,
+		"valueRanges":      valueRanges,
+	})
+}
+
+func (s *Server) writeValues(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ss.write(asMap(r.body["valueRange"]))
+	if err != nil {
+		return nil, err
+	}
+	return ok(result)
+}
+
+func (s *Server) batchWriteValues(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	var responses []any
+	for _, raw := range asSlice(r.body["valueRanges"]) {
+		result, err := ss.write(asMap(raw))
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, result)
+	}
+	return ok(map[string]any{"responses": responses, "revision": ss.revision, "spreadsheetToken": ss.token})
+}
+
+// appendValues 把数据写到区域所在列已用区域的下一行（insertDataOption 对内存模型无差别）。
+func (s *Server) appendValues(r *request) (*response, *apiError) {
+	return s.insertValues(r, false)
+}
+
+// prependValues 在区域起始行之前插入数据，原有行整体下移。
+func (s *Server) prependValues(r *request) (*response, *apiError) {
+	return s.insertValues(r, true)
+}
+
+func (s *Server) insertValues(r *request, prepend bool) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	vr := asMap(r.body["valueRange"])
+	rng, err := ss.parseRange(str(vr["range"]))
+	if err != nil {
+		return nil, err
+	}
+	values := toValues(vr["values"])
+	sh := rng.sheet
+	row := rng.startRow
+	if prepend {
+		grown := make([][]any, len(values))
+		if row > len(sh.cells) {
+			row = len(sh.cells)
+		}
+		sh.cells = append(sh.cells[:row], append(grown, sh.cells[row:]...)...)
+	} else {
+		for row < len(sh.cells) && !rowEmpty(sh.cells[row]) {
+			row++
+		}
+	}
+	target := cellRange{sheet: sh, startRow: row, startCol: rng.startCol, endRow: -1, endCol: -1}
+	result := ss.writeAt(target, values)
+	tableEnd := row + len(values)
+	return ok(map[string]any{
+		"revision":         ss.revision,
+		"spreadsheetToken": ss.token,
+		"tableRange":       formatRange(sh.id, rng.startRow, rng.startCol, tableEnd-1, rng.startCol+maxWidth(values)-1),
+		"updates":          result,
+	})
+}
+
+// batchUpdateSheets 支持 addSheet / deleteSheet / copySheet / updateSheet 四类请求。
+func (s *Server) batchUpdateSheets(r *request) (*response, *apiError) {
+	ss, err := s.spreadsheet(r)
+	if err != nil {
+		return nil, err
+	}
+	var replies []any
+	for _, raw := range asSlice(r.body["requests"]) {
+		req := asMap(raw)
+		switch {
+		case req["addSheet"] != nil:
+			props := asMap(asMap(req["addSheet"])["properties"])
+			sh := s.newSheet(str(props["title"]))
+			index := len(ss.sheets)
+			if n, ok := toInt(props["index"]); ok && n >= 0 && n < len(ss.sheets) {
+				index = n
+			}
+			ss.sheets = append(ss.sheets[:index], append([]*sheet{sh}, ss.sheets[index:]...)...)
+			replies = append(replies, map[string]any{"addSheet": map[string]any{"properties": map[string]any{"sheetId": sh.id, "title": sh.title, "index": index}}})
+		case req["deleteSheet"] != nil:
+			id := str(asMap(req["deleteSheet"])["sheetId"])
+			i := ss.sheetIndex(id)
+			if i < 0 {
+				return nil, errNotFound("sheet", id)
+			}
+			ss.sheets = append(ss.sheets[:i], ss.sheets[i+1:]...)
+			replies = append(replies, map[string]any{"deleteSheet": map[string]any{"result": true, "sheetId": id}})
+		case req["copySheet"] != nil:
+			cp := asMap(req["copySheet"])
+			id := str(asMap(cp["source"])["sheetId"])
+			i := ss.sheetIndex(id)
+			if i < 0 {
+				return nil, errNotFound("sheet", id)
+			}
+			sh := s.newSheet(str(asMap(cp["destination"])["title"]))
+			if sh.title == "" {
+				sh.title = ss.sheets[i].title + " (副本)"
+			}
+			for _, row := range ss.sheets[i].cells {
+				sh.cells = append(sh.cells, append([]any(nil), row...))
+			}
+			ss.sheets = append(ss.sheets, sh)
+			replies = append(replies, map[string]any{"copySheet": map[string]any{"properties": map[string]any{"sheetId": sh.id, "title": sh.title, "index": len(ss.sheets) - 1}}})
+		case req["updateSheet"] != nil:
+			props := asMap(asMap(req["updateSheet"])["properties"])
+			id := str(props["sheetId"])
+			i := ss.sheetIndex(id)
+			if i < 0 {
+				return nil, errNotFound("sheet", id)
+			}
+			if title := str(props["title"]); title != "" {
+				ss.sheets[i].title = title
+			}
+			replies = append(replies, map[string]any{"updateSheet": map[string]any{"properties": props}})
+		default:
+			return nil, errInvalid("unsupported sheets_batch_update request")
+		}
+	}
+	ss.revision++
+	return ok(map[string]any{"replies": replies})
+}
+
+func (ss *spreadsheet) sheetIndex(ref string) int {
+	for i, sh := range ss.sheets {
+		if sh.id == ref || sh.title == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// write 按 valueRange{range, values} 写入，返回 v2 写接口的响应体。
+func (ss *spreadsheet) write(vr map[string]any) (map[string]any, *apiError) {
+	if vr == nil {
+		return nil, errInvalid("valueRange is required")
+	}
+	rng, err := ss.parseRange(str(vr["range"]))
+	if err != nil {
+		return nil, err
+	}
+	values := toValues(vr["values"])
+	if rng.endRow >= 0 && len(values) > rng.endRow-rng.startRow+1 ||
+		rng.endCol >= 0 && maxWidth(values) > rng.endCol-rng.startCol+1 {
+		return nil, errInvalid("values exceed range %s", str(vr["range"]))
+	}
+	return ss.writeAt(rng, values), nil
+}
+
+func (ss *spreadsheet) writeAt(rng cellRange, values [][]any) map[string]any {
+	sh := rng.sheet
+	cells := 0
+	for i, row := range values {
+		for j, v := range row {
+			sh.set(rng.startRow+i, rng.startCol+j, v)
+			cells++
+		}
+	}
+	ss.revision++
+	width := maxWidth(values)
+	return map[string]any{
+		"revision":         ss.revision,
+		"spreadsheetToken": ss.token,
+		"updatedRange":     formatRange(sh.id, rng.startRow, rng.startCol, rng.startRow+len(values)-1, rng.startCol+width-1),
+		"updatedRows":      len(values),
+		"updatedColumns":   width,
+		"updatedCells":     cells,
+	}
+}
+
+// valueRange 读取区域内的值，空单元格为 null；开放区域截到已用范围。
+func (ss *spreadsheet) valueRange(rng cellRange) map[string]any {
+	sh := rng.sheet
+	endRow, endCol := rng.endRow, rng.endCol
+	usedRows, usedCols := sh.usedSize()
+	if endRow < 0 {
+		endRow = usedRows - 1
+	}
+	if endCol < 0 {
+		endCol = usedCols - 1
+	}
+	values := [][]any{}
+	for i := rng.startRow; i <= endRow; i++ {
+		row := make([]any, 0, endCol-rng.startCol+1)
+		for j := rng.startCol; j <= endCol; j++ {
+			row = append(row, sh.get(i, j))
+		}
+		values = append(values, row)
+	}
+	return map[string]any{
+		"majorDimension": "ROWS",
+		"range":          formatRange(sh.id, rng.startRow, rng.startCol, endRow, endCol),
+		"revision":       ss.revision,
+		"values":         values,
+	}
+}
+
+func (ss *spreadsheet) snapshot() map[string]any {
+	sheets := make([]any, len(ss.sheets))
+	for i, sh := range ss.sheets {
+		sheets[i] = map[string]any{"sheet_id": sh.id, "title": sh.title, "values": sh.cells}
+	}
+	return map[string]any{"title": ss.title, "revision": ss.revision, "sheets": sheets}
+}
+
+func (sh *sheet) get(row, col int) any {
+	if row < len(sh.cells) && col < len(sh.cells[row]) {
+		return sh.cells[row][col]
+	}
+	return nil
+}
+
+func (sh *sheet) set(row, col int, v any) {
+	for len(sh.cells) <= row {
+		sh.cells = append(sh.cells, nil)
+	}
+	for len(sh.cells[row]) <= col {
+		sh.cells[row] = append(sh.cells[row], nil)
+	}
+	sh.cells[row][col] = v
+}
+
+// usedSize 返回已写入数据的行数与列数。
+func (sh *sheet) usedSize() (int, int) {
+	rows, cols := 0, 0
+	for i, row := range sh.cells {
+		if !rowEmpty(row) {
+			rows = i + 1
+		}
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	return rows, cols
+}
+
+// gridSize 返回网格大小：至少为新建工作表的默认大小。
+func (sh *sheet) gridSize() (int, int) {
+	rows, cols := sh.usedSize()
+	return max(rows, defaultSheetRows), max(cols, defaultSheetCols)
+}
+
+func rowEmpty(row []any) bool {
+	for _, v := range row {
+		if v != nil && v != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func maxWidth(values [][]any) int {
+	w := 0
+	for _, row := range values {
+		w = max(w, len(row))
+	}
+	return w
+}
+
+func toValues(v any) [][]any {
+	var out [][]any
+	for _, row := range asSlice(v) {
+		out = append(out, asSlice(row))
+	}
+	return out
+}
+
+// parseRange 解析 <sheetId|title>[!A1[:C3]]，支持整列（A:C）与单元格（A1）写法。
+func (ss *spreadsheet) parseRange(raw string) (cellRange, *apiError) {
+	ref, cells, _ := strings.Cut(raw, "!")
+	i := ss.sheetIndex(ref)
+	if i < 0 {
+		return cellRange{}, errNotFound("sheet", ref)
+	}
+	rng := cellRange{sheet: ss.sheets[i], endRow: -1, endCol: -1}
+	if cells == "" {
+		return rng, nil
+	}
+	start, end, hasEnd := strings.Cut(cells, ":")
+	r0, c0, ok := parseCell(start)
+	if !ok {
+		return cellRange{}, errInvalid("invalid range %q", raw)
+	}
+	rng.startRow, rng.startCol = max(r0, 0), max(c0, 0)
+	if !hasEnd {
+		if r0 >= 0 {
+			rng.endRow = r0
+		}
+		if c0 >= 0 {
+			rng.endCol = c0
+		}
+		return rng, nil
+	}
+	r1, c1, ok := parseCell(end)
+	if !ok {
+		return cellRange{}, errInvalid("invalid range %q", raw)
+	}
+	rng.endRow, rng.endCol = r1, c1
+	return rng, nil
+}
+
+// parseCell 解析 A1 / A / 1 形式，缺省的行或列返回 -1。
+func parseCell(s string) (int, int, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	i := 0
+	col := 0
+	for i < len(s) && s[i] >= 'A' && s[i] <= 'Z' {
+		col = col*26 + int(s[i]-'A'+1)
+		i++
+	}
+	rowPart := s[i:]
+	if i == 0 && rowPart == "" {
+		return 0, 0, false
+	}
+	row := -1
+	if rowPart != "" {
+		n, err := strconv.Atoi(rowPart)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		row = n - 1
+	}
+	return row, col - 1, true
+}
+
+func formatRange(sheetID string, r0, c0, r1, c1 int) string {
+	if r1 < r0 || c1 < c0 {
+		return sheetID
+	}
+	return fmt.Sprintf("%s!%s%d:%s%d", sheetID, columnName(c0), r0+1, columnName(c1), r1+1)
+}
+
+func columnName(col int) string {
+	name := ""
+	for n := col + 1; n > 0; n = (n - 1) / 26 {
+		name = string(rune('A'+(n-1)%26)) + name
+	}
+	return name
+}
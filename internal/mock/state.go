@@ -0,0 +1,171 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// state 是 mock 服务的全部内存数据，由 Server.mu 保护。
+type state struct {
+	documents    map[string]*document
+	files        map[string]*driveFile
+	messages     map[string]*message
+	messageOrder []string
+	messageUUIDs map[string]string // uuid → message_id，重复发送幂等
+	spreadsheets map[string]*spreadsheet
+	bases        map[string]*base
+	wikiNodes    map[string]*wikiNode
+	wikiOrder    []string // 节点创建顺序，列表按此排序
+}
+
+func newState() *state {
+	return &state{
+		documents:    make(map[string]*document),
+		files:        make(map[string]*driveFile),
+		messages:     make(map[string]*message),
+		messageUUIDs: make(map[string]string),
+		spreadsheets: make(map[string]*spreadsheet),
+		bases:        make(map[string]*base),
+		wikiNodes:    make(map[string]*wikiNode),
+	}
+}
+
+// snapshot 导出全部状态，供 GET /mock/state 在 CI 中断言。
+func (st *state) snapshot() map[string]any {
+	docs := make(map[string]any, len(st.documents))
+	for id, doc := range st.documents {
+		docs[id] = map[string]any{
+			"title":       doc.title,
+			"revision_id": doc.revision,
+			"raw_content": doc.rawContent(),
+			"blocks":      doc.listBlocks(),
+		}
+	}
+	files := make([]any, 0, len(st.files))
+	for _, token := range sortedKeys(st.files) {
+		files = append(files, st.files[token].meta())
+	}
+	messages := make([]any, 0, len(st.messageOrder))
+	for _, id := range st.messageOrder {
+		messages = append(messages, st.messages[id].toMap())
+	}
+	sheets := make(map[string]any, len(st.spreadsheets))
+	for token, ss := range st.spreadsheets {
+		sheets[token] = ss.snapshot()
+	}
+	bases := make(map[string]any, len(st.bases))
+	for token, b := range st.bases {
+		bases[token] = b.snapshot()
+	}
+	wikiNodes := make([]any, 0, len(st.wikiOrder))
+	for _, token := range st.wikiOrder {
+		wikiNodes = append(wikiNodes, st.wikiNodes[token].meta(st))
+	}
+	return map[string]any{
+		"documents":    docs,
+		"files":        files,
+		"messages":     messages,
+		"spreadsheets": sheets,
+		"bases":        bases,
+		"wiki_nodes":   wikiNodes,
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paginate 按 page_size / page_token 切片；page_token 为上一页末尾的偏移量。
+func paginate[T any](items []T, r *request, defaultSize int) ([]T, bool, string) {
+	size := defaultSize
+	if n, err := strconv.Atoi(r.query("page_size")); err == nil && n > 0 {
+		size = n
+	}
+	start := 0
+	if n, err := strconv.Atoi(r.query("page_token")); err == nil && n > 0 {
+		start = n
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + size
+	if end >= len(items) {
+		return items[start:], false, ""
+	}
+	return items[start:end], true, strconv.Itoa(end)
+}
+
+// str 把 JSON 值转成字符串（string / json.Number / 其它标量）。
+func str(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// toInt 把 JSON 数字（json.Number / float64 / int）或数字字符串转成 int。
+func toInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case json.Number:
+		n, err := val.Int64()
+		if err != nil {
+			f, ferr := val.Float64()
+			if ferr != nil {
+				return 0, false
+			}
+			return int(f), true
+		}
+		return int(n), true
+	case float64:
+		return int(val), true
+	case int:
+		return val, true
+	case string:
+		n, err := strconv.Atoi(val)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// cloneJSON 深拷贝一个 JSON 值，避免把请求体对象直接挂进状态后被后续修改影响。
+func cloneJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = cloneJSON(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = cloneJSON(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
@@ -0,0 +1,133 @@
+package mock
+
+import (
+	"net/http"
+)
+
+// wikiNode 是知识空间中的一个节点。docx 节点创建时同时生成一篇空文档，obj_token 即 document_id。
+type wikiNode struct {
+	spaceID  string
+	token    string
+	objToken string
+	objType  string
+	nodeType string
+	parent   string
+	title    string
+	created  string
+}
+
+func (n *wikiNode) meta(st *state) map[string]any {
+	hasChild := false
+	for _, other := range st.wikiNodes {
+		if other.spaceID == n.spaceID && other.parent == n.token {
+			hasChild = true
+			break
+		}
+	}
+	return map[string]any{
+		"space_id":          n.spaceID,
+		"node_token":        n.token,
+		"obj_token":         n.objToken,
+		"obj_type":          n.objType,
+		"node_type":         n.nodeType,
+		"parent_node_token": n.parent,
+		"title":             n.title,
+		"has_child":         hasChild,
+		"obj_create_time":   n.created,
+		"obj_edit_time":     n.created,
+	}
+}
+
+func (s *Server) wikiRoutes() []*route {
+	const p = "/open-apis/wiki/v2/spaces"
+	return []*route{
+		newRoute(http.MethodGet, p+"/get_node", s.getWikiNode),
+		newRoute(http.MethodGet, p+"/{space_id}/nodes", s.listWikiNodes),
+		newRoute(http.MethodPost, p+"/{space_id}/nodes", s.createWikiNode),
+		newRoute(http.MethodPost, p+"/{space_id}/nodes/{node_token}/update_title", s.updateWikiNodeTitle),
+	}
+}
+
+func (s *Server) createWikiNode(r *request) (*response, *apiError) {
+	spaceID := r.param("space_id")
+	parent := str(r.body["parent_node_token"])
+	if parent != "" {
+		p := s.state.wikiNodes[parent]
+		if p == nil || p.spaceID != spaceID {
+			return nil, errNotFound("parent node", parent)
+		}
+	}
+	objType := str(r.body["obj_type"])
+	if objType == "" {
+		objType = "docx"
+	}
+	nodeType := str(r.body["node_type"])
+	if nodeType == "" {
+		nodeType = "origin"
+	}
+	title := str(r.body["title"])
+	node := &wikiNode{
+		spaceID:  spaceID,
+		token:    s.newID("wikcn"),
+		objType:  objType,
+		nodeType: nodeType,
+		parent:   parent,
+		title:    title,
+		created:  s.nowSec(),
+	}
+	if objType == "docx" {
+		node.objToken = s.newDocument(title, "").id
+	} else {
+		node.objToken = s.newID(objType)
+	}
+	s.state.wikiNodes[node.token] = node
+	s.state.wikiOrder = append(s.state.wikiOrder, node.token)
+	return ok(map[string]any{"node": node.meta(s.state)})
+}
+
+func (s *Server) getWikiNode(r *request) (*response, *apiError) {
+	token := r.query("token")
+	if token == "" {
+		return nil, errInvalid("token is required")
+	}
+	node := s.state.wikiNodes[token]
+	if node == nil {
+		// 与线上一致：也接受 obj_token（如 docx 文档 ID）查询其所在节点
+		for _, n := range s.state.wikiNodes {
+			if n.objToken == token {
+				node = n
+				break
+			}
+		}
+	}
+	if node == nil {
+		return nil, errNotFound("node", token)
+	}
+	return ok(map[string]any{"node": node.meta(s.state)})
+}
+
+func (s *Server) listWikiNodes(r *request) (*response, *apiError) {
+	spaceID := r.param("space_id")
+	parent := r.query("parent_node_token")
+	var items []any
+	for _, token := range s.state.wikiOrder {
+		n := s.state.wikiNodes[token]
+		if n.spaceID == spaceID && n.parent == parent {
+			items = append(items, n.meta(s.state))
+		}
+	}
+	page, hasMore, next := paginate(items, r, 50)
+	return ok(map[string]any{"items": page, "has_more": hasMore, "page_token": next})
+}
+
+func (s *Server) updateWikiNodeTitle(r *request) (*response, *apiError) {
+	node := s.state.wikiNodes[r.param("node_token")]
+	if node == nil || node.spaceID != r.param("space_id") {
+		return nil, errNotFound("node", r.param("node_token"))
+	}
+	node.title = str(r.body["title"])
+	if doc := s.state.documents[node.objToken]; doc != nil {
+		doc.title = node.title
+	}
+	return ok(map[string]any{})
+}
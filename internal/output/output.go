@@ -43,6 +43,8 @@ type Options struct {
 	JQ         string
 	OutputFile string
 	DryRun     bool
+	// Columns 指定 table/csv 的列顺序（可选，由命令设置）；未列出的列按默认规则排在其后
+	Columns []string
 
 	PageAll   bool
 	PageSize  int
@@ -183,9 +185,9 @@ func RenderString(o *Options, data any) (string, error) {
 	case FormatNDJSON:
 		return formatNDJSON(results)
 	case FormatTable:
-		return formatTable(results)
+		return formatTable(results, o.Columns)
 	case FormatCSV:
-		return formatCSV(results)
+		return formatCSV(results, o.Columns)
 	default:
 		return "", fmt.Errorf("不支持的 --format %q", o.Format)
 	}
@@ -211,17 +213,41 @@ func normalize(data any) (any, error) {
 }
 
 // applyJQ 用 gojq 对 input 求值，返回所有输出结果。
-// 先经 toJQInput 把 json.Number 转成 gojq 精确数字类型（保大整数精度）。
 func applyJQ(expr string, input any) ([]any, error) {
+	prog, err := CompileJQ(expr)
+	if err != nil {
+		return nil, err
+	}
+	return prog.Eval(input)
+}
+
+// JQProgram 是编译好的 jq 程序，供事件流等需要对多条输入重复求值的场景复用，
+// 避免每条输入都重新解析表达式。
+type JQProgram struct {
+	code *gojq.Code
+}
+
+// CompileJQ 解析并编译 jq 表达式。
+func CompileJQ(expr string) (*JQProgram, error) {
 	query, err := gojq.Parse(expr)
 	if err != nil {
 		return nil, fmt.Errorf("jq 表达式解析失败: %w", err)
 	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("jq 表达式编译失败: %w", err)
+	}
+	return &JQProgram{code: code}, nil
+}
+
+// Eval 对归一化后的 input（map/slice/json.Number 等）求值，返回所有输出结果。
+// 先经 toJQInput 把 json.Number 转成 gojq 精确数字类型（保大整数精度）。
+func (p *JQProgram) Eval(input any) ([]any, error) {
 	jqInput, err := toJQInput(input)
 	if err != nil {
 		return nil, err
 	}
-	iter := query.Run(jqInput)
+	iter := p.code.Run(jqInput)
 	var results []any
 	for {
 		v, ok := iter.Next()
@@ -239,6 +265,36 @@ func applyJQ(expr string, input any) ([]any, error) {
 	return results, nil
 }
 
+// EvalJSON 对一段原始 JSON 求值（数字按 UseNumber 解码，不丢大整数精度）。
+func (p *JQProgram) EvalJSON(raw []byte) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var input any
+	if err := dec.Decode(&input); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+	return p.Eval(input)
+}
+
+// Match 把程序当作谓词：任一输出既不是 null 也不是 false 即为命中（与 jq select 的真值规则一致）。
+func (p *JQProgram) Match(raw []byte) (bool, error) {
+	results, err := p.EvalJSON(raw)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range results {
+		if r != nil && r != false {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CompactJSON 把 jq 结果编码为单行 JSON（不转义 HTML 字符），用于 NDJSON 输出。
+func CompactJSON(v any) (string, error) {
+	return encodeJSONCompact(v)
+}
+
 // toJQInput 把含 json.Number 的归一化值转成 gojq 可精确处理的类型。
 // gojq 接受 int / *big.Int / float64：整数走 int（飞书 19 位 message_id/chat_id 仍在 int64 内），
 // 超 int64 走 *big.Int，小数走 float64。
@@ -389,6 +445,31 @@ func collectColumns(rows []map[string]any) []string {
 	return cols
 }
 
+// orderColumns 把 preferred 中出现过的列按 preferred 的顺序排在最前，其余保持原顺序。
+func orderColumns(cols, preferred []string) []string {
+	if len(preferred) == 0 {
+		return cols
+	}
+	present := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		present[c] = true
+	}
+	out := make([]string, 0, len(cols))
+	used := make(map[string]bool, len(preferred))
+	for _, c := range preferred {
+		if present[c] && !used[c] {
+			used[c] = true
+			out = append(out, c)
+		}
+	}
+	for _, c := range cols {
+		if !used[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
 // cellString 把单元格值转成字符串：标量直出，复合类型回退紧凑 JSON。
 func cellString(v any) string {
 	if v == nil {
@@ -415,7 +496,7 @@ func cellString(v any) string {
 	}
 }
 
-func formatTable(results []any) (string, error) {
+func formatTable(results []any, order []string) (string, error) {
 	rows, err := rowsFromResults(results)
 	if err != nil {
 		return "", err
@@ -423,7 +504,7 @@ func formatTable(results []any) (string, error) {
 	if len(rows) == 0 {
 		return "(空结果)\n", nil
 	}
-	cols := collectColumns(rows)
+	cols := orderColumns(collectColumns(rows), order)
 	// 表头也做单行化，避免列名含换行/制表符破坏对齐
 	headers := make([]string, len(cols))
 	widths := make([]int, len(cols))
@@ -496,7 +577,7 @@ func isWide(r rune) bool {
 		(r >= 0x1F300 && r <= 0x1FAFF) // emoji 区段（近似）
 }
 
-func formatCSV(results []any) (string, error) {
+func formatCSV(results []any, order []string) (string, error) {
 	rows, err := rowsFromResults(results)
 	if err != nil {
 		return "", err
@@ -507,7 +588,7 @@ func formatCSV(results []any) (string, error) {
 		w.Flush()
 		return buf.String(), nil
 	}
-	cols := collectColumns(rows)
+	cols := orderColumns(collectColumns(rows), order)
 	if err := w.Write(cols); err != nil {
 		return "", fmt.Errorf("CSV 写表头失败: %w", err)
 	}
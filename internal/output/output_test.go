@@ -79,6 +79,18 @@ func TestRenderCSV(t *testing.T) {
 	}
 }
 
+func TestRenderColumnsOrder(t *testing.T) {
+	data := []any{map[string]any{"a": 1, "b": 2, "c": 3}}
+	got := mustRender(t, &Options{Format: FormatCSV, Columns: []string{"c", "x", "a"}}, data)
+	if header := strings.SplitN(got, "\n", 2)[0]; header != "c,a,b" {
+		t.Errorf("指定列序后表头 = %q, want c,a,b", header)
+	}
+	got = mustRender(t, &Options{Format: FormatTable, Columns: []string{"b"}}, data)
+	if !strings.HasPrefix(got, "b  a  c") {
+		t.Errorf("table 表头 = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+}
+
 func TestRenderJQFilter(t *testing.T) {
 	data := map[string]any{
 		"items": []any{
@@ -202,3 +214,39 @@ func TestAddOutputFlagsNoCollisionWithExistingOutput(t *testing.T) {
 		t.Errorf("format 仍应被注册")
 	}
 }
+
+func TestJQProgramMatchAndEvalJSON(t *testing.T) {
+	prog, err := CompileJQ(`.chat == "oc_a"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for raw, want := range map[string]bool{
+		`{"chat":"oc_a"}`: true,
+		`{"chat":"oc_b"}`: false,
+		`{}`:              false,
+	} {
+		got, err := prog.Match([]byte(raw))
+		if err != nil || got != want {
+			t.Errorf("Match(%s) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+
+	prog, err = CompileJQ(`.ids[]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := prog.EvalJSON([]byte(`{"ids":[7030776512726958083,2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("期望 2 个输出，got %v", results)
+	}
+	if s, _ := CompactJSON(results[0]); s != "7030776512726958083" {
+		t.Errorf("大整数精度丢失: %s", s)
+	}
+
+	if _, err := CompileJQ(`.a | (`); err == nil {
+		t.Error("语法错误应在编译期报出")
+	}
+}
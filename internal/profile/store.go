@@ -32,6 +32,7 @@
 package profile
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -40,6 +41,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+
+	"github.com/riba2534/feishu-cli/internal/secretstore"
 )
 
 const (
@@ -461,6 +464,11 @@ func hasLegacyLayout() bool {
 	return false
 }
 
+// HasLegacyLayout 报告 ~/.feishu-cli/ 下是否仍有旧布局的 config.yaml / token.json。
+func HasLegacyLayout() bool {
+	return hasLegacyLayout()
+}
+
 // fileExists 测试文件是否存在。
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -472,6 +480,7 @@ type CreateOpts struct {
 	// AppID 写入 config.yaml 的 app_id，可为空（用户后续手动填）。
 	AppID string
 	// AppSecret 写入 config.yaml 的 app_secret，可为空。
+	// 设置了 FEISHU_SECRET_STORE 时写入 secretstore，config.yaml 只保存引用。
 	AppSecret string
 	// BaseURL 写入 config.yaml 的 base_url，为空时使用默认值。
 	BaseURL string
@@ -515,7 +524,11 @@ func Create(name string, opts CreateOpts) error {
 	if baseURL == "" {
 		baseURL = "https://open.feishu.cn"
 	}
-	content := renderConfigYAML(opts.AppID, opts.AppSecret, baseURL)
+	appSecret, err := sealNewSecret("app_secret", opts.AppSecret)
+	if err != nil {
+		return err
+	}
+	content := renderConfigYAML(opts.AppID, appSecret, baseURL)
 	if err := writeFileAtomic(configFile, []byte(content), 0600); err != nil {
 		return fmt.Errorf("写入 config.yaml 失败: %w", err)
 	}
@@ -557,6 +570,7 @@ func Remove(name string) error {
 	if err != nil {
 		return err
 	}
+	eraseDirSecrets(dir)
 	if err := os.RemoveAll(dir); err != nil {
 		return fmt.Errorf("删除 profile 目录失败: %w", err)
 	}
@@ -759,6 +773,100 @@ func MigrateLegacy(opts MigrateLegacyOpts) (string, error) {
 	return target, nil
 }
 
+// sealNewSecret 按 FEISHU_SECRET_STORE 存入新 secret，返回写进 config.yaml 的值（明文或引用）。
+func sealNewSecret(field, value string) (string, error) {
+	backend, err := secretstore.DefaultBackend()
+	if err != nil {
+		return "", err
+	}
+	root, err := RootDir()
+	if err != nil {
+		return "", err
+	}
+	return secretstore.Seal(backend, root, field, value, "")
+}
+
+// configSecretFields 是 config.yaml 中可能保存凭证的顶层字段。
+var configSecretFields = []string{"app_secret", "user_access_token"}
+
+// MigrateConfigSecrets 把 dir/config.yaml 中的明文 app_secret / user_access_token 移入 backend，
+// 原地改写对应行（其余内容与注释保持不变），返回被迁移的字段名。文件不存在时返回空。
+func MigrateConfigSecrets(dir, backend string) ([]string, error) {
+	path := filepath.Join(dir, configFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	root, err := RootDir()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := ReadConfigFieldsFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	values := map[string]string{"app_secret": fields.AppSecret, "user_access_token": fields.UserAccessToken}
+
+	content := string(data)
+	var migrated []string
+	for _, field := range configSecretFields {
+		value := values[field]
+		if value == "" || secretstore.IsRef(value) {
+			continue
+		}
+		line := regexp.MustCompile(`(?m)^` + field + `:[^\n]*$`)
+		if !line.MatchString(content) {
+			// 字段不在顶层单行（例如多行字符串），不冒险改写
+			return nil, fmt.Errorf("%s 中的 %s 不是单行写法，请手动改写后重试", path, field)
+		}
+		sealed, err := secretstore.Seal(backend, root, field, value, "")
+		if err != nil {
+			return nil, err
+		}
+		content = line.ReplaceAllLiteralString(content, fmt.Sprintf("%s: %q", field, sealed))
+		migrated = append(migrated, field)
+	}
+	if len(migrated) == 0 {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, []byte(content), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	return migrated, nil
+}
+
+// eraseDirSecrets 尽力删除 dir 下 config.yaml / token.json 引用的 secretstore 条目，
+// 避免删除 profile 后在加密文件或凭证助手中留下孤儿凭证。
+func eraseDirSecrets(dir string) {
+	root, err := RootDir()
+	if err != nil {
+		return
+	}
+	if fields, err := ReadConfigFields(dir); err == nil {
+		_ = secretstore.Erase(fields.AppSecret, root)
+		_ = secretstore.Erase(fields.UserAccessToken, root)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, tokenFileName))
+	if err != nil {
+		return
+	}
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if json.Unmarshal(data, &token) == nil {
+		_ = secretstore.Erase(token.AccessToken, root)
+		_ = secretstore.Erase(token.RefreshToken, root)
+	}
+}
+
 // writeFileAtomic 原子写入文件：先写 .tmp 后 rename。
 func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 	dir := filepath.Dir(path)
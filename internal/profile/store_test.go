@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/riba2534/feishu-cli/internal/secretstore"
 )
 
 // withTempHome 把 homeFunc 重定向到 t.TempDir()，并在测试结束时还原。
@@ -542,3 +544,50 @@ func getProfileDirT(t *testing.T, name string) string {
 	}
 	return dir
 }
+
+func TestCreateAndMigrateConfigSecrets(t *testing.T) {
+	withTempHome(t)
+	t.Setenv(secretstore.EnvPassphrase, "test-pass")
+
+	// 未设置 FEISHU_SECRET_STORE：保持明文写入，随后迁移
+	t.Setenv(secretstore.EnvBackend, "")
+	if err := Create("plain", CreateOpts{AppID: "cli_p", AppSecret: "plain-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ := ProfileDir("plain")
+	migrated, err := MigrateConfigSecrets(dir, secretstore.BackendFile)
+	if err != nil || len(migrated) != 1 || migrated[0] != "app_secret" {
+		t.Fatalf("MigrateConfigSecrets = %v, %v", migrated, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if strings.Contains(string(data), "plain-secret") || !strings.Contains(string(data), `app_id: "cli_p"`) {
+		t.Fatalf("迁移后 config.yaml 不符合预期:\n%s", data)
+	}
+	fields, _ := ReadConfigFields(dir)
+	root, _ := RootDir()
+	if got, err := secretstore.Resolve(fields.AppSecret, root); err != nil || got != "plain-secret" {
+		t.Fatalf("Resolve = %q, %v", got, err)
+	}
+	if again, err := MigrateConfigSecrets(dir, secretstore.BackendFile); err != nil || len(again) != 0 {
+		t.Fatalf("重复迁移应无改动: %v, %v", again, err)
+	}
+
+	// 设置 FEISHU_SECRET_STORE 后 Create 直接写引用
+	t.Setenv(secretstore.EnvBackend, secretstore.BackendFile)
+	if err := Create("sealed", CreateOpts{AppID: "cli_s", AppSecret: "sealed-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	sealedDir, _ := ProfileDir("sealed")
+	sealed, _ := ReadConfigFields(sealedDir)
+	if !secretstore.IsRef(sealed.AppSecret) {
+		t.Fatalf("app_secret 应为引用: %q", sealed.AppSecret)
+	}
+
+	// 删除 profile 时清理其引用的条目
+	if err := Remove("sealed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secretstore.Resolve(sealed.AppSecret, root); !errors.Is(err, secretstore.ErrNotFound) {
+		t.Fatalf("Remove 后 secret 应被删除: %v", err)
+	}
+}
@@ -0,0 +1,707 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 运行期的值只有 nil（NULL）、float64、string、bool 四种，装载时由 normalizeValue 归一
+
+// toNumber 把值转为数字；字符串需能完整解析为数字
+func toNumber(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
+
+// truth 返回条件的真值；NULL 为未知（known=false），WHERE / HAVING / ON 按假处理
+func truth(v any) (value, known bool) {
+	switch x := v.(type) {
+	case nil:
+		return false, false
+	case bool:
+		return x, true
+	}
+	f, _ := toNumber(v)
+	return f != 0, true
+}
+
+// compareValues 比较两个非 NULL 值：两边都是字符串时按文本比较，
+// 否则只要能转为数字就按数字比较，仍不行再退回文本。任一边为 NULL 时 ok=false
+func compareValues(a, b any) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	if aStr && bStr {
+		return strings.Compare(as, bs), true
+	}
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return strings.Compare(toText(a), toText(b)), true
+}
+
+// sortCompare 是排序用的全序：NULL 最小，其余同 compareValues
+func sortCompare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	c, _ := compareValues(a, b)
+	return c
+}
+
+// valuesKey 把一组值编码为分组 / 去重用的键；类型不同的值（"1" 与 1）视为不同
+func valuesKey(vals []any) string {
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return fmt.Sprint(vals)
+	}
+	return string(b)
+}
+
+// likeMatch 实现 LIKE：% 匹配任意串，_ 匹配单个字符，不区分大小写
+func likeMatch(s, pattern string) bool {
+	str := []rune(strings.ToLower(s))
+	pat := []rune(strings.ToLower(pattern))
+	// 贪心 + 回溯到最近一个 %
+	si, pi, starP, starS := 0, 0, -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(pat) && (pat[pi] == '_' || pat[pi] == str[si]):
+			si++
+			pi++
+		case pi < len(pat) && pat[pi] == '%':
+			starP, starS = pi, si
+			pi++
+		case starP >= 0:
+			starS++
+			si, pi = starS, starP+1
+		default:
+			return false
+		}
+	}
+	for pi < len(pat) && pat[pi] == '%' {
+		pi++
+	}
+	return pi == len(pat)
+}
+
+// rowCtx 是表达式求值的上下文：row 为当前行；分组模式下 group 为本组全部行，row 为组内首行
+type rowCtx struct {
+	row   []any
+	group [][]any
+}
+
+type evalFn func(ctx *rowCtx) (any, error)
+
+// compiler 把语法树绑定到具体列位置，编译为求值函数
+type compiler struct {
+	scope     *scope
+	aggregate bool            // 是否允许聚合函数
+	aliases   map[string]expr // 输出列别名：列名解析不到时回退（GROUP BY / HAVING / ORDER BY 可用）
+}
+
+var aggregateFuncs = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true, "GROUP_CONCAT": true}
+
+// scalarArity 是标量函数的参数个数范围，上限 -1 表示不限
+var scalarArity = map[string][2]int{
+	"LOWER": {1, 1}, "UPPER": {1, 1}, "LENGTH": {1, 1}, "TRIM": {1, 1},
+	"SUBSTR": {2, 3}, "REPLACE": {3, 3}, "COALESCE": {1, -1}, "IFNULL": {2, 2},
+	"ROUND": {1, 2}, "ABS": {1, 1},
+}
+
+func (c *compiler) compile(e expr) (evalFn, error) {
+	switch n := e.(type) {
+	case *literal:
+		v := n.value
+		return func(*rowCtx) (any, error) { return v, nil }, nil
+
+	case *columnRef:
+		idx, err := c.scope.resolve(n)
+		if err != nil {
+			if alias, ok := c.aliases[n.name]; ok && n.table == "" {
+				// 别名指向的表达式只在原作用域内解析，避免 SELECT a+1 AS a 这类自引用
+				return (&compiler{scope: c.scope, aggregate: c.aggregate}).compile(alias)
+			}
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) { return ctx.row[idx], nil }, nil
+
+	case *unaryExpr:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "NOT" {
+			return func(ctx *rowCtx) (any, error) {
+				v, err := x(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if b, known := truth(v); known {
+					return !b, nil
+				}
+				return nil, nil
+			}, nil
+		}
+		return func(ctx *rowCtx) (any, error) {
+			v, err := x(ctx)
+			if err != nil || v == nil {
+				return nil, err
+			}
+			f, ok := toNumber(v)
+			if !ok {
+				return nil, fmt.Errorf("%s: %q 不是数字", n.src, toText(v))
+			}
+			return -f, nil
+		}, nil
+
+	case *binaryExpr:
+		return c.compileBinary(n)
+
+	case *likeExpr:
+		x, pattern, err := c.compile2(n.x, n.pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) {
+			v, p, err := eval2(ctx, x, pattern)
+			if err != nil || v == nil || p == nil {
+				return nil, err
+			}
+			return likeMatch(toText(v), toText(p)) != n.not, nil
+		}, nil
+
+	case *inExpr:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return nil, err
+		}
+		list, err := c.compileList(n.list)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) {
+			v, err := x(ctx)
+			if err != nil || v == nil {
+				return nil, err
+			}
+			sawNull := false
+			for _, item := range list {
+				w, err := item(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if cmp, ok := compareValues(v, w); ok && cmp == 0 {
+					return !n.not, nil
+				}
+				sawNull = sawNull || w == nil
+			}
+			if sawNull {
+				return nil, nil
+			}
+			return n.not, nil
+		}, nil
+
+	case *betweenExpr:
+		fns, err := c.compileList([]expr{n.x, n.lo, n.hi})
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) {
+			vals, err := evalAll(ctx, fns)
+			if err != nil {
+				return nil, err
+			}
+			lo, ok1 := compareValues(vals[0], vals[1])
+			hi, ok2 := compareValues(vals[0], vals[2])
+			if !ok1 || !ok2 {
+				return nil, nil
+			}
+			return (lo >= 0 && hi <= 0) != n.not, nil
+		}, nil
+
+	case *isNullExpr:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) {
+			v, err := x(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return (v == nil) != n.not, nil
+		}, nil
+
+	case *caseExpr:
+		return c.compileCase(n)
+
+	case *castExpr:
+		x, err := c.compile(n.x)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *rowCtx) (any, error) {
+			v, err := x(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return castValue(v, n.typ), nil
+		}, nil
+
+	case *callExpr:
+		if aggregateFuncs[n.name] {
+			return c.compileAggregate(n)
+		}
+		return c.compileScalar(n)
+	}
+	return nil, fmt.Errorf("不支持的表达式 %s", e.source())
+}
+
+func (c *compiler) compile2(a, b expr) (evalFn, evalFn, error) {
+	x, err := c.compile(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := c.compile(b)
+	return x, y, err
+}
+
+func (c *compiler) compileList(list []expr) ([]evalFn, error) {
+	fns := make([]evalFn, len(list))
+	for i, e := range list {
+		fn, err := c.compile(e)
+		if err != nil {
+			return nil, err
+		}
+		fns[i] = fn
+	}
+	return fns, nil
+}
+
+func eval2(ctx *rowCtx, x, y evalFn) (any, any, error) {
+	a, err := x(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := y(ctx)
+	return a, b, err
+}
+
+func evalAll(ctx *rowCtx, fns []evalFn) ([]any, error) {
+	vals := make([]any, len(fns))
+	for i, fn := range fns {
+		v, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func (c *compiler) compileBinary(n *binaryExpr) (evalFn, error) {
+	l, r, err := c.compile2(n.l, n.r)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "AND", "OR":
+		// 三值逻辑：AND 遇假即假，OR 遇真即真，其余含 NULL 时为 NULL
+		short := n.op == "OR"
+		return func(ctx *rowCtx) (any, error) {
+			a, err := l(ctx)
+			if err != nil {
+				return nil, err
+			}
+			av, aKnown := truth(a)
+			if aKnown && av == short {
+				return short, nil
+			}
+			b, err := r(ctx)
+			if err != nil {
+				return nil, err
+			}
+			bv, bKnown := truth(b)
+			if bKnown && bv == short {
+				return short, nil
+			}
+			if !aKnown || !bKnown {
+				return nil, nil
+			}
+			return !short, nil
+		}, nil
+
+	case "=", "!=", "<", "<=", ">", ">=":
+		return func(ctx *rowCtx) (any, error) {
+			a, b, err := eval2(ctx, l, r)
+			if err != nil {
+				return nil, err
+			}
+			cmp, ok := compareValues(a, b)
+			if !ok {
+				return nil, nil
+			}
+			switch n.op {
+			case "=":
+				return cmp == 0, nil
+			case "!=":
+				return cmp != 0, nil
+			case "<":
+				return cmp < 0, nil
+			case "<=":
+				return cmp <= 0, nil
+			case ">":
+				return cmp > 0, nil
+			}
+			return cmp >= 0, nil
+		}, nil
+
+	case "||":
+		return func(ctx *rowCtx) (any, error) {
+			a, b, err := eval2(ctx, l, r)
+			if err != nil || a == nil || b == nil {
+				return nil, err
+			}
+			return toText(a) + toText(b), nil
+		}, nil
+	}
+
+	// 算术：NULL 参与运算得 NULL，除以 0 得 NULL
+	return func(ctx *rowCtx) (any, error) {
+		a, b, err := eval2(ctx, l, r)
+		if err != nil || a == nil || b == nil {
+			return nil, err
+		}
+		x, ok := toNumber(a)
+		if !ok {
+			return nil, fmt.Errorf("%s: %q 不是数字", n.src, toText(a))
+		}
+		y, ok := toNumber(b)
+		if !ok {
+			return nil, fmt.Errorf("%s: %q 不是数字", n.src, toText(b))
+		}
+		switch n.op {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "*":
+			return x * y, nil
+		}
+		if y == 0 {
+			return nil, nil
+		}
+		if n.op == "/" {
+			return x / y, nil
+		}
+		return math.Mod(x, y), nil
+	}, nil
+}
+
+func (c *compiler) compileCase(n *caseExpr) (evalFn, error) {
+	var operand evalFn
+	var err error
+	if n.operand != nil {
+		if operand, err = c.compile(n.operand); err != nil {
+			return nil, err
+		}
+	}
+	conds := make([]evalFn, len(n.whens))
+	results := make([]evalFn, len(n.whens))
+	for i, w := range n.whens {
+		if conds[i], results[i], err = c.compile2(w.cond, w.result); err != nil {
+			return nil, err
+		}
+	}
+	elseFn := evalFn(func(*rowCtx) (any, error) { return nil, nil })
+	if n.elseExpr != nil {
+		if elseFn, err = c.compile(n.elseExpr); err != nil {
+			return nil, err
+		}
+	}
+	return func(ctx *rowCtx) (any, error) {
+		var subject any
+		if operand != nil {
+			v, err := operand(ctx)
+			if err != nil {
+				return nil, err
+			}
+			subject = v
+		}
+		for i, cond := range conds {
+			v, err := cond(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var hit bool
+			if operand != nil {
+				cmp, ok := compareValues(subject, v)
+				hit = ok && cmp == 0
+			} else {
+				hit, _ = truth(v)
+			}
+			if hit {
+				return results[i](ctx)
+			}
+		}
+		return elseFn(ctx)
+	}, nil
+}
+
+// castValue 做类型转换；无法转为数字时得 NULL
+func castValue(v any, typ string) any {
+	if v == nil {
+		return nil
+	}
+	switch typ {
+	case "TEXT":
+		return toText(v)
+	case "INTEGER":
+		if f, ok := toNumber(v); ok {
+			return math.Trunc(f)
+		}
+		return nil
+	}
+	if f, ok := toNumber(v); ok {
+		return f
+	}
+	return nil
+}
+
+func (c *compiler) compileAggregate(n *callExpr) (evalFn, error) {
+	if !c.aggregate {
+		return nil, fmt.Errorf("此处不能使用聚合函数 %s", n.src)
+	}
+	switch {
+	case n.star && n.name != "COUNT":
+		return nil, fmt.Errorf("%s: 只有 COUNT 支持 *", n.src)
+	case n.star:
+		return func(ctx *rowCtx) (any, error) { return float64(len(ctx.group)), nil }, nil
+	case n.name == "GROUP_CONCAT" && (len(n.args) < 1 || len(n.args) > 2),
+		n.name != "GROUP_CONCAT" && len(n.args) != 1:
+		return nil, fmt.Errorf("%s: 参数个数不正确", n.src)
+	}
+	// 聚合函数的参数逐行求值，不允许再嵌套聚合
+	inner := &compiler{scope: c.scope, aliases: c.aliases}
+	arg, err := inner.compile(n.args[0])
+	if err != nil {
+		return nil, err
+	}
+	sep := evalFn(func(*rowCtx) (any, error) { return ",", nil })
+	if len(n.args) == 2 {
+		if sep, err = inner.compile(n.args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx *rowCtx) (any, error) {
+		// 收集本组非 NULL 的参数值，DISTINCT 时去重
+		var vals []any
+		seen := map[string]bool{}
+		for _, row := range ctx.group {
+			v, err := arg(&rowCtx{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				continue
+			}
+			if n.distinct {
+				key := valuesKey([]any{v})
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			vals = append(vals, v)
+		}
+
+		switch n.name {
+		case "COUNT":
+			return float64(len(vals)), nil
+		case "MIN", "MAX":
+			var best any
+			for _, v := range vals {
+				cmp := sortCompare(v, best)
+				if best == nil || (n.name == "MIN" && cmp < 0) || (n.name == "MAX" && cmp > 0) {
+					best = v
+				}
+			}
+			return best, nil
+		case "GROUP_CONCAT":
+			if len(vals) == 0 {
+				return nil, nil
+			}
+			s, err := sep(ctx)
+			if err != nil {
+				return nil, err
+			}
+			parts := make([]string, len(vals))
+			for i, v := range vals {
+				parts[i] = toText(v)
+			}
+			return strings.Join(parts, toText(s)), nil
+		}
+		// SUM / AVG
+		if len(vals) == 0 {
+			return nil, nil
+		}
+		var sum float64
+		for _, v := range vals {
+			f, ok := toNumber(v)
+			if !ok {
+				return nil, fmt.Errorf("%s: %q 不是数字", n.src, toText(v))
+			}
+			sum += f
+		}
+		if n.name == "AVG" {
+			return sum / float64(len(vals)), nil
+		}
+		return sum, nil
+	}, nil
+}
+
+func (c *compiler) compileScalar(n *callExpr) (evalFn, error) {
+	arity, ok := scalarArity[n.name]
+	if !ok {
+		return nil, fmt.Errorf("未知函数 %s", n.name)
+	}
+	if n.star || n.distinct || len(n.args) < arity[0] || (arity[1] >= 0 && len(n.args) > arity[1]) {
+		return nil, fmt.Errorf("%s: 参数不正确", n.src)
+	}
+	args, err := c.compileList(n.args)
+	if err != nil {
+		return nil, err
+	}
+	num := func(v any) (float64, error) {
+		f, ok := toNumber(v)
+		if !ok {
+			return 0, fmt.Errorf("%s: %q 不是数字", n.src, toText(v))
+		}
+		return f, nil
+	}
+
+	return func(ctx *rowCtx) (any, error) {
+		vals, err := evalAll(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		switch n.name {
+		case "COALESCE", "IFNULL":
+			for _, v := range vals {
+				if v != nil {
+					return v, nil
+				}
+			}
+			return nil, nil
+		}
+		for _, v := range vals {
+			if v == nil {
+				return nil, nil
+			}
+		}
+		s := toText(vals[0])
+		switch n.name {
+		case "LOWER":
+			return strings.ToLower(s), nil
+		case "UPPER":
+			return strings.ToUpper(s), nil
+		case "TRIM":
+			return strings.TrimSpace(s), nil
+		case "LENGTH":
+			return float64(utf8.RuneCountInString(s)), nil
+		case "REPLACE":
+			if from := toText(vals[1]); from != "" {
+				return strings.ReplaceAll(s, from, toText(vals[2])), nil
+			}
+			return s, nil
+		case "SUBSTR":
+			return substr(s, vals[1:], num)
+		case "ABS":
+			f, err := num(vals[0])
+			return math.Abs(f), err
+		}
+		// ROUND
+		f, err := num(vals[0])
+		if err != nil {
+			return nil, err
+		}
+		digits := 0.0
+		if len(vals) == 2 {
+			if digits, err = num(vals[1]); err != nil {
+				return nil, err
+			}
+		}
+		scale := math.Pow(10, math.Trunc(digits))
+		return math.Round(f*scale) / scale, nil
+	}, nil
+}
+
+// substr 按字符（非字节）截取：起点从 1 开始，负数表示从末尾倒数
+func substr(s string, args []any, num func(any) (float64, error)) (any, error) {
+	rs := []rune(s)
+	start, err := num(args[0])
+	if err != nil {
+		return nil, err
+	}
+	from := int(start) - 1
+	if start < 0 {
+		from = len(rs) + int(start)
+	}
+	to := len(rs)
+	if len(args) == 2 {
+		n, err := num(args[1])
+		if err != nil {
+			return nil, err
+		}
+		to = from + int(n)
+	}
+	from = max(0, min(from, len(rs)))
+	to = max(from, min(to, len(rs)))
+	return string(rs[from:to]), nil
+}
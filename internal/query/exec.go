@@ -0,0 +1,651 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Table 是调用方装载好的一张数据表，Rows 的每一行与 Columns 一一对应
+type Table struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Result 是查询结果
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Loader 按数据源引用装载数据
+type Loader func(ref TableRef) (*Table, error)
+
+type column struct{ table, name string }
+
+// scope 是 FROM / JOIN 拼出的宽行的列布局
+type scope struct{ cols []column }
+
+func (s *scope) add(table string, names []string) {
+	for _, name := range names {
+		s.cols = append(s.cols, column{table: table, name: name})
+	}
+}
+
+// resolve 把列引用绑定到宽行下标：先精确匹配，再不区分大小写匹配
+func (s *scope) resolve(ref *columnRef) (int, error) {
+	for _, fold := range []bool{false, true} {
+		idx := -1
+		var tables []string
+		for i, c := range s.cols {
+			if ref.table != "" && !strings.EqualFold(c.table, ref.table) {
+				continue
+			}
+			if c.name == ref.name || (fold && strings.EqualFold(c.name, ref.name)) {
+				if idx < 0 {
+					idx = i
+				}
+				tables = append(tables, c.table)
+			}
+		}
+		switch {
+		case len(tables) == 1:
+			return idx, nil
+		case len(tables) > 1:
+			return 0, fmt.Errorf("列 %s 有歧义（%s 中都有），请加表别名限定", ref.src, strings.Join(tables, "、"))
+		}
+	}
+	if ref.table != "" && !s.hasTable(ref.table) {
+		return 0, fmt.Errorf("未知的表别名 %q", ref.table)
+	}
+	return 0, fmt.Errorf("未知的列 %s", ref.src)
+}
+
+func (s *scope) hasTable(alias string) bool {
+	for _, c := range s.cols {
+		if strings.EqualFold(c.table, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute 装载语句引用的数据源并执行查询
+func Execute(stmt *Select, load Loader) (*Result, error) {
+	cache := map[string]*Table{}
+	aliases := map[string]bool{}
+	get := func(ref TableRef) (*Table, error) {
+		if aliases[strings.ToLower(ref.Alias)] {
+			return nil, fmt.Errorf("表别名 %q 重复，请用 AS 指定不同的别名", ref.Alias)
+		}
+		aliases[strings.ToLower(ref.Alias)] = true
+		if t, ok := cache[ref.Key()]; ok {
+			return t, nil
+		}
+		t, err := load(ref)
+		if err != nil {
+			return nil, err
+		}
+		t = normalizeTable(t)
+		cache[ref.Key()] = t
+		return t, nil
+	}
+
+	from, err := get(stmt.from)
+	if err != nil {
+		return nil, err
+	}
+	sc := &scope{}
+	sc.add(stmt.from.Alias, from.Columns)
+	rows := from.Rows
+	for _, j := range stmt.joins {
+		right, err := get(j.table)
+		if err != nil {
+			return nil, err
+		}
+		if rows, err = joinRows(sc, rows, j, right); err != nil {
+			return nil, err
+		}
+	}
+
+	if stmt.where != nil {
+		cond, err := (&compiler{scope: sc}).compile(stmt.where)
+		if err != nil {
+			return nil, fmt.Errorf("WHERE: %w", err)
+		}
+		kept := rows[:0:0]
+		for _, row := range rows {
+			ok, err := test(cond, &rowCtx{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+	return project(stmt, sc, rows)
+}
+
+// normalizeTable 复制一份数据并把值归一为 nil / float64 / string / bool，行宽补齐到列数
+func normalizeTable(t *Table) *Table {
+	out := &Table{Columns: t.Columns, Rows: make([][]any, len(t.Rows))}
+	for i, row := range t.Rows {
+		r := make([]any, len(t.Columns))
+		for j := range r {
+			if j < len(row) {
+				r[j] = normalizeValue(row[j])
+			}
+		}
+		out.Rows[i] = r
+	}
+	return out
+}
+
+func normalizeValue(v any) any {
+	switch x := v.(type) {
+	case nil, string, float64, bool:
+		return x
+	case int:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case json.Number:
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func test(cond evalFn, ctx *rowCtx) (bool, error) {
+	v, err := cond(ctx)
+	if err != nil {
+		return false, err
+	}
+	ok, _ := truth(v)
+	return ok, nil
+}
+
+// joinRows 把右表连接到已有的宽行上。ON 中含「左列 = 右列」时按该列建哈希索引，
+// 否则逐对比较；哈希键按数字归一后可能多配，最终仍以完整 ON 条件为准
+func joinRows(sc *scope, left [][]any, j joinClause, right *Table) ([][]any, error) {
+	leftWidth := len(sc.cols)
+	sc.add(j.table.Alias, right.Columns)
+	on, err := (&compiler{scope: sc}).compile(j.on)
+	if err != nil {
+		return nil, fmt.Errorf("JOIN %s ON: %w", j.table.Alias, err)
+	}
+
+	li, ri := equiJoinColumns(sc, j.on, leftWidth)
+	var index map[string][]int
+	all := make([]int, len(right.Rows))
+	for k := range all {
+		all[k] = k
+	}
+	if li >= 0 {
+		index = map[string][]int{}
+		for k, row := range right.Rows {
+			if v := row[ri-leftWidth]; v != nil {
+				key := joinKey(v)
+				index[key] = append(index[key], k)
+			}
+		}
+	}
+
+	var out [][]any
+	for _, l := range left {
+		candidates := all
+		if index != nil {
+			candidates = nil
+			if v := l[li]; v != nil {
+				candidates = index[joinKey(v)]
+			}
+		}
+		matched := false
+		for _, k := range candidates {
+			row := append(append(make([]any, 0, len(sc.cols)), l...), right.Rows[k]...)
+			ok, err := test(on, &rowCtx{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				out = append(out, row)
+				matched = true
+			}
+		}
+		if !matched && j.left {
+			out = append(out, append(append(make([]any, 0, len(sc.cols)), l...), make([]any, len(right.Columns))...))
+		}
+	}
+	return out, nil
+}
+
+// equiJoinColumns 在 ON 的 AND 链中找一个「左表列 = 右表列」，返回两边的宽行下标；找不到返回 -1
+func equiJoinColumns(sc *scope, on expr, leftWidth int) (int, int) {
+	b, ok := on.(*binaryExpr)
+	if !ok {
+		return -1, -1
+	}
+	if b.op == "AND" {
+		if li, ri := equiJoinColumns(sc, b.l, leftWidth); li >= 0 {
+			return li, ri
+		}
+		return equiJoinColumns(sc, b.r, leftWidth)
+	}
+	lc, lok := b.l.(*columnRef)
+	rc, rok := b.r.(*columnRef)
+	if b.op != "=" || !lok || !rok {
+		return -1, -1
+	}
+	x, err1 := sc.resolve(lc)
+	y, err2 := sc.resolve(rc)
+	if err1 != nil || err2 != nil {
+		return -1, -1
+	}
+	if x > y {
+		x, y = y, x
+	}
+	if x < leftWidth && y >= leftWidth {
+		return x, y
+	}
+	return -1, -1
+}
+
+// joinKey 是哈希连接的桶键：能转为数字的值按数字归一，保证与 compareValues 的相等不漏配
+func joinKey(v any) string {
+	if f, ok := toNumber(v); ok {
+		return fmt.Sprintf("n:%v", f)
+	}
+	return "s:" + toText(v)
+}
+
+type outputColumn struct {
+	name  string
+	table string // 直接取自某表的列，命名冲突时用于加前缀
+	fn    evalFn
+}
+
+// project 处理分组、HAVING、投影、DISTINCT、ORDER BY 与 LIMIT
+func project(stmt *Select, sc *scope, rows [][]any) (*Result, error) {
+	grouped := len(stmt.groupBy) > 0 || stmt.having != nil
+	for _, item := range stmt.items {
+		grouped = grouped || (!item.star && hasAggregate(item.expr))
+	}
+	for _, o := range stmt.orderBy {
+		grouped = grouped || hasAggregate(o.expr)
+	}
+
+	aliases := map[string]expr{}
+	for _, item := range stmt.items {
+		if item.alias != "" {
+			aliases[item.alias] = item.expr
+		}
+	}
+	c := &compiler{scope: sc, aggregate: grouped}
+	var gc *groupCheck
+	if grouped {
+		gc = newGroupCheck(sc, stmt.groupBy, aliases)
+	}
+	var outs []outputColumn
+	aliasOut := map[string]int{} // 别名 → 输出列下标
+	for _, item := range stmt.items {
+		if item.star {
+			found := false
+			for i, col := range sc.cols {
+				if item.starTable != "" && !strings.EqualFold(col.table, item.starTable) {
+					continue
+				}
+				if gc != nil && !gc.cols[i] {
+					return nil, fmt.Errorf("SELECT *: 列 %s 既不在 GROUP BY 中，也不在聚合函数内", col.name)
+				}
+				idx := i
+				found = true
+				outs = append(outs, outputColumn{name: col.name, table: col.table, fn: func(ctx *rowCtx) (any, error) { return ctx.row[idx], nil }})
+			}
+			if !found && item.starTable != "" {
+				return nil, fmt.Errorf("未知的表别名 %q", item.starTable)
+			}
+			continue
+		}
+		fn, err := c.compile(item.expr)
+		if err != nil {
+			return nil, err
+		}
+		if err := gc.check("SELECT", item.expr); err != nil {
+			return nil, err
+		}
+		if item.alias != "" {
+			aliasOut[item.alias] = len(outs)
+		}
+		out := outputColumn{name: item.alias, fn: fn}
+		if ref, ok := item.expr.(*columnRef); ok && out.name == "" {
+			out.name = ref.name
+			if idx, err := sc.resolve(ref); err == nil {
+				out.table = sc.cols[idx].table
+			}
+		}
+		if out.name == "" {
+			out.name = item.expr.source()
+		}
+		outs = append(outs, out)
+	}
+
+	// ORDER BY：输出列别名与序号直接取投影值，其余按表达式求值
+	type sortKey struct {
+		out  int // >=0 时取第 out 个输出列
+		fn   evalFn
+		desc bool
+	}
+	keys := make([]sortKey, len(stmt.orderBy))
+	for i, o := range stmt.orderBy {
+		keys[i] = sortKey{out: -1, desc: o.desc}
+		if lit, ok := o.expr.(*literal); ok {
+			f, isNum := lit.value.(float64)
+			if !isNum || f != float64(int(f)) || f < 1 || int(f) > len(outs) {
+				return nil, fmt.Errorf("ORDER BY %s: 序号超出输出列范围 1..%d", lit.src, len(outs))
+			}
+			keys[i].out = int(f) - 1
+			continue
+		}
+		if ref, ok := o.expr.(*columnRef); ok && ref.table == "" {
+			if k, ok := aliasOut[ref.name]; ok {
+				keys[i].out = k
+				continue
+			}
+		}
+		fn, err := (&compiler{scope: sc, aggregate: grouped, aliases: aliases}).compile(o.expr)
+		if err != nil {
+			return nil, fmt.Errorf("ORDER BY: %w", err)
+		}
+		if err := gc.check("ORDER BY", o.expr); err != nil {
+			return nil, err
+		}
+		keys[i].fn = fn
+	}
+
+	// 每个待输出的上下文：非分组时一行一个，分组时一组一个
+	var ctxs []*rowCtx
+	if !grouped {
+		for _, row := range rows {
+			ctxs = append(ctxs, &rowCtx{row: row})
+		}
+	} else {
+		groupFns, err := (&compiler{scope: sc, aliases: aliases}).compileList(stmt.groupBy)
+		if err != nil {
+			return nil, fmt.Errorf("GROUP BY: %w", err)
+		}
+		byKey := map[string]*rowCtx{}
+		for _, row := range rows {
+			vals, err := evalAll(&rowCtx{row: row}, groupFns)
+			if err != nil {
+				return nil, err
+			}
+			key := valuesKey(vals)
+			g := byKey[key]
+			if g == nil {
+				g = &rowCtx{row: row}
+				byKey[key] = g
+				ctxs = append(ctxs, g)
+			}
+			g.group = append(g.group, row)
+		}
+		// 无 GROUP BY 的聚合查询即使没有输入行也输出一行
+		if len(stmt.groupBy) == 0 && len(ctxs) == 0 {
+			ctxs = append(ctxs, &rowCtx{row: make([]any, len(sc.cols))})
+		}
+		if stmt.having != nil {
+			cond, err := (&compiler{scope: sc, aggregate: true, aliases: aliases}).compile(stmt.having)
+			if err != nil {
+				return nil, fmt.Errorf("HAVING: %w", err)
+			}
+			if err := gc.check("HAVING", stmt.having); err != nil {
+				return nil, err
+			}
+			kept := ctxs[:0:0]
+			for _, ctx := range ctxs {
+				ok, err := test(cond, ctx)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					kept = append(kept, ctx)
+				}
+			}
+			ctxs = kept
+		}
+	}
+
+	type resultRow struct{ vals, keys []any }
+	var results []resultRow
+	seen := map[string]bool{}
+	for _, ctx := range ctxs {
+		vals := make([]any, len(outs))
+		for i, out := range outs {
+			v, err := out.fn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+		if stmt.distinct {
+			key := valuesKey(vals)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		r := resultRow{vals: vals, keys: make([]any, len(keys))}
+		for i, k := range keys {
+			if k.out >= 0 {
+				r.keys[i] = vals[k.out]
+				continue
+			}
+			v, err := k.fn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r.keys[i] = v
+		}
+		results = append(results, r)
+	}
+
+	if len(keys) > 0 {
+		sort.SliceStable(results, func(a, b int) bool {
+			for i, k := range keys {
+				c := sortCompare(results[a].keys[i], results[b].keys[i])
+				if c == 0 {
+					continue
+				}
+				if k.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	if stmt.offset > 0 {
+		results = results[min(stmt.offset, len(results)):]
+	}
+	if stmt.limit >= 0 && stmt.limit < len(results) {
+		results = results[:stmt.limit]
+	}
+
+	res := &Result{Columns: outputNames(outs), Rows: make([][]any, len(results))}
+	for i, r := range results {
+		res.Rows[i] = r.vals
+	}
+	return res, nil
+}
+
+// outputNames 为输出列去重：重名时先加表别名前缀，仍冲突再追加 _2、_3
+func outputNames(outs []outputColumn) []string {
+	count := map[string]int{}
+	for _, o := range outs {
+		count[o.name]++
+	}
+	names := make([]string, len(outs))
+	used := map[string]bool{}
+	for i, o := range outs {
+		name := o.name
+		if count[name] > 1 && o.table != "" {
+			name = o.table + "." + name
+		}
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", o.name, n)
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// hasAggregate 判断表达式中是否含聚合函数
+// groupCheck 检查分组查询中聚合函数之外引用的列都来自 GROUP BY，
+// 否则同组各行的值不同，结果取决于分组里碰巧第一行是哪条
+type groupCheck struct {
+	scope   *scope
+	aliases map[string]expr
+	cols    map[int]bool    // GROUP BY 中直接出现的列（宽行下标）
+	exprs   map[string]bool // GROUP BY 中的其它表达式（按原文匹配）
+}
+
+func newGroupCheck(sc *scope, groupBy []expr, aliases map[string]expr) *groupCheck {
+	gc := &groupCheck{scope: sc, aliases: aliases, cols: map[int]bool{}, exprs: map[string]bool{}}
+	for _, e := range groupBy {
+		if ref, ok := e.(*columnRef); ok {
+			if idx, err := sc.resolve(ref); err == nil {
+				gc.cols[idx] = true
+				continue
+			}
+			if alias, ok := aliases[ref.name]; ok && ref.table == "" {
+				e = alias
+				if aref, ok := alias.(*columnRef); ok {
+					if idx, err := sc.resolve(aref); err == nil {
+						gc.cols[idx] = true
+						continue
+					}
+				}
+			}
+		}
+		gc.exprs[e.source()] = true
+	}
+	return gc
+}
+
+// check 在 e 中找聚合函数之外、又不属于 GROUP BY 的列；gc 为 nil（非分组查询）时不检查
+func (gc *groupCheck) check(clause string, e expr) error {
+	if gc == nil {
+		return nil
+	}
+	if ref := gc.ungrouped(e); ref != nil {
+		return fmt.Errorf("%s %s: 列 %s 既不在 GROUP BY 中，也不在聚合函数内", clause, e.source(), ref.source())
+	}
+	return nil
+}
+
+func (gc *groupCheck) ungrouped(e expr) *columnRef {
+	if e == nil || gc.exprs[e.source()] {
+		return nil
+	}
+	switch n := e.(type) {
+	case *columnRef:
+		idx, err := gc.scope.resolve(n)
+		if err != nil {
+			if alias, ok := gc.aliases[n.name]; ok && n.table == "" {
+				return gc.ungrouped(alias)
+			}
+			return nil // 未知列由编译阶段报错
+		}
+		if gc.cols[idx] {
+			return nil
+		}
+		return n
+	case *callExpr:
+		if aggregateFuncs[n.name] {
+			return nil
+		}
+		return gc.first(n.args...)
+	case *unaryExpr:
+		return gc.ungrouped(n.x)
+	case *binaryExpr:
+		return gc.first(n.l, n.r)
+	case *likeExpr:
+		return gc.first(n.x, n.pattern)
+	case *inExpr:
+		return gc.first(append([]expr{n.x}, n.list...)...)
+	case *betweenExpr:
+		return gc.first(n.x, n.lo, n.hi)
+	case *isNullExpr:
+		return gc.ungrouped(n.x)
+	case *castExpr:
+		return gc.ungrouped(n.x)
+	case *caseExpr:
+		list := []expr{n.operand, n.elseExpr}
+		for _, w := range n.whens {
+			list = append(list, w.cond, w.result)
+		}
+		return gc.first(list...)
+	}
+	return nil
+}
+
+func (gc *groupCheck) first(list ...expr) *columnRef {
+	for _, e := range list {
+		if ref := gc.ungrouped(e); ref != nil {
+			return ref
+		}
+	}
+	return nil
+}
+
+func hasAggregate(e expr) bool {
+	switch n := e.(type) {
+	case *callExpr:
+		if aggregateFuncs[n.name] {
+			return true
+		}
+		return anyAggregate(n.args...)
+	case *unaryExpr:
+		return hasAggregate(n.x)
+	case *binaryExpr:
+		return anyAggregate(n.l, n.r)
+	case *likeExpr:
+		return anyAggregate(n.x, n.pattern)
+	case *inExpr:
+		return hasAggregate(n.x) || anyAggregate(n.list...)
+	case *betweenExpr:
+		return anyAggregate(n.x, n.lo, n.hi)
+	case *isNullExpr:
+		return hasAggregate(n.x)
+	case *castExpr:
+		return hasAggregate(n.x)
+	case *caseExpr:
+		if n.operand != nil && hasAggregate(n.operand) || n.elseExpr != nil && hasAggregate(n.elseExpr) {
+			return true
+		}
+		for _, w := range n.whens {
+			if anyAggregate(w.cond, w.result) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyAggregate(list ...expr) bool {
+	for _, e := range list {
+		if hasAggregate(e) {
+			return true
+		}
+	}
+	return false
+}
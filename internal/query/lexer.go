@@ -0,0 +1,161 @@
+// Package query 是一个内存 SQL 引擎：解析 SELECT 语句，对调用方装载好的若干张表做
+// 过滤、连接、分组聚合、排序，供 feishu-cli query 在本地关联多维表格与电子表格。
+//
+// 支持的语法（关键字不区分大小写）:
+//
+//	SELECT [DISTINCT] expr [AS alias], ... | * | t.*
+//	FROM source [AS] alias
+//	[[INNER | LEFT [OUTER]] JOIN source [AS] alias ON expr] ...
+//	[WHERE expr] [GROUP BY expr, ...] [HAVING expr]
+//	[ORDER BY expr [ASC|DESC], ...] [LIMIT n [OFFSET m]]
+//
+// source 为点分名称（如 base.<token>.<table_id>），由调用方解释；标识符可用 "..." 或 `...` 引起来。
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // 标识符或关键字
+	tokQuoted           // "..." / `...` 引起来的标识符，永远不是关键字
+	tokString           // '...'
+	tokNumber           // 123 / 1.5 / 1e3
+	tokOp               // 运算符与标点
+)
+
+type token struct {
+	kind tokenKind
+	text string // 原文；tokString / tokQuoted 为去掉引号、处理转义后的内容
+	pos  int    // 在语句中的字节偏移，用于报错
+	end  int    // 结束字节偏移（不含）
+}
+
+// keywords 是保留字：不加引号时不能当作标识符
+var keywords = map[string]bool{
+	"SELECT": true, "DISTINCT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true,
+	"HAVING": true, "ORDER": true, "ASC": true, "DESC": true, "LIMIT": true, "OFFSET": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "OUTER": true, "ON": true, "AS": true,
+	"AND": true, "OR": true, "NOT": true, "IN": true, "LIKE": true, "IS": true, "NULL": true,
+	"BETWEEN": true, "TRUE": true, "FALSE": true, "CASE": true, "WHEN": true, "THEN": true,
+	"ELSE": true, "END": true, "CAST": true,
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) isOp(op string) bool {
+	return t.kind == tokOp && t.text == op
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "语句结尾"
+	case tokString:
+		return "'" + t.text + "'"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex 把语句切分为 token，末尾附一个 tokEOF
+func lex(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	// 以 rune 下标扫描，记录字节偏移
+	offsets := make([]int, len(rs)+1)
+	for i, b := 0, 0; i < len(rs); i++ {
+		offsets[i] = b
+		b += len(string(rs[i]))
+		offsets[i+1] = b
+	}
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(rs) {
+					return nil, fmt.Errorf("位置 %d: 引号 %c 未闭合", offsets[start], r)
+				}
+				if rs[i] == r {
+					if i+1 < len(rs) && rs[i+1] == r { // 连写两个引号表示引号本身
+						sb.WriteRune(r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(rs[i])
+				i++
+			}
+			kind := tokQuoted
+			if r == '\'' {
+				kind = tokString
+			}
+			toks = append(toks, token{kind: kind, text: sb.String(), pos: offsets[start], end: offsets[i]})
+			continue
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.') {
+				i++
+			}
+			if i < len(rs) && (rs[i] == 'e' || rs[i] == 'E') {
+				j := i + 1
+				if j < len(rs) && (rs[j] == '+' || rs[j] == '-') {
+					j++
+				}
+				if j < len(rs) && unicode.IsDigit(rs[j]) {
+					for i = j; i < len(rs) && unicode.IsDigit(rs[i]); i++ {
+					}
+				}
+			}
+			toks = append(toks, token{kind: tokNumber, text: string(rs[start:i]), pos: offsets[start], end: offsets[i]})
+			continue
+		case isIdentRune(r):
+			for i < len(rs) && (isIdentRune(rs[i]) || unicode.IsDigit(rs[i])) {
+				i++
+			}
+			toks = append(toks, token{kind: tokIdent, text: string(rs[start:i]), pos: offsets[start], end: offsets[i]})
+			continue
+		}
+		// 运算符：先匹配两字符的
+		if i+1 < len(rs) {
+			two := string(rs[i : i+2])
+			switch two {
+			case "<=", ">=", "<>", "!=", "||":
+				toks = append(toks, token{kind: tokOp, text: two, pos: offsets[start], end: offsets[i+2]})
+				i += 2
+				continue
+			}
+		}
+		if strings.ContainsRune("=<>+-*/%(),.;", r) {
+			toks = append(toks, token{kind: tokOp, text: string(r), pos: offsets[start], end: offsets[i+1]})
+			i++
+			continue
+		}
+		return nil, fmt.Errorf("位置 %d: 无法识别的字符 %q", offsets[start], r)
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src), end: len(src)}), nil
+}
+
+// isIdentRune 允许字母（含中文）、下划线与 $ 开头；数据表 / 字段名常为中文
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
@@ -0,0 +1,693 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TableRef 是 FROM / JOIN 中引用的数据源
+type TableRef struct {
+	Parts []string // 点分名称的各段，如 [base <app_token> <table_id>]
+	Alias string   // 表别名；未指定时取最后一段
+}
+
+// Key 返回数据源的唯一标识，同一数据源被多次引用（自连接）时只装载一次
+func (r TableRef) Key() string {
+	return strings.Join(r.Parts, ".")
+}
+
+// Select 是解析后的 SELECT 语句
+type Select struct {
+	distinct bool
+	items    []selectItem
+	from     TableRef
+	joins    []joinClause
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderItem
+	limit    int // -1 表示不限
+	offset   int
+}
+
+// Sources 按出现顺序返回语句引用的全部数据源
+func (s *Select) Sources() []TableRef {
+	refs := []TableRef{s.from}
+	for _, j := range s.joins {
+		refs = append(refs, j.table)
+	}
+	return refs
+}
+
+type selectItem struct {
+	expr      expr
+	alias     string
+	star      bool   // * 或 t.*
+	starTable string // t.* 中的 t
+}
+
+type joinClause struct {
+	left  bool
+	table TableRef
+	on    expr
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+}
+
+// expr 是表达式语法树节点；source 为其在语句中的原文，用作未命名输出列的列名
+type expr interface{ source() string }
+
+type node struct{ src string }
+
+func (n node) source() string { return n.src }
+
+type (
+	literal struct {
+		node
+		value any
+	}
+	columnRef struct {
+		node
+		table, name string
+	}
+	unaryExpr struct {
+		node
+		op string
+		x  expr
+	}
+	binaryExpr struct {
+		node
+		op   string
+		l, r expr
+	}
+	likeExpr struct {
+		node
+		x, pattern expr
+		not        bool
+	}
+	inExpr struct {
+		node
+		x    expr
+		list []expr
+		not  bool
+	}
+	betweenExpr struct {
+		node
+		x, lo, hi expr
+		not       bool
+	}
+	isNullExpr struct {
+		node
+		x   expr
+		not bool
+	}
+	caseExpr struct {
+		node
+		operand  expr
+		whens    []whenClause
+		elseExpr expr
+	}
+	whenClause struct{ cond, result expr }
+	castExpr   struct {
+		node
+		x   expr
+		typ string // INTEGER / REAL / TEXT
+	}
+	callExpr struct {
+		node
+		name     string // 大写
+		args     []expr
+		distinct bool
+		star     bool // COUNT(*)
+	}
+)
+
+// castTypes 把 CAST 支持的类型名归一到三种内部类型
+var castTypes = map[string]string{
+	"INTEGER": "INTEGER", "INT": "INTEGER", "BIGINT": "INTEGER",
+	"REAL": "REAL", "FLOAT": "REAL", "DOUBLE": "REAL", "NUMBER": "REAL", "NUMERIC": "REAL", "DECIMAL": "REAL",
+	"TEXT": "TEXT", "STRING": "TEXT", "VARCHAR": "TEXT", "CHAR": "TEXT",
+}
+
+// Parse 解析一条 SELECT 语句，末尾分号可选
+func Parse(sql string) (*Select, error) {
+	toks, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: sql, toks: toks}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	p.acceptOp(";")
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return stmt, nil
+}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) accept(keyword string) bool {
+	if p.peek().is(keyword) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptOp(op string) bool {
+	if p.peek().isOp(op) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(keyword string) error {
+	if !p.accept(keyword) {
+		return fmt.Errorf("位置 %d: 需要 %s，实际为 %s", p.peek().pos, keyword, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return fmt.Errorf("位置 %d: 需要 %q，实际为 %s", p.peek().pos, op, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	return fmt.Errorf("位置 %d: 意外的 %s", t.pos, t)
+}
+
+// span 返回从第 start 个 token 到上一个已消费 token 的原文
+func (p *parser) span(start int) node {
+	if p.i <= start {
+		return node{}
+	}
+	return node{src: p.src[p.toks[start].pos:p.toks[p.i-1].end]}
+}
+
+// isName 判断 token 能否作为名称：引号标识符，或非保留字的普通标识符
+func isName(t token) bool {
+	return t.kind == tokQuoted || (t.kind == tokIdent && !keywords[strings.ToUpper(t.text)])
+}
+
+func (p *parser) parseName(what string) (string, error) {
+	t := p.peek()
+	if !isName(t) {
+		return "", fmt.Errorf("位置 %d: 需要%s，实际为 %s（与关键字同名时请用双引号括起来）", t.pos, what, t)
+	}
+	p.i++
+	return t.text, nil
+}
+
+func (p *parser) parseSelect() (*Select, error) {
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	s := &Select{limit: -1}
+	s.distinct = p.accept("DISTINCT")
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		s.items = append(s.items, item)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.from, err = p.parseTableRef(); err != nil {
+		return nil, err
+	}
+joins:
+	for {
+		var j joinClause
+		switch {
+		case p.accept("LEFT"):
+			p.accept("OUTER")
+			j.left = true
+			if err := p.expect("JOIN"); err != nil {
+				return nil, err
+			}
+		case p.accept("INNER"):
+			if err := p.expect("JOIN"); err != nil {
+				return nil, err
+			}
+		case p.accept("JOIN"):
+		default:
+			break joins
+		}
+		if j.table, err = p.parseTableRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("ON"); err != nil {
+			return nil, err
+		}
+		if j.on, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		s.joins = append(s.joins, j)
+	}
+
+	if p.accept("WHERE") {
+		if s.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		if s.groupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("HAVING") {
+		if s.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			var item orderItem
+			if item.expr, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+			if p.accept("DESC") {
+				item.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			s.orderBy = append(s.orderBy, item)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		if s.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.accept("OFFSET") {
+			if s.offset, err = p.parseCount("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseCount(clause string) (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, fmt.Errorf("位置 %d: %s 需要非负整数，实际为 %s", t.pos, clause, t)
+	}
+	return n, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	if p.acceptOp("*") {
+		return selectItem{star: true}, nil
+	}
+	if t := p.peek(); isName(t) && p.i+2 < len(p.toks) && p.toks[p.i+1].isOp(".") && p.toks[p.i+2].isOp("*") {
+		p.i += 3
+		return selectItem{star: true, starTable: t.text}, nil
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{expr: e}
+	if p.accept("AS") {
+		if item.alias, err = p.parseName("列别名"); err != nil {
+			return selectItem{}, err
+		}
+	} else if isName(p.peek()) {
+		item.alias = p.next().text
+	}
+	return item, nil
+}
+
+// parseTableRef 解析点分数据源名称与可选别名。名称各段之间不能有空白；
+// 数字开头的段会被词法切成数字 + 标识符，这里按相邻 token 重新拼回
+func (p *parser) parseTableRef() (TableRef, error) {
+	first := p.peek()
+	if first.kind != tokIdent && first.kind != tokQuoted && first.kind != tokNumber {
+		return TableRef{}, fmt.Errorf("位置 %d: 需要数据源（如 base.<app_token>.<table_id>），实际为 %s", first.pos, first)
+	}
+	parts := []string{""}
+scan:
+	for prevEnd := -1; ; p.i++ {
+		t := p.peek()
+		if prevEnd >= 0 && t.pos != prevEnd {
+			break
+		}
+		switch {
+		case t.isOp("."):
+			parts = append(parts, "")
+		case t.kind == tokQuoted:
+			parts[len(parts)-1] += t.text
+		case t.kind == tokIdent || t.kind == tokNumber:
+			segs := strings.Split(t.text, ".")
+			parts[len(parts)-1] += segs[0]
+			parts = append(parts, segs[1:]...)
+		default:
+			break scan
+		}
+		prevEnd = t.end
+	}
+	for _, part := range parts {
+		if part == "" {
+			return TableRef{}, fmt.Errorf("位置 %d: 数据源名称 %q 格式不正确", first.pos, p.src[first.pos:p.toks[p.i-1].end])
+		}
+	}
+	ref := TableRef{Parts: parts, Alias: parts[len(parts)-1]}
+	if p.accept("AS") {
+		alias, err := p.parseName("表别名")
+		if err != nil {
+			return TableRef{}, err
+		}
+		ref.Alias = alias
+	} else if isName(p.peek()) {
+		ref.Alias = p.next().text
+	}
+	return ref, nil
+}
+
+func (p *parser) parseExprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.acceptOp(",") {
+			return list, nil
+		}
+	}
+}
+
+func (p *parser) parseExpr() (expr, error) { return p.parseOr() }
+
+func (p *parser) parseOr() (expr, error) {
+	start := p.i
+	l, err := p.parseAnd()
+	for err == nil && p.accept("OR") {
+		var r expr
+		if r, err = p.parseAnd(); err == nil {
+			l = &binaryExpr{node: p.span(start), op: "OR", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	start := p.i
+	l, err := p.parseNot()
+	for err == nil && p.accept("AND") {
+		var r expr
+		if r, err = p.parseNot(); err == nil {
+			l = &binaryExpr{node: p.span(start), op: "AND", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseNot() (expr, error) {
+	start := p.i
+	if p.accept("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{node: p.span(start), op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	start := p.i
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind == tokOp {
+			switch t.text {
+			case "=", "!=", "<>", "<", "<=", ">", ">=":
+				p.i++
+				r, err := p.parseAdditive()
+				if err != nil {
+					return nil, err
+				}
+				op := t.text
+				if op == "<>" {
+					op = "!="
+				}
+				l = &binaryExpr{node: p.span(start), op: op, l: l, r: r}
+				continue
+			}
+			return l, nil
+		}
+		if p.accept("IS") {
+			not := p.accept("NOT")
+			if err := p.expect("NULL"); err != nil {
+				return nil, err
+			}
+			l = &isNullExpr{node: p.span(start), x: l, not: not}
+			continue
+		}
+		save := p.i
+		not := p.accept("NOT")
+		switch {
+		case p.accept("LIKE"):
+			pattern, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			l = &likeExpr{node: p.span(start), x: l, pattern: pattern, not: not}
+		case p.accept("IN"):
+			if err := p.expectOp("("); err != nil {
+				return nil, err
+			}
+			list, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			l = &inExpr{node: p.span(start), x: l, list: list, not: not}
+		case p.accept("BETWEEN"):
+			lo, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("AND"); err != nil {
+				return nil, err
+			}
+			hi, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			l = &betweenExpr{node: p.span(start), x: l, lo: lo, hi: hi, not: not}
+		default:
+			p.i = save
+			return l, nil
+		}
+	}
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	start := p.i
+	l, err := p.parseMultiplicative()
+	for err == nil && (p.peek().isOp("+") || p.peek().isOp("-") || p.peek().isOp("||")) {
+		op := p.next().text
+		var r expr
+		if r, err = p.parseMultiplicative(); err == nil {
+			l = &binaryExpr{node: p.span(start), op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	start := p.i
+	l, err := p.parseUnary()
+	for err == nil && (p.peek().isOp("*") || p.peek().isOp("/") || p.peek().isOp("%")) {
+		op := p.next().text
+		var r expr
+		if r, err = p.parseUnary(); err == nil {
+			l = &binaryExpr{node: p.span(start), op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	start := p.i
+	if p.acceptOp("-") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{node: p.span(start), op: "-", x: x}, nil
+	}
+	if p.acceptOp("+") {
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	start := p.i
+	t := p.next()
+	switch {
+	case t.kind == tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("位置 %d: 无效数字 %s", t.pos, t.text)
+		}
+		return &literal{node: p.span(start), value: f}, nil
+	case t.kind == tokString:
+		return &literal{node: p.span(start), value: t.text}, nil
+	case t.is("NULL"):
+		return &literal{node: p.span(start)}, nil
+	case t.is("TRUE"), t.is("FALSE"):
+		return &literal{node: p.span(start), value: t.is("TRUE")}, nil
+	case t.isOp("("):
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expectOp(")")
+	case t.is("CASE"):
+		return p.parseCase(start)
+	case t.is("CAST"):
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AS"); err != nil {
+			return nil, err
+		}
+		tt := p.next()
+		typ := castTypes[strings.ToUpper(tt.text)]
+		if tt.kind != tokIdent || typ == "" {
+			return nil, fmt.Errorf("位置 %d: CAST 不支持类型 %s（可用 INTEGER / REAL / TEXT）", tt.pos, tt)
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return &castExpr{node: p.span(start), x: x, typ: typ}, nil
+	case isName(t):
+		if t.kind == tokIdent && p.peek().isOp("(") {
+			return p.parseCall(start, t)
+		}
+		if p.acceptOp(".") {
+			// 限定列名的列部分允许与关键字同名，如 t.order
+			col := p.next()
+			if col.kind != tokIdent && col.kind != tokQuoted {
+				return nil, fmt.Errorf("位置 %d: %s. 之后需要列名，实际为 %s", col.pos, t.text, col)
+			}
+			return &columnRef{node: p.span(start), table: t.text, name: col.text}, nil
+		}
+		return &columnRef{node: p.span(start), name: t.text}, nil
+	}
+	return nil, p.unexpected(t)
+}
+
+func (p *parser) parseCall(start int, name token) (expr, error) {
+	p.i++ // (
+	call := &callExpr{name: strings.ToUpper(name.text)}
+	switch {
+	case p.acceptOp("*"):
+		call.star = true
+	case p.peek().isOp(")"):
+	default:
+		call.distinct = p.accept("DISTINCT")
+		args, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		call.args = args
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	call.node = p.span(start)
+	return call, nil
+}
+
+func (p *parser) parseCase(start int) (expr, error) {
+	c := &caseExpr{}
+	var err error
+	if !p.peek().is("WHEN") {
+		if c.operand, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.accept("WHEN") {
+		var w whenClause
+		if w.cond, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		if w.result, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, fmt.Errorf("位置 %d: CASE 至少需要一个 WHEN", p.peek().pos)
+	}
+	if p.accept("ELSE") {
+		if c.elseExpr, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("END"); err != nil {
+		return nil, err
+	}
+	c.node = p.span(start)
+	return c, nil
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testTables = map[string]*Table{
+	"base.bas1.tblEmp": {
+		Columns: []string{"record_id", "工号", "姓名", "部门", "薪资"},
+		Rows: [][]any{
+			{"rec1", "E1", "张三", "研发", int64(300)},
+			{"rec2", "E2", "李四", "研发", json.Number("500")},
+			{"rec3", "E3", "王五", "销售", 200.0},
+			{"rec4", "E4", "赵六", nil, nil},
+		},
+	},
+	"sheet.sht1.s1": {
+		Columns: []string{"工号", "绩效"},
+		Rows: [][]any{
+			{"E1", "A"},
+			{"E2", "B"},
+			{"E3", "A"},
+			{"E9", "C"},
+		},
+	},
+}
+
+func runQuery(t *testing.T, sql string) *Result {
+	t.Helper()
+	stmt, err := Parse(sql)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", sql, err)
+	}
+	res, err := Execute(stmt, func(ref TableRef) (*Table, error) {
+		if tb, ok := testTables[ref.Key()]; ok {
+			return tb, nil
+		}
+		return nil, fmt.Errorf("no table %s", ref.Key())
+	})
+	if err != nil {
+		t.Fatalf("Execute(%q): %v", sql, err)
+	}
+	return res
+}
+
+// rowsText 把结果行渲染为 "a|b;c|d" 便于断言，NULL 写作 ∅
+func rowsText(res *Result) string {
+	var rows []string
+	for _, row := range res.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			if v == nil {
+				cells[i] = "∅"
+			} else {
+				cells[i] = toText(v)
+			}
+		}
+		rows = append(rows, strings.Join(cells, "|"))
+	}
+	return strings.Join(rows, ";")
+}
+
+func TestQueryExecute(t *testing.T) {
+	cases := []struct {
+		sql, cols, rows string
+	}{
+		{
+			`SELECT 姓名, 薪资 * 2 AS 双倍 FROM base.bas1.tblEmp WHERE 薪资 >= 300 ORDER BY 薪资 DESC`,
+			"姓名,双倍", "李四|1000;张三|600",
+		},
+		{
+			`select e.姓名, s.绩效 from base.bas1.tblEmp e join sheet.sht1.s1 s on e.工号 = s.工号 order by 1`,
+			"姓名,绩效", "张三|A;李四|B;王五|A",
+		},
+		{
+			`SELECT e.工号, s.绩效 FROM base.bas1.tblEmp AS e LEFT JOIN sheet.sht1.s1 AS s ON s.工号 = e.工号 WHERE s.绩效 IS NULL`,
+			"工号,绩效", "E4|∅",
+		},
+		{
+			`SELECT 部门, COUNT(*) n, SUM(薪资) total, AVG(薪资), GROUP_CONCAT(姓名, '/') FROM base.bas1.tblEmp GROUP BY 部门 HAVING COUNT(*) >= 1 ORDER BY total DESC`,
+			"部门,n,total,AVG(薪资),GROUP_CONCAT(姓名, '/')", "研发|2|800|400|张三/李四;销售|1|200|200|王五;∅|1|∅|∅|赵六",
+		},
+		{
+			`SELECT s.绩效, COUNT(DISTINCT e.部门) AS 部门数, MAX(e.薪资) FROM sheet.sht1.s1 s LEFT JOIN base.bas1.tblEmp e ON e.工号 = s.工号 GROUP BY s.绩效 ORDER BY s.绩效`,
+			"绩效,部门数,MAX(e.薪资)", "A|2|300;B|1|500;C|0|∅",
+		},
+		{
+			`SELECT COALESCE(部门, '未分配') AS d, COUNT(*) FROM base.bas1.tblEmp GROUP BY d HAVING d <> '销售' ORDER BY d`,
+			"d,COUNT(*)", "未分配|1;研发|2",
+		},
+		{
+			`SELECT COUNT(*), SUM(薪资) FROM base.bas1.tblEmp WHERE 姓名 = '不存在'`,
+			"COUNT(*),SUM(薪资)", "0|∅",
+		},
+		{
+			`SELECT DISTINCT 部门 FROM base.bas1.tblEmp WHERE 部门 IS NOT NULL ORDER BY 部门 LIMIT 5`,
+			"部门", "研发;销售",
+		},
+		{
+			`SELECT 姓名 FROM base.bas1.tblEmp WHERE 工号 IN ('E1', 'E3') OR 姓名 LIKE '%六' ORDER BY 工号 LIMIT 2 OFFSET 1`,
+			"姓名", "王五;赵六",
+		},
+		{
+			`SELECT 工号, CASE WHEN 薪资 BETWEEN 250 AND 400 THEN '中' WHEN 薪资 > 400 THEN '高' ELSE '低' END 档位, COALESCE(部门, '未分配') || '-' || UPPER(工号) AS 标签 FROM base.bas1.tblEmp`,
+			"工号,档位,标签", "E1|中|研发-E1;E2|高|研发-E2;E3|低|销售-E3;E4|低|未分配-E4",
+		},
+		{
+			`SELECT SUBSTR(姓名, 1, 1) AS 姓, LENGTH(姓名), ROUND(薪资 / 3, 2), CAST(薪资 / 3 AS INTEGER) FROM base.bas1.tblEmp WHERE 薪资 IS NOT NULL AND NOT 部门 = '销售'`,
+			"姓,LENGTH(姓名),ROUND(薪资 / 3, 2),CAST(薪资 / 3 AS INTEGER)", "张|2|100|100;李|2|166.67|166",
+		},
+		{
+			`SELECT * FROM sheet.sht1.s1 a JOIN sheet.sht1.s1 b ON a.绩效 = b.绩效 AND a.工号 < b.工号`,
+			"a.工号,a.绩效,b.工号,b.绩效", "E1|A|E3|A",
+		},
+		{
+			`SELECT s.*, e.姓名 FROM sheet.sht1.s1 s LEFT JOIN base.bas1.tblEmp e ON e.工号 = s.工号 WHERE e.record_id IS NULL`,
+			"工号,绩效,姓名", "E9|C|∅",
+		},
+	}
+	for _, tc := range cases {
+		res := runQuery(t, tc.sql)
+		if got := strings.Join(res.Columns, ","); got != tc.cols {
+			t.Errorf("%s\ncolumns = %s, want %s", tc.sql, got, tc.cols)
+		}
+		if got := rowsText(res); got != tc.rows {
+			t.Errorf("%s\nrows = %s, want %s", tc.sql, got, tc.rows)
+		}
+	}
+}
+
+func TestQueryNonEquiJoin(t *testing.T) {
+	// ON 中没有等值条件时退化为逐对比较
+	res := runQuery(t, `SELECT e.姓名, s.工号 FROM base.bas1.tblEmp e JOIN sheet.sht1.s1 s ON s.工号 > e.工号 AND e.薪资 > 400`)
+	if got := rowsText(res); got != "李四|E3;李四|E9" {
+		t.Errorf("rows = %s", got)
+	}
+}
+
+func TestParseTableRef(t *testing.T) {
+	stmt, err := Parse(`SELECT * FROM sheet.shtABC."0b1a2c!A1:C9" AS s JOIN base.bas1.tbl2 ON 1 = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := stmt.Sources()
+	want := []TableRef{
+		{Parts: []string{"sheet", "shtABC", "0b1a2c!A1:C9"}, Alias: "s"},
+		{Parts: []string{"base", "bas1", "tbl2"}, Alias: "tbl2"},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs = %+v", refs)
+	}
+	// 数字开头的段被词法切开后应拼回原样
+	stmt, err = Parse(`SELECT * FROM sheet.sht1.0b1a2c`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stmt.Sources()[0].Parts; !reflect.DeepEqual(got, []string{"sheet", "sht1", "0b1a2c"}) {
+		t.Errorf("parts = %q", got)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	cases := map[string]string{
+		`SELECT FROM base.bas1.tblEmp`:                                          "意外的",
+		`SELECT 姓名 FROM base.bas1.tblEmp WHERE`:                                 "意外的 语句结尾",
+		`SELECT 'abc FROM base.bas1.tblEmp`:                                     "未闭合",
+		`SELECT 不存在 FROM base.bas1.tblEmp`:                                      "未知的列 不存在",
+		`SELECT 工号 FROM base.bas1.tblEmp e JOIN sheet.sht1.s1 s ON e.工号 = s.工号`: "有歧义",
+		`SELECT 姓名 FROM base.bas1.tblEmp WHERE COUNT(*) > 1`:                    "不能使用聚合函数",
+		`SELECT NOPE(姓名) FROM base.bas1.tblEmp`:                                 "未知函数 NOPE",
+		`SELECT 姓名 FROM base.bas1.tblEmp ORDER BY 3`:                            "序号超出",
+		`SELECT * FROM base.bas1.tblEmp JOIN base.bas1.tblEmp ON 1 = 1`:         "别名",
+		`SELECT 姓名 || 1, 姓名 * 2 FROM base.bas1.tblEmp`:                          "不是数字",
+		`SELECT * FROM base.bas1.missing`:                                       "no table",
+		`SELECT x.* FROM base.bas1.tblEmp`:                                      "未知的表别名",
+		`SELECT CAST(薪资 AS DATE) FROM base.bas1.tblEmp`:                         "CAST 不支持",
+		`SELECT 姓名 FROM base.bas1.tblEmp LIMIT -1`:                              "非负整数",
+		`SELECT SUM(COUNT(*)) FROM base.bas1.tblEmp`:                            "不能使用聚合函数",
+		`SELECT 姓名 FROM base.bas1.tblEmp GROUP BY 部门`:                           "列 姓名 既不在 GROUP BY 中",
+		`SELECT 部门, 姓名 || '/' FROM base.bas1.tblEmp GROUP BY 部门`:                "列 姓名 既不在 GROUP BY 中",
+		`SELECT 部门 FROM base.bas1.tblEmp GROUP BY 部门 HAVING 薪资 > 1`:             "HAVING 薪资 > 1: 列 薪资",
+		`SELECT 部门, COUNT(*) FROM base.bas1.tblEmp GROUP BY 部门 ORDER BY 姓名`:     "ORDER BY 姓名: 列 姓名",
+		`SELECT 姓名, COUNT(*) FROM base.bas1.tblEmp`:                             "列 姓名 既不在 GROUP BY 中",
+		`SELECT * FROM base.bas1.tblEmp GROUP BY 部门`:                            "SELECT *",
+	}
+	for sql, want := range cases {
+		stmt, err := Parse(sql)
+		if err == nil {
+			_, err = Execute(stmt, func(ref TableRef) (*Table, error) {
+				if tb, ok := testTables[ref.Key()]; ok {
+					return tb, nil
+				}
+				return nil, fmt.Errorf("no table %s", ref.Key())
+			})
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s\nerr = %v, want containing %q", sql, err, want)
+		}
+	}
+}
+
+func TestLikeMatch(t *testing.T) {
+	cases := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"Hello", "h%", true},
+		{"Hello", "%LL_", true},
+		{"Hello", "%x%", false},
+		{"研发中心", "研发%", true},
+		{"研发中心", "_发__", true},
+		{"abc", "a%c%", true},
+		{"", "%", true},
+		{"ab", "a", false},
+	}
+	for _, tc := range cases {
+		if got := likeMatch(tc.s, tc.pattern); got != tc.want {
+			t.Errorf("likeMatch(%q, %q) = %v", tc.s, tc.pattern, got)
+		}
+	}
+}
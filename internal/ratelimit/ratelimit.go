@@ -0,0 +1,354 @@
+// Package ratelimit 实现跨进程共享的应用级客户端限流。
+//
+// 飞书开放平台的频控按应用（app_id）+ 接口统计，多个 feishu-cli 进程并发调用同一应用时，
+// 进程内的 limiter（如 client.AcquireDocWriteSlot）互不知情，只能靠 429 后退避兜底，
+// 并发一高就演变成集体退避。这里把令牌桶状态放到 ~/.feishu-cli/ratelimit/<app_id>.json，
+// 用文件锁串行化读改写，所有进程共享同一份配额：
+//
+//   - 桶按 API 族划分：/open-apis/<family>/... 的 family（docx、im、bitable…）
+//   - 速率按端点类配置：<family>:write / <family>:read 优先，其次 <family>，最后 default
+//   - 任一进程收到 HTTP 429 时把该桶冻结到 x-ogw-ratelimit-reset 之后，其余进程同步暂停，
+//     而不是各自撞墙再各自退避
+//
+// 限流只是保护层：状态文件或锁不可用时放行请求并告警一次，不让命令失败。
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/riba2534/feishu-cli/internal/filelock"
+)
+
+// DirName 是状态目录名，位于 ~/.feishu-cli/ 下，所有 profile 共用（配额属于应用而非 profile）。
+const DirName = "ratelimit"
+
+// DefaultQPS 是未单独配置的 API 族的默认速率（次/秒）。
+const DefaultQPS = 10
+
+// window 是 doctor 展示"最近用量"的统计窗口。
+const window = time.Minute
+
+// resetHeader 是飞书返回的限流重置秒数。
+const resetHeader = "x-ogw-ratelimit-reset"
+
+// defaultPenalty 是 429 未带重置时间时整桶冻结的时长。
+const defaultPenalty = time.Second
+
+// Config 是限流配置。Classes 的 key 为 default、<family>、<family>:read 或 <family>:write，
+// 值为每秒请求数（可为小数，如 100 次/分钟 ≈ 1.6）。
+type Config struct {
+	Default float64
+	Classes map[string]float64
+}
+
+// HTTPClient 与 larkcore.HttpClient 同形，避免本包依赖 SDK。
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// bucket 是一个 API 族的共享令牌桶。
+type bucket struct {
+	Tokens       float64   `json:"tokens"`
+	Updated      time.Time `json:"updated"`
+	WindowStart  time.Time `json:"window_start"`
+	WindowCount  int       `json:"window_count"`
+	BlockedUntil time.Time `json:"blocked_until"`
+}
+
+// state 是 <app_id>.json 的磁盘格式。
+type state struct {
+	Buckets map[string]*bucket `json:"buckets"`
+}
+
+// Limiter 按 app_id 共享配额。
+type Limiter struct {
+	dir   string
+	appID string
+	cfg   Config
+	now   func() time.Time
+
+	warnOnce sync.Once
+}
+
+// New 创建 limiter；rootDir 为 ~/.feishu-cli。
+func New(rootDir, appID string, cfg Config) *Limiter {
+	if qps := cfg.Classes["default"]; qps > 0 {
+		cfg.Default = qps
+	}
+	if cfg.Default <= 0 {
+		cfg.Default = DefaultQPS
+	}
+	return &Limiter{dir: filepath.Join(rootDir, DirName), appID: appID, cfg: cfg, now: time.Now}
+}
+
+// DefaultQPS 返回未单独配置的 API 族所用的速率。
+func (l *Limiter) DefaultQPS() float64 {
+	return l.cfg.Default
+}
+
+// Class 返回请求所属的桶名与速率。
+func (l *Limiter) Class(method, path string) (string, float64) {
+	return l.cfg.resolve(Family(path), method)
+}
+
+var familyRe = regexp.MustCompile(`^/open-apis/([A-Za-z0-9_]+)/`)
+
+// Family 从 /open-apis/<family>/<version>/... 提取 API 族；非 OpenAPI 路径归入 other。
+func Family(path string) string {
+	if m := familyRe.FindStringSubmatch(path); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return "other"
+}
+
+func (c Config) resolve(family, method string) (string, float64) {
+	kind := "read"
+	if method != http.MethodGet && method != http.MethodHead {
+		kind = "write"
+	}
+	if qps, ok := c.Classes[family+":"+kind]; ok && qps > 0 {
+		return family + ":" + kind, qps
+	}
+	if qps, ok := c.Classes[family]; ok && qps > 0 {
+		return family, qps
+	}
+	return family, c.Default
+}
+
+// Wait 阻塞到 class 桶拿到一个令牌，或 done 被关闭。
+func (l *Limiter) Wait(done <-chan struct{}, class string, qps float64) error {
+	for {
+		wait, err := l.take(class, qps)
+		if err != nil {
+			l.warn(err)
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-done:
+			t.Stop()
+			return fmt.Errorf("等待限流配额时被取消")
+		case <-t.C:
+		}
+	}
+}
+
+// take 在文件锁内尝试取令牌；拿不到时返回需要等待的时长。
+func (l *Limiter) take(class string, qps float64) (time.Duration, error) {
+	var wait time.Duration
+	err := l.update(func(s *state) {
+		now := l.now()
+		b := s.bucket(class, qps, now)
+		b.refill(qps, now)
+		if now.Before(b.BlockedUntil) {
+			wait = b.BlockedUntil.Sub(now)
+			return
+		}
+		if b.Tokens < 1 {
+			wait = max(time.Duration((1-b.Tokens)/qps*float64(time.Second)), time.Millisecond)
+			return
+		}
+		b.Tokens--
+		if now.Sub(b.WindowStart) >= window {
+			b.WindowStart, b.WindowCount = now, 0
+		}
+		b.WindowCount++
+	})
+	return wait, err
+}
+
+// Penalize 在收到 429 后清空 class 桶并冻结 d，让所有进程一起暂停。
+func (l *Limiter) Penalize(class string, qps float64, d time.Duration) {
+	err := l.update(func(s *state) {
+		now := l.now()
+		b := s.bucket(class, qps, now)
+		b.Tokens, b.Updated = 0, now
+		if until := now.Add(d); until.After(b.BlockedUntil) {
+			b.BlockedUntil = until
+		}
+	})
+	if err != nil {
+		l.warn(err)
+	}
+}
+
+func (s *state) bucket(class string, qps float64, now time.Time) *bucket {
+	b, ok := s.Buckets[class]
+	if !ok {
+		b = &bucket{Tokens: burst(qps), Updated: now, WindowStart: now}
+		s.Buckets[class] = b
+	}
+	return b
+}
+
+func (b *bucket) refill(qps float64, now time.Time) {
+	if elapsed := now.Sub(b.Updated).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(b.Tokens+elapsed*qps, burst(qps))
+		b.Updated = now
+	}
+}
+
+// burst 是桶容量：一秒的配额，至少 1，允许小爆发。
+func burst(qps float64) float64 {
+	return math.Max(qps, 1)
+}
+
+func (l *Limiter) path() string {
+	return filepath.Join(l.dir, sanitize(l.appID)+".json")
+}
+
+// update 在文件锁内读出状态、交给 fn 修改并写回；状态文件损坏时从空状态重来。
+func (l *Limiter) update(fn func(*state)) error {
+	if err := os.MkdirAll(l.dir, 0700); err != nil {
+		return fmt.Errorf("创建限流状态目录失败: %w", err)
+	}
+	path := l.path()
+	return filelock.With(path+".lock", func() error {
+		s := readState(path)
+		fn(s)
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("写入限流状态失败: %w", err)
+		}
+		return nil
+	})
+}
+
+func readState(path string) *state {
+	s := &state{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, s)
+	}
+	if s.Buckets == nil {
+		s.Buckets = map[string]*bucket{}
+	}
+	return s
+}
+
+func (l *Limiter) warn(err error) {
+	l.warnOnce.Do(func() {
+		fmt.Fprintf(os.Stderr, "⚠️  跨进程限流不可用，本次不限速: %v\n", err)
+	})
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+func sanitize(appID string) string {
+	if appID == "" {
+		return "_"
+	}
+	return unsafeChars.ReplaceAllString(appID, "_")
+}
+
+// Client 把 Limiter 包在 HTTP 客户端外层：发请求前取令牌，遇到 429 冻结对应桶。
+type Client struct {
+	limiter *Limiter
+	inner   HTTPClient
+}
+
+// NewClient 创建限流 HTTP 客户端；inner 为 nil 时使用 http.DefaultClient。
+func NewClient(l *Limiter, inner HTTPClient) *Client {
+	if inner == nil {
+		inner = http.DefaultClient
+	}
+	return &Client{limiter: l, inner: inner}
+}
+
+// Do 实现 HTTPClient。
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	class, qps := c.limiter.Class(req.Method, req.URL.Path)
+	if err := c.limiter.Wait(req.Context().Done(), class, qps); err != nil {
+		return nil, err
+	}
+	resp, err := c.inner.Do(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.Penalize(class, qps, penaltyFor(resp.Header))
+	}
+	return resp, err
+}
+
+// penaltyFor 按 x-ogw-ratelimit-reset 决定冻结时长。
+func penaltyFor(h http.Header) time.Duration {
+	if sec, err := strconv.ParseFloat(h.Get(resetHeader), 64); err == nil && sec > 0 {
+		return time.Duration(sec * float64(time.Second))
+	}
+	return defaultPenalty
+}
+
+// Usage 是 doctor 展示的单个桶用量。
+type Usage struct {
+	Class        string    `json:"class"`
+	QPS          float64   `json:"qps"`
+	Available    float64   `json:"available"`
+	Burst        float64   `json:"burst"`
+	LastMinute   int       `json:"last_minute"`
+	BlockedUntil time.Time `json:"blocked_until"`
+}
+
+// Usage 读取当前各桶的剩余配额与最近一分钟请求数（只读，按当前时间推算回补）。
+func (l *Limiter) Usage() ([]Usage, error) {
+	var out []Usage
+	path := l.path()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	err := filelock.With(path+".lock", func() error {
+		s := readState(path)
+		now := l.now()
+		for class, b := range s.Buckets {
+			family, kind, _ := strings.Cut(class, ":")
+			method := http.MethodGet
+			if kind == "write" {
+				method = http.MethodPost
+			}
+			_, qps := l.cfg.resolve(family, method)
+			b.refill(qps, now)
+			u := Usage{Class: class, QPS: qps, Available: b.Tokens, Burst: burst(qps)}
+			if now.Sub(b.WindowStart) < window {
+				u.LastMinute = b.WindowCount
+			}
+			if now.Before(b.BlockedUntil) {
+				u.BlockedUntil = b.BlockedUntil
+			}
+			out = append(out, u)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out, err
+}
+
+// ParseClasses 解析 "docx:write=3,im=50" 形式的端点类配置（FEISHU_RATE_LIMIT_CLASSES）。
+func ParseClasses(s string) (map[string]float64, error) {
+	out := map[string]float64{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		qps, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || qps <= 0 {
+			return nil, fmt.Errorf("限流配置 %q 非法，应为 <class>=<每秒次数>，如 docx:write=3", item)
+		}
+		out[strings.ToLower(strings.TrimSpace(key))] = qps
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassResolution(t *testing.T) {
+	l := New(t.TempDir(), "cli_a", Config{Classes: map[string]float64{
+		"default":    5,
+		"docx:write": 3,
+		"im":         50,
+	}})
+	tests := []struct {
+		method, path string
+		class        string
+		qps          float64
+	}{
+		{http.MethodPost, "/open-apis/docx/v1/documents/abc/blocks/abc/children", "docx:write", 3},
+		{http.MethodGet, "/open-apis/docx/v1/documents/abc/raw_content", "docx", 5},
+		{http.MethodPost, "/open-apis/im/v1/messages", "im", 50},
+		{http.MethodGet, "/open-apis/im/v1/messages", "im", 50},
+		{http.MethodPost, "/open-apis/auth/v3/tenant_access_token/internal", "auth", 5},
+		{http.MethodGet, "/healthz", "other", 5},
+	}
+	for _, tc := range tests {
+		class, qps := l.Class(tc.method, tc.path)
+		if class != tc.class || qps != tc.qps {
+			t.Errorf("Class(%s %s) = %s,%g, want %s,%g", tc.method, tc.path, class, qps, tc.class, tc.qps)
+		}
+	}
+}
+
+// TestSharedBudgetAcrossLimiters 用两个独立 Limiter（模拟两个进程）共享同一状态目录，
+// 合计请求速率应受同一个桶约束。
+func TestSharedBudgetAcrossLimiters(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Classes: map[string]float64{"docx": 20}}
+	limiters := []*Limiter{New(dir, "cli_a", cfg), New(dir, "cli_a", cfg)}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, l := range limiters {
+		wg.Add(1)
+		go func(l *Limiter) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if err := l.Wait(nil, "docx", 20); err != nil {
+					t.Error(err)
+				}
+			}
+		}(l)
+	}
+	wg.Wait()
+	// 40 次请求，桶容量 20、回补 20/s：至少要等约 1s
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("40 次请求只用了 %v，跨实例配额未共享", elapsed)
+	}
+
+	usage, err := limiters[0].Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || usage[0].Class != "docx" || usage[0].LastMinute != 40 {
+		t.Fatalf("usage = %+v", usage)
+	}
+
+	// 不同 app_id 互不影响
+	other := New(dir, "cli_b", cfg)
+	if wait, err := other.take("docx", 20); err != nil || wait != 0 {
+		t.Fatalf("cli_b take = %v, %v", wait, err)
+	}
+}
+
+func TestClientPenalizesOn429(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set(resetHeader, "0.3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	l := New(t.TempDir(), "cli_a", Config{Default: 100})
+	c := NewClient(l, srv.Client())
+	get := func() {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/open-apis/im/v1/chats", nil)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	usage, _ := l.Usage()
+	if len(usage) != 1 || usage[0].BlockedUntil.IsZero() {
+		t.Fatalf("429 后应冻结 im 桶: %+v", usage)
+	}
+	start := time.Now()
+	get()
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("冻结期内请求未等待: %v", elapsed)
+	}
+}
+
+func TestParseClasses(t *testing.T) {
+	got, err := ParseClasses(" docx:write=3, IM=1.5 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["docx:write"] != 3 || got["im"] != 1.5 || len(got) != 2 {
+		t.Fatalf("ParseClasses = %v", got)
+	}
+	for _, bad := range []string{"docx", "docx=0", "docx=abc"} {
+		if _, err := ParseClasses(bad); err == nil {
+			t.Errorf("ParseClasses(%q) 应报错", bad)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package secretstore
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/riba2534/feishu-cli/internal/filelock"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// FileName 是 file 后端的加密文件名，位于 ~/.feishu-cli/ 下，所有 profile 共用。
+const FileName = "secrets.enc"
+
+// pbkdf2Iterations 是新建加密文件时的 PBKDF2 迭代次数（OWASP 2023 对 SHA-256 的建议值）。
+// 迭代次数写进文件头，调整后旧文件仍按原次数解密。
+var pbkdf2Iterations = 600000
+
+// fileEnvelope 是 secrets.enc 的磁盘格式，密文解开后是 key → 明文 的 JSON 对象。
+type fileEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type fileStore struct {
+	path string
+}
+
+func newFileStore(rootDir string) *fileStore {
+	return &fileStore{path: filepath.Join(rootDir, FileName)}
+}
+
+// 进程内缓存口令与派生密钥：一次命令可能多次读写（config + token），只提示一次、只派生一次。
+// 只缓存已验证的口令（解密成功或新建文件写入成功），输错的口令不会一直沿用。
+var (
+	passMu      sync.Mutex
+	passphrase  string
+	derivedKeys = map[string][]byte{} // salt|iterations → key
+)
+
+// promptFunc 在终端上提示输入口令，测试中可替换。
+var promptFunc = promptPassphrase
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	return s.update(func(secrets map[string]string) error {
+		secrets[key] = value
+		return nil
+	})
+}
+
+func (s *fileStore) Delete(key string) error {
+	return s.update(func(secrets map[string]string) error {
+		if _, ok := secrets[key]; !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		delete(secrets, key)
+		return nil
+	})
+}
+
+// update 在跨进程文件锁内完成 读取 → 修改 → 写回，避免多个进程同时写入时互相覆盖。
+func (s *fileStore) update(fn func(secrets map[string]string) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	return filelock.With(s.path+".lock", func() error {
+		secrets, env, err := s.load()
+		if err != nil {
+			return err
+		}
+		if err := fn(secrets); err != nil {
+			return err
+		}
+		return s.save(secrets, env)
+	})
+}
+
+// load 解密整个文件；文件不存在时返回空集合与 nil envelope（首次写入时新建）。
+func (s *fileStore) load() (map[string]string, *fileEnvelope, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("读取 %s 失败: %w", s.path, err)
+	}
+	var env fileEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, nil, fmt.Errorf("解析 %s 失败: %w", s.path, err)
+	}
+	if env.Version != 1 || env.KDF != "pbkdf2-sha256" {
+		return nil, nil, fmt.Errorf("%s 格式不受支持（version=%d kdf=%s）", s.path, env.Version, env.KDF)
+	}
+	gcm, remember, err := s.cipher(&env, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("解密 %s 失败：口令错误或文件已损坏", s.path)
+	}
+	remember()
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("解析 %s 内容失败: %w", s.path, err)
+	}
+	return secrets, &env, nil
+}
+
+// save 用新 nonce 重新加密并原子写回；env 为空时生成新 salt。调用方需持有文件锁。
+func (s *fileStore) save(secrets map[string]string, env *fileEnvelope) error {
+	if env == nil {
+		env = &fileEnvelope{Version: 1, KDF: "pbkdf2-sha256", Iterations: pbkdf2Iterations, Salt: make([]byte, 16)}
+		if _, err := rand.Read(env.Salt); err != nil {
+			return fmt.Errorf("生成 salt 失败: %w", err)
+		}
+	}
+	gcm, remember, err := s.cipher(env, true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plain, nil)
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 临时文件建在同目录下且名字唯一，保证 rename 原子且不同写入者互不踩踏
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("写入 %s 失败: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("写入 %s 失败: %w", s.path, err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("替换 %s 失败: %w", s.path, err)
+	}
+	remember()
+	return nil
+}
+
+// cipher 返回 env 对应的 AES-GCM；remember 在口令被验证后调用，把口令与密钥写入进程内缓存。
+func (s *fileStore) cipher(env *fileEnvelope, creating bool) (cipher.AEAD, func(), error) {
+	key, pass, err := deriveKey(env.Salt, env.Iterations, creating)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, func() { rememberKey(env.Salt, env.Iterations, pass, key) }, nil
+}
+
+func derivedKeyID(salt []byte, iterations int) string { return fmt.Sprintf("%x|%d", salt, iterations) }
+
+// deriveKey 取得口令（已验证的缓存 → 环境变量 → 终端提示）并派生 32 字节 AES 密钥。
+// 结果不在这里缓存：口令可能是错的，由调用方验证后通过 rememberKey 写入缓存。
+func deriveKey(salt []byte, iterations int, creating bool) ([]byte, string, error) {
+	passMu.Lock()
+	defer passMu.Unlock()
+	pass := passphrase
+	if key, ok := derivedKeys[derivedKeyID(salt, iterations)]; ok {
+		return key, pass, nil
+	}
+	if pass == "" {
+		pass = os.Getenv(EnvPassphrase)
+	}
+	if pass == "" {
+		p, err := promptFunc(creating)
+		if err != nil {
+			return nil, "", err
+		}
+		pass = p
+	}
+	return pbkdf2.Key([]byte(pass), salt, iterations, 32, sha256.New), pass, nil
+}
+
+func rememberKey(salt []byte, iterations int, pass string, key []byte) {
+	passMu.Lock()
+	defer passMu.Unlock()
+	passphrase = pass
+	derivedKeys[derivedKeyID(salt, iterations)] = key
+}
+
+// promptPassphrase 在终端上无回显读取口令；新建加密文件时要求输入两次确认。
+func promptPassphrase(confirm bool) (string, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return "", fmt.Errorf("secret 存储需要口令：请设置环境变量 %s", EnvPassphrase)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	first, err := readHidden(reader, "请输入 secret 存储口令: ")
+	if err != nil {
+		return "", err
+	}
+	if first == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	if confirm {
+		second, err := readHidden(reader, "请再次输入口令: ")
+		if err != nil {
+			return "", err
+		}
+		if first != second {
+			return "", fmt.Errorf("两次输入的口令不一致")
+		}
+	}
+	return first, nil
+}
+
+// readHidden 借助 stty 关闭回显读一行；stty 不可用（如 Windows）时退化为普通读取。
+func readHidden(reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if stty("-echo") == nil {
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取口令失败（非交互环境请设置 %s）: %w", EnvPassphrase, err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,69 @@
+package secretstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// helperStore 把 secret 的读写委托给外部凭证助手（如对接 pass、1Password CLI、系统钥匙串的脚本）。
+//
+// 协议：
+//
+//	<helper> get <key>    stdout 输出明文（末尾换行会被去掉）；key 不存在时以退出码 44 结束
+//	<helper> store <key>  从 stdin 读取明文
+//	<helper> erase <key>  删除；key 不存在也应返回 0
+type helperStore struct {
+	argv []string
+}
+
+// helperNotFoundExit 是 helper 表示 key 不存在的退出码。
+const helperNotFoundExit = 44
+
+func newHelperStore() (*helperStore, error) {
+	argv := strings.Fields(os.Getenv(EnvHelper))
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("helper 后端需要设置 %s（例如 %s=\"/usr/local/bin/feishu-secret\"）", EnvHelper, EnvHelper)
+	}
+	return &helperStore{argv: argv}, nil
+}
+
+func (h *helperStore) Get(key string) (string, error) {
+	out, err := h.run("get", key, "")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+func (h *helperStore) Set(key, value string) error {
+	_, err := h.run("store", key, value)
+	return err
+}
+
+func (h *helperStore) Delete(key string) error {
+	_, err := h.run("erase", key, "")
+	return err
+}
+
+func (h *helperStore) run(action, key, stdin string) (string, error) {
+	args := append(append([]string{}, h.argv[1:]...), action, key)
+	cmd := exec.Command(h.argv[0], args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == helperNotFoundExit {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("凭证助手 %s %s 失败: %s", h.argv[0], action, msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,184 @@
+// Package secretstore 为 config.yaml 的 app_secret 与 token.json 的 access/refresh token
+// 提供可插拔的密文存储。
+//
+// 启用加密存储后，配置文件里不再保存明文，而是保存一个引用：
+//
+//	secret://<backend>/<key>
+//
+// 读取时按引用中的 backend 取回明文；没有引用前缀的值视为明文，保持向后兼容。
+//
+// 后端：
+//   - plaintext：默认，直接写明文（历史行为）
+//   - file：口令加密文件 ~/.feishu-cli/secrets.enc（AES-256-GCM，PBKDF2-SHA256 派生密钥），
+//     口令取自 FEISHU_SECRET_PASSPHRASE，终端交互时可提示输入
+//   - helper：调用外部凭证助手 FEISHU_SECRET_HELPER，协议为
+//     `<helper> get <key>`（stdout 输出明文）/ `<helper> store <key>`（stdin 读明文）/ `<helper> erase <key>`
+//
+// 新写入使用哪个后端由 FEISHU_SECRET_STORE 决定；已经是引用的字段沿用原后端（粘性），
+// 因此迁移一次后无需长期设置该环境变量。
+package secretstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// BackendPlaintext 明文存储（默认）。
+	BackendPlaintext = "plaintext"
+	// BackendFile 口令加密文件。
+	BackendFile = "file"
+	// BackendHelper 外部凭证助手。
+	BackendHelper = "helper"
+
+	// EnvBackend 选择新写入使用的后端。
+	EnvBackend = "FEISHU_SECRET_STORE"
+	// EnvPassphrase file 后端的口令。
+	EnvPassphrase = "FEISHU_SECRET_PASSPHRASE"
+	// EnvHelper helper 后端的命令（按空白切分，不经 shell）。
+	EnvHelper = "FEISHU_SECRET_HELPER"
+
+	refPrefix = "secret://"
+)
+
+// ErrNotFound 表示后端中没有该 key。
+var ErrNotFound = errors.New("secret 不存在")
+
+// Store 是密文存储后端。
+type Store interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Open 打开指定后端。rootDir 为 ~/.feishu-cli，file 后端把加密文件放在其下。
+func Open(backend, rootDir string) (Store, error) {
+	switch backend {
+	case BackendFile:
+		return newFileStore(rootDir), nil
+	case BackendHelper:
+		return newHelperStore()
+	case BackendPlaintext, "":
+		return nil, fmt.Errorf("plaintext 不是密文存储后端")
+	default:
+		return nil, fmt.Errorf("未知的 secret 存储后端 %q（可选: %s / %s / %s）", backend, BackendPlaintext, BackendFile, BackendHelper)
+	}
+}
+
+// DefaultBackend 返回 FEISHU_SECRET_STORE 指定的后端，未设置时为 plaintext。
+func DefaultBackend() (string, error) {
+	backend := strings.TrimSpace(os.Getenv(EnvBackend))
+	switch backend {
+	case "":
+		return BackendPlaintext, nil
+	case BackendPlaintext, BackendFile, BackendHelper:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("%s=%q 非法（可选: %s / %s / %s）", EnvBackend, backend, BackendPlaintext, BackendFile, BackendHelper)
+	}
+}
+
+// IsRef 报告 value 是否为 secret 引用。
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, refPrefix)
+}
+
+// ParseRef 解析 secret://<backend>/<key>。
+func ParseRef(value string) (backend, key string, ok bool) {
+	if !IsRef(value) {
+		return "", "", false
+	}
+	backend, key, ok = strings.Cut(strings.TrimPrefix(value, refPrefix), "/")
+	if !ok || backend == "" || key == "" {
+		return "", "", false
+	}
+	return backend, key, true
+}
+
+// BackendOf 返回 value 所用的后端；明文（含空值）返回 plaintext。
+func BackendOf(value string) string {
+	if backend, _, ok := ParseRef(value); ok {
+		return backend
+	}
+	return BackendPlaintext
+}
+
+func ref(backend, key string) string {
+	return refPrefix + backend + "/" + key
+}
+
+// Resolve 把引用还原为明文；非引用值原样返回。
+func Resolve(value, rootDir string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	backend, key, ok := ParseRef(value)
+	if !ok {
+		return "", fmt.Errorf("secret 引用格式非法: %q", value)
+	}
+	store, err := Open(backend, rootDir)
+	if err != nil {
+		return "", err
+	}
+	secret, err := store.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("读取 secret %s 失败: %w", key, err)
+	}
+	return secret, nil
+}
+
+// Seal 把明文 value 存入 backend 并返回要写进配置文件的值。
+//
+// backend 为 plaintext 或 value 为空时原样返回 value；previous 是该字段当前在文件里的值，
+// 若它是同一后端的引用则复用其 key 覆盖写，避免每次刷新 token 都留下孤儿条目。
+func Seal(backend, rootDir, field, value, previous string) (string, error) {
+	if backend == BackendPlaintext || backend == "" || value == "" {
+		return value, nil
+	}
+	store, err := Open(backend, rootDir)
+	if err != nil {
+		return "", err
+	}
+	key := ""
+	if prevBackend, prevKey, ok := ParseRef(previous); ok && prevBackend == backend {
+		key = prevKey
+	}
+	if key == "" {
+		if key, err = newKey(field); err != nil {
+			return "", err
+		}
+	}
+	if err := store.Set(key, value); err != nil {
+		return "", fmt.Errorf("写入 secret %s 失败: %w", key, err)
+	}
+	return ref(backend, key), nil
+}
+
+// Erase 删除引用指向的 secret；非引用值或条目已不存在时什么也不做。
+func Erase(value, rootDir string) error {
+	backend, key, ok := ParseRef(value)
+	if !ok {
+		return nil
+	}
+	store, err := Open(backend, rootDir)
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(key); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// newKey 生成 <field>-<16 位随机 hex> 形式的 key：与 profile 名解耦，重命名 profile 后引用仍然有效。
+func newKey(field string) (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("生成 secret key 失败: %w", err)
+	}
+	return field + "-" + hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,216 @@
+package secretstore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// useTestPassphrase 设置口令并清空进程内缓存，迭代次数降到 1 让测试不被 KDF 拖慢。
+func useTestPassphrase(t *testing.T, pass string) {
+	t.Helper()
+	t.Setenv(EnvPassphrase, pass)
+	oldIter, oldPrompt := pbkdf2Iterations, promptFunc
+	pbkdf2Iterations = 1
+	promptFunc = func(bool) (string, error) { return "", errors.New("不应提示输入口令") }
+	resetPassphrase()
+	t.Cleanup(func() {
+		pbkdf2Iterations, promptFunc = oldIter, oldPrompt
+		resetPassphrase()
+	})
+}
+
+func resetPassphrase() {
+	passMu.Lock()
+	defer passMu.Unlock()
+	passphrase = ""
+	derivedKeys = map[string][]byte{}
+}
+
+func TestDeriveKeyVector(t *testing.T) {
+	useTestPassphrase(t, "passwd")
+	key, _, err := deriveKey([]byte("salt"), 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RFC 7914 §11 PBKDF2-HMAC-SHA256 测试向量的前 32 字节
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"
+	if got := hex.EncodeToString(key); got != want {
+		t.Fatalf("deriveKey = %s", got)
+	}
+}
+
+func TestFileStoreSealResolveErase(t *testing.T) {
+	root := t.TempDir()
+	useTestPassphrase(t, "pw")
+
+	ref, err := Seal(BackendFile, root, "app_secret", "s3cret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ref, "secret://file/app_secret-") {
+		t.Fatalf("ref = %q", ref)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, FileName))
+	if strings.Contains(string(data), "s3cret") {
+		t.Fatal("加密文件中不应出现明文")
+	}
+	if got, err := Resolve(ref, root); err != nil || got != "s3cret" {
+		t.Fatalf("Resolve = %q, %v", got, err)
+	}
+
+	// 同后端的旧引用复用 key，覆盖写
+	again, err := Seal(BackendFile, root, "app_secret", "rotated", ref)
+	if err != nil || again != ref {
+		t.Fatalf("Seal 应复用 key: %q, %v", again, err)
+	}
+	if got, _ := Resolve(ref, root); got != "rotated" {
+		t.Fatalf("覆盖后 Resolve = %q", got)
+	}
+
+	// 口令错误时报错而不是返回垃圾
+	useTestPassphrase(t, "wrong")
+	if _, err := Resolve(ref, root); err == nil || !strings.Contains(err.Error(), "口令错误") {
+		t.Fatalf("错误口令应报错: %v", err)
+	}
+
+	useTestPassphrase(t, "pw")
+	if err := Erase(ref, root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve(ref, root); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Erase 后应 not found: %v", err)
+	}
+}
+
+func TestFileStoreWrongPassphraseNotCached(t *testing.T) {
+	root := t.TempDir()
+	useTestPassphrase(t, "pw")
+	ref, err := Seal(BackendFile, root, "app_secret", "s3cret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新进程：不设环境变量，第一次在终端输错口令，第二次输对
+	resetPassphrase()
+	t.Setenv(EnvPassphrase, "")
+	inputs := []string{"wrong", "pw"}
+	promptFunc = func(bool) (string, error) {
+		p := inputs[0]
+		inputs = inputs[1:]
+		return p, nil
+	}
+	if _, err := Resolve(ref, root); err == nil || !strings.Contains(err.Error(), "口令错误") {
+		t.Fatalf("错误口令应报错: %v", err)
+	}
+	if got, err := Resolve(ref, root); err != nil || got != "s3cret" {
+		t.Fatalf("重新输入正确口令后 Resolve = %q, %v", got, err)
+	}
+	// 正确口令已缓存，不再提示
+	if got, err := Resolve(ref, root); err != nil || got != "s3cret" || len(inputs) != 0 {
+		t.Fatalf("Resolve = %q, %v (剩余输入 %v)", got, err, inputs)
+	}
+}
+
+func TestFileStoreConcurrentSet(t *testing.T) {
+	root := t.TempDir()
+	useTestPassphrase(t, "pw")
+
+	// 每个 goroutine 各自持有一个 fileStore，模拟多个进程同时写入
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- newFileStore(root).Set(fmt.Sprintf("k%d", i), fmt.Sprintf("v%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := newFileStore(root)
+	for i := 0; i < n; i++ {
+		if got, err := store.Get(fmt.Sprintf("k%d", i)); err != nil || got != fmt.Sprintf("v%d", i) {
+			t.Fatalf("k%d = %q, %v（并发写入丢失）", i, got, err)
+		}
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(root, "*.tmp")); len(leftovers) != 0 {
+		t.Fatalf("残留临时文件: %v", leftovers)
+	}
+}
+
+func TestPlaintextPassThrough(t *testing.T) {
+	if got, err := Seal(BackendPlaintext, t.TempDir(), "app_secret", "plain", ""); err != nil || got != "plain" {
+		t.Fatalf("plaintext Seal = %q, %v", got, err)
+	}
+	if got, err := Resolve("plain", ""); err != nil || got != "plain" {
+		t.Fatalf("非引用 Resolve = %q, %v", got, err)
+	}
+	if err := Erase("plain", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultBackend(t *testing.T) {
+	t.Setenv(EnvBackend, "")
+	if b, err := DefaultBackend(); err != nil || b != BackendPlaintext {
+		t.Fatalf("未设置时应为 plaintext: %q, %v", b, err)
+	}
+	t.Setenv(EnvBackend, "keychain")
+	if _, err := DefaultBackend(); err == nil {
+		t.Fatal("非法后端应报错")
+	}
+}
+
+func TestHelperStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper 脚本依赖 /bin/sh")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	// 每个 key 存成 dir 下的一个文件
+	body := `#!/bin/sh
+f="` + dir + `/$2"
+case "$1" in
+  get) [ -f "$f" ] || exit 44; cat "$f" ;;
+  store) cat > "$f" ;;
+  erase) rm -f "$f" ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvHelper, script)
+
+	ref, err := Seal(BackendHelper, "", "refresh_token", "ur-token", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Resolve(ref, ""); err != nil || got != "ur-token" {
+		t.Fatalf("Resolve = %q, %v", got, err)
+	}
+	if err := Erase(ref, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve(ref, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("退出码 44 应映射为 ErrNotFound: %v", err)
+	}
+
+	t.Setenv(EnvHelper, "")
+	if _, err := Open(BackendHelper, ""); err == nil {
+		t.Fatal("未设置 helper 命令应报错")
+	}
+}